@@ -15,10 +15,11 @@ import (
 	"github.com/gin-gonic/gin"
 	"github.com/joho/godotenv"
 
+	"dental_backend/internal/config"
 	"dental_backend/internal/database"
+	"dental_backend/internal/fieldcrypt"
 	"dental_backend/internal/handlers"
-	"dental_backend/internal/models"
-	"dental_backend/internal/services"
+	"dental_backend/internal/notify"
 )
 
 func main() {
@@ -32,40 +33,87 @@ func main() {
 		}
 	}
 
-	// Initialize database connection using the shared database package
-	_, err = database.InitDB()
+	cfg := config.Load()
+
+	// Open the database connection pool
+	db, err := database.Open(&cfg.Database)
 	if err != nil {
 		log.Fatal("Failed to initialize database:", err)
 	}
 
 	// Defer closing the database connection
 	defer func() {
-		if err := database.CloseDB(); err != nil {
+		if err := db.Close(); err != nil {
 			log.Printf("Error closing database: %v", err)
 		}
 	}()
 
+	// Refuse to start against an outdated schema unless auto-migration is enabled
+	if err := database.EnsureSchema(context.Background(), db, &cfg.Database); err != nil {
+		log.Fatal("Database schema check failed: ", err)
+	}
+
+	// Refuse to start as a user the clinic_isolation policies do not apply to
+	if err := database.CheckRowSecurity(context.Background(), db); err != nil {
+		log.Fatal("Database role check failed: ", err)
+	}
+
 	// Set release mode for production
 	gin.SetMode(gin.ReleaseMode)
 
-	// Create router
-	router := gin.New()
-	router.Use(gin.Logger())
-	router.Use(gin.Recovery())
-	router.Use(corsMiddleware())
+	// Sensitive patient fields are encrypted when a keyfile is configured
+	cipher, err := fieldcrypt.Load(cfg.FieldKeysFile)
+	if err != nil {
+		log.Fatal("Failed to load field encryption keys: ", err)
+	}
+	if cipher == nil {
+		log.Println("FIELD_KEYS_FILE is not set; sensitive patient fields are stored in plaintext")
+	}
+
+	// Refuse sign-in provider settings that would let accounts be taken over
+	for _, provider := range cfg.OIDCProviders {
+		if err := provider.Validate(); err != nil {
+			log.Fatal("Invalid sign-in provider settings: ", err)
+		}
+	}
+
+	// Wire the application together
+	server := handlers.NewServer(cfg, db, handlers.PostgresRepositories(db, cipher))
+
+	// Account emails are always sent from this process
+	notifiers, err := notify.New(cfg.Notify, &http.Client{Timeout: 30 * time.Second})
+	if err != nil {
+		log.Fatal("Invalid notification settings: ", err)
+	}
+	server.SetNotifiers(notifiers)
+
+	// Send appointment reminders from this process when no separate worker runs
+	reminderCtx, stopReminders := context.WithCancel(context.Background())
+	defer stopReminders()
+	if cfg.RemindersInProcess {
+		// The jobs work across clinics, on connections the row-level security
+		// policies let see all of them
+		jobsDB, err := database.Open(cfg.Database.ForAllClinics())
+		if err != nil {
+			log.Fatal("Failed to initialize database for background jobs:", err)
+		}
+		defer jobsDB.Close()
 
-	// Define routes
-	setupRoutes(router)
+		jobs := handlers.NewServer(cfg, jobsDB, handlers.PostgresRepositories(jobsDB, cipher))
+		jobs.SetNotifiers(notifiers)
+		go jobs.RunReminders(reminderCtx)
+		go jobs.RunRiskRecompute(reminderCtx)
+	}
 
 	// Create HTTP server
 	srv := &http.Server{
-		Addr:    ":8080",
-		Handler: router,
+		Addr:    cfg.Addr,
+		Handler: server.Router(),
 	}
 
 	// Start server in a goroutine
 	go func() {
-		fmt.Println("Starting server on :8080")
+		fmt.Printf("Starting server on %s\n", cfg.Addr)
 		if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
 			log.Fatalf("Failed to start server: %v", err)
 		}
@@ -76,6 +124,7 @@ func main() {
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
 	<-quit
 	fmt.Println("Shutting down server...")
+	stopReminders()
 
 	// The context is used to inform the server it has 5 seconds to finish
 	// the request it is currently handling
@@ -88,175 +137,3 @@ func main() {
 
 	fmt.Println("Server exiting")
 }
-
-func setupRoutes(router *gin.Engine) {
-	// Health check endpoint
-	router.GET("/health", func(c *gin.Context) {
-		c.JSON(http.StatusOK, gin.H{
-			"status":  "ok",
-			"message": "Dental backend is running",
-		})
-	})
-
-	// API routes
-	api := router.Group("/api")
-	{
-		// Authentication endpoints
-		api.POST("/auth/register", handlers.Register)
-		api.POST("/auth/login", handlers.Login)
-		api.POST("/auth/google-login", handlers.GoogleLogin)       // Add Google login route
-		api.POST("/auth/google-register", handlers.GoogleRegister) // Add Google registration route
-		api.GET("/auth/user", handlers.AuthMiddleware(), handlers.GetCurrentUser)
-
-		// Dashboard endpoints
-		api.GET("/dashboard/stats", handlers.AuthMiddleware(), getDashboardStats)
-
-		// Patient endpoints
-		api.GET("/patients", handlers.AuthMiddleware(), handlers.GetPatients)
-		api.GET("/patients/stats", handlers.AuthMiddleware(), handlers.GetPatientStats)
-		api.POST("/patients", handlers.AuthMiddleware(), handlers.CreatePatient)
-		api.GET("/patients/:id", handlers.AuthMiddleware(), handlers.GetPatient)
-		api.PUT("/patients/:id", handlers.AuthMiddleware(), handlers.UpdatePatient)
-		api.DELETE("/patients/:id", handlers.AuthMiddleware(), handlers.DeletePatient)
-
-		// Appointment endpoints (accessible by dentists and staff)
-		appointmentRoutes := api.Group("/appointments")
-		appointmentRoutes.Use(handlers.AuthMiddleware())
-		{
-			appointmentRoutes.GET("/today", handlers.GetTodaysAppointments)
-			appointmentRoutes.GET("", handlers.GetAppointments)
-			appointmentRoutes.GET("/:id", handlers.GetAppointment)
-			appointmentRoutes.POST("", handlers.CreateAppointment)
-			appointmentRoutes.PUT("/:id", handlers.UpdateAppointment)
-			appointmentRoutes.DELETE("/:id", handlers.DeleteAppointment)
-		}
-
-		// Treatment endpoints
-		api.GET("/treatments/queue", handlers.AuthMiddleware(), handlers.GetTreatmentQueue)
-		api.GET("/treatments", handlers.AuthMiddleware(), handlers.GetTreatments)
-		api.GET("/treatments/:id", handlers.AuthMiddleware(), handlers.GetTreatment)
-		api.POST("/treatments", handlers.AuthMiddleware(), handlers.CreateTreatment)
-		api.PUT("/treatments/:id", handlers.AuthMiddleware(), handlers.UpdateTreatment)
-		api.DELETE("/treatments/:id", handlers.AuthMiddleware(), handlers.DeleteTreatment)
-
-		// Patient treatment endpoints
-		api.GET("/patients/:id/treatments", handlers.AuthMiddleware(), handlers.GetPatientTreatments)
-		api.POST("/patient-treatments", handlers.AuthMiddleware(), handlers.CreatePatientTreatment)
-		api.PUT("/patient-treatments/:id", handlers.AuthMiddleware(), handlers.UpdatePatientTreatment)
-		api.DELETE("/patient-treatments/:id", handlers.AuthMiddleware(), handlers.DeletePatientTreatment)
-		
-		// Tooth analysis endpoint
-		api.POST("/tooth-analysis", handlers.AuthMiddleware(), handlers.AnalyzeTooth)
-
-		// Billing endpoints
-		api.GET("/billing/stats", handlers.AuthMiddleware(), handlers.GetBillingStats)
-		api.GET("/billing/invoices", handlers.AuthMiddleware(), handlers.GetInvoices)
-		api.GET("/billing/invoices/:id", handlers.AuthMiddleware(), handlers.GetInvoice)
-		api.POST("/billing/invoices", handlers.AuthMiddleware(), handlers.CreateInvoice)
-		api.PUT("/billing/invoices/:id", handlers.AuthMiddleware(), handlers.UpdateInvoice)
-		api.DELETE("/billing/invoices/:id", handlers.AuthMiddleware(), handlers.DeleteInvoice)
-
-		// Insurance claims endpoints
-		api.GET("/billing/claims", handlers.AuthMiddleware(), handlers.GetInsuranceClaims)
-		api.GET("/billing/claims/:id", handlers.AuthMiddleware(), handlers.GetInsuranceClaim)
-		api.POST("/billing/claims", handlers.AuthMiddleware(), handlers.CreateInsuranceClaim)
-		api.PUT("/billing/claims/:id", handlers.AuthMiddleware(), handlers.UpdateInsuranceClaim)
-		api.DELETE("/billing/claims/:id", handlers.AuthMiddleware(), handlers.DeleteInsuranceClaim)
-
-		// Activity endpoints
-		api.GET("/activity/recent", handlers.AuthMiddleware(), getRecentActivity)
-	}
-}
-
-// Middleware to handle CORS
-func corsMiddleware() gin.HandlerFunc {
-	return func(c *gin.Context) {
-		origin := c.Request.Header.Get("Origin")
-		if origin == "" {
-			origin = "*"
-		}
-
-		c.Writer.Header().Set("Access-Control-Allow-Origin", origin)
-		c.Writer.Header().Set("Access-Control-Allow-Credentials", "true")
-		c.Writer.Header().Set("Access-Control-Allow-Headers", "Content-Type, Content-Length, Accept-Encoding, X-CSRF-Token, Authorization, accept, origin, Cache-Control, X-Requested-With")
-		c.Writer.Header().Set("Access-Control-Allow-Methods", "POST, OPTIONS, GET, PUT, DELETE")
-
-		// Completely remove Cross-Origin-Opener-Policy for all requests to fix Google OAuth popup issues
-		// This is required for Google OAuth to work properly
-		c.Writer.Header().Del("Cross-Origin-Opener-Policy")
-
-		if c.Request.Method == "OPTIONS" {
-			c.AbortWithStatus(204)
-			return
-		}
-
-		c.Next()
-	}
-}
-
-// Dashboard stats handler
-func getDashboardStats(c *gin.Context) {
-	// Get database connection from the shared database package
-	db := database.GetDB()
-
-	// Create services
-	patientService := services.NewPatientService(db)
-	appointmentService := services.NewAppointmentService(db)
-	treatmentService := services.NewTreatmentService(db)
-
-	// Get patient stats from service
-	patientStats, err := patientService.GetPatientStats()
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve patient statistics"})
-		return
-	}
-
-	// Get dentist ID from context (0 if not a dentist or not logged in)
-	dentistID := 0
-	dentistIDValue, exists := c.Get("dentistID")
-	if exists {
-		dentistID, _ = dentistIDValue.(int)
-	}
-
-	// Get today's appointments count
-	var todaysAppointments []models.Appointment
-	if dentistID > 0 {
-		todaysAppointments, err = appointmentService.GetTodaysAppointments(dentistID)
-	} else {
-		// For non-dentists, get all appointments
-		todaysAppointments, err = appointmentService.GetTodaysAppointments(0)
-	}
-	
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve today's appointments"})
-		return
-	}
-
-	// Get pending treatments count
-	var pendingTreatments []models.PatientTreatment
-	if dentistID > 0 {
-		pendingTreatments, err = treatmentService.GetTreatmentQueueForDentist(dentistID)
-	} else {
-		// For non-dentists, get all treatments
-		pendingTreatments, err = treatmentService.GetTreatmentQueue()
-	}
-	
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve pending treatments"})
-		return
-	}
-
-	// In a real implementation, this would fetch actual dashboard stats
-	c.JSON(http.StatusOK, gin.H{
-		"todayAppointments": len(todaysAppointments),
-		"activePatients":    patientStats.TotalPatients,
-		"pendingTreatments": len(pendingTreatments),
-		"monthlyRevenue":    48950.00,
-	})
-}
-
-// Recent activity handler
-func getRecentActivity(c *gin.Context) {
-	// In a real implementation, this would fetch recent activity from the database
-	c.JSON(http.StatusOK, []interface{}{})
-}
@@ -0,0 +1,108 @@
+// dental_backend/cmd/migrate/main.go
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+
+	"github.com/joho/godotenv"
+
+	"dental_backend/internal/config"
+	"dental_backend/internal/database"
+)
+
+const usage = `Usage: migrate <command> [args]
+
+Commands:
+  up          Apply all pending migrations
+  down [n]    Roll back the last n applied migrations (default 1)
+  status      List migrations and whether they have been applied
+  redo        Roll back and re-apply the most recent migration`
+
+func main() {
+	if len(os.Args) < 2 {
+		fmt.Println(usage)
+		os.Exit(2)
+	}
+
+	// Load environment variables the same way the API does
+	if err := godotenv.Load(".env"); err != nil {
+		if err := godotenv.Load("dental_backend/.env"); err != nil {
+			log.Println("No .env file found, using system environment variables")
+		}
+	}
+
+	// Migrations run as the user that owns the schema
+	db, err := database.Open(config.Load().Database.Migrator())
+	if err != nil {
+		log.Fatal("Failed to initialize database:", err)
+	}
+	defer db.Close()
+
+	migrator, err := database.NewMigrator(db)
+	if err != nil {
+		log.Fatal("Failed to load migrations:", err)
+	}
+
+	ctx := context.Background()
+
+	switch os.Args[1] {
+	case "up":
+		applied, err := migrator.Up(ctx)
+		for _, m := range applied {
+			fmt.Printf("Applied   %04d_%s\n", m.Version, m.Name)
+		}
+		if err != nil {
+			log.Fatal(err)
+		}
+		if len(applied) == 0 {
+			fmt.Println("Database is already up to date")
+		}
+
+	case "down":
+		steps := 1
+		if len(os.Args) > 2 {
+			steps, err = strconv.Atoi(os.Args[2])
+			if err != nil || steps < 1 {
+				log.Fatalf("Invalid number of steps %q", os.Args[2])
+			}
+		}
+		rolledBack, err := migrator.Down(ctx, steps)
+		for _, m := range rolledBack {
+			fmt.Printf("Reverted  %04d_%s\n", m.Version, m.Name)
+		}
+		if err != nil {
+			log.Fatal(err)
+		}
+		if len(rolledBack) == 0 {
+			fmt.Println("No applied migrations to roll back")
+		}
+
+	case "status":
+		statuses, err := migrator.Status(ctx)
+		if err != nil {
+			log.Fatal(err)
+		}
+		for _, s := range statuses {
+			state := "pending"
+			if s.Applied {
+				state = "applied " + s.AppliedAt.Format("2006-01-02 15:04:05")
+			}
+			fmt.Printf("%04d_%-40s %s\n", s.Version, s.Name, state)
+		}
+
+	case "redo":
+		m, err := migrator.Redo(ctx)
+		if err != nil {
+			log.Fatal(err)
+		}
+		fmt.Printf("Redid     %04d_%s\n", m.Version, m.Name)
+
+	default:
+		fmt.Println(usage)
+		os.Exit(2)
+	}
+}
@@ -0,0 +1,113 @@
+// dental_backend/cmd/rekey/main.go
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/joho/godotenv"
+
+	"dental_backend/internal/config"
+	"dental_backend/internal/database"
+	"dental_backend/internal/fieldcrypt"
+	"dental_backend/internal/repository/postgres"
+)
+
+const usage = `Usage: rekey [-rotate] [-decrypt] [-batch n]
+
+Encrypts the sensitive fields of patients and their medical history entries
+still stored in plaintext, and re-encrypts those encrypted under a key other
+than the current one in FIELD_KEYS_FILE. Keys that are no longer current can
+be removed from the keyfile once it has run.
+
+Flags:
+  -rotate     Add a new key to the keyfile and make it current first,
+              creating the keyfile when it does not exist
+  -decrypt    Write every row back in plaintext instead, before turning
+              encryption off or rolling back migration 0018
+  -batch n    Rows rewritten per transaction (default 500)`
+
+// rewriter is a repository whose encrypted rows the command rewrites
+type rewriter interface {
+	Rekey(ctx context.Context, batchSize int) (int, error)
+	Decrypt(ctx context.Context, batchSize int) (int, error)
+}
+
+func main() {
+	flag.Usage = func() { fmt.Fprintln(os.Stderr, usage) }
+	rotate := flag.Bool("rotate", false, "")
+	decrypt := flag.Bool("decrypt", false, "")
+	batch := flag.Int("batch", 500, "")
+	flag.Parse()
+
+	if *rotate && *decrypt || *batch < 1 || flag.NArg() > 0 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	// Load environment variables the same way the API does
+	if err := godotenv.Load(".env"); err != nil {
+		if err := godotenv.Load("dental_backend/.env"); err != nil {
+			log.Println("No .env file found, using system environment variables")
+		}
+	}
+
+	cfg := config.Load()
+	if cfg.FieldKeysFile == "" {
+		log.Fatal("FIELD_KEYS_FILE is not set")
+	}
+
+	if *rotate {
+		keyID, err := fieldcrypt.RotateKeyfile(cfg.FieldKeysFile)
+		if err != nil {
+			log.Fatal("Failed to rotate keys: ", err)
+		}
+		fmt.Printf("Key %s is now current in %s\n", keyID, cfg.FieldKeysFile)
+	}
+
+	cipher, err := fieldcrypt.Load(cfg.FieldKeysFile)
+	if err != nil {
+		log.Fatal("Failed to load field encryption keys: ", err)
+	}
+
+	// Every clinic's patients are rewritten
+	db, err := database.Open(cfg.Database.ForAllClinics())
+	if err != nil {
+		log.Fatal("Failed to initialize database:", err)
+	}
+	defer db.Close()
+
+	ctx := context.Background()
+	if err := database.EnsureSchema(ctx, db, &cfg.Database); err != nil {
+		log.Fatal("Database schema check failed: ", err)
+	}
+
+	// Patients and their medical history entries are encrypted alike
+	tables := []struct {
+		name string
+		rows rewriter
+	}{
+		{"patients", postgres.NewPatientRepository(db, cipher)},
+		{"medical history entries", postgres.NewMedicalEntryRepository(db, cipher)},
+	}
+
+	for _, table := range tables {
+		if *decrypt {
+			n, err := table.rows.Decrypt(ctx, *batch)
+			fmt.Printf("Decrypted %d %s\n", n, table.name)
+			if err != nil {
+				log.Fatal(err)
+			}
+			continue
+		}
+
+		n, err := table.rows.Rekey(ctx, *batch)
+		fmt.Printf("Encrypted %d %s with key %s\n", n, table.name, cipher.CurrentKeyID())
+		if err != nil {
+			log.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,120 @@
+// dental_backend/cmd/worker/main.go
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"net/http"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/joho/godotenv"
+
+	"dental_backend/internal/config"
+	"dental_backend/internal/database"
+	"dental_backend/internal/fieldcrypt"
+	"dental_backend/internal/notify"
+	"dental_backend/internal/repository/postgres"
+	"dental_backend/internal/services"
+)
+
+func main() {
+	once := flag.Bool("once", false, "send due reminders and recompute patient risk once and exit")
+	flag.Parse()
+
+	// Load environment variables the same way the API does
+	if err := godotenv.Load(".env"); err != nil {
+		if err := godotenv.Load("dental_backend/.env"); err != nil {
+			log.Println("No .env file found, using system environment variables")
+		}
+	}
+
+	cfg := config.Load()
+
+	// Reminders and risk are worked out for every clinic, on connections the
+	// row-level security policies let see all of them
+	db, err := database.Open(cfg.Database.ForAllClinics())
+	if err != nil {
+		log.Fatal("Failed to initialize database:", err)
+	}
+	defer db.Close()
+
+	// Refuse to start against an outdated schema unless auto-migration is enabled
+	if err := database.EnsureSchema(context.Background(), db, &cfg.Database); err != nil {
+		log.Fatal("Database schema check failed: ", err)
+	}
+	if err := database.CheckRowSecurity(context.Background(), db); err != nil {
+		log.Fatal("Database role check failed: ", err)
+	}
+
+	cipher, err := fieldcrypt.Load(cfg.FieldKeysFile)
+	if err != nil {
+		log.Fatal("Failed to load field encryption keys: ", err)
+	}
+
+	notifiers, err := notify.New(cfg.Notify, &http.Client{Timeout: 30 * time.Second})
+	if err != nil {
+		log.Fatal("Invalid notification settings: ", err)
+	}
+	if len(notifiers) == 0 {
+		log.Println("No notification channels configured; set NOTIFY_EMAIL or NOTIFY_SMS to send reminders")
+	}
+
+	appointments := postgres.NewAppointmentRepository(db)
+	patients := postgres.NewPatientRepository(db, cipher)
+
+	reminders := services.NewReminderService(
+		appointments,
+		patients,
+		postgres.NewReminderRepository(db),
+		notifiers,
+		cfg.ReminderOffsets,
+	)
+	reminders.SetLinks(services.NewLinkTokens(cfg.LinkSecret), cfg.PatientLinkURL)
+
+	risk := services.NewRiskService(
+		postgres.NewRiskRepository(db),
+		patients,
+		postgres.NewMedicalEntryRepository(db, cipher),
+		postgres.NewTreatmentRepository(db),
+		appointments,
+		postgres.NewBillingRepository(db),
+	)
+
+	// Stop after the current run on SIGINT or SIGTERM
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	if *once {
+		sent, err := reminders.RunOnce(ctx)
+		if err != nil {
+			log.Fatal("Failed to send reminders: ", err)
+		}
+		fmt.Printf("Sent %d reminders\n", sent)
+
+		result, err := risk.RecomputeAll(ctx)
+		if err != nil {
+			log.Fatal("Failed to recompute patient risk: ", err)
+		}
+		fmt.Printf("Recomputed risk of %d patients, %d changed level\n", result.Patients, result.Changed)
+		return
+	}
+
+	// Recompute patient risk alongside the reminders, and let both finish their current run
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		fmt.Printf("Recomputing patient risk every %v\n", cfg.RiskRecomputeInterval)
+		risk.Run(ctx, cfg.RiskRecomputeInterval)
+	}()
+
+	fmt.Printf("Sending reminders %v before appointments, checking every %v\n", cfg.ReminderOffsets, cfg.ReminderInterval)
+	reminders.Run(ctx, cfg.ReminderInterval)
+	wg.Wait()
+	fmt.Println("Worker exiting")
+}
@@ -0,0 +1,187 @@
+// dental_backend/internal/audit/audit.go
+
+// Package audit seals audit trail entries into a hash chain and works out what
+// a write changed in a record.
+package audit
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"reflect"
+	"strings"
+	"time"
+
+	"dental_backend/internal/models"
+)
+
+// GenesisHash is the PrevHash of the first entry in the chain
+var GenesisHash = strings.Repeat("0", 64)
+
+// Hash returns the hash sealing entry after entry.PrevHash. The ID is left out,
+// since the database assigns it, and OccurredAt is taken to the microsecond,
+// the precision PostgreSQL stores.
+func Hash(entry models.AuditEntry) string {
+	// A struct marshals its fields in declaration order, so the encoding is stable
+	payload, _ := json.Marshal(struct {
+		PrevHash     string `json:"prevHash"`
+		OccurredAt   string `json:"occurredAt"`
+		ClinicID     *int   `json:"clinicId"`
+		ActorID      *int   `json:"actorId"`
+		ActorRole    string `json:"actorRole"`
+		Action       string `json:"action"`
+		ResourceType string `json:"resourceType"`
+		ResourceID   string `json:"resourceId"`
+		Method       string `json:"method"`
+		Route        string `json:"route"`
+		Status       int    `json:"status"`
+		Changes      string `json:"changes"`
+		RequestID    string `json:"requestId"`
+		IPAddress    string `json:"ipAddress"`
+	}{
+		PrevHash:     entry.PrevHash,
+		OccurredAt:   entry.OccurredAt.UTC().Truncate(time.Microsecond).Format(time.RFC3339Nano),
+		ClinicID:     entry.ClinicID,
+		ActorID:      entry.ActorID,
+		ActorRole:    entry.ActorRole,
+		Action:       entry.Action,
+		ResourceType: entry.ResourceType,
+		ResourceID:   entry.ResourceID,
+		Method:       entry.Method,
+		Route:        entry.Route,
+		Status:       entry.Status,
+		Changes:      string(entry.Changes),
+		RequestID:    entry.RequestID,
+		IPAddress:    entry.IPAddress,
+	})
+
+	sum := sha256.Sum256(payload)
+	return hex.EncodeToString(sum[:])
+}
+
+// Change is a field's value before and after a write
+type Change struct {
+	From interface{} `json:"from"`
+	To   interface{} `json:"to"`
+}
+
+// Redacted stands in for the values of sensitive fields, which the trail records
+// only the names of
+const Redacted = "[redacted]"
+
+// ignoredFields change on every write and say nothing about what was changed
+var ignoredFields = map[string]bool{"updatedAt": true}
+
+// Diff compares the JSON encodings of a record before and after a write and
+// returns the fields that differ, or nil when none do. A nil before is a
+// creation and a nil after a deletion. Fields tagged encrypted, and fields
+// holding records with such fields, are named with their values redacted.
+func Diff(before, after interface{}) (json.RawMessage, error) {
+	from, err := fields(before)
+	if err != nil {
+		return nil, err
+	}
+	to, err := fields(after)
+	if err != nil {
+		return nil, err
+	}
+
+	sensitive := make(map[string]bool)
+	for _, record := range []interface{}{before, after} {
+		if record != nil {
+			sensitiveFields(reflect.TypeOf(record), sensitive)
+		}
+	}
+	change := func(name string, from, to interface{}) Change {
+		if sensitive[name] {
+			return Change{From: Redacted, To: Redacted}
+		}
+		return Change{From: from, To: to}
+	}
+
+	changes := make(map[string]Change)
+	for name, value := range from {
+		if !ignoredFields[name] && !reflect.DeepEqual(value, to[name]) {
+			changes[name] = change(name, value, to[name])
+		}
+	}
+	for name, value := range to {
+		if _, seen := from[name]; !seen && !ignoredFields[name] && value != nil {
+			changes[name] = change(name, nil, value)
+		}
+	}
+	if len(changes) == 0 {
+		return nil, nil
+	}
+
+	// Maps marshal with sorted keys, so equal changes encode identically
+	return json.Marshal(changes)
+}
+
+// fields decodes the JSON encoding of a record into its top-level fields
+func fields(record interface{}) (map[string]interface{}, error) {
+	if record == nil {
+		return nil, nil
+	}
+	encoded, err := json.Marshal(record)
+	if err != nil {
+		return nil, err
+	}
+	if bytes.Equal(encoded, []byte("null")) {
+		return nil, nil
+	}
+
+	var decoded map[string]interface{}
+	if err := json.Unmarshal(encoded, &decoded); err != nil {
+		return nil, err
+	}
+	return decoded, nil
+}
+
+// sensitiveFields adds the JSON names of the top-level fields of records of type t
+// that are tagged encrypted or hold records with such fields, such as a patient's
+// medical entries. Embedded structs contribute their fields, as they do to the
+// JSON encoding.
+func sensitiveFields(t reflect.Type, names map[string]bool) {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return
+	}
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "-" {
+			continue
+		}
+		if field.Anonymous && name == "" {
+			sensitiveFields(field.Type, names)
+			continue
+		}
+		if !field.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		if field.Tag.Get("encrypted") == "true" || holdsSensitive(field.Type) {
+			names[name] = true
+		}
+	}
+}
+
+// holdsSensitive reports whether values of type t hold records with sensitive fields
+func holdsSensitive(t reflect.Type) bool {
+	for t.Kind() == reflect.Ptr || t.Kind() == reflect.Slice || t.Kind() == reflect.Array || t.Kind() == reflect.Map {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return false
+	}
+	names := make(map[string]bool)
+	sensitiveFields(t, names)
+	return len(names) > 0
+}
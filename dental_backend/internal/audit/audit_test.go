@@ -0,0 +1,185 @@
+// dental_backend/internal/audit/audit_test.go
+package audit
+
+import (
+	"encoding/json"
+	"strconv"
+	"testing"
+	"time"
+
+	"dental_backend/internal/models"
+)
+
+// newChain returns n entries sealed into a chain the way the audit repositories append them
+func newChain(n int) []models.AuditEntry {
+	clinicID := 1
+	start := time.Date(2026, 3, 2, 9, 0, 0, 123456789, time.UTC)
+
+	entries := make([]models.AuditEntry, n)
+	prev := GenesisHash
+	for i := range entries {
+		actorID := 10 + i
+		entry := models.AuditEntry{
+			ID:           int64(i + 1),
+			OccurredAt:   start.Add(time.Duration(i) * time.Minute),
+			ClinicID:     &clinicID,
+			ActorID:      &actorID,
+			ActorRole:    "staff",
+			Action:       "update",
+			ResourceType: "patient",
+			ResourceID:   strconv.Itoa(100 + i),
+			Method:       "PUT",
+			Route:        "/api/patients/:id",
+			Status:       200,
+			Changes:      json.RawMessage(`{"firstName":{"from":"Pat","to":"Patricia"}}`),
+			RequestID:    "req-" + strconv.Itoa(i),
+			IPAddress:    "203.0.113.7",
+			PrevHash:     prev,
+		}
+		entry.Hash = Hash(entry)
+		entries[i] = entry
+		prev = entry.Hash
+	}
+	return entries
+}
+
+// brokenAt walks entries as the audit service's Verify does and returns the
+// index of the first entry that does not check out, or -1 when the chain holds
+func brokenAt(entries []models.AuditEntry) int {
+	prev := GenesisHash
+	for i, entry := range entries {
+		if entry.PrevHash != prev || Hash(entry) != entry.Hash {
+			return i
+		}
+		prev = entry.Hash
+	}
+	return -1
+}
+
+func TestChainVerifies(t *testing.T) {
+	if i := brokenAt(newChain(5)); i != -1 {
+		t.Fatalf("untouched chain broken at entry %d", i)
+	}
+}
+
+func TestChainDetectsTampering(t *testing.T) {
+	tests := []struct {
+		name   string
+		tamper func(entries []models.AuditEntry) []models.AuditEntry
+		want   int
+	}{
+		{
+			name: "modified status",
+			tamper: func(entries []models.AuditEntry) []models.AuditEntry {
+				entries[2].Status = 403
+				return entries
+			},
+			want: 2,
+		},
+		{
+			name: "modified changes",
+			tamper: func(entries []models.AuditEntry) []models.AuditEntry {
+				entries[1].Changes = json.RawMessage(`{"firstName":{"from":"Pat","to":"Pam"}}`)
+				return entries
+			},
+			want: 1,
+		},
+		{
+			name: "modified actor",
+			tamper: func(entries []models.AuditEntry) []models.AuditEntry {
+				other := 99
+				entries[3].ActorID = &other
+				return entries
+			},
+			want: 3,
+		},
+		{
+			name: "modified time",
+			tamper: func(entries []models.AuditEntry) []models.AuditEntry {
+				entries[0].OccurredAt = entries[0].OccurredAt.Add(time.Second)
+				return entries
+			},
+			want: 0,
+		},
+		{
+			name: "modified and resealed",
+			tamper: func(entries []models.AuditEntry) []models.AuditEntry {
+				entries[2].Action = "delete"
+				entries[2].Hash = Hash(entries[2])
+				return entries
+			},
+			want: 3,
+		},
+		{
+			name: "deleted entry",
+			tamper: func(entries []models.AuditEntry) []models.AuditEntry {
+				return append(entries[:2], entries[3:]...)
+			},
+			want: 2,
+		},
+		{
+			name: "deleted first entry",
+			tamper: func(entries []models.AuditEntry) []models.AuditEntry {
+				return entries[1:]
+			},
+			want: 0,
+		},
+		{
+			name: "reordered entries",
+			tamper: func(entries []models.AuditEntry) []models.AuditEntry {
+				entries[1], entries[2] = entries[2], entries[1]
+				return entries
+			},
+			want: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := brokenAt(tt.tamper(newChain(5))); got != tt.want {
+				t.Errorf("chain broken at entry %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHashLeavesOutWhatTheDatabaseDecides(t *testing.T) {
+	entry := newChain(1)[0]
+	want := Hash(entry)
+
+	// The database assigns the ID and stores times to the microsecond in UTC
+	stored := entry
+	stored.ID = 42
+	stored.OccurredAt = entry.OccurredAt.Truncate(time.Microsecond).In(time.FixedZone("CET", 3600))
+	if got := Hash(stored); got != want {
+		t.Errorf("hash of the stored entry = %s, want %s", got, want)
+	}
+}
+
+func TestDiffRedactsEncryptedFields(t *testing.T) {
+	before := models.Patient{ID: 1, FirstName: "Pat", Phone: "555-0100"}
+	after := models.Patient{ID: 1, FirstName: "Patricia", Phone: "555-0199"}
+
+	raw, err := Diff(before, after)
+	if err != nil {
+		t.Fatalf("Diff: %v", err)
+	}
+	var changes map[string]Change
+	if err := json.Unmarshal(raw, &changes); err != nil {
+		t.Fatalf("decode changes: %v", err)
+	}
+
+	if got := changes["firstName"]; got.From != "Pat" || got.To != "Patricia" {
+		t.Errorf("firstName change = %+v, want Pat to Patricia", got)
+	}
+	if got := changes["phone"]; got.From != Redacted || got.To != Redacted {
+		t.Errorf("phone change = %+v, want it redacted", got)
+	}
+	if len(changes) != 2 {
+		t.Errorf("changes = %s, want firstName and phone only", raw)
+	}
+
+	if raw, err := Diff(before, before); err != nil || raw != nil {
+		t.Errorf("Diff of an unchanged record = %s, %v, want nil", raw, err)
+	}
+}
@@ -0,0 +1,101 @@
+// dental_backend/internal/captcha/captcha.go
+
+// Package captcha verifies the tokens captcha widgets add to public forms. It is
+// provider-agnostic: any service speaking the common siteverify protocol
+// (reCAPTCHA, hCaptcha, Cloudflare Turnstile) can be plugged in by URL.
+package captcha
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// ErrRejected is returned when the provider does not accept a token
+var ErrRejected = errors.New("captcha verification failed")
+
+// Verifier checks the captcha token submitted with a public request
+type Verifier interface {
+	Verify(ctx context.Context, token, remoteIP string) error
+}
+
+// Config selects the captcha provider
+type Config struct {
+	// VerifyURL is the provider's siteverify endpoint; empty disables captcha checks
+	VerifyURL string
+	Secret    string
+}
+
+// New returns the verifier for cfg, or one that accepts everything when no provider is configured
+func New(cfg Config, client *http.Client) Verifier {
+	if cfg.VerifyURL == "" {
+		return Disabled{}
+	}
+	return NewSiteVerifier(cfg.VerifyURL, cfg.Secret, client)
+}
+
+// Disabled accepts every request, for deployments without a captcha
+type Disabled struct{}
+
+// Verify always succeeds
+func (Disabled) Verify(ctx context.Context, token, remoteIP string) error {
+	return nil
+}
+
+// SiteVerifier posts tokens to a siteverify endpoint
+type SiteVerifier struct {
+	url    string
+	secret string
+	client *http.Client
+}
+
+// NewSiteVerifier creates a verifier for the given siteverify endpoint and secret
+func NewSiteVerifier(verifyURL, secret string, client *http.Client) *SiteVerifier {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	return &SiteVerifier{url: verifyURL, secret: secret, client: client}
+}
+
+// Verify returns ErrRejected for missing or refused tokens, or an error when the provider cannot be reached
+func (v *SiteVerifier) Verify(ctx context.Context, token, remoteIP string) error {
+	if token == "" {
+		return ErrRejected
+	}
+
+	form := url.Values{"secret": {v.secret}, "response": {token}}
+	if remoteIP != "" {
+		form.Set("remoteip", remoteIP)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, v.url, strings.NewReader(form.Encode()))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := v.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("captcha provider returned %s", resp.Status)
+	}
+
+	var result struct {
+		Success bool `json:"success"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return fmt.Errorf("decode captcha response: %w", err)
+	}
+	if !result.Success {
+		return ErrRejected
+	}
+	return nil
+}
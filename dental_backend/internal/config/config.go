@@ -0,0 +1,266 @@
+// dental_backend/internal/config/config.go
+package config
+
+import (
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"dental_backend/internal/captcha"
+	"dental_backend/internal/database"
+	"dental_backend/internal/idtoken"
+	"dental_backend/internal/notify"
+	"dental_backend/internal/oidc"
+)
+
+// Config holds the application configuration
+type Config struct {
+	// Addr is the address the HTTP server listens on
+	Addr string
+
+	// JWTSecret signs and verifies access tokens
+	JWTSecret string
+
+	// AccessTokenTTL is how long an access token is valid; clients renew it with
+	// their refresh token
+	AccessTokenTTL time.Duration
+
+	// RefreshTokenTTL is how long a session lasts without being refreshed
+	RefreshTokenTTL time.Duration
+
+	// LinkSecret signs patient self-service links; it defaults to JWTSecret
+	LinkSecret string
+
+	// PatientLinkURL is the page patients open from reminders; link tokens are
+	// appended to it. Reminders carry no link when it is empty.
+	PatientLinkURL string
+
+	// PublicAPIURL is the externally reachable base URL of this API, used to build
+	// calendar subscription URLs; they are relative when it is empty
+	PublicAPIURL string
+
+	// MLServiceURL is the base URL of the tooth analysis service
+	MLServiceURL string
+
+	// WaitlistHold is how long a freed slot is held for the top waitlist
+	// candidate; zero offers freed slots without holding them
+	WaitlistHold time.Duration
+
+	// ReminderOffsets are how long before an appointment reminders are sent
+	ReminderOffsets []time.Duration
+
+	// ReminderInterval is how often the reminder job looks for due reminders
+	ReminderInterval time.Duration
+
+	// RemindersInProcess runs the reminder and risk recompute jobs inside the
+	// API server instead of a separate cmd/worker process
+	RemindersInProcess bool
+
+	// RiskRecomputeInterval is how often every patient's derived risk is recomputed
+	RiskRecomputeInterval time.Duration
+
+	// Notify configures the email and SMS channels used to reach patients and users
+	Notify notify.Config
+
+	// AppURL is the base URL of the web app; password reset and email
+	// verification emails link to pages under it
+	AppURL string
+
+	// PasswordResetTTL and EmailVerificationTTL are how long emailed account
+	// links stay valid
+	PasswordResetTTL     time.Duration
+	EmailVerificationTTL time.Duration
+
+	// MFAIssuer names the service in authenticator apps
+	MFAIssuer string
+
+	// GoogleClientID is the OAuth client Google ID tokens must be issued to;
+	// empty disables Google sign-in
+	GoogleClientID string
+	// GoogleJWKSURL is where the keys Google signs ID tokens with are fetched from
+	GoogleJWKSURL string
+	// GoogleAutoLink links a Google account to the user with the same verified
+	// email address on its first sign-in; otherwise users link it once signed in
+	GoogleAutoLink bool
+
+	// OIDCProviders are the OpenID Connect providers users can sign in with
+	OIDCProviders []oidc.Config
+
+	// PublicRateLimit is how many requests per minute one client IP may make to
+	// the public endpoints; zero disables the limit
+	PublicRateLimit int
+
+	// BookingRateLimit is how many online bookings per hour one client IP may
+	// submit; zero disables the limit
+	BookingRateLimit int
+
+	// Captcha verifies online booking submissions; disabled when no URL is set
+	Captcha captcha.Config
+
+	// FieldKeysFile is the keyfile sensitive patient fields are encrypted with;
+	// they are stored in plaintext when it is empty
+	FieldKeysFile string
+
+	Database database.Config
+}
+
+// Load reads the application configuration from environment variables
+func Load() *Config {
+	jwtSecret := getEnv("JWT_SECRET", "dental_secret_key") // fallback for development
+
+	return &Config{
+		Addr:            getEnv("HTTP_ADDR", ":8080"),
+		JWTSecret:       jwtSecret,
+		AccessTokenTTL:  getEnvDuration("ACCESS_TOKEN_TTL", 15*time.Minute),
+		RefreshTokenTTL: getEnvDuration("REFRESH_TOKEN_TTL", 30*24*time.Hour),
+		LinkSecret:      getEnv("APPOINTMENT_LINK_SECRET", jwtSecret),
+		PatientLinkURL:  getEnv("PATIENT_LINK_URL", ""),
+		PublicAPIURL:    strings.TrimSuffix(getEnv("PUBLIC_API_URL", ""), "/"),
+		MLServiceURL:    getEnv("ML_SERVICE_URL", "http://localhost:8000"),
+		WaitlistHold:    time.Duration(getEnvInt("WAITLIST_HOLD_MINUTES", 0)) * time.Minute,
+
+		ReminderOffsets:    getEnvDurations("REMINDER_OFFSETS", []time.Duration{48 * time.Hour, 2 * time.Hour}),
+		ReminderInterval:   getEnvDuration("REMINDER_INTERVAL", time.Minute),
+		RemindersInProcess: getEnv("REMINDERS_IN_PROCESS", "false") == "true",
+
+		RiskRecomputeInterval: getEnvDuration("RISK_RECOMPUTE_INTERVAL", 24*time.Hour),
+
+		Notify: notify.Config{
+			EmailDriver: getEnv("NOTIFY_EMAIL", ""),
+			SMSDriver:   getEnv("NOTIFY_SMS", ""),
+			SMTP: notify.SMTPConfig{
+				Host:     getEnv("SMTP_HOST", "localhost"),
+				Port:     getEnv("SMTP_PORT", "587"),
+				Username: getEnv("SMTP_USERNAME", ""),
+				Password: getEnv("SMTP_PASSWORD", ""),
+				From:     getEnv("SMTP_FROM", "no-reply@localhost"),
+			},
+			SMSWebhookURL:   getEnv("SMS_WEBHOOK_URL", ""),
+			SMSWebhookToken: getEnv("SMS_WEBHOOK_TOKEN", ""),
+			LogFile:         getEnv("NOTIFY_LOG_FILE", ""),
+		},
+
+		AppURL:               strings.TrimSuffix(getEnv("APP_URL", "http://localhost:5173"), "/"),
+		PasswordResetTTL:     getEnvDuration("PASSWORD_RESET_TTL", time.Hour),
+		EmailVerificationTTL: getEnvDuration("EMAIL_VERIFICATION_TTL", 48*time.Hour),
+		MFAIssuer:            getEnv("MFA_ISSUER", "Dental Flow"),
+
+		GoogleClientID: getEnv("GOOGLE_CLIENT_ID", ""),
+		GoogleJWKSURL:  getEnv("GOOGLE_JWKS_URL", idtoken.GoogleJWKSURL),
+		GoogleAutoLink: getEnv("GOOGLE_AUTO_LINK", "false") == "true",
+		OIDCProviders:  getOIDCProviders(),
+
+		PublicRateLimit:  getEnvInt("PUBLIC_RATE_LIMIT", 60),
+		BookingRateLimit: getEnvInt("BOOKING_RATE_LIMIT", 5),
+		Captcha: captcha.Config{
+			VerifyURL: getEnv("CAPTCHA_VERIFY_URL", ""),
+			Secret:    getEnv("CAPTCHA_SECRET", ""),
+		},
+
+		FieldKeysFile: getEnv("FIELD_KEYS_FILE", ""),
+
+		Database: database.Config{
+			Host:     getEnv("DB_HOST", "localhost"),
+			Port:     getEnv("DB_PORT", "5432"),
+			User:     getEnv("DB_USER", "sittminthar"),
+			Password: getEnv("DB_PASSWORD", ""),
+			DBName:   getEnv("DB_NAME", "dental_scheduler"),
+			SSLMode:  getEnv("DB_SSL_MODE", "disable"),
+
+			MigrateUser:     getEnv("DB_MIGRATE_USER", getEnv("DB_USER", "sittminthar")),
+			MigratePassword: getEnv("DB_MIGRATE_PASSWORD", getEnv("DB_PASSWORD", "")),
+
+			AutoMigrate: getEnv("DB_AUTO_MIGRATE", "false") == "true",
+		},
+	}
+}
+
+// getOIDCProviders reads the providers named in OIDC_PROVIDERS, such as "entra,keycloak",
+// each configured by variables prefixed with OIDC_<NAME>_
+func getOIDCProviders() []oidc.Config {
+	var providers []oidc.Config
+	for _, name := range strings.Split(os.Getenv("OIDC_PROVIDERS"), ",") {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name == "" {
+			continue
+		}
+		prefix := "OIDC_" + strings.ToUpper(strings.ReplaceAll(name, "-", "_")) + "_"
+
+		providers = append(providers, oidc.Config{
+			Name:         name,
+			DisplayName:  getEnv(prefix+"DISPLAY_NAME", name),
+			Issuer:       os.Getenv(prefix + "ISSUER"),
+			ClientID:     os.Getenv(prefix + "CLIENT_ID"),
+			ClientSecret: os.Getenv(prefix + "CLIENT_SECRET"),
+			Scopes:       strings.Fields(strings.ReplaceAll(getEnv(prefix+"SCOPES", "openid email profile"), ",", " ")),
+			RedirectURL:  os.Getenv(prefix + "REDIRECT_URL"),
+			EmailClaim:   getEnv(prefix+"EMAIL_CLAIM", "email"),
+			GroupsClaim:  getEnv(prefix+"GROUPS_CLAIM", "groups"),
+			RoleMap:      getEnvRoleMap(prefix + "ROLE_MAP"),
+			DefaultRole:  os.Getenv(prefix + "DEFAULT_ROLE"),
+			TrustEmail:   getEnv(prefix+"TRUST_EMAIL", "false") == "true",
+			AutoLink:     getEnv(prefix+"AUTO_LINK", "false") == "true",
+		})
+	}
+	return providers
+}
+
+// getEnvRoleMap reads group to role mappings such as "dental-admins=admin,dentists=dentist"
+func getEnvRoleMap(key string) []oidc.RoleMapping {
+	var mappings []oidc.RoleMapping
+	for _, part := range strings.Split(os.Getenv(key), ",") {
+		group, role, ok := strings.Cut(part, "=")
+		if !ok {
+			continue
+		}
+		mappings = append(mappings, oidc.RoleMapping{Group: strings.TrimSpace(group), Role: strings.TrimSpace(role)})
+	}
+	return mappings
+}
+
+// getEnvInt returns an integer environment variable or a default value when it is unset or invalid
+func getEnvInt(key string, defaultValue int) int {
+	value, err := strconv.Atoi(os.Getenv(key))
+	if err != nil {
+		return defaultValue
+	}
+	return value
+}
+
+// getEnvDuration returns a duration environment variable such as "90s" or a default
+// value when it is unset or invalid
+func getEnvDuration(key string, defaultValue time.Duration) time.Duration {
+	value, err := time.ParseDuration(os.Getenv(key))
+	if err != nil || value <= 0 {
+		return defaultValue
+	}
+	return value
+}
+
+// getEnvDurations returns a comma-separated list of durations such as "48h,2h" or a
+// default value when it is unset or any entry is invalid
+func getEnvDurations(key string, defaultValue []time.Duration) []time.Duration {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue
+	}
+
+	var durations []time.Duration
+	for _, part := range strings.Split(value, ",") {
+		d, err := time.ParseDuration(strings.TrimSpace(part))
+		if err != nil || d <= 0 {
+			return defaultValue
+		}
+		durations = append(durations, d)
+	}
+	return durations
+}
+
+// getEnv returns the value of an environment variable or a default value
+func getEnv(key, defaultValue string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return defaultValue
+}
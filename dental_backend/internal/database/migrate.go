@@ -0,0 +1,391 @@
+// dental_backend/internal/database/migrate.go
+package database
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"embed"
+	"fmt"
+	"io/fs"
+	"log"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+//go:embed migrations/*.sql
+var migrationFiles embed.FS
+
+// migrationLockID is the advisory lock key held while migrations run so that
+// two processes never apply the same version concurrently
+const migrationLockID = 482311907
+
+// Migration is a single versioned schema change
+type Migration struct {
+	Version int
+	Name    string
+	UpSQL   string
+	DownSQL string
+}
+
+// MigrationStatus describes whether a migration has been applied
+type MigrationStatus struct {
+	Version   int        `json:"version"`
+	Name      string     `json:"name"`
+	Applied   bool       `json:"applied"`
+	AppliedAt *time.Time `json:"appliedAt"`
+}
+
+// Migrator applies the embedded migrations to a database
+type Migrator struct {
+	db         *sql.DB
+	migrations []Migration
+}
+
+// NewMigrator creates a migrator for the embedded migration set
+func NewMigrator(db *sql.DB) (*Migrator, error) {
+	migrations, err := loadMigrations(migrationFiles)
+	if err != nil {
+		return nil, err
+	}
+	return &Migrator{db: db, migrations: migrations}, nil
+}
+
+// loadMigrations reads NNNN_name.up.sql / NNNN_name.down.sql pairs in version order
+func loadMigrations(fsys fs.FS) ([]Migration, error) {
+	entries, err := fs.ReadDir(fsys, "migrations")
+	if err != nil {
+		return nil, fmt.Errorf("failed to read migrations: %w", err)
+	}
+
+	byVersion := map[int]*Migration{}
+	for _, entry := range entries {
+		fileName := entry.Name()
+
+		var direction string
+		switch {
+		case strings.HasSuffix(fileName, ".up.sql"):
+			direction = "up"
+		case strings.HasSuffix(fileName, ".down.sql"):
+			direction = "down"
+		default:
+			continue
+		}
+
+		base := strings.TrimSuffix(fileName, "."+direction+".sql")
+		versionPart, name, found := strings.Cut(base, "_")
+		if !found {
+			return nil, fmt.Errorf("invalid migration file name %q", fileName)
+		}
+		version, err := strconv.Atoi(versionPart)
+		if err != nil {
+			return nil, fmt.Errorf("invalid migration version in %q: %w", fileName, err)
+		}
+
+		contents, err := fs.ReadFile(fsys, path.Join("migrations", fileName))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read migration %q: %w", fileName, err)
+		}
+
+		m, ok := byVersion[version]
+		if !ok {
+			m = &Migration{Version: version, Name: name}
+			byVersion[version] = m
+		} else if m.Name != name {
+			return nil, fmt.Errorf("migration version %d has conflicting names %q and %q", version, m.Name, name)
+		}
+
+		if direction == "up" {
+			m.UpSQL = string(contents)
+		} else {
+			m.DownSQL = string(contents)
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, m := range byVersion {
+		if m.UpSQL == "" {
+			return nil, fmt.Errorf("migration %04d_%s is missing its up file", m.Version, m.Name)
+		}
+		migrations = append(migrations, *m)
+	}
+	sort.Slice(migrations, func(i, j int) bool { return migrations[i].Version < migrations[j].Version })
+
+	return migrations, nil
+}
+
+// ensureVersionTable creates the table that records applied versions. It is only
+// created when missing, so that users who may not create tables can check the schema.
+func (m *Migrator) ensureVersionTable(ctx context.Context, conn *sql.Conn) error {
+	var exists bool
+	if err := conn.QueryRowContext(ctx, "SELECT to_regclass('schema_migrations') IS NOT NULL").Scan(&exists); err != nil {
+		return fmt.Errorf("failed to check for schema_migrations table: %w", err)
+	}
+	if exists {
+		return nil
+	}
+
+	_, err := conn.ExecContext(ctx, `
+		CREATE TABLE IF NOT EXISTS schema_migrations (
+			version    INTEGER PRIMARY KEY,
+			name       VARCHAR(255) NOT NULL,
+			applied_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+		)`)
+	if err != nil {
+		return fmt.Errorf("failed to create schema_migrations table: %w", err)
+	}
+	return nil
+}
+
+// appliedVersions returns the applied versions and when they were applied
+func appliedVersions(ctx context.Context, q interface {
+	QueryContext(context.Context, string, ...interface{}) (*sql.Rows, error)
+}) (map[int]time.Time, error) {
+	rows, err := q.QueryContext(ctx, "SELECT version, applied_at FROM schema_migrations")
+	if err != nil {
+		return nil, fmt.Errorf("failed to read schema_migrations: %w", err)
+	}
+	defer rows.Close()
+
+	applied := map[int]time.Time{}
+	for rows.Next() {
+		var version int
+		var appliedAt time.Time
+		if err := rows.Scan(&version, &appliedAt); err != nil {
+			return nil, err
+		}
+		applied[version] = appliedAt
+	}
+	return applied, rows.Err()
+}
+
+// withLock runs fn on a dedicated connection holding the migration advisory lock.
+// The connection sees every clinic's rows, so that migrations can rewrite them.
+func (m *Migrator) withLock(ctx context.Context, fn func(conn *sql.Conn) error) error {
+	conn, err := m.db.Conn(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to acquire connection: %w", err)
+	}
+	defer conn.Close()
+
+	if _, err := conn.ExecContext(ctx, "SELECT set_config('app.all_clinics', 'on', false)"); err != nil {
+		return fmt.Errorf("failed to open every clinic to migrations: %w", err)
+	}
+	defer func() {
+		if _, err := conn.ExecContext(context.Background(), "SELECT set_config('app.all_clinics', '', false)"); err != nil {
+			log.Printf("Error resetting migration connection, discarding it: %v", err)
+			conn.Raw(func(interface{}) error { return driver.ErrBadConn })
+		}
+	}()
+
+	if _, err := conn.ExecContext(ctx, "SELECT pg_advisory_lock($1)", migrationLockID); err != nil {
+		return fmt.Errorf("failed to acquire migration lock: %w", err)
+	}
+	defer func() {
+		if _, err := conn.ExecContext(context.Background(), "SELECT pg_advisory_unlock($1)", migrationLockID); err != nil {
+			log.Printf("Error releasing migration lock: %v", err)
+		}
+	}()
+
+	if err := m.ensureVersionTable(ctx, conn); err != nil {
+		return err
+	}
+
+	return fn(conn)
+}
+
+// apply runs one migration in a transaction and records or removes its version
+func (m *Migrator) apply(ctx context.Context, conn *sql.Conn, migration Migration, up bool) error {
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	script := migration.UpSQL
+	if !up {
+		script = migration.DownSQL
+	}
+
+	if _, err := tx.ExecContext(ctx, script); err != nil {
+		return fmt.Errorf("migration %04d_%s failed: %w", migration.Version, migration.Name, err)
+	}
+
+	if up {
+		_, err = tx.ExecContext(ctx, "INSERT INTO schema_migrations (version, name) VALUES ($1, $2)", migration.Version, migration.Name)
+	} else {
+		_, err = tx.ExecContext(ctx, "DELETE FROM schema_migrations WHERE version = $1", migration.Version)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to record migration %04d_%s: %w", migration.Version, migration.Name, err)
+	}
+
+	return tx.Commit()
+}
+
+// Up applies every pending migration in version order
+func (m *Migrator) Up(ctx context.Context) ([]Migration, error) {
+	var applied []Migration
+	err := m.withLock(ctx, func(conn *sql.Conn) error {
+		versions, err := appliedVersions(ctx, conn)
+		if err != nil {
+			return err
+		}
+
+		for _, migration := range m.migrations {
+			if _, ok := versions[migration.Version]; ok {
+				continue
+			}
+			if err := m.apply(ctx, conn, migration, true); err != nil {
+				return err
+			}
+			applied = append(applied, migration)
+		}
+		return nil
+	})
+	return applied, err
+}
+
+// Down rolls back the given number of most recently applied migrations
+func (m *Migrator) Down(ctx context.Context, steps int) ([]Migration, error) {
+	var rolledBack []Migration
+	err := m.withLock(ctx, func(conn *sql.Conn) error {
+		var err error
+		rolledBack, err = m.down(ctx, conn, steps)
+		return err
+	})
+	return rolledBack, err
+}
+
+// down rolls back up to steps applied migrations on a locked connection
+func (m *Migrator) down(ctx context.Context, conn *sql.Conn, steps int) ([]Migration, error) {
+	versions, err := appliedVersions(ctx, conn)
+	if err != nil {
+		return nil, err
+	}
+
+	var rolledBack []Migration
+	for i := len(m.migrations) - 1; i >= 0 && len(rolledBack) < steps; i-- {
+		migration := m.migrations[i]
+		if _, ok := versions[migration.Version]; !ok {
+			continue
+		}
+		if migration.DownSQL == "" {
+			return rolledBack, fmt.Errorf("migration %04d_%s has no down file", migration.Version, migration.Name)
+		}
+		if err := m.apply(ctx, conn, migration, false); err != nil {
+			return rolledBack, err
+		}
+		rolledBack = append(rolledBack, migration)
+	}
+	return rolledBack, nil
+}
+
+// Redo rolls back the most recently applied migration and applies it again
+func (m *Migrator) Redo(ctx context.Context) (*Migration, error) {
+	var redone *Migration
+	err := m.withLock(ctx, func(conn *sql.Conn) error {
+		rolledBack, err := m.down(ctx, conn, 1)
+		if err != nil {
+			return err
+		}
+		if len(rolledBack) == 0 {
+			return fmt.Errorf("no applied migrations to redo")
+		}
+
+		redone = &rolledBack[0]
+		return m.apply(ctx, conn, *redone, true)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return redone, nil
+}
+
+// Status reports every known migration and whether it has been applied
+func (m *Migrator) Status(ctx context.Context) ([]MigrationStatus, error) {
+	var statuses []MigrationStatus
+	err := m.withLock(ctx, func(conn *sql.Conn) error {
+		versions, err := appliedVersions(ctx, conn)
+		if err != nil {
+			return err
+		}
+
+		for _, migration := range m.migrations {
+			status := MigrationStatus{Version: migration.Version, Name: migration.Name}
+			if appliedAt, ok := versions[migration.Version]; ok {
+				appliedAt := appliedAt
+				status.Applied = true
+				status.AppliedAt = &appliedAt
+			}
+			statuses = append(statuses, status)
+		}
+		return nil
+	})
+	return statuses, err
+}
+
+// Pending returns the migrations that have not been applied yet
+func (m *Migrator) Pending(ctx context.Context) ([]Migration, error) {
+	statuses, err := m.Status(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var pending []Migration
+	for i, status := range statuses {
+		if !status.Applied {
+			pending = append(pending, m.migrations[i])
+		}
+	}
+	return pending, nil
+}
+
+// EnsureSchema checks that the database schema is current. When the configuration
+// enables AutoMigrate pending migrations are applied as its MigrateUser,
+// otherwise an error lists what is missing.
+func EnsureSchema(ctx context.Context, db *sql.DB, config *Config) error {
+	migrator, err := NewMigrator(db)
+	if err != nil {
+		return err
+	}
+
+	pending, err := migrator.Pending(ctx)
+	if err != nil {
+		return err
+	}
+	if len(pending) == 0 {
+		return nil
+	}
+
+	if !config.AutoMigrate {
+		names := make([]string, len(pending))
+		for i, migration := range pending {
+			names[i] = fmt.Sprintf("%04d_%s", migration.Version, migration.Name)
+		}
+		return fmt.Errorf("database schema is behind by %d migration(s): %s; run `go run ./cmd/migrate up` or set DB_AUTO_MIGRATE=true",
+			len(pending), strings.Join(names, ", "))
+	}
+
+	// The application user does not own the schema; migrate as the user who does
+	if config.MigrateUser != config.User {
+		owner, err := Open(config.Migrator())
+		if err != nil {
+			return err
+		}
+		defer owner.Close()
+		migrator.db = owner
+	}
+
+	applied, err := migrator.Up(ctx)
+	if err != nil {
+		return err
+	}
+	for _, migration := range applied {
+		log.Printf("Applied migration %04d_%s", migration.Version, migration.Name)
+	}
+	return nil
+}
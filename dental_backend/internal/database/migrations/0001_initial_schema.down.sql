@@ -0,0 +1,9 @@
+-- 0001_initial_schema.down.sql
+
+DROP TABLE IF EXISTS insurance_claims;
+DROP TABLE IF EXISTS invoices;
+DROP TABLE IF EXISTS appointments;
+DROP TABLE IF EXISTS patient_treatments;
+DROP TABLE IF EXISTS treatments;
+DROP TABLE IF EXISTS patients;
+DROP TABLE IF EXISTS users;
@@ -0,0 +1,107 @@
+-- 0001_initial_schema.up.sql
+-- Baseline schema for the tables the services already depend on.
+
+CREATE TABLE IF NOT EXISTS users (
+    id            SERIAL PRIMARY KEY,
+    email         VARCHAR(255) NOT NULL UNIQUE,
+    password_hash VARCHAR(255) NOT NULL DEFAULT '',
+    first_name    VARCHAR(100) NOT NULL,
+    last_name     VARCHAR(100) NOT NULL,
+    role          VARCHAR(20)  NOT NULL CHECK (role IN ('dentist', 'hygienist', 'admin', 'staff')),
+    phone         VARCHAR(50),
+    google_id     VARCHAR(255) UNIQUE,
+    created_at    TIMESTAMPTZ  NOT NULL DEFAULT NOW(),
+    updated_at    TIMESTAMPTZ  NOT NULL DEFAULT NOW()
+);
+
+CREATE TABLE IF NOT EXISTS patients (
+    id                      SERIAL PRIMARY KEY,
+    first_name              VARCHAR(100) NOT NULL,
+    last_name               VARCHAR(100) NOT NULL,
+    date_of_birth           DATE         NOT NULL,
+    phone                   VARCHAR(50)  NOT NULL,
+    email                   VARCHAR(255) NOT NULL,
+    address                 TEXT         NOT NULL DEFAULT '',
+    emergency_contact       TEXT         NOT NULL DEFAULT '',
+    insurance_provider      VARCHAR(255) NOT NULL DEFAULT '',
+    insurance_policy_number VARCHAR(100) NOT NULL DEFAULT '',
+    medical_history         TEXT         NOT NULL DEFAULT '',
+    risk_level              VARCHAR(10)  NOT NULL DEFAULT 'low' CHECK (risk_level IN ('low', 'medium', 'high')),
+    created_at              TIMESTAMPTZ  NOT NULL DEFAULT NOW(),
+    updated_at              TIMESTAMPTZ  NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_patients_last_name ON patients (last_name);
+CREATE INDEX IF NOT EXISTS idx_patients_email ON patients (email);
+
+CREATE TABLE IF NOT EXISTS treatments (
+    id               SERIAL PRIMARY KEY,
+    name             VARCHAR(255)  NOT NULL,
+    description      TEXT          NOT NULL DEFAULT '',
+    cost             NUMERIC(10,2) NOT NULL CHECK (cost >= 0),
+    duration_minutes INTEGER       NOT NULL CHECK (duration_minutes > 0),
+    category         VARCHAR(50)   NOT NULL DEFAULT 'General'
+);
+
+CREATE TABLE IF NOT EXISTS patient_treatments (
+    id              SERIAL PRIMARY KEY,
+    patient_id      INTEGER     NOT NULL REFERENCES patients (id) ON DELETE CASCADE,
+    treatment_id    INTEGER     NOT NULL REFERENCES treatments (id) ON DELETE RESTRICT,
+    dentist_id      INTEGER     REFERENCES users (id) ON DELETE SET NULL,
+    status          VARCHAR(20) NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'in-progress', 'completed')),
+    priority        VARCHAR(10) NOT NULL DEFAULT 'normal' CHECK (priority IN ('low', 'normal', 'high', 'urgent')),
+    start_date      DATE        NOT NULL,
+    completion_date DATE,
+    notes           TEXT        NOT NULL DEFAULT '',
+    created_at      TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at      TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_patient_treatments_patient ON patient_treatments (patient_id);
+CREATE INDEX IF NOT EXISTS idx_patient_treatments_status ON patient_treatments (status);
+
+CREATE TABLE IF NOT EXISTS appointments (
+    id               SERIAL PRIMARY KEY,
+    patient_id       INTEGER     NOT NULL REFERENCES patients (id) ON DELETE CASCADE,
+    dentist_id       INTEGER     NOT NULL REFERENCES users (id) ON DELETE RESTRICT,
+    appointment_date DATE        NOT NULL,
+    start_time       TIME        NOT NULL,
+    status           VARCHAR(20) NOT NULL DEFAULT 'scheduled' CHECK (status IN ('scheduled', 'completed', 'cancelled', 'no-show')),
+    notes            TEXT        NOT NULL DEFAULT '',
+    created_at       TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at       TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_appointments_dentist_date ON appointments (dentist_id, appointment_date);
+CREATE INDEX IF NOT EXISTS idx_appointments_patient ON appointments (patient_id);
+
+CREATE TABLE IF NOT EXISTS invoices (
+    id             SERIAL PRIMARY KEY,
+    patient_id     INTEGER       NOT NULL REFERENCES patients (id) ON DELETE CASCADE,
+    amount         NUMERIC(10,2) NOT NULL CHECK (amount >= 0),
+    status         VARCHAR(20)   NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'paid', 'overdue')),
+    due_date       DATE          NOT NULL,
+    issued_date    DATE          NOT NULL DEFAULT CURRENT_DATE,
+    payment_method VARCHAR(50)   NOT NULL DEFAULT '',
+    notes          TEXT          NOT NULL DEFAULT '',
+    created_at     TIMESTAMPTZ   NOT NULL DEFAULT NOW(),
+    updated_at     TIMESTAMPTZ   NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_invoices_patient ON invoices (patient_id);
+CREATE INDEX IF NOT EXISTS idx_invoices_status ON invoices (status);
+
+CREATE TABLE IF NOT EXISTS insurance_claims (
+    id              SERIAL PRIMARY KEY,
+    patient_id      INTEGER       NOT NULL REFERENCES patients (id) ON DELETE CASCADE,
+    treatment_id    INTEGER       REFERENCES treatments (id) ON DELETE SET NULL,
+    claim_amount    NUMERIC(10,2) NOT NULL CHECK (claim_amount >= 0),
+    status          VARCHAR(20)   NOT NULL DEFAULT 'submitted',
+    submission_date DATE          NOT NULL DEFAULT CURRENT_DATE,
+    approval_date   DATE,
+    notes           TEXT          NOT NULL DEFAULT '',
+    created_at      TIMESTAMPTZ   NOT NULL DEFAULT NOW(),
+    updated_at      TIMESTAMPTZ   NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_insurance_claims_patient ON insurance_claims (patient_id);
@@ -0,0 +1,9 @@
+-- 0002_appointment_duration.down.sql
+
+ALTER TABLE appointments DROP CONSTRAINT IF EXISTS appointments_no_overlap;
+ALTER TABLE appointments DROP CONSTRAINT IF EXISTS appointments_same_day;
+
+ALTER TABLE appointments
+    DROP COLUMN IF EXISTS end_time,
+    DROP COLUMN IF EXISTS duration_minutes,
+    DROP COLUMN IF EXISTS treatment_id;
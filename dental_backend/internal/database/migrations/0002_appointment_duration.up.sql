@@ -0,0 +1,20 @@
+-- 0002_appointment_duration.up.sql
+-- Appointments occupy a time range; the exclusion constraint stops two active
+-- bookings for the same dentist from overlapping, even under concurrent writes.
+
+CREATE EXTENSION IF NOT EXISTS btree_gist;
+
+ALTER TABLE appointments
+    ADD COLUMN treatment_id     INTEGER REFERENCES treatments (id) ON DELETE SET NULL,
+    ADD COLUMN duration_minutes INTEGER NOT NULL DEFAULT 30 CHECK (duration_minutes > 0),
+    ADD COLUMN end_time         TIME GENERATED ALWAYS AS (start_time + make_interval(mins => duration_minutes)) STORED;
+
+-- An appointment may not run past midnight
+ALTER TABLE appointments
+    ADD CONSTRAINT appointments_same_day CHECK (end_time > start_time);
+
+ALTER TABLE appointments
+    ADD CONSTRAINT appointments_no_overlap EXCLUDE USING gist (
+        dentist_id WITH =,
+        tsrange(appointment_date + start_time, appointment_date + end_time) WITH &&
+    ) WHERE (status NOT IN ('cancelled', 'no-show'));
@@ -0,0 +1,5 @@
+-- 0003_dentist_schedules.down.sql
+
+DROP TABLE IF EXISTS schedule_closures;
+DROP TABLE IF EXISTS dentist_breaks;
+DROP TABLE IF EXISTS dentist_working_hours;
@@ -0,0 +1,38 @@
+-- 0003_dentist_schedules.up.sql
+-- Weekly working hours and breaks per dentist, plus one-off closures. A closure
+-- without a dentist applies to the whole clinic.
+
+CREATE TABLE IF NOT EXISTS dentist_working_hours (
+    id         SERIAL PRIMARY KEY,
+    dentist_id INTEGER  NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    weekday    SMALLINT NOT NULL CHECK (weekday BETWEEN 0 AND 6),
+    start_time TIME     NOT NULL,
+    end_time   TIME     NOT NULL,
+    CHECK (end_time > start_time)
+);
+
+CREATE INDEX IF NOT EXISTS idx_dentist_working_hours_dentist ON dentist_working_hours (dentist_id, weekday);
+
+CREATE TABLE IF NOT EXISTS dentist_breaks (
+    id         SERIAL PRIMARY KEY,
+    dentist_id INTEGER      NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    weekday    SMALLINT     NOT NULL CHECK (weekday BETWEEN 0 AND 6),
+    start_time TIME         NOT NULL,
+    end_time   TIME         NOT NULL,
+    label      VARCHAR(100) NOT NULL DEFAULT '',
+    CHECK (end_time > start_time)
+);
+
+CREATE INDEX IF NOT EXISTS idx_dentist_breaks_dentist ON dentist_breaks (dentist_id, weekday);
+
+CREATE TABLE IF NOT EXISTS schedule_closures (
+    id         SERIAL PRIMARY KEY,
+    dentist_id INTEGER     REFERENCES users (id) ON DELETE CASCADE,
+    start_date DATE        NOT NULL,
+    end_date   DATE        NOT NULL,
+    reason     TEXT        NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CHECK (end_date >= start_date)
+);
+
+CREATE INDEX IF NOT EXISTS idx_schedule_closures_dates ON schedule_closures (start_date, end_date);
@@ -0,0 +1,27 @@
+-- 0004_appointment_series.up.sql
+-- Recurring series are expanded into ordinary appointment rows up front; the
+-- series row keeps the rule and template the occurrences were created from.
+
+CREATE TABLE IF NOT EXISTS appointment_series (
+    id               SERIAL PRIMARY KEY,
+    dentist_id       INTEGER     NOT NULL REFERENCES users (id) ON DELETE RESTRICT,
+    patient_id       INTEGER     NOT NULL REFERENCES patients (id) ON DELETE CASCADE,
+    treatment_id     INTEGER     REFERENCES treatments (id) ON DELETE SET NULL,
+    start_date       DATE        NOT NULL,
+    start_time       TIME        NOT NULL,
+    duration_minutes INTEGER     NOT NULL CHECK (duration_minutes > 0),
+    notes            TEXT        NOT NULL DEFAULT '',
+    frequency        VARCHAR(10) NOT NULL CHECK (frequency IN ('daily', 'weekly', 'monthly')),
+    "interval"       INTEGER     NOT NULL DEFAULT 1 CHECK ("interval" > 0),
+    count            INTEGER     CHECK (count > 0),
+    until            DATE,
+    weekday          SMALLINT    CHECK (weekday BETWEEN 0 AND 6),
+    created_at       TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at       TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CHECK (count IS NOT NULL OR until IS NOT NULL)
+);
+
+ALTER TABLE appointments
+    ADD COLUMN series_id INTEGER REFERENCES appointment_series (id) ON DELETE SET NULL;
+
+CREATE INDEX IF NOT EXISTS idx_appointments_series ON appointments (series_id, appointment_date);
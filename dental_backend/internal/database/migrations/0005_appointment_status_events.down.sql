@@ -0,0 +1,19 @@
+-- 0005_appointment_status_events.down.sql
+
+DROP TABLE IF EXISTS appointment_events;
+
+ALTER TABLE appointments
+    DROP COLUMN IF EXISTS no_show_at,
+    DROP COLUMN IF EXISTS cancelled_at,
+    DROP COLUMN IF EXISTS completed_at,
+    DROP COLUMN IF EXISTS in_chair_at,
+    DROP COLUMN IF EXISTS checked_in_at,
+    DROP COLUMN IF EXISTS confirmed_at,
+    DROP COLUMN IF EXISTS cancellation_reason;
+
+-- Fold the front-desk statuses back into the original set
+UPDATE appointments SET status = 'scheduled' WHERE status IN ('confirmed', 'checked-in', 'in-chair');
+
+ALTER TABLE appointments DROP CONSTRAINT IF EXISTS appointments_status_check;
+ALTER TABLE appointments
+    ADD CONSTRAINT appointments_status_check CHECK (status IN ('scheduled', 'completed', 'cancelled', 'no-show'));
@@ -0,0 +1,34 @@
+-- 0005_appointment_status_events.up.sql
+-- Adds the front-desk statuses, a timestamp per status and an event log of
+-- every status change.
+
+ALTER TABLE appointments DROP CONSTRAINT IF EXISTS appointments_status_check;
+ALTER TABLE appointments
+    ADD CONSTRAINT appointments_status_check CHECK (status IN (
+        'scheduled', 'confirmed', 'checked-in', 'in-chair', 'completed', 'cancelled', 'no-show'
+    ));
+
+ALTER TABLE appointments
+    ADD COLUMN cancellation_reason TEXT NOT NULL DEFAULT '',
+    ADD COLUMN confirmed_at        TIMESTAMPTZ,
+    ADD COLUMN checked_in_at       TIMESTAMPTZ,
+    ADD COLUMN in_chair_at         TIMESTAMPTZ,
+    ADD COLUMN completed_at        TIMESTAMPTZ,
+    ADD COLUMN cancelled_at        TIMESTAMPTZ,
+    ADD COLUMN no_show_at          TIMESTAMPTZ;
+
+CREATE TABLE IF NOT EXISTS appointment_events (
+    id             SERIAL PRIMARY KEY,
+    appointment_id INTEGER     NOT NULL REFERENCES appointments (id) ON DELETE CASCADE,
+    from_status    VARCHAR(20) NOT NULL DEFAULT '',
+    to_status      VARCHAR(20) NOT NULL,
+    reason         TEXT        NOT NULL DEFAULT '',
+    actor_id       INTEGER     REFERENCES users (id) ON DELETE SET NULL,
+    created_at     TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_appointment_events_appointment ON appointment_events (appointment_id, created_at);
+
+-- Existing appointments start their history with their current status
+INSERT INTO appointment_events (appointment_id, from_status, to_status, created_at)
+SELECT id, '', status, created_at FROM appointments;
@@ -0,0 +1,4 @@
+-- 0006_waitlist.down.sql
+
+DROP TABLE IF EXISTS slot_openings;
+DROP TABLE IF EXISTS waitlist_entries;
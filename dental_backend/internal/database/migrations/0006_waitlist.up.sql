@@ -0,0 +1,39 @@
+-- 0006_waitlist.up.sql
+-- Patients waiting for an earlier appointment, and the slots freed by
+-- cancellations that can be offered to them.
+
+CREATE TABLE IF NOT EXISTS waitlist_entries (
+    id               SERIAL PRIMARY KEY,
+    patient_id       INTEGER     NOT NULL REFERENCES patients (id) ON DELETE CASCADE,
+    dentist_ids      INTEGER[]   NOT NULL DEFAULT '{}', -- empty means any dentist
+    weekdays         SMALLINT[]  NOT NULL DEFAULT '{}', -- empty means any day, 0 = Sunday
+    earliest_time    TIME,
+    latest_time      TIME,
+    duration_minutes INTEGER     NOT NULL DEFAULT 30 CHECK (duration_minutes > 0),
+    treatment_id     INTEGER     REFERENCES treatments (id) ON DELETE SET NULL,
+    urgency          VARCHAR(10) NOT NULL DEFAULT 'medium' CHECK (urgency IN ('low', 'medium', 'high', 'urgent')),
+    status           VARCHAR(10) NOT NULL DEFAULT 'waiting' CHECK (status IN ('waiting', 'booked', 'removed')),
+    notes            TEXT        NOT NULL DEFAULT '',
+    created_at       TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at       TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CHECK (earliest_time IS NULL OR latest_time IS NULL OR earliest_time < latest_time)
+);
+
+CREATE INDEX IF NOT EXISTS idx_waitlist_entries_status ON waitlist_entries (status, created_at);
+
+CREATE TABLE IF NOT EXISTS slot_openings (
+    id               SERIAL PRIMARY KEY,
+    dentist_id       INTEGER     NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    appointment_date DATE        NOT NULL,
+    start_time       TIME        NOT NULL,
+    end_time         TIME        NOT NULL,
+    appointment_id   INTEGER     REFERENCES appointments (id) ON DELETE SET NULL,
+    status           VARCHAR(10) NOT NULL DEFAULT 'open' CHECK (status IN ('open', 'held', 'filled')),
+    held_entry_id    INTEGER     REFERENCES waitlist_entries (id) ON DELETE SET NULL,
+    hold_expires_at  TIMESTAMPTZ,
+    created_at       TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at       TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CHECK (start_time < end_time)
+);
+
+CREATE INDEX IF NOT EXISTS idx_slot_openings_dentist_date ON slot_openings (dentist_id, appointment_date);
@@ -0,0 +1,3 @@
+-- 0007_appointment_reminders.down.sql
+
+DROP TABLE IF EXISTS reminder_deliveries;
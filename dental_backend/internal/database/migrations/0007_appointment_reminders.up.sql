@@ -0,0 +1,21 @@
+-- 0007_appointment_reminders.up.sql
+-- One row per reminder sent, or being sent, for an appointment. The unique key
+-- includes the appointment's date and start time so a rescheduled appointment
+-- gets fresh reminders while a restarted worker never sends the same one twice.
+
+CREATE TABLE IF NOT EXISTS reminder_deliveries (
+    id               SERIAL PRIMARY KEY,
+    appointment_id   INTEGER      NOT NULL REFERENCES appointments (id) ON DELETE CASCADE,
+    appointment_date DATE         NOT NULL,
+    start_time       TIME         NOT NULL,
+    offset_minutes   INTEGER      NOT NULL CHECK (offset_minutes > 0),
+    channel          VARCHAR(10)  NOT NULL CHECK (channel IN ('email', 'sms')),
+    recipient        VARCHAR(255) NOT NULL,
+    status           VARCHAR(10)  NOT NULL DEFAULT 'sending' CHECK (status IN ('sending', 'sent', 'failed')),
+    attempts         INTEGER      NOT NULL DEFAULT 1,
+    error            TEXT         NOT NULL DEFAULT '',
+    sent_at          TIMESTAMPTZ,
+    created_at       TIMESTAMPTZ  NOT NULL DEFAULT NOW(),
+    updated_at       TIMESTAMPTZ  NOT NULL DEFAULT NOW(),
+    UNIQUE (appointment_id, appointment_date, start_time, offset_minutes, channel)
+);
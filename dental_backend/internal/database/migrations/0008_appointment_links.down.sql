@@ -0,0 +1,5 @@
+-- 0008_appointment_links.down.sql
+
+DROP TABLE IF EXISTS appointment_link_uses;
+
+ALTER TABLE appointment_events DROP COLUMN IF EXISTS actor_type;
@@ -0,0 +1,15 @@
+-- 0008_appointment_links.up.sql
+-- Records who made each status change, and the self-service link tokens
+-- patients have already used so that each link works only once.
+
+ALTER TABLE appointment_events
+    ADD COLUMN actor_type VARCHAR(10) NOT NULL DEFAULT 'system' CHECK (actor_type IN ('user', 'patient', 'system'));
+
+UPDATE appointment_events SET actor_type = 'user' WHERE actor_id IS NOT NULL;
+
+CREATE TABLE IF NOT EXISTS appointment_link_uses (
+    token_id       VARCHAR(64) PRIMARY KEY,
+    appointment_id INTEGER     NOT NULL REFERENCES appointments (id) ON DELETE CASCADE,
+    action         VARCHAR(20) NOT NULL,
+    used_at        TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
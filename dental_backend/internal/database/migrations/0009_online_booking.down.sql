@@ -0,0 +1,15 @@
+-- 0009_online_booking.down.sql
+
+DROP INDEX IF EXISTS idx_patients_email_dob;
+
+ALTER TABLE patients DROP COLUMN IF EXISTS provisional;
+ALTER TABLE treatments DROP COLUMN IF EXISTS bookable_online;
+
+-- Pending online bookings become ordinary scheduled appointments
+UPDATE appointments SET status = 'scheduled' WHERE status = 'pending-confirmation';
+
+ALTER TABLE appointments DROP CONSTRAINT IF EXISTS appointments_status_check;
+ALTER TABLE appointments
+    ADD CONSTRAINT appointments_status_check CHECK (status IN (
+        'scheduled', 'confirmed', 'checked-in', 'in-chair', 'completed', 'cancelled', 'no-show'
+    ));
@@ -0,0 +1,18 @@
+-- 0009_online_booking.up.sql
+-- Public online booking: treatments offered as appointment types, provisional
+-- patients created from bookings, and the pending-confirmation status that
+-- online bookings wait in until the front desk accepts them.
+
+ALTER TABLE appointments DROP CONSTRAINT IF EXISTS appointments_status_check;
+ALTER TABLE appointments
+    ADD CONSTRAINT appointments_status_check CHECK (status IN (
+        'pending-confirmation', 'scheduled', 'confirmed', 'checked-in', 'in-chair', 'completed', 'cancelled', 'no-show'
+    ));
+
+ALTER TABLE treatments
+    ADD COLUMN bookable_online BOOLEAN NOT NULL DEFAULT FALSE;
+
+ALTER TABLE patients
+    ADD COLUMN provisional BOOLEAN NOT NULL DEFAULT FALSE;
+
+CREATE INDEX IF NOT EXISTS idx_patients_email_dob ON patients (lower(email), date_of_birth);
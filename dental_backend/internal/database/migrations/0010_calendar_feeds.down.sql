@@ -0,0 +1,5 @@
+-- 0010_calendar_feeds.down.sql
+
+DROP TABLE IF EXISTS calendar_feeds;
+
+ALTER TABLE appointments DROP COLUMN IF EXISTS sequence;
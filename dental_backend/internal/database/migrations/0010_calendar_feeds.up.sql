@@ -0,0 +1,19 @@
+-- 0010_calendar_feeds.up.sql
+-- Revision numbers for calendar clients, and the revocable tokens that
+-- authorize iCalendar feed subscriptions.
+
+ALTER TABLE appointments
+    ADD COLUMN sequence INTEGER NOT NULL DEFAULT 0;
+
+CREATE TABLE IF NOT EXISTS calendar_feeds (
+    id           SERIAL PRIMARY KEY,
+    user_id      INTEGER     NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    feed_type    VARCHAR(10) NOT NULL CHECK (feed_type IN ('dentist', 'patient')),
+    subject_id   INTEGER     NOT NULL,
+    token_hash   CHAR(64)    NOT NULL UNIQUE,
+    created_at   TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    last_used_at TIMESTAMPTZ,
+    revoked_at   TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS idx_calendar_feeds_user ON calendar_feeds (user_id);
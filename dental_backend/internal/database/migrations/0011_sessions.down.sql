@@ -0,0 +1,3 @@
+-- 0011_sessions.down.sql
+
+DROP TABLE IF EXISTS sessions;
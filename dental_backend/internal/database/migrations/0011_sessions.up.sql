@@ -0,0 +1,20 @@
+-- 0011_sessions.up.sql
+-- Server-side login sessions. Access tokens name their session, so revoking
+-- a session locks out its access tokens immediately; refresh tokens rotate on
+-- every use and only their hashes are stored.
+
+CREATE TABLE IF NOT EXISTS sessions (
+    id                  VARCHAR(64) PRIMARY KEY,
+    user_id             INTEGER     NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    token_hash          CHAR(64)    NOT NULL UNIQUE,
+    previous_token_hash CHAR(64),
+    user_agent          TEXT        NOT NULL DEFAULT '',
+    ip_address          VARCHAR(64) NOT NULL DEFAULT '',
+    created_at          TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    last_used_at        TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    expires_at          TIMESTAMPTZ NOT NULL,
+    revoked_at          TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS idx_sessions_user ON sessions (user_id) WHERE revoked_at IS NULL;
+CREATE INDEX IF NOT EXISTS idx_sessions_previous_token ON sessions (previous_token_hash);
@@ -0,0 +1,3 @@
+-- 0012_user_management.down.sql
+
+ALTER TABLE users DROP COLUMN IF EXISTS deactivated_at;
@@ -0,0 +1,5 @@
+-- 0012_user_management.up.sql
+-- Deactivated users keep their records and history but can no longer sign in.
+
+ALTER TABLE users
+    ADD COLUMN deactivated_at TIMESTAMPTZ;
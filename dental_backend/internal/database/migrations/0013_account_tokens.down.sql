@@ -0,0 +1,5 @@
+-- 0013_account_tokens.down.sql
+
+DROP TABLE IF EXISTS auth_policy;
+DROP TABLE IF EXISTS account_tokens;
+ALTER TABLE users DROP COLUMN IF EXISTS email_verified;
@@ -0,0 +1,30 @@
+-- 0013_account_tokens.up.sql
+-- Email verification, single-use password reset and email verification tokens,
+-- and the admin-editable sign-in policy.
+
+-- Accounts that predate verification are treated as verified
+ALTER TABLE users
+    ADD COLUMN email_verified BOOLEAN NOT NULL DEFAULT TRUE;
+ALTER TABLE users
+    ALTER COLUMN email_verified SET DEFAULT FALSE;
+
+CREATE TABLE IF NOT EXISTS account_tokens (
+    id         SERIAL PRIMARY KEY,
+    user_id    INTEGER     NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    purpose    VARCHAR(20) NOT NULL CHECK (purpose IN ('password-reset', 'email-verification')),
+    token_hash VARCHAR(64) NOT NULL UNIQUE,
+    expires_at TIMESTAMPTZ NOT NULL,
+    used_at    TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_account_tokens_user ON account_tokens (user_id, purpose) WHERE used_at IS NULL;
+
+-- A single row holding the clinic's sign-in policy
+CREATE TABLE IF NOT EXISTS auth_policy (
+    id                         BOOLEAN     PRIMARY KEY DEFAULT TRUE CHECK (id),
+    require_email_verification BOOLEAN     NOT NULL DEFAULT FALSE,
+    updated_at                 TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+INSERT INTO auth_policy (id) VALUES (TRUE) ON CONFLICT DO NOTHING;
@@ -0,0 +1,8 @@
+-- 0014_two_factor.down.sql
+
+ALTER TABLE auth_policy DROP COLUMN IF EXISTS require_mfa_roles;
+DROP TABLE IF EXISTS mfa_backup_codes;
+ALTER TABLE users
+    DROP COLUMN IF EXISTS totp_last_step,
+    DROP COLUMN IF EXISTS totp_enabled_at,
+    DROP COLUMN IF EXISTS totp_secret;
@@ -0,0 +1,20 @@
+-- 0014_two_factor.up.sql
+-- TOTP two-factor authentication with hashed single-use backup codes, and the
+-- roles the sign-in policy requires it for.
+
+ALTER TABLE users
+    ADD COLUMN totp_secret     VARCHAR(64),
+    ADD COLUMN totp_enabled_at TIMESTAMPTZ,
+    ADD COLUMN totp_last_step  BIGINT;
+
+CREATE TABLE IF NOT EXISTS mfa_backup_codes (
+    id         SERIAL PRIMARY KEY,
+    user_id    INTEGER     NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    code_hash  VARCHAR(64) NOT NULL,
+    used_at    TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    UNIQUE (user_id, code_hash)
+);
+
+ALTER TABLE auth_policy
+    ADD COLUMN require_mfa_roles TEXT[] NOT NULL DEFAULT '{}';
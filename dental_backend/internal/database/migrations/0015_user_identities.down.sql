@@ -0,0 +1,14 @@
+-- 0015_user_identities.down.sql
+
+DELETE FROM account_tokens WHERE purpose = 'sign-in';
+ALTER TABLE account_tokens DROP CONSTRAINT IF EXISTS account_tokens_purpose_check;
+ALTER TABLE account_tokens
+    ADD CONSTRAINT account_tokens_purpose_check CHECK (purpose IN ('password-reset', 'email-verification'));
+
+ALTER TABLE users ADD COLUMN IF NOT EXISTS google_id VARCHAR(255) UNIQUE;
+
+UPDATE users SET google_id = i.subject
+FROM user_identities i
+WHERE i.user_id = users.id AND i.provider = 'google';
+
+DROP TABLE IF EXISTS user_identities;
@@ -0,0 +1,26 @@
+-- 0015_user_identities.up.sql
+-- Accounts at external OpenID Connect providers linked to users, replacing the
+-- single google_id column, and one-time codes handing a finished provider
+-- sign-in back to the web app.
+
+CREATE TABLE IF NOT EXISTS user_identities (
+    id            SERIAL PRIMARY KEY,
+    user_id       INTEGER      NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    provider      VARCHAR(50)  NOT NULL,
+    subject       VARCHAR(255) NOT NULL,
+    email         VARCHAR(255),
+    created_at    TIMESTAMPTZ  NOT NULL DEFAULT NOW(),
+    last_login_at TIMESTAMPTZ,
+    UNIQUE (provider, subject),
+    UNIQUE (user_id, provider)
+);
+
+INSERT INTO user_identities (user_id, provider, subject, email)
+SELECT id, 'google', google_id, email FROM users WHERE google_id IS NOT NULL
+ON CONFLICT DO NOTHING;
+
+ALTER TABLE users DROP COLUMN IF EXISTS google_id;
+
+ALTER TABLE account_tokens DROP CONSTRAINT IF EXISTS account_tokens_purpose_check;
+ALTER TABLE account_tokens
+    ADD CONSTRAINT account_tokens_purpose_check CHECK (purpose IN ('password-reset', 'email-verification', 'sign-in'));
@@ -0,0 +1,26 @@
+-- 0016_clinics.down.sql
+
+DO $$
+DECLARE
+    tbl TEXT;
+BEGIN
+    FOREACH tbl IN ARRAY ARRAY[
+        'patients', 'treatments', 'patient_treatments', 'appointments', 'invoices', 'insurance_claims',
+        'appointment_series', 'waitlist_entries', 'slot_openings', 'schedule_closures'
+    ] LOOP
+        EXECUTE format('DROP TRIGGER IF EXISTS %I ON %I', tbl || '_clinic', tbl);
+        EXECUTE format('DROP POLICY IF EXISTS clinic_isolation ON %I', tbl);
+        EXECUTE format('ALTER TABLE %I NO FORCE ROW LEVEL SECURITY', tbl);
+        EXECUTE format('ALTER TABLE %I DISABLE ROW LEVEL SECURITY', tbl);
+        EXECUTE format('ALTER TABLE %I DROP COLUMN IF EXISTS clinic_id', tbl);
+    END LOOP;
+END
+$$;
+
+DROP FUNCTION IF EXISTS fill_clinic_id();
+DROP FUNCTION IF EXISTS current_clinic_id();
+
+ALTER TABLE sessions DROP COLUMN IF EXISTS clinic_id;
+
+DROP TABLE IF EXISTS clinic_memberships;
+DROP TABLE IF EXISTS clinics;
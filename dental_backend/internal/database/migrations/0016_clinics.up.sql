@@ -0,0 +1,114 @@
+-- 0016_clinics.up.sql
+-- Clinics and the users who work at them, each with a role per clinic, and
+-- clinic ownership of patient records. Row-level security limits a connection
+-- that has set app.clinic_id to that clinic's rows; connections that have not
+-- set it (the reminder worker, patient self-service links and calendar feeds)
+-- see every clinic. Existing records and users move to the first clinic.
+
+CREATE TABLE IF NOT EXISTS clinics (
+    id         SERIAL PRIMARY KEY,
+    name       VARCHAR(255) NOT NULL,
+    address    TEXT         NOT NULL DEFAULT '',
+    phone      VARCHAR(50)  NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ  NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ  NOT NULL DEFAULT NOW()
+);
+
+INSERT INTO clinics (id, name) VALUES (1, 'Main Clinic');
+SELECT setval(pg_get_serial_sequence('clinics', 'id'), 1);
+
+CREATE TABLE IF NOT EXISTS clinic_memberships (
+    clinic_id  INTEGER     NOT NULL REFERENCES clinics (id) ON DELETE CASCADE,
+    user_id    INTEGER     NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    role       VARCHAR(20) NOT NULL CHECK (role IN ('dentist', 'hygienist', 'admin', 'staff')),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (clinic_id, user_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_clinic_memberships_user ON clinic_memberships (user_id);
+
+INSERT INTO clinic_memberships (clinic_id, user_id, role)
+SELECT 1, id, role FROM users;
+
+-- The clinic a session's access tokens are issued for
+ALTER TABLE sessions
+    ADD COLUMN clinic_id INTEGER REFERENCES clinics (id) ON DELETE SET NULL;
+
+-- current_clinic_id returns the clinic the connection is limited to, or NULL
+CREATE OR REPLACE FUNCTION current_clinic_id() RETURNS INTEGER AS $$
+    SELECT NULLIF(current_setting('app.clinic_id', true), '')::INTEGER
+$$ LANGUAGE sql STABLE;
+
+-- fill_clinic_id gives new rows the connection's clinic, or their parent's. Its
+-- arguments are (parent table, foreign key column) pairs; a parent that is not
+-- visible, or belongs to another clinic, rejects the row as a foreign key
+-- violation. A row's clinic never changes.
+CREATE OR REPLACE FUNCTION fill_clinic_id() RETURNS TRIGGER AS $$
+DECLARE
+    parent_id     INTEGER;
+    parent_clinic INTEGER;
+BEGIN
+    IF TG_OP = 'UPDATE' THEN
+        NEW.clinic_id := OLD.clinic_id;
+    ELSIF NEW.clinic_id IS NULL THEN
+        NEW.clinic_id := current_clinic_id();
+    END IF;
+
+    FOR i IN 0 .. TG_NARGS - 1 BY 2 LOOP
+        EXECUTE format('SELECT ($1).%I', TG_ARGV[i + 1]) INTO parent_id USING NEW;
+        CONTINUE WHEN parent_id IS NULL;
+
+        EXECUTE format('SELECT clinic_id FROM %I WHERE id = $1', TG_ARGV[i]) INTO parent_clinic USING parent_id;
+        IF parent_clinic IS NULL THEN
+            RAISE EXCEPTION '% % not found', TG_ARGV[i], parent_id USING ERRCODE = 'foreign_key_violation';
+        END IF;
+
+        NEW.clinic_id := COALESCE(NEW.clinic_id, parent_clinic);
+        IF NEW.clinic_id <> parent_clinic THEN
+            RAISE EXCEPTION '% % belongs to another clinic', TG_ARGV[i], parent_id USING ERRCODE = 'foreign_key_violation';
+        END IF;
+    END LOOP;
+
+    RETURN NEW;
+END
+$$ LANGUAGE plpgsql;
+
+DO $$
+DECLARE
+    tbl TEXT;
+BEGIN
+    FOREACH tbl IN ARRAY ARRAY[
+        'patients', 'treatments', 'patient_treatments', 'appointments', 'invoices', 'insurance_claims',
+        'appointment_series', 'waitlist_entries', 'slot_openings', 'schedule_closures'
+    ] LOOP
+        EXECUTE format('ALTER TABLE %I ADD COLUMN clinic_id INTEGER NOT NULL DEFAULT 1 REFERENCES clinics (id)', tbl);
+        EXECUTE format('ALTER TABLE %I ALTER COLUMN clinic_id DROP DEFAULT', tbl);
+        EXECUTE format('CREATE INDEX IF NOT EXISTS %I ON %I (clinic_id)', 'idx_' || tbl || '_clinic', tbl);
+
+        EXECUTE format('ALTER TABLE %I ENABLE ROW LEVEL SECURITY', tbl);
+        EXECUTE format('ALTER TABLE %I FORCE ROW LEVEL SECURITY', tbl);
+        EXECUTE format('CREATE POLICY clinic_isolation ON %I USING (current_clinic_id() IS NULL OR clinic_id = current_clinic_id())', tbl);
+    END LOOP;
+END
+$$;
+
+CREATE TRIGGER patients_clinic BEFORE INSERT OR UPDATE ON patients
+    FOR EACH ROW EXECUTE FUNCTION fill_clinic_id();
+CREATE TRIGGER treatments_clinic BEFORE INSERT OR UPDATE ON treatments
+    FOR EACH ROW EXECUTE FUNCTION fill_clinic_id();
+CREATE TRIGGER schedule_closures_clinic BEFORE INSERT OR UPDATE ON schedule_closures
+    FOR EACH ROW EXECUTE FUNCTION fill_clinic_id();
+CREATE TRIGGER patient_treatments_clinic BEFORE INSERT OR UPDATE ON patient_treatments
+    FOR EACH ROW EXECUTE FUNCTION fill_clinic_id('patients', 'patient_id', 'treatments', 'treatment_id');
+CREATE TRIGGER appointment_series_clinic BEFORE INSERT OR UPDATE ON appointment_series
+    FOR EACH ROW EXECUTE FUNCTION fill_clinic_id('patients', 'patient_id', 'treatments', 'treatment_id');
+CREATE TRIGGER appointments_clinic BEFORE INSERT OR UPDATE ON appointments
+    FOR EACH ROW EXECUTE FUNCTION fill_clinic_id('patients', 'patient_id', 'appointment_series', 'series_id');
+CREATE TRIGGER invoices_clinic BEFORE INSERT OR UPDATE ON invoices
+    FOR EACH ROW EXECUTE FUNCTION fill_clinic_id('patients', 'patient_id');
+CREATE TRIGGER insurance_claims_clinic BEFORE INSERT OR UPDATE ON insurance_claims
+    FOR EACH ROW EXECUTE FUNCTION fill_clinic_id('patients', 'patient_id', 'treatments', 'treatment_id');
+CREATE TRIGGER waitlist_entries_clinic BEFORE INSERT OR UPDATE ON waitlist_entries
+    FOR EACH ROW EXECUTE FUNCTION fill_clinic_id('patients', 'patient_id', 'treatments', 'treatment_id');
+CREATE TRIGGER slot_openings_clinic BEFORE INSERT OR UPDATE ON slot_openings
+    FOR EACH ROW EXECUTE FUNCTION fill_clinic_id('appointments', 'appointment_id', 'waitlist_entries', 'held_entry_id');
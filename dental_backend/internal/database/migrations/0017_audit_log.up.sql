@@ -0,0 +1,40 @@
+-- 0017_audit_log.up.sql
+-- Append-only record of every API request: who did what to which record, what
+-- a write changed, and where the request came from. Each entry holds the hash
+-- of the one before it, so editing or removing an entry breaks the chain.
+
+CREATE TABLE IF NOT EXISTS audit_log (
+    id            BIGSERIAL    PRIMARY KEY,
+    occurred_at   TIMESTAMPTZ  NOT NULL,
+    clinic_id     INTEGER,
+    actor_id      INTEGER,
+    actor_role    VARCHAR(20)  NOT NULL DEFAULT '',
+    action        VARCHAR(20)  NOT NULL,
+    resource_type VARCHAR(50)  NOT NULL,
+    resource_id   VARCHAR(50)  NOT NULL DEFAULT '',
+    method        VARCHAR(10)  NOT NULL,
+    route         VARCHAR(255) NOT NULL,
+    status        INTEGER      NOT NULL,
+    -- JSON rather than JSONB keeps the text exactly as it was hashed
+    changes       JSON,
+    request_id    VARCHAR(64)  NOT NULL,
+    ip_address    VARCHAR(45)  NOT NULL DEFAULT '',
+    prev_hash     CHAR(64)     NOT NULL,
+    hash          CHAR(64)     NOT NULL UNIQUE
+);
+
+CREATE INDEX IF NOT EXISTS idx_audit_log_occurred_at ON audit_log (occurred_at);
+CREATE INDEX IF NOT EXISTS idx_audit_log_clinic ON audit_log (clinic_id, occurred_at);
+CREATE INDEX IF NOT EXISTS idx_audit_log_actor ON audit_log (actor_id, occurred_at);
+CREATE INDEX IF NOT EXISTS idx_audit_log_resource ON audit_log (resource_type, resource_id);
+
+CREATE OR REPLACE FUNCTION audit_log_append_only() RETURNS TRIGGER AS $$
+BEGIN
+    RAISE EXCEPTION 'audit_log is append-only' USING ERRCODE = 'insufficient_privilege';
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER audit_log_no_update BEFORE UPDATE OR DELETE ON audit_log
+    FOR EACH ROW EXECUTE FUNCTION audit_log_append_only();
+CREATE TRIGGER audit_log_no_truncate BEFORE TRUNCATE ON audit_log
+    FOR EACH STATEMENT EXECUTE FUNCTION audit_log_append_only();
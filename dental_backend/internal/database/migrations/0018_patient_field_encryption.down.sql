@@ -0,0 +1,28 @@
+-- 0018_patient_field_encryption.down.sql
+
+-- The columns cannot go back to their types while they hold ciphertext
+DO $$
+BEGIN
+    IF EXISTS (SELECT 1 FROM patients WHERE data_key IS NOT NULL) THEN
+        RAISE EXCEPTION 'patients have encrypted fields; run "rekey -decrypt" before rolling back';
+    END IF;
+END
+$$;
+
+DROP INDEX IF EXISTS idx_patients_plain_email;
+DROP INDEX IF EXISTS idx_patients_data_key_id;
+DROP INDEX IF EXISTS idx_patients_phone_bidx;
+DROP INDEX IF EXISTS idx_patients_email_bidx;
+
+ALTER TABLE patients
+    DROP COLUMN IF EXISTS phone_bidx,
+    DROP COLUMN IF EXISTS email_bidx,
+    DROP COLUMN IF EXISTS data_key_id,
+    DROP COLUMN IF EXISTS data_key,
+    ALTER COLUMN date_of_birth TYPE DATE USING date_of_birth::DATE,
+    ALTER COLUMN phone TYPE VARCHAR(50),
+    ALTER COLUMN email TYPE VARCHAR(255),
+    ALTER COLUMN insurance_policy_number TYPE VARCHAR(100);
+
+CREATE INDEX IF NOT EXISTS idx_patients_email ON patients (email);
+CREATE INDEX IF NOT EXISTS idx_patients_email_dob ON patients (lower(email), date_of_birth);
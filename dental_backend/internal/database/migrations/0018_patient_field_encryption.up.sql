@@ -0,0 +1,26 @@
+-- 0018_patient_field_encryption.up.sql
+-- Room for encrypting sensitive patient fields in the application. Encrypted
+-- values are stored as base64 text, each row keeps its data key wrapped by a
+-- key from the keyfile, and email and phone are looked up through keyed hashes
+-- (blind indexes) instead of their values. Existing rows stay in plaintext,
+-- marked by a NULL data_key, until cmd/rekey encrypts them.
+
+DROP INDEX IF EXISTS idx_patients_email;
+DROP INDEX IF EXISTS idx_patients_email_dob;
+
+ALTER TABLE patients
+    ALTER COLUMN date_of_birth TYPE TEXT USING to_char(date_of_birth, 'YYYY-MM-DD'),
+    ALTER COLUMN phone TYPE TEXT,
+    ALTER COLUMN email TYPE TEXT,
+    ALTER COLUMN insurance_policy_number TYPE TEXT,
+    ADD COLUMN data_key BYTEA,
+    ADD COLUMN data_key_id VARCHAR(64),
+    ADD COLUMN email_bidx CHAR(64),
+    ADD COLUMN phone_bidx CHAR(64);
+
+CREATE INDEX IF NOT EXISTS idx_patients_email_bidx ON patients (email_bidx);
+CREATE INDEX IF NOT EXISTS idx_patients_phone_bidx ON patients (phone_bidx);
+CREATE INDEX IF NOT EXISTS idx_patients_data_key_id ON patients (data_key_id);
+
+-- Plaintext rows are still found by email until they are encrypted
+CREATE INDEX IF NOT EXISTS idx_patients_plain_email ON patients (lower(email)) WHERE data_key IS NULL;
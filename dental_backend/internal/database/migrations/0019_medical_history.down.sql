@@ -0,0 +1,3 @@
+-- 0019_medical_history.down.sql
+
+DROP TABLE IF EXISTS patient_medical_entries;
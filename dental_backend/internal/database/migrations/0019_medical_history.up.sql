@@ -0,0 +1,38 @@
+-- 0019_medical_history.up.sql
+-- Structured medical histories: the allergies, medications and conditions of
+-- each patient, with severity, onset and status, from which medical alerts are
+-- drawn. Name, reaction, dosage and notes are encrypted like the patient's
+-- fields when field encryption is enabled. The free text in
+-- patients.medical_history is kept as the patient's unstructured notes.
+
+CREATE TABLE IF NOT EXISTS patient_medical_entries (
+    id          SERIAL PRIMARY KEY,
+    clinic_id   INTEGER     NOT NULL REFERENCES clinics (id),
+    patient_id  INTEGER     NOT NULL REFERENCES patients (id) ON DELETE CASCADE,
+    kind        VARCHAR(20) NOT NULL CHECK (kind IN ('allergy', 'medication', 'condition')),
+    name        TEXT        NOT NULL,
+    severity    VARCHAR(20) NOT NULL DEFAULT '' CHECK (severity IN ('', 'mild', 'moderate', 'severe', 'life-threatening')),
+    reaction    TEXT        NOT NULL DEFAULT '',
+    dosage      TEXT        NOT NULL DEFAULT '',
+    onset       DATE,
+    status      VARCHAR(20) NOT NULL DEFAULT 'active' CHECK (status IN ('active', 'inactive', 'resolved')),
+    alert       BOOLEAN     NOT NULL DEFAULT FALSE,
+    notes       TEXT        NOT NULL DEFAULT '',
+    recorded_by INTEGER     REFERENCES users (id) ON DELETE SET NULL,
+    data_key    BYTEA,
+    data_key_id VARCHAR(64),
+    created_at  TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at  TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_patient_medical_entries_patient ON patient_medical_entries (patient_id, kind);
+CREATE INDEX IF NOT EXISTS idx_patient_medical_entries_clinic ON patient_medical_entries (clinic_id);
+CREATE INDEX IF NOT EXISTS idx_patient_medical_entries_data_key_id ON patient_medical_entries (data_key_id);
+
+ALTER TABLE patient_medical_entries ENABLE ROW LEVEL SECURITY;
+ALTER TABLE patient_medical_entries FORCE ROW LEVEL SECURITY;
+CREATE POLICY clinic_isolation ON patient_medical_entries
+    USING (current_clinic_id() IS NULL OR clinic_id = current_clinic_id());
+
+CREATE TRIGGER patient_medical_entries_clinic BEFORE INSERT OR UPDATE ON patient_medical_entries
+    FOR EACH ROW EXECUTE FUNCTION fill_clinic_id('patients', 'patient_id');
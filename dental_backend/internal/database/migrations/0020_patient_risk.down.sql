@@ -0,0 +1,4 @@
+-- 0020_patient_risk.down.sql
+
+DROP TABLE IF EXISTS patient_risk_scores;
+DROP TABLE IF EXISTS risk_rules;
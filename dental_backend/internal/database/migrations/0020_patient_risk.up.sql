@@ -0,0 +1,30 @@
+-- 0020_patient_risk.up.sql
+-- Derived patient risk: the scoring rules, shared by every clinic, and the
+-- latest assessment of each patient with the factors behind it. The manual
+-- patients.risk_level is left as entered.
+
+-- A single row holding the configured rules; the built-in defaults apply until it exists
+CREATE TABLE IF NOT EXISTS risk_rules (
+    id         BOOLEAN     PRIMARY KEY DEFAULT TRUE CHECK (id),
+    rules      JSONB       NOT NULL,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE TABLE IF NOT EXISTS patient_risk_scores (
+    patient_id  INTEGER     PRIMARY KEY REFERENCES patients (id) ON DELETE CASCADE,
+    clinic_id   INTEGER     NOT NULL REFERENCES clinics (id),
+    score       INTEGER     NOT NULL,
+    level       VARCHAR(10) NOT NULL CHECK (level IN ('low', 'medium', 'high')),
+    factors     JSONB       NOT NULL DEFAULT '[]',
+    computed_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_patient_risk_scores_clinic ON patient_risk_scores (clinic_id, level);
+
+ALTER TABLE patient_risk_scores ENABLE ROW LEVEL SECURITY;
+ALTER TABLE patient_risk_scores FORCE ROW LEVEL SECURITY;
+CREATE POLICY clinic_isolation ON patient_risk_scores
+    USING (current_clinic_id() IS NULL OR clinic_id = current_clinic_id());
+
+CREATE TRIGGER patient_risk_scores_clinic BEFORE INSERT OR UPDATE ON patient_risk_scores
+    FOR EACH ROW EXECUTE FUNCTION fill_clinic_id('patients', 'patient_id');
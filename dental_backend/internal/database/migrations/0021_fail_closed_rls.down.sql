@@ -0,0 +1,17 @@
+-- 0021_fail_closed_rls.down.sql
+
+DO $$
+DECLARE
+    tbl TEXT;
+BEGIN
+    FOREACH tbl IN ARRAY ARRAY[
+        'patients', 'treatments', 'patient_treatments', 'appointments', 'invoices', 'insurance_claims',
+        'appointment_series', 'waitlist_entries', 'slot_openings', 'schedule_closures',
+        'patient_medical_entries', 'patient_risk_scores'
+    ] LOOP
+        EXECUTE format('ALTER POLICY clinic_isolation ON %I USING (current_clinic_id() IS NULL OR clinic_id = current_clinic_id())', tbl);
+    END LOOP;
+END
+$$;
+
+DROP FUNCTION IF EXISTS all_clinics();
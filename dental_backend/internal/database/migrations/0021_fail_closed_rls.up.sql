@@ -0,0 +1,24 @@
+-- 0021_fail_closed_rls.up.sql
+-- Row-level security fails closed: a connection that has not set app.clinic_id
+-- sees no clinic's rows. Connections that serve every clinic (the reminder and
+-- risk jobs, patient self-service links, calendar feeds and migrations) say so
+-- explicitly by setting app.all_clinics to on.
+
+-- all_clinics reports whether the connection serves every clinic
+CREATE OR REPLACE FUNCTION all_clinics() RETURNS BOOLEAN AS $$
+    SELECT COALESCE(current_setting('app.all_clinics', true), '') = 'on'
+$$ LANGUAGE sql STABLE;
+
+DO $$
+DECLARE
+    tbl TEXT;
+BEGIN
+    FOREACH tbl IN ARRAY ARRAY[
+        'patients', 'treatments', 'patient_treatments', 'appointments', 'invoices', 'insurance_claims',
+        'appointment_series', 'waitlist_entries', 'slot_openings', 'schedule_closures',
+        'patient_medical_entries', 'patient_risk_scores'
+    ] LOOP
+        EXECUTE format('ALTER POLICY clinic_isolation ON %I USING (clinic_id = current_clinic_id() OR all_clinics())', tbl);
+    END LOOP;
+END
+$$;
@@ -0,0 +1,32 @@
+-- 0022_clinic_settings.down.sql
+-- The first clinic's settings become the shared ones
+
+ALTER TABLE risk_rules RENAME TO risk_rules_clinic;
+
+CREATE TABLE IF NOT EXISTS risk_rules (
+    id         BOOLEAN     PRIMARY KEY DEFAULT TRUE CHECK (id),
+    rules      JSONB       NOT NULL,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+INSERT INTO risk_rules (id, rules, updated_at)
+SELECT TRUE, rules, updated_at FROM risk_rules_clinic ORDER BY clinic_id LIMIT 1;
+
+DROP TABLE risk_rules_clinic;
+
+ALTER TABLE auth_policy RENAME TO auth_policy_clinic;
+
+CREATE TABLE IF NOT EXISTS auth_policy (
+    id                         BOOLEAN     PRIMARY KEY DEFAULT TRUE CHECK (id),
+    require_email_verification BOOLEAN     NOT NULL DEFAULT FALSE,
+    require_mfa_roles          TEXT[]      NOT NULL DEFAULT '{}',
+    updated_at                 TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+INSERT INTO auth_policy (id, require_email_verification, require_mfa_roles, updated_at)
+SELECT TRUE, require_email_verification, require_mfa_roles, updated_at
+FROM auth_policy_clinic ORDER BY clinic_id LIMIT 1;
+
+INSERT INTO auth_policy (id) VALUES (TRUE) ON CONFLICT DO NOTHING;
+
+DROP TABLE auth_policy_clinic;
@@ -0,0 +1,33 @@
+-- 0022_clinic_settings.up.sql
+-- The sign-in policy and the risk scoring rules belong to each clinic, so an
+-- admin of one clinic cannot change them for another. Every existing clinic
+-- keeps the settings configured so far; clinics without a row use the defaults.
+
+ALTER TABLE auth_policy RENAME TO auth_policy_shared;
+
+CREATE TABLE IF NOT EXISTS auth_policy (
+    clinic_id                  INTEGER     PRIMARY KEY REFERENCES clinics (id) ON DELETE CASCADE,
+    require_email_verification BOOLEAN     NOT NULL DEFAULT FALSE,
+    require_mfa_roles          TEXT[]      NOT NULL DEFAULT '{}',
+    updated_at                 TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+INSERT INTO auth_policy (clinic_id, require_email_verification, require_mfa_roles, updated_at)
+SELECT c.id, p.require_email_verification, p.require_mfa_roles, p.updated_at
+FROM clinics c CROSS JOIN auth_policy_shared p;
+
+DROP TABLE auth_policy_shared;
+
+ALTER TABLE risk_rules RENAME TO risk_rules_shared;
+
+CREATE TABLE IF NOT EXISTS risk_rules (
+    clinic_id  INTEGER     PRIMARY KEY REFERENCES clinics (id) ON DELETE CASCADE,
+    rules      JSONB       NOT NULL,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+INSERT INTO risk_rules (clinic_id, rules, updated_at)
+SELECT c.id, r.rules, r.updated_at
+FROM clinics c CROSS JOIN risk_rules_shared r;
+
+DROP TABLE risk_rules_shared;
@@ -0,0 +1,7 @@
+-- 0023_clinic_calendar_feeds.down.sql
+
+DROP TRIGGER IF EXISTS calendar_feeds_clinic ON calendar_feeds;
+DROP POLICY IF EXISTS clinic_isolation ON calendar_feeds;
+ALTER TABLE calendar_feeds NO FORCE ROW LEVEL SECURITY;
+ALTER TABLE calendar_feeds DISABLE ROW LEVEL SECURITY;
+ALTER TABLE calendar_feeds DROP COLUMN IF EXISTS clinic_id;
@@ -0,0 +1,32 @@
+-- 0023_clinic_calendar_feeds.up.sql
+-- A calendar feed belongs to the clinic it was issued at: it lists only that
+-- clinic's appointments, follows its owner's role there and stops working when
+-- they leave it. Existing patient feeds move to the patient's clinic and other
+-- feeds to their owner's first clinic; feeds whose owner has no clinic are
+-- dropped, as they could no longer be served.
+
+ALTER TABLE calendar_feeds
+    ADD COLUMN clinic_id INTEGER REFERENCES clinics (id) ON DELETE CASCADE;
+
+UPDATE calendar_feeds f SET clinic_id = p.clinic_id
+FROM patients p
+WHERE f.feed_type = 'patient' AND p.id = f.subject_id;
+
+UPDATE calendar_feeds f SET clinic_id = (
+    SELECT MIN(m.clinic_id) FROM clinic_memberships m WHERE m.user_id = f.user_id
+)
+WHERE f.clinic_id IS NULL;
+
+DELETE FROM calendar_feeds WHERE clinic_id IS NULL;
+
+ALTER TABLE calendar_feeds ALTER COLUMN clinic_id SET NOT NULL;
+
+CREATE INDEX IF NOT EXISTS idx_calendar_feeds_clinic ON calendar_feeds (clinic_id);
+
+ALTER TABLE calendar_feeds ENABLE ROW LEVEL SECURITY;
+ALTER TABLE calendar_feeds FORCE ROW LEVEL SECURITY;
+CREATE POLICY clinic_isolation ON calendar_feeds
+    USING (clinic_id = current_clinic_id() OR all_clinics());
+
+CREATE TRIGGER calendar_feeds_clinic BEFORE INSERT OR UPDATE ON calendar_feeds
+    FOR EACH ROW EXECUTE FUNCTION fill_clinic_id();
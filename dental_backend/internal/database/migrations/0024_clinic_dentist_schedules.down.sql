@@ -0,0 +1,25 @@
+-- 0024_clinic_dentist_schedules.down.sql
+-- Each dentist keeps the week of their first clinic
+
+DO $$
+DECLARE
+    tbl TEXT;
+BEGIN
+    FOREACH tbl IN ARRAY ARRAY['dentist_working_hours', 'dentist_breaks'] LOOP
+        EXECUTE format('DROP TRIGGER IF EXISTS %I ON %I', tbl || '_clinic', tbl);
+        EXECUTE format('DROP POLICY IF EXISTS clinic_isolation ON %I', tbl);
+        EXECUTE format('ALTER TABLE %I NO FORCE ROW LEVEL SECURITY', tbl);
+        EXECUTE format('ALTER TABLE %I DISABLE ROW LEVEL SECURITY', tbl);
+        EXECUTE format(
+            'DELETE FROM %I t WHERE clinic_id <> (SELECT MIN(o.clinic_id) FROM %I o WHERE o.dentist_id = t.dentist_id)',
+            tbl, tbl
+        );
+        EXECUTE format('ALTER TABLE %I DROP COLUMN IF EXISTS clinic_id', tbl);
+    END LOOP;
+END
+$$;
+
+DROP INDEX IF EXISTS idx_dentist_working_hours_dentist;
+DROP INDEX IF EXISTS idx_dentist_breaks_dentist;
+CREATE INDEX IF NOT EXISTS idx_dentist_working_hours_dentist ON dentist_working_hours (dentist_id, weekday);
+CREATE INDEX IF NOT EXISTS idx_dentist_breaks_dentist ON dentist_breaks (dentist_id, weekday);
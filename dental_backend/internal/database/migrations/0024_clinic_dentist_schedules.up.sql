@@ -0,0 +1,47 @@
+-- 0024_clinic_dentist_schedules.up.sql
+-- Working hours and breaks belong to a clinic, so a dentist who works at several
+-- clinics keeps a week at each and an admin of one clinic cannot change another's.
+-- A dentist's current week is copied to every clinic they work at; hours of users
+-- without a clinic are dropped.
+
+DO $$
+DECLARE
+    tbl TEXT;
+BEGIN
+    FOREACH tbl IN ARRAY ARRAY['dentist_working_hours', 'dentist_breaks'] LOOP
+        EXECUTE format('ALTER TABLE %I ADD COLUMN clinic_id INTEGER REFERENCES clinics (id) ON DELETE CASCADE', tbl);
+    END LOOP;
+END
+$$;
+
+INSERT INTO dentist_working_hours (clinic_id, dentist_id, weekday, start_time, end_time)
+SELECT m.clinic_id, h.dentist_id, h.weekday, h.start_time, h.end_time
+FROM dentist_working_hours h
+JOIN clinic_memberships m ON m.user_id = h.dentist_id;
+
+INSERT INTO dentist_breaks (clinic_id, dentist_id, weekday, start_time, end_time, label)
+SELECT m.clinic_id, b.dentist_id, b.weekday, b.start_time, b.end_time, b.label
+FROM dentist_breaks b
+JOIN clinic_memberships m ON m.user_id = b.dentist_id;
+
+DELETE FROM dentist_working_hours WHERE clinic_id IS NULL;
+DELETE FROM dentist_breaks WHERE clinic_id IS NULL;
+
+DROP INDEX IF EXISTS idx_dentist_working_hours_dentist;
+DROP INDEX IF EXISTS idx_dentist_breaks_dentist;
+CREATE INDEX IF NOT EXISTS idx_dentist_working_hours_dentist ON dentist_working_hours (clinic_id, dentist_id, weekday);
+CREATE INDEX IF NOT EXISTS idx_dentist_breaks_dentist ON dentist_breaks (clinic_id, dentist_id, weekday);
+
+DO $$
+DECLARE
+    tbl TEXT;
+BEGIN
+    FOREACH tbl IN ARRAY ARRAY['dentist_working_hours', 'dentist_breaks'] LOOP
+        EXECUTE format('ALTER TABLE %I ALTER COLUMN clinic_id SET NOT NULL', tbl);
+        EXECUTE format('ALTER TABLE %I ENABLE ROW LEVEL SECURITY', tbl);
+        EXECUTE format('ALTER TABLE %I FORCE ROW LEVEL SECURITY', tbl);
+        EXECUTE format('CREATE POLICY clinic_isolation ON %I USING (clinic_id = current_clinic_id() OR all_clinics())', tbl);
+        EXECUTE format('CREATE TRIGGER %I BEFORE INSERT OR UPDATE ON %I FOR EACH ROW EXECUTE FUNCTION fill_clinic_id()', tbl || '_clinic', tbl);
+    END LOOP;
+END
+$$;
@@ -2,23 +2,15 @@
 package database
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
 	"log"
-	"os"
 	"time"
 
 	_ "github.com/lib/pq"
 )
 
-var (
-	// DB is the global database connection pool
-	DB *sql.DB
-
-	// Global configuration
-	config *Config
-)
-
 // Config holds database configuration parameters
 type Config struct {
 	Host     string
@@ -27,36 +19,34 @@ type Config struct {
 	Password string
 	DBName   string
 	SSLMode  string
-}
 
-// LoadConfig loads database configuration from environment variables
-func LoadConfig() *Config {
-	config = &Config{
-		Host:     getEnv("DB_HOST", "localhost"),
-		Port:     getEnv("DB_PORT", "5432"),
-		User:     getEnv("DB_USER", "sittminthar"),
-		Password: getEnv("DB_PASSWORD", ""),
-		DBName:   getEnv("DB_NAME", "dental_scheduler"),
-		SSLMode:  getEnv("DB_SSL_MODE", "disable"),
-	}
-	return config
+	// MigrateUser and MigratePassword are the credentials migrations run with;
+	// the user owns the schema, unlike User, which row-level security applies to
+	MigrateUser     string
+	MigratePassword string
+
+	// AutoMigrate applies pending migrations at startup instead of refusing to start
+	AutoMigrate bool
+
+	// AllClinics opens connections that see every clinic's records, for the
+	// background jobs that work across clinics
+	AllClinics bool
 }
 
-// getEnv returns the value of an environment variable or a default value
-func getEnv(key, defaultValue string) string {
-	if value := os.Getenv(key); value != "" {
-		return value
-	}
-	return defaultValue
+// Migrator returns the configuration to run migrations with
+func (c Config) Migrator() *Config {
+	c.User, c.Password = c.MigrateUser, c.MigratePassword
+	return &c
 }
 
-// InitDB initializes the database connection pool
-func InitDB() (*sql.DB, error) {
-	// Load configuration if not already loaded
-	if config == nil {
-		LoadConfig()
-	}
+// ForAllClinics returns the configuration for connections that see every clinic
+func (c Config) ForAllClinics() *Config {
+	c.AllClinics = true
+	return &c
+}
 
+// Open initializes a database connection pool for the given configuration
+func Open(config *Config) (*sql.DB, error) {
 	// Create connection string
 	// Handle empty password case
 	var connStr string
@@ -71,54 +61,63 @@ func InitDB() (*sql.DB, error) {
 			config.Host, config.Port, config.User, config.Password, config.DBName, config.SSLMode,
 		)
 	}
+	if config.AllClinics {
+		connStr += " options='-c app.all_clinics=on'"
+	}
 
 	log.Printf("Connecting to database: host=%s port=%s user=%s dbname=%s sslmode=%s",
 		config.Host, config.Port, config.User, config.DBName, config.SSLMode)
 
 	// Open database connection
-	var err error
-	DB, err = sql.Open("postgres", connStr)
+	db, err := sql.Open("postgres", connStr)
 	if err != nil {
 		return nil, fmt.Errorf("failed to open database connection: %w", err)
 	}
 
 	// Configure connection pool
-	DB.SetMaxOpenConns(25)                  // Maximum number of open connections
-	DB.SetMaxIdleConns(5)                   // Maximum number of idle connections
-	DB.SetConnMaxLifetime(60 * time.Minute) // Maximum lifetime of a connection
-	DB.SetConnMaxIdleTime(10 * time.Minute) // Maximum idle time of a connection
+	db.SetMaxOpenConns(25)                  // Maximum number of open connections
+	db.SetMaxIdleConns(5)                   // Maximum number of idle connections
+	db.SetConnMaxLifetime(60 * time.Minute) // Maximum lifetime of a connection
+	db.SetConnMaxIdleTime(10 * time.Minute) // Maximum idle time of a connection
 
 	// Test the connection
-	if err := DB.Ping(); err != nil {
+	if err := db.Ping(); err != nil {
+		db.Close()
 		return nil, fmt.Errorf("failed to ping database: %w", err)
 	}
 
 	log.Println("Successfully connected to the database")
 
-	return DB, nil
+	return db, nil
 }
 
-// GetDB returns the global database connection
-func GetDB() *sql.DB {
-	if DB == nil {
-		log.Fatal("Database not initialized. Call InitDB() first.")
+// CheckRowSecurity refuses a connection whose user bypasses the row-level
+// security that keeps clinics apart: a superuser, a role with BYPASSRLS, or the
+// owner of the tables, who could turn the policies off
+func CheckRowSecurity(ctx context.Context, db *sql.DB) error {
+	var user string
+	var bypasses, owns bool
+	err := db.QueryRowContext(ctx, `
+		SELECT current_user, rolsuper OR rolbypassrls,
+			EXISTS (SELECT 1 FROM pg_tables WHERE tablename = 'patients' AND tableowner = current_user)
+		FROM pg_roles WHERE rolname = current_user`).Scan(&user, &bypasses, &owns)
+	if err != nil {
+		return fmt.Errorf("failed to check database role: %w", err)
 	}
-	return DB
-}
-
-// CloseDB closes the database connection
-func CloseDB() error {
-	if DB != nil {
-		return DB.Close()
+	if bypasses {
+		return fmt.Errorf("database user %q is a superuser or has BYPASSRLS, so clinics would not be kept apart; connect as the application role from scripts/db_roles.sql", user)
+	}
+	if owns {
+		return fmt.Errorf("database user %q owns the schema; connect as the application role from scripts/db_roles.sql and run migrations as DB_MIGRATE_USER", user)
 	}
 	return nil
 }
 
 // HealthCheck verifies database connectivity
-func HealthCheck() error {
-	if DB == nil {
+func HealthCheck(ctx context.Context, db *sql.DB) error {
+	if db == nil {
 		return fmt.Errorf("database not initialized")
 	}
 
-	return DB.Ping()
+	return db.PingContext(ctx)
 }
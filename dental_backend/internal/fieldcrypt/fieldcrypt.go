@@ -0,0 +1,213 @@
+// dental_backend/internal/fieldcrypt/fieldcrypt.go
+
+// Package fieldcrypt encrypts individual fields of a record before they are
+// stored. Each record gets a data key of its own, which is stored alongside it
+// wrapped by a key from a KeyProvider (envelope encryption). After the
+// provider's key is rotated, records sealed under the old key stay readable
+// until they are re-sealed under the new one. Encrypted fields can still be
+// looked up by equality through blind indexes.
+package fieldcrypt
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"reflect"
+)
+
+// KeySize is the size of data keys and provider keys, for AES-256
+const KeySize = 32
+
+// ErrUnknownKey is returned when a record's data key was wrapped by a key the
+// provider does not have
+var ErrUnknownKey = errors.New("unknown field encryption key")
+
+// KeyProvider holds the keys data keys are wrapped with
+type KeyProvider interface {
+	// CurrentKeyID names the key new data keys are wrapped with
+	CurrentKeyID() string
+	// WrapKey encrypts a data key with the named key
+	WrapKey(ctx context.Context, keyID string, dataKey []byte) ([]byte, error)
+	// UnwrapKey decrypts a data key wrapped with the named key
+	UnwrapKey(ctx context.Context, keyID string, wrapped []byte) ([]byte, error)
+	// IndexKey is the key blind indexes are computed with. It is never rotated,
+	// since every index would have to be recomputed from the plaintext.
+	IndexKey() []byte
+}
+
+// Envelope is what is stored with an encrypted record to decrypt it again
+type Envelope struct {
+	KeyID      string
+	WrappedKey []byte
+}
+
+// Cipher encrypts the string fields of a struct tagged `encrypted:"true"`
+type Cipher struct {
+	keys KeyProvider
+}
+
+// New creates a cipher wrapping data keys with keys
+func New(keys KeyProvider) *Cipher {
+	return &Cipher{keys: keys}
+}
+
+// Load creates a cipher from the keyfile at path, or returns nil when path is
+// empty and fields are to be stored in plaintext
+func Load(path string) (*Cipher, error) {
+	if path == "" {
+		return nil, nil
+	}
+	keys, err := LoadKeyfile(path)
+	if err != nil {
+		return nil, err
+	}
+	return New(keys), nil
+}
+
+// CurrentKeyID names the key new records are sealed under
+func (c *Cipher) CurrentKeyID() string {
+	return c.keys.CurrentKeyID()
+}
+
+// Seal encrypts the tagged fields of the struct record points to in place under
+// a new data key, and returns the envelope to store with it. Empty fields stay
+// empty.
+func (c *Cipher) Seal(ctx context.Context, record interface{}) (Envelope, error) {
+	dataKey := make([]byte, KeySize)
+	if _, err := rand.Read(dataKey); err != nil {
+		return Envelope{}, err
+	}
+
+	keyID := c.keys.CurrentKeyID()
+	wrapped, err := c.keys.WrapKey(ctx, keyID, dataKey)
+	if err != nil {
+		return Envelope{}, err
+	}
+
+	aead, err := newGCM(dataKey)
+	if err != nil {
+		return Envelope{}, err
+	}
+	err = eachField(record, func(name string, value reflect.Value) error {
+		if value.String() == "" {
+			return nil
+		}
+		sealed, err := seal(aead, []byte(value.String()), []byte(name))
+		if err != nil {
+			return err
+		}
+		value.SetString(base64.StdEncoding.EncodeToString(sealed))
+		return nil
+	})
+	if err != nil {
+		return Envelope{}, err
+	}
+
+	return Envelope{KeyID: keyID, WrappedKey: wrapped}, nil
+}
+
+// Open decrypts the tagged fields of the struct record points to in place
+func (c *Cipher) Open(ctx context.Context, record interface{}, env Envelope) error {
+	dataKey, err := c.keys.UnwrapKey(ctx, env.KeyID, env.WrappedKey)
+	if err != nil {
+		return err
+	}
+	aead, err := newGCM(dataKey)
+	if err != nil {
+		return err
+	}
+
+	return eachField(record, func(name string, value reflect.Value) error {
+		if value.String() == "" {
+			return nil
+		}
+		sealed, err := base64.StdEncoding.DecodeString(value.String())
+		if err != nil {
+			return fmt.Errorf("field %s is not encrypted: %w", name, err)
+		}
+		plain, err := open(aead, sealed, []byte(name))
+		if err != nil {
+			return fmt.Errorf("decrypting field %s: %w", name, err)
+		}
+		value.SetString(string(plain))
+		return nil
+	})
+}
+
+// BlindIndex returns a keyed hash of a field's value that can be stored and
+// matched by equality without revealing the value. Callers normalize the value
+// first, the same way when storing and searching. An empty value has no index.
+func (c *Cipher) BlindIndex(field, value string) string {
+	if value == "" {
+		return ""
+	}
+	mac := hmac.New(sha256.New, c.keys.IndexKey())
+	mac.Write([]byte(field))
+	mac.Write([]byte{0})
+	mac.Write([]byte(value))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// eachField calls fn with the name and value of every string field of the
+// struct record points to that is tagged for encryption. The name, the field's
+// db tag when it has one, binds the ciphertext to its column.
+func eachField(record interface{}, fn func(name string, value reflect.Value) error) error {
+	v := reflect.ValueOf(record)
+	if v.Kind() != reflect.Ptr || v.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("fieldcrypt: %T is not a pointer to a struct", record)
+	}
+	v = v.Elem()
+
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if field.Tag.Get("encrypted") != "true" {
+			continue
+		}
+		if field.Type.Kind() != reflect.String {
+			return fmt.Errorf("fieldcrypt: field %s is not a string", field.Name)
+		}
+		name := field.Tag.Get("db")
+		if name == "" {
+			name = field.Name
+		}
+		if err := fn(name, v.Field(i)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// newGCM returns AES-GCM under key
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// seal encrypts plain under a random nonce, which is prepended to the result
+func seal(aead cipher.AEAD, plain, additional []byte) ([]byte, error) {
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	return aead.Seal(nonce, nonce, plain, additional), nil
+}
+
+// open reverses seal
+func open(aead cipher.AEAD, sealed, additional []byte) ([]byte, error) {
+	if len(sealed) < aead.NonceSize() {
+		return nil, errors.New("ciphertext too short")
+	}
+	nonce, ciphertext := sealed[:aead.NonceSize()], sealed[aead.NonceSize():]
+	return aead.Open(nil, nonce, ciphertext, additional)
+}
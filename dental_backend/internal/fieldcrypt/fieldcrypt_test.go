@@ -0,0 +1,214 @@
+// dental_backend/internal/fieldcrypt/fieldcrypt_test.go
+package fieldcrypt
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"path/filepath"
+	"testing"
+)
+
+// record has fields of each kind Seal meets
+type record struct {
+	Name  string
+	Phone string `db:"phone" encrypted:"true"`
+	Email string `db:"email" encrypted:"true"`
+	Notes string `encrypted:"true"`
+}
+
+// newKeyfile creates a keyfile with one key in a temporary directory and returns its path
+func newKeyfile(t *testing.T) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "keys.json")
+	if _, err := RotateKeyfile(path); err != nil {
+		t.Fatalf("create keyfile: %v", err)
+	}
+	return path
+}
+
+// load reads the cipher for the keyfile at path
+func load(t *testing.T, path string) *Cipher {
+	t.Helper()
+	c, err := Load(path)
+	if err != nil {
+		t.Fatalf("load keyfile: %v", err)
+	}
+	return c
+}
+
+// sealed returns a sealed copy of the test record with its envelope
+func sealed(t *testing.T, c *Cipher) (record, Envelope) {
+	t.Helper()
+	r := record{Name: "Pat Jones", Phone: "555-0100", Email: "pat@example.com"}
+	env, err := c.Seal(context.Background(), &r)
+	if err != nil {
+		t.Fatalf("seal: %v", err)
+	}
+	return r, env
+}
+
+func TestSealOpenRoundTrip(t *testing.T) {
+	c := load(t, newKeyfile(t))
+	r, env := sealed(t, c)
+
+	if r.Name != "Pat Jones" {
+		t.Errorf("untagged field = %q, want it left alone", r.Name)
+	}
+	if r.Phone == "555-0100" || r.Email == "pat@example.com" {
+		t.Errorf("tagged fields = %q, %q, want them encrypted", r.Phone, r.Email)
+	}
+	if r.Notes != "" {
+		t.Errorf("empty field = %q, want it left empty", r.Notes)
+	}
+	if env.KeyID != c.CurrentKeyID() || len(env.WrappedKey) == 0 {
+		t.Errorf("envelope = %+v, want a data key wrapped with %s", env, c.CurrentKeyID())
+	}
+
+	if err := c.Open(context.Background(), &r, env); err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	want := record{Name: "Pat Jones", Phone: "555-0100", Email: "pat@example.com"}
+	if r != want {
+		t.Errorf("opened record = %+v, want %+v", r, want)
+	}
+}
+
+func TestSealUsesAFreshDataKeyPerRecord(t *testing.T) {
+	c := load(t, newKeyfile(t))
+	first, firstEnv := sealed(t, c)
+	second, secondEnv := sealed(t, c)
+
+	if first.Phone == second.Phone {
+		t.Error("the same value sealed twice gave the same ciphertext")
+	}
+	if string(firstEnv.WrappedKey) == string(secondEnv.WrappedKey) {
+		t.Error("two records share a wrapped data key")
+	}
+}
+
+func TestOpenRejectsWrongKeysAndTampering(t *testing.T) {
+	c := load(t, newKeyfile(t))
+
+	tests := []struct {
+		name   string
+		cipher *Cipher
+		tamper func(r *record, env *Envelope)
+		is     error
+	}{
+		{
+			name:   "another keyfile's key of the same name",
+			cipher: load(t, newKeyfile(t)),
+			tamper: func(r *record, env *Envelope) {},
+		},
+		{
+			name:   "unknown key",
+			cipher: c,
+			tamper: func(r *record, env *Envelope) { env.KeyID = "retired" },
+			is:     ErrUnknownKey,
+		},
+		{
+			name:   "tampered wrapped key",
+			cipher: c,
+			tamper: func(r *record, env *Envelope) { env.WrappedKey[len(env.WrappedKey)-1] ^= 1 },
+		},
+		{
+			name:   "tampered ciphertext",
+			cipher: c,
+			tamper: func(r *record, env *Envelope) {
+				raw, _ := base64.StdEncoding.DecodeString(r.Phone)
+				raw[len(raw)-1] ^= 1
+				r.Phone = base64.StdEncoding.EncodeToString(raw)
+			},
+		},
+		{
+			name:   "ciphertext moved to another column",
+			cipher: c,
+			tamper: func(r *record, env *Envelope) { r.Phone, r.Email = r.Email, r.Phone },
+		},
+		{
+			name:   "plaintext in an encrypted column",
+			cipher: c,
+			tamper: func(r *record, env *Envelope) { r.Phone = "555-0100" },
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, env := sealed(t, c)
+			tt.tamper(&r, &env)
+
+			err := tt.cipher.Open(context.Background(), &r, env)
+			if err == nil {
+				t.Fatal("open succeeded")
+			}
+			if tt.is != nil && !errors.Is(err, tt.is) {
+				t.Errorf("open error = %v, want %v", err, tt.is)
+			}
+		})
+	}
+}
+
+func TestRotatedKeysKeepOldRecordsReadable(t *testing.T) {
+	path := newKeyfile(t)
+	before := load(t, path)
+	old, oldEnv := sealed(t, before)
+
+	newID, err := RotateKeyfile(path)
+	if err != nil {
+		t.Fatalf("rotate keyfile: %v", err)
+	}
+	after := load(t, path)
+	if after.CurrentKeyID() != newID || newID == oldEnv.KeyID {
+		t.Fatalf("current key = %s after rotating to %s from %s", after.CurrentKeyID(), newID, oldEnv.KeyID)
+	}
+
+	// Records sealed before the rotation open with the old key, which stays in the file
+	if err := after.Open(context.Background(), &old, oldEnv); err != nil {
+		t.Fatalf("open record sealed under the old key: %v", err)
+	}
+	if old.Phone != "555-0100" {
+		t.Errorf("phone = %q, want 555-0100", old.Phone)
+	}
+
+	// and re-sealing puts them under the new one
+	_, newEnv := sealed(t, after)
+	if newEnv.KeyID != newID {
+		t.Errorf("new record sealed under %s, want %s", newEnv.KeyID, newID)
+	}
+}
+
+func TestBlindIndex(t *testing.T) {
+	path := newKeyfile(t)
+	c := load(t, path)
+	index := c.BlindIndex("email", "pat@example.com")
+
+	if got := c.BlindIndex("email", "pat@example.com"); got != index {
+		t.Errorf("index changed between calls: %s, %s", index, got)
+	}
+	if got := c.BlindIndex("email", "sam@example.com"); got == index {
+		t.Error("different values share an index")
+	}
+	if got := c.BlindIndex("phone", "pat@example.com"); got == index {
+		t.Error("the same value in different fields shares an index")
+	}
+	if got := c.BlindIndex("email", ""); got != "" {
+		t.Errorf("index of an empty value = %q, want none", got)
+	}
+
+	// Rotation only adds a key-wrapping key, so stored indexes keep matching
+	if _, err := RotateKeyfile(path); err != nil {
+		t.Fatalf("rotate keyfile: %v", err)
+	}
+	if got := load(t, path).BlindIndex("email", "pat@example.com"); got != index {
+		t.Errorf("index after rotation = %s, want %s", got, index)
+	}
+}
+
+func TestSealRejectsNonStructs(t *testing.T) {
+	c := load(t, newKeyfile(t))
+	r := record{}
+	if _, err := c.Seal(context.Background(), r); err == nil {
+		t.Error("sealing a struct value rather than a pointer succeeded")
+	}
+}
@@ -0,0 +1,183 @@
+// dental_backend/internal/fieldcrypt/keyfile.go
+package fieldcrypt
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// Keyfile is a KeyProvider reading its keys from a local JSON file such as
+//
+//	{
+//	  "current": "2026-10-17",
+//	  "keys": {"2026-10-17": "<base64 of 32 random bytes>"},
+//	  "indexKey": "<base64 of 32 random bytes>"
+//	}
+//
+// Keys that are no longer current stay in the file until no data key is
+// wrapped with them.
+type Keyfile struct {
+	current  string
+	keys     map[string][]byte
+	indexKey []byte
+}
+
+// keyfileJSON is the file format of a Keyfile
+type keyfileJSON struct {
+	Current  string            `json:"current"`
+	Keys     map[string]string `json:"keys"`
+	IndexKey string            `json:"indexKey"`
+}
+
+// LoadKeyfile reads the keys in the file at path
+func LoadKeyfile(path string) (*Keyfile, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var file keyfileJSON
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("keyfile %s: %w", path, err)
+	}
+
+	k := &Keyfile{current: file.Current, keys: make(map[string][]byte)}
+	for id, encoded := range file.Keys {
+		key, err := decodeKey(encoded)
+		if err != nil {
+			return nil, fmt.Errorf("keyfile %s: key %q: %w", path, id, err)
+		}
+		k.keys[id] = key
+	}
+	if _, ok := k.keys[k.current]; !ok {
+		return nil, fmt.Errorf("keyfile %s: current key %q is not in keys", path, k.current)
+	}
+	if k.indexKey, err = decodeKey(file.IndexKey); err != nil {
+		return nil, fmt.Errorf("keyfile %s: index key: %w", path, err)
+	}
+	return k, nil
+}
+
+// RotateKeyfile adds a new random key to the file at path and makes it current,
+// creating the file when it does not exist, and returns the new key's ID
+func RotateKeyfile(path string) (string, error) {
+	file := keyfileJSON{Keys: make(map[string]string)}
+	data, err := os.ReadFile(path)
+	switch {
+	case err == nil:
+		// Check the existing file is usable before changing it
+		if _, err := LoadKeyfile(path); err != nil {
+			return "", err
+		}
+		if err := json.Unmarshal(data, &file); err != nil {
+			return "", err
+		}
+	case errors.Is(err, os.ErrNotExist):
+		indexKey, err := randomKey()
+		if err != nil {
+			return "", err
+		}
+		file.IndexKey = indexKey
+	default:
+		return "", err
+	}
+
+	// Keys are named by the day they were added
+	id := time.Now().UTC().Format("2006-01-02")
+	for n := 2; file.Keys[id] != ""; n++ {
+		id = fmt.Sprintf("%s.%d", time.Now().UTC().Format("2006-01-02"), n)
+	}
+	key, err := randomKey()
+	if err != nil {
+		return "", err
+	}
+	file.Keys[id] = key
+	file.Current = id
+
+	data, err = json.MarshalIndent(file, "", "  ")
+	if err != nil {
+		return "", err
+	}
+
+	// Write a temporary file and rename it, so the keyfile is never left half written
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*")
+	if err != nil {
+		return "", err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(append(data, '\n')); err != nil {
+		tmp.Close()
+		return "", err
+	}
+	if err := tmp.Close(); err != nil {
+		return "", err
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return "", err
+	}
+	return id, nil
+}
+
+// CurrentKeyID names the key new data keys are wrapped with
+func (k *Keyfile) CurrentKeyID() string {
+	return k.current
+}
+
+// WrapKey encrypts a data key with the named key
+func (k *Keyfile) WrapKey(ctx context.Context, keyID string, dataKey []byte) ([]byte, error) {
+	key, ok := k.keys[keyID]
+	if !ok {
+		return nil, ErrUnknownKey
+	}
+	aead, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	return seal(aead, dataKey, []byte(keyID))
+}
+
+// UnwrapKey decrypts a data key wrapped with the named key
+func (k *Keyfile) UnwrapKey(ctx context.Context, keyID string, wrapped []byte) ([]byte, error) {
+	key, ok := k.keys[keyID]
+	if !ok {
+		return nil, ErrUnknownKey
+	}
+	aead, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	return open(aead, wrapped, []byte(keyID))
+}
+
+// IndexKey is the key blind indexes are computed with
+func (k *Keyfile) IndexKey() []byte {
+	return k.indexKey
+}
+
+// decodeKey decodes a base64 key of KeySize bytes
+func decodeKey(encoded string) ([]byte, error) {
+	key, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, err
+	}
+	if len(key) != KeySize {
+		return nil, fmt.Errorf("must be %d bytes, got %d", KeySize, len(key))
+	}
+	return key, nil
+}
+
+// randomKey returns a new random key in base64
+func randomKey() (string, error) {
+	key := make([]byte, KeySize)
+	if _, err := rand.Read(key); err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(key), nil
+}
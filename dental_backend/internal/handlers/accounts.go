@@ -0,0 +1,125 @@
+// dental_backend/internal/handlers/accounts.go
+package handlers
+
+import (
+	"errors"
+	"log"
+	"net/http"
+
+	"dental_backend/internal/models"
+	"dental_backend/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ForgotPassword handles POST /api/auth/forgot-password. The response is the same
+// whether or not the address belongs to an account.
+func (s *Server) ForgotPassword(c *gin.Context) {
+	var req models.ForgotPasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := s.accounts.RequestPasswordReset(c.Request.Context(), req.Email); err != nil {
+		log.Printf("Error sending password reset: %v", err)
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "If an account exists for that address, a reset link has been sent"})
+}
+
+// ResetPassword handles POST /api/auth/reset-password
+func (s *Server) ResetPassword(c *gin.Context) {
+	var req models.ResetPasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := s.accounts.ResetPassword(c.Request.Context(), req); err != nil {
+		if errors.Is(err, services.ErrInvalidAccountToken) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "This link is invalid or has expired"})
+			return
+		}
+		log.Printf("Error resetting password: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to reset password"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Password reset successfully"})
+}
+
+// VerifyEmail handles POST /api/auth/verify-email
+func (s *Server) VerifyEmail(c *gin.Context) {
+	var req models.VerifyEmailRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := s.accounts.VerifyEmail(c.Request.Context(), req.Token); err != nil {
+		if errors.Is(err, services.ErrInvalidAccountToken) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "This link is invalid or has expired"})
+			return
+		}
+		log.Printf("Error verifying email: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to verify email address"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Email address verified"})
+}
+
+// ResendVerification handles POST /api/auth/resend-verification. The response is the
+// same whether or not the address belongs to an account.
+func (s *Server) ResendVerification(c *gin.Context) {
+	var req models.ResendVerificationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := s.accounts.ResendVerification(c.Request.Context(), req.Email); err != nil {
+		log.Printf("Error sending verification email: %v", err)
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "If the address needs verifying, a new link has been sent"})
+}
+
+// sendVerification emails a verification link after an account is created or its
+// address changes, logging failures since the account change itself succeeded
+func (s *Server) sendVerification(c *gin.Context, user *models.User) {
+	if err := s.accounts.SendVerification(c.Request.Context(), *user); err != nil {
+		log.Printf("Error sending verification email: %v", err)
+	}
+}
+
+// GetAuthPolicy handles GET /api/auth/policy
+func (s *Server) GetAuthPolicy(c *gin.Context) {
+	policy, err := s.accounts.GetPolicy(c.Request.Context())
+	if err != nil {
+		log.Printf("Error retrieving auth policy: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve policy"})
+		return
+	}
+
+	c.JSON(http.StatusOK, policy)
+}
+
+// UpdateAuthPolicy handles PUT /api/auth/policy
+func (s *Server) UpdateAuthPolicy(c *gin.Context) {
+	var req models.UpdateAuthPolicyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	policy, err := s.accounts.UpdatePolicy(c.Request.Context(), req)
+	if err != nil {
+		log.Printf("Error updating auth policy: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update policy"})
+		return
+	}
+
+	c.JSON(http.StatusOK, policy)
+}
@@ -0,0 +1,134 @@
+// dental_backend/internal/handlers/appointment_links.go
+package handlers
+
+import (
+	"errors"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+
+	"dental_backend/internal/models"
+	"dental_backend/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// CreateAppointmentLink handles POST /api/appointments/:id/link, issuing a self-service
+// link token that staff can send to the patient
+func (s *Server) CreateAppointmentLink(c *gin.Context) {
+	// Get logged-in user from context
+	caller, exists := currentCaller(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	appointmentID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid appointment ID"})
+		return
+	}
+
+	link, err := s.links.IssueLink(c.Request.Context(), appointmentID, caller)
+	if err != nil {
+		if _, ok := err.(*services.ValidationError); ok {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		log.Printf("Error issuing appointment link: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to issue appointment link"})
+		return
+	}
+	if link == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Appointment not found"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, link)
+}
+
+// respondPublicLink writes the result of a self-service link request. Conflict details
+// name other patients, so they are never passed on to the public.
+func respondPublicLink(c *gin.Context, result interface{}, err error, failure string) {
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrInvalidLink):
+			c.JSON(http.StatusNotFound, gin.H{"error": "This link is invalid or has expired"})
+		case errors.Is(err, services.ErrLinkUsed):
+			c.JSON(http.StatusGone, gin.H{"error": "This link has already been used"})
+		default:
+			if _, ok := err.(*services.ValidationError); ok {
+				c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+				return
+			}
+			if _, ok := err.(*services.ConflictError); ok {
+				c.JSON(http.StatusConflict, gin.H{"error": "That time is no longer available"})
+				return
+			}
+			log.Printf("Error handling appointment link: %v", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": failure})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// PreviewAppointmentConfirmation handles GET /api/public/appointments/:token/confirm
+func (s *Server) PreviewAppointmentConfirmation(c *gin.Context) {
+	preview, err := s.links.Preview(c.Request.Context(), c.Param("token"), models.AppointmentLinkConfirm)
+	respondPublicLink(c, preview, err, "Failed to load appointment")
+}
+
+// ConfirmAppointmentByLink handles POST /api/public/appointments/:token/confirm
+func (s *Server) ConfirmAppointmentByLink(c *gin.Context) {
+	appointment, err := s.links.Confirm(c.Request.Context(), c.Param("token"))
+	respondPublicLink(c, appointment, err, "Failed to confirm appointment")
+}
+
+// PreviewAppointmentCancellation handles GET /api/public/appointments/:token/cancel
+func (s *Server) PreviewAppointmentCancellation(c *gin.Context) {
+	preview, err := s.links.Preview(c.Request.Context(), c.Param("token"), models.AppointmentLinkCancel)
+	respondPublicLink(c, preview, err, "Failed to load appointment")
+}
+
+// CancelAppointmentByLink handles POST /api/public/appointments/:token/cancel with an optional reason
+func (s *Server) CancelAppointmentByLink(c *gin.Context) {
+	var req models.PublicCancelRequest
+	if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	appointment, err := s.links.Cancel(c.Request.Context(), c.Param("token"), req)
+	respondPublicLink(c, appointment, err, "Failed to cancel appointment")
+}
+
+// GetRescheduleOptions handles GET /api/public/appointments/:token/reschedule-options?days=
+func (s *Server) GetRescheduleOptions(c *gin.Context) {
+	days := 0
+	if value := c.Query("days"); value != "" {
+		var err error
+		if days, err = strconv.Atoi(value); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid days"})
+			return
+		}
+	}
+
+	options, err := s.links.RescheduleOptions(c.Request.Context(), c.Param("token"), days)
+	respondPublicLink(c, options, err, "Failed to load reschedule options")
+}
+
+// RescheduleAppointmentByLink handles POST /api/public/appointments/:token/reschedule-options,
+// moving the appointment to one of the offered slots
+func (s *Server) RescheduleAppointmentByLink(c *gin.Context) {
+	var req models.PublicRescheduleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	appointment, err := s.links.Reschedule(c.Request.Context(), c.Param("token"), req)
+	respondPublicLink(c, appointment, err, "Failed to reschedule appointment")
+}
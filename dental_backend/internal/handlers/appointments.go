@@ -7,30 +7,24 @@ import (
 	"net/http"
 	"strconv"
 
-	"dental_backend/internal/database" // Import the shared database package
 	"dental_backend/internal/models"
+	"dental_backend/internal/repository"
 	"dental_backend/internal/services"
 
 	"github.com/gin-gonic/gin"
 )
 
-// GetTodaysAppointments retrieves all appointments for today for the logged-in dentist
-func GetTodaysAppointments(c *gin.Context) {
-	// Get database connection from the shared database package
-	db := database.GetDB()
-
-	// Create appointment service
-	appointmentService := services.NewAppointmentService(db)
-
-	// Get logged-in dentist ID from context
-	dentistID, exists := c.Get("userID")
+// GetTodaysAppointments retrieves today's appointments visible to the logged-in user
+func (s *Server) GetTodaysAppointments(c *gin.Context) {
+	// Get logged-in user from context
+	caller, exists := currentCaller(c)
 	if !exists {
 		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
 		return
 	}
 
 	// Get today's appointments from service
-	appointments, err := appointmentService.GetTodaysAppointments(dentistID.(int))
+	appointments, err := s.appointments.GetTodaysAppointments(c.Request.Context(), caller)
 	if err != nil {
 		log.Printf("Error retrieving today's appointments: %v", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve appointments", "details": err.Error()})
@@ -45,51 +39,57 @@ func GetTodaysAppointments(c *gin.Context) {
 	c.JSON(http.StatusOK, appointments)
 }
 
-// GetAppointments retrieves all appointments with optional filtering for the logged-in dentist
-func GetAppointments(c *gin.Context) {
-	// Get database connection from the shared database package
-	db := database.GetDB()
-
-	// Create appointment service
-	appointmentService := services.NewAppointmentService(db)
-
-	// Get logged-in dentist ID from context
-	dentistID, exists := c.Get("userID")
+// GetAppointments retrieves the appointments visible to the logged-in user with optional filtering
+func (s *Server) GetAppointments(c *gin.Context) {
+	// Get logged-in user from context
+	caller, exists := currentCaller(c)
 	if !exists {
 		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
 		return
 	}
 
-	// Get query parameters for filtering
-	date := c.Query("date")
-	status := c.Query("status")
-	patientIDStr := c.Query("patientId")
-
-	// Convert parameters to pointers as expected by the service method
-	var datePtr *string
-	var statusPtr *string
-	var patientIDPtr *int
-
-	if date != "" {
-		datePtr = &date
-	}
-
-	if status != "" {
-		statusPtr = &status
+	// Convert query parameters to the pointers expected by the filter
+	var filter repository.AppointmentFilter
+	for param, dst := range map[string]**string{
+		"date":   &filter.Date,
+		"from":   &filter.From,
+		"to":     &filter.To,
+		"status": &filter.Status,
+	} {
+		if value := c.Query(param); value != "" {
+			*dst = &value
+		}
 	}
 
-	if patientIDStr != "" {
+	if patientIDStr := c.Query("patientId"); patientIDStr != "" {
 		patientIDInt, err := strconv.Atoi(patientIDStr)
 		if err != nil {
 			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid patient ID"})
 			return
 		}
-		patientIDPtr = &patientIDInt
+		filter.PatientID = &patientIDInt
+	}
+
+	if dentistIDStr := c.Query("dentistId"); dentistIDStr != "" {
+		dentistIDInt, err := strconv.Atoi(dentistIDStr)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid dentist ID"})
+			return
+		}
+		filter.DentistID = &dentistIDInt
 	}
 
 	// Get appointments from service
-	appointments, err := appointmentService.GetAllAppointments(dentistID.(int), datePtr, statusPtr, patientIDPtr)
+	appointments, err := s.appointments.GetAllAppointments(c.Request.Context(), caller, filter)
 	if err != nil {
+		if _, ok := err.(*services.ValidationError); ok {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		if _, ok := err.(*services.ForbiddenError); ok {
+			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+			return
+		}
 		log.Printf("Error retrieving appointments: %v", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve appointments", "details": err.Error()})
 		return
@@ -103,16 +103,10 @@ func GetAppointments(c *gin.Context) {
 	c.JSON(http.StatusOK, appointments)
 }
 
-// GetAppointment retrieves a single appointment by ID for the logged-in dentist
-func GetAppointment(c *gin.Context) {
-	// Get database connection from the shared database package
-	db := database.GetDB()
-
-	// Create appointment service
-	appointmentService := services.NewAppointmentService(db)
-
-	// Get logged-in dentist ID from context
-	dentistID, exists := c.Get("userID")
+// GetAppointment retrieves a single appointment by ID visible to the logged-in user
+func (s *Server) GetAppointment(c *gin.Context) {
+	// Get logged-in user from context
+	caller, exists := currentCaller(c)
 	if !exists {
 		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
 		return
@@ -126,7 +120,7 @@ func GetAppointment(c *gin.Context) {
 	}
 
 	// Get appointment from service
-	appointment, err := appointmentService.GetAppointmentByID(appointmentID, dentistID.(int))
+	appointment, err := s.appointments.GetAppointmentByID(c.Request.Context(), appointmentID, caller)
 	if err != nil {
 		log.Printf("Error retrieving appointment: %v", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve appointment", "details": err.Error()})
@@ -141,16 +135,71 @@ func GetAppointment(c *gin.Context) {
 	c.JSON(http.StatusOK, appointment)
 }
 
-// CreateAppointment creates a new appointment associated with the logged-in dentist
-func CreateAppointment(c *gin.Context) {
-	// Get database connection from the shared database package
-	db := database.GetDB()
+// GetAppointmentHistory handles GET /api/appointments/:id/history
+func (s *Server) GetAppointmentHistory(c *gin.Context) {
+	// Get logged-in user from context
+	caller, exists := currentCaller(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	appointmentID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid appointment ID"})
+		return
+	}
 
-	// Create appointment service
-	appointmentService := services.NewAppointmentService(db)
+	events, err := s.appointments.GetAppointmentHistory(c.Request.Context(), appointmentID, caller)
+	if err != nil {
+		log.Printf("Error retrieving appointment history: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve appointment history"})
+		return
+	}
 
-	// Get logged-in dentist ID from context
-	dentistID, exists := c.Get("userID")
+	if events == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Appointment not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, events)
+}
+
+// GetAppointmentReminders handles GET /api/appointments/:id/reminders
+func (s *Server) GetAppointmentReminders(c *gin.Context) {
+	// Get logged-in user from context
+	caller, exists := currentCaller(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	appointmentID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid appointment ID"})
+		return
+	}
+
+	deliveries, err := s.reminders.GetReminders(c.Request.Context(), appointmentID, caller)
+	if err != nil {
+		log.Printf("Error retrieving appointment reminders: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve appointment reminders"})
+		return
+	}
+
+	if deliveries == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Appointment not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, deliveries)
+}
+
+// CreateAppointment creates a new appointment for the logged-in dentist or, when staff
+// or an admin is booking, for the dentist named in the request
+func (s *Server) CreateAppointment(c *gin.Context) {
+	// Get logged-in user from context
+	caller, exists := currentCaller(c)
 	if !exists {
 		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
 		return
@@ -163,13 +212,23 @@ func CreateAppointment(c *gin.Context) {
 	}
 
 	// Create appointment through service
-	newAppointment, err := appointmentService.CreateAppointment(req, dentistID.(int))
+	newAppointment, err := s.appointments.CreateAppointment(c.Request.Context(), req, caller)
 	if err != nil {
 		// Check if it's a validation error
 		if _, ok := err.(*services.ValidationError); ok {
 			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 			return
 		}
+		// Check if the caller may book into the requested calendar
+		if _, ok := err.(*services.ForbiddenError); ok {
+			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+			return
+		}
+		// Check if the slot is already taken
+		if conflictErr, ok := err.(*services.ConflictError); ok {
+			c.JSON(http.StatusConflict, gin.H{"error": conflictErr.Message, "conflictingAppointment": conflictErr.Conflicting})
+			return
+		}
 		log.Printf("Error creating appointment: %v", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create appointment", "details": err.Error()})
 		return
@@ -178,16 +237,10 @@ func CreateAppointment(c *gin.Context) {
 	c.JSON(http.StatusCreated, newAppointment)
 }
 
-// UpdateAppointment updates an existing appointment for the logged-in dentist
-func UpdateAppointment(c *gin.Context) {
-	// Get database connection from the shared database package
-	db := database.GetDB()
-
-	// Create appointment service
-	appointmentService := services.NewAppointmentService(db)
-
-	// Get logged-in dentist ID from context
-	dentistID, exists := c.Get("userID")
+// UpdateAppointment updates an existing appointment visible to the logged-in user
+func (s *Server) UpdateAppointment(c *gin.Context) {
+	// Get logged-in user from context
+	caller, exists := currentCaller(c)
 	if !exists {
 		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
 		return
@@ -206,14 +259,26 @@ func UpdateAppointment(c *gin.Context) {
 		return
 	}
 
+	// Edits to following occurrences or a whole series report conflicts per occurrence
+	if scope := c.Query("scope"); scope != "" && scope != string(models.SeriesScopeThis) {
+		result, err := s.series.UpdateOccurrences(c.Request.Context(), appointmentID, scope, req, caller)
+		s.respondSeriesChange(c, result, err, "Failed to update appointments")
+		return
+	}
+
 	// Update appointment through service
-	updatedAppointment, err := appointmentService.UpdateAppointment(appointmentID, req, dentistID.(int))
+	updatedAppointment, err := s.appointments.UpdateAppointment(c.Request.Context(), appointmentID, req, caller)
 	if err != nil {
 		// Check if it's a validation error
 		if _, ok := err.(*services.ValidationError); ok {
 			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 			return
 		}
+		// Check if the slot is already taken
+		if conflictErr, ok := err.(*services.ConflictError); ok {
+			c.JSON(http.StatusConflict, gin.H{"error": conflictErr.Message, "conflictingAppointment": conflictErr.Conflicting})
+			return
+		}
 		// Check if appointment was not found
 		if err == sql.ErrNoRows {
 			c.JSON(http.StatusNotFound, gin.H{"error": "Appointment not found"})
@@ -232,16 +297,10 @@ func UpdateAppointment(c *gin.Context) {
 	c.JSON(http.StatusOK, updatedAppointment)
 }
 
-// DeleteAppointment deletes an appointment for the logged-in dentist
-func DeleteAppointment(c *gin.Context) {
-	// Get database connection from the shared database package
-	db := database.GetDB()
-
-	// Create appointment service
-	appointmentService := services.NewAppointmentService(db)
-
-	// Get logged-in dentist ID from context
-	dentistID, exists := c.Get("userID")
+// DeleteAppointment deletes an appointment visible to the logged-in user
+func (s *Server) DeleteAppointment(c *gin.Context) {
+	// Get logged-in user from context
+	caller, exists := currentCaller(c)
 	if !exists {
 		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
 		return
@@ -255,7 +314,7 @@ func DeleteAppointment(c *gin.Context) {
 	}
 
 	// Delete appointment through service
-	err = appointmentService.DeleteAppointment(appointmentID, dentistID.(int))
+	err = s.appointments.DeleteAppointment(c.Request.Context(), appointmentID, caller)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			c.JSON(http.StatusNotFound, gin.H{"error": "Appointment not found"})
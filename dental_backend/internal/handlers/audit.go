@@ -0,0 +1,444 @@
+// dental_backend/internal/handlers/audit.go
+package handlers
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/csv"
+	"encoding/hex"
+	"encoding/json"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"dental_backend/internal/audit"
+	"dental_backend/internal/models"
+	"dental_backend/internal/rbac"
+	"dental_backend/internal/repository"
+	"dental_backend/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// auditRecordKey is the context key of the request's *auditRecord
+const auditRecordKey = "auditRecord"
+
+// auditLoader reads a record the audit trail compares before and after a write
+type auditLoader func(s *Server, ctx context.Context, caller services.Caller, id int) (interface{}, error)
+
+// auditedRecords are the records whose changes the audit trail captures, keyed by
+// the route of the record. Actions under it, such as /api/appointments/:id/cancel,
+// are compared against the same record, and creations posted to its collection
+// against the record they return.
+var auditedRecords = map[string]auditLoader{
+	"/api/patients/:id": func(s *Server, ctx context.Context, _ services.Caller, id int) (interface{}, error) {
+		return s.loadAuditedPatient(ctx, id)
+	},
+	"/api/appointments/:id": func(s *Server, ctx context.Context, caller services.Caller, id int) (interface{}, error) {
+		return s.appointments.GetAppointmentByID(ctx, id, caller)
+	},
+	"/api/treatments/:id": func(s *Server, ctx context.Context, _ services.Caller, id int) (interface{}, error) {
+		return s.treatments.GetTreatmentByID(ctx, id)
+	},
+	"/api/patient-treatments/:id": func(s *Server, ctx context.Context, _ services.Caller, id int) (interface{}, error) {
+		return s.treatments.GetPatientTreatmentByID(ctx, id)
+	},
+	"/api/billing/invoices/:id": func(s *Server, ctx context.Context, _ services.Caller, id int) (interface{}, error) {
+		return s.billing.GetInvoiceByID(ctx, id)
+	},
+	"/api/billing/claims/:id": func(s *Server, ctx context.Context, _ services.Caller, id int) (interface{}, error) {
+		return s.billing.GetInsuranceClaimByID(ctx, id)
+	},
+	"/api/waitlist/:id": func(s *Server, ctx context.Context, _ services.Caller, id int) (interface{}, error) {
+		return s.waitlist.GetEntry(ctx, id)
+	},
+	"/api/users/:id": func(s *Server, ctx context.Context, _ services.Caller, id int) (interface{}, error) {
+		return s.users.GetUser(ctx, id)
+	},
+	"/api/schedules/:dentistId": func(s *Server, ctx context.Context, _ services.Caller, id int) (interface{}, error) {
+		return s.schedules.GetSchedule(ctx, id)
+	},
+	"/api/closures/:id": func(s *Server, ctx context.Context, _ services.Caller, id int) (interface{}, error) {
+		return s.schedules.GetClosureByID(ctx, id)
+	},
+	"/api/clinics/:id": func(s *Server, ctx context.Context, _ services.Caller, id int) (interface{}, error) {
+		return s.clinics.GetClinic(ctx, id)
+	},
+}
+
+// auditRecord collects what the audit trail needs to know about one request
+type auditRecord struct {
+	server     *Server
+	permission *rbac.Permission
+
+	// load reads the record the request changes; nil when it changes none the
+	// trail compares. id is that record, and before its state beforehand.
+	load   auditLoader
+	caller services.Caller
+	id     int
+	before interface{}
+
+	// body keeps the response of a creation, which holds the new record's ID
+	body *bodyRecorder
+
+	// diff holds what the request changed in the record, once it has been served
+	diff json.RawMessage
+}
+
+// bodyRecorder keeps a copy of the response body
+type bodyRecorder struct {
+	gin.ResponseWriter
+	body []byte
+}
+
+func (w *bodyRecorder) Write(data []byte) (int, error) {
+	w.body = append(w.body, data...)
+	return w.ResponseWriter.Write(data)
+}
+
+// createdID returns the id field of the JSON response, or 0
+func (w *bodyRecorder) createdID() int {
+	var created struct {
+		ID int `json:"id"`
+	}
+	if err := json.Unmarshal(w.body, &created); err != nil {
+		return 0
+	}
+	return created.ID
+}
+
+// noteAuthorized tells the audit trail which permission let the request through,
+// and keeps a copy of the record it is about to change
+func noteAuthorized(c *gin.Context, permission rbac.Permission) {
+	value, exists := c.Get(auditRecordKey)
+	if !exists {
+		return
+	}
+	record := value.(*auditRecord)
+	record.permission = &permission
+	if permission.Action == rbac.ActionRead {
+		return
+	}
+
+	route, param := recordRoute(c.FullPath())
+	load, ok := auditedRecords[route]
+	if !ok {
+		return
+	}
+	record.caller, _ = currentCaller(c)
+
+	// A creation; the new record is read once the response names it
+	if param == "" {
+		record.load = load
+		record.body = &bodyRecorder{ResponseWriter: c.Writer}
+		c.Writer = record.body
+		return
+	}
+
+	id, err := strconv.Atoi(c.Param(param))
+	if err != nil {
+		return
+	}
+	before, err := load(record.server, c.Request.Context(), record.caller, id)
+	if err != nil {
+		log.Printf("Error reading %s for the audit trail: %v", route, err)
+		return
+	}
+	record.load, record.id, record.before = load, id, before
+}
+
+// noteServed compares the record the request changed with its state beforehand.
+// It runs while the request is still limited to its clinic, which the record
+// is only visible within.
+func noteServed(c *gin.Context) {
+	value, exists := c.Get(auditRecordKey)
+	if !exists {
+		return
+	}
+	record := value.(*auditRecord)
+	record.diff = record.changes(context.WithoutCancel(c.Request.Context()), c)
+}
+
+// recordRoute returns the route of the record a route works on and the name of
+// its ID parameter. A route without parameters is a collection, whose records
+// are at route/:id; the parameter name is then empty.
+func recordRoute(route string) (string, string) {
+	segments := strings.Split(route, "/")
+	for i, segment := range segments {
+		if strings.HasPrefix(segment, ":") {
+			return strings.Join(segments[:i+1], "/"), segment[1:]
+		}
+	}
+	return route + "/:id", ""
+}
+
+// changes compares the record before and after the request, or returns nil
+// when the request did not change an audited record
+func (r *auditRecord) changes(ctx context.Context, c *gin.Context) json.RawMessage {
+	if r.load == nil || c.Writer.Status() >= http.StatusMultipleChoices {
+		return nil
+	}
+
+	var after interface{}
+	if c.Request.Method != http.MethodDelete {
+		if r.id == 0 && r.body != nil {
+			r.id = r.body.createdID()
+		}
+		if r.id == 0 {
+			return nil
+		}
+
+		var err error
+		after, err = r.load(r.server, ctx, r.caller, r.id)
+		if err != nil {
+			log.Printf("Error reading %s for the audit trail: %v", c.FullPath(), err)
+			return nil
+		}
+	}
+
+	changes, err := audit.Diff(r.before, after)
+	if err != nil {
+		log.Printf("Error comparing %s for the audit trail: %v", c.FullPath(), err)
+		return nil
+	}
+	return changes
+}
+
+// auditTrail records every API request in the audit log once it has been served:
+// the caller, the permission it used, the record it touched and, for writes,
+// what changed. Requests carry an X-Request-ID, taken from the client when it
+// sends a usable one.
+func (s *Server) auditTrail() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		// The entry is written after the clinic connection has been released,
+		// and even when the client has gone away; the log is not kept apart by clinic
+		ctx := context.WithoutCancel(c.Request.Context())
+
+		requestID := c.GetHeader("X-Request-ID")
+		if !validRequestID(requestID) {
+			requestID = newRequestID()
+		}
+		c.Header("X-Request-ID", requestID)
+
+		record := &auditRecord{server: s}
+		c.Set(auditRecordKey, record)
+
+		c.Next()
+
+		entry := models.AuditEntry{
+			Action:       auditAction(c.Request.Method),
+			ResourceType: auditResourceType(c.FullPath()),
+			Method:       c.Request.Method,
+			Route:        c.FullPath(),
+			Status:       c.Writer.Status(),
+			Changes:      record.diff,
+			RequestID:    requestID,
+			IPAddress:    c.ClientIP(),
+		}
+		if record.permission != nil {
+			entry.Action = string(record.permission.Action)
+			entry.ResourceType = string(record.permission.Resource)
+		}
+		if record.id != 0 {
+			entry.ResourceID = strconv.Itoa(record.id)
+		} else {
+			entry.ResourceID = routeID(c)
+		}
+		if userID, exists := c.Get("userID"); exists {
+			id := userID.(int)
+			entry.ActorID = &id
+			entry.ActorRole = c.GetString("userRole")
+		}
+		if clinicID, exists := c.Get("clinicID"); exists {
+			id := clinicID.(int)
+			entry.ClinicID = &id
+		}
+
+		if err := s.audit.Record(ctx, entry); err != nil {
+			log.Printf("Error writing audit entry for request %s: %v", requestID, err)
+		}
+	}
+}
+
+// validRequestID reports whether a client-supplied request ID can be kept
+func validRequestID(id string) bool {
+	if id == "" || len(id) > 64 {
+		return false
+	}
+	for _, r := range id {
+		if !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '-' || r == '_' || r == '.') {
+			return false
+		}
+	}
+	return true
+}
+
+// newRequestID returns a random request ID
+func newRequestID() string {
+	raw := make([]byte, 16)
+	if _, err := rand.Read(raw); err != nil {
+		return strconv.FormatInt(time.Now().UnixNano(), 36)
+	}
+	return hex.EncodeToString(raw)
+}
+
+// auditAction names what a request without a permission did by its method
+func auditAction(method string) string {
+	switch method {
+	case http.MethodGet, http.MethodHead:
+		return string(rbac.ActionRead)
+	case http.MethodPut, http.MethodPatch:
+		return string(rbac.ActionUpdate)
+	case http.MethodDelete:
+		return string(rbac.ActionDelete)
+	}
+	return string(rbac.ActionCreate)
+}
+
+// auditResourceType names what a request without a permission worked on by
+// the first segment of its route, such as "auth" or "public"
+func auditResourceType(route string) string {
+	segments := strings.Split(strings.TrimPrefix(route, "/api/"), "/")
+	return segments[0]
+}
+
+// routeID returns the first ID parameter of the route. Tokens in routes are
+// credentials, so they are never recorded.
+func routeID(c *gin.Context) string {
+	for _, param := range c.Params {
+		if param.Key == "id" || strings.HasSuffix(param.Key, "Id") {
+			return param.Value
+		}
+	}
+	return ""
+}
+
+// GetAuditLog handles GET /api/audit with optional actorId, action, resourceType,
+// resourceId, from, to, limit and offset filters. Entries of the current clinic
+// are returned newest first, as JSON or, with format=csv, as a CSV download.
+func (s *Server) GetAuditLog(c *gin.Context) {
+	clinicID := c.GetInt("clinicID")
+	filter := repository.AuditFilter{
+		ClinicID:     &clinicID,
+		Action:       c.Query("action"),
+		ResourceType: c.Query("resourceType"),
+		ResourceID:   c.Query("resourceId"),
+	}
+
+	for param, dst := range map[string]*int{"limit": &filter.Limit, "offset": &filter.Offset} {
+		if value := c.Query(param); value != "" {
+			n, err := strconv.Atoi(value)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid " + param})
+				return
+			}
+			*dst = n
+		}
+	}
+	if value := c.Query("actorId"); value != "" {
+		actorID, err := strconv.Atoi(value)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid actor ID"})
+			return
+		}
+		filter.ActorID = &actorID
+	}
+
+	// Dates cover the whole day; "to" is inclusive
+	for param, dst := range map[string]**time.Time{"from": &filter.From, "to": &filter.To} {
+		value := c.Query(param)
+		if value == "" {
+			continue
+		}
+		t, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			day, dayErr := time.Parse("2006-01-02", value)
+			if dayErr != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": param + " must be a date (YYYY-MM-DD) or an RFC 3339 time"})
+				return
+			}
+			if param == "to" {
+				day = day.AddDate(0, 0, 1)
+			}
+			t = day
+		}
+		*dst = &t
+	}
+
+	entries, err := s.audit.GetEntries(c.Request.Context(), filter)
+	if err != nil {
+		log.Printf("Error retrieving audit log: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve audit log"})
+		return
+	}
+
+	if c.Query("format") == "csv" {
+		writeAuditCSV(c, entries)
+		return
+	}
+	c.JSON(http.StatusOK, entries)
+}
+
+// writeAuditCSV sends entries as a CSV download
+func writeAuditCSV(c *gin.Context, entries []models.AuditEntry) {
+	c.Header("Content-Type", "text/csv; charset=utf-8")
+	c.Header("Content-Disposition", `attachment; filename="audit-log.csv"`)
+	c.Status(http.StatusOK)
+
+	optional := func(v *int) string {
+		if v == nil {
+			return ""
+		}
+		return strconv.Itoa(*v)
+	}
+
+	w := csv.NewWriter(c.Writer)
+	w.Write([]string{"id", "occurredAt", "clinicId", "actorId", "actorRole", "action", "resourceType", "resourceId",
+		"method", "route", "status", "changes", "requestId", "ipAddress", "prevHash", "hash"})
+	for _, e := range entries {
+		w.Write([]string{
+			strconv.FormatInt(e.ID, 10),
+			e.OccurredAt.UTC().Format(time.RFC3339Nano),
+			optional(e.ClinicID),
+			optional(e.ActorID),
+			e.ActorRole,
+			e.Action,
+			e.ResourceType,
+			csvCell(e.ResourceID),
+			e.Method,
+			e.Route,
+			strconv.Itoa(e.Status),
+			csvCell(string(e.Changes)),
+			csvCell(e.RequestID),
+			e.IPAddress,
+			e.PrevHash,
+			e.Hash,
+		})
+	}
+	w.Flush()
+	if err := w.Error(); err != nil {
+		log.Printf("Error writing audit CSV: %v", err)
+	}
+}
+
+// csvCell keeps spreadsheets from reading a value as a formula
+func csvCell(value string) string {
+	if value != "" && strings.ContainsRune("=+-@\t\r", rune(value[0])) {
+		return "'" + value
+	}
+	return value
+}
+
+// VerifyAuditLog handles GET /api/audit/verify, checking the hash chain of the whole log
+func (s *Server) VerifyAuditLog(c *gin.Context) {
+	result, err := s.audit.Verify(c.Request.Context())
+	if err != nil {
+		log.Printf("Error verifying audit log: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to verify audit log"})
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
@@ -2,21 +2,18 @@
 package handlers
 
 import (
-	"fmt"
+	"errors"
+	"log"
 	"net/http"
-	"os"
 	"time"
-	"strings"
-	"encoding/base64"
-	"encoding/json"
-	"io"
 
-	"dental_backend/internal/database"
+	"dental_backend/internal/idtoken"
 	"dental_backend/internal/models"
+	"dental_backend/internal/repository"
+	"dental_backend/internal/repository/postgres"
+	"dental_backend/internal/services"
 
 	"github.com/gin-gonic/gin"
-	"github.com/golang-jwt/jwt/v5"
-	"golang.org/x/crypto/bcrypt"
 )
 
 // LoginRequest represents the login request payload
@@ -31,14 +28,20 @@ type RegisterRequest struct {
 	Password  string `json:"password" binding:"required,min=6"`
 	FirstName string `json:"firstName" binding:"required"`
 	LastName  string `json:"lastName" binding:"required"`
-	Role      string `json:"role" binding:"required,oneof=dentist hygienist admin staff"`
 	Phone     string `json:"phone"`
 }
 
 // AuthResponse represents the response for authentication endpoints
 type AuthResponse struct {
-	Token string      `json:"token"`
-	User  models.User `json:"user"`
+	// Token is the short-lived access token sent as the Bearer header
+	Token        string      `json:"token"`
+	RefreshToken string      `json:"refreshToken"`
+	ExpiresAt    time.Time   `json:"expiresAt"`
+	User         models.User `json:"user"`
+	// Clinic is the clinic the token works in, with the user's role there
+	Clinic *models.UserClinic `json:"clinic,omitempty"`
+	// BackupCodes is only set when signing in enabled two-factor authentication
+	BackupCodes []string `json:"backupCodes,omitempty"`
 }
 
 // GoogleLoginRequest represents the Google login request payload
@@ -46,173 +49,125 @@ type GoogleLoginRequest struct {
 	IDToken string `json:"idToken" binding:"required"`
 }
 
-// Register handles user registration
-func Register(c *gin.Context) {
+// Register handles user registration. Self-registered accounts get the staff
+// role but join no clinic, so they cannot sign in until an admin adds them to one;
+// only admins creating users under /api/users choose a role.
+func (s *Server) Register(c *gin.Context) {
 	var req RegisterRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
 
-	// Get database connection from the shared database package
-	db := database.GetDB()
-
-	// Check if user already exists
-	var existingUserID int
-	err := db.QueryRow("SELECT id FROM users WHERE email = $1", req.Email).Scan(&existingUserID)
-	if err != nil && err.Error() != "sql: no rows in result set" {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
-		return
-	}
-	if existingUserID != 0 {
-		c.JSON(http.StatusConflict, gin.H{"error": "User already exists"})
-		return
-	}
-
-	// Hash the password
-	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(req.Password), bcrypt.DefaultCost)
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to hash password"})
-		return
-	}
-
-	// Create the user
-	var newUser models.User
-	err = db.QueryRow(
-		"INSERT INTO users (email, password_hash, first_name, last_name, role, phone) VALUES ($1, $2, $3, $4, $5, $6) RETURNING id, email, first_name, last_name, role, phone, created_at, updated_at",
-		req.Email, string(hashedPassword), req.FirstName, req.LastName, req.Role, req.Phone,
-	).Scan(&newUser.ID, &newUser.Email, &newUser.FirstName, &newUser.LastName, &newUser.Role, &newUser.Phone, &newUser.CreatedAt, &newUser.UpdatedAt)
-
+	// Create the user; the email address starts unverified
+	newUser, err := s.users.CreateUser(c.Request.Context(), models.CreateUserRequest{
+		Email:     req.Email,
+		Password:  req.Password,
+		FirstName: req.FirstName,
+		LastName:  req.LastName,
+		Role:      string(models.UserRoleStaff),
+		Phone:     req.Phone,
+	})
 	if err != nil {
+		if _, ok := err.(*services.ConflictError); ok {
+			c.JSON(http.StatusConflict, gin.H{"error": "User already exists"})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create user"})
 		return
 	}
 
-	// Generate JWT token
-	token, err := generateToken(newUser)
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate token"})
-		return
-	}
+	// Email a verification link
+	s.sendVerification(c, newUser)
+
+	registrationPending(c, newUser)
+}
 
-	c.JSON(http.StatusCreated, AuthResponse{
-		Token: token,
-		User:  newUser,
+// registrationPending responds to a self-registration, which waits for an admin
+// to add the new account to a clinic
+func registrationPending(c *gin.Context, user *models.User) {
+	c.JSON(http.StatusCreated, gin.H{
+		"message":         "Your account has been created. An administrator must add you to a clinic before you can sign in",
+		"pendingApproval": true,
+		"user":            user,
 	})
 }
 
 // Login handles user login
-func Login(c *gin.Context) {
+func (s *Server) Login(c *gin.Context) {
 	var req LoginRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
 
-	// Get database connection from the shared database package
-	db := database.GetDB()
-
-	// Find user by email
-	var user models.User
-	var passwordHash string
-	err := db.QueryRow(
-		"SELECT id, email, password_hash, first_name, last_name, role, phone, created_at, updated_at FROM users WHERE email = $1",
-		req.Email,
-	).Scan(&user.ID, &user.Email, &passwordHash, &user.FirstName, &user.LastName, &user.Role, &user.Phone, &user.CreatedAt, &user.UpdatedAt)
-
+	// Find the user by email and check their password
+	user, err := s.users.Authenticate(c.Request.Context(), req.Email, req.Password)
 	if err != nil {
-		if err.Error() == "sql: no rows in result set" {
+		if errors.Is(err, services.ErrInvalidCredentials) {
 			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid credentials"})
 			return
 		}
+		log.Printf("Error finding user to sign in: %v", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
 		return
 	}
 
-	// Check password
-	if err := bcrypt.CompareHashAndPassword([]byte(passwordHash), []byte(req.Password)); err != nil {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid credentials"})
-		return
-	}
-
-	// Generate JWT token
-	token, err := generateToken(user)
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate token"})
+	// Check the account may sign in
+	clinic, ok := s.checkSignIn(c, *user)
+	if !ok {
 		return
 	}
 
-	c.JSON(http.StatusOK, AuthResponse{
-		Token: token,
-		User:  user,
-	})
+	// Ask for a second factor or start a session
+	s.signIn(c, *user, *clinic, http.StatusOK)
 }
 
 // GoogleLogin handles Google login
-func GoogleLogin(c *gin.Context) {
-	// Log the incoming request
-	fmt.Printf("Google login request received. Method: %s, Content-Type: %s\n", c.Request.Method, c.GetHeader("Content-Type"))
-	
+func (s *Server) GoogleLogin(c *gin.Context) {
 	var req GoogleLoginRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		fmt.Printf("Error binding JSON: %v\n", err)
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request format", "details": err.Error()})
 		return
 	}
 
-	// Log the received request for debugging
-	fmt.Printf("Received Google login request with IDToken: %.50s...\n", req.IDToken) // Only log first 50 chars
-
 	// Check if IDToken is empty
 	if req.IDToken == "" {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "IDToken is required"})
 		return
 	}
 
-	// Get database connection from the shared database package
-	db := database.GetDB()
-
-	// Verify ID token with Google's API
-	userInfo, err := verifyGoogleIDToken(req.IDToken)
-	if err != nil {
-		fmt.Printf("Error verifying Google ID token: %v\n", err)
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid Google ID token", "details": err.Error()})
+	// Verify the ID token against Google's signing keys
+	userInfo, ok := s.verifyGoogleIDToken(c, req.IDToken)
+	if !ok {
 		return
 	}
 	
-	// Log the user info for debugging
-	fmt.Printf("Verified user info: %+v\n", userInfo)
-	
-	// Check if user already exists
-	var existingUser models.User
-	err = db.QueryRow(
-		"SELECT id, email, first_name, last_name, role, phone, created_at, updated_at FROM users WHERE email = $1",
-		userInfo.Email,
-	).Scan(&existingUser.ID, &existingUser.Email, &existingUser.FirstName, &existingUser.LastName, 
-		&existingUser.Role, &existingUser.Phone, &existingUser.CreatedAt, &existingUser.UpdatedAt)
-
-	if err != nil && err.Error() != "sql: no rows in result set" {
-		fmt.Printf("Database error when checking existing user: %v\n", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error", "details": err.Error()})
+	// Find the user linked to the Google account, or with its verified address
+	// when Google accounts are auto-linked
+	profile := userInfo.profile()
+	profile.AutoLink = s.cfg.GoogleAutoLink
+	existingUser, err := s.identities.Find(c.Request.Context(), profile)
+	if err != nil {
+		if _, ok := err.(*services.ConflictError); ok {
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+			return
+		}
+		log.Printf("Error finding the user of a Google account: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
 		return
 	}
 
 	// If user exists, log them in
-	if existingUser.ID != 0 {
-		fmt.Printf("Existing user found: %+v\n", existingUser)
-		
-		// Generate JWT token
-		token, err := generateToken(existingUser)
-		if err != nil {
-			fmt.Printf("Error generating token: %v\n", err)
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate token", "details": err.Error()})
+	if existingUser != nil {
+		// Check the account may sign in
+		clinic, ok := s.checkSignIn(c, *existingUser)
+		if !ok {
 			return
 		}
-
-		c.JSON(http.StatusOK, AuthResponse{
-			Token: token,
-			User:  existingUser,
-		})
+		
+		// Ask for a second factor or start a session
+		s.signIn(c, *existingUser, *clinic, http.StatusOK)
 		return
 	}
 	
@@ -229,78 +184,77 @@ func GoogleLogin(c *gin.Context) {
 // GoogleRegisterRequest represents the Google registration request payload
 type GoogleRegisterRequest struct {
 	IDToken string `json:"IDToken" binding:"required"`
-	Role    string `json:"role" binding:"required,oneof=dentist hygienist admin staff"`
 	Phone   string `json:"phone"`
 }
 
-// GoogleRegister handles Google registration for new users
-func GoogleRegister(c *gin.Context) {
+// GoogleRegister handles Google registration for new users, who wait for an
+// admin to add them to a clinic like other self-registered accounts
+func (s *Server) GoogleRegister(c *gin.Context) {
 	var req GoogleRegisterRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
 
-	// Get database connection from the shared database package
-	db := database.GetDB()
-
-	// Verify ID token with Google's API
-	userInfo, err := verifyGoogleIDToken(req.IDToken)
-	if err != nil {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid Google ID token", "details": err.Error()})
+	// Verify the ID token against Google's signing keys
+	userInfo, ok := s.verifyGoogleIDToken(c, req.IDToken)
+	if !ok {
 		return
 	}
 	
-	// Check if user already exists
-	var existingUser models.User
-	err = db.QueryRow(
-		"SELECT id, email, first_name, last_name, role, phone, created_at, updated_at FROM users WHERE email = $1",
-		userInfo.Email,
-	).Scan(&existingUser.ID, &existingUser.Email, &existingUser.FirstName, &existingUser.LastName, 
-		&existingUser.Role, &existingUser.Phone, &existingUser.CreatedAt, &existingUser.UpdatedAt)
-
-	if err != nil && err.Error() != "sql: no rows in result set" {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error", "details": err.Error()})
+	// Create the user and link the Google account to it
+	profile := userInfo.profile()
+	profile.Role = string(models.UserRoleStaff)
+	profile.Phone = req.Phone
+	newUser, err := s.identities.Register(c.Request.Context(), profile)
+	if err != nil {
+		if _, ok := err.(*services.ConflictError); ok {
+			c.JSON(http.StatusConflict, gin.H{"error": "User already exists"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create user"})
 		return
 	}
 
-	// If user already exists, return error
-	if existingUser.ID != 0 {
-		c.JSON(http.StatusConflict, gin.H{"error": "User already exists"})
+	registrationPending(c, newUser)
+}
+
+// LinkGoogleAccount handles POST /api/auth/identities/google, linking the Google
+// account an ID token is for to the signed-in user
+func (s *Server) LinkGoogleAccount(c *gin.Context) {
+	// Get logged-in user from context
+	caller, exists := currentCaller(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
 		return
 	}
-	
-	// Create new user with Google info and additional fields
-	var phone *string = nil
-	if req.Phone != "" {
-		phone = &req.Phone
+
+	var req GoogleLoginRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
 	}
-	
-	var newUser models.User
-	err = db.QueryRow(
-		"INSERT INTO users (email, password_hash, first_name, last_name, role, phone, google_id) VALUES ($1, $2, $3, $4, $5, $6, $7) RETURNING id, email, first_name, last_name, role, phone, created_at, updated_at",
-		userInfo.Email, "", userInfo.GivenName, userInfo.FamilyName, req.Role, phone, userInfo.ID,
-	).Scan(&newUser.ID, &newUser.Email, &newUser.FirstName, &newUser.LastName, &newUser.Role, &newUser.Phone, &newUser.CreatedAt, &newUser.UpdatedAt)
 
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create user", "details": err.Error()})
+	// Verify the ID token against Google's signing keys
+	userInfo, ok := s.verifyGoogleIDToken(c, req.IDToken)
+	if !ok {
 		return
 	}
-	
-	// Generate JWT token
-	token, err := generateToken(newUser)
+
+	identity, err := s.identities.Link(c.Request.Context(), caller.UserID, userInfo.profile())
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate token", "details": err.Error()})
+		if _, ok := err.(*services.ConflictError); ok {
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+			return
+		}
+		log.Printf("Error linking Google account: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to link Google account"})
 		return
 	}
 
-	c.JSON(http.StatusCreated, AuthResponse{
-		Token: token,
-		User:  newUser,
-	})
+	c.JSON(http.StatusOK, identity)
 }
 
-// GetCurrentUser returns the current authenticated user
 // GoogleUserInfo represents user info from Google
 type GoogleUserInfo struct {
 	ID            string `json:"id"`
@@ -313,141 +267,173 @@ type GoogleUserInfo struct {
 	Locale        string `json:"locale"`
 }
 
-// verifyGoogleIDToken verifies a Google ID token and returns user info
-func verifyGoogleIDToken(idToken string) (*GoogleUserInfo, error) {
-	// First, try to decode as a standard Google ID token
-	url := "https://oauth2.googleapis.com/tokeninfo?id_token=" + idToken
-	fmt.Printf("Making request to Google tokeninfo endpoint: %.100s...\n", url) // Log first 100 chars
-	
-	resp, err := http.Get(url)
-	if err != nil {
-		fmt.Printf("Error making request to Google: %v\n", err)
-		// If standard verification fails, try to decode as our custom credential
-		return decodeCustomCredential(idToken)
+// profile returns the Google account as an external profile
+func (u *GoogleUserInfo) profile() models.ExternalProfile {
+	return models.ExternalProfile{
+		Provider:      "google",
+		Subject:       u.ID,
+		Email:         u.Email,
+		EmailVerified: u.VerifiedEmail,
+		FirstName:     u.GivenName,
+		LastName:      u.FamilyName,
 	}
-	defer resp.Body.Close()
+}
 
-	// Check if request was successful
-	fmt.Printf("Google API response status: %d\n", resp.StatusCode)
-	if resp.StatusCode != http.StatusOK {
-		fmt.Printf("Google API returned non-OK status: %d\n", resp.StatusCode)
-		// If standard verification fails, try to decode as our custom credential
-		return decodeCustomCredential(idToken)
+// verifyGoogleIDToken checks a Google ID token's signature, audience, issuer and
+// expiry and returns the user it identifies, writing the error response when the
+// token is not accepted
+func (s *Server) verifyGoogleIDToken(c *gin.Context, idToken string) (*GoogleUserInfo, bool) {
+	if s.google == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Google sign-in is not configured"})
+		return nil, false
 	}
 
-	// Read response body
-	body, err := io.ReadAll(resp.Body)
+	claims, err := s.google.Verify(c.Request.Context(), idToken)
 	if err != nil {
-		fmt.Printf("Error reading response body: %v\n", err)
-		return nil, err
+		if errors.Is(err, idtoken.ErrKeysUnavailable) {
+			log.Printf("Error fetching Google signing keys: %v", err)
+			c.JSON(http.StatusBadGateway, gin.H{"error": "Could not verify Google ID token"})
+			return nil, false
+		}
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid Google ID token", "details": err.Error()})
+		return nil, false
 	}
-	
-	fmt.Printf("Google API response body length: %d\n", len(body))
 
-	// Parse JSON response
-	var userInfo GoogleUserInfo
-	if err := json.Unmarshal(body, &userInfo); err != nil {
-		fmt.Printf("Error unmarshaling JSON response: %v\n", err)
-		return nil, err
-	}
+	// Check if email is verified
+	if claims.Email == "" || !claims.EmailVerified {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid Google ID token", "details": "email not verified"})
+		return nil, false
+	}
+
+	return &GoogleUserInfo{
+		ID:            claims.Subject,
+		Email:         claims.Email,
+		VerifiedEmail: claims.EmailVerified,
+		Name:          claims.Name,
+		GivenName:     claims.GivenName,
+		FamilyName:    claims.FamilyName,
+		Picture:       claims.Picture,
+	}, true
+}
 
-	// Log some info about the user
-	fmt.Printf("User email from Google: %s, verified: %t\n", userInfo.Email, userInfo.VerifiedEmail)
+// GetCurrentUser returns the current authenticated user
+func (s *Server) GetCurrentUser(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
 
-	// Check if email is verified
-	if !userInfo.VerifiedEmail {
-		return nil, fmt.Errorf("email not verified")
+	user, err := s.users.GetUser(c.Request.Context(), userID.(int))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		return
+	}
+	if user == nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not found"})
+		return
 	}
 
-	return &userInfo, nil
+	c.JSON(http.StatusOK, user)
 }
 
-// decodeCustomCredential decodes our custom credential format
-func decodeCustomCredential(credential string) (*GoogleUserInfo, error) {
-	// Decode base64
-	decoded, err := base64.StdEncoding.DecodeString(credential)
-	if err != nil {
-		return nil, fmt.Errorf("failed to decode credential: %v", err)
+// checkSignIn returns the clinic the user signs in to, or writes the error response
+// when they may not sign in. Deactivated accounts keep their history but cannot sign
+// in, and the clinic's auth policy may require a verified email address.
+func (s *Server) checkSignIn(c *gin.Context, user models.User) (*models.UserClinic, bool) {
+	if !user.Active {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Account is deactivated"})
+		return nil, false
 	}
-	
-	// Parse JSON
-	var userInfo GoogleUserInfo
-	if err := json.Unmarshal(decoded, &userInfo); err != nil {
-		return nil, fmt.Errorf("failed to parse credential JSON: %v", err)
+
+	clinic, err := s.sessions.SignInClinic(c.Request.Context(), user.ID)
+	if err != nil {
+		sessionStartFailed(c, err)
+		return nil, false
 	}
-	
-	// Validate required fields
-	if userInfo.Email == "" {
-		return nil, fmt.Errorf("credential missing email")
+
+	required, err := s.accounts.VerificationRequired(c.Request.Context(), user, *clinic)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		return nil, false
 	}
-	
-	// If given_name or family_name are missing, try to extract from name
-	if userInfo.GivenName == "" && userInfo.FamilyName == "" && userInfo.Name != "" {
-		parts := strings.Split(userInfo.Name, " ")
-		if len(parts) >= 2 {
-			userInfo.GivenName = parts[0]
-			userInfo.FamilyName = parts[1]
-		} else {
-			userInfo.GivenName = userInfo.Name
-		}
+	if required {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Email address is not verified", "verificationRequired": true})
+		return nil, false
 	}
-	
-	return &userInfo, nil
+
+	return clinic, true
 }
 
-func GetCurrentUser(c *gin.Context) {
-	userID, exists := c.Get("userID")
-	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+// signIn finishes a sign-in into the clinic that passed the password or Google
+// check. Users with two-factor enabled, or whose role at the clinic the policy
+// requires it for, get a challenge to answer at /api/auth/mfa/verify; everyone
+// else gets a session straight away.
+func (s *Server) signIn(c *gin.Context, user models.User, clinic models.UserClinic, status int) {
+	challenge, err := s.mfa.Challenge(c.Request.Context(), user, clinic)
+	if err != nil {
+		log.Printf("Error creating two-factor challenge: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to sign in"})
+		return
+	}
+	if challenge != nil {
+		c.JSON(http.StatusOK, gin.H{
+			"mfaRequired":        true,
+			"mfaToken":           challenge.Token,
+			"expiresAt":          challenge.ExpiresAt,
+			"enrollmentRequired": challenge.EnrollmentRequired,
+		})
 		return
 	}
 
-	// Get database connection from the shared database package
-	db := database.GetDB()
-
-	var user models.User
-	err := db.QueryRow(
-		"SELECT id, email, first_name, last_name, role, phone, created_at, updated_at FROM users WHERE id = $1",
-		userID,
-	).Scan(&user.ID, &user.Email, &user.FirstName, &user.LastName, &user.Role, &user.Phone, &user.CreatedAt, &user.UpdatedAt)
-
+	// Start a session and issue its tokens
+	response, err := s.startSession(c, user)
 	if err != nil {
-		if err.Error() == "sql: no rows in result set" {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "User not found"})
-			return
-		}
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		sessionStartFailed(c, err)
 		return
 	}
 
-	c.JSON(http.StatusOK, user)
+	c.JSON(status, response)
 }
 
-// generateToken creates a JWT token for a user
-func generateToken(user models.User) (string, error) {
-	// Get the secret key from environment variables
-	secret := os.Getenv("JWT_SECRET")
-	if secret == "" {
-		secret = "dental_secret_key" // fallback for development
+// startSession opens a session for an authenticated user and builds the response carrying its tokens
+func (s *Server) startSession(c *gin.Context, user models.User) (*AuthResponse, error) {
+	pair, err := s.sessions.Start(c.Request.Context(), user, sessionClient(c))
+	if err != nil {
+		return nil, err
 	}
 
-	// Create the Claims
-	claims := jwt.MapClaims{
-		"user_id":   user.ID,
-		"user_role": user.Role,
-		"exp":       time.Now().Add(time.Hour * 72).Unix(), // 72 hours
-		"iat":       time.Now().Unix(),
-	}
+	// Attribute the sign-in to the user in the audit trail
+	c.Set("userID", user.ID)
+	c.Set("userRole", pair.Clinic.Role)
+	c.Set("clinicID", pair.Clinic.ClinicID)
 
-	// Create token
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	return &AuthResponse{
+		Token:        pair.AccessToken,
+		RefreshToken: pair.RefreshToken,
+		ExpiresAt:    pair.ExpiresAt,
+		User:         user,
+		Clinic:       &pair.Clinic,
+	}, nil
+}
 
-	// Generate encoded token
-	return token.SignedString([]byte(secret))
+// sessionStartFailed responds to an error from startSession
+func sessionStartFailed(c *gin.Context, err error) {
+	if errors.Is(err, services.ErrNoClinic) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Your account is not assigned to a clinic"})
+		return
+	}
+	log.Printf("Error starting session: %v", err)
+	c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate token"})
+}
+
+// sessionClient describes the device making the request
+func sessionClient(c *gin.Context) models.SessionClient {
+	return models.SessionClient{UserAgent: c.Request.UserAgent(), IPAddress: c.ClientIP()}
 }
 
 // AuthMiddleware validates JWT tokens
-func AuthMiddleware() gin.HandlerFunc {
+func (s *Server) AuthMiddleware() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		// Get the token from the Authorization header
 		authHeader := c.GetHeader("Authorization")
@@ -467,59 +453,89 @@ func AuthMiddleware() gin.HandlerFunc {
 		// Extract the token
 		tokenString := authHeader[7:]
 
-		// Get the secret key
-		secret := os.Getenv("JWT_SECRET")
-		if secret == "" {
-			secret = "dental_secret_key" // fallback for development
-		}
-
-		// Parse the token
-		token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
-			// Validate the alg is what we expect
-			if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-				return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
-			}
-			return []byte(secret), nil
-		})
-
+		// Verify the token and that its session has not been revoked
+		claims, err := s.sessions.Authenticate(c.Request.Context(), tokenString)
 		if err != nil {
+			if !errors.Is(err, services.ErrInvalidSession) {
+				log.Printf("Error checking session: %v", err)
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to verify session"})
+				c.Abort()
+				return
+			}
 			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid token"})
 			c.Abort()
 			return
 		}
 
-		// Check if the token is valid
-		if claims, ok := token.Claims.(jwt.MapClaims); ok && token.Valid {
-			// Add user ID to the context
-			if userID, ok := claims["user_id"].(float64); ok {
-				c.Set("userID", int(userID))
-				c.Set("userRole", claims["user_role"])
-				c.Next()
-				return
-			}
+		// Add user ID to the context
+		c.Set("userID", claims.UserID)
+		c.Set("userRole", string(claims.Role))
+		c.Set("sessionID", claims.SessionID)
+
+		// Attribute writes made while serving this request to the user
+		c.Request = c.Request.WithContext(repository.WithActor(c.Request.Context(), claims.UserID))
+
+		// Limit the request to the token's clinic
+		release, ok := s.scopeClinic(c, claims.ClinicID)
+		if !ok {
+			return
 		}
+		defer release()
+		c.Next()
+	}
+}
+
+// scopeClinic limits the rest of the request to one clinic's records. The
+// returned func releases the database connection the scope pins; when the scope
+// cannot be set up it responds and reports false.
+func (s *Server) scopeClinic(c *gin.Context, clinicID int) (func(), bool) {
+	c.Set("clinicID", clinicID)
+	if s.db == nil {
+		c.Request = c.Request.WithContext(repository.WithClinic(c.Request.Context(), clinicID))
+		return func() {}, true
+	}
 
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid token claims"})
+	ctx, release, err := postgres.ScopeClinic(c.Request.Context(), s.db, clinicID)
+	if err != nil {
+		log.Printf("Error scoping request to clinic %d: %v", clinicID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to open clinic"})
 		c.Abort()
+		return nil, false
 	}
+	c.Request = c.Request.WithContext(ctx)
+	return release, true
 }
 
-// RoleMiddleware checks if the user has the required role
-func RoleMiddleware(requiredRole string) gin.HandlerFunc {
+// allClinics opens every clinic's records to the rest of the request, for the
+// patient self-service links and calendar feeds, whose tokens name the records
+// they may reach
+func (s *Server) allClinics() gin.HandlerFunc {
 	return func(c *gin.Context) {
-		userRole, exists := c.Get("userRole")
-		if !exists {
-			c.JSON(http.StatusForbidden, gin.H{"error": "Forbidden"})
-			c.Abort()
+		if s.db == nil {
+			c.Request = c.Request.WithContext(repository.WithAllClinics(c.Request.Context()))
+			c.Next()
 			return
 		}
 
-		if userRole != requiredRole && userRole != "admin" {
-			c.JSON(http.StatusForbidden, gin.H{"error": "Insufficient permissions"})
+		ctx, release, err := postgres.ScopeAllClinics(c.Request.Context(), s.db)
+		if err != nil {
+			log.Printf("Error opening every clinic to the request: %v", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to open clinic"})
 			c.Abort()
 			return
 		}
+		defer release()
 
+		c.Request = c.Request.WithContext(ctx)
 		c.Next()
 	}
 }
+
+// currentCaller returns the authenticated user set by AuthMiddleware
+func currentCaller(c *gin.Context) (services.Caller, bool) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		return services.Caller{}, false
+	}
+	return services.Caller{UserID: userID.(int), Role: models.UserRole(c.GetString("userRole"))}, true
+}
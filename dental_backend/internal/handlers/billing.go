@@ -7,23 +7,15 @@ import (
 	"strconv"
 	"time"
 
-	"dental_backend/internal/database"
 	"dental_backend/internal/models"
-	"dental_backend/internal/services"
 
 	"github.com/gin-gonic/gin"
 )
 
 // GetBillingStats retrieves billing statistics for the dashboard
-func GetBillingStats(c *gin.Context) {
-	// Get database connection from the shared database package
-	db := database.GetDB()
-
-	// Create billing service
-	billingService := services.NewBillingService(db)
-
+func (s *Server) GetBillingStats(c *gin.Context) {
 	// Get billing stats from service
-	stats, err := billingService.GetBillingStats()
+	stats, err := s.billing.GetBillingStats(c.Request.Context())
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve billing statistics"})
 		return
@@ -46,13 +38,7 @@ func GetBillingStats(c *gin.Context) {
 }
 
 // GetInvoices retrieves all invoices with optional filtering
-func GetInvoices(c *gin.Context) {
-	// Get database connection from the shared database package
-	db := database.GetDB()
-
-	// Create billing service
-	billingService := services.NewBillingService(db)
-
+func (s *Server) GetInvoices(c *gin.Context) {
 	// Get query parameters for filtering
 	status := c.Query("status")
 	patientID := c.Query("patientId")
@@ -63,7 +49,7 @@ func GetInvoices(c *gin.Context) {
 		patientIDFilter = patientID
 	}
 
-	invoices, err := billingService.GetAllInvoices(status, patientIDFilter)
+	invoices, err := s.billing.GetAllInvoices(c.Request.Context(), status, patientIDFilter)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve invoices"})
 		return
@@ -116,13 +102,7 @@ func GetInvoices(c *gin.Context) {
 }
 
 // GetInvoice retrieves a single invoice by ID
-func GetInvoice(c *gin.Context) {
-	// Get database connection from the shared database package
-	db := database.GetDB()
-
-	// Create billing service
-	billingService := services.NewBillingService(db)
-
+func (s *Server) GetInvoice(c *gin.Context) {
 	id := c.Param("id")
 	invoiceID, err := strconv.Atoi(id)
 	if err != nil {
@@ -131,7 +111,7 @@ func GetInvoice(c *gin.Context) {
 	}
 
 	// Get invoice from service
-	invoice, err := billingService.GetInvoiceByID(invoiceID)
+	invoice, err := s.billing.GetInvoiceByID(c.Request.Context(), invoiceID)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			c.JSON(http.StatusNotFound, gin.H{"error": "Invoice not found"})
@@ -177,13 +157,7 @@ func GetInvoice(c *gin.Context) {
 }
 
 // CreateInvoice creates a new invoice
-func CreateInvoice(c *gin.Context) {
-	// Get database connection from the shared database package
-	db := database.GetDB()
-
-	// Create billing service
-	billingService := services.NewBillingService(db)
-
+func (s *Server) CreateInvoice(c *gin.Context) {
 	var req models.CreateInvoiceRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
@@ -200,7 +174,7 @@ func CreateInvoice(c *gin.Context) {
 	}
 
 	// Create invoice through service
-	newInvoice, err := billingService.CreateInvoice(req)
+	newInvoice, err := s.billing.CreateInvoice(c.Request.Context(), req)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create invoice"})
 		return
@@ -237,13 +211,7 @@ func CreateInvoice(c *gin.Context) {
 }
 
 // UpdateInvoice updates an existing invoice
-func UpdateInvoice(c *gin.Context) {
-	// Get database connection from the shared database package
-	db := database.GetDB()
-
-	// Create billing service
-	billingService := services.NewBillingService(db)
-
+func (s *Server) UpdateInvoice(c *gin.Context) {
 	id := c.Param("id")
 	invoiceID, err := strconv.Atoi(id)
 	if err != nil {
@@ -258,7 +226,7 @@ func UpdateInvoice(c *gin.Context) {
 	}
 
 	// Update invoice through service
-	updatedInvoice, err := billingService.UpdateInvoice(invoiceID, req)
+	updatedInvoice, err := s.billing.UpdateInvoice(c.Request.Context(), invoiceID, req)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			c.JSON(http.StatusNotFound, gin.H{"error": "Invoice not found"})
@@ -299,13 +267,7 @@ func UpdateInvoice(c *gin.Context) {
 }
 
 // DeleteInvoice deletes an invoice by ID
-func DeleteInvoice(c *gin.Context) {
-	// Get database connection from the shared database package
-	db := database.GetDB()
-
-	// Create billing service
-	billingService := services.NewBillingService(db)
-
+func (s *Server) DeleteInvoice(c *gin.Context) {
 	id := c.Param("id")
 	invoiceID, err := strconv.Atoi(id)
 	if err != nil {
@@ -314,7 +276,7 @@ func DeleteInvoice(c *gin.Context) {
 	}
 
 	// Delete invoice through service
-	err = billingService.DeleteInvoice(invoiceID)
+	err = s.billing.DeleteInvoice(c.Request.Context(), invoiceID)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			c.JSON(http.StatusNotFound, gin.H{"error": "Invoice not found"})
@@ -328,13 +290,7 @@ func DeleteInvoice(c *gin.Context) {
 }
 
 // GetInsuranceClaims retrieves all insurance claims with optional filtering
-func GetInsuranceClaims(c *gin.Context) {
-	// Get database connection from the shared database package
-	db := database.GetDB()
-
-	// Create billing service
-	billingService := services.NewBillingService(db)
-
+func (s *Server) GetInsuranceClaims(c *gin.Context) {
 	// Get query parameters for filtering
 	status := c.Query("status")
 	patientID := c.Query("patientId")
@@ -345,7 +301,7 @@ func GetInsuranceClaims(c *gin.Context) {
 		patientIDFilter = patientID
 	}
 
-	claims, err := billingService.GetAllInsuranceClaims(status, patientIDFilter)
+	claims, err := s.billing.GetAllInsuranceClaims(c.Request.Context(), status, patientIDFilter)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve insurance claims"})
 		return
@@ -401,13 +357,7 @@ func GetInsuranceClaims(c *gin.Context) {
 }
 
 // GetInsuranceClaim retrieves a single insurance claim by ID
-func GetInsuranceClaim(c *gin.Context) {
-	// Get database connection from the shared database package
-	db := database.GetDB()
-
-	// Create billing service
-	billingService := services.NewBillingService(db)
-
+func (s *Server) GetInsuranceClaim(c *gin.Context) {
 	id := c.Param("id")
 	claimID, err := strconv.Atoi(id)
 	if err != nil {
@@ -416,7 +366,7 @@ func GetInsuranceClaim(c *gin.Context) {
 	}
 
 	// Get insurance claim from service
-	claim, err := billingService.GetInsuranceClaimByID(claimID)
+	claim, err := s.billing.GetInsuranceClaimByID(c.Request.Context(), claimID)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			c.JSON(http.StatusNotFound, gin.H{"error": "Insurance claim not found"})
@@ -464,13 +414,7 @@ func GetInsuranceClaim(c *gin.Context) {
 }
 
 // CreateInsuranceClaim creates a new insurance claim
-func CreateInsuranceClaim(c *gin.Context) {
-	// Get database connection from the shared database package
-	db := database.GetDB()
-
-	// Create billing service
-	billingService := services.NewBillingService(db)
-
+func (s *Server) CreateInsuranceClaim(c *gin.Context) {
 	var req models.CreateInsuranceClaimRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
@@ -487,7 +431,7 @@ func CreateInsuranceClaim(c *gin.Context) {
 	}
 
 	// Create insurance claim through service
-	newClaim, err := billingService.CreateInsuranceClaim(req)
+	newClaim, err := s.billing.CreateInsuranceClaim(c.Request.Context(), req)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create insurance claim"})
 		return
@@ -526,13 +470,7 @@ func CreateInsuranceClaim(c *gin.Context) {
 }
 
 // UpdateInsuranceClaim updates an existing insurance claim
-func UpdateInsuranceClaim(c *gin.Context) {
-	// Get database connection from the shared database package
-	db := database.GetDB()
-
-	// Create billing service
-	billingService := services.NewBillingService(db)
-
+func (s *Server) UpdateInsuranceClaim(c *gin.Context) {
 	id := c.Param("id")
 	claimID, err := strconv.Atoi(id)
 	if err != nil {
@@ -547,7 +485,7 @@ func UpdateInsuranceClaim(c *gin.Context) {
 	}
 
 	// Update insurance claim through service
-	updatedClaim, err := billingService.UpdateInsuranceClaim(claimID, req)
+	updatedClaim, err := s.billing.UpdateInsuranceClaim(c.Request.Context(), claimID, req)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			c.JSON(http.StatusNotFound, gin.H{"error": "Insurance claim not found"})
@@ -590,13 +528,7 @@ func UpdateInsuranceClaim(c *gin.Context) {
 }
 
 // DeleteInsuranceClaim deletes an insurance claim by ID
-func DeleteInsuranceClaim(c *gin.Context) {
-	// Get database connection from the shared database package
-	db := database.GetDB()
-
-	// Create billing service
-	billingService := services.NewBillingService(db)
-
+func (s *Server) DeleteInsuranceClaim(c *gin.Context) {
 	id := c.Param("id")
 	claimID, err := strconv.Atoi(id)
 	if err != nil {
@@ -605,7 +537,7 @@ func DeleteInsuranceClaim(c *gin.Context) {
 	}
 
 	// Delete insurance claim through service
-	err = billingService.DeleteInsuranceClaim(claimID)
+	err = s.billing.DeleteInsuranceClaim(c.Request.Context(), claimID)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			c.JSON(http.StatusNotFound, gin.H{"error": "Insurance claim not found"})
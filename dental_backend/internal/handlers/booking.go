@@ -0,0 +1,107 @@
+// dental_backend/internal/handlers/booking.go
+package handlers
+
+import (
+	"log"
+	"net/http"
+	"strconv"
+
+	"dental_backend/internal/models"
+	"dental_backend/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetBookableAppointmentTypes handles GET /api/public/booking/appointment-types
+func (s *Server) GetBookableAppointmentTypes(c *gin.Context) {
+	types, err := s.booking.GetAppointmentTypes(c.Request.Context())
+	if err != nil {
+		log.Printf("Error retrieving appointment types: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve appointment types"})
+		return
+	}
+
+	c.JSON(http.StatusOK, types)
+}
+
+// GetBookableDentists handles GET /api/public/booking/dentists
+func (s *Server) GetBookableDentists(c *gin.Context) {
+	dentists, err := s.booking.GetDentists(c.Request.Context())
+	if err != nil {
+		log.Printf("Error retrieving dentists: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve dentists"})
+		return
+	}
+
+	// Ensure we always return an array, even if empty
+	if dentists == nil {
+		dentists = []models.Dentist{}
+	}
+
+	c.JSON(http.StatusOK, dentists)
+}
+
+// GetBookingSlots handles GET /api/public/booking/slots?appointmentTypeId=&date=&dentistId=
+func (s *Server) GetBookingSlots(c *gin.Context) {
+	typeID, err := strconv.Atoi(c.Query("appointmentTypeId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid appointment type ID"})
+		return
+	}
+
+	date := c.Query("date")
+	if date == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "date is required"})
+		return
+	}
+
+	var dentistID *int
+	if value := c.Query("dentistId"); value != "" {
+		id, err := strconv.Atoi(value)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid dentist ID"})
+			return
+		}
+		dentistID = &id
+	}
+
+	slots, err := s.booking.GetSlots(c.Request.Context(), typeID, date, dentistID)
+	if err != nil {
+		if _, ok := err.(*services.ValidationError); ok {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		log.Printf("Error computing booking slots: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve available slots"})
+		return
+	}
+
+	c.JSON(http.StatusOK, slots)
+}
+
+// CreateOnlineBooking handles POST /api/public/booking. Conflict details name other
+// patients, so they are never passed on to the public.
+func (s *Server) CreateOnlineBooking(c *gin.Context) {
+	var req models.OnlineBookingRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	booking, err := s.booking.Book(c.Request.Context(), req, c.ClientIP())
+	if err != nil {
+		if _, ok := err.(*services.ValidationError); ok {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		if _, ok := err.(*services.ConflictError); ok {
+			c.JSON(http.StatusConflict, gin.H{"error": "That time is no longer available"})
+			return
+		}
+		log.Printf("Error creating online booking: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create booking"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, booking)
+}
@@ -0,0 +1,155 @@
+// dental_backend/internal/handlers/calendar.go
+package handlers
+
+import (
+	"database/sql"
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"dental_backend/internal/models"
+	"dental_backend/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetCalendarFeeds handles GET /api/calendar/feeds
+func (s *Server) GetCalendarFeeds(c *gin.Context) {
+	// Get logged-in user from context
+	caller, exists := currentCaller(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	feeds, err := s.calendar.GetFeeds(c.Request.Context(), caller)
+	if err != nil {
+		log.Printf("Error retrieving calendar feeds: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve calendar feeds"})
+		return
+	}
+
+	c.JSON(http.StatusOK, feeds)
+}
+
+// CreateCalendarFeed handles POST /api/calendar/feeds. The response is the only
+// time the feed token is shown.
+func (s *Server) CreateCalendarFeed(c *gin.Context) {
+	// Get logged-in user from context
+	caller, exists := currentCaller(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	var req models.CreateCalendarFeedRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	feed, err := s.calendar.CreateFeed(c.Request.Context(), req, caller)
+	if err != nil {
+		if _, ok := err.(*services.ValidationError); ok {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		if _, ok := err.(*services.ForbiddenError); ok {
+			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+			return
+		}
+		log.Printf("Error creating calendar feed: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create calendar feed"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, feed)
+}
+
+// RevokeCalendarFeed handles DELETE /api/calendar/feeds/:id
+func (s *Server) RevokeCalendarFeed(c *gin.Context) {
+	// Get logged-in user from context
+	caller, exists := currentCaller(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid calendar feed ID"})
+		return
+	}
+
+	if err := s.calendar.RevokeFeed(c.Request.Context(), id, caller); err != nil {
+		if err == sql.ErrNoRows {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Calendar feed not found"})
+			return
+		}
+		log.Printf("Error revoking calendar feed: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to revoke calendar feed"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Calendar feed revoked successfully"})
+}
+
+// GetDentistCalendar handles GET /api/calendar/dentists/:id.ics?token=
+func (s *Server) GetDentistCalendar(c *gin.Context) {
+	s.serveCalendar(c, models.CalendarFeedDentist)
+}
+
+// GetPatientCalendar handles GET /api/calendar/patients/:id.ics?token=
+func (s *Server) GetPatientCalendar(c *gin.Context) {
+	s.serveCalendar(c, models.CalendarFeedPatient)
+}
+
+// serveCalendar writes the iCalendar feed named by the :file parameter ("<id>.ics"),
+// authorized by the token query parameter. The token is looked up across every
+// clinic; the feed is then rendered limited to the clinic it was issued at.
+func (s *Server) serveCalendar(c *gin.Context, feedType models.CalendarFeedType) {
+	idStr, ok := strings.CutSuffix(c.Param("file"), ".ics")
+	if !ok {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Calendar not found"})
+		return
+	}
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid calendar ID"})
+		return
+	}
+
+	feed, err := s.calendar.OpenFeed(c.Request.Context(), feedType, id, c.Query("token"))
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidFeed) {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid or revoked calendar token"})
+			return
+		}
+		log.Printf("Error checking calendar feed token: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve calendar"})
+		return
+	}
+
+	// The feed shows only the clinic it was issued at
+	release, ok := s.scopeClinic(c, feed.ClinicID)
+	if !ok {
+		return
+	}
+	defer release()
+
+	calendar, err := s.calendar.Feed(c.Request.Context(), feed)
+	if err != nil {
+		log.Printf("Error rendering calendar feed: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve calendar"})
+		return
+	}
+
+	c.Header("Content-Type", "text/calendar; charset=utf-8")
+	c.Header("Cache-Control", "private, no-cache")
+	c.Status(http.StatusOK)
+	if err := calendar.Write(c.Writer); err != nil {
+		log.Printf("Error writing calendar feed: %v", err)
+	}
+}
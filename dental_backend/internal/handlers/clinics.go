@@ -0,0 +1,248 @@
+// dental_backend/internal/handlers/clinics.go
+package handlers
+
+import (
+	"database/sql"
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+
+	"dental_backend/internal/models"
+	"dental_backend/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// respondClinicError writes the error from a clinic management call
+func respondClinicError(c *gin.Context, err error, failure string) {
+	if _, ok := err.(*services.ValidationError); ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if _, ok := err.(*services.ForbiddenError); ok {
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		return
+	}
+	if _, ok := err.(*services.ConflictError); ok {
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		return
+	}
+	log.Printf("Error managing clinic: %v", err)
+	c.JSON(http.StatusInternalServerError, gin.H{"error": failure})
+}
+
+// GetMyClinics handles GET /api/auth/clinics, listing the clinics the current user works at
+func (s *Server) GetMyClinics(c *gin.Context) {
+	// Get logged-in user from context
+	caller, exists := currentCaller(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	clinics, err := s.sessions.Clinics(c.Request.Context(), caller.UserID)
+	if err != nil {
+		respondClinicError(c, err, "Failed to retrieve clinics")
+		return
+	}
+
+	c.JSON(http.StatusOK, clinics)
+}
+
+// SwitchClinic handles POST /api/auth/clinic, moving the current session to
+// another clinic. The response carries a new access token; the refresh token
+// is unchanged.
+func (s *Server) SwitchClinic(c *gin.Context) {
+	// Get logged-in user from context
+	caller, exists := currentCaller(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	var req models.SwitchClinicRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	pair, err := s.sessions.SwitchClinic(c.Request.Context(), caller.UserID, c.GetString("sessionID"), req.ClinicID)
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidSession) {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid token"})
+			return
+		}
+		respondClinicError(c, err, "Failed to switch clinic")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"token":     pair.AccessToken,
+		"expiresAt": pair.ExpiresAt,
+		"clinic":    pair.Clinic,
+	})
+}
+
+// CreateClinic handles POST /api/clinics; the caller becomes the new clinic's admin
+func (s *Server) CreateClinic(c *gin.Context) {
+	// Get logged-in user from context
+	caller, exists := currentCaller(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	var req models.CreateClinicRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	clinic, err := s.clinics.CreateClinic(c.Request.Context(), caller, req)
+	if err != nil {
+		respondClinicError(c, err, "Failed to create clinic")
+		return
+	}
+
+	c.JSON(http.StatusCreated, clinic)
+}
+
+// GetCurrentClinic handles GET /api/clinics/current
+func (s *Server) GetCurrentClinic(c *gin.Context) {
+	clinic, err := s.clinics.GetClinic(c.Request.Context(), c.GetInt("clinicID"))
+	if err != nil {
+		respondClinicError(c, err, "Failed to retrieve clinic")
+		return
+	}
+	if clinic == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Clinic not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, clinic)
+}
+
+// UpdateCurrentClinic handles PUT /api/clinics/current
+func (s *Server) UpdateCurrentClinic(c *gin.Context) {
+	var req models.UpdateClinicRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	clinic, err := s.clinics.UpdateClinic(c.Request.Context(), c.GetInt("clinicID"), req)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Clinic not found"})
+			return
+		}
+		respondClinicError(c, err, "Failed to update clinic")
+		return
+	}
+
+	c.JSON(http.StatusOK, clinic)
+}
+
+// GetClinicMembers handles GET /api/clinics/current/members
+func (s *Server) GetClinicMembers(c *gin.Context) {
+	members, err := s.clinics.GetMembers(c.Request.Context(), c.GetInt("clinicID"))
+	if err != nil {
+		respondClinicError(c, err, "Failed to retrieve clinic members")
+		return
+	}
+
+	c.JSON(http.StatusOK, members)
+}
+
+// SetClinicMember handles PUT /api/clinics/current/members/:userId, adding a
+// user to the current clinic or changing their role there
+func (s *Server) SetClinicMember(c *gin.Context) {
+	userID, err := strconv.Atoi(c.Param("userId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return
+	}
+
+	var req models.SetClinicMemberRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := s.clinics.SetMember(c.Request.Context(), c.GetInt("clinicID"), userID, req); err != nil {
+		if err == sql.ErrNoRows {
+			c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+			return
+		}
+		respondClinicError(c, err, "Failed to update clinic member")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Clinic member updated"})
+}
+
+// RemoveClinicMember handles DELETE /api/clinics/current/members/:userId
+func (s *Server) RemoveClinicMember(c *gin.Context) {
+	// Get logged-in user from context
+	caller, exists := currentCaller(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	userID, err := strconv.Atoi(c.Param("userId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return
+	}
+
+	if err := s.clinics.RemoveMember(c.Request.Context(), caller, c.GetInt("clinicID"), userID); err != nil {
+		if err == sql.ErrNoRows {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Clinic member not found"})
+			return
+		}
+		respondClinicError(c, err, "Failed to remove clinic member")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Clinic member removed"})
+}
+
+// bookingClinic limits public booking requests to the clinic named by the
+// clinicId query parameter, or to the default clinic
+func (s *Server) bookingClinic() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var clinic *models.Clinic
+		var err error
+		if value := c.Query("clinicId"); value != "" {
+			id, convErr := strconv.Atoi(value)
+			if convErr != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid clinic ID"})
+				c.Abort()
+				return
+			}
+			clinic, err = s.clinics.GetClinic(c.Request.Context(), id)
+		} else {
+			clinic, err = s.clinics.DefaultClinic(c.Request.Context())
+		}
+		if err != nil {
+			log.Printf("Error retrieving booking clinic: %v", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve clinic"})
+			c.Abort()
+			return
+		}
+		if clinic == nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Clinic not found"})
+			c.Abort()
+			return
+		}
+
+		release, ok := s.scopeClinic(c, clinic.ID)
+		if !ok {
+			return
+		}
+		defer release()
+		c.Next()
+	}
+}
@@ -0,0 +1,62 @@
+// dental_backend/internal/handlers/dashboard.go
+package handlers
+
+import (
+	"net/http"
+
+	"dental_backend/internal/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetDashboardStats handles GET /api/dashboard/stats
+func (s *Server) GetDashboardStats(c *gin.Context) {
+	// Get patient stats from service
+	patientStats, err := s.patients.GetPatientStats(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve patient statistics"})
+		return
+	}
+
+	// Get logged-in user from context
+	caller, exists := currentCaller(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	// Get today's appointments count; staff and admins see the whole clinic
+	todaysAppointments, err := s.appointments.GetTodaysAppointments(c.Request.Context(), caller)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve today's appointments"})
+		return
+	}
+
+	// Get pending treatments count
+	var pendingTreatments []models.PatientTreatment
+	if !caller.ClinicWide() {
+		pendingTreatments, err = s.treatments.GetTreatmentQueueForDentist(c.Request.Context(), caller.UserID)
+	} else {
+		// For staff and admins, get all treatments
+		pendingTreatments, err = s.treatments.GetTreatmentQueue(c.Request.Context())
+	}
+
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve pending treatments"})
+		return
+	}
+
+	// In a real implementation, this would fetch actual dashboard stats
+	c.JSON(http.StatusOK, gin.H{
+		"todayAppointments": len(todaysAppointments),
+		"activePatients":    patientStats.TotalPatients,
+		"pendingTreatments": len(pendingTreatments),
+		"monthlyRevenue":    48950.00,
+	})
+}
+
+// GetRecentActivity handles GET /api/activity/recent
+func (s *Server) GetRecentActivity(c *gin.Context) {
+	// In a real implementation, this would fetch recent activity from the database
+	c.JSON(http.StatusOK, []interface{}{})
+}
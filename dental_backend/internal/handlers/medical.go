@@ -0,0 +1,191 @@
+// dental_backend/internal/handlers/medical.go
+package handlers
+
+import (
+	"context"
+	"database/sql"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"dental_backend/internal/models"
+	"dental_backend/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// medicalEntryCollections maps the collections under /api/patients/:id to the
+// kind of medical history entry each holds
+var medicalEntryCollections = map[string]models.MedicalEntryKind{
+	"allergies":   models.MedicalEntryAllergy,
+	"medications": models.MedicalEntryMedication,
+	"conditions":  models.MedicalEntryCondition,
+}
+
+// medicalEntryKind returns the kind of entry the route of the request works on
+func medicalEntryKind(c *gin.Context) models.MedicalEntryKind {
+	segments := strings.Split(c.FullPath(), "/")
+	for i, segment := range segments {
+		if segment == ":id" && i+1 < len(segments) {
+			return medicalEntryCollections[segments[i+1]]
+		}
+	}
+	return ""
+}
+
+// respondMedicalError writes the error from a medical history call
+func respondMedicalError(c *gin.Context, err error, notFound, failure string) {
+	if err == sql.ErrNoRows {
+		c.JSON(http.StatusNotFound, gin.H{"error": notFound})
+		return
+	}
+	if _, ok := err.(*services.ValidationError); ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	log.Printf("Error managing medical history: %v", err)
+	c.JSON(http.StatusInternalServerError, gin.H{"error": failure})
+}
+
+// auditedPatient is a patient as the audit trail compares them, with the
+// medical history entries written under their route
+type auditedPatient struct {
+	*models.Patient
+	Allergies   []models.MedicalEntry `json:"allergies,omitempty"`
+	Medications []models.MedicalEntry `json:"medications,omitempty"`
+	Conditions  []models.MedicalEntry `json:"conditions,omitempty"`
+}
+
+// loadAuditedPatient reads a patient and their medical history for the audit trail
+func (s *Server) loadAuditedPatient(ctx context.Context, id int) (interface{}, error) {
+	patient, err := s.patients.GetPatientByID(ctx, id)
+	if err != nil || patient == nil {
+		return nil, err
+	}
+	history, err := s.medical.GetHistory(ctx, id)
+	if err != nil || history == nil {
+		return nil, err
+	}
+	return auditedPatient{Patient: patient, Allergies: history.Allergies, Medications: history.Medications, Conditions: history.Conditions}, nil
+}
+
+// GetMedicalHistory handles GET /api/patients/:id/medical-history, returning the
+// patient's allergies, medications and conditions with their medical alerts
+func (s *Server) GetMedicalHistory(c *gin.Context) {
+	patientID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid patient ID"})
+		return
+	}
+
+	history, err := s.medical.GetHistory(c.Request.Context(), patientID)
+	if err != nil {
+		respondMedicalError(c, err, "Patient not found", "Failed to retrieve medical history")
+		return
+	}
+	if history == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Patient not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, history)
+}
+
+// GetMedicalEntries handles GET /api/patients/:id/allergies, /medications and /conditions
+func (s *Server) GetMedicalEntries(c *gin.Context) {
+	patientID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid patient ID"})
+		return
+	}
+
+	entries, err := s.medical.ListEntries(c.Request.Context(), patientID, medicalEntryKind(c))
+	if err != nil {
+		respondMedicalError(c, err, "Patient not found", "Failed to retrieve medical history")
+		return
+	}
+
+	c.JSON(http.StatusOK, entries)
+}
+
+// CreateMedicalEntry handles POST /api/patients/:id/allergies, /medications and /conditions
+func (s *Server) CreateMedicalEntry(c *gin.Context) {
+	// Get logged-in user from context
+	caller, exists := currentCaller(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	patientID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid patient ID"})
+		return
+	}
+
+	var req models.CreateMedicalEntryRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	entry, err := s.medical.CreateEntry(c.Request.Context(), caller, patientID, medicalEntryKind(c), req)
+	if err != nil {
+		respondMedicalError(c, err, "Patient not found", "Failed to create medical history entry")
+		return
+	}
+
+	c.JSON(http.StatusCreated, entry)
+}
+
+// UpdateMedicalEntry handles PUT /api/patients/:id/allergies/:entryId, and
+// likewise for medications and conditions
+func (s *Server) UpdateMedicalEntry(c *gin.Context) {
+	patientID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid patient ID"})
+		return
+	}
+	entryID, err := strconv.Atoi(c.Param("entryId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid entry ID"})
+		return
+	}
+
+	var req models.UpdateMedicalEntryRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	entry, err := s.medical.UpdateEntry(c.Request.Context(), patientID, medicalEntryKind(c), entryID, req)
+	if err != nil {
+		respondMedicalError(c, err, "Medical history entry not found", "Failed to update medical history entry")
+		return
+	}
+
+	c.JSON(http.StatusOK, entry)
+}
+
+// DeleteMedicalEntry handles DELETE /api/patients/:id/allergies/:entryId, and
+// likewise for medications and conditions
+func (s *Server) DeleteMedicalEntry(c *gin.Context) {
+	patientID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid patient ID"})
+		return
+	}
+	entryID, err := strconv.Atoi(c.Param("entryId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid entry ID"})
+		return
+	}
+
+	if err := s.medical.DeleteEntry(c.Request.Context(), patientID, medicalEntryKind(c), entryID); err != nil {
+		respondMedicalError(c, err, "Medical history entry not found", "Failed to delete medical history entry")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Medical history entry deleted"})
+}
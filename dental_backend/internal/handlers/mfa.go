@@ -0,0 +1,202 @@
+// dental_backend/internal/handlers/mfa.go
+package handlers
+
+import (
+	"errors"
+	"log"
+	"net/http"
+
+	"dental_backend/internal/models"
+	"dental_backend/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// respondMFAError writes the error from a two-factor call
+func respondMFAError(c *gin.Context, err error, failure string) {
+	if errors.Is(err, services.ErrInvalidChallenge) {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Sign-in has expired, please sign in again"})
+		return
+	}
+	if _, ok := err.(*services.ValidationError); ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if _, ok := err.(*services.ForbiddenError); ok {
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		return
+	}
+	if _, ok := err.(*services.ConflictError); ok {
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		return
+	}
+	log.Printf("Error with two-factor authentication: %v", err)
+	c.JSON(http.StatusInternalServerError, gin.H{"error": failure})
+}
+
+// GetMFAStatus handles GET /api/auth/mfa
+func (s *Server) GetMFAStatus(c *gin.Context) {
+	// Get logged-in user from context
+	caller, exists := currentCaller(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	status, err := s.mfa.Status(c.Request.Context(), caller)
+	if err != nil {
+		respondMFAError(c, err, "Failed to retrieve two-factor status")
+		return
+	}
+	if status == nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, status)
+}
+
+// SetupMFA handles POST /api/auth/mfa/setup, returning a new secret to add to an
+// authenticator app. It takes effect once confirmed through /api/auth/mfa/enable.
+func (s *Server) SetupMFA(c *gin.Context) {
+	// Get logged-in user from context
+	caller, exists := currentCaller(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	user, err := s.users.GetUser(c.Request.Context(), caller.UserID)
+	if err != nil {
+		respondMFAError(c, err, "Failed to set up two-factor authentication")
+		return
+	}
+	if user == nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not found"})
+		return
+	}
+
+	setup, err := s.mfa.Setup(c.Request.Context(), *user)
+	if err != nil {
+		respondMFAError(c, err, "Failed to set up two-factor authentication")
+		return
+	}
+
+	c.JSON(http.StatusOK, setup)
+}
+
+// EnableMFA handles POST /api/auth/mfa/enable. The backup codes in the response
+// are not shown again.
+func (s *Server) EnableMFA(c *gin.Context) {
+	// Get logged-in user from context
+	caller, exists := currentCaller(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	var req models.MFACodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	codes, err := s.mfa.Enable(c.Request.Context(), caller.UserID, req.Code)
+	if err != nil {
+		respondMFAError(c, err, "Failed to enable two-factor authentication")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Two-factor authentication enabled", "backupCodes": codes})
+}
+
+// DisableMFA handles POST /api/auth/mfa/disable
+func (s *Server) DisableMFA(c *gin.Context) {
+	// Get logged-in user from context
+	caller, exists := currentCaller(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	var req models.MFACodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := s.mfa.Disable(c.Request.Context(), caller, req.Code); err != nil {
+		respondMFAError(c, err, "Failed to disable two-factor authentication")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Two-factor authentication disabled"})
+}
+
+// RegenerateBackupCodes handles POST /api/auth/mfa/backup-codes, replacing all
+// earlier backup codes
+func (s *Server) RegenerateBackupCodes(c *gin.Context) {
+	// Get logged-in user from context
+	caller, exists := currentCaller(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	var req models.MFACodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	codes, err := s.mfa.RegenerateBackupCodes(c.Request.Context(), caller.UserID, req.Code)
+	if err != nil {
+		respondMFAError(c, err, "Failed to generate backup codes")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"backupCodes": codes})
+}
+
+// EnrollMFAChallenge handles POST /api/auth/mfa/enroll, setting up an authenticator
+// for a user whose login was stopped because the policy requires two-factor
+func (s *Server) EnrollMFAChallenge(c *gin.Context) {
+	var req models.MFAEnrollRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	setup, err := s.mfa.EnrollFromChallenge(c.Request.Context(), req.MFAToken)
+	if err != nil {
+		respondMFAError(c, err, "Failed to set up two-factor authentication")
+		return
+	}
+
+	c.JSON(http.StatusOK, setup)
+}
+
+// VerifyMFAChallenge handles POST /api/auth/mfa/verify, the second step of a login
+func (s *Server) VerifyMFAChallenge(c *gin.Context) {
+	var req models.MFAChallengeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	user, codes, err := s.mfa.CompleteChallenge(c.Request.Context(), req.MFAToken, req.Code)
+	if err != nil {
+		respondMFAError(c, err, "Failed to verify code")
+		return
+	}
+
+	// Start a session and issue its tokens
+	response, err := s.startSession(c, *user)
+	if err != nil {
+		sessionStartFailed(c, err)
+		return
+	}
+	response.BackupCodes = codes
+
+	c.JSON(http.StatusOK, response)
+}
@@ -0,0 +1,339 @@
+// dental_backend/internal/handlers/oidc.go
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"sort"
+	"time"
+
+	"dental_backend/internal/models"
+	"dental_backend/internal/oidc"
+	"dental_backend/internal/services"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const (
+	// oidcStateCookie carries the state, nonce and PKCE verifier of a sign-in in
+	// progress between the login redirect and the callback
+	oidcStateCookie = "oidc_state"
+	oidcStateTTL    = 10 * time.Minute
+	oidcStateType   = "oidc-state"
+)
+
+// oidcState is what a provider sign-in has to remember until the callback.
+// LinkUserID is the signed-in user linking the provider account, or zero for a sign-in.
+type oidcState struct {
+	Provider   string
+	State      string
+	Nonce      string
+	Verifier   string
+	LinkUserID int
+}
+
+// GetSignInProviders handles GET /api/auth/oidc/providers
+func (s *Server) GetSignInProviders(c *gin.Context) {
+	providers := []models.SignInProvider{}
+	for name, provider := range s.oidcProviders {
+		providers = append(providers, models.SignInProvider{
+			Name:        name,
+			DisplayName: provider.DisplayName(),
+			LoginURL:    "/api/auth/oidc/" + name + "/login",
+		})
+	}
+	sort.Slice(providers, func(i, j int) bool { return providers[i].DisplayName < providers[j].DisplayName })
+
+	c.JSON(http.StatusOK, providers)
+}
+
+// OIDCLogin handles GET /api/auth/oidc/:provider/login, redirecting the browser to the provider
+func (s *Server) OIDCLogin(c *gin.Context) {
+	provider, ok := s.oidcProviders[c.Param("provider")]
+	if !ok {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Sign-in provider not found"})
+		return
+	}
+
+	authURL, ok := s.startOIDC(c, provider, 0)
+	if !ok {
+		return
+	}
+	c.Redirect(http.StatusFound, authURL)
+}
+
+// LinkOIDCAccount handles POST /api/auth/oidc/:provider/link. It returns the
+// provider URL for the web app to send the browser to; the callback links the
+// account the user signs in to there to the caller.
+func (s *Server) LinkOIDCAccount(c *gin.Context) {
+	// Get logged-in user from context
+	caller, exists := currentCaller(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	provider, ok := s.oidcProviders[c.Param("provider")]
+	if !ok {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Sign-in provider not found"})
+		return
+	}
+
+	authURL, ok := s.startOIDC(c, provider, caller.UserID)
+	if !ok {
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"url": authURL})
+}
+
+// startOIDC remembers a new sign-in, or a link for linkUserID, in the state
+// cookie and returns the provider URL to send the browser to, writing the error
+// response when it cannot be started
+func (s *Server) startOIDC(c *gin.Context, provider *oidc.Provider, linkUserID int) (string, bool) {
+	// Generate the values the callback checks
+	state := oidcState{Provider: provider.Name(), LinkUserID: linkUserID}
+	for _, value := range []*string{&state.State, &state.Nonce, &state.Verifier} {
+		random, err := oidc.RandomString()
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to start sign-in"})
+			return "", false
+		}
+		*value = random
+	}
+
+	authURL, err := provider.AuthCodeURL(c.Request.Context(), s.oidcRedirectURL(c, provider), state.State, state.Nonce, oidc.Challenge(state.Verifier))
+	if err != nil {
+		log.Printf("Error starting %s sign-in: %v", provider.Name(), err)
+		c.JSON(http.StatusBadGateway, gin.H{"error": "Sign-in provider is unavailable"})
+		return "", false
+	}
+
+	cookie, err := s.signOIDCState(state)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to start sign-in"})
+		return "", false
+	}
+	s.setOIDCCookie(c, cookie, int(oidcStateTTL.Seconds()))
+
+	return authURL, true
+}
+
+// OIDCCallback handles GET /api/auth/oidc/:provider/callback. The browser is sent
+// back to the web app with a single-use code to exchange for tokens, or an error.
+func (s *Server) OIDCCallback(c *gin.Context) {
+	provider, ok := s.oidcProviders[c.Param("provider")]
+	if !ok {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Sign-in provider not found"})
+		return
+	}
+
+	// The state cookie is only good for this one callback
+	cookie, _ := c.Cookie(oidcStateCookie)
+	s.setOIDCCookie(c, "", -1)
+
+	state, err := s.parseOIDCState(cookie)
+	if err != nil || state.Provider != provider.Name() || c.Query("state") != state.State {
+		s.oidcFailure(c, "Sign-in has expired, please try again")
+		return
+	}
+	if providerError := c.Query("error"); providerError != "" {
+		s.oidcFailure(c, "Sign-in was cancelled or refused by "+provider.DisplayName())
+		return
+	}
+
+	identity, err := provider.Exchange(c.Request.Context(), s.oidcRedirectURL(c, provider), c.Query("code"), state.Verifier, state.Nonce)
+	if err != nil {
+		log.Printf("Error completing %s sign-in: %v", provider.Name(), err)
+		if errors.Is(err, oidc.ErrRejected) {
+			s.oidcFailure(c, "Sign-in was refused by "+provider.DisplayName())
+			return
+		}
+		s.oidcFailure(c, provider.DisplayName()+" is unavailable, please try again later")
+		return
+	}
+
+	profile := models.ExternalProfile{
+		Provider:      provider.Name(),
+		Subject:       identity.Subject,
+		Email:         identity.Email,
+		EmailVerified: identity.EmailVerified,
+		FirstName:     identity.FirstName,
+		LastName:      identity.LastName,
+		Role:          identity.Role,
+		AutoLink:      provider.AutoLink(),
+	}
+	if state.LinkUserID != 0 {
+		s.finishOIDCLink(c, provider, state.LinkUserID, profile)
+		return
+	}
+
+	// Find, link or create the user
+	user, err := s.identities.SignIn(c.Request.Context(), profile)
+	if err != nil {
+		switch err.(type) {
+		case *services.ValidationError, *services.ForbiddenError, *services.ConflictError:
+			s.oidcFailure(c, err.Error())
+			return
+		}
+		log.Printf("Error signing in with %s: %v", provider.Name(), err)
+		s.oidcFailure(c, "Failed to sign in")
+		return
+	}
+
+	code, err := s.identities.IssueSignInCode(c.Request.Context(), *user)
+	if err != nil {
+		log.Printf("Error issuing sign-in code: %v", err)
+		s.oidcFailure(c, "Failed to sign in")
+		return
+	}
+
+	c.Redirect(http.StatusFound, s.cfg.AppURL+"/oidc/callback?code="+url.QueryEscape(code))
+}
+
+// finishOIDCLink links the provider account to the user who started the link and
+// sends the browser back to the web app
+func (s *Server) finishOIDCLink(c *gin.Context, provider *oidc.Provider, userID int, profile models.ExternalProfile) {
+	if _, err := s.identities.Link(c.Request.Context(), userID, profile); err != nil {
+		message := "Failed to link your " + provider.DisplayName() + " account"
+		if _, ok := err.(*services.ConflictError); ok {
+			message = err.Error()
+		} else {
+			log.Printf("Error linking %s account: %v", provider.Name(), err)
+		}
+		c.Redirect(http.StatusFound, s.cfg.AppURL+"/oidc/callback?error="+url.QueryEscape(message))
+		return
+	}
+
+	c.Redirect(http.StatusFound, s.cfg.AppURL+"/oidc/callback?linked="+url.QueryEscape(provider.Name()))
+}
+
+// ExchangeSignInCode handles POST /api/auth/oidc/exchange, the web app's last step
+// of a provider sign-in. It responds like a password login.
+func (s *Server) ExchangeSignInCode(c *gin.Context) {
+	var req models.SignInCodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	user, err := s.identities.RedeemSignInCode(c.Request.Context(), req.Code)
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidAccountToken) {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Sign-in has expired, please try again"})
+			return
+		}
+		log.Printf("Error redeeming sign-in code: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to sign in"})
+		return
+	}
+
+	// Check the account may sign in
+	clinic, ok := s.checkSignIn(c, *user)
+	if !ok {
+		return
+	}
+
+	// Ask for a second factor or start a session
+	s.signIn(c, *user, *clinic, http.StatusOK)
+}
+
+// GetMyIdentities handles GET /api/auth/identities, listing the caller's linked provider accounts
+func (s *Server) GetMyIdentities(c *gin.Context) {
+	// Get logged-in user from context
+	caller, exists := currentCaller(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	identities, err := s.identities.ListIdentities(c.Request.Context(), caller.UserID)
+	if err != nil {
+		log.Printf("Error retrieving identities: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve linked accounts"})
+		return
+	}
+
+	c.JSON(http.StatusOK, identities)
+}
+
+// oidcFailure sends the browser back to the web app's login page with a message
+func (s *Server) oidcFailure(c *gin.Context, message string) {
+	c.Redirect(http.StatusFound, s.cfg.AppURL+"/login?error="+url.QueryEscape(message))
+}
+
+// oidcRedirectURL returns the callback URL registered with the provider, derived
+// from the request when none is configured
+func (s *Server) oidcRedirectURL(c *gin.Context, provider *oidc.Provider) string {
+	if redirectURL := provider.RedirectURL(); redirectURL != "" {
+		return redirectURL
+	}
+
+	base := s.cfg.PublicAPIURL
+	if base == "" {
+		scheme := "http"
+		if c.Request.TLS != nil || c.GetHeader("X-Forwarded-Proto") == "https" {
+			scheme = "https"
+		}
+		base = scheme + "://" + c.Request.Host
+	}
+	return base + "/api/auth/oidc/" + provider.Name() + "/callback"
+}
+
+// setOIDCCookie sets or, with a negative maxAge, clears the state cookie
+func (s *Server) setOIDCCookie(c *gin.Context, value string, maxAge int) {
+	secure := c.Request.TLS != nil || c.GetHeader("X-Forwarded-Proto") == "https"
+	c.SetSameSite(http.SameSiteLaxMode)
+	c.SetCookie(oidcStateCookie, value, maxAge, "/api/auth/oidc/", "", secure, true)
+}
+
+// signOIDCState seals a sign-in's state into a signed, short-lived cookie value
+func (s *Server) signOIDCState(state oidcState) (string, error) {
+	now := time.Now()
+	claims := jwt.MapClaims{
+		"typ":      oidcStateType,
+		"provider": state.Provider,
+		"state":    state.State,
+		"nonce":    state.Nonce,
+		"verifier": state.Verifier,
+		"link":     state.LinkUserID,
+		"exp":      now.Add(oidcStateTTL).Unix(),
+		"iat":      now.Unix(),
+	}
+	return jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(s.cfg.JWTSecret))
+}
+
+// parseOIDCState verifies a state cookie value
+func (s *Server) parseOIDCState(value string) (*oidcState, error) {
+	token, err := jwt.Parse(value, func(token *jwt.Token) (interface{}, error) {
+		// Validate the alg is what we expect
+		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
+		}
+		return []byte(s.cfg.JWTSecret), nil
+	})
+	if err != nil || !token.Valid {
+		return nil, errors.New("invalid sign-in state")
+	}
+
+	claims, ok := token.Claims.(jwt.MapClaims)
+	if !ok || claims["typ"] != oidcStateType {
+		return nil, errors.New("invalid sign-in state")
+	}
+
+	state := &oidcState{}
+	state.Provider, _ = claims["provider"].(string)
+	state.State, _ = claims["state"].(string)
+	state.Nonce, _ = claims["nonce"].(string)
+	state.Verifier, _ = claims["verifier"].(string)
+	if link, ok := claims["link"].(float64); ok {
+		state.LinkUserID = int(link)
+	}
+	if state.State == "" || state.Nonce == "" || state.Verifier == "" {
+		return nil, errors.New("invalid sign-in state")
+	}
+	return state, nil
+}
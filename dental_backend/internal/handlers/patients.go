@@ -5,7 +5,6 @@ import (
 	"net/http"
 	"strconv"
 
-	"dental_backend/internal/database"
 	"dental_backend/internal/models"
 	"dental_backend/internal/services"
 
@@ -13,19 +12,13 @@ import (
 )
 
 // GetPatients retrieves all patients with optional filtering
-func GetPatients(c *gin.Context) {
-	// Get database connection from the shared database package
-	db := database.GetDB()
-
-	// Create patient service
-	patientService := services.NewPatientService(db)
-
+func (s *Server) GetPatients(c *gin.Context) {
 	// Get query parameters for filtering
 	search := c.Query("search")
 	status := c.Query("status")
 
 	// Get patients from service
-	patients, err := patientService.GetAllPatients(search, status)
+	patients, err := s.patients.GetAllPatients(c.Request.Context(), search, status)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve patients"})
 		return
@@ -35,13 +28,7 @@ func GetPatients(c *gin.Context) {
 }
 
 // GetPatient retrieves a single patient by ID
-func GetPatient(c *gin.Context) {
-	// Get database connection from the shared database package
-	db := database.GetDB()
-
-	// Create patient service
-	patientService := services.NewPatientService(db)
-
+func (s *Server) GetPatient(c *gin.Context) {
 	id := c.Param("id")
 	patientID, err := strconv.Atoi(id)
 	if err != nil {
@@ -49,8 +36,8 @@ func GetPatient(c *gin.Context) {
 		return
 	}
 
-	// Get patient from service
-	patient, err := patientService.GetPatientByID(patientID)
+	// Get patient with their medical alerts from service
+	patient, err := s.medical.GetPatientDetail(c.Request.Context(), patientID)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve patient"})
 		return
@@ -61,17 +48,17 @@ func GetPatient(c *gin.Context) {
 		return
 	}
 
+	// Add the derived risk level next to the one entered by hand
+	if patient.DerivedRisk, err = s.risk.GetAssessment(c.Request.Context(), patientID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve patient"})
+		return
+	}
+
 	c.JSON(http.StatusOK, patient)
 }
 
 // CreatePatient creates a new patient
-func CreatePatient(c *gin.Context) {
-	// Get database connection from the shared database package
-	db := database.GetDB()
-
-	// Create patient service
-	patientService := services.NewPatientService(db)
-
+func (s *Server) CreatePatient(c *gin.Context) {
 	var req models.CreatePatientRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
@@ -79,8 +66,12 @@ func CreatePatient(c *gin.Context) {
 	}
 
 	// Create patient through service
-	newPatient, err := patientService.CreatePatient(req)
+	newPatient, err := s.patients.CreatePatient(c.Request.Context(), req)
 	if err != nil {
+		if _, ok := err.(*services.ValidationError); ok {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create patient"})
 		return
 	}
@@ -89,13 +80,7 @@ func CreatePatient(c *gin.Context) {
 }
 
 // UpdatePatient updates an existing patient
-func UpdatePatient(c *gin.Context) {
-	// Get database connection from the shared database package
-	db := database.GetDB()
-
-	// Create patient service
-	patientService := services.NewPatientService(db)
-
+func (s *Server) UpdatePatient(c *gin.Context) {
 	id := c.Param("id")
 	patientID, err := strconv.Atoi(id)
 	if err != nil {
@@ -110,8 +95,12 @@ func UpdatePatient(c *gin.Context) {
 	}
 
 	// Update patient through service
-	updatedPatient, err := patientService.UpdatePatient(patientID, req)
+	updatedPatient, err := s.patients.UpdatePatient(c.Request.Context(), patientID, req)
 	if err != nil {
+		if _, ok := err.(*services.ValidationError); ok {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
 		if err != nil && err.Error() == "sql: no rows in result set" {
 			c.JSON(http.StatusNotFound, gin.H{"error": "Patient not found"})
 			return
@@ -124,13 +113,7 @@ func UpdatePatient(c *gin.Context) {
 }
 
 // DeletePatient deletes a patient by ID
-func DeletePatient(c *gin.Context) {
-	// Get database connection from the shared database package
-	db := database.GetDB()
-
-	// Create patient service
-	patientService := services.NewPatientService(db)
-
+func (s *Server) DeletePatient(c *gin.Context) {
 	id := c.Param("id")
 	patientID, err := strconv.Atoi(id)
 	if err != nil {
@@ -139,7 +122,7 @@ func DeletePatient(c *gin.Context) {
 	}
 
 	// Delete patient through service
-	err = patientService.DeletePatient(patientID)
+	err = s.patients.DeletePatient(c.Request.Context(), patientID)
 	if err != nil {
 		if err != nil && err.Error() == "sql: no rows in result set" {
 			c.JSON(http.StatusNotFound, gin.H{"error": "Patient not found"})
@@ -153,15 +136,9 @@ func DeletePatient(c *gin.Context) {
 }
 
 // GetPatientStats retrieves patient statistics
-func GetPatientStats(c *gin.Context) {
-	// Get database connection from the shared database package
-	db := database.GetDB()
-
-	// Create patient service
-	patientService := services.NewPatientService(db)
-
+func (s *Server) GetPatientStats(c *gin.Context) {
 	// Get patient stats from service
-	stats, err := patientService.GetPatientStats()
+	stats, err := s.patients.GetPatientStats(c.Request.Context())
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve patient statistics"})
 		return
@@ -0,0 +1,39 @@
+// dental_backend/internal/handlers/permissions.go
+package handlers
+
+import (
+	"net/http"
+
+	"dental_backend/internal/models"
+	"dental_backend/internal/rbac"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RequirePermission rejects users whose role lacks a permission in the matrix.
+// It must run after AuthMiddleware.
+func RequirePermission(resource rbac.Resource, action rbac.Action) gin.HandlerFunc {
+	permission := rbac.Permission{Resource: resource, Action: action}
+
+	return func(c *gin.Context) {
+		role := models.UserRole(c.GetString("userRole"))
+		if !rbac.Allowed(role, permission) {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{
+				"error":             "Insufficient permissions: " + permission.String() + " is required",
+				"missingPermission": permission.String(),
+				"role":              role,
+			})
+			return
+		}
+		noteAuthorized(c, permission)
+
+		c.Next()
+
+		noteServed(c)
+	}
+}
+
+// GetPermissions handles GET /api/auth/permissions, returning the permission matrix
+func (s *Server) GetPermissions(c *gin.Context) {
+	c.JSON(http.StatusOK, rbac.Matrix)
+}
@@ -0,0 +1,88 @@
+// dental_backend/internal/handlers/rate_limit.go
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// rateLimiter allows each client IP a fixed number of requests per window.
+// A nil limiter allows everything.
+type rateLimiter struct {
+	limit  int
+	window time.Duration
+
+	mu      sync.Mutex
+	clients map[string]*rateWindow
+	pruned  time.Time
+}
+
+// rateWindow counts one client's requests in the current window
+type rateWindow struct {
+	start time.Time
+	count int
+}
+
+// newRateLimiter creates a limiter allowing limit requests per window,
+// or nil when limit is zero or negative
+func newRateLimiter(limit int, window time.Duration) *rateLimiter {
+	if limit <= 0 {
+		return nil
+	}
+	return &rateLimiter{
+		limit:   limit,
+		window:  window,
+		clients: make(map[string]*rateWindow),
+	}
+}
+
+// allow records a request from key and returns how long to wait when it is over the limit
+func (l *rateLimiter) allow(key string, now time.Time) (bool, time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	// Drop expired windows now and then so idle clients do not pile up
+	if now.Sub(l.pruned) >= l.window {
+		for k, w := range l.clients {
+			if now.Sub(w.start) >= l.window {
+				delete(l.clients, k)
+			}
+		}
+		l.pruned = now
+	}
+
+	w, ok := l.clients[key]
+	if !ok || now.Sub(w.start) >= l.window {
+		l.clients[key] = &rateWindow{start: now, count: 1}
+		return true, 0
+	}
+	if w.count >= l.limit {
+		return false, w.start.Add(l.window).Sub(now)
+	}
+	w.count++
+	return true, 0
+}
+
+// middleware rejects clients over the limit with 429 Too Many Requests
+func (l *rateLimiter) middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if l == nil {
+			c.Next()
+			return
+		}
+
+		ok, retryAfter := l.allow(c.ClientIP(), time.Now())
+		if !ok {
+			seconds := int((retryAfter + time.Second - 1) / time.Second)
+			c.Header("Retry-After", strconv.Itoa(seconds))
+			c.AbortWithStatusJSON(http.StatusTooManyRequests, gin.H{"error": "Too many requests, please try again later"})
+			return
+		}
+
+		c.Next()
+	}
+}
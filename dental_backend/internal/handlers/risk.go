@@ -0,0 +1,83 @@
+// dental_backend/internal/handlers/risk.go
+package handlers
+
+import (
+	"log"
+	"net/http"
+	"strconv"
+
+	"dental_backend/internal/models"
+	"dental_backend/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetPatientRisk handles GET /api/patients/:id/risk
+func (s *Server) GetPatientRisk(c *gin.Context) {
+	patientID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid patient ID"})
+		return
+	}
+
+	// Get the derived risk and the factors behind it from service
+	assessment, err := s.risk.GetAssessment(c.Request.Context(), patientID)
+	if err != nil {
+		log.Printf("Error retrieving risk of patient %d: %v", patientID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve patient risk"})
+		return
+	}
+	if assessment == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Patient not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, assessment)
+}
+
+// GetRiskRules handles GET /api/risk/rules
+func (s *Server) GetRiskRules(c *gin.Context) {
+	rules, err := s.risk.GetRules(c.Request.Context())
+	if err != nil {
+		log.Printf("Error retrieving risk rules: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve risk rules"})
+		return
+	}
+
+	c.JSON(http.StatusOK, rules)
+}
+
+// UpdateRiskRules handles PUT /api/risk/rules
+func (s *Server) UpdateRiskRules(c *gin.Context) {
+	var req models.RiskRules
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	rules, err := s.risk.UpdateRules(c.Request.Context(), req)
+	if err != nil {
+		if _, ok := err.(*services.ValidationError); ok {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		log.Printf("Error updating risk rules: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update risk rules"})
+		return
+	}
+
+	c.JSON(http.StatusOK, rules)
+}
+
+// RecomputeRisk handles POST /api/risk/recompute, reassessing every patient of
+// the current clinic under the current rules
+func (s *Server) RecomputeRisk(c *gin.Context) {
+	result, err := s.risk.RecomputeAll(c.Request.Context())
+	if err != nil {
+		log.Printf("Error recomputing patient risk: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to recompute patient risk"})
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
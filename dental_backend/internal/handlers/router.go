@@ -0,0 +1,271 @@
+// dental_backend/internal/handlers/router.go
+package handlers
+
+import (
+	"net/http"
+
+	"dental_backend/internal/rbac"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Router builds the HTTP router with all API routes
+func (s *Server) Router() *gin.Engine {
+	router := gin.New()
+	router.Use(gin.Logger())
+	router.Use(gin.Recovery())
+	router.Use(corsMiddleware())
+
+	s.setupRoutes(router)
+
+	return router
+}
+
+func (s *Server) setupRoutes(router *gin.Engine) {
+	// Health check endpoint
+	router.GET("/health", func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{
+			"status":  "ok",
+			"message": "Dental backend is running",
+		})
+	})
+
+	// API routes
+	api := router.Group("/api")
+	api.Use(s.auditTrail())
+	{
+		// Authentication endpoints
+		api.POST("/auth/register", s.Register)
+		api.POST("/auth/login", s.Login)
+		api.POST("/auth/google-login", s.GoogleLogin)
+		api.POST("/auth/google-register", s.GoogleRegister)
+		api.GET("/auth/user", s.AuthMiddleware(), s.GetCurrentUser)
+		api.PUT("/auth/user", s.AuthMiddleware(), s.UpdateCurrentUser)
+		api.POST("/auth/change-password", s.AuthMiddleware(), s.ChangePassword)
+		api.POST("/auth/refresh", s.RefreshToken)
+		api.POST("/auth/forgot-password", s.publicLimiter.middleware(), s.ForgotPassword)
+		api.POST("/auth/reset-password", s.publicLimiter.middleware(), s.ResetPassword)
+		api.POST("/auth/verify-email", s.publicLimiter.middleware(), s.VerifyEmail)
+		api.POST("/auth/resend-verification", s.publicLimiter.middleware(), s.ResendVerification)
+		api.POST("/auth/mfa/enroll", s.publicLimiter.middleware(), s.EnrollMFAChallenge)
+		api.POST("/auth/mfa/verify", s.publicLimiter.middleware(), s.VerifyMFAChallenge)
+		api.GET("/auth/mfa", s.AuthMiddleware(), s.GetMFAStatus)
+		api.POST("/auth/mfa/setup", s.AuthMiddleware(), s.SetupMFA)
+		api.POST("/auth/mfa/enable", s.AuthMiddleware(), s.EnableMFA)
+		api.POST("/auth/mfa/disable", s.AuthMiddleware(), s.DisableMFA)
+		api.POST("/auth/mfa/backup-codes", s.AuthMiddleware(), s.RegenerateBackupCodes)
+		api.GET("/auth/oidc/providers", s.GetSignInProviders)
+		api.GET("/auth/oidc/:provider/login", s.publicLimiter.middleware(), s.OIDCLogin)
+		api.GET("/auth/oidc/:provider/callback", s.publicLimiter.middleware(), s.OIDCCallback)
+		api.POST("/auth/oidc/exchange", s.publicLimiter.middleware(), s.ExchangeSignInCode)
+		api.POST("/auth/oidc/:provider/link", s.AuthMiddleware(), s.LinkOIDCAccount)
+		api.GET("/auth/identities", s.AuthMiddleware(), s.GetMyIdentities)
+		api.POST("/auth/identities/google", s.AuthMiddleware(), s.LinkGoogleAccount)
+		api.POST("/auth/logout", s.AuthMiddleware(), s.Logout)
+		api.POST("/auth/logout-all", s.AuthMiddleware(), s.LogoutAll)
+		api.GET("/auth/sessions", s.AuthMiddleware(), s.GetMySessions)
+		api.GET("/auth/clinics", s.AuthMiddleware(), s.GetMyClinics)
+		api.POST("/auth/clinic", s.AuthMiddleware(), s.SwitchClinic)
+		api.GET("/auth/permissions", s.AuthMiddleware(), RequirePermission(rbac.ResourcePermissions, rbac.ActionRead), s.GetPermissions)
+		api.GET("/auth/policy", s.AuthMiddleware(), RequirePermission(rbac.ResourceAuthPolicy, rbac.ActionRead), s.GetAuthPolicy)
+		api.PUT("/auth/policy", s.AuthMiddleware(), RequirePermission(rbac.ResourceAuthPolicy, rbac.ActionUpdate), s.UpdateAuthPolicy)
+
+		// User administration
+		userRoutes := api.Group("/users")
+		userRoutes.Use(s.AuthMiddleware())
+		{
+			userRoutes.GET("", RequirePermission(rbac.ResourceUsers, rbac.ActionRead), s.GetUsers)
+			userRoutes.POST("", RequirePermission(rbac.ResourceUsers, rbac.ActionCreate), s.CreateUser)
+			userRoutes.GET("/:id", RequirePermission(rbac.ResourceUsers, rbac.ActionRead), s.GetUser)
+			userRoutes.PUT("/:id", RequirePermission(rbac.ResourceUsers, rbac.ActionUpdate), s.UpdateUser)
+			userRoutes.DELETE("/:id", RequirePermission(rbac.ResourceUsers, rbac.ActionDelete), s.DeleteUser)
+			userRoutes.POST("/:id/deactivate", RequirePermission(rbac.ResourceUsers, rbac.ActionUpdate), s.DeactivateUser)
+			userRoutes.POST("/:id/activate", RequirePermission(rbac.ResourceUsers, rbac.ActionUpdate), s.ActivateUser)
+			userRoutes.GET("/:id/sessions", RequirePermission(rbac.ResourceUsers, rbac.ActionRead), s.GetUserSessions)
+			userRoutes.DELETE("/:id/sessions", RequirePermission(rbac.ResourceUsers, rbac.ActionUpdate), s.RevokeUserSessions)
+			userRoutes.DELETE("/:id/sessions/:sessionId", RequirePermission(rbac.ResourceUsers, rbac.ActionUpdate), s.RevokeUserSession)
+		}
+
+		// Audit trail
+		api.GET("/audit", s.AuthMiddleware(), RequirePermission(rbac.ResourceAuditLog, rbac.ActionRead), s.GetAuditLog)
+		api.GET("/audit/verify", s.AuthMiddleware(), RequirePermission(rbac.ResourceAuditLog, rbac.ActionRead), s.VerifyAuditLog)
+
+		// Clinic administration; the current clinic is the one the token works in
+		clinicRoutes := api.Group("/clinics")
+		clinicRoutes.Use(s.AuthMiddleware())
+		{
+			clinicRoutes.POST("", RequirePermission(rbac.ResourceClinics, rbac.ActionCreate), s.CreateClinic)
+			clinicRoutes.GET("/current", RequirePermission(rbac.ResourceClinics, rbac.ActionRead), s.GetCurrentClinic)
+			clinicRoutes.PUT("/current", RequirePermission(rbac.ResourceClinics, rbac.ActionUpdate), s.UpdateCurrentClinic)
+			clinicRoutes.GET("/current/members", RequirePermission(rbac.ResourceClinics, rbac.ActionRead), s.GetClinicMembers)
+			clinicRoutes.PUT("/current/members/:userId", RequirePermission(rbac.ResourceClinics, rbac.ActionUpdate), s.SetClinicMember)
+			clinicRoutes.DELETE("/current/members/:userId", RequirePermission(rbac.ResourceClinics, rbac.ActionUpdate), s.RemoveClinicMember)
+		}
+
+		// Dashboard endpoints
+		api.GET("/dashboard/stats", s.AuthMiddleware(), RequirePermission(rbac.ResourceDashboard, rbac.ActionRead), s.GetDashboardStats)
+
+		// Patient endpoints
+		api.GET("/patients", s.AuthMiddleware(), RequirePermission(rbac.ResourcePatients, rbac.ActionRead), s.GetPatients)
+		api.GET("/patients/stats", s.AuthMiddleware(), RequirePermission(rbac.ResourcePatients, rbac.ActionRead), s.GetPatientStats)
+		api.POST("/patients", s.AuthMiddleware(), RequirePermission(rbac.ResourcePatients, rbac.ActionCreate), s.CreatePatient)
+		api.GET("/patients/:id", s.AuthMiddleware(), RequirePermission(rbac.ResourcePatients, rbac.ActionRead), s.GetPatient)
+		api.PUT("/patients/:id", s.AuthMiddleware(), RequirePermission(rbac.ResourcePatients, rbac.ActionUpdate), s.UpdatePatient)
+		api.DELETE("/patients/:id", s.AuthMiddleware(), RequirePermission(rbac.ResourcePatients, rbac.ActionDelete), s.DeletePatient)
+		api.GET("/patients/:id/risk", s.AuthMiddleware(), RequirePermission(rbac.ResourcePatients, rbac.ActionRead), s.GetPatientRisk)
+
+		// Risk scoring rules; recomputing applies them to the current clinic's patients
+		api.GET("/risk/rules", s.AuthMiddleware(), RequirePermission(rbac.ResourceRiskRules, rbac.ActionRead), s.GetRiskRules)
+		api.PUT("/risk/rules", s.AuthMiddleware(), RequirePermission(rbac.ResourceRiskRules, rbac.ActionUpdate), s.UpdateRiskRules)
+		api.POST("/risk/recompute", s.AuthMiddleware(), RequirePermission(rbac.ResourceRiskRules, rbac.ActionUpdate), s.RecomputeRisk)
+
+		// Medical history endpoints: allergies, medications and conditions
+		api.GET("/patients/:id/medical-history", s.AuthMiddleware(), RequirePermission(rbac.ResourceMedicalHistory, rbac.ActionRead), s.GetMedicalHistory)
+		for collection := range medicalEntryCollections {
+			api.GET("/patients/:id/"+collection, s.AuthMiddleware(), RequirePermission(rbac.ResourceMedicalHistory, rbac.ActionRead), s.GetMedicalEntries)
+			api.POST("/patients/:id/"+collection, s.AuthMiddleware(), RequirePermission(rbac.ResourceMedicalHistory, rbac.ActionCreate), s.CreateMedicalEntry)
+			api.PUT("/patients/:id/"+collection+"/:entryId", s.AuthMiddleware(), RequirePermission(rbac.ResourceMedicalHistory, rbac.ActionUpdate), s.UpdateMedicalEntry)
+			api.DELETE("/patients/:id/"+collection+"/:entryId", s.AuthMiddleware(), RequirePermission(rbac.ResourceMedicalHistory, rbac.ActionDelete), s.DeleteMedicalEntry)
+		}
+
+		// Appointment endpoints (accessible by dentists and staff)
+		appointmentRoutes := api.Group("/appointments")
+		appointmentRoutes.Use(s.AuthMiddleware())
+		{
+			appointmentRoutes.GET("/today", RequirePermission(rbac.ResourceAppointments, rbac.ActionRead), s.GetTodaysAppointments)
+			appointmentRoutes.GET("", RequirePermission(rbac.ResourceAppointments, rbac.ActionRead), s.GetAppointments)
+			appointmentRoutes.GET("/:id", RequirePermission(rbac.ResourceAppointments, rbac.ActionRead), s.GetAppointment)
+			appointmentRoutes.POST("", RequirePermission(rbac.ResourceAppointments, rbac.ActionCreate), s.CreateAppointment)
+			appointmentRoutes.PUT("/:id", RequirePermission(rbac.ResourceAppointments, rbac.ActionUpdate), s.UpdateAppointment)
+			appointmentRoutes.DELETE("/:id", RequirePermission(rbac.ResourceAppointments, rbac.ActionDelete), s.DeleteAppointment)
+			appointmentRoutes.POST("/:id/cancel", RequirePermission(rbac.ResourceAppointments, rbac.ActionUpdate), s.CancelAppointment)
+			appointmentRoutes.GET("/:id/history", RequirePermission(rbac.ResourceAppointments, rbac.ActionRead), s.GetAppointmentHistory)
+			appointmentRoutes.GET("/:id/reminders", RequirePermission(rbac.ResourceAppointments, rbac.ActionRead), s.GetAppointmentReminders)
+			appointmentRoutes.POST("/:id/link", RequirePermission(rbac.ResourceAppointments, rbac.ActionUpdate), s.CreateAppointmentLink)
+
+			// Recurring series
+			appointmentRoutes.POST("/series", RequirePermission(rbac.ResourceAppointments, rbac.ActionCreate), s.CreateAppointmentSeries)
+			appointmentRoutes.GET("/series/:id", RequirePermission(rbac.ResourceAppointments, rbac.ActionRead), s.GetAppointmentSeries)
+		}
+
+		// Public endpoints (no login), throttled per client IP
+		public := api.Group("/public")
+		public.Use(s.publicLimiter.middleware())
+
+		// Online booking, for the clinic named by ?clinicId= or the default clinic
+		public.GET("/booking/appointment-types", s.bookingClinic(), s.GetBookableAppointmentTypes)
+		public.GET("/booking/dentists", s.bookingClinic(), s.GetBookableDentists)
+		public.GET("/booking/slots", s.bookingClinic(), s.GetBookingSlots)
+		public.POST("/booking", s.bookingLimiter.middleware(), s.bookingClinic(), s.CreateOnlineBooking)
+
+		// Patient self-service links (authorized by the signed token, which names
+		// the appointment, so they are open to every clinic)
+		publicAppointments := public.Group("/appointments/:token")
+		publicAppointments.Use(s.allClinics())
+		{
+			publicAppointments.GET("/confirm", s.PreviewAppointmentConfirmation)
+			publicAppointments.POST("/confirm", s.ConfirmAppointmentByLink)
+			publicAppointments.GET("/cancel", s.PreviewAppointmentCancellation)
+			publicAppointments.POST("/cancel", s.CancelAppointmentByLink)
+			publicAppointments.GET("/reschedule-options", s.GetRescheduleOptions)
+			publicAppointments.POST("/reschedule-options", s.RescheduleAppointmentByLink)
+		}
+
+		// Calendar feed subscriptions
+		calendarRoutes := api.Group("/calendar")
+		{
+			calendarRoutes.GET("/feeds", s.AuthMiddleware(), RequirePermission(rbac.ResourceCalendarFeeds, rbac.ActionRead), s.GetCalendarFeeds)
+			calendarRoutes.POST("/feeds", s.AuthMiddleware(), RequirePermission(rbac.ResourceCalendarFeeds, rbac.ActionCreate), s.CreateCalendarFeed)
+			calendarRoutes.DELETE("/feeds/:id", s.AuthMiddleware(), RequirePermission(rbac.ResourceCalendarFeeds, rbac.ActionDelete), s.RevokeCalendarFeed)
+
+			// Calendar clients cannot send Bearer headers, so the feeds are authorized by their token
+			calendarRoutes.GET("/dentists/:file", s.publicLimiter.middleware(), s.allClinics(), s.GetDentistCalendar)
+			calendarRoutes.GET("/patients/:file", s.publicLimiter.middleware(), s.allClinics(), s.GetPatientCalendar)
+		}
+
+		// Waitlist endpoints
+		waitlistRoutes := api.Group("/waitlist")
+		waitlistRoutes.Use(s.AuthMiddleware())
+		{
+			waitlistRoutes.GET("", RequirePermission(rbac.ResourceWaitlist, rbac.ActionRead), s.GetWaitlist)
+			waitlistRoutes.POST("", RequirePermission(rbac.ResourceWaitlist, rbac.ActionCreate), s.CreateWaitlistEntry)
+			waitlistRoutes.GET("/:id", RequirePermission(rbac.ResourceWaitlist, rbac.ActionRead), s.GetWaitlistEntry)
+			waitlistRoutes.PUT("/:id", RequirePermission(rbac.ResourceWaitlist, rbac.ActionUpdate), s.UpdateWaitlistEntry)
+			waitlistRoutes.DELETE("/:id", RequirePermission(rbac.ResourceWaitlist, rbac.ActionDelete), s.DeleteWaitlistEntry)
+
+			// Slots freed by cancellations
+			waitlistRoutes.GET("/openings", RequirePermission(rbac.ResourceWaitlist, rbac.ActionRead), s.GetSlotOpenings)
+			waitlistRoutes.GET("/openings/:id/candidates", RequirePermission(rbac.ResourceWaitlist, rbac.ActionRead), s.GetOpeningCandidates)
+			waitlistRoutes.POST("/openings/:id/book", RequirePermission(rbac.ResourceWaitlist, rbac.ActionUpdate), s.BookSlotOpening)
+		}
+
+		// Schedule and availability endpoints
+		api.GET("/availability", s.AuthMiddleware(), RequirePermission(rbac.ResourceSchedules, rbac.ActionRead), s.GetAvailability)
+		api.GET("/schedules/:dentistId", s.AuthMiddleware(), RequirePermission(rbac.ResourceSchedules, rbac.ActionRead), s.GetSchedule)
+		api.PUT("/schedules/:dentistId", s.AuthMiddleware(), RequirePermission(rbac.ResourceSchedules, rbac.ActionUpdate), s.UpdateSchedule)
+		api.GET("/closures", s.AuthMiddleware(), RequirePermission(rbac.ResourceClosures, rbac.ActionRead), s.GetClosures)
+		api.POST("/closures", s.AuthMiddleware(), RequirePermission(rbac.ResourceClosures, rbac.ActionCreate), s.CreateClosure)
+		api.DELETE("/closures/:id", s.AuthMiddleware(), RequirePermission(rbac.ResourceClosures, rbac.ActionDelete), s.DeleteClosure)
+
+		// Treatment endpoints
+		api.GET("/treatments/queue", s.AuthMiddleware(), RequirePermission(rbac.ResourcePatientTreatments, rbac.ActionRead), s.GetTreatmentQueue)
+		api.GET("/treatments", s.AuthMiddleware(), RequirePermission(rbac.ResourceTreatments, rbac.ActionRead), s.GetTreatments)
+		api.GET("/treatments/:id", s.AuthMiddleware(), RequirePermission(rbac.ResourceTreatments, rbac.ActionRead), s.GetTreatment)
+		api.POST("/treatments", s.AuthMiddleware(), RequirePermission(rbac.ResourceTreatments, rbac.ActionCreate), s.CreateTreatment)
+		api.PUT("/treatments/:id", s.AuthMiddleware(), RequirePermission(rbac.ResourceTreatments, rbac.ActionUpdate), s.UpdateTreatment)
+		api.DELETE("/treatments/:id", s.AuthMiddleware(), RequirePermission(rbac.ResourceTreatments, rbac.ActionDelete), s.DeleteTreatment)
+
+		// Patient treatment endpoints
+		api.GET("/patients/:id/treatments", s.AuthMiddleware(), RequirePermission(rbac.ResourcePatientTreatments, rbac.ActionRead), s.GetPatientTreatments)
+		api.POST("/patient-treatments", s.AuthMiddleware(), RequirePermission(rbac.ResourcePatientTreatments, rbac.ActionCreate), s.CreatePatientTreatment)
+		api.PUT("/patient-treatments/:id", s.AuthMiddleware(), RequirePermission(rbac.ResourcePatientTreatments, rbac.ActionUpdate), s.UpdatePatientTreatment)
+		api.DELETE("/patient-treatments/:id", s.AuthMiddleware(), RequirePermission(rbac.ResourcePatientTreatments, rbac.ActionDelete), s.DeletePatientTreatment)
+
+		// Tooth analysis endpoint
+		api.POST("/tooth-analysis", s.AuthMiddleware(), RequirePermission(rbac.ResourceToothAnalysis, rbac.ActionCreate), s.AnalyzeTooth)
+
+		// Billing endpoints
+		api.GET("/billing/stats", s.AuthMiddleware(), RequirePermission(rbac.ResourceInvoices, rbac.ActionRead), s.GetBillingStats)
+		api.GET("/billing/invoices", s.AuthMiddleware(), RequirePermission(rbac.ResourceInvoices, rbac.ActionRead), s.GetInvoices)
+		api.GET("/billing/invoices/:id", s.AuthMiddleware(), RequirePermission(rbac.ResourceInvoices, rbac.ActionRead), s.GetInvoice)
+		api.POST("/billing/invoices", s.AuthMiddleware(), RequirePermission(rbac.ResourceInvoices, rbac.ActionCreate), s.CreateInvoice)
+		api.PUT("/billing/invoices/:id", s.AuthMiddleware(), RequirePermission(rbac.ResourceInvoices, rbac.ActionUpdate), s.UpdateInvoice)
+		api.DELETE("/billing/invoices/:id", s.AuthMiddleware(), RequirePermission(rbac.ResourceInvoices, rbac.ActionDelete), s.DeleteInvoice)
+
+		// Insurance claims endpoints
+		api.GET("/billing/claims", s.AuthMiddleware(), RequirePermission(rbac.ResourceClaims, rbac.ActionRead), s.GetInsuranceClaims)
+		api.GET("/billing/claims/:id", s.AuthMiddleware(), RequirePermission(rbac.ResourceClaims, rbac.ActionRead), s.GetInsuranceClaim)
+		api.POST("/billing/claims", s.AuthMiddleware(), RequirePermission(rbac.ResourceClaims, rbac.ActionCreate), s.CreateInsuranceClaim)
+		api.PUT("/billing/claims/:id", s.AuthMiddleware(), RequirePermission(rbac.ResourceClaims, rbac.ActionUpdate), s.UpdateInsuranceClaim)
+		api.DELETE("/billing/claims/:id", s.AuthMiddleware(), RequirePermission(rbac.ResourceClaims, rbac.ActionDelete), s.DeleteInsuranceClaim)
+
+		// Activity endpoints
+		api.GET("/activity/recent", s.AuthMiddleware(), RequirePermission(rbac.ResourceDashboard, rbac.ActionRead), s.GetRecentActivity)
+	}
+}
+
+// Middleware to handle CORS
+func corsMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		origin := c.Request.Header.Get("Origin")
+		if origin == "" {
+			origin = "*"
+		}
+
+		c.Writer.Header().Set("Access-Control-Allow-Origin", origin)
+		c.Writer.Header().Set("Access-Control-Allow-Credentials", "true")
+		c.Writer.Header().Set("Access-Control-Allow-Headers", "Content-Type, Content-Length, Accept-Encoding, X-CSRF-Token, Authorization, accept, origin, Cache-Control, X-Requested-With")
+		c.Writer.Header().Set("Access-Control-Allow-Methods", "POST, OPTIONS, GET, PUT, DELETE")
+
+		// Completely remove Cross-Origin-Opener-Policy for all requests to fix Google OAuth popup issues
+		// This is required for Google OAuth to work properly
+		c.Writer.Header().Del("Cross-Origin-Opener-Policy")
+
+		if c.Request.Method == "OPTIONS" {
+			c.AbortWithStatus(204)
+			return
+		}
+
+		c.Next()
+	}
+}
@@ -0,0 +1,202 @@
+// dental_backend/internal/handlers/schedules.go
+package handlers
+
+import (
+	"database/sql"
+	"log"
+	"net/http"
+	"strconv"
+
+	"dental_backend/internal/models"
+	"dental_backend/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// canManageSchedule reports whether the logged-in user may change the given
+// dentist's schedule; a nil dentistID means the whole clinic, which only admins manage
+func canManageSchedule(c *gin.Context, dentistID *int) bool {
+	if c.GetString("userRole") == string(models.UserRoleAdmin) {
+		return true
+	}
+	return dentistID != nil && c.GetInt("userID") == *dentistID
+}
+
+// GetAvailability handles GET /api/availability?dentistId=&date=&duration=
+func (s *Server) GetAvailability(c *gin.Context) {
+	dentistID, err := strconv.Atoi(c.Query("dentistId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid dentist ID"})
+		return
+	}
+
+	date := c.Query("date")
+	if date == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "date is required"})
+		return
+	}
+
+	duration := 0
+	if durationStr := c.Query("duration"); durationStr != "" {
+		duration, err = strconv.Atoi(durationStr)
+		if err != nil || duration <= 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid duration"})
+			return
+		}
+	}
+
+	availability, err := s.schedules.GetAvailability(c.Request.Context(), dentistID, date, duration)
+	if err != nil {
+		if _, ok := err.(*services.ValidationError); ok {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		log.Printf("Error computing availability: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve availability"})
+		return
+	}
+
+	c.JSON(http.StatusOK, availability)
+}
+
+// GetSchedule handles GET /api/schedules/:dentistId
+func (s *Server) GetSchedule(c *gin.Context) {
+	dentistID, err := strconv.Atoi(c.Param("dentistId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid dentist ID"})
+		return
+	}
+
+	schedule, err := s.schedules.GetSchedule(c.Request.Context(), dentistID)
+	if err != nil {
+		if _, ok := err.(*services.ValidationError); ok {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve schedule"})
+		return
+	}
+
+	c.JSON(http.StatusOK, schedule)
+}
+
+// UpdateSchedule handles PUT /api/schedules/:dentistId
+func (s *Server) UpdateSchedule(c *gin.Context) {
+	dentistID, err := strconv.Atoi(c.Param("dentistId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid dentist ID"})
+		return
+	}
+
+	if !canManageSchedule(c, &dentistID) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only the dentist or an admin can change this schedule"})
+		return
+	}
+
+	var req models.UpdateScheduleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	schedule, err := s.schedules.UpdateSchedule(c.Request.Context(), dentistID, req)
+	if err != nil {
+		if _, ok := err.(*services.ValidationError); ok {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		log.Printf("Error updating schedule: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update schedule"})
+		return
+	}
+
+	c.JSON(http.StatusOK, schedule)
+}
+
+// GetClosures handles GET /api/closures?dentistId=&from=&to=
+func (s *Server) GetClosures(c *gin.Context) {
+	var dentistID *int
+	if dentistIDStr := c.Query("dentistId"); dentistIDStr != "" {
+		id, err := strconv.Atoi(dentistIDStr)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid dentist ID"})
+			return
+		}
+		dentistID = &id
+	}
+
+	closures, err := s.schedules.GetClosures(c.Request.Context(), dentistID, c.Query("from"), c.Query("to"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve closures"})
+		return
+	}
+
+	// Ensure we always return an array, even if empty
+	if closures == nil {
+		closures = []models.Closure{}
+	}
+
+	c.JSON(http.StatusOK, closures)
+}
+
+// CreateClosure handles POST /api/closures
+func (s *Server) CreateClosure(c *gin.Context) {
+	var req models.CreateClosureRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if !canManageSchedule(c, req.DentistID) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only admins can close the clinic or another dentist's calendar"})
+		return
+	}
+
+	closure, err := s.schedules.CreateClosure(c.Request.Context(), req)
+	if err != nil {
+		if _, ok := err.(*services.ValidationError); ok {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		log.Printf("Error creating closure: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create closure"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, closure)
+}
+
+// DeleteClosure handles DELETE /api/closures/:id
+func (s *Server) DeleteClosure(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid closure ID"})
+		return
+	}
+
+	closure, err := s.schedules.GetClosureByID(c.Request.Context(), id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve closure"})
+		return
+	}
+	if closure == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Closure not found"})
+		return
+	}
+
+	if !canManageSchedule(c, closure.DentistID) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only admins can remove this closure"})
+		return
+	}
+
+	if err := s.schedules.DeleteClosure(c.Request.Context(), id); err != nil {
+		if err == sql.ErrNoRows {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Closure not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete closure"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Closure deleted successfully"})
+}
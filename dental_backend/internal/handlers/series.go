@@ -0,0 +1,139 @@
+// dental_backend/internal/handlers/series.go
+package handlers
+
+import (
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+
+	"dental_backend/internal/models"
+	"dental_backend/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// CreateAppointmentSeries handles POST /api/appointments/series
+func (s *Server) CreateAppointmentSeries(c *gin.Context) {
+	// Get logged-in user from context
+	caller, exists := currentCaller(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	var req models.CreateSeriesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	result, err := s.series.CreateSeries(c.Request.Context(), req, caller)
+	if err != nil {
+		if _, ok := err.(*services.ValidationError); ok {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		if _, ok := err.(*services.ForbiddenError); ok {
+			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+			return
+		}
+		log.Printf("Error creating appointment series: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create appointment series"})
+		return
+	}
+
+	// None of the occurrences could be booked
+	if len(result.Appointments) == 0 {
+		c.JSON(http.StatusConflict, gin.H{"error": "No occurrence of the series could be booked", "conflicts": result.Conflicts})
+		return
+	}
+
+	c.JSON(http.StatusCreated, result)
+}
+
+// GetAppointmentSeries handles GET /api/appointments/series/:id
+func (s *Server) GetAppointmentSeries(c *gin.Context) {
+	// Get logged-in user from context
+	caller, exists := currentCaller(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	seriesID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid series ID"})
+		return
+	}
+
+	result, err := s.series.GetSeries(c.Request.Context(), seriesID, caller)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve appointment series"})
+		return
+	}
+
+	if result == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Appointment series not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// CancelAppointment handles POST /api/appointments/:id/cancel?scope=this|following|all
+func (s *Server) CancelAppointment(c *gin.Context) {
+	// Get logged-in user from context
+	caller, exists := currentCaller(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	appointmentID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid appointment ID"})
+		return
+	}
+
+	// The reason is optional, so an empty body is accepted
+	var req models.CancelAppointmentRequest
+	if err := c.ShouldBindJSON(&req); err != nil && err != io.EOF {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	result, err := s.series.CancelOccurrences(c.Request.Context(), appointmentID, c.Query("scope"), req.Reason, caller)
+	s.respondSeriesChange(c, result, err, "Failed to cancel appointments")
+}
+
+// respondSeriesChange writes the outcome of an operation on one or more occurrences
+func (s *Server) respondSeriesChange(c *gin.Context, result *models.SeriesResult, err error, failure string) {
+	if err != nil {
+		if _, ok := err.(*services.ValidationError); ok {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		log.Printf("%s: %v", failure, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": failure})
+		return
+	}
+
+	if result == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Appointment not found"})
+		return
+	}
+
+	// A single occurrence that failed is reported like a plain appointment update
+	if len(result.Appointments) == 0 && len(result.Conflicts) == 1 {
+		conflict := result.Conflicts[0]
+		status := http.StatusBadRequest
+		if conflict.ConflictingAppointment != nil {
+			status = http.StatusConflict
+		}
+		c.JSON(status, gin.H{"error": conflict.Reason, "conflicts": result.Conflicts})
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
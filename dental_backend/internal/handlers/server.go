@@ -0,0 +1,216 @@
+// dental_backend/internal/handlers/server.go
+package handlers
+
+import (
+	"context"
+	"database/sql"
+	"net/http"
+	"time"
+
+	"dental_backend/internal/captcha"
+	"dental_backend/internal/config"
+	"dental_backend/internal/fieldcrypt"
+	"dental_backend/internal/idtoken"
+	"dental_backend/internal/notify"
+	"dental_backend/internal/oidc"
+	"dental_backend/internal/repository"
+	"dental_backend/internal/repository/postgres"
+	"dental_backend/internal/services"
+)
+
+// Repositories groups the storage implementations the server is built on
+type Repositories struct {
+	Patients      repository.PatientRepository
+	Appointments  repository.AppointmentRepository
+	Treatments    repository.TreatmentRepository
+	Medical       repository.MedicalEntryRepository
+	Risk          repository.RiskRepository
+	Billing       repository.BillingRepository
+	Schedules     repository.ScheduleRepository
+	Series        repository.SeriesRepository
+	Waitlist      repository.WaitlistRepository
+	Reminders     repository.ReminderRepository
+	Links         repository.AppointmentLinkRepository
+	Feeds         repository.CalendarFeedRepository
+	Users         repository.UserRepository
+	Sessions      repository.SessionRepository
+	AccountTokens repository.AccountTokenRepository
+	AuthPolicy    repository.AuthPolicyRepository
+	MFA           repository.MFARepository
+	Identities    repository.IdentityRepository
+	Clinics       repository.ClinicRepository
+	Audit         repository.AuditRepository
+}
+
+// PostgresRepositories returns PostgreSQL-backed repositories sharing one pool
+func PostgresRepositories(db *sql.DB, cipher *fieldcrypt.Cipher) Repositories {
+	return Repositories{
+		Patients:      postgres.NewPatientRepository(db, cipher),
+		Appointments:  postgres.NewAppointmentRepository(db),
+		Treatments:    postgres.NewTreatmentRepository(db),
+		Medical:       postgres.NewMedicalEntryRepository(db, cipher),
+		Risk:          postgres.NewRiskRepository(db),
+		Billing:       postgres.NewBillingRepository(db),
+		Schedules:     postgres.NewScheduleRepository(db),
+		Series:        postgres.NewSeriesRepository(db),
+		Waitlist:      postgres.NewWaitlistRepository(db),
+		Reminders:     postgres.NewReminderRepository(db),
+		Links:         postgres.NewAppointmentLinkRepository(db),
+		Feeds:         postgres.NewCalendarFeedRepository(db),
+		Users:         postgres.NewUserRepository(db),
+		Sessions:      postgres.NewSessionRepository(db),
+		AccountTokens: postgres.NewAccountTokenRepository(db),
+		AuthPolicy:    postgres.NewAuthPolicyRepository(db),
+		MFA:           postgres.NewMFARepository(db),
+		Identities:    postgres.NewIdentityRepository(db),
+		Clinics:       postgres.NewClinicRepository(db),
+		Audit:         postgres.NewAuditRepository(db),
+	}
+}
+
+// Server owns the configuration, database pool, services and HTTP clients
+// used by the API handlers. Each Server is independent, so several can run
+// in one process.
+type Server struct {
+	cfg *config.Config
+
+	// db is used directly by the authentication handlers for user records, and
+	// to pin each request's connection to its clinic; nil outside PostgreSQL
+	db *sql.DB
+
+	patients     *services.PatientService
+	appointments *services.AppointmentService
+	treatments   *services.TreatmentService
+	medical      *services.MedicalHistoryService
+	risk         *services.RiskService
+	billing      *services.BillingService
+	schedules    *services.ScheduleService
+	series       *services.SeriesService
+	waitlist     *services.WaitlistService
+	reminders    *services.ReminderService
+	links        *services.AppointmentLinkService
+	booking      *services.BookingService
+	calendar     *services.CalendarService
+	sessions     *services.SessionService
+	users        *services.UserService
+	accounts     *services.AccountService
+	mfa          *services.MFAService
+	identities   *services.IdentityService
+	clinics      *services.ClinicService
+	audit        *services.AuditService
+
+	// publicLimiter throttles the public endpoints and bookingLimiter online
+	// booking submissions, per client IP; nil disables a limit
+	publicLimiter  *rateLimiter
+	bookingLimiter *rateLimiter
+
+	// google verifies Google Sign-In ID tokens; nil when no client ID is configured
+	google *idtoken.Verifier
+	// oidcProviders are the configured OpenID Connect providers by name
+	oidcProviders map[string]*oidc.Provider
+
+	// httpClient is used for outbound calls to Google and the ML service
+	httpClient *http.Client
+}
+
+// NewServer creates a server wired to the given repositories
+func NewServer(cfg *config.Config, db *sql.DB, repos Repositories) *Server {
+	appointments := services.NewAppointmentService(repos.Appointments, repos.Treatments, repos.Schedules)
+	schedules := services.NewScheduleService(repos.Schedules, repos.Appointments)
+	linkTokens := services.NewLinkTokens(cfg.LinkSecret)
+
+	reminders := services.NewReminderService(repos.Appointments, repos.Patients, repos.Reminders, nil, cfg.ReminderOffsets)
+	reminders.SetLinks(linkTokens, cfg.PatientLinkURL)
+	links := services.NewAppointmentLinkService(linkTokens, repos.Links, repos.Appointments, appointments, schedules)
+	httpClient := &http.Client{Timeout: 30 * time.Second}
+
+	// Changes to patients and their records rederive their risk
+	patients := services.NewPatientService(repos.Patients)
+	medical := services.NewMedicalHistoryService(repos.Medical, repos.Patients)
+	treatments := services.NewTreatmentService(repos.Treatments, repos.Patients)
+	billing := services.NewBillingService(repos.Billing)
+	risk := services.NewRiskService(repos.Risk, repos.Patients, repos.Medical, repos.Treatments, repos.Appointments, repos.Billing)
+	risk.Watch(patients, medical, treatments, appointments, billing)
+
+	// Switching clinics is held to the two-factor policy for the role there
+	sessions := services.NewSessionService(repos.Sessions, repos.Users, repos.Clinics, cfg.JWTSecret, cfg.AccessTokenTTL, cfg.RefreshTokenTTL)
+	mfa := services.NewMFAService(repos.MFA, repos.Users, repos.AuthPolicy, cfg.JWTSecret, cfg.MFAIssuer)
+	sessions.SetMFA(mfa)
+
+	return &Server{
+		cfg:          cfg,
+		db:           db,
+		patients:     patients,
+		appointments: appointments,
+		treatments:   treatments,
+		medical:      medical,
+		risk:         risk,
+		billing:      billing,
+		schedules:    schedules,
+		series:       services.NewSeriesService(repos.Series, repos.Appointments, appointments),
+		waitlist:     services.NewWaitlistService(repos.Waitlist, repos.Patients, appointments, cfg.WaitlistHold),
+		reminders:    reminders,
+		links:        links,
+		sessions:     sessions,
+		users:        services.NewUserService(repos.Users, repos.Sessions, repos.Clinics),
+		accounts:     services.NewAccountService(repos.Users, repos.AccountTokens, repos.Sessions, repos.AuthPolicy, cfg.AppURL, cfg.PasswordResetTTL, cfg.EmailVerificationTTL),
+		mfa:          mfa,
+		identities:   services.NewIdentityService(repos.Identities, repos.Users, repos.Clinics, repos.AccountTokens),
+		clinics:      services.NewClinicService(repos.Clinics, repos.Users),
+		audit:        services.NewAuditService(repos.Audit),
+		calendar:     services.NewCalendarService(repos.Feeds, repos.Appointments, repos.Patients, schedules, cfg.PublicAPIURL),
+		booking:      services.NewBookingService(repos.Treatments, repos.Patients, schedules, appointments, links, captcha.New(cfg.Captcha, httpClient)),
+
+		publicLimiter:  newRateLimiter(cfg.PublicRateLimit, time.Minute),
+		bookingLimiter: newRateLimiter(cfg.BookingRateLimit, time.Hour),
+
+		google:        newGoogleVerifier(cfg, httpClient),
+		oidcProviders: newOIDCProviders(cfg, httpClient),
+		httpClient:    httpClient,
+	}
+}
+
+// newOIDCProviders creates the configured OpenID Connect providers
+func newOIDCProviders(cfg *config.Config, client *http.Client) map[string]*oidc.Provider {
+	providers := make(map[string]*oidc.Provider, len(cfg.OIDCProviders))
+	for _, providerCfg := range cfg.OIDCProviders {
+		providers[providerCfg.Name] = oidc.NewProvider(providerCfg, client)
+	}
+	return providers
+}
+
+// newGoogleVerifier returns the Google ID token verifier, or nil when Google sign-in is not configured
+func newGoogleVerifier(cfg *config.Config, client *http.Client) *idtoken.Verifier {
+	if cfg.GoogleClientID == "" {
+		return nil
+	}
+	return idtoken.NewVerifier(idtoken.NewKeySet(cfg.GoogleJWKSURL, client), cfg.GoogleClientID, idtoken.GoogleIssuers...)
+}
+
+// SetNotifiers sets the channels used to reach patients and users. Until it is
+// called the server sends no notifications.
+func (s *Server) SetNotifiers(notifiers notify.Notifiers) {
+	s.reminders.SetNotifiers(notifiers)
+	s.accounts.SetMailer(notifiers[notify.ChannelEmail])
+}
+
+// RunReminders sends appointment reminders in the background of the API process
+// until ctx is cancelled, for deployments that do not run cmd/worker. The server
+// must be built on connections opened to every clinic.
+func (s *Server) RunReminders(ctx context.Context) {
+	s.reminders.Run(ctx, s.cfg.ReminderInterval)
+}
+
+// RunRiskRecompute rederives the risk of every patient in the background of the
+// API process until ctx is cancelled, for deployments that do not run cmd/worker.
+// The server must be built on connections opened to every clinic.
+func (s *Server) RunRiskRecompute(ctx context.Context) {
+	s.risk.Run(ctx, s.cfg.RiskRecomputeInterval)
+}
+
+// SetHTTPClient replaces the client used for outbound HTTP calls
+func (s *Server) SetHTTPClient(client *http.Client) {
+	s.httpClient = client
+	s.google = newGoogleVerifier(s.cfg, client)
+	s.oidcProviders = newOIDCProviders(s.cfg, client)
+}
@@ -0,0 +1,168 @@
+// dental_backend/internal/handlers/sessions.go
+package handlers
+
+import (
+	"database/sql"
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+
+	"dental_backend/internal/models"
+	"dental_backend/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RefreshToken handles POST /api/auth/refresh, exchanging a refresh token for a
+// new access token and a replacement refresh token
+func (s *Server) RefreshToken(c *gin.Context) {
+	var req models.RefreshRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	pair, user, err := s.sessions.Refresh(c.Request.Context(), req.RefreshToken, sessionClient(c))
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidSession) {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid or expired refresh token"})
+			return
+		}
+		log.Printf("Error refreshing session: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to refresh session"})
+		return
+	}
+
+	// Attribute the refresh to the user in the audit trail
+	c.Set("userID", user.ID)
+	c.Set("userRole", pair.Clinic.Role)
+	c.Set("clinicID", pair.Clinic.ClinicID)
+
+	c.JSON(http.StatusOK, AuthResponse{
+		Token:        pair.AccessToken,
+		RefreshToken: pair.RefreshToken,
+		ExpiresAt:    pair.ExpiresAt,
+		User:         *user,
+		Clinic:       &pair.Clinic,
+	})
+}
+
+// Logout handles POST /api/auth/logout, ending the session the request was made with
+func (s *Server) Logout(c *gin.Context) {
+	// Get logged-in user from context
+	caller, exists := currentCaller(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	if err := s.sessions.Logout(c.Request.Context(), caller.UserID, c.GetString("sessionID")); err != nil && err != sql.ErrNoRows {
+		log.Printf("Error revoking session: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to log out"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Logged out successfully"})
+}
+
+// LogoutAll handles POST /api/auth/logout-all, ending every session of the current user
+func (s *Server) LogoutAll(c *gin.Context) {
+	// Get logged-in user from context
+	caller, exists := currentCaller(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	count, err := s.sessions.LogoutAll(c.Request.Context(), caller.UserID)
+	if err != nil {
+		log.Printf("Error revoking sessions: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to log out"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Logged out of all sessions", "revoked": count})
+}
+
+// GetMySessions handles GET /api/auth/sessions
+func (s *Server) GetMySessions(c *gin.Context) {
+	// Get logged-in user from context
+	caller, exists := currentCaller(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	s.respondSessions(c, caller.UserID)
+}
+
+// GetUserSessions handles GET /api/users/:id/sessions (admin only)
+func (s *Server) GetUserSessions(c *gin.Context) {
+	userID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return
+	}
+
+	s.respondSessions(c, userID)
+}
+
+// respondSessions writes the active sessions of a user
+func (s *Server) respondSessions(c *gin.Context, userID int) {
+	sessions, err := s.sessions.GetSessions(c.Request.Context(), userID)
+	if err != nil {
+		log.Printf("Error retrieving sessions: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve sessions"})
+		return
+	}
+	if sessions == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, sessions)
+}
+
+// RevokeUserSession handles DELETE /api/users/:id/sessions/:sessionId (admin only)
+func (s *Server) RevokeUserSession(c *gin.Context) {
+	userID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return
+	}
+
+	if err := s.sessions.RevokeUserSession(c.Request.Context(), userID, c.Param("sessionId")); err != nil {
+		if err == sql.ErrNoRows {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Session not found"})
+			return
+		}
+		log.Printf("Error revoking session: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to revoke session"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Session revoked successfully"})
+}
+
+// RevokeUserSessions handles DELETE /api/users/:id/sessions (admin only)
+func (s *Server) RevokeUserSessions(c *gin.Context) {
+	userID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return
+	}
+
+	count, err := s.sessions.RevokeUserSessions(c.Request.Context(), userID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+			return
+		}
+		log.Printf("Error revoking sessions: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to revoke sessions"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Sessions revoked successfully", "revoked": count})
+}
@@ -2,9 +2,8 @@
 package handlers
 
 import (
-	"dental_backend/internal/database"
 	"dental_backend/internal/models"
-	"dental_backend/internal/services"
+	"log"
 	"net/http"
 	"strconv"
 	"os"
@@ -31,19 +30,9 @@ type ToothAnalysisFinding struct {
 }
 
 // GetTreatments handles GET /api/treatments
-func GetTreatments(c *gin.Context) {
-	// Get database connection
-	db := database.GetDB()
-	if db == nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database connection not available"})
-		return
-	}
-
-	// Create treatment service
-	treatmentService := services.NewTreatmentService(db)
-
+func (s *Server) GetTreatments(c *gin.Context) {
 	// Get all treatments
-	treatments, err := treatmentService.GetAllTreatments()
+	treatments, err := s.treatments.GetAllTreatments(c.Request.Context())
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve treatments"})
 		return
@@ -53,7 +42,7 @@ func GetTreatments(c *gin.Context) {
 }
 
 // GetTreatment handles GET /api/treatments/:id
-func GetTreatment(c *gin.Context) {
+func (s *Server) GetTreatment(c *gin.Context) {
 	// Parse treatment ID
 	idStr := c.Param("id")
 	id, err := strconv.Atoi(idStr)
@@ -62,18 +51,8 @@ func GetTreatment(c *gin.Context) {
 		return
 	}
 
-	// Get database connection
-	db := database.GetDB()
-	if db == nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database connection not available"})
-		return
-	}
-
-	// Create treatment service
-	treatmentService := services.NewTreatmentService(db)
-
 	// Get treatment by ID
-	treatment, err := treatmentService.GetTreatmentByID(id)
+	treatment, err := s.treatments.GetTreatmentByID(c.Request.Context(), id)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve treatment"})
 		return
@@ -88,25 +67,15 @@ func GetTreatment(c *gin.Context) {
 }
 
 // CreateTreatment handles POST /api/treatments
-func CreateTreatment(c *gin.Context) {
+func (s *Server) CreateTreatment(c *gin.Context) {
 	var req models.CreateTreatmentRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
 
-	// Get database connection
-	db := database.GetDB()
-	if db == nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database connection not available"})
-		return
-	}
-
-	// Create treatment service
-	treatmentService := services.NewTreatmentService(db)
-
 	// Create treatment
-	treatment, err := treatmentService.CreateTreatment(req)
+	treatment, err := s.treatments.CreateTreatment(c.Request.Context(), req)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create treatment"})
 		return
@@ -116,7 +85,7 @@ func CreateTreatment(c *gin.Context) {
 }
 
 // UpdateTreatment handles PUT /api/treatments/:id
-func UpdateTreatment(c *gin.Context) {
+func (s *Server) UpdateTreatment(c *gin.Context) {
 	// Parse treatment ID
 	idStr := c.Param("id")
 	id, err := strconv.Atoi(idStr)
@@ -131,18 +100,8 @@ func UpdateTreatment(c *gin.Context) {
 		return
 	}
 
-	// Get database connection
-	db := database.GetDB()
-	if db == nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database connection not available"})
-		return
-	}
-
-	// Create treatment service
-	treatmentService := services.NewTreatmentService(db)
-
 	// Update treatment
-	treatment, err := treatmentService.UpdateTreatment(id, req)
+	treatment, err := s.treatments.UpdateTreatment(c.Request.Context(), id, req)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update treatment"})
 		return
@@ -157,7 +116,7 @@ func UpdateTreatment(c *gin.Context) {
 }
 
 // DeleteTreatment handles DELETE /api/treatments/:id
-func DeleteTreatment(c *gin.Context) {
+func (s *Server) DeleteTreatment(c *gin.Context) {
 	// Parse treatment ID
 	idStr := c.Param("id")
 	id, err := strconv.Atoi(idStr)
@@ -166,18 +125,8 @@ func DeleteTreatment(c *gin.Context) {
 		return
 	}
 
-	// Get database connection
-	db := database.GetDB()
-	if db == nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database connection not available"})
-		return
-	}
-
-	// Create treatment service
-	treatmentService := services.NewTreatmentService(db)
-
 	// Delete treatment
-	err = treatmentService.DeleteTreatment(id)
+	err = s.treatments.DeleteTreatment(c.Request.Context(), id)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete treatment"})
 		return
@@ -187,20 +136,17 @@ func DeleteTreatment(c *gin.Context) {
 }
 
 // GetTreatmentQueue handles GET /api/treatments/queue
-func GetTreatmentQueue(c *gin.Context) {
-	// Get database connection
-	db := database.GetDB()
-	if db == nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database connection not available"})
+func (s *Server) GetTreatmentQueue(c *gin.Context) {
+	// Get treatment queue
+	treatments, err := s.treatments.GetTreatmentQueue(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve treatment queue"})
 		return
 	}
 
-	// Create treatment service
-	treatmentService := services.NewTreatmentService(db)
-
-	// Get treatment queue
-	treatments, err := treatmentService.GetTreatmentQueue()
-	if err != nil {
+	// Warn whoever picks up a treatment about the patient's allergies and conditions
+	if err := s.medical.AttachAlerts(c.Request.Context(), treatments); err != nil {
+		log.Printf("Error retrieving medical alerts: %v", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve treatment queue"})
 		return
 	}
@@ -209,7 +155,7 @@ func GetTreatmentQueue(c *gin.Context) {
 }
 
 // GetPatientTreatments handles GET /api/patients/:id/treatments
-func GetPatientTreatments(c *gin.Context) {
+func (s *Server) GetPatientTreatments(c *gin.Context) {
 	// Parse patient ID
 	idStr := c.Param("id")
 	patientID, err := strconv.Atoi(idStr)
@@ -218,18 +164,8 @@ func GetPatientTreatments(c *gin.Context) {
 		return
 	}
 
-	// Get database connection
-	db := database.GetDB()
-	if db == nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database connection not available"})
-		return
-	}
-
-	// Create treatment service
-	treatmentService := services.NewTreatmentService(db)
-
 	// Get patient treatments
-	treatments, err := treatmentService.GetPatientTreatments(patientID)
+	treatments, err := s.treatments.GetPatientTreatments(c.Request.Context(), patientID)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve patient treatments"})
 		return
@@ -239,7 +175,7 @@ func GetPatientTreatments(c *gin.Context) {
 }
 
 // CreatePatientTreatment handles POST /api/patient-treatments
-func CreatePatientTreatment(c *gin.Context) {
+func (s *Server) CreatePatientTreatment(c *gin.Context) {
 	var req models.CreatePatientTreatmentRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
@@ -259,18 +195,8 @@ func CreatePatientTreatment(c *gin.Context) {
 		return
 	}
 
-	// Get database connection
-	db := database.GetDB()
-	if db == nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database connection not available"})
-		return
-	}
-
-	// Create treatment service
-	treatmentService := services.NewTreatmentService(db)
-
 	// Create patient treatment
-	treatment, err := treatmentService.CreatePatientTreatment(req, dentistIDInt)
+	treatment, err := s.treatments.CreatePatientTreatment(c.Request.Context(), req, dentistIDInt)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create patient treatment"})
 		return
@@ -280,7 +206,7 @@ func CreatePatientTreatment(c *gin.Context) {
 }
 
 // UpdatePatientTreatment handles PUT /api/patient-treatments/:id
-func UpdatePatientTreatment(c *gin.Context) {
+func (s *Server) UpdatePatientTreatment(c *gin.Context) {
 	// Parse treatment ID
 	idStr := c.Param("id")
 	id, err := strconv.Atoi(idStr)
@@ -295,18 +221,8 @@ func UpdatePatientTreatment(c *gin.Context) {
 		return
 	}
 
-	// Get database connection
-	db := database.GetDB()
-	if db == nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database connection not available"})
-		return
-	}
-
-	// Create treatment service
-	treatmentService := services.NewTreatmentService(db)
-
 	// Update patient treatment
-	treatment, err := treatmentService.UpdatePatientTreatment(id, req)
+	treatment, err := s.treatments.UpdatePatientTreatment(c.Request.Context(), id, req)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update patient treatment"})
 		return
@@ -321,7 +237,7 @@ func UpdatePatientTreatment(c *gin.Context) {
 }
 
 // DeletePatientTreatment handles DELETE /api/patient-treatments/:id
-func DeletePatientTreatment(c *gin.Context) {
+func (s *Server) DeletePatientTreatment(c *gin.Context) {
 	// Parse treatment ID
 	idStr := c.Param("id")
 	id, err := strconv.Atoi(idStr)
@@ -330,18 +246,8 @@ func DeletePatientTreatment(c *gin.Context) {
 		return
 	}
 
-	// Get database connection
-	db := database.GetDB()
-	if db == nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database connection not available"})
-		return
-	}
-
-	// Create treatment service
-	treatmentService := services.NewTreatmentService(db)
-
 	// Delete patient treatment
-	err = treatmentService.DeletePatientTreatment(id)
+	err = s.treatments.DeletePatientTreatment(c.Request.Context(), id)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete patient treatment"})
 		return
@@ -351,7 +257,7 @@ func DeletePatientTreatment(c *gin.Context) {
 }
 
 // AnalyzeTooth handles POST /api/tooth-analysis
-func AnalyzeTooth(c *gin.Context) {
+func (s *Server) AnalyzeTooth(c *gin.Context) {
 	// Parse form data
 	patientIDStr := c.PostForm("patientId")
 	patientID, err := strconv.Atoi(patientIDStr)
@@ -383,12 +289,6 @@ func AnalyzeTooth(c *gin.Context) {
 	}
 	defer os.Remove(tempFile) // Clean up
 
-	// Forward to Python ML service
-	mlServiceURL := os.Getenv("ML_SERVICE_URL")
-	if mlServiceURL == "" {
-		mlServiceURL = "http://localhost:8000" // Default URL
-	}
-
 	// Create multipart form data to send to ML service
 	var buf bytes.Buffer
 	writer := multipart.NewWriter(&buf)
@@ -423,7 +323,7 @@ func AnalyzeTooth(c *gin.Context) {
 	}
 
 	// Send request to ML service
-	resp, err := http.Post(mlServiceURL+"/analyze", writer.FormDataContentType(), &buf)
+	resp, err := s.httpClient.Post(s.cfg.MLServiceURL+"/analyze", writer.FormDataContentType(), &buf)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to connect to ML service: " + err.Error()})
 		return
@@ -0,0 +1,251 @@
+// dental_backend/internal/handlers/users.go
+package handlers
+
+import (
+	"database/sql"
+	"log"
+	"net/http"
+	"strconv"
+
+	"dental_backend/internal/models"
+	"dental_backend/internal/repository"
+	"dental_backend/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// respondUserError writes the error from a user management call
+func respondUserError(c *gin.Context, err error, failure string) {
+	if err == sql.ErrNoRows {
+		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		return
+	}
+	if _, ok := err.(*services.ValidationError); ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if _, ok := err.(*services.ForbiddenError); ok {
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		return
+	}
+	if _, ok := err.(*services.ConflictError); ok {
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		return
+	}
+	log.Printf("Error managing user: %v", err)
+	c.JSON(http.StatusInternalServerError, gin.H{"error": failure})
+}
+
+// GetUsers handles GET /api/users with optional role, active and search filters
+func (s *Server) GetUsers(c *gin.Context) {
+	filter := repository.UserFilter{Search: c.Query("search")}
+	if role := c.Query("role"); role != "" {
+		filter.Role = &role
+	}
+	if active := c.Query("active"); active != "" {
+		value, err := strconv.ParseBool(active)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "active must be true or false"})
+			return
+		}
+		filter.Active = &value
+	}
+
+	users, err := s.users.GetUsers(c.Request.Context(), filter)
+	if err != nil {
+		respondUserError(c, err, "Failed to retrieve users")
+		return
+	}
+
+	c.JSON(http.StatusOK, users)
+}
+
+// GetUser handles GET /api/users/:id
+func (s *Server) GetUser(c *gin.Context) {
+	userID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return
+	}
+
+	user, err := s.users.GetUser(c.Request.Context(), userID)
+	if err != nil {
+		respondUserError(c, err, "Failed to retrieve user")
+		return
+	}
+	if user == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, user)
+}
+
+// CreateUser handles POST /api/users
+func (s *Server) CreateUser(c *gin.Context) {
+	var req models.CreateUserRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	user, err := s.users.CreateUser(c.Request.Context(), req)
+	if err != nil {
+		respondUserError(c, err, "Failed to create user")
+		return
+	}
+	s.sendVerification(c, user)
+
+	c.JSON(http.StatusCreated, user)
+}
+
+// UpdateUser handles PUT /api/users/:id; a new role applies from the user's next token refresh
+func (s *Server) UpdateUser(c *gin.Context) {
+	userID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return
+	}
+
+	var req models.UpdateUserRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	user, err := s.users.UpdateUser(c.Request.Context(), userID, req)
+	if err != nil {
+		respondUserError(c, err, "Failed to update user")
+		return
+	}
+	if user == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		return
+	}
+
+	// A changed address has to be verified again
+	if req.Email != "" && !user.EmailVerified {
+		s.sendVerification(c, user)
+	}
+
+	c.JSON(http.StatusOK, user)
+}
+
+// DeleteUser handles DELETE /api/users/:id
+func (s *Server) DeleteUser(c *gin.Context) {
+	userID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return
+	}
+
+	// Get logged-in user from context
+	caller, exists := currentCaller(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	if err := s.users.DeleteUser(c.Request.Context(), caller, userID); err != nil {
+		respondUserError(c, err, "Failed to delete user")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "User deleted successfully"})
+}
+
+// DeactivateUser handles POST /api/users/:id/deactivate, blocking sign-in and ending the user's sessions
+func (s *Server) DeactivateUser(c *gin.Context) {
+	userID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return
+	}
+
+	// Get logged-in user from context
+	caller, exists := currentCaller(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	if err := s.users.Deactivate(c.Request.Context(), caller, userID); err != nil {
+		respondUserError(c, err, "Failed to deactivate user")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "User deactivated successfully"})
+}
+
+// ActivateUser handles POST /api/users/:id/activate
+func (s *Server) ActivateUser(c *gin.Context) {
+	userID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return
+	}
+
+	if err := s.users.Activate(c.Request.Context(), userID); err != nil {
+		respondUserError(c, err, "Failed to activate user")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "User activated successfully"})
+}
+
+// UpdateCurrentUser handles PUT /api/auth/user, letting users edit their own profile
+func (s *Server) UpdateCurrentUser(c *gin.Context) {
+	// Get logged-in user from context
+	caller, exists := currentCaller(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	var req models.UpdateUserRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	user, err := s.users.UpdateCurrentUser(c.Request.Context(), caller, req)
+	if err != nil {
+		respondUserError(c, err, "Failed to update profile")
+		return
+	}
+	if user == nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not found"})
+		return
+	}
+
+	// A changed address has to be verified again
+	if req.Email != "" && !user.EmailVerified {
+		s.sendVerification(c, user)
+	}
+
+	c.JSON(http.StatusOK, user)
+}
+
+// ChangePassword handles POST /api/auth/change-password. Every other session of
+// the user is signed out; the current one stays valid.
+func (s *Server) ChangePassword(c *gin.Context) {
+	// Get logged-in user from context
+	caller, exists := currentCaller(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	var req models.ChangePasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := s.users.ChangePassword(c.Request.Context(), caller, c.GetString("sessionID"), req); err != nil {
+		respondUserError(c, err, "Failed to change password")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Password changed successfully"})
+}
@@ -0,0 +1,236 @@
+// dental_backend/internal/handlers/waitlist.go
+package handlers
+
+import (
+	"database/sql"
+	"log"
+	"net/http"
+	"strconv"
+
+	"dental_backend/internal/models"
+	"dental_backend/internal/repository"
+	"dental_backend/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetWaitlist handles GET /api/waitlist?status=&dentistId=&patientId=
+func (s *Server) GetWaitlist(c *gin.Context) {
+	var filter repository.WaitlistFilter
+	if status := c.Query("status"); status != "" {
+		filter.Status = &status
+	}
+
+	for param, dst := range map[string]**int{"dentistId": &filter.DentistID, "patientId": &filter.PatientID} {
+		value := c.Query(param)
+		if value == "" {
+			continue
+		}
+		id, err := strconv.Atoi(value)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid " + param})
+			return
+		}
+		*dst = &id
+	}
+
+	entries, err := s.waitlist.GetEntries(c.Request.Context(), filter)
+	if err != nil {
+		log.Printf("Error retrieving waitlist: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve waitlist"})
+		return
+	}
+
+	// Ensure we always return an array, even if empty
+	if entries == nil {
+		entries = []models.WaitlistEntry{}
+	}
+
+	c.JSON(http.StatusOK, entries)
+}
+
+// GetWaitlistEntry handles GET /api/waitlist/:id
+func (s *Server) GetWaitlistEntry(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid waitlist entry ID"})
+		return
+	}
+
+	entry, err := s.waitlist.GetEntry(c.Request.Context(), id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve waitlist entry"})
+		return
+	}
+	if entry == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Waitlist entry not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, entry)
+}
+
+// CreateWaitlistEntry handles POST /api/waitlist
+func (s *Server) CreateWaitlistEntry(c *gin.Context) {
+	var req models.CreateWaitlistEntryRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	entry, err := s.waitlist.CreateEntry(c.Request.Context(), req)
+	if err != nil {
+		if _, ok := err.(*services.ValidationError); ok {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		log.Printf("Error creating waitlist entry: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create waitlist entry"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, entry)
+}
+
+// UpdateWaitlistEntry handles PUT /api/waitlist/:id
+func (s *Server) UpdateWaitlistEntry(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid waitlist entry ID"})
+		return
+	}
+
+	var req models.UpdateWaitlistEntryRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	entry, err := s.waitlist.UpdateEntry(c.Request.Context(), id, req)
+	if err != nil {
+		if _, ok := err.(*services.ValidationError); ok {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		log.Printf("Error updating waitlist entry: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update waitlist entry"})
+		return
+	}
+	if entry == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Waitlist entry not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, entry)
+}
+
+// DeleteWaitlistEntry handles DELETE /api/waitlist/:id
+func (s *Server) DeleteWaitlistEntry(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid waitlist entry ID"})
+		return
+	}
+
+	if err := s.waitlist.DeleteEntry(c.Request.Context(), id); err != nil {
+		if err == sql.ErrNoRows {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Waitlist entry not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete waitlist entry"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Waitlist entry deleted successfully"})
+}
+
+// GetSlotOpenings handles GET /api/waitlist/openings
+func (s *Server) GetSlotOpenings(c *gin.Context) {
+	// Get logged-in user from context
+	caller, exists := currentCaller(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	openings, err := s.waitlist.GetOpenings(c.Request.Context(), caller)
+	if err != nil {
+		log.Printf("Error retrieving slot openings: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve slot openings"})
+		return
+	}
+
+	c.JSON(http.StatusOK, openings)
+}
+
+// GetOpeningCandidates handles GET /api/waitlist/openings/:id/candidates
+func (s *Server) GetOpeningCandidates(c *gin.Context) {
+	// Get logged-in user from context
+	caller, exists := currentCaller(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	openingID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid opening ID"})
+		return
+	}
+
+	result, err := s.waitlist.GetCandidates(c.Request.Context(), openingID, caller)
+	if err != nil {
+		log.Printf("Error ranking waitlist candidates: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve waitlist candidates"})
+		return
+	}
+	if result == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Opening not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// BookSlotOpening handles POST /api/waitlist/openings/:id/book
+func (s *Server) BookSlotOpening(c *gin.Context) {
+	// Get logged-in user from context
+	caller, exists := currentCaller(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	openingID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid opening ID"})
+		return
+	}
+
+	var req models.BookOpeningRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	appointment, err := s.waitlist.BookOpening(c.Request.Context(), openingID, req, caller)
+	if err != nil {
+		if _, ok := err.(*services.ValidationError); ok {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		if conflictErr, ok := err.(*services.ConflictError); ok {
+			c.JSON(http.StatusConflict, gin.H{"error": conflictErr.Message, "conflictingAppointment": conflictErr.Conflicting})
+			return
+		}
+		log.Printf("Error booking slot opening: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to book slot opening"})
+		return
+	}
+	if appointment == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Opening not found"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, appointment)
+}
@@ -0,0 +1,127 @@
+// dental_backend/internal/ical/ical.go
+
+// Package ical writes iCalendar (RFC 5545) documents for calendar subscriptions.
+// It covers only what the feeds need: one calendar of timed VEVENTs in UTC.
+package ical
+
+import (
+	"bufio"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Event statuses
+const (
+	StatusTentative = "TENTATIVE"
+	StatusConfirmed = "CONFIRMED"
+	StatusCancelled = "CANCELLED"
+)
+
+// maxLineOctets is the longest content line allowed before folding
+const maxLineOctets = 75
+
+// Calendar is a VCALENDAR holding events
+type Calendar struct {
+	// ProdID identifies the product that created the calendar
+	ProdID string
+	// Name is shown by calendar clients for the subscription
+	Name   string
+	Events []Event
+}
+
+// Event is a VEVENT. UID must stay the same for the life of the event, and
+// Sequence must increase whenever its time or status changes so that clients
+// replace their copy.
+type Event struct {
+	UID         string
+	Sequence    int
+	Stamp       time.Time
+	Start       time.Time
+	End         time.Time
+	Summary     string
+	Description string
+	Location    string
+	Status      string
+}
+
+// Write renders the calendar with CRLF line endings and folded lines
+func (c Calendar) Write(w io.Writer) error {
+	bw := bufio.NewWriter(w)
+	line := func(name, value string) {
+		writeFolded(bw, name+":"+value)
+	}
+
+	line("BEGIN", "VCALENDAR")
+	line("VERSION", "2.0")
+	line("PRODID", c.ProdID)
+	line("CALSCALE", "GREGORIAN")
+	line("METHOD", "PUBLISH")
+	if c.Name != "" {
+		line("X-WR-CALNAME", escapeText(c.Name))
+	}
+
+	for _, e := range c.Events {
+		line("BEGIN", "VEVENT")
+		line("UID", e.UID)
+		line("SEQUENCE", strconv.Itoa(e.Sequence))
+		line("DTSTAMP", formatUTC(e.Stamp))
+		line("DTSTART", formatUTC(e.Start))
+		line("DTEND", formatUTC(e.End))
+		line("SUMMARY", escapeText(e.Summary))
+		if e.Description != "" {
+			line("DESCRIPTION", escapeText(e.Description))
+		}
+		if e.Location != "" {
+			line("LOCATION", escapeText(e.Location))
+		}
+		if e.Status != "" {
+			line("STATUS", e.Status)
+		}
+		line("END", "VEVENT")
+	}
+
+	line("END", "VCALENDAR")
+	return bw.Flush()
+}
+
+// formatUTC formats a time as an RFC 5545 UTC date-time
+func formatUTC(t time.Time) string {
+	return t.UTC().Format("20060102T150405Z")
+}
+
+// textEscaper escapes the characters RFC 5545 reserves in TEXT values
+var textEscaper = strings.NewReplacer(
+	`\`, `\\`,
+	";", `\;`,
+	",", `\,`,
+	"\r\n", `\n`,
+	"\n", `\n`,
+	"\r", `\n`,
+)
+
+// escapeText escapes a TEXT property value
+func escapeText(s string) string {
+	return textEscaper.Replace(s)
+}
+
+// writeFolded writes one content line, folding it at 75 octets without splitting
+// a UTF-8 sequence; continuation lines start with a space
+func writeFolded(w *bufio.Writer, line string) {
+	limit := maxLineOctets
+	for len(line) > limit {
+		cut := limit
+		// Back up to the start of a UTF-8 sequence
+		for cut > 0 && line[cut]&0xC0 == 0x80 {
+			cut--
+		}
+		w.WriteString(line[:cut])
+		w.WriteString("\r\n ")
+		line = line[cut:]
+		// The leading space counts towards the next line's length
+		limit = maxLineOctets - 1
+	}
+	w.WriteString(line)
+	w.WriteString("\r\n")
+}
@@ -0,0 +1,273 @@
+// dental_backend/internal/idtoken/idtoken.go
+
+// Package idtoken verifies OpenID Connect ID tokens locally. Tokens must be RS256
+// signed by a key from the issuer's published JWKS, which is cached and refreshed
+// as it ages or when a token names a key it has not seen, and must be issued by an
+// expected issuer for the configured client and not yet expired.
+package idtoken
+
+import (
+	"context"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// GoogleJWKSURL is where Google publishes the keys its ID tokens are signed with
+const GoogleJWKSURL = "https://www.googleapis.com/oauth2/v3/certs"
+
+// GoogleIssuers are the issuers Google ID tokens carry
+var GoogleIssuers = []string{"https://accounts.google.com", "accounts.google.com"}
+
+var (
+	// ErrInvalidToken is returned for tokens that are malformed, badly signed,
+	// expired or meant for another issuer or client
+	ErrInvalidToken = errors.New("invalid ID token")
+
+	// ErrKeysUnavailable is returned when the signing keys cannot be fetched
+	ErrKeysUnavailable = errors.New("ID token signing keys unavailable")
+)
+
+const (
+	// defaultKeyTTL is how long keys are cached when the JWKS response has no max-age
+	defaultKeyTTL = time.Hour
+	// minRefetch limits how often an unknown key ID triggers a fetch
+	minRefetch = time.Minute
+	// leeway tolerates clock drift between us and the issuer
+	leeway = time.Minute
+)
+
+// KeySet is a cached JWKS document
+type KeySet struct {
+	url    string
+	client *http.Client
+
+	mu        sync.Mutex
+	keys      map[string]*rsa.PublicKey
+	fetchedAt time.Time
+	expiresAt time.Time
+
+	// now returns the current time; replaced in tests to pin "now"
+	now func() time.Time
+}
+
+// NewKeySet creates a key set fetched from jwksURL
+func NewKeySet(jwksURL string, client *http.Client) *KeySet {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	return &KeySet{url: jwksURL, client: client, now: time.Now}
+}
+
+// Key returns the public key with the given ID, fetching the key set when it has
+// expired or does not know the ID yet. When a refresh fails, keys already cached
+// keep being used.
+func (k *KeySet) Key(ctx context.Context, kid string) (*rsa.PublicKey, error) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	now := k.now()
+	key, known := k.keys[kid]
+	stale := now.After(k.expiresAt)
+	if known && !stale {
+		return key, nil
+	}
+	if !known && !stale && now.Sub(k.fetchedAt) < minRefetch {
+		return nil, fmt.Errorf("%w: unknown key %q", ErrInvalidToken, kid)
+	}
+
+	if err := k.fetch(ctx); err != nil {
+		if known {
+			return key, nil
+		}
+		return nil, err
+	}
+
+	key, ok := k.keys[kid]
+	if !ok {
+		return nil, fmt.Errorf("%w: unknown key %q", ErrInvalidToken, kid)
+	}
+	return key, nil
+}
+
+// fetch downloads the key set; the caller holds mu
+func (k *KeySet) fetch(ctx context.Context) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, k.url, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := k.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrKeysUnavailable, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%w: key server returned %s", ErrKeysUnavailable, resp.Status)
+	}
+
+	var doc struct {
+		Keys []struct {
+			Kty string `json:"kty"`
+			Kid string `json:"kid"`
+			Use string `json:"use"`
+			N   string `json:"n"`
+			E   string `json:"e"`
+		} `json:"keys"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&doc); err != nil {
+		return fmt.Errorf("%w: decode key set: %v", ErrKeysUnavailable, err)
+	}
+
+	keys := make(map[string]*rsa.PublicKey, len(doc.Keys))
+	for _, jwk := range doc.Keys {
+		if jwk.Kty != "RSA" || (jwk.Use != "" && jwk.Use != "sig") {
+			continue
+		}
+		key, err := rsaKey(jwk.N, jwk.E)
+		if err != nil {
+			return fmt.Errorf("%w: key %q: %v", ErrKeysUnavailable, jwk.Kid, err)
+		}
+		keys[jwk.Kid] = key
+	}
+
+	now := k.now()
+	k.keys = keys
+	k.fetchedAt = now
+	k.expiresAt = now.Add(cacheTTL(resp.Header.Get("Cache-Control")))
+	return nil
+}
+
+// rsaKey decodes the base64url modulus and exponent of a JWK
+func rsaKey(n, e string) (*rsa.PublicKey, error) {
+	modulus, err := base64.RawURLEncoding.DecodeString(n)
+	if err != nil {
+		return nil, fmt.Errorf("decode modulus: %v", err)
+	}
+	exponent, err := base64.RawURLEncoding.DecodeString(e)
+	if err != nil {
+		return nil, fmt.Errorf("decode exponent: %v", err)
+	}
+	if len(exponent) == 0 || len(exponent) > 4 {
+		return nil, errors.New("unsupported exponent")
+	}
+
+	return &rsa.PublicKey{
+		N: new(big.Int).SetBytes(modulus),
+		E: int(new(big.Int).SetBytes(exponent).Int64()),
+	}, nil
+}
+
+// cacheTTL reads max-age from a Cache-Control header
+func cacheTTL(header string) time.Duration {
+	for _, directive := range strings.Split(header, ",") {
+		value, ok := strings.CutPrefix(strings.TrimSpace(directive), "max-age=")
+		if !ok {
+			continue
+		}
+		if seconds, err := strconv.Atoi(value); err == nil && seconds > 0 {
+			return time.Duration(seconds) * time.Second
+		}
+	}
+	return defaultKeyTTL
+}
+
+// Claims are the identity claims of a verified ID token
+type Claims struct {
+	Issuer        string
+	Subject       string
+	Email         string
+	EmailVerified bool
+	Name          string
+	GivenName     string
+	FamilyName    string
+	Picture       string
+
+	// Raw holds every claim, for providers that put roles or groups in their own claims
+	Raw map[string]interface{}
+}
+
+// Verifier checks ID tokens issued for one client
+type Verifier struct {
+	keys     *KeySet
+	audience string
+	issuers  []string
+
+	// now returns the current time; replaced in tests to pin "now"
+	now func() time.Time
+}
+
+// NewVerifier creates a verifier accepting tokens for the client ID audience from
+// any of issuers, signed with keys
+func NewVerifier(keys *KeySet, audience string, issuers ...string) *Verifier {
+	return &Verifier{keys: keys, audience: audience, issuers: issuers, now: time.Now}
+}
+
+// Verify checks the token and returns its claims. Errors wrap ErrInvalidToken, or
+// ErrKeysUnavailable when the token could not be checked.
+func (v *Verifier) Verify(ctx context.Context, raw string) (*Claims, error) {
+	claims := jwt.MapClaims{}
+	_, err := jwt.ParseWithClaims(raw, claims, func(token *jwt.Token) (interface{}, error) {
+		kid, _ := token.Header["kid"].(string)
+		return v.keys.Key(ctx, kid)
+	},
+		jwt.WithValidMethods([]string{jwt.SigningMethodRS256.Alg()}),
+		jwt.WithAudience(v.audience),
+		jwt.WithExpirationRequired(),
+		jwt.WithIssuedAt(),
+		jwt.WithLeeway(leeway),
+		jwt.WithTimeFunc(v.now),
+	)
+	if err != nil {
+		if errors.Is(err, ErrKeysUnavailable) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("%w: %v", ErrInvalidToken, err)
+	}
+
+	issuer, _ := claims["iss"].(string)
+	if !v.trusts(issuer) {
+		return nil, fmt.Errorf("%w: unexpected issuer %q", ErrInvalidToken, issuer)
+	}
+	subject, _ := claims["sub"].(string)
+	if subject == "" {
+		return nil, fmt.Errorf("%w: missing subject", ErrInvalidToken)
+	}
+
+	result := &Claims{Issuer: issuer, Subject: subject, Raw: claims}
+	result.Email, _ = claims["email"].(string)
+	result.Name, _ = claims["name"].(string)
+	result.GivenName, _ = claims["given_name"].(string)
+	result.FamilyName, _ = claims["family_name"].(string)
+	result.Picture, _ = claims["picture"].(string)
+
+	// Some issuers send email_verified as a string
+	switch verified := claims["email_verified"].(type) {
+	case bool:
+		result.EmailVerified = verified
+	case string:
+		result.EmailVerified = verified == "true"
+	}
+
+	return result, nil
+}
+
+// trusts reports whether issuer is one the verifier accepts
+func (v *Verifier) trusts(issuer string) bool {
+	for _, trusted := range v.issuers {
+		if issuer == trusted {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,277 @@
+// dental_backend/internal/idtoken/idtoken_test.go
+package idtoken
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const (
+	testIssuer   = "https://issuer.example.com"
+	testAudience = "client-123"
+)
+
+// jwksServer publishes the public halves of its keys and counts the fetches
+type jwksServer struct {
+	*httptest.Server
+
+	mu      sync.Mutex
+	keys    map[string]*rsa.PrivateKey
+	fetches int
+}
+
+func newJWKSServer(t *testing.T, keys map[string]*rsa.PrivateKey) *jwksServer {
+	t.Helper()
+	s := &jwksServer{keys: keys}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		s.fetches++
+
+		type jwk struct {
+			Kty string `json:"kty"`
+			Kid string `json:"kid"`
+			Use string `json:"use"`
+			N   string `json:"n"`
+			E   string `json:"e"`
+		}
+		var doc struct {
+			Keys []jwk `json:"keys"`
+		}
+		for kid, key := range s.keys {
+			doc.Keys = append(doc.Keys, jwk{
+				Kty: "RSA",
+				Kid: kid,
+				Use: "sig",
+				N:   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+				E:   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+			})
+		}
+		w.Header().Set("Cache-Control", "public, max-age=3600")
+		json.NewEncoder(w).Encode(doc)
+	}))
+	t.Cleanup(s.Close)
+	return s
+}
+
+// publish adds a key to the published set
+func (s *jwksServer) publish(kid string, key *rsa.PrivateKey) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.keys[kid] = key
+}
+
+// fetchCount returns how many times the key set was fetched
+func (s *jwksServer) fetchCount() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.fetches
+}
+
+func generateKey(t *testing.T) *rsa.PrivateKey {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("generate key: %v", err)
+	}
+	return key
+}
+
+// validClaims returns the claims of a token that verifies at now
+func validClaims(now time.Time) jwt.MapClaims {
+	return jwt.MapClaims{
+		"iss":            testIssuer,
+		"aud":            testAudience,
+		"sub":            "user-1",
+		"email":          "jane@example.com",
+		"email_verified": true,
+		"iat":            now.Add(-time.Minute).Unix(),
+		"exp":            now.Add(time.Hour).Unix(),
+	}
+}
+
+// signRS256 signs claims with key under the key ID kid
+func signRS256(t *testing.T, kid string, key *rsa.PrivateKey, claims jwt.MapClaims) string {
+	t.Helper()
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	token.Header["kid"] = kid
+	raw, err := token.SignedString(key)
+	if err != nil {
+		t.Fatalf("sign token: %v", err)
+	}
+	return raw
+}
+
+func TestVerify(t *testing.T) {
+	now := time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC)
+	key := generateKey(t)
+	otherKey := generateKey(t)
+
+	tests := []struct {
+		name    string
+		token   func(t *testing.T) string
+		wantErr error
+	}{
+		{
+			name: "valid",
+			token: func(t *testing.T) string {
+				return signRS256(t, "key-1", key, validClaims(now))
+			},
+		},
+		{
+			name: "expired",
+			token: func(t *testing.T) string {
+				claims := validClaims(now)
+				claims["iat"] = now.Add(-2 * time.Hour).Unix()
+				claims["exp"] = now.Add(-time.Hour).Unix()
+				return signRS256(t, "key-1", key, claims)
+			},
+			wantErr: ErrInvalidToken,
+		},
+		{
+			name: "wrong audience",
+			token: func(t *testing.T) string {
+				claims := validClaims(now)
+				claims["aud"] = "another-client"
+				return signRS256(t, "key-1", key, claims)
+			},
+			wantErr: ErrInvalidToken,
+		},
+		{
+			name: "wrong issuer",
+			token: func(t *testing.T) string {
+				claims := validClaims(now)
+				claims["iss"] = "https://evil.example.com"
+				return signRS256(t, "key-1", key, claims)
+			},
+			wantErr: ErrInvalidToken,
+		},
+		{
+			name: "missing subject",
+			token: func(t *testing.T) string {
+				claims := validClaims(now)
+				delete(claims, "sub")
+				return signRS256(t, "key-1", key, claims)
+			},
+			wantErr: ErrInvalidToken,
+		},
+		{
+			name: "bad signature",
+			token: func(t *testing.T) string {
+				return signRS256(t, "key-1", otherKey, validClaims(now))
+			},
+			wantErr: ErrInvalidToken,
+		},
+		{
+			name: "alg none",
+			token: func(t *testing.T) string {
+				token := jwt.NewWithClaims(jwt.SigningMethodNone, validClaims(now))
+				token.Header["kid"] = "key-1"
+				raw, err := token.SignedString(jwt.UnsafeAllowNoneSignatureType)
+				if err != nil {
+					t.Fatalf("sign token: %v", err)
+				}
+				return raw
+			},
+			wantErr: ErrInvalidToken,
+		},
+		{
+			// HS256 signed with the public key as the HMAC secret must not pass as RS256
+			name: "HS256 key confusion",
+			token: func(t *testing.T) string {
+				secret, err := x509.MarshalPKIXPublicKey(&key.PublicKey)
+				if err != nil {
+					t.Fatalf("marshal public key: %v", err)
+				}
+				token := jwt.NewWithClaims(jwt.SigningMethodHS256, validClaims(now))
+				token.Header["kid"] = "key-1"
+				raw, err := token.SignedString(secret)
+				if err != nil {
+					t.Fatalf("sign token: %v", err)
+				}
+				return raw
+			},
+			wantErr: ErrInvalidToken,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := newJWKSServer(t, map[string]*rsa.PrivateKey{"key-1": key})
+			keys := NewKeySet(server.URL, server.Client())
+			keys.now = func() time.Time { return now }
+			verifier := NewVerifier(keys, testAudience, testIssuer)
+			verifier.now = func() time.Time { return now }
+
+			claims, err := verifier.Verify(context.Background(), tt.token(t))
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("Verify() error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Verify() error = %v", err)
+			}
+			if claims.Subject != "user-1" || claims.Email != "jane@example.com" || !claims.EmailVerified {
+				t.Errorf("Verify() claims = %+v", claims)
+			}
+		})
+	}
+}
+
+func TestVerifyUnknownKeyRefetchesOnce(t *testing.T) {
+	now := time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC)
+	key := generateKey(t)
+	rotated := generateKey(t)
+
+	server := newJWKSServer(t, map[string]*rsa.PrivateKey{"key-1": key})
+	keys := NewKeySet(server.URL, server.Client())
+	keys.now = func() time.Time { return now }
+	verifier := NewVerifier(keys, testAudience, testIssuer)
+	verifier.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	if _, err := verifier.Verify(ctx, signRS256(t, "key-1", key, validClaims(now))); err != nil {
+		t.Fatalf("Verify() with a published key: %v", err)
+	}
+	if got := server.fetchCount(); got != 1 {
+		t.Fatalf("fetches after first token = %d, want 1", got)
+	}
+
+	// Once minRefetch has passed, a token naming a key rotated in since the
+	// last fetch refetches the set
+	now = now.Add(minRefetch)
+	server.publish("key-2", rotated)
+	if _, err := verifier.Verify(ctx, signRS256(t, "key-2", rotated, validClaims(now))); err != nil {
+		t.Fatalf("Verify() with a rotated key: %v", err)
+	}
+	if got := server.fetchCount(); got != 2 {
+		t.Fatalf("fetches after rotated key = %d, want 2", got)
+	}
+
+	// Further unknown keys within minRefetch are rejected without fetching
+	unknown := signRS256(t, "key-3", generateKey(t), validClaims(now))
+	for i := 0; i < 3; i++ {
+		_, err := verifier.Verify(ctx, unknown)
+		if !errors.Is(err, ErrInvalidToken) {
+			t.Fatalf("Verify() with an unknown key error = %v, want %v", err, ErrInvalidToken)
+		}
+	}
+	if got := server.fetchCount(); got != 2 {
+		t.Errorf("fetches after repeated unknown keys = %d, want 2", got)
+	}
+}
@@ -0,0 +1,72 @@
+// dental_backend/internal/models/account.go
+package models
+
+import "time"
+
+// AccountTokenPurpose is what a single-use account token may be exchanged for
+type AccountTokenPurpose string
+
+const (
+	AccountTokenPasswordReset     AccountTokenPurpose = "password-reset"
+	AccountTokenEmailVerification AccountTokenPurpose = "email-verification"
+	// AccountTokenSignIn hands a finished provider sign-in back to the web app
+	AccountTokenSignIn AccountTokenPurpose = "sign-in"
+)
+
+// AccountToken is an emailed single-use token; only its hash is stored
+type AccountToken struct {
+	ID        int                 `json:"id" db:"id"`
+	UserID    int                 `json:"userId" db:"user_id"`
+	Purpose   AccountTokenPurpose `json:"purpose" db:"purpose"`
+	ExpiresAt time.Time           `json:"expiresAt" db:"expires_at"`
+	UsedAt    *time.Time          `json:"usedAt" db:"used_at"`
+	CreatedAt time.Time           `json:"createdAt" db:"created_at"`
+}
+
+// AuthPolicy holds the clinic's sign-in rules, edited by admins
+type AuthPolicy struct {
+	// RequireEmailVerification stops users signing in with a password until
+	// they have verified their email address
+	RequireEmailVerification bool `json:"requireEmailVerification" db:"require_email_verification"`
+	// RequireMFARoles lists the roles that must use two-factor authentication
+	RequireMFARoles []string  `json:"requireMfaRoles" db:"require_mfa_roles"`
+	UpdatedAt       time.Time `json:"updatedAt" db:"updated_at"`
+}
+
+// RequiresMFA reports whether the policy makes two-factor authentication mandatory for a role
+func (p AuthPolicy) RequiresMFA(role string) bool {
+	for _, r := range p.RequireMFARoles {
+		if r == role {
+			return true
+		}
+	}
+	return false
+}
+
+// UpdateAuthPolicyRequest represents the request payload for changing the sign-in policy;
+// nil fields are left unchanged
+type UpdateAuthPolicyRequest struct {
+	RequireEmailVerification *bool     `json:"requireEmailVerification"`
+	RequireMFARoles          *[]string `json:"requireMfaRoles" binding:"omitempty,dive,oneof=dentist hygienist admin staff"`
+}
+
+// ForgotPasswordRequest represents the request payload for requesting a password reset email
+type ForgotPasswordRequest struct {
+	Email string `json:"email" binding:"required,email"`
+}
+
+// ResetPasswordRequest represents the request payload for setting a new password from a reset email
+type ResetPasswordRequest struct {
+	Token       string `json:"token" binding:"required"`
+	NewPassword string `json:"newPassword" binding:"required,min=6"`
+}
+
+// VerifyEmailRequest represents the request payload for confirming an email address
+type VerifyEmailRequest struct {
+	Token string `json:"token" binding:"required"`
+}
+
+// ResendVerificationRequest represents the request payload for sending a new verification email
+type ResendVerificationRequest struct {
+	Email string `json:"email" binding:"required,email"`
+}
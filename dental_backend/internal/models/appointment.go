@@ -2,40 +2,104 @@
 package models
 
 import (
+	"fmt"
 	"time"
 )
 
 // Appointment represents an appointment in the system
 type Appointment struct {
 	ID              int       `json:"id" db:"id"`
+	ClinicID        int       `json:"clinicId" db:"clinic_id"`
 	PatientID       int       `json:"patientId" db:"patient_id"`
 	DentistID       int       `json:"dentistId" db:"dentist_id"`
 	PatientName     string    `json:"patientName" db:"patient_name"`
 	AppointmentDate string    `json:"appointmentDate" db:"appointment_date"`
 	StartTime       string    `json:"startTime" db:"start_time"`
+	EndTime         string    `json:"endTime" db:"end_time"`
+	Duration        int       `json:"duration" db:"duration_minutes"` // in minutes
+	TreatmentID     *int      `json:"treatmentId" db:"treatment_id"`  // nullable
+	SeriesID        *int      `json:"seriesId" db:"series_id"`        // nullable
 	Status          string    `json:"status" db:"status"`
 	Notes           string    `json:"notes" db:"notes"`
+	Sequence        int       `json:"sequence" db:"sequence"` // bumped when the time changes or it is cancelled
 	CreatedAt       time.Time `json:"createdAt" db:"created_at"`
 	UpdatedAt       time.Time `json:"updatedAt" db:"updated_at"`
+
+	CancellationReason string     `json:"cancellationReason" db:"cancellation_reason"`
+	ConfirmedAt        *time.Time `json:"confirmedAt" db:"confirmed_at"`
+	CheckedInAt        *time.Time `json:"checkedInAt" db:"checked_in_at"`
+	InChairAt          *time.Time `json:"inChairAt" db:"in_chair_at"`
+	CompletedAt        *time.Time `json:"completedAt" db:"completed_at"`
+	CancelledAt        *time.Time `json:"cancelledAt" db:"cancelled_at"`
+	NoShowAt           *time.Time `json:"noShowAt" db:"no_show_at"`
 }
 
+// DefaultAppointmentDuration is used when neither a duration nor a treatment is given
+const DefaultAppointmentDuration = 30
+
 // AppointmentStatus represents the status of an appointment
 type AppointmentStatus string
 
 const (
-	AppointmentStatusScheduled AppointmentStatus = "scheduled"
-	AppointmentStatusCompleted AppointmentStatus = "completed"
-	AppointmentStatusCancelled AppointmentStatus = "cancelled"
-	AppointmentStatusNoShow    AppointmentStatus = "no-show"
+	AppointmentStatusPendingConfirmation AppointmentStatus = "pending-confirmation"
+	AppointmentStatusScheduled           AppointmentStatus = "scheduled"
+	AppointmentStatusConfirmed           AppointmentStatus = "confirmed"
+	AppointmentStatusCheckedIn           AppointmentStatus = "checked-in"
+	AppointmentStatusInChair             AppointmentStatus = "in-chair"
+	AppointmentStatusCompleted           AppointmentStatus = "completed"
+	AppointmentStatusCancelled           AppointmentStatus = "cancelled"
+	AppointmentStatusNoShow              AppointmentStatus = "no-show"
 )
 
+// AppointmentTransitions lists the statuses each status may move to. Online
+// bookings start pending confirmation by the front desk. Completed, cancelled
+// and no-show appointments are final.
+var AppointmentTransitions = map[AppointmentStatus][]AppointmentStatus{
+	AppointmentStatusPendingConfirmation: {AppointmentStatusScheduled, AppointmentStatusConfirmed, AppointmentStatusCancelled},
+	AppointmentStatusScheduled:           {AppointmentStatusConfirmed, AppointmentStatusCheckedIn, AppointmentStatusCancelled, AppointmentStatusNoShow},
+	AppointmentStatusConfirmed:           {AppointmentStatusScheduled, AppointmentStatusCheckedIn, AppointmentStatusCancelled, AppointmentStatusNoShow},
+	AppointmentStatusCheckedIn:           {AppointmentStatusInChair, AppointmentStatusCancelled},
+	AppointmentStatusInChair:             {AppointmentStatusCompleted},
+	AppointmentStatusCompleted:           {},
+	AppointmentStatusCancelled:           {},
+	AppointmentStatusNoShow:              {},
+}
+
+// CanTransition reports whether an appointment may move from one status to another
+func CanTransition(from, to AppointmentStatus) bool {
+	for _, next := range AppointmentTransitions[from] {
+		if next == to {
+			return true
+		}
+	}
+	return false
+}
+
+// AppointmentEvent records one status change of an appointment
+type AppointmentEvent struct {
+	ID            int       `json:"id" db:"id"`
+	AppointmentID int       `json:"appointmentId" db:"appointment_id"`
+	FromStatus    string    `json:"fromStatus" db:"from_status"` // empty for the initial status
+	ToStatus      string    `json:"toStatus" db:"to_status"`
+	Reason        string    `json:"reason" db:"reason"`
+	ActorID       *int      `json:"actorId" db:"actor_id"`     // nullable
+	ActorType     string    `json:"actorType" db:"actor_type"` // user, patient or system
+	CreatedAt     time.Time `json:"createdAt" db:"created_at"`
+}
+
 // CreateAppointmentRequest represents the request payload for creating an appointment
 type CreateAppointmentRequest struct {
+	DentistID       *int   `json:"dentistId"` // required when staff or an admin books
 	PatientID       int    `json:"patientId" binding:"required"`
 	AppointmentDate string `json:"appointmentDate" binding:"required"`
 	StartTime       string `json:"startTime" binding:"required"`
-	Status          string `json:"status" binding:"oneof=scheduled completed cancelled no-show"`
+	Duration        int    `json:"duration" binding:"omitempty,min=1"`
+	TreatmentID     *int   `json:"treatmentId"`
+	Status          string `json:"status" binding:"omitempty,oneof=scheduled confirmed"`
 	Notes           string `json:"notes"`
+
+	// SeriesID links an occurrence to its recurring series; set by the service only
+	SeriesID *int `json:"-"`
 }
 
 // UpdateAppointmentRequest represents the request payload for updating an appointment
@@ -43,6 +107,110 @@ type UpdateAppointmentRequest struct {
 	PatientID       int    `json:"patientId"`
 	AppointmentDate string `json:"appointmentDate"`
 	StartTime       string `json:"startTime"`
-	Status          string `json:"status" binding:"oneof=scheduled completed cancelled no-show"`
+	Duration        int    `json:"duration" binding:"omitempty,min=1"`
+	TreatmentID     *int   `json:"treatmentId"`
+	Status          string `json:"status" binding:"omitempty,oneof=scheduled confirmed checked-in in-chair completed cancelled no-show"`
 	Notes           string `json:"notes"`
-}
\ No newline at end of file
+
+	// CancellationReason is recorded when Status moves to cancelled
+	CancellationReason string `json:"cancellationReason"`
+
+	// FromStatus makes a status change conditional on the current status; set by the service only
+	FromStatus string `json:"-"`
+}
+
+// CancelAppointmentRequest represents the request payload for cancelling appointments
+type CancelAppointmentRequest struct {
+	Reason string `json:"reason"`
+}
+
+// IsUpcoming reports whether the appointment is booked but the visit has not started
+func (a Appointment) IsUpcoming() bool {
+	return a.Status == string(AppointmentStatusScheduled) || a.Status == string(AppointmentStatusConfirmed)
+}
+
+// IsPending reports whether the appointment is an online booking awaiting the front desk
+func (a Appointment) IsPending() bool {
+	return a.Status == string(AppointmentStatusPendingConfirmation)
+}
+
+// IsFinal reports whether the appointment can no longer change status
+func (a Appointment) IsFinal() bool {
+	return len(AppointmentTransitions[AppointmentStatus(a.Status)]) == 0
+}
+
+// IsActive reports whether the appointment still occupies its time slot
+func (a Appointment) IsActive() bool {
+	return a.Status != string(AppointmentStatusCancelled) && a.Status != string(AppointmentStatusNoShow)
+}
+
+// Appointment dates and times arrive from clients as "2006-01-02" and "15:04",
+// but TIME and DATE columns scanned from PostgreSQL come back as RFC 3339 values.
+var (
+	appointmentDateLayouts = []string{"2006-01-02", time.RFC3339}
+	appointmentTimeLayouts = []string{"15:04", "15:04:05", time.RFC3339}
+)
+
+// ParseAppointmentDate parses an appointment date in any of the accepted layouts
+func ParseAppointmentDate(value string) (time.Time, error) {
+	for _, layout := range appointmentDateLayouts {
+		if t, err := time.Parse(layout, value); err == nil {
+			return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC), nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid appointment date %q", value)
+}
+
+// ParseAppointmentTime parses a start time and returns its offset from midnight
+func ParseAppointmentTime(value string) (time.Duration, error) {
+	for _, layout := range appointmentTimeLayouts {
+		if t, err := time.Parse(layout, value); err == nil {
+			return time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute + time.Duration(t.Second())*time.Second, nil
+		}
+	}
+	return 0, fmt.Errorf("invalid appointment time %q", value)
+}
+
+// FormatClock formats an offset from midnight as "15:04:05"
+func FormatClock(offset time.Duration) string {
+	return fmt.Sprintf("%02d:%02d:%02d", int(offset.Hours()), int(offset.Minutes())%60, int(offset.Seconds())%60)
+}
+
+// FormatHourMinute formats an offset from midnight as "15:04"
+func FormatHourMinute(offset time.Duration) string {
+	return fmt.Sprintf("%02d:%02d", int(offset.Hours()), int(offset.Minutes())%60)
+}
+
+// AppointmentWindow is the half-open interval an appointment occupies on one day
+type AppointmentWindow struct {
+	Date  string // YYYY-MM-DD
+	Start time.Duration
+	End   time.Duration
+}
+
+// NewAppointmentWindow builds the window for a date, start time and duration in minutes
+func NewAppointmentWindow(date, startTime string, duration int) (AppointmentWindow, error) {
+	day, err := ParseAppointmentDate(date)
+	if err != nil {
+		return AppointmentWindow{}, err
+	}
+	start, err := ParseAppointmentTime(startTime)
+	if err != nil {
+		return AppointmentWindow{}, err
+	}
+	return AppointmentWindow{
+		Date:  day.Format("2006-01-02"),
+		Start: start,
+		End:   start + time.Duration(duration)*time.Minute,
+	}, nil
+}
+
+// Window returns the interval occupied by the appointment
+func (a Appointment) Window() (AppointmentWindow, error) {
+	return NewAppointmentWindow(a.AppointmentDate, a.StartTime, a.Duration)
+}
+
+// Overlaps reports whether two windows on the same day intersect; touching ends do not overlap
+func (w AppointmentWindow) Overlaps(other AppointmentWindow) bool {
+	return w.Date == other.Date && w.Start < other.End && other.Start < w.End
+}
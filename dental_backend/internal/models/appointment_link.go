@@ -0,0 +1,78 @@
+// dental_backend/internal/models/appointment_link.go
+package models
+
+import (
+	"time"
+)
+
+// AppointmentLinkAction is what a patient does through a self-service link
+type AppointmentLinkAction string
+
+const (
+	AppointmentLinkConfirm    AppointmentLinkAction = "confirm"
+	AppointmentLinkCancel     AppointmentLinkAction = "cancel"
+	AppointmentLinkReschedule AppointmentLinkAction = "reschedule"
+)
+
+// AppointmentLink is a signed self-service link token issued for an appointment
+type AppointmentLink struct {
+	AppointmentID int       `json:"appointmentId"`
+	Token         string    `json:"token"`
+	ExpiresAt     time.Time `json:"expiresAt"`
+}
+
+// AppointmentLinkUse records that a link token has been spent on an action
+type AppointmentLinkUse struct {
+	TokenID       string    `json:"tokenId" db:"token_id"`
+	AppointmentID int       `json:"appointmentId" db:"appointment_id"`
+	Action        string    `json:"action" db:"action"`
+	UsedAt        time.Time `json:"usedAt" db:"used_at"`
+}
+
+// PublicAppointment is the part of an appointment shown to a patient through a link
+type PublicAppointment struct {
+	PatientName     string `json:"patientName"`
+	AppointmentDate string `json:"appointmentDate"`
+	StartTime       string `json:"startTime"`
+	EndTime         string `json:"endTime"`
+	Duration        int    `json:"duration"`
+	Status          string `json:"status"`
+}
+
+// NewPublicAppointment strips an appointment down to what its patient may see
+func NewPublicAppointment(a Appointment) PublicAppointment {
+	return PublicAppointment{
+		PatientName:     a.PatientName,
+		AppointmentDate: a.AppointmentDate,
+		StartTime:       a.StartTime,
+		EndTime:         a.EndTime,
+		Duration:        a.Duration,
+		Status:          a.Status,
+	}
+}
+
+// AppointmentLinkPreview tells a patient opening a link whether its action is still possible
+type AppointmentLinkPreview struct {
+	Appointment PublicAppointment `json:"appointment"`
+	Action      string            `json:"action"`
+	Allowed     bool              `json:"allowed"`
+	Reason      string            `json:"reason,omitempty"` // why the action is not allowed
+}
+
+// RescheduleOptions lists the open slots a patient may move their appointment to
+type RescheduleOptions struct {
+	Appointment PublicAppointment `json:"appointment"`
+	Days        []Availability    `json:"days"`
+}
+
+// PublicCancelRequest represents the request payload for a patient cancelling through a link
+type PublicCancelRequest struct {
+	Reason string `json:"reason"`
+}
+
+// PublicRescheduleRequest represents the request payload for a patient moving their appointment
+// to one of the offered slots
+type PublicRescheduleRequest struct {
+	AppointmentDate string `json:"appointmentDate" binding:"required"`
+	StartTime       string `json:"startTime" binding:"required"`
+}
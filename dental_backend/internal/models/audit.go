@@ -0,0 +1,39 @@
+// dental_backend/internal/models/audit.go
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// AuditEntry records one API request: who made it, what it did to which record
+// and, for writes, what changed. Entries form a hash chain; Hash covers every
+// other field except ID, and PrevHash is the Hash of the entry before.
+type AuditEntry struct {
+	ID           int64     `json:"id" db:"id"`
+	OccurredAt   time.Time `json:"occurredAt" db:"occurred_at"`
+	ClinicID     *int      `json:"clinicId" db:"clinic_id"`
+	ActorID      *int      `json:"actorId" db:"actor_id"`
+	ActorRole    string    `json:"actorRole" db:"actor_role"`
+	Action       string    `json:"action" db:"action"`
+	ResourceType string    `json:"resourceType" db:"resource_type"`
+	ResourceID   string    `json:"resourceId" db:"resource_id"`
+	Method       string    `json:"method" db:"method"`
+	Route        string    `json:"route" db:"route"`
+	Status       int       `json:"status" db:"status"`
+	// Changes maps each field a write changed to its old and new value
+	Changes   json.RawMessage `json:"changes,omitempty" db:"changes"`
+	RequestID string          `json:"requestId" db:"request_id"`
+	IPAddress string          `json:"ipAddress" db:"ip_address"`
+	PrevHash  string          `json:"prevHash" db:"prev_hash"`
+	Hash      string          `json:"hash" db:"hash"`
+}
+
+// AuditVerification is the result of checking the audit hash chain
+type AuditVerification struct {
+	Valid   bool `json:"valid"`
+	Checked int  `json:"checked"`
+	// BrokenAt is the first entry that does not match the chain
+	BrokenAt *int64 `json:"brokenAt,omitempty"`
+	Reason   string `json:"reason,omitempty"`
+}
@@ -0,0 +1,43 @@
+// dental_backend/internal/models/booking.go
+package models
+
+// AppointmentType is a treatment offered for online booking
+type AppointmentType struct {
+	ID          int    `json:"id"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	Category    string `json:"category"`
+	Duration    int    `json:"duration"` // in minutes
+}
+
+// Dentist is the public profile of a dentist patients can book with
+type Dentist struct {
+	ID   int    `json:"id" db:"id"`
+	Name string `json:"name" db:"name"`
+}
+
+// OnlineBookingRequest represents the request payload for a patient booking online.
+// The patient is matched by email and date of birth, or created provisionally.
+type OnlineBookingRequest struct {
+	AppointmentTypeID int    `json:"appointmentTypeId" binding:"required"`
+	DentistID         int    `json:"dentistId" binding:"required"`
+	AppointmentDate   string `json:"appointmentDate" binding:"required"`
+	StartTime         string `json:"startTime" binding:"required"`
+
+	FirstName   string `json:"firstName" binding:"required"`
+	LastName    string `json:"lastName" binding:"required"`
+	DateOfBirth string `json:"dateOfBirth" binding:"required"`
+	Email       string `json:"email" binding:"required,email"`
+	Phone       string `json:"phone" binding:"required"`
+	Notes       string `json:"notes"`
+
+	// CaptchaToken is passed to the configured captcha verifier, if any
+	CaptchaToken string `json:"captchaToken"`
+}
+
+// OnlineBooking is the result of an online booking, with a self-service link the
+// patient can use to cancel or reschedule it
+type OnlineBooking struct {
+	Appointment PublicAppointment `json:"appointment"`
+	Link        *AppointmentLink  `json:"link"`
+}
@@ -0,0 +1,38 @@
+// dental_backend/internal/models/calendar.go
+package models
+
+import "time"
+
+// CalendarFeedType is whose appointments a calendar feed lists
+type CalendarFeedType string
+
+const (
+	CalendarFeedDentist CalendarFeedType = "dentist"
+	CalendarFeedPatient CalendarFeedType = "patient"
+)
+
+// CalendarFeed is a subscription URL issued to a user for one dentist's or one
+// patient's appointments at one clinic. Only a hash of the token is stored, so
+// the token and URL are returned once, when the feed is created.
+type CalendarFeed struct {
+	ID         int              `json:"id" db:"id"`
+	ClinicID   int              `json:"clinicId" db:"clinic_id"`
+	UserID     int              `json:"userId" db:"user_id"`
+	Type       CalendarFeedType `json:"type" db:"feed_type"`
+	SubjectID  int              `json:"subjectId" db:"subject_id"`
+	CreatedAt  time.Time        `json:"createdAt" db:"created_at"`
+	LastUsedAt *time.Time       `json:"lastUsedAt" db:"last_used_at"`
+	RevokedAt  *time.Time       `json:"revokedAt" db:"revoked_at"`
+
+	// UserRole is the owner's current role at the clinic, which scopes what the feed shows
+	UserRole string `json:"-" db:"role"`
+
+	Token string `json:"token,omitempty"`
+	URL   string `json:"url,omitempty"`
+}
+
+// CreateCalendarFeedRequest represents the request payload for creating a calendar feed
+type CreateCalendarFeedRequest struct {
+	Type      string `json:"type" binding:"required,oneof=dentist patient"`
+	SubjectID int    `json:"subjectId" binding:"required"`
+}
@@ -0,0 +1,59 @@
+// dental_backend/internal/models/clinic.go
+package models
+
+import "time"
+
+// Clinic is one practice of the group. Patients and their records belong to a
+// single clinic; users may work at several.
+type Clinic struct {
+	ID        int       `json:"id" db:"id"`
+	Name      string    `json:"name" db:"name"`
+	Address   string    `json:"address" db:"address"`
+	Phone     string    `json:"phone" db:"phone"`
+	CreatedAt time.Time `json:"createdAt" db:"created_at"`
+	UpdatedAt time.Time `json:"updatedAt" db:"updated_at"`
+}
+
+// UserClinic is a clinic a user works at, with their role there
+type UserClinic struct {
+	ClinicID int    `json:"clinicId" db:"clinic_id"`
+	Name     string `json:"name" db:"name"`
+	Role     string `json:"role" db:"role"`
+}
+
+// ClinicMember is a user working at a clinic, with their role there
+type ClinicMember struct {
+	UserID    int       `json:"userId" db:"user_id"`
+	Email     string    `json:"email" db:"email"`
+	FirstName string    `json:"firstName" db:"first_name"`
+	LastName  string    `json:"lastName" db:"last_name"`
+	Role      string    `json:"role" db:"role"`
+	Active    bool      `json:"active"`
+	JoinedAt  time.Time `json:"joinedAt" db:"created_at"`
+}
+
+// CreateClinicRequest represents the request payload for opening a clinic
+type CreateClinicRequest struct {
+	Name    string `json:"name" binding:"required"`
+	Address string `json:"address"`
+	Phone   string `json:"phone"`
+}
+
+// UpdateClinicRequest represents the request payload for changing a clinic's details;
+// nil fields are left unchanged
+type UpdateClinicRequest struct {
+	Name    *string `json:"name" binding:"omitempty,min=1"`
+	Address *string `json:"address"`
+	Phone   *string `json:"phone"`
+}
+
+// SetClinicMemberRequest represents the request payload for adding a user to a
+// clinic or changing their role there
+type SetClinicMemberRequest struct {
+	Role string `json:"role" binding:"required,oneof=dentist hygienist admin staff"`
+}
+
+// SwitchClinicRequest represents the request payload for changing the clinic a session works in
+type SwitchClinicRequest struct {
+	ClinicID int `json:"clinicId" binding:"required"`
+}
@@ -0,0 +1,43 @@
+// dental_backend/internal/models/identity.go
+package models
+
+import "time"
+
+// UserIdentity links a user to their account at an external sign-in provider
+type UserIdentity struct {
+	ID          int        `json:"id" db:"id"`
+	UserID      int        `json:"userId" db:"user_id"`
+	Provider    string     `json:"provider" db:"provider"`
+	Subject     string     `json:"-" db:"subject"`
+	Email       string     `json:"email" db:"email"`
+	CreatedAt   time.Time  `json:"createdAt" db:"created_at"`
+	LastLoginAt *time.Time `json:"lastLoginAt" db:"last_login_at"`
+}
+
+// ExternalProfile is what a sign-in provider vouches for about a user. Role is the
+// role a new account gets; empty when the provider may not create accounts.
+// AutoLink is set for providers the operator trusts to link an account to the
+// user with the same verified email address on its first sign-in.
+type ExternalProfile struct {
+	Provider      string
+	Subject       string
+	Email         string
+	EmailVerified bool
+	FirstName     string
+	LastName      string
+	Phone         string
+	Role          string
+	AutoLink      bool
+}
+
+// SignInProvider describes a configured OpenID Connect provider to the login page
+type SignInProvider struct {
+	Name        string `json:"name"`
+	DisplayName string `json:"displayName"`
+	LoginURL    string `json:"loginUrl"`
+}
+
+// SignInCodeRequest represents the request payload exchanging a provider sign-in code for tokens
+type SignInCodeRequest struct {
+	Code string `json:"code" binding:"required"`
+}
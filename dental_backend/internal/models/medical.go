@@ -0,0 +1,110 @@
+// dental_backend/internal/models/medical.go
+package models
+
+import (
+	"time"
+)
+
+// MedicalEntryKind is what a medical history entry records
+type MedicalEntryKind string
+
+const (
+	MedicalEntryAllergy    MedicalEntryKind = "allergy"
+	MedicalEntryMedication MedicalEntryKind = "medication"
+	MedicalEntryCondition  MedicalEntryKind = "condition"
+)
+
+// MedicalSeverity represents how serious an allergy, medication or condition is
+type MedicalSeverity string
+
+const (
+	MedicalSeverityMild            MedicalSeverity = "mild"
+	MedicalSeverityModerate        MedicalSeverity = "moderate"
+	MedicalSeveritySevere          MedicalSeverity = "severe"
+	MedicalSeverityLifeThreatening MedicalSeverity = "life-threatening"
+)
+
+// MedicalEntryStatus represents whether an entry still applies to the patient
+type MedicalEntryStatus string
+
+const (
+	MedicalEntryStatusActive   MedicalEntryStatus = "active"
+	MedicalEntryStatusInactive MedicalEntryStatus = "inactive"
+	MedicalEntryStatusResolved MedicalEntryStatus = "resolved"
+)
+
+// MedicalEntry is an allergy, medication or condition in a patient's medical
+// history. Fields tagged encrypted are stored encrypted when field encryption
+// keys are configured.
+type MedicalEntry struct {
+	ID         int       `json:"id" db:"id"`
+	PatientID  int       `json:"patientId" db:"patient_id"`
+	Kind       string    `json:"kind" db:"kind"`
+	Name       string    `json:"name" db:"name" encrypted:"true"`         // the allergen, drug or condition
+	Severity   string    `json:"severity" db:"severity"`                  // empty when not assessed
+	Reaction   string    `json:"reaction" db:"reaction" encrypted:"true"` // allergies
+	Dosage     string    `json:"dosage" db:"dosage" encrypted:"true"`     // medications
+	Onset      string    `json:"onset" db:"onset"`                        // YYYY-MM-DD, empty when unknown
+	Status     string    `json:"status" db:"status"`
+	Alert      bool      `json:"alert" db:"alert"` // shown as a medical alert whatever its severity
+	Notes      string    `json:"notes" db:"notes" encrypted:"true"`
+	RecordedBy *int      `json:"recordedBy" db:"recorded_by"` // nullable
+	CreatedAt  time.Time `json:"createdAt" db:"created_at"`
+	UpdatedAt  time.Time `json:"updatedAt" db:"updated_at"`
+}
+
+// MedicalAlert is an entry of a patient's medical history staff must be warned
+// about before treating them
+type MedicalAlert struct {
+	EntryID  int    `json:"entryId"`
+	Kind     string `json:"kind"`
+	Name     string `json:"name"`
+	Severity string `json:"severity,omitempty"`
+	Detail   string `json:"detail,omitempty"` // the reaction to an allergen or the dosage of a medication
+}
+
+// MedicalHistory is a patient's medical history: the structured entries, the
+// alerts drawn from them, and the free-text notes recorded before entries
+// existed, kept as the patient's medicalHistory
+type MedicalHistory struct {
+	PatientID   int            `json:"patientId"`
+	Alerts      []MedicalAlert `json:"alerts"`
+	Allergies   []MedicalEntry `json:"allergies"`
+	Medications []MedicalEntry `json:"medications"`
+	Conditions  []MedicalEntry `json:"conditions"`
+	Notes       string         `json:"notes"`
+}
+
+// PatientDetail is a patient with the alerts from their medical history and
+// their derived risk, next to the risk level entered by hand
+type PatientDetail struct {
+	Patient
+	MedicalAlerts []MedicalAlert  `json:"medicalAlerts"`
+	DerivedRisk   *RiskAssessment `json:"derivedRisk"`
+}
+
+// CreateMedicalEntryRequest represents the request payload for adding an
+// allergy, medication or condition to a patient's medical history
+type CreateMedicalEntryRequest struct {
+	Name     string `json:"name" binding:"required"`
+	Severity string `json:"severity" binding:"omitempty,oneof=mild moderate severe life-threatening"`
+	Reaction string `json:"reaction"`
+	Dosage   string `json:"dosage"`
+	Onset    string `json:"onset"`
+	Status   string `json:"status" binding:"omitempty,oneof=active inactive resolved"`
+	Alert    bool   `json:"alert"`
+	Notes    string `json:"notes"`
+}
+
+// UpdateMedicalEntryRequest represents the request payload for updating a
+// medical history entry; empty strings clear the optional fields
+type UpdateMedicalEntryRequest struct {
+	Name     *string `json:"name" binding:"omitempty,min=1"`
+	Severity *string `json:"severity" binding:"omitempty,oneof='' mild moderate severe life-threatening"`
+	Reaction *string `json:"reaction"`
+	Dosage   *string `json:"dosage"`
+	Onset    *string `json:"onset"`
+	Status   *string `json:"status" binding:"omitempty,oneof=active inactive resolved"`
+	Alert    *bool   `json:"alert"`
+	Notes    *string `json:"notes"`
+}
@@ -0,0 +1,54 @@
+// dental_backend/internal/models/mfa.go
+package models
+
+import "time"
+
+// UserMFA is a user's two-factor authentication state. Secret is set from setup
+// onwards but only enforced once EnabledAt is set.
+type UserMFA struct {
+	UserID    int        `json:"userId" db:"id"`
+	Secret    string     `json:"-" db:"totp_secret"`
+	EnabledAt *time.Time `json:"enabledAt" db:"totp_enabled_at"`
+	// LastStep is the time step of the last accepted code, so a code cannot be replayed
+	LastStep             *int64 `json:"-" db:"totp_last_step"`
+	BackupCodesRemaining int    `json:"backupCodesRemaining"`
+}
+
+// MFAStatus describes the caller's two-factor authentication
+type MFAStatus struct {
+	Enabled              bool       `json:"enabled"`
+	EnabledAt            *time.Time `json:"enabledAt"`
+	BackupCodesRemaining int        `json:"backupCodesRemaining"`
+	// Required is set when the sign-in policy makes two-factor authentication mandatory for the caller's role
+	Required bool `json:"required"`
+}
+
+// MFASetup is a new, not yet enabled authenticator secret
+type MFASetup struct {
+	Secret          string `json:"secret"`
+	ProvisioningURI string `json:"provisioningUri"`
+}
+
+// MFAChallenge is returned by a password login that still needs a second factor.
+// When EnrollmentRequired is set the user has to set up an authenticator first.
+type MFAChallenge struct {
+	Token              string    `json:"mfaToken"`
+	ExpiresAt          time.Time `json:"expiresAt"`
+	EnrollmentRequired bool      `json:"enrollmentRequired"`
+}
+
+// MFACodeRequest represents a request carrying an authenticator or backup code
+type MFACodeRequest struct {
+	Code string `json:"code" binding:"required"`
+}
+
+// MFAChallengeRequest represents the request payload answering a login challenge
+type MFAChallengeRequest struct {
+	MFAToken string `json:"mfaToken" binding:"required"`
+	Code     string `json:"code" binding:"required"`
+}
+
+// MFAEnrollRequest represents the request payload for setting up an authenticator during login
+type MFAEnrollRequest struct {
+	MFAToken string `json:"mfaToken" binding:"required"`
+}
@@ -5,20 +5,22 @@ import (
 	"time"
 )
 
-// Patient represents a patient in the system
+// Patient represents a patient in the system. Fields tagged encrypted are
+// stored encrypted when field encryption keys are configured.
 type Patient struct {
 	ID                    int       `json:"id" db:"id"`
 	FirstName             string    `json:"firstName" db:"first_name"`
 	LastName              string    `json:"lastName" db:"last_name"`
-	DateOfBirth           string    `json:"dateOfBirth" db:"date_of_birth"`
-	Phone                 string    `json:"phone" db:"phone"`
-	Email                 string    `json:"email" db:"email"`
-	Address               string    `json:"address" db:"address"`
+	DateOfBirth           string    `json:"dateOfBirth" db:"date_of_birth" encrypted:"true"`
+	Phone                 string    `json:"phone" db:"phone" encrypted:"true"`
+	Email                 string    `json:"email" db:"email" encrypted:"true"`
+	Address               string    `json:"address" db:"address" encrypted:"true"`
 	EmergencyContact      string    `json:"emergencyContact" db:"emergency_contact"`
 	InsuranceProvider     string    `json:"insuranceProvider" db:"insurance_provider"`
-	InsurancePolicyNumber string    `json:"insurancePolicyNumber" db:"insurance_policy_number"`
-	MedicalHistory        string    `json:"medicalHistory" db:"medical_history"`
+	InsurancePolicyNumber string    `json:"insurancePolicyNumber" db:"insurance_policy_number" encrypted:"true"`
+	MedicalHistory        string    `json:"medicalHistory" db:"medical_history" encrypted:"true"`
 	RiskLevel             string    `json:"riskLevel" db:"risk_level"`
+	Provisional           bool      `json:"provisional" db:"provisional"` // created by online booking, not yet verified
 	CreatedAt             time.Time `json:"createdAt" db:"created_at"`
 	UpdatedAt             time.Time `json:"updatedAt" db:"updated_at"`
 }
@@ -45,6 +47,9 @@ type CreatePatientRequest struct {
 	InsurancePolicyNumber string `json:"insurancePolicyNumber"`
 	MedicalHistory        string `json:"medicalHistory"`
 	RiskLevel             string `json:"riskLevel" binding:"required,oneof=low medium high"`
+
+	// Provisional marks patients created by online booking; set by the service only
+	Provisional bool `json:"-"`
 }
 
 // UpdatePatientRequest represents the request payload for updating a patient
@@ -60,4 +65,5 @@ type UpdatePatientRequest struct {
 	InsurancePolicyNumber string `json:"insurancePolicyNumber"`
 	MedicalHistory        string `json:"medicalHistory"`
 	RiskLevel             string `json:"riskLevel" binding:"oneof=low medium high"`
+	Provisional           *bool  `json:"provisional"` // cleared once staff have verified an online booking
 }
@@ -0,0 +1,36 @@
+// dental_backend/internal/models/reminder.go
+package models
+
+import (
+	"time"
+)
+
+// ReminderStatus represents the state of a reminder delivery
+type ReminderStatus string
+
+const (
+	ReminderSending ReminderStatus = "sending"
+	ReminderSent    ReminderStatus = "sent"
+	ReminderFailed  ReminderStatus = "failed"
+)
+
+// MaxReminderAttempts is how many times a failed reminder is retried before giving up
+const MaxReminderAttempts = 3
+
+// ReminderDelivery records a reminder sent to a patient for one appointment slot,
+// offset and channel
+type ReminderDelivery struct {
+	ID              int        `json:"id" db:"id"`
+	AppointmentID   int        `json:"appointmentId" db:"appointment_id"`
+	AppointmentDate string     `json:"appointmentDate" db:"appointment_date"`
+	StartTime       string     `json:"startTime" db:"start_time"`
+	OffsetMinutes   int        `json:"offsetMinutes" db:"offset_minutes"`
+	Channel         string     `json:"channel" db:"channel"`
+	Recipient       string     `json:"recipient" db:"recipient"`
+	Status          string     `json:"status" db:"status"`
+	Attempts        int        `json:"attempts" db:"attempts"`
+	Error           string     `json:"error" db:"error"`
+	SentAt          *time.Time `json:"sentAt" db:"sent_at"`
+	CreatedAt       time.Time  `json:"createdAt" db:"created_at"`
+	UpdatedAt       time.Time  `json:"updatedAt" db:"updated_at"`
+}
@@ -0,0 +1,110 @@
+// dental_backend/internal/models/risk.go
+package models
+
+import (
+	"time"
+)
+
+// RiskFactorKind names what a risk factor was derived from
+type RiskFactorKind string
+
+const (
+	RiskFactorConditions             RiskFactorKind = "conditions"
+	RiskFactorAge                    RiskFactorKind = "age"
+	RiskFactorHighPriorityTreatments RiskFactorKind = "highPriorityTreatments"
+	RiskFactorNoShows                RiskFactorKind = "noShows"
+	RiskFactorOverdueInvoices        RiskFactorKind = "overdueInvoices"
+)
+
+// RiskRules configures how a patient's risk score and level are derived. Each
+// factor adds points to the score; the level follows from the thresholds.
+type RiskRules struct {
+	Conditions             RiskConditionRule `json:"conditions"`
+	AgeBands               []RiskAgeBand     `json:"ageBands" binding:"dive"`
+	HighPriorityTreatments RiskCountRule     `json:"highPriorityTreatments"` // outstanding high and urgent patient treatments
+	NoShows                RiskCountRule     `json:"noShows"`
+	NoShowWindowDays       int               `json:"noShowWindowDays" binding:"min=0"` // zero counts every no-show
+	OverdueInvoices        RiskCountRule     `json:"overdueInvoices"`                  // overdue, or pending past their due date
+
+	// MediumScore and HighScore are the scores from which a patient is at medium and high risk
+	MediumScore int `json:"mediumScore" binding:"min=1"`
+	HighScore   int `json:"highScore" binding:"gtfield=MediumScore"`
+}
+
+// RiskConditionRule gives the points for each active medical condition by its severity
+type RiskConditionRule struct {
+	Unassessed      int `json:"unassessed" binding:"min=0"`
+	Mild            int `json:"mild" binding:"min=0"`
+	Moderate        int `json:"moderate" binding:"min=0"`
+	Severe          int `json:"severe" binding:"min=0"`
+	LifeThreatening int `json:"lifeThreatening" binding:"min=0"`
+	Max             int `json:"max" binding:"min=0"` // cap on the factor's points; zero is no cap
+}
+
+// Points returns the points for an active condition of the severity
+func (r RiskConditionRule) Points(severity string) int {
+	switch MedicalSeverity(severity) {
+	case MedicalSeverityMild:
+		return r.Mild
+	case MedicalSeverityModerate:
+		return r.Moderate
+	case MedicalSeveritySevere:
+		return r.Severe
+	case MedicalSeverityLifeThreatening:
+		return r.LifeThreatening
+	}
+	return r.Unassessed
+}
+
+// RiskCountRule gives the points for each occurrence of a factor
+type RiskCountRule struct {
+	Points int `json:"points" binding:"min=0"`
+	Max    int `json:"max" binding:"min=0"` // cap on the factor's points; zero is no cap
+}
+
+// RiskAgeBand gives the points for patients whose age falls within the band
+type RiskAgeBand struct {
+	MinAge int `json:"minAge" binding:"min=0"`
+	MaxAge int `json:"maxAge" binding:"min=0"` // inclusive; zero is no upper bound
+	Points int `json:"points" binding:"min=0"`
+}
+
+// DefaultRiskRules returns the rules used until an admin configures others
+func DefaultRiskRules() RiskRules {
+	return RiskRules{
+		Conditions:             RiskConditionRule{Unassessed: 1, Mild: 1, Moderate: 2, Severe: 3, LifeThreatening: 5, Max: 8},
+		AgeBands:               []RiskAgeBand{{MinAge: 0, MaxAge: 12, Points: 1}, {MinAge: 65, MaxAge: 79, Points: 2}, {MinAge: 80, Points: 3}},
+		HighPriorityTreatments: RiskCountRule{Points: 2, Max: 4},
+		NoShows:                RiskCountRule{Points: 1, Max: 3},
+		NoShowWindowDays:       365,
+		OverdueInvoices:        RiskCountRule{Points: 1, Max: 2},
+		MediumScore:            3,
+		HighScore:              6,
+	}
+}
+
+// RiskFactor is one contribution to a patient's derived risk score. It
+// describes the factor by counts and bands only, so the assessment reveals none
+// of the encrypted medical details behind it.
+type RiskFactor struct {
+	Factor      string `json:"factor"`
+	Description string `json:"description"`
+	Count       int    `json:"count,omitempty"`
+	Points      int    `json:"points"`
+}
+
+// RiskAssessment is a patient's derived risk score and level with the factors
+// that contributed to it
+type RiskAssessment struct {
+	PatientID  int          `json:"patientId" db:"patient_id"`
+	Score      int          `json:"score" db:"score"`
+	Level      string       `json:"level" db:"level"`
+	Factors    []RiskFactor `json:"factors" db:"factors"`
+	ComputedAt time.Time    `json:"computedAt" db:"computed_at"`
+}
+
+// RiskRecomputeResult reports a batch recompute of derived risk levels
+type RiskRecomputeResult struct {
+	Patients int `json:"patients"`
+	Changed  int `json:"changed"` // patients whose derived level changed
+}
@@ -0,0 +1,99 @@
+// dental_backend/internal/models/schedule.go
+package models
+
+import (
+	"time"
+)
+
+// WorkingHours is one block of a dentist's weekly working time
+type WorkingHours struct {
+	ID        int    `json:"id" db:"id"`
+	DentistID int    `json:"dentistId" db:"dentist_id"`
+	Weekday   int    `json:"weekday" db:"weekday"` // 0 = Sunday ... 6 = Saturday
+	StartTime string `json:"startTime" db:"start_time"`
+	EndTime   string `json:"endTime" db:"end_time"`
+}
+
+// ScheduleBreak is a recurring weekly break such as lunch
+type ScheduleBreak struct {
+	ID        int    `json:"id" db:"id"`
+	DentistID int    `json:"dentistId" db:"dentist_id"`
+	Weekday   int    `json:"weekday" db:"weekday"` // 0 = Sunday ... 6 = Saturday
+	StartTime string `json:"startTime" db:"start_time"`
+	EndTime   string `json:"endTime" db:"end_time"`
+	Label     string `json:"label" db:"label"`
+}
+
+// WeeklySchedule is a dentist's recurring working hours and breaks
+type WeeklySchedule struct {
+	DentistID int             `json:"dentistId"`
+	Hours     []WorkingHours  `json:"hours"`
+	Breaks    []ScheduleBreak `json:"breaks"`
+
+	// Default is true when the dentist has no hours of their own and works the clinic default
+	Default bool `json:"default"`
+}
+
+// Closure is a one-off closure or holiday; a nil DentistID closes the whole clinic
+type Closure struct {
+	ID        int       `json:"id" db:"id"`
+	DentistID *int      `json:"dentistId" db:"dentist_id"` // nullable
+	StartDate string    `json:"startDate" db:"start_date"`
+	EndDate   string    `json:"endDate" db:"end_date"`
+	Reason    string    `json:"reason" db:"reason"`
+	CreatedAt time.Time `json:"createdAt" db:"created_at"`
+}
+
+// Slot is a bookable start and end time on a given day
+type Slot struct {
+	StartTime string `json:"startTime"`
+	EndTime   string `json:"endTime"`
+}
+
+// Availability lists the open slots for a dentist on one day
+type Availability struct {
+	DentistID int    `json:"dentistId"`
+	Date      string `json:"date"`
+	Duration  int    `json:"duration"`
+	Closed    bool   `json:"closed"`
+	Reason    string `json:"reason,omitempty"`
+	Slots     []Slot `json:"slots"`
+}
+
+// WorkingHoursEntry is a block of working time in a schedule update
+type WorkingHoursEntry struct {
+	Weekday   int    `json:"weekday" binding:"min=0,max=6"`
+	StartTime string `json:"startTime" binding:"required"`
+	EndTime   string `json:"endTime" binding:"required"`
+}
+
+// ScheduleBreakEntry is a break in a schedule update
+type ScheduleBreakEntry struct {
+	Weekday   int    `json:"weekday" binding:"min=0,max=6"`
+	StartTime string `json:"startTime" binding:"required"`
+	EndTime   string `json:"endTime" binding:"required"`
+	Label     string `json:"label"`
+}
+
+// UpdateScheduleRequest replaces a dentist's weekly schedule
+type UpdateScheduleRequest struct {
+	Hours  []WorkingHoursEntry  `json:"hours" binding:"dive"`
+	Breaks []ScheduleBreakEntry `json:"breaks" binding:"dive"`
+}
+
+// CreateClosureRequest represents the request payload for creating a closure
+type CreateClosureRequest struct {
+	DentistID *int   `json:"dentistId"`
+	StartDate string `json:"startDate" binding:"required"`
+	EndDate   string `json:"endDate"`
+	Reason    string `json:"reason"`
+}
+
+// DefaultWorkingHours is the clinic's standard week, used for dentists without a schedule
+var DefaultWorkingHours = []WorkingHoursEntry{
+	{Weekday: int(time.Monday), StartTime: "09:00", EndTime: "17:00"},
+	{Weekday: int(time.Tuesday), StartTime: "09:00", EndTime: "17:00"},
+	{Weekday: int(time.Wednesday), StartTime: "09:00", EndTime: "17:00"},
+	{Weekday: int(time.Thursday), StartTime: "09:00", EndTime: "17:00"},
+	{Weekday: int(time.Friday), StartTime: "09:00", EndTime: "17:00"},
+}
@@ -0,0 +1,79 @@
+// dental_backend/internal/models/series.go
+package models
+
+import (
+	"time"
+)
+
+// RecurrenceFrequency is how often a series repeats
+type RecurrenceFrequency string
+
+const (
+	RecurrenceDaily   RecurrenceFrequency = "daily"
+	RecurrenceWeekly  RecurrenceFrequency = "weekly"
+	RecurrenceMonthly RecurrenceFrequency = "monthly"
+)
+
+// MaxSeriesOccurrences caps how many appointments one series may expand into
+const MaxSeriesOccurrences = 104
+
+// Recurrence is an RRULE-style rule: FREQ, INTERVAL, COUNT or UNTIL, and BYDAY for weekly series
+type Recurrence struct {
+	Frequency string `json:"frequency" db:"frequency" binding:"required,oneof=daily weekly monthly"`
+	Interval  int    `json:"interval" db:"interval" binding:"omitempty,min=1"`
+	Count     int    `json:"count,omitempty" db:"count" binding:"omitempty,min=1"`
+	Until     string `json:"until,omitempty" db:"until"`
+	Weekday   *int   `json:"weekday,omitempty" db:"weekday" binding:"omitempty,min=0,max=6"` // 0 = Sunday
+}
+
+// AppointmentSeries is the template a recurring set of appointments was expanded from
+type AppointmentSeries struct {
+	ID          int        `json:"id" db:"id"`
+	DentistID   int        `json:"dentistId" db:"dentist_id"`
+	PatientID   int        `json:"patientId" db:"patient_id"`
+	TreatmentID *int       `json:"treatmentId" db:"treatment_id"` // nullable
+	StartDate   string     `json:"startDate" db:"start_date"`
+	StartTime   string     `json:"startTime" db:"start_time"`
+	Duration    int        `json:"duration" db:"duration_minutes"` // in minutes
+	Notes       string     `json:"notes" db:"notes"`
+	Recurrence  Recurrence `json:"recurrence"`
+	CreatedAt   time.Time  `json:"createdAt" db:"created_at"`
+	UpdatedAt   time.Time  `json:"updatedAt" db:"updated_at"`
+}
+
+// SeriesScope selects which occurrences of a series an edit or cancellation applies to
+type SeriesScope string
+
+const (
+	SeriesScopeThis      SeriesScope = "this"
+	SeriesScopeFollowing SeriesScope = "following"
+	SeriesScopeAll       SeriesScope = "all"
+)
+
+// CreateSeriesRequest represents the request payload for creating a recurring series
+type CreateSeriesRequest struct {
+	DentistID   *int       `json:"dentistId"` // required when staff or an admin books
+	PatientID   int        `json:"patientId" binding:"required"`
+	TreatmentID *int       `json:"treatmentId"`
+	StartDate   string     `json:"startDate" binding:"required"`
+	StartTime   string     `json:"startTime" binding:"required"`
+	Duration    int        `json:"duration" binding:"omitempty,min=1"`
+	Notes       string     `json:"notes"`
+	Recurrence  Recurrence `json:"recurrence" binding:"required"`
+}
+
+// OccurrenceConflict explains why one occurrence of a series was not booked or changed
+type OccurrenceConflict struct {
+	AppointmentID          *int         `json:"appointmentId,omitempty"`
+	Date                   string       `json:"date"`
+	StartTime              string       `json:"startTime"`
+	Reason                 string       `json:"reason"`
+	ConflictingAppointment *Appointment `json:"conflictingAppointment,omitempty"`
+}
+
+// SeriesResult reports the appointments touched by a series operation and the occurrences that failed
+type SeriesResult struct {
+	Series       *AppointmentSeries   `json:"series"`
+	Appointments []Appointment        `json:"appointments"`
+	Conflicts    []OccurrenceConflict `json:"conflicts"`
+}
@@ -0,0 +1,40 @@
+// dental_backend/internal/models/session.go
+package models
+
+import "time"
+
+// Session is a login on one device. Its access tokens are only accepted while
+// it is neither revoked nor expired.
+type Session struct {
+	ID         string     `json:"id" db:"id"`
+	UserID     int        `json:"userId" db:"user_id"`
+	ClinicID   *int       `json:"clinicId" db:"clinic_id"`
+	UserAgent  string     `json:"userAgent" db:"user_agent"`
+	IPAddress  string     `json:"ipAddress" db:"ip_address"`
+	CreatedAt  time.Time  `json:"createdAt" db:"created_at"`
+	LastUsedAt time.Time  `json:"lastUsedAt" db:"last_used_at"`
+	ExpiresAt  time.Time  `json:"expiresAt" db:"expires_at"`
+	RevokedAt  *time.Time `json:"revokedAt" db:"revoked_at"`
+}
+
+// SessionClient describes the device a session was started or refreshed from
+type SessionClient struct {
+	UserAgent string
+	IPAddress string
+}
+
+// TokenPair is a short-lived access token with the refresh token that renews it
+type TokenPair struct {
+	AccessToken  string
+	RefreshToken string
+	// ExpiresAt is when the access token expires
+	ExpiresAt time.Time
+	SessionID string
+	// Clinic is the clinic the access token works in, with the user's role there
+	Clinic UserClinic
+}
+
+// RefreshRequest represents the request payload for refreshing an access token
+type RefreshRequest struct {
+	RefreshToken string `json:"refreshToken" binding:"required"`
+}
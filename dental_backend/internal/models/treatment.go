@@ -7,12 +7,13 @@ import (
 
 // Treatment represents a treatment in the system
 type Treatment struct {
-	ID          int     `json:"id" db:"id"`
-	Name        string  `json:"name" db:"name"`
-	Description string  `json:"description" db:"description"`
-	Cost        float64 `json:"cost" db:"cost"`
-	Duration    int     `json:"duration" db:"duration_minutes"` // in minutes
-	Category    string  `json:"category" db:"category"`
+	ID             int     `json:"id" db:"id"`
+	Name           string  `json:"name" db:"name"`
+	Description    string  `json:"description" db:"description"`
+	Cost           float64 `json:"cost" db:"cost"`
+	Duration       int     `json:"duration" db:"duration_minutes"` // in minutes
+	Category       string  `json:"category" db:"category"`
+	BookableOnline bool    `json:"bookableOnline" db:"bookable_online"` // offered as an appointment type in online booking
 	// Removed CreatedAt and UpdatedAt since they don't exist in the database
 }
 
@@ -32,6 +33,9 @@ type PatientTreatment struct {
 	Notes          string    `json:"notes" db:"notes"`
 	CreatedAt      time.Time `json:"createdAt" db:"created_at"`
 	UpdatedAt      time.Time `json:"updatedAt" db:"updated_at"`
+
+	// MedicalAlerts are the patient's medical alerts, set on treatment queue entries
+	MedicalAlerts []MedicalAlert `json:"medicalAlerts,omitempty" db:"-"`
 }
 
 // TreatmentCategory represents the category of a treatment
@@ -73,6 +77,8 @@ type CreateTreatmentRequest struct {
 	Cost        float64 `json:"cost" binding:"required,min=0"`
 	Duration    int     `json:"duration" binding:"required,min=1"`
 	Category    string  `json:"category"`
+
+	BookableOnline bool `json:"bookableOnline"`
 }
 
 // UpdateTreatmentRequest represents the request payload for updating a treatment
@@ -82,6 +88,8 @@ type UpdateTreatmentRequest struct {
 	Cost        float64 `json:"cost" binding:"min=0"`
 	Duration    int     `json:"duration" binding:"min=1"`
 	Category    string  `json:"category"`
+
+	BookableOnline *bool `json:"bookableOnline"`
 }
 
 // CreatePatientTreatmentRequest represents the request payload for creating a patient treatment
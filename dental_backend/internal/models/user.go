@@ -16,6 +16,16 @@ type User struct {
 	Phone     string    `json:"phone" db:"phone"`
 	CreatedAt time.Time `json:"createdAt" db:"created_at"`
 	UpdatedAt time.Time `json:"updatedAt" db:"updated_at"`
+
+	// EmailVerified is set once the user follows the link sent to their email address
+	EmailVerified bool `json:"emailVerified" db:"email_verified"`
+
+	// MFAEnabled is set once the user has enrolled an authenticator app
+	MFAEnabled bool `json:"mfaEnabled"`
+
+	// Active is false once the user is deactivated; they keep their history but cannot sign in
+	Active        bool       `json:"active"`
+	DeactivatedAt *time.Time `json:"deactivatedAt" db:"deactivated_at"`
 }
 
 // UserRole represents the role of a user in the system
@@ -40,7 +50,6 @@ type RegisterRequest struct {
 	Password  string `json:"password" binding:"required,min=6"`
 	FirstName string `json:"firstName" binding:"required"`
 	LastName  string `json:"lastName" binding:"required"`
-	Role      string `json:"role" binding:"required,oneof=dentist hygienist admin staff"`
 	Phone     string `json:"phone"`
 }
 
@@ -50,12 +59,23 @@ type AuthResponse struct {
 	User  User   `json:"user"`
 }
 
-// UpdateUserRequest represents the request payload for updating a user
+// CreateUserRequest represents the request payload for an admin creating a user
+type CreateUserRequest struct {
+	Email     string `json:"email" binding:"required,email"`
+	Password  string `json:"password" binding:"required,min=6"`
+	FirstName string `json:"firstName" binding:"required"`
+	LastName  string `json:"lastName" binding:"required"`
+	Role      string `json:"role" binding:"required,oneof=dentist hygienist admin staff"`
+	Phone     string `json:"phone"`
+}
+
+// UpdateUserRequest represents the request payload for updating a user.
+// Empty fields are left unchanged.
 type UpdateUserRequest struct {
-	Email     string `json:"email" binding:"email"`
+	Email     string `json:"email" binding:"omitempty,email"`
 	FirstName string `json:"firstName"`
 	LastName  string `json:"lastName"`
-	Role      string `json:"role" binding:"oneof=dentist hygienist admin staff"`
+	Role      string `json:"role" binding:"omitempty,oneof=dentist hygienist admin staff"`
 	Phone     string `json:"phone"`
 }
 
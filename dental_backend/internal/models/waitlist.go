@@ -0,0 +1,135 @@
+// dental_backend/internal/models/waitlist.go
+package models
+
+import (
+	"time"
+)
+
+// WaitlistUrgency is how soon a waitlisted patient needs to be seen
+type WaitlistUrgency string
+
+const (
+	WaitlistUrgencyLow    WaitlistUrgency = "low"
+	WaitlistUrgencyMedium WaitlistUrgency = "medium"
+	WaitlistUrgencyHigh   WaitlistUrgency = "high"
+	WaitlistUrgencyUrgent WaitlistUrgency = "urgent"
+)
+
+// WaitlistStatus represents the state of a waitlist entry
+type WaitlistStatus string
+
+const (
+	WaitlistStatusWaiting WaitlistStatus = "waiting"
+	WaitlistStatusBooked  WaitlistStatus = "booked"
+	WaitlistStatusRemoved WaitlistStatus = "removed"
+)
+
+// WaitlistEntry is a patient waiting for an earlier or any suitable slot
+type WaitlistEntry struct {
+	ID           int       `json:"id" db:"id"`
+	PatientID    int       `json:"patientId" db:"patient_id"`
+	PatientName  string    `json:"patientName" db:"patient_name"` // Joined from patients table
+	DentistIDs   []int     `json:"dentistIds" db:"dentist_ids"`   // empty means any dentist
+	Weekdays     []int     `json:"weekdays" db:"weekdays"`        // empty means any day, 0 = Sunday
+	EarliestTime string    `json:"earliestTime" db:"earliest_time"`
+	LatestTime   string    `json:"latestTime" db:"latest_time"`
+	Duration     int       `json:"duration" db:"duration_minutes"` // in minutes
+	TreatmentID  *int      `json:"treatmentId" db:"treatment_id"`  // nullable
+	Urgency      string    `json:"urgency" db:"urgency"`
+	Status       string    `json:"status" db:"status"`
+	Notes        string    `json:"notes" db:"notes"`
+	CreatedAt    time.Time `json:"createdAt" db:"created_at"`
+	UpdatedAt    time.Time `json:"updatedAt" db:"updated_at"`
+}
+
+// CreateWaitlistEntryRequest represents the request payload for adding a patient to the waitlist
+type CreateWaitlistEntryRequest struct {
+	PatientID    int    `json:"patientId" binding:"required"`
+	DentistIDs   []int  `json:"dentistIds"`
+	Weekdays     []int  `json:"weekdays" binding:"omitempty,dive,min=0,max=6"`
+	EarliestTime string `json:"earliestTime"`
+	LatestTime   string `json:"latestTime"`
+	Duration     int    `json:"duration" binding:"omitempty,min=1"`
+	TreatmentID  *int   `json:"treatmentId"`
+	Urgency      string `json:"urgency" binding:"omitempty,oneof=low medium high urgent"`
+	Notes        string `json:"notes"`
+}
+
+// UpdateWaitlistEntryRequest represents the request payload for updating a waitlist entry.
+// Preference lists are replaced when present; other fields are applied when non-empty.
+type UpdateWaitlistEntryRequest struct {
+	DentistIDs   *[]int `json:"dentistIds"`
+	Weekdays     *[]int `json:"weekdays" binding:"omitempty,dive,min=0,max=6"`
+	EarliestTime string `json:"earliestTime"`
+	LatestTime   string `json:"latestTime"`
+	Duration     int    `json:"duration" binding:"omitempty,min=1"`
+	Urgency      string `json:"urgency" binding:"omitempty,oneof=low medium high urgent"`
+	Status       string `json:"status" binding:"omitempty,oneof=waiting removed"`
+	Notes        string `json:"notes"`
+}
+
+// SlotOpeningStatus represents the state of a freed slot
+type SlotOpeningStatus string
+
+const (
+	SlotOpeningOpen   SlotOpeningStatus = "open"
+	SlotOpeningHeld   SlotOpeningStatus = "held"
+	SlotOpeningFilled SlotOpeningStatus = "filled"
+)
+
+// SlotOpening is a slot freed by a cancelled or deleted appointment
+type SlotOpening struct {
+	ID              int        `json:"id" db:"id"`
+	DentistID       int        `json:"dentistId" db:"dentist_id"`
+	AppointmentDate string     `json:"appointmentDate" db:"appointment_date"`
+	StartTime       string     `json:"startTime" db:"start_time"`
+	EndTime         string     `json:"endTime" db:"end_time"`
+	AppointmentID   *int       `json:"appointmentId" db:"appointment_id"` // the appointment that freed it, nullable
+	Status          string     `json:"status" db:"status"`
+	HeldEntryID     *int       `json:"heldEntryId" db:"held_entry_id"` // nullable
+	HoldExpiresAt   *time.Time `json:"holdExpiresAt" db:"hold_expires_at"`
+	CreatedAt       time.Time  `json:"createdAt" db:"created_at"`
+	UpdatedAt       time.Time  `json:"updatedAt" db:"updated_at"`
+}
+
+// Window returns the time range of the opening
+func (o SlotOpening) Window() (AppointmentWindow, error) {
+	start, err := ParseAppointmentTime(o.StartTime)
+	if err != nil {
+		return AppointmentWindow{}, err
+	}
+	end, err := ParseAppointmentTime(o.EndTime)
+	if err != nil {
+		return AppointmentWindow{}, err
+	}
+	return AppointmentWindow{Date: o.AppointmentDate, Start: start, End: end}, nil
+}
+
+// IsHeld reports whether the opening is reserved for a waitlist entry at the given time
+func (o SlotOpening) IsHeld(now time.Time) bool {
+	return o.Status == string(SlotOpeningHeld) && o.HoldExpiresAt != nil && o.HoldExpiresAt.After(now)
+}
+
+// WaitlistCandidate is a waitlist entry ranked against a slot opening
+type WaitlistCandidate struct {
+	Entry   WaitlistEntry `json:"entry"`
+	Score   int           `json:"score"`
+	Reasons []string      `json:"reasons"`
+}
+
+// OpeningCandidates is a slot opening with its ranked waitlist candidates
+type OpeningCandidates struct {
+	Opening    SlotOpening         `json:"opening"`
+	Candidates []WaitlistCandidate `json:"candidates"`
+}
+
+// HoldOpeningRequest represents the request payload for holding an opening for a waitlist entry
+type HoldOpeningRequest struct {
+	EntryID int `json:"entryId" binding:"required"`
+}
+
+// BookOpeningRequest represents the request payload for booking a waitlist entry into an opening
+type BookOpeningRequest struct {
+	EntryID int    `json:"entryId" binding:"required"`
+	Notes   string `json:"notes"`
+}
@@ -0,0 +1,34 @@
+// dental_backend/internal/notify/log.go
+package notify
+
+import (
+	"context"
+	"io"
+	"log"
+	"sync"
+)
+
+// LogNotifier writes messages to a log instead of delivering them, for local
+// development and testing
+type LogNotifier struct {
+	mu     sync.Mutex
+	logger *log.Logger
+}
+
+// NewLogNotifier creates a notifier writing to w, or to the standard logger when w is nil
+func NewLogNotifier(w io.Writer) *LogNotifier {
+	logger := log.Default()
+	if w != nil {
+		logger = log.New(w, "", log.LstdFlags)
+	}
+	return &LogNotifier{logger: logger}
+}
+
+// Send records the message
+func (n *LogNotifier) Send(ctx context.Context, msg Message) error {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	n.logger.Printf("[%s] to=%s subject=%q\n%s", msg.Channel, msg.To, msg.Subject, msg.Body)
+	return nil
+}
@@ -0,0 +1,108 @@
+// dental_backend/internal/notify/notify.go
+
+// Package notify delivers messages to patients and users over email and SMS.
+package notify
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+)
+
+// Channel is the medium a message is delivered over
+type Channel string
+
+const (
+	ChannelEmail Channel = "email"
+	ChannelSMS   Channel = "sms"
+)
+
+// Message is a notification addressed to one recipient
+type Message struct {
+	Channel Channel
+	To      string // email address or phone number
+	Subject string // ignored for SMS
+	Body    string
+}
+
+// Notifier delivers messages over one channel
+type Notifier interface {
+	Send(ctx context.Context, msg Message) error
+}
+
+// Notifiers maps each enabled channel to the notifier that delivers it
+type Notifiers map[Channel]Notifier
+
+// Config selects and configures the notifier used for each channel
+type Config struct {
+	// EmailDriver is "smtp", "log" or empty to disable email
+	EmailDriver string
+	// SMSDriver is "webhook", "log" or empty to disable SMS
+	SMSDriver string
+
+	SMTP SMTPConfig
+
+	// SMSWebhookURL receives a JSON POST for every SMS; SMSWebhookToken, when
+	// set, is sent as a bearer token
+	SMSWebhookURL   string
+	SMSWebhookToken string
+
+	// LogFile is where the log driver appends messages; empty writes to the standard logger
+	LogFile string
+}
+
+// New builds the notifiers enabled in cfg. The client is used by the webhook driver.
+func New(cfg Config, client *http.Client) (Notifiers, error) {
+	notifiers := Notifiers{}
+
+	var logNotifier *LogNotifier
+	logDriver := func() (*LogNotifier, error) {
+		if logNotifier != nil {
+			return logNotifier, nil
+		}
+		if cfg.LogFile == "" {
+			logNotifier = NewLogNotifier(nil)
+			return logNotifier, nil
+		}
+		file, err := os.OpenFile(cfg.LogFile, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+		if err != nil {
+			return nil, fmt.Errorf("open notification log: %w", err)
+		}
+		logNotifier = NewLogNotifier(file)
+		return logNotifier, nil
+	}
+
+	switch cfg.EmailDriver {
+	case "":
+	case "smtp":
+		notifiers[ChannelEmail] = NewSMTPNotifier(cfg.SMTP)
+	case "log":
+		n, err := logDriver()
+		if err != nil {
+			return nil, err
+		}
+		notifiers[ChannelEmail] = n
+	default:
+		return nil, fmt.Errorf("unknown email driver %q", cfg.EmailDriver)
+	}
+
+	switch cfg.SMSDriver {
+	case "":
+	case "webhook":
+		if cfg.SMSWebhookURL == "" {
+			return nil, fmt.Errorf("the webhook SMS driver needs SMS_WEBHOOK_URL")
+		}
+		notifiers[ChannelSMS] = NewWebhookNotifier(cfg.SMSWebhookURL, cfg.SMSWebhookToken, client)
+	case "log":
+		n, err := logDriver()
+		if err != nil {
+			return nil, err
+		}
+		notifiers[ChannelSMS] = n
+	default:
+		return nil, fmt.Errorf("unknown SMS driver %q", cfg.SMSDriver)
+	}
+
+	return notifiers, nil
+}
@@ -0,0 +1,59 @@
+// dental_backend/internal/notify/smtp.go
+package notify
+
+import (
+	"context"
+	"fmt"
+	"mime"
+	"net"
+	"net/smtp"
+	"strings"
+	"time"
+)
+
+// SMTPConfig holds the mail server settings used by SMTPNotifier
+type SMTPConfig struct {
+	Host     string
+	Port     string
+	Username string
+	Password string
+	From     string
+}
+
+// SMTPNotifier sends email through an SMTP server
+type SMTPNotifier struct {
+	cfg SMTPConfig
+}
+
+// NewSMTPNotifier creates a notifier that sends mail through the configured server
+func NewSMTPNotifier(cfg SMTPConfig) *SMTPNotifier {
+	return &SMTPNotifier{cfg: cfg}
+}
+
+// Send delivers a plain-text email
+func (n *SMTPNotifier) Send(ctx context.Context, msg Message) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	var auth smtp.Auth
+	if n.cfg.Username != "" {
+		auth = smtp.PlainAuth("", n.cfg.Username, n.cfg.Password, n.cfg.Host)
+	}
+
+	return smtp.SendMail(net.JoinHostPort(n.cfg.Host, n.cfg.Port), auth, n.cfg.From, []string{msg.To}, n.compose(msg))
+}
+
+// compose renders the message headers and body
+func (n *SMTPNotifier) compose(msg Message) []byte {
+	var b strings.Builder
+	fmt.Fprintf(&b, "From: %s\r\n", n.cfg.From)
+	fmt.Fprintf(&b, "To: %s\r\n", msg.To)
+	fmt.Fprintf(&b, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", msg.Subject))
+	fmt.Fprintf(&b, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	b.WriteString("MIME-Version: 1.0\r\n")
+	b.WriteString("Content-Type: text/plain; charset=utf-8\r\n")
+	b.WriteString("\r\n")
+	b.WriteString(strings.ReplaceAll(msg.Body, "\n", "\r\n"))
+	return []byte(b.String())
+}
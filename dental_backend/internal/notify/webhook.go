@@ -0,0 +1,54 @@
+// dental_backend/internal/notify/webhook.go
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+)
+
+// WebhookNotifier sends SMS by posting each message to a generic HTTP gateway
+// as {"to": "...", "message": "..."}
+type WebhookNotifier struct {
+	url    string
+	token  string
+	client *http.Client
+}
+
+// NewWebhookNotifier creates a notifier that posts messages to url
+func NewWebhookNotifier(url, token string, client *http.Client) *WebhookNotifier {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	return &WebhookNotifier{url: url, token: token, client: client}
+}
+
+// Send posts the message to the gateway and fails on any non-2xx response
+func (n *WebhookNotifier) Send(ctx context.Context, msg Message) error {
+	payload, err := json.Marshal(map[string]string{"to": msg.To, "message": msg.Body})
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, n.url, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if n.token != "" {
+		req.Header.Set("Authorization", "Bearer "+n.token)
+	}
+
+	resp, err := n.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("SMS gateway returned %s", resp.Status)
+	}
+	return nil
+}
@@ -0,0 +1,350 @@
+// dental_backend/internal/oidc/oidc.go
+
+// Package oidc signs users in through OpenID Connect providers such as Microsoft
+// Entra ID, Keycloak or Google. Providers are found through their discovery
+// document and used with the authorization code flow and PKCE; the ID token
+// returned for the code is verified with package idtoken.
+package oidc
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+
+	"dental_backend/internal/idtoken"
+)
+
+// ErrRejected is returned when the provider refuses the authorization code or the
+// ID token it returns is not valid for this sign-in
+var ErrRejected = errors.New("sign-in rejected by provider")
+
+// RoleMapping gives users in a provider group a role
+type RoleMapping struct {
+	Group string
+	Role  string
+}
+
+// Config describes one provider
+type Config struct {
+	// Name identifies the provider in URLs and linked identities
+	Name        string
+	DisplayName string
+
+	// Issuer is the issuer URL the discovery document is read from
+	Issuer       string
+	ClientID     string
+	ClientSecret string
+	Scopes       []string
+
+	// RedirectURL is the callback registered with the provider; empty derives it
+	// from the request
+	RedirectURL string
+
+	// EmailClaim and GroupsClaim name the claims holding the email address and groups
+	EmailClaim  string
+	GroupsClaim string
+
+	// RoleMap gives the role for new users from their groups; the first matching
+	// entry wins, then DefaultRole. New users with no role are not signed up.
+	RoleMap     []RoleMapping
+	DefaultRole string
+
+	// TrustEmail treats every email address from the provider as verified, for
+	// providers such as Entra ID that do not send email_verified
+	TrustEmail bool
+
+	// AutoLink links a provider account to the user with the same verified email
+	// address the first time it signs in. It cannot be combined with TrustEmail,
+	// which would let anyone who can set their address at the provider take over
+	// the user; without it users link their accounts once signed in.
+	AutoLink bool
+}
+
+// Validate reports settings that cannot be used together
+func (c Config) Validate() error {
+	if c.AutoLink && c.TrustEmail {
+		return fmt.Errorf("provider %s: auto-linking accounts by email cannot be combined with trusting unverified email addresses", c.Name)
+	}
+	return nil
+}
+
+// Identity is a user as vouched for by a provider
+type Identity struct {
+	Subject       string
+	Email         string
+	EmailVerified bool
+	FirstName     string
+	LastName      string
+	Groups        []string
+	// Role is the role a new account gets, empty when it may not be created
+	Role string
+}
+
+// discovery is the part of a provider's discovery document used for sign-in
+type discovery struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	JWKSURI               string `json:"jwks_uri"`
+}
+
+// Provider is a configured OpenID Connect provider
+type Provider struct {
+	cfg    Config
+	client *http.Client
+
+	// mu guards the discovery document and verifier, loaded on first use
+	mu        sync.Mutex
+	discovery *discovery
+	verifier  *idtoken.Verifier
+}
+
+// NewProvider creates a provider; its discovery document is fetched on first use
+func NewProvider(cfg Config, client *http.Client) *Provider {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	if cfg.EmailClaim == "" {
+		cfg.EmailClaim = "email"
+	}
+	if cfg.GroupsClaim == "" {
+		cfg.GroupsClaim = "groups"
+	}
+	if len(cfg.Scopes) == 0 {
+		cfg.Scopes = []string{"openid", "email", "profile"}
+	}
+	if cfg.DisplayName == "" {
+		cfg.DisplayName = cfg.Name
+	}
+	return &Provider{cfg: cfg, client: client}
+}
+
+// Name returns the provider's name
+func (p *Provider) Name() string {
+	return p.cfg.Name
+}
+
+// DisplayName returns the name shown on the login page
+func (p *Provider) DisplayName() string {
+	return p.cfg.DisplayName
+}
+
+// RedirectURL returns the configured callback, or "" when it is derived from the request
+func (p *Provider) RedirectURL() string {
+	return p.cfg.RedirectURL
+}
+
+// AutoLink reports whether the provider's accounts are linked to users by their
+// verified email address. It never does when email addresses are trusted unverified.
+func (p *Provider) AutoLink() bool {
+	return p.cfg.AutoLink && !p.cfg.TrustEmail
+}
+
+// discover loads and caches the discovery document
+func (p *Provider) discover(ctx context.Context) (*discovery, *idtoken.Verifier, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.discovery != nil {
+		return p.discovery, p.verifier, nil
+	}
+
+	issuer := strings.TrimSuffix(p.cfg.Issuer, "/")
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, issuer+"/.well-known/openid-configuration", nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return nil, nil, fmt.Errorf("fetch %s discovery document: %w", p.cfg.Name, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, nil, fmt.Errorf("fetch %s discovery document: %s", p.cfg.Name, resp.Status)
+	}
+
+	var doc discovery
+	if err := json.NewDecoder(resp.Body).Decode(&doc); err != nil {
+		return nil, nil, fmt.Errorf("decode %s discovery document: %w", p.cfg.Name, err)
+	}
+	if strings.TrimSuffix(doc.Issuer, "/") != issuer {
+		return nil, nil, fmt.Errorf("%s discovery document is for issuer %q", p.cfg.Name, doc.Issuer)
+	}
+	if doc.AuthorizationEndpoint == "" || doc.TokenEndpoint == "" || doc.JWKSURI == "" {
+		return nil, nil, fmt.Errorf("%s discovery document is missing endpoints", p.cfg.Name)
+	}
+
+	p.discovery = &doc
+	p.verifier = idtoken.NewVerifier(idtoken.NewKeySet(doc.JWKSURI, p.client), p.cfg.ClientID, doc.Issuer)
+	return p.discovery, p.verifier, nil
+}
+
+// AuthCodeURL returns the provider URL to send the browser to. state and nonce are
+// checked again at the callback, and challenge is the PKCE challenge of the
+// verifier passed to Exchange.
+func (p *Provider) AuthCodeURL(ctx context.Context, redirectURL, state, nonce, challenge string) (string, error) {
+	doc, _, err := p.discover(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	query := url.Values{
+		"response_type":         {"code"},
+		"client_id":             {p.cfg.ClientID},
+		"redirect_uri":          {redirectURL},
+		"scope":                 {strings.Join(p.cfg.Scopes, " ")},
+		"state":                 {state},
+		"nonce":                 {nonce},
+		"code_challenge":        {challenge},
+		"code_challenge_method": {"S256"},
+	}
+
+	separator := "?"
+	if strings.Contains(doc.AuthorizationEndpoint, "?") {
+		separator = "&"
+	}
+	return doc.AuthorizationEndpoint + separator + query.Encode(), nil
+}
+
+// Exchange redeems an authorization code and returns the identity from its ID
+// token, which must carry nonce
+func (p *Provider) Exchange(ctx context.Context, redirectURL, code, codeVerifier, nonce string) (*Identity, error) {
+	doc, verifier, err := p.discover(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	form := url.Values{
+		"grant_type":    {"authorization_code"},
+		"code":          {code},
+		"redirect_uri":  {redirectURL},
+		"client_id":     {p.cfg.ClientID},
+		"code_verifier": {codeVerifier},
+	}
+	if p.cfg.ClientSecret != "" {
+		form.Set("client_secret", p.cfg.ClientSecret)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, doc.TokenEndpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("redeem %s authorization code: %w", p.cfg.Name, err)
+	}
+	defer resp.Body.Close()
+
+	var result struct {
+		IDToken          string `json:"id_token"`
+		Error            string `json:"error"`
+		ErrorDescription string `json:"error_description"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("decode %s token response: %w", p.cfg.Name, err)
+	}
+	if result.Error != "" {
+		return nil, fmt.Errorf("%w: %s %s", ErrRejected, result.Error, result.ErrorDescription)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("redeem %s authorization code: %s", p.cfg.Name, resp.Status)
+	}
+	if result.IDToken == "" {
+		return nil, fmt.Errorf("%w: no ID token returned", ErrRejected)
+	}
+
+	claims, err := verifier.Verify(ctx, result.IDToken)
+	if err != nil {
+		if errors.Is(err, idtoken.ErrInvalidToken) {
+			return nil, fmt.Errorf("%w: %v", ErrRejected, err)
+		}
+		return nil, err
+	}
+	if tokenNonce, _ := claims.Raw["nonce"].(string); tokenNonce == "" || tokenNonce != nonce {
+		return nil, fmt.Errorf("%w: nonce mismatch", ErrRejected)
+	}
+
+	return p.identity(claims), nil
+}
+
+// identity maps verified claims to an identity
+func (p *Provider) identity(claims *idtoken.Claims) *Identity {
+	identity := &Identity{
+		Subject:       claims.Subject,
+		Email:         claims.Email,
+		EmailVerified: claims.EmailVerified || p.cfg.TrustEmail,
+		FirstName:     claims.GivenName,
+		LastName:      claims.FamilyName,
+		Groups:        stringList(claims.Raw[p.cfg.GroupsClaim]),
+	}
+	// email_verified only vouches for the standard email claim
+	if email, ok := claims.Raw[p.cfg.EmailClaim].(string); ok && email != claims.Email {
+		identity.Email = email
+		identity.EmailVerified = p.cfg.TrustEmail
+	}
+
+	// Fall back to splitting the display name
+	if identity.FirstName == "" && identity.LastName == "" && claims.Name != "" {
+		first, last, _ := strings.Cut(claims.Name, " ")
+		identity.FirstName, identity.LastName = first, last
+	}
+
+	identity.Role = p.role(identity.Groups)
+	return identity
+}
+
+// role returns the role for a user in groups, or "" when none applies
+func (p *Provider) role(groups []string) string {
+	for _, mapping := range p.cfg.RoleMap {
+		for _, group := range groups {
+			if group == mapping.Group {
+				return mapping.Role
+			}
+		}
+	}
+	return p.cfg.DefaultRole
+}
+
+// stringList reads a claim that is a string or a list of strings
+func stringList(claim interface{}) []string {
+	switch value := claim.(type) {
+	case string:
+		return []string{value}
+	case []interface{}:
+		list := make([]string, 0, len(value))
+		for _, item := range value {
+			if s, ok := item.(string); ok {
+				list = append(list, s)
+			}
+		}
+		return list
+	}
+	return nil
+}
+
+// RandomString returns a URL-safe random string for state, nonce and PKCE verifiers
+func RandomString() (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(buf), nil
+}
+
+// Challenge returns the S256 PKCE challenge for a verifier
+func Challenge(verifier string) string {
+	sum := sha256.Sum256([]byte(verifier))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
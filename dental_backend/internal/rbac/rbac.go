@@ -0,0 +1,142 @@
+// dental_backend/internal/rbac/rbac.go
+
+// Package rbac holds the role-based permission matrix. Every authenticated route
+// names the permission it needs; which records within a resource a role may touch
+// (a dentist's own calendar, for example) is still decided by the services.
+package rbac
+
+import "dental_backend/internal/models"
+
+// Resource is a kind of record the API exposes
+type Resource string
+
+const (
+	ResourcePatients          Resource = "patients"
+	ResourceAppointments      Resource = "appointments"
+	ResourceSchedules         Resource = "schedules"
+	ResourceClosures          Resource = "closures"
+	ResourceTreatments        Resource = "treatments"
+	ResourcePatientTreatments Resource = "patient-treatments"
+	ResourceMedicalHistory    Resource = "medical-history"
+	ResourceRiskRules         Resource = "risk-rules"
+	ResourceInvoices          Resource = "invoices"
+	ResourceClaims            Resource = "claims"
+	ResourceWaitlist          Resource = "waitlist"
+	ResourceDashboard         Resource = "dashboard"
+	ResourceToothAnalysis     Resource = "tooth-analysis"
+	ResourceCalendarFeeds     Resource = "calendar-feeds"
+	ResourceUsers             Resource = "users"
+	ResourcePermissions       Resource = "permissions"
+	ResourceAuthPolicy        Resource = "auth-policy"
+	ResourceClinics           Resource = "clinics"
+	ResourceAuditLog          Resource = "audit-log"
+)
+
+// Action is an operation on a resource
+type Action string
+
+const (
+	ActionRead   Action = "read"
+	ActionCreate Action = "create"
+	ActionUpdate Action = "update"
+	ActionDelete Action = "delete"
+)
+
+// Permission is one action on one resource, written "resource:action"
+type Permission struct {
+	Resource Resource
+	Action   Action
+}
+
+func (p Permission) String() string {
+	return string(p.Resource) + ":" + string(p.Action)
+}
+
+// Actions lists what a role may do with each resource
+type Actions map[Resource][]Action
+
+var (
+	readOnly = []Action{ActionRead}
+	crud     = []Action{ActionRead, ActionCreate, ActionUpdate, ActionDelete}
+	noDelete = []Action{ActionRead, ActionCreate, ActionUpdate}
+)
+
+// Matrix is the permission matrix: role → resource → actions. Roles and resources
+// that are not listed get nothing.
+var Matrix = map[models.UserRole]Actions{
+	models.UserRoleAdmin: {
+		ResourcePatients:          crud,
+		ResourceAppointments:      crud,
+		ResourceSchedules:         []Action{ActionRead, ActionUpdate},
+		ResourceClosures:          []Action{ActionRead, ActionCreate, ActionDelete},
+		ResourceTreatments:        crud,
+		ResourcePatientTreatments: crud,
+		ResourceMedicalHistory:    crud,
+		ResourceRiskRules:         []Action{ActionRead, ActionUpdate},
+		ResourceInvoices:          crud,
+		ResourceClaims:            crud,
+		ResourceWaitlist:          crud,
+		ResourceDashboard:         readOnly,
+		ResourceToothAnalysis:     []Action{ActionCreate},
+		ResourceCalendarFeeds:     []Action{ActionRead, ActionCreate, ActionDelete},
+		ResourceUsers:             crud,
+		ResourcePermissions:       readOnly,
+		ResourceAuthPolicy:        []Action{ActionRead, ActionUpdate},
+		ResourceClinics:           noDelete,
+		ResourceAuditLog:          readOnly,
+	},
+	models.UserRoleDentist: {
+		ResourcePatients:          noDelete,
+		ResourceAppointments:      crud,
+		ResourceSchedules:         []Action{ActionRead, ActionUpdate},
+		ResourceClosures:          []Action{ActionRead, ActionCreate, ActionDelete},
+		ResourceTreatments:        readOnly,
+		ResourcePatientTreatments: crud,
+		ResourceMedicalHistory:    crud,
+		ResourceRiskRules:         readOnly,
+		ResourceInvoices:          readOnly,
+		ResourceClaims:            readOnly,
+		ResourceWaitlist:          noDelete,
+		ResourceDashboard:         readOnly,
+		ResourceToothAnalysis:     []Action{ActionCreate},
+		ResourceCalendarFeeds:     []Action{ActionRead, ActionCreate, ActionDelete},
+	},
+	models.UserRoleHygienist: {
+		ResourcePatients:          []Action{ActionRead, ActionUpdate},
+		ResourceAppointments:      noDelete,
+		ResourceSchedules:         readOnly,
+		ResourceClosures:          readOnly,
+		ResourceTreatments:        readOnly,
+		ResourcePatientTreatments: noDelete,
+		ResourceMedicalHistory:    noDelete,
+		ResourceRiskRules:         readOnly,
+		ResourceWaitlist:          readOnly,
+		ResourceDashboard:         readOnly,
+		ResourceToothAnalysis:     []Action{ActionCreate},
+		ResourceCalendarFeeds:     []Action{ActionRead, ActionCreate, ActionDelete},
+	},
+	models.UserRoleStaff: {
+		ResourcePatients:          noDelete,
+		ResourceAppointments:      crud,
+		ResourceSchedules:         readOnly,
+		ResourceClosures:          readOnly,
+		ResourceTreatments:        readOnly,
+		ResourcePatientTreatments: readOnly,
+		ResourceMedicalHistory:    readOnly,
+		ResourceInvoices:          noDelete,
+		ResourceClaims:            noDelete,
+		ResourceWaitlist:          crud,
+		ResourceDashboard:         readOnly,
+		ResourceCalendarFeeds:     []Action{ActionRead, ActionCreate, ActionDelete},
+	},
+}
+
+// Allowed reports whether a role holds a permission
+func Allowed(role models.UserRole, permission Permission) bool {
+	for _, action := range Matrix[role][permission.Resource] {
+		if action == permission.Action {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,77 @@
+// dental_backend/internal/repository/context.go
+package repository
+
+import "context"
+
+type actorKey struct{}
+
+type patientActorKey struct{}
+
+type clinicKey struct{}
+
+type allClinicsKey struct{}
+
+// Actor types recorded against status changes
+const (
+	ActorUser    = "user"
+	ActorPatient = "patient"
+	ActorSystem  = "system"
+)
+
+// WithActor returns a context recording the user responsible for the writes made with it
+func WithActor(ctx context.Context, userID int) context.Context {
+	return context.WithValue(ctx, actorKey{}, userID)
+}
+
+// WithPatientActor returns a context recording that the writes made with it were
+// requested by the patient themselves, through a self-service link
+func WithPatientActor(ctx context.Context) context.Context {
+	return context.WithValue(ctx, patientActorKey{}, true)
+}
+
+// ActorID returns the user recorded by WithActor, or nil for system and anonymous writes
+func ActorID(ctx context.Context) *int {
+	userID, ok := ctx.Value(actorKey{}).(int)
+	if !ok {
+		return nil
+	}
+	return &userID
+}
+
+// ActorType reports who is responsible for the writes made with ctx: a logged-in
+// user, the patient, or the system when neither is recorded
+func ActorType(ctx context.Context) string {
+	if ActorID(ctx) != nil {
+		return ActorUser
+	}
+	if patient, _ := ctx.Value(patientActorKey{}).(bool); patient {
+		return ActorPatient
+	}
+	return ActorSystem
+}
+
+// WithClinic returns a context limiting the reads and writes made with it to one
+// clinic's records
+func WithClinic(ctx context.Context, clinicID int) context.Context {
+	return context.WithValue(ctx, clinicKey{}, clinicID)
+}
+
+// ClinicID returns the clinic recorded by WithClinic. Without one no clinic's
+// records are visible, unless the context was opened with WithAllClinics.
+func ClinicID(ctx context.Context) (int, bool) {
+	clinicID, ok := ctx.Value(clinicKey{}).(int)
+	return clinicID, ok
+}
+
+// WithAllClinics returns a context that sees every clinic's records, for work
+// done on behalf of no particular clinic: the reminder worker, patient
+// self-service links and calendar feeds
+func WithAllClinics(ctx context.Context) context.Context {
+	return context.WithValue(ctx, allClinicsKey{}, true)
+}
+
+// AllClinics reports whether ctx was opened with WithAllClinics
+func AllClinics(ctx context.Context) bool {
+	all, _ := ctx.Value(allClinicsKey{}).(bool)
+	return all
+}
@@ -0,0 +1,54 @@
+// dental_backend/internal/repository/memory/account_token_repository.go
+package memory
+
+import (
+	"context"
+
+	"dental_backend/internal/models"
+)
+
+// AccountTokenRepository is the in-memory implementation of repository.AccountTokenRepository
+type AccountTokenRepository struct {
+	store *Store
+}
+
+// NewAccountTokenRepository creates an account token repository backed by the store
+func NewAccountTokenRepository(store *Store) *AccountTokenRepository {
+	return &AccountTokenRepository{store: store}
+}
+
+// Create stores a token, superseding the user's unused tokens for the same purpose
+func (r *AccountTokenRepository) Create(ctx context.Context, token models.AccountToken, tokenHash string) (*models.AccountToken, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	now := r.store.Now()
+	for hash, t := range r.store.accountTokens {
+		if t.UserID == token.UserID && t.Purpose == token.Purpose && t.UsedAt == nil {
+			t.UsedAt = &now
+			r.store.accountTokens[hash] = t
+		}
+	}
+
+	token.ID = r.store.newID()
+	token.UsedAt = nil
+	token.CreatedAt = now
+	r.store.accountTokens[tokenHash] = token
+	return &token, nil
+}
+
+// Consume marks an unused, unexpired token as used
+func (r *AccountTokenRepository) Consume(ctx context.Context, purpose models.AccountTokenPurpose, tokenHash string) (*models.AccountToken, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	now := r.store.Now()
+	token, ok := r.store.accountTokens[tokenHash]
+	if !ok || token.Purpose != purpose || token.UsedAt != nil || !token.ExpiresAt.After(now) {
+		return nil, nil
+	}
+
+	token.UsedAt = &now
+	r.store.accountTokens[tokenHash] = token
+	return &token, nil
+}
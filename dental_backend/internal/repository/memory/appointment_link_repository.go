@@ -0,0 +1,49 @@
+// dental_backend/internal/repository/memory/appointment_link_repository.go
+package memory
+
+import (
+	"context"
+
+	"dental_backend/internal/models"
+)
+
+// AppointmentLinkRepository is the in-memory implementation of repository.AppointmentLinkRepository
+type AppointmentLinkRepository struct {
+	store *Store
+}
+
+// NewAppointmentLinkRepository creates an appointment link repository backed by the store
+func NewAppointmentLinkRepository(store *Store) *AppointmentLinkRepository {
+	return &AppointmentLinkRepository{store: store}
+}
+
+// Use records a token as spent
+func (r *AppointmentLinkRepository) Use(ctx context.Context, use models.AppointmentLinkUse) (bool, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if _, ok := r.store.linkUses[use.TokenID]; ok {
+		return false, nil
+	}
+	use.UsedAt = r.store.Now()
+	r.store.linkUses[use.TokenID] = use
+	return true, nil
+}
+
+// Used reports whether a token has been spent
+func (r *AppointmentLinkRepository) Used(ctx context.Context, tokenID string) (bool, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	_, ok := r.store.linkUses[tokenID]
+	return ok, nil
+}
+
+// Release forgets a spent token
+func (r *AppointmentLinkRepository) Release(ctx context.Context, tokenID string) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	delete(r.store.linkUses, tokenID)
+	return nil
+}
@@ -0,0 +1,278 @@
+// dental_backend/internal/repository/memory/appointment_repository.go
+package memory
+
+import (
+	"context"
+	"database/sql"
+	"sort"
+	"time"
+
+	"dental_backend/internal/models"
+	"dental_backend/internal/repository"
+)
+
+// AppointmentRepository is the in-memory implementation of repository.AppointmentRepository
+type AppointmentRepository struct {
+	store *Store
+}
+
+// NewAppointmentRepository creates an appointment repository backed by the store
+func NewAppointmentRepository(store *Store) *AppointmentRepository {
+	return &AppointmentRepository{store: store}
+}
+
+// withPatientName returns a copy of the appointment with its joined patient name
+// and its clinic
+func (r *AppointmentRepository) withPatientName(a models.Appointment) models.Appointment {
+	a.PatientName = r.store.patientName(a.PatientID)
+	a.ClinicID = r.store.rowClinics[a.ID]
+	return a
+}
+
+// withEndTime derives the stored end time from the start time and duration
+func withEndTime(a models.Appointment) models.Appointment {
+	if window, err := a.Window(); err == nil {
+		a.EndTime = models.FormatClock(window.End)
+	}
+	return a
+}
+
+// stampStatus moves the appointment to status and sets the matching transition timestamp
+func stampStatus(a *models.Appointment, status string, reason string, now time.Time) {
+	a.Status = status
+	switch models.AppointmentStatus(status) {
+	case models.AppointmentStatusConfirmed:
+		a.ConfirmedAt = &now
+	case models.AppointmentStatusCheckedIn:
+		a.CheckedInAt = &now
+	case models.AppointmentStatusInChair:
+		a.InChairAt = &now
+	case models.AppointmentStatusCompleted:
+		a.CompletedAt = &now
+	case models.AppointmentStatusCancelled:
+		a.CancelledAt = &now
+		a.CancellationReason = reason
+	case models.AppointmentStatusNoShow:
+		a.NoShowAt = &now
+	}
+}
+
+// overlapping returns active appointments of the dentist intersecting window; callers must hold a lock
+func (r *AppointmentRepository) overlapping(dentistID int, window models.AppointmentWindow, excludeID int) []models.Appointment {
+	var appointments []models.Appointment
+	for _, a := range r.store.appointments {
+		if a.DentistID != dentistID || a.ID == excludeID || !a.IsActive() {
+			continue
+		}
+		other, err := a.Window()
+		if err != nil || !window.Overlaps(other) {
+			continue
+		}
+		appointments = append(appointments, r.withPatientName(a))
+	}
+
+	sort.Slice(appointments, func(i, j int) bool {
+		return appointments[i].StartTime < appointments[j].StartTime
+	})
+	return appointments
+}
+
+// conflicts reports whether an active appointment would double-book its dentist; callers must hold a lock
+func (r *AppointmentRepository) conflicts(a models.Appointment) bool {
+	if !a.IsActive() {
+		return false
+	}
+	window, err := a.Window()
+	if err != nil {
+		return false
+	}
+	return len(r.overlapping(a.DentistID, window, a.ID)) > 0
+}
+
+// List returns the appointments matching the filter ordered by date and time
+func (r *AppointmentRepository) List(ctx context.Context, filter repository.AppointmentFilter) ([]models.Appointment, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	var appointments []models.Appointment
+	for _, a := range r.store.appointments {
+		if !r.store.visible(ctx, a.ID) {
+			continue
+		}
+		if filter.DentistID != nil && a.DentistID != *filter.DentistID {
+			continue
+		}
+		if filter.Date != nil && a.AppointmentDate != *filter.Date {
+			continue
+		}
+		if filter.From != nil && a.AppointmentDate < *filter.From {
+			continue
+		}
+		if filter.To != nil && a.AppointmentDate > *filter.To {
+			continue
+		}
+		if filter.Status != nil && a.Status != *filter.Status {
+			continue
+		}
+		if filter.PatientID != nil && a.PatientID != *filter.PatientID {
+			continue
+		}
+		if filter.SeriesID != nil && (a.SeriesID == nil || *a.SeriesID != *filter.SeriesID) {
+			continue
+		}
+		appointments = append(appointments, r.withPatientName(a))
+	}
+
+	sort.Slice(appointments, func(i, j int) bool {
+		if appointments[i].AppointmentDate != appointments[j].AppointmentDate {
+			return appointments[i].AppointmentDate < appointments[j].AppointmentDate
+		}
+		return appointments[i].StartTime < appointments[j].StartTime
+	})
+
+	return appointments, nil
+}
+
+// GetByID returns an appointment, restricted to a dentist when dentistID is set, or nil
+func (r *AppointmentRepository) GetByID(ctx context.Context, id int, dentistID *int) (*models.Appointment, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	a, ok := r.store.appointments[id]
+	if !ok || !r.store.visible(ctx, id) || (dentistID != nil && a.DentistID != *dentistID) {
+		return nil, nil
+	}
+	a = r.withPatientName(a)
+	return &a, nil
+}
+
+// Create stores a new appointment for the dentist
+func (r *AppointmentRepository) Create(ctx context.Context, req models.CreateAppointmentRequest, dentistID int) (*models.Appointment, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	now := r.store.Now()
+	a := models.Appointment{
+		ID:              r.store.newID(),
+		PatientID:       req.PatientID,
+		DentistID:       dentistID,
+		AppointmentDate: req.AppointmentDate,
+		StartTime:       req.StartTime,
+		Duration:        req.Duration,
+		TreatmentID:     req.TreatmentID,
+		SeriesID:        req.SeriesID,
+		Notes:           req.Notes,
+		CreatedAt:       now,
+		UpdatedAt:       now,
+	}
+	stampStatus(&a, req.Status, "", now)
+	if a.Duration == 0 {
+		a.Duration = models.DefaultAppointmentDuration
+	}
+	a = withEndTime(a)
+	if r.conflicts(a) {
+		return nil, repository.ErrOverlap
+	}
+	r.store.appointments[a.ID] = a
+	r.store.claim(ctx, a.ID, a.PatientID)
+	r.store.recordEvent(ctx, a.ID, "", a.Status, "")
+
+	a = r.withPatientName(a)
+	return &a, nil
+}
+
+// Update applies the non-empty fields of req to an appointment owned by the dentist
+func (r *AppointmentRepository) Update(ctx context.Context, id int, req models.UpdateAppointmentRequest, dentistID int) (*models.Appointment, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	a, ok := r.store.appointments[id]
+	if !ok || !r.store.visible(ctx, id) || a.DentistID != dentistID {
+		return nil, nil
+	}
+	if req.FromStatus != "" && req.FromStatus != a.Status {
+		return nil, repository.ErrStaleStatus
+	}
+	fromStatus := a.Status
+	fromWindow, _ := a.Window()
+	now := r.store.Now()
+
+	if req.PatientID != 0 {
+		a.PatientID = req.PatientID
+	}
+	setIfNotEmpty(&a.AppointmentDate, req.AppointmentDate)
+	setIfNotEmpty(&a.StartTime, req.StartTime)
+	if req.Duration != 0 {
+		a.Duration = req.Duration
+	}
+	if req.TreatmentID != nil {
+		a.TreatmentID = req.TreatmentID
+	}
+	statusChanged := req.Status != "" && req.Status != fromStatus
+	if statusChanged {
+		stampStatus(&a, req.Status, req.CancellationReason, now)
+	}
+	setIfNotEmpty(&a.Notes, req.Notes)
+	a.UpdatedAt = now
+
+	// Calendar clients need a new revision when the time changes or the visit is cancelled
+	if window, _ := a.Window(); window != fromWindow ||
+		(statusChanged && a.Status == string(models.AppointmentStatusCancelled)) {
+		a.Sequence++
+	}
+
+	a = withEndTime(a)
+	if r.conflicts(a) {
+		return nil, repository.ErrOverlap
+	}
+	r.store.appointments[id] = a
+	if statusChanged {
+		r.store.recordEvent(ctx, id, fromStatus, a.Status, req.CancellationReason)
+	}
+
+	a = r.withPatientName(a)
+	return &a, nil
+}
+
+// Overlapping returns the dentist's active appointments that intersect the window
+func (r *AppointmentRepository) Overlapping(ctx context.Context, dentistID int, window models.AppointmentWindow, excludeID int) ([]models.Appointment, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	// Double-booking checks see every clinic, as the exclusion constraint does;
+	// callers only see their own clinic's appointments
+	var appointments []models.Appointment
+	for _, a := range r.overlapping(dentistID, window, excludeID) {
+		if r.store.visible(ctx, a.ID) {
+			appointments = append(appointments, a)
+		}
+	}
+	return appointments, nil
+}
+
+// Delete removes an appointment owned by the dentist
+func (r *AppointmentRepository) Delete(ctx context.Context, id int, dentistID int) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	a, ok := r.store.appointments[id]
+	if !ok || !r.store.visible(ctx, id) || a.DentistID != dentistID {
+		return sql.ErrNoRows
+	}
+	r.store.deleteAppointment(id)
+	return nil
+}
+
+// Events returns the status history of an appointment, oldest first
+func (r *AppointmentRepository) Events(ctx context.Context, appointmentID int) ([]models.AppointmentEvent, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	var events []models.AppointmentEvent
+	for _, e := range r.store.appointmentEvents {
+		if e.AppointmentID == appointmentID {
+			events = append(events, e)
+		}
+	}
+	return events, nil
+}
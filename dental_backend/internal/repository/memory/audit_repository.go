@@ -0,0 +1,92 @@
+// dental_backend/internal/repository/memory/audit_repository.go
+package memory
+
+import (
+	"context"
+
+	"dental_backend/internal/audit"
+	"dental_backend/internal/models"
+	"dental_backend/internal/repository"
+)
+
+// AuditRepository is the in-memory implementation of repository.AuditRepository
+type AuditRepository struct {
+	store *Store
+}
+
+// NewAuditRepository creates an audit repository backed by the store
+func NewAuditRepository(store *Store) *AuditRepository {
+	return &AuditRepository{store: store}
+}
+
+// Append seals entry onto the end of the chain
+func (r *AuditRepository) Append(ctx context.Context, entry models.AuditEntry) (*models.AuditEntry, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	entry.PrevHash = audit.GenesisHash
+	if n := len(r.store.auditLog); n > 0 {
+		entry.PrevHash = r.store.auditLog[n-1].Hash
+	}
+	entry.Hash = audit.Hash(entry)
+	entry.ID = int64(r.store.newID())
+
+	r.store.auditLog = append(r.store.auditLog, entry)
+	return &entry, nil
+}
+
+// List returns matching entries, newest first
+func (r *AuditRepository) List(ctx context.Context, filter repository.AuditFilter) ([]models.AuditEntry, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	var entries []models.AuditEntry
+	skipped := 0
+	for i := len(r.store.auditLog) - 1; i >= 0 && len(entries) < filter.Limit; i-- {
+		e := r.store.auditLog[i]
+		if !auditMatches(e, filter) {
+			continue
+		}
+		if skipped < filter.Offset {
+			skipped++
+			continue
+		}
+		entries = append(entries, e)
+	}
+	return entries, nil
+}
+
+// auditMatches reports whether an entry passes the filter
+func auditMatches(e models.AuditEntry, filter repository.AuditFilter) bool {
+	switch {
+	case filter.ClinicID != nil && (e.ClinicID == nil || *e.ClinicID != *filter.ClinicID):
+		return false
+	case filter.ActorID != nil && (e.ActorID == nil || *e.ActorID != *filter.ActorID):
+		return false
+	case filter.Action != "" && e.Action != filter.Action:
+		return false
+	case filter.ResourceType != "" && e.ResourceType != filter.ResourceType:
+		return false
+	case filter.ResourceID != "" && e.ResourceID != filter.ResourceID:
+		return false
+	case filter.From != nil && e.OccurredAt.Before(*filter.From):
+		return false
+	case filter.To != nil && !e.OccurredAt.Before(*filter.To):
+		return false
+	}
+	return true
+}
+
+// Chain returns up to limit entries after afterID in chain order
+func (r *AuditRepository) Chain(ctx context.Context, afterID int64, limit int) ([]models.AuditEntry, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	var entries []models.AuditEntry
+	for _, e := range r.store.auditLog {
+		if e.ID > afterID && len(entries) < limit {
+			entries = append(entries, e)
+		}
+	}
+	return entries, nil
+}
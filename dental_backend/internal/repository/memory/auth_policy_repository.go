@@ -0,0 +1,50 @@
+// dental_backend/internal/repository/memory/auth_policy_repository.go
+package memory
+
+import (
+	"context"
+
+	"dental_backend/internal/models"
+	"dental_backend/internal/repository"
+)
+
+// AuthPolicyRepository is the in-memory implementation of repository.AuthPolicyRepository
+type AuthPolicyRepository struct {
+	store *Store
+}
+
+// NewAuthPolicyRepository creates an auth policy repository backed by the store
+func NewAuthPolicyRepository(store *Store) *AuthPolicyRepository {
+	return &AuthPolicyRepository{store: store}
+}
+
+// Get returns the sign-in policy of the clinic of ctx, or the defaults
+func (r *AuthPolicyRepository) Get(ctx context.Context) (*models.AuthPolicy, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	clinicID, _ := repository.ClinicID(ctx)
+	policy := r.store.authPolicies[clinicID]
+	policy.RequireMFARoles = append([]string{}, policy.RequireMFARoles...)
+	return &policy, nil
+}
+
+// Update applies the non-nil fields of req to the policy of the clinic of ctx
+func (r *AuthPolicyRepository) Update(ctx context.Context, req models.UpdateAuthPolicyRequest) (*models.AuthPolicy, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	clinicID, _ := repository.ClinicID(ctx)
+	policy := r.store.authPolicies[clinicID]
+	if req.RequireEmailVerification != nil {
+		policy.RequireEmailVerification = *req.RequireEmailVerification
+	}
+	if req.RequireMFARoles != nil {
+		policy.RequireMFARoles = append([]string{}, *req.RequireMFARoles...)
+	}
+	policy.UpdatedAt = r.store.Now()
+
+	r.store.authPolicies[clinicID] = policy
+	policy.RequireMFARoles = append([]string{}, policy.RequireMFARoles...)
+	return &policy, nil
+}
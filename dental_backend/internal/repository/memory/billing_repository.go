@@ -0,0 +1,300 @@
+// dental_backend/internal/repository/memory/billing_repository.go
+package memory
+
+import (
+	"context"
+	"database/sql"
+	"sort"
+	"strconv"
+
+	"dental_backend/internal/models"
+)
+
+// BillingRepository is the in-memory implementation of repository.BillingRepository
+type BillingRepository struct {
+	store *Store
+}
+
+// NewBillingRepository creates a billing repository backed by the store
+func NewBillingRepository(store *Store) *BillingRepository {
+	return &BillingRepository{store: store}
+}
+
+// Stats computes the dashboard billing statistics
+func (r *BillingRepository) Stats(ctx context.Context) (*models.BillingStats, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	currentMonth := r.store.today()[:7]
+
+	var stats models.BillingStats
+	for _, i := range r.store.invoices {
+		if !r.store.visible(ctx, i.ID) {
+			continue
+		}
+		switch i.Status {
+		case "paid":
+			stats.Collections += i.Amount
+			if len(i.IssuedDate) >= 7 && i.IssuedDate[:7] == currentMonth {
+				stats.MonthlyRevenue += i.Amount
+			}
+		case "pending":
+			stats.PendingPayments += i.Amount
+		}
+	}
+	for _, c := range r.store.claims {
+		if c.Status == "submitted" && r.store.visible(ctx, c.ID) {
+			stats.InsuranceClaims += c.ClaimAmount
+		}
+	}
+
+	return &stats, nil
+}
+
+// matchesPatient reports whether a record belongs to the patient ID filter
+func matchesPatient(patientID int, filter string) bool {
+	return filter == "" || strconv.Itoa(patientID) == filter
+}
+
+// ListInvoices returns invoices newest first with optional filtering
+func (r *BillingRepository) ListInvoices(ctx context.Context, status, patientID string) ([]models.Invoice, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	var invoices []models.Invoice
+	for _, i := range r.store.invoices {
+		if !r.store.visible(ctx, i.ID) {
+			continue
+		}
+		if status != "" && i.Status != status {
+			continue
+		}
+		if !matchesPatient(i.PatientID, patientID) {
+			continue
+		}
+		invoices = append(invoices, r.invoiceWithJoins(i))
+	}
+
+	sort.Slice(invoices, func(a, b int) bool { return invoices[a].CreatedAt.After(invoices[b].CreatedAt) })
+
+	return invoices, nil
+}
+
+// invoiceWithJoins fills in the joined patient name; callers must hold a lock
+func (r *BillingRepository) invoiceWithJoins(i models.Invoice) models.Invoice {
+	i.PatientName = r.store.patientName(i.PatientID)
+	if i.PatientName == "" {
+		i.PatientName = "Unknown Patient"
+	}
+	return i
+}
+
+// GetInvoice returns an invoice or nil
+func (r *BillingRepository) GetInvoice(ctx context.Context, id int) (*models.Invoice, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	i, ok := r.store.invoices[id]
+	if !ok || !r.store.visible(ctx, id) {
+		return nil, nil
+	}
+	i = r.invoiceWithJoins(i)
+	return &i, nil
+}
+
+// CreateInvoice stores a new invoice
+func (r *BillingRepository) CreateInvoice(ctx context.Context, req models.CreateInvoiceRequest) (*models.Invoice, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	now := r.store.Now()
+	i := models.Invoice{
+		ID:            r.store.newID(),
+		PatientID:     req.PatientID,
+		Amount:        req.Amount,
+		Status:        req.Status,
+		DueDate:       req.DueDate,
+		IssuedDate:    req.IssuedDate,
+		PaymentMethod: req.PaymentMethod,
+		Notes:         req.Notes,
+		CreatedAt:     now,
+		UpdatedAt:     now,
+	}
+	if i.IssuedDate == "" {
+		i.IssuedDate = r.store.today()
+	}
+	r.store.invoices[i.ID] = i
+	r.store.claim(ctx, i.ID, i.PatientID)
+
+	i = r.invoiceWithJoins(i)
+	return &i, nil
+}
+
+// UpdateInvoice applies the non-zero fields of req
+func (r *BillingRepository) UpdateInvoice(ctx context.Context, id int, req models.UpdateInvoiceRequest) (*models.Invoice, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	i, ok := r.store.invoices[id]
+	if !ok || !r.store.visible(ctx, id) {
+		return nil, sql.ErrNoRows
+	}
+
+	if req.PatientID != 0 {
+		i.PatientID = req.PatientID
+	}
+	if req.Amount != 0 {
+		i.Amount = req.Amount
+	}
+	setIfNotEmpty(&i.Status, req.Status)
+	setIfNotEmpty(&i.DueDate, req.DueDate)
+	setIfNotEmpty(&i.IssuedDate, req.IssuedDate)
+	setIfNotEmpty(&i.PaymentMethod, req.PaymentMethod)
+	setIfNotEmpty(&i.Notes, req.Notes)
+	i.UpdatedAt = r.store.Now()
+
+	r.store.invoices[id] = i
+
+	i = r.invoiceWithJoins(i)
+	return &i, nil
+}
+
+// DeleteInvoice removes an invoice
+func (r *BillingRepository) DeleteInvoice(ctx context.Context, id int) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if _, ok := r.store.invoices[id]; !ok || !r.store.visible(ctx, id) {
+		return sql.ErrNoRows
+	}
+	delete(r.store.invoices, id)
+	return nil
+}
+
+// claimWithJoins fills in the joined patient and treatment names; callers must hold a lock
+func (r *BillingRepository) claimWithJoins(c models.InsuranceClaim) models.InsuranceClaim {
+	c.PatientName = r.store.patientName(c.PatientID)
+	c.TreatmentName = nil
+	if c.TreatmentID != nil {
+		if t, ok := r.store.treatments[*c.TreatmentID]; ok {
+			name := t.Name
+			c.TreatmentName = &name
+		}
+	}
+	return c
+}
+
+// ListClaims returns insurance claims newest first with optional filtering
+func (r *BillingRepository) ListClaims(ctx context.Context, status, patientID string) ([]models.InsuranceClaim, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	var claims []models.InsuranceClaim
+	for _, c := range r.store.claims {
+		if !r.store.visible(ctx, c.ID) {
+			continue
+		}
+		if status != "" && c.Status != status {
+			continue
+		}
+		if !matchesPatient(c.PatientID, patientID) {
+			continue
+		}
+		claims = append(claims, r.claimWithJoins(c))
+	}
+
+	sort.Slice(claims, func(a, b int) bool { return claims[a].CreatedAt.After(claims[b].CreatedAt) })
+
+	return claims, nil
+}
+
+// GetClaim returns an insurance claim or nil
+func (r *BillingRepository) GetClaim(ctx context.Context, id int) (*models.InsuranceClaim, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	c, ok := r.store.claims[id]
+	if !ok || !r.store.visible(ctx, id) {
+		return nil, nil
+	}
+	c = r.claimWithJoins(c)
+	return &c, nil
+}
+
+// CreateClaim stores a new insurance claim
+func (r *BillingRepository) CreateClaim(ctx context.Context, req models.CreateInsuranceClaimRequest) (*models.InsuranceClaim, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	now := r.store.Now()
+	c := models.InsuranceClaim{
+		ID:             r.store.newID(),
+		PatientID:      req.PatientID,
+		TreatmentID:    req.TreatmentID,
+		ClaimAmount:    req.ClaimAmount,
+		Status:         req.Status,
+		SubmissionDate: req.SubmissionDate,
+		ApprovalDate:   req.ApprovalDate,
+		Notes:          req.Notes,
+		CreatedAt:      now,
+		UpdatedAt:      now,
+	}
+	if c.SubmissionDate == "" {
+		c.SubmissionDate = r.store.today()
+	}
+	r.store.claims[c.ID] = c
+	r.store.claim(ctx, c.ID, c.PatientID)
+
+	c = r.claimWithJoins(c)
+	return &c, nil
+}
+
+// UpdateClaim applies req using the same replace semantics as the SQL implementation:
+// a missing treatment or approval date clears the stored value
+func (r *BillingRepository) UpdateClaim(ctx context.Context, id int, req models.UpdateInsuranceClaimRequest) (*models.InsuranceClaim, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	c, ok := r.store.claims[id]
+	if !ok || !r.store.visible(ctx, id) {
+		return nil, sql.ErrNoRows
+	}
+
+	if req.PatientID != 0 {
+		c.PatientID = req.PatientID
+	}
+	c.TreatmentID = nil
+	if req.TreatmentID != nil && *req.TreatmentID != 0 {
+		treatmentID := *req.TreatmentID
+		c.TreatmentID = &treatmentID
+	}
+	if req.ClaimAmount != 0 {
+		c.ClaimAmount = req.ClaimAmount
+	}
+	setIfNotEmpty(&c.Status, req.Status)
+	setIfNotEmpty(&c.SubmissionDate, req.SubmissionDate)
+	c.ApprovalDate = nil
+	if req.ApprovalDate != nil && *req.ApprovalDate != "" {
+		approvalDate := *req.ApprovalDate
+		c.ApprovalDate = &approvalDate
+	}
+	setIfNotEmpty(&c.Notes, req.Notes)
+	c.UpdatedAt = r.store.Now()
+
+	r.store.claims[id] = c
+
+	c = r.claimWithJoins(c)
+	return &c, nil
+}
+
+// DeleteClaim removes an insurance claim
+func (r *BillingRepository) DeleteClaim(ctx context.Context, id int) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if _, ok := r.store.claims[id]; !ok || !r.store.visible(ctx, id) {
+		return sql.ErrNoRows
+	}
+	delete(r.store.claims, id)
+	return nil
+}
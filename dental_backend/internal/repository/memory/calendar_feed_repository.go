@@ -0,0 +1,106 @@
+// dental_backend/internal/repository/memory/calendar_feed_repository.go
+package memory
+
+import (
+	"context"
+	"database/sql"
+	"sort"
+
+	"dental_backend/internal/models"
+)
+
+// CalendarFeedRepository is the in-memory implementation of repository.CalendarFeedRepository
+type CalendarFeedRepository struct {
+	store *Store
+}
+
+// NewCalendarFeedRepository creates a calendar feed repository backed by the store
+func NewCalendarFeedRepository(store *Store) *CalendarFeedRepository {
+	return &CalendarFeedRepository{store: store}
+}
+
+// Create stores a new calendar feed at the clinic of ctx
+func (r *CalendarFeedRepository) Create(ctx context.Context, feed models.CalendarFeed, tokenHash string) (*models.CalendarFeed, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	f := models.CalendarFeed{
+		ID:        r.store.newID(),
+		UserID:    feed.UserID,
+		Type:      feed.Type,
+		SubjectID: feed.SubjectID,
+		CreatedAt: r.store.Now(),
+	}
+	r.store.claim(ctx, f.ID, 0)
+	f.ClinicID = r.store.rowClinics[f.ID]
+	r.store.calendarFeeds[f.ID] = calendarFeedRow{feed: f, tokenHash: tokenHash}
+	return &f, nil
+}
+
+// FindActive returns the unrevoked feed with the token hash, with its owner's role
+// at the feed's clinic, and stamps its last use
+func (r *CalendarFeedRepository) FindActive(ctx context.Context, tokenHash string) (*models.CalendarFeed, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	for id, row := range r.store.calendarFeeds {
+		if row.tokenHash != tokenHash || row.feed.RevokedAt != nil || !r.store.visible(ctx, id) {
+			continue
+		}
+		user, ok := r.store.users[row.feed.UserID]
+		if !ok || user.DeactivatedAt != nil {
+			return nil, nil
+		}
+		membership, ok := r.store.memberships[membershipKey{clinicID: row.feed.ClinicID, userID: user.ID}]
+		if !ok {
+			return nil, nil
+		}
+
+		now := r.store.Now()
+		row.feed.LastUsedAt = &now
+		r.store.calendarFeeds[id] = row
+
+		f := row.feed
+		f.UserRole = membership.role
+		return &f, nil
+	}
+	return nil, nil
+}
+
+// ListForUser returns a user's feeds at the clinic of ctx, newest first
+func (r *CalendarFeedRepository) ListForUser(ctx context.Context, userID int) ([]models.CalendarFeed, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	var feeds []models.CalendarFeed
+	for id, row := range r.store.calendarFeeds {
+		if row.feed.UserID == userID && r.store.visible(ctx, id) {
+			feeds = append(feeds, row.feed)
+		}
+	}
+
+	sort.Slice(feeds, func(i, j int) bool {
+		if feeds[i].CreatedAt.Equal(feeds[j].CreatedAt) {
+			return feeds[i].ID > feeds[j].ID
+		}
+		return feeds[i].CreatedAt.After(feeds[j].CreatedAt)
+	})
+
+	return feeds, nil
+}
+
+// Revoke revokes an active feed at the clinic of ctx, restricted to its owner when userID is set
+func (r *CalendarFeedRepository) Revoke(ctx context.Context, id int, userID *int) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	row, ok := r.store.calendarFeeds[id]
+	if !ok || row.feed.RevokedAt != nil || !r.store.visible(ctx, id) || (userID != nil && row.feed.UserID != *userID) {
+		return sql.ErrNoRows
+	}
+
+	now := r.store.Now()
+	row.feed.RevokedAt = &now
+	r.store.calendarFeeds[id] = row
+	return nil
+}
@@ -0,0 +1,210 @@
+// dental_backend/internal/repository/memory/clinic_repository.go
+package memory
+
+import (
+	"context"
+	"database/sql"
+	"sort"
+
+	"dental_backend/internal/models"
+)
+
+// ClinicRepository is the in-memory implementation of repository.ClinicRepository
+type ClinicRepository struct {
+	store *Store
+}
+
+// NewClinicRepository creates a clinic repository backed by the store
+func NewClinicRepository(store *Store) *ClinicRepository {
+	return &ClinicRepository{store: store}
+}
+
+// List returns every clinic ordered by name
+func (r *ClinicRepository) List(ctx context.Context) ([]models.Clinic, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	var clinics []models.Clinic
+	for _, c := range r.store.clinics {
+		clinics = append(clinics, c)
+	}
+	sort.Slice(clinics, func(i, j int) bool {
+		if clinics[i].Name == clinics[j].Name {
+			return clinics[i].ID < clinics[j].ID
+		}
+		return clinics[i].Name < clinics[j].Name
+	})
+	return clinics, nil
+}
+
+// GetByID returns a clinic or nil when it does not exist
+func (r *ClinicRepository) GetByID(ctx context.Context, id int) (*models.Clinic, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	c, ok := r.store.clinics[id]
+	if !ok {
+		return nil, nil
+	}
+	return &c, nil
+}
+
+// Default returns the first clinic
+func (r *ClinicRepository) Default(ctx context.Context) (*models.Clinic, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	c, ok := r.store.clinics[r.store.defaultClinicID()]
+	if !ok {
+		return nil, nil
+	}
+	return &c, nil
+}
+
+// Create stores a clinic with ownerID as its admin
+func (r *ClinicRepository) Create(ctx context.Context, req models.CreateClinicRequest, ownerID int) (*models.Clinic, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	now := r.store.Now()
+	c := models.Clinic{
+		ID:        r.store.newID(),
+		Name:      req.Name,
+		Address:   req.Address,
+		Phone:     req.Phone,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	r.store.clinics[c.ID] = c
+	r.store.memberships[membershipKey{clinicID: c.ID, userID: ownerID}] = membershipRow{role: string(models.UserRoleAdmin), createdAt: now}
+	return &c, nil
+}
+
+// Update applies the non-nil fields of req
+func (r *ClinicRepository) Update(ctx context.Context, id int, req models.UpdateClinicRequest) (*models.Clinic, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	c, ok := r.store.clinics[id]
+	if !ok {
+		return nil, sql.ErrNoRows
+	}
+	if req.Name != nil {
+		c.Name = *req.Name
+	}
+	if req.Address != nil {
+		c.Address = *req.Address
+	}
+	if req.Phone != nil {
+		c.Phone = *req.Phone
+	}
+	c.UpdatedAt = r.store.Now()
+	r.store.clinics[id] = c
+	return &c, nil
+}
+
+// ListForUser returns the clinics a user works at by clinic ID
+func (r *ClinicRepository) ListForUser(ctx context.Context, userID int) ([]models.UserClinic, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	var clinics []models.UserClinic
+	for key, row := range r.store.memberships {
+		if key.userID == userID {
+			clinics = append(clinics, models.UserClinic{ClinicID: key.clinicID, Name: r.store.clinics[key.clinicID].Name, Role: row.role})
+		}
+	}
+	sort.Slice(clinics, func(i, j int) bool { return clinics[i].ClinicID < clinics[j].ClinicID })
+	return clinics, nil
+}
+
+// Membership returns the user's membership of a clinic
+func (r *ClinicRepository) Membership(ctx context.Context, clinicID, userID int) (*models.UserClinic, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	row, ok := r.store.memberships[membershipKey{clinicID: clinicID, userID: userID}]
+	if !ok {
+		return nil, nil
+	}
+	return &models.UserClinic{ClinicID: clinicID, Name: r.store.clinics[clinicID].Name, Role: row.role}, nil
+}
+
+// Members returns the users working at a clinic ordered by name
+func (r *ClinicRepository) Members(ctx context.Context, clinicID int) ([]models.ClinicMember, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	var members []models.ClinicMember
+	for key, row := range r.store.memberships {
+		if key.clinicID != clinicID {
+			continue
+		}
+		u := r.store.users[key.userID]
+		members = append(members, models.ClinicMember{
+			UserID:    u.ID,
+			Email:     u.Email,
+			FirstName: u.FirstName,
+			LastName:  u.LastName,
+			Role:      row.role,
+			Active:    u.DeactivatedAt == nil,
+			JoinedAt:  row.createdAt,
+		})
+	}
+	sort.Slice(members, func(i, j int) bool {
+		if members[i].LastName != members[j].LastName {
+			return members[i].LastName < members[j].LastName
+		}
+		if members[i].FirstName != members[j].FirstName {
+			return members[i].FirstName < members[j].FirstName
+		}
+		return members[i].UserID < members[j].UserID
+	})
+	return members, nil
+}
+
+// SetMember adds a user to a clinic or changes their role there
+func (r *ClinicRepository) SetMember(ctx context.Context, clinicID, userID int, role string) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if _, ok := r.store.users[userID]; !ok {
+		return sql.ErrNoRows
+	}
+
+	key := membershipKey{clinicID: clinicID, userID: userID}
+	row, ok := r.store.memberships[key]
+	if !ok {
+		row.createdAt = r.store.Now()
+	}
+	row.role = role
+	r.store.memberships[key] = row
+	return nil
+}
+
+// RemoveMember removes a user from a clinic
+func (r *ClinicRepository) RemoveMember(ctx context.Context, clinicID, userID int) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	key := membershipKey{clinicID: clinicID, userID: userID}
+	if _, ok := r.store.memberships[key]; !ok {
+		return sql.ErrNoRows
+	}
+	delete(r.store.memberships, key)
+	return nil
+}
+
+// CountActiveAdmins counts the active admins of a clinic
+func (r *ClinicRepository) CountActiveAdmins(ctx context.Context, clinicID int) (int, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	count := 0
+	for key, row := range r.store.memberships {
+		if key.clinicID == clinicID && row.role == string(models.UserRoleAdmin) && r.store.users[key.userID].DeactivatedAt == nil {
+			count++
+		}
+	}
+	return count, nil
+}
@@ -0,0 +1,79 @@
+// dental_backend/internal/repository/memory/identity_repository.go
+package memory
+
+import (
+	"context"
+	"sort"
+
+	"dental_backend/internal/models"
+	"dental_backend/internal/repository"
+)
+
+// IdentityRepository is the in-memory implementation of repository.IdentityRepository
+type IdentityRepository struct {
+	store *Store
+}
+
+// NewIdentityRepository creates an identity repository backed by the store
+func NewIdentityRepository(store *Store) *IdentityRepository {
+	return &IdentityRepository{store: store}
+}
+
+// Find returns the identity for a provider's subject
+func (r *IdentityRepository) Find(ctx context.Context, provider, subject string) (*models.UserIdentity, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	for _, identity := range r.store.identities {
+		if identity.Provider == provider && identity.Subject == subject {
+			return &identity, nil
+		}
+	}
+	return nil, nil
+}
+
+// Create links an identity, mirroring the unique constraints of the table
+func (r *IdentityRepository) Create(ctx context.Context, identity models.UserIdentity) (*models.UserIdentity, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	for _, existing := range r.store.identities {
+		if existing.Provider == identity.Provider &&
+			(existing.Subject == identity.Subject || existing.UserID == identity.UserID) {
+			return nil, repository.ErrIdentityTaken
+		}
+	}
+
+	identity.ID = r.store.newID()
+	identity.CreatedAt = r.store.Now()
+	r.store.identities[identity.ID] = identity
+	return &identity, nil
+}
+
+// RecordLogin stamps the identity's last sign-in
+func (r *IdentityRepository) RecordLogin(ctx context.Context, id int) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if identity, ok := r.store.identities[id]; ok {
+		now := r.store.Now()
+		identity.LastLoginAt = &now
+		r.store.identities[id] = identity
+	}
+	return nil
+}
+
+// ListByUser returns the user's identities ordered by provider
+func (r *IdentityRepository) ListByUser(ctx context.Context, userID int) ([]models.UserIdentity, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	identities := []models.UserIdentity{}
+	for _, identity := range r.store.identities {
+		if identity.UserID == userID {
+			identities = append(identities, identity)
+		}
+	}
+	sort.Slice(identities, func(i, j int) bool { return identities[i].Provider < identities[j].Provider })
+	return identities, nil
+}
@@ -0,0 +1,132 @@
+// dental_backend/internal/repository/memory/medical_repository.go
+package memory
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sort"
+
+	"dental_backend/internal/models"
+)
+
+// MedicalEntryRepository is the in-memory implementation of repository.MedicalEntryRepository
+type MedicalEntryRepository struct {
+	store *Store
+}
+
+// NewMedicalEntryRepository creates a medical entry repository backed by the store
+func NewMedicalEntryRepository(store *Store) *MedicalEntryRepository {
+	return &MedicalEntryRepository{store: store}
+}
+
+// sortMedicalEntries orders entries active first, then by onset, newest first
+func sortMedicalEntries(entries []models.MedicalEntry) {
+	sort.Slice(entries, func(i, j int) bool {
+		activeI := entries[i].Status == string(models.MedicalEntryStatusActive)
+		activeJ := entries[j].Status == string(models.MedicalEntryStatusActive)
+		if activeI != activeJ {
+			return activeI
+		}
+		if entries[i].Onset != entries[j].Onset {
+			return entries[i].Onset > entries[j].Onset
+		}
+		return entries[i].ID > entries[j].ID
+	})
+}
+
+// ListForPatient returns a patient's entries
+func (r *MedicalEntryRepository) ListForPatient(ctx context.Context, patientID int) ([]models.MedicalEntry, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	var entries []models.MedicalEntry
+	for _, e := range r.store.medicalEntries {
+		if e.PatientID == patientID && r.store.visible(ctx, e.ID) {
+			entries = append(entries, e)
+		}
+	}
+	sortMedicalEntries(entries)
+	return entries, nil
+}
+
+// ListActive returns the active entries of the patients
+func (r *MedicalEntryRepository) ListActive(ctx context.Context, patientIDs []int) ([]models.MedicalEntry, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	wanted := make(map[int]bool, len(patientIDs))
+	for _, id := range patientIDs {
+		wanted[id] = true
+	}
+
+	var entries []models.MedicalEntry
+	for _, e := range r.store.medicalEntries {
+		if wanted[e.PatientID] && e.Status == string(models.MedicalEntryStatusActive) && r.store.visible(ctx, e.ID) {
+			entries = append(entries, e)
+		}
+	}
+	sortMedicalEntries(entries)
+	return entries, nil
+}
+
+// GetByID returns an entry or nil
+func (r *MedicalEntryRepository) GetByID(ctx context.Context, id int) (*models.MedicalEntry, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	e, ok := r.store.medicalEntries[id]
+	if !ok || !r.store.visible(ctx, id) {
+		return nil, nil
+	}
+	return &e, nil
+}
+
+// Create stores a new entry
+func (r *MedicalEntryRepository) Create(ctx context.Context, entry models.MedicalEntry) (*models.MedicalEntry, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if _, ok := r.store.patients[entry.PatientID]; !ok || !r.store.visible(ctx, entry.PatientID) {
+		return nil, fmt.Errorf("failed to insert medical entry: patient %d does not exist", entry.PatientID)
+	}
+
+	now := r.store.Now()
+	entry.ID = r.store.newID()
+	entry.CreatedAt = now
+	entry.UpdatedAt = now
+	r.store.medicalEntries[entry.ID] = entry
+	r.store.claim(ctx, entry.ID, entry.PatientID)
+	return &entry, nil
+}
+
+// Update writes every field of entry but its patient and kind
+func (r *MedicalEntryRepository) Update(ctx context.Context, entry models.MedicalEntry) (*models.MedicalEntry, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	existing, ok := r.store.medicalEntries[entry.ID]
+	if !ok || !r.store.visible(ctx, entry.ID) {
+		return nil, sql.ErrNoRows
+	}
+
+	entry.PatientID = existing.PatientID
+	entry.Kind = existing.Kind
+	entry.RecordedBy = existing.RecordedBy
+	entry.CreatedAt = existing.CreatedAt
+	entry.UpdatedAt = r.store.Now()
+	r.store.medicalEntries[entry.ID] = entry
+	return &entry, nil
+}
+
+// Delete removes an entry
+func (r *MedicalEntryRepository) Delete(ctx context.Context, id int) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if _, ok := r.store.medicalEntries[id]; !ok || !r.store.visible(ctx, id) {
+		return sql.ErrNoRows
+	}
+	delete(r.store.medicalEntries, id)
+	return nil
+}
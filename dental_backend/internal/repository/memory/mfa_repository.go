@@ -0,0 +1,149 @@
+// dental_backend/internal/repository/memory/mfa_repository.go
+package memory
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"dental_backend/internal/models"
+)
+
+// MFARepository is the in-memory implementation of repository.MFARepository
+type MFARepository struct {
+	store *Store
+}
+
+// NewMFARepository creates a two-factor repository backed by the store
+func NewMFARepository(store *Store) *MFARepository {
+	return &MFARepository{store: store}
+}
+
+// Get returns the user's two-factor state or nil when the user does not exist
+func (r *MFARepository) Get(ctx context.Context, userID int) (*models.UserMFA, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	if _, ok := r.store.users[userID]; !ok {
+		return nil, nil
+	}
+
+	row := r.store.mfa[userID]
+	mfa := row.mfa
+	mfa.UserID = userID
+	for _, usedAt := range row.backupCodes {
+		if usedAt == nil {
+			mfa.BackupCodesRemaining++
+		}
+	}
+	return &mfa, nil
+}
+
+// setEnabled mirrors the enabled flag onto the user; callers must hold the write lock
+func (r *MFARepository) setEnabled(userID int, enabled bool) {
+	u := r.store.users[userID]
+	u.MFAEnabled = enabled
+	r.store.users[userID] = u
+}
+
+// SetSecret stores a secret awaiting confirmation
+func (r *MFARepository) SetSecret(ctx context.Context, userID int, secret string) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if _, ok := r.store.users[userID]; !ok {
+		return sql.ErrNoRows
+	}
+
+	row := r.store.mfa[userID]
+	row.mfa.Secret = secret
+	r.store.mfa[userID] = row
+	return nil
+}
+
+// Enable turns on the stored secret and replaces the backup codes
+func (r *MFARepository) Enable(ctx context.Context, userID int, step int64, codeHashes []string) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if _, ok := r.store.users[userID]; !ok {
+		return sql.ErrNoRows
+	}
+
+	now := r.store.Now()
+	row := r.store.mfa[userID]
+	row.mfa.EnabledAt = &now
+	row.mfa.LastStep = &step
+	row.backupCodes = backupCodeSet(codeHashes)
+	r.store.mfa[userID] = row
+	r.setEnabled(userID, true)
+	return nil
+}
+
+// Disable removes the secret and backup codes
+func (r *MFARepository) Disable(ctx context.Context, userID int) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if _, ok := r.store.users[userID]; !ok {
+		return sql.ErrNoRows
+	}
+
+	delete(r.store.mfa, userID)
+	r.setEnabled(userID, false)
+	return nil
+}
+
+// ReplaceBackupCodes discards the user's backup codes and stores new ones
+func (r *MFARepository) ReplaceBackupCodes(ctx context.Context, userID int, codeHashes []string) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	row := r.store.mfa[userID]
+	row.backupCodes = backupCodeSet(codeHashes)
+	r.store.mfa[userID] = row
+	return nil
+}
+
+// UseStep records a code's time step as used unless it or a later one already was
+func (r *MFARepository) UseStep(ctx context.Context, userID int, step int64) (bool, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	row, ok := r.store.mfa[userID]
+	if !ok || (row.mfa.LastStep != nil && *row.mfa.LastStep >= step) {
+		return false, nil
+	}
+
+	row.mfa.LastStep = &step
+	r.store.mfa[userID] = row
+	return true, nil
+}
+
+// UseBackupCode marks an unused backup code as used
+func (r *MFARepository) UseBackupCode(ctx context.Context, userID int, codeHash string) (bool, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	row, ok := r.store.mfa[userID]
+	if !ok {
+		return false, nil
+	}
+	usedAt, ok := row.backupCodes[codeHash]
+	if !ok || usedAt != nil {
+		return false, nil
+	}
+
+	now := r.store.Now()
+	row.backupCodes[codeHash] = &now
+	return true, nil
+}
+
+// backupCodeSet returns unused entries for each code hash
+func backupCodeSet(codeHashes []string) map[string]*time.Time {
+	codes := make(map[string]*time.Time, len(codeHashes))
+	for _, hash := range codeHashes {
+		codes[hash] = nil
+	}
+	return codes
+}
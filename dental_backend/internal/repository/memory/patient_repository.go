@@ -0,0 +1,224 @@
+// dental_backend/internal/repository/memory/patient_repository.go
+package memory
+
+import (
+	"context"
+	"database/sql"
+	"sort"
+	"strings"
+
+	"dental_backend/internal/models"
+)
+
+// PatientRepository is the in-memory implementation of repository.PatientRepository
+type PatientRepository struct {
+	store *Store
+}
+
+// NewPatientRepository creates a patient repository backed by the store
+func NewPatientRepository(store *Store) *PatientRepository {
+	return &PatientRepository{store: store}
+}
+
+// List returns patients newest first, optionally filtered by search term
+func (r *PatientRepository) List(ctx context.Context, search string) ([]models.Patient, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	needle := strings.ToLower(search)
+
+	var patients []models.Patient
+	for _, p := range r.store.patients {
+		if !r.store.visible(ctx, p.ID) {
+			continue
+		}
+		if needle != "" &&
+			!strings.Contains(strings.ToLower(p.FirstName), needle) &&
+			!strings.Contains(strings.ToLower(p.LastName), needle) &&
+			!strings.Contains(strings.ToLower(p.Email), needle) &&
+			!strings.Contains(strings.ToLower(p.Phone), needle) {
+			continue
+		}
+		patients = append(patients, p)
+	}
+
+	sort.Slice(patients, func(i, j int) bool {
+		if patients[i].CreatedAt.Equal(patients[j].CreatedAt) {
+			return patients[i].ID > patients[j].ID
+		}
+		return patients[i].CreatedAt.After(patients[j].CreatedAt)
+	})
+
+	return patients, nil
+}
+
+// GetByID returns a patient or nil when it does not exist
+func (r *PatientRepository) GetByID(ctx context.Context, id int) (*models.Patient, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	p, ok := r.store.patients[id]
+	if !ok || !r.store.visible(ctx, id) {
+		return nil, nil
+	}
+	return &p, nil
+}
+
+// Create stores a new patient
+func (r *PatientRepository) Create(ctx context.Context, req models.CreatePatientRequest) (*models.Patient, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	now := r.store.Now()
+	p := models.Patient{
+		ID:                    r.store.newID(),
+		FirstName:             req.FirstName,
+		LastName:              req.LastName,
+		DateOfBirth:           req.DateOfBirth,
+		Phone:                 req.Phone,
+		Email:                 req.Email,
+		Address:               req.Address,
+		EmergencyContact:      req.EmergencyContact,
+		InsuranceProvider:     req.InsuranceProvider,
+		InsurancePolicyNumber: req.InsurancePolicyNumber,
+		MedicalHistory:        req.MedicalHistory,
+		RiskLevel:             req.RiskLevel,
+		Provisional:           req.Provisional,
+		CreatedAt:             now,
+		UpdatedAt:             now,
+	}
+	r.store.patients[p.ID] = p
+	r.store.claim(ctx, p.ID, 0)
+
+	return &p, nil
+}
+
+// Update applies the non-empty fields of req
+func (r *PatientRepository) Update(ctx context.Context, id int, req models.UpdatePatientRequest) (*models.Patient, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	p, ok := r.store.patients[id]
+	if !ok || !r.store.visible(ctx, id) {
+		return nil, nil
+	}
+
+	setIfNotEmpty(&p.FirstName, req.FirstName)
+	setIfNotEmpty(&p.LastName, req.LastName)
+	setIfNotEmpty(&p.DateOfBirth, req.DateOfBirth)
+	setIfNotEmpty(&p.Phone, req.Phone)
+	setIfNotEmpty(&p.Email, req.Email)
+	setIfNotEmpty(&p.Address, req.Address)
+	setIfNotEmpty(&p.EmergencyContact, req.EmergencyContact)
+	setIfNotEmpty(&p.InsuranceProvider, req.InsuranceProvider)
+	setIfNotEmpty(&p.InsurancePolicyNumber, req.InsurancePolicyNumber)
+	setIfNotEmpty(&p.MedicalHistory, req.MedicalHistory)
+	setIfNotEmpty(&p.RiskLevel, req.RiskLevel)
+	if req.Provisional != nil {
+		p.Provisional = *req.Provisional
+	}
+	p.UpdatedAt = r.store.Now()
+
+	r.store.patients[id] = p
+	return &p, nil
+}
+
+// FindByEmailAndDOB returns the patient matching an email and date of birth,
+// preferring verified patients over provisional ones
+func (r *PatientRepository) FindByEmailAndDOB(ctx context.Context, email, dateOfBirth string) (*models.Patient, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	dob, err := models.ParseAppointmentDate(dateOfBirth)
+	if err != nil {
+		return nil, nil
+	}
+
+	var match *models.Patient
+	for _, p := range r.store.patients {
+		if !r.store.visible(ctx, p.ID) || !strings.EqualFold(p.Email, email) {
+			continue
+		}
+		if pdob, err := models.ParseAppointmentDate(p.DateOfBirth); err != nil || !pdob.Equal(dob) {
+			continue
+		}
+		if match == nil || (match.Provisional && !p.Provisional) ||
+			(match.Provisional == p.Provisional && p.ID < match.ID) {
+			p := p
+			match = &p
+		}
+	}
+	return match, nil
+}
+
+// Delete removes a patient and the records that cascade from it
+func (r *PatientRepository) Delete(ctx context.Context, id int) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if _, ok := r.store.patients[id]; !ok || !r.store.visible(ctx, id) {
+		return sql.ErrNoRows
+	}
+	delete(r.store.patients, id)
+
+	// Mirror the ON DELETE CASCADE foreign keys
+	for apptID, a := range r.store.appointments {
+		if a.PatientID == id {
+			r.store.deleteAppointment(apptID)
+		}
+	}
+	for seriesID, series := range r.store.series {
+		if series.PatientID == id {
+			delete(r.store.series, seriesID)
+		}
+	}
+	for entryID, e := range r.store.waitlist {
+		if e.PatientID == id {
+			delete(r.store.waitlist, entryID)
+		}
+	}
+	for ptID, pt := range r.store.patientTreatments {
+		if pt.PatientID == id {
+			delete(r.store.patientTreatments, ptID)
+		}
+	}
+	for invoiceID, i := range r.store.invoices {
+		if i.PatientID == id {
+			delete(r.store.invoices, invoiceID)
+		}
+	}
+	for claimID, c := range r.store.claims {
+		if c.PatientID == id {
+			delete(r.store.claims, claimID)
+		}
+	}
+	for entryID, e := range r.store.medicalEntries {
+		if e.PatientID == id {
+			delete(r.store.medicalEntries, entryID)
+		}
+	}
+	delete(r.store.riskAssessments, id)
+
+	return nil
+}
+
+// Count returns the number of patients, optionally restricted to a risk level
+func (r *PatientRepository) Count(ctx context.Context, riskLevel string) (int, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	count := 0
+	for _, p := range r.store.patients {
+		if r.store.visible(ctx, p.ID) && (riskLevel == "" || p.RiskLevel == riskLevel) {
+			count++
+		}
+	}
+	return count, nil
+}
+
+// setIfNotEmpty overwrites dst when value is non-empty, mirroring the partial update queries
+func setIfNotEmpty(dst *string, value string) {
+	if value != "" {
+		*dst = value
+	}
+}
@@ -0,0 +1,98 @@
+// dental_backend/internal/repository/memory/reminder_repository.go
+package memory
+
+import (
+	"context"
+	"database/sql"
+	"sort"
+
+	"dental_backend/internal/models"
+)
+
+// ReminderRepository is the in-memory implementation of repository.ReminderRepository
+type ReminderRepository struct {
+	store *Store
+}
+
+// NewReminderRepository creates a reminder repository backed by the store
+func NewReminderRepository(store *Store) *ReminderRepository {
+	return &ReminderRepository{store: store}
+}
+
+// Claim inserts a delivery, or re-claims a failed one with attempts left
+func (r *ReminderRepository) Claim(ctx context.Context, delivery models.ReminderDelivery) (*models.ReminderDelivery, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	now := r.store.Now()
+
+	// Mirror the unique key on appointment, slot, offset and channel
+	for id, d := range r.store.reminders {
+		if d.AppointmentID != delivery.AppointmentID || d.AppointmentDate != delivery.AppointmentDate ||
+			d.StartTime != delivery.StartTime || d.OffsetMinutes != delivery.OffsetMinutes || d.Channel != delivery.Channel {
+			continue
+		}
+		if d.Status != string(models.ReminderFailed) || d.Attempts >= models.MaxReminderAttempts {
+			return nil, nil
+		}
+		d.Status = string(models.ReminderSending)
+		d.Attempts++
+		d.Recipient = delivery.Recipient
+		d.Error = ""
+		d.UpdatedAt = now
+		r.store.reminders[id] = d
+		return &d, nil
+	}
+
+	delivery.ID = r.store.newID()
+	delivery.Status = string(models.ReminderSending)
+	delivery.Attempts = 1
+	delivery.Error = ""
+	delivery.SentAt = nil
+	delivery.CreatedAt = now
+	delivery.UpdatedAt = now
+	r.store.reminders[delivery.ID] = delivery
+
+	return &delivery, nil
+}
+
+// Finish marks a claimed delivery sent, or failed with the given error
+func (r *ReminderRepository) Finish(ctx context.Context, id int, sendErr string) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	d, ok := r.store.reminders[id]
+	if !ok {
+		return sql.ErrNoRows
+	}
+
+	now := r.store.Now()
+	if sendErr == "" {
+		d.Status = string(models.ReminderSent)
+		d.SentAt = &now
+	} else {
+		d.Status = string(models.ReminderFailed)
+		d.Error = sendErr
+	}
+	d.UpdatedAt = now
+	r.store.reminders[id] = d
+
+	return nil
+}
+
+// ListForAppointment returns an appointment's reminder deliveries, oldest first
+func (r *ReminderRepository) ListForAppointment(ctx context.Context, appointmentID int) ([]models.ReminderDelivery, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	var deliveries []models.ReminderDelivery
+	for _, d := range r.store.reminders {
+		if d.AppointmentID == appointmentID {
+			deliveries = append(deliveries, d)
+		}
+	}
+
+	sort.Slice(deliveries, func(i, j int) bool { return deliveries[i].ID < deliveries[j].ID })
+
+	return deliveries, nil
+}
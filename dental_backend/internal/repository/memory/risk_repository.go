@@ -0,0 +1,101 @@
+// dental_backend/internal/repository/memory/risk_repository.go
+package memory
+
+import (
+	"context"
+	"fmt"
+
+	"dental_backend/internal/models"
+	"dental_backend/internal/repository"
+)
+
+// RiskRepository is the in-memory implementation of repository.RiskRepository
+type RiskRepository struct {
+	store *Store
+}
+
+// NewRiskRepository creates a risk repository backed by the store
+func NewRiskRepository(store *Store) *RiskRepository {
+	return &RiskRepository{store: store}
+}
+
+// Rules returns the rules of the clinic of ctx or nil while the defaults apply
+func (r *RiskRepository) Rules(ctx context.Context) (*models.RiskRules, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	clinicID, _ := repository.ClinicID(ctx)
+	return r.rules(clinicID), nil
+}
+
+// PatientRules returns the rules of the patient's clinic or nil while the defaults apply
+func (r *RiskRepository) PatientRules(ctx context.Context, patientID int) (*models.RiskRules, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	if _, ok := r.store.patients[patientID]; !ok || !r.store.visible(ctx, patientID) {
+		return nil, nil
+	}
+	return r.rules(r.store.rowClinics[patientID]), nil
+}
+
+// rules returns a copy of the clinic's rules or nil; callers must hold a lock
+func (r *RiskRepository) rules(clinicID int) *models.RiskRules {
+	rules, ok := r.store.riskRules[clinicID]
+	if !ok {
+		return nil
+	}
+	rules.AgeBands = append([]models.RiskAgeBand{}, rules.AgeBands...)
+	return &rules
+}
+
+// SetRules stores the rules of the clinic of ctx in place of any configured before
+func (r *RiskRepository) SetRules(ctx context.Context, rules models.RiskRules) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	clinicID, _ := repository.ClinicID(ctx)
+	rules.AgeBands = append([]models.RiskAgeBand{}, rules.AgeBands...)
+	r.store.riskRules[clinicID] = rules
+	return nil
+}
+
+// GetAssessment returns a patient's latest assessment or nil
+func (r *RiskRepository) GetAssessment(ctx context.Context, patientID int) (*models.RiskAssessment, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	a, ok := r.store.riskAssessments[patientID]
+	if !ok || !r.store.visible(ctx, patientID) {
+		return nil, nil
+	}
+	a.Factors = append([]models.RiskFactor{}, a.Factors...)
+	return &a, nil
+}
+
+// SaveAssessment replaces a patient's assessment
+func (r *RiskRepository) SaveAssessment(ctx context.Context, a models.RiskAssessment) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if _, ok := r.store.patients[a.PatientID]; !ok || !r.store.visible(ctx, a.PatientID) {
+		return fmt.Errorf("failed to save risk assessment: patient %d does not exist", a.PatientID)
+	}
+	a.Factors = append([]models.RiskFactor{}, a.Factors...)
+	r.store.riskAssessments[a.PatientID] = a
+	return nil
+}
+
+// CountByLevel returns the number of assessed patients at each derived level
+func (r *RiskRepository) CountByLevel(ctx context.Context) (map[string]int, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	counts := make(map[string]int)
+	for patientID, a := range r.store.riskAssessments {
+		if r.store.visible(ctx, patientID) {
+			counts[a.Level]++
+		}
+	}
+	return counts, nil
+}
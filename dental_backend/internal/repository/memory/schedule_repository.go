@@ -0,0 +1,202 @@
+// dental_backend/internal/repository/memory/schedule_repository.go
+package memory
+
+import (
+	"context"
+	"database/sql"
+	"sort"
+
+	"dental_backend/internal/models"
+	"dental_backend/internal/repository"
+)
+
+// ScheduleRepository is the in-memory implementation of repository.ScheduleRepository
+type ScheduleRepository struct {
+	store *Store
+}
+
+// NewScheduleRepository creates a schedule repository backed by the store
+func NewScheduleRepository(store *Store) *ScheduleRepository {
+	return &ScheduleRepository{store: store}
+}
+
+// IsDentist reports whether the user exists, is active and has the dentist role
+func (r *ScheduleRepository) IsDentist(ctx context.Context, userID int) (bool, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	u, ok := r.store.users[userID]
+	return ok && r.store.isDentist(ctx, u), nil
+}
+
+// Dentists returns every active dentist ordered by name
+func (r *ScheduleRepository) Dentists(ctx context.Context) ([]models.Dentist, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	var users []models.User
+	for _, u := range r.store.users {
+		if r.store.isDentist(ctx, u) {
+			users = append(users, u)
+		}
+	}
+	sort.Slice(users, func(i, j int) bool {
+		if users[i].LastName != users[j].LastName {
+			return users[i].LastName < users[j].LastName
+		}
+		if users[i].FirstName != users[j].FirstName {
+			return users[i].FirstName < users[j].FirstName
+		}
+		return users[i].ID < users[j].ID
+	})
+
+	var dentists []models.Dentist
+	for _, u := range users {
+		dentists = append(dentists, models.Dentist{ID: u.ID, Name: u.FirstName + " " + u.LastName})
+	}
+	return dentists, nil
+}
+
+// weekKey returns the key of the dentist's week at the clinic of ctx, which has
+// no week without a clinic
+func weekKey(ctx context.Context, dentistID int) (membershipKey, bool) {
+	clinicID, ok := repository.ClinicID(ctx)
+	return membershipKey{clinicID: clinicID, userID: dentistID}, ok
+}
+
+// WorkingHours returns the dentist's weekly working hours at the clinic of ctx
+func (r *ScheduleRepository) WorkingHours(ctx context.Context, dentistID int) ([]models.WorkingHours, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	key, ok := weekKey(ctx, dentistID)
+	if !ok {
+		return nil, nil
+	}
+	return append([]models.WorkingHours(nil), r.store.workingHours[key]...), nil
+}
+
+// Breaks returns the dentist's weekly breaks at the clinic of ctx
+func (r *ScheduleRepository) Breaks(ctx context.Context, dentistID int) ([]models.ScheduleBreak, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	key, ok := weekKey(ctx, dentistID)
+	if !ok {
+		return nil, nil
+	}
+	return append([]models.ScheduleBreak(nil), r.store.breaks[key]...), nil
+}
+
+// ReplaceWeek replaces the dentist's working hours and breaks at the clinic of ctx
+func (r *ScheduleRepository) ReplaceWeek(ctx context.Context, dentistID int, hours []models.WorkingHours, breaks []models.ScheduleBreak) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	key, _ := weekKey(ctx, dentistID)
+
+	storedHours := make([]models.WorkingHours, len(hours))
+	for i, h := range hours {
+		h.ID = r.store.newID()
+		h.DentistID = dentistID
+		storedHours[i] = h
+	}
+	sort.SliceStable(storedHours, func(i, j int) bool {
+		if storedHours[i].Weekday != storedHours[j].Weekday {
+			return storedHours[i].Weekday < storedHours[j].Weekday
+		}
+		return storedHours[i].StartTime < storedHours[j].StartTime
+	})
+
+	storedBreaks := make([]models.ScheduleBreak, len(breaks))
+	for i, b := range breaks {
+		b.ID = r.store.newID()
+		b.DentistID = dentistID
+		storedBreaks[i] = b
+	}
+	sort.SliceStable(storedBreaks, func(i, j int) bool {
+		if storedBreaks[i].Weekday != storedBreaks[j].Weekday {
+			return storedBreaks[i].Weekday < storedBreaks[j].Weekday
+		}
+		return storedBreaks[i].StartTime < storedBreaks[j].StartTime
+	})
+
+	r.store.workingHours[key] = storedHours
+	r.store.breaks[key] = storedBreaks
+	return nil
+}
+
+// Closures returns closures intersecting the date range
+func (r *ScheduleRepository) Closures(ctx context.Context, dentistID *int, from, to string) ([]models.Closure, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	var closures []models.Closure
+	for _, c := range r.store.closures {
+		if !r.store.visible(ctx, c.ID) {
+			continue
+		}
+		if dentistID != nil && c.DentistID != nil && *c.DentistID != *dentistID {
+			continue
+		}
+		if from != "" && c.EndDate < from {
+			continue
+		}
+		if to != "" && c.StartDate > to {
+			continue
+		}
+		closures = append(closures, c)
+	}
+
+	sort.Slice(closures, func(i, j int) bool {
+		if closures[i].StartDate != closures[j].StartDate {
+			return closures[i].StartDate < closures[j].StartDate
+		}
+		return closures[i].ID < closures[j].ID
+	})
+
+	return closures, nil
+}
+
+// GetClosure returns a closure or nil when it does not exist
+func (r *ScheduleRepository) GetClosure(ctx context.Context, id int) (*models.Closure, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	c, ok := r.store.closures[id]
+	if !ok || !r.store.visible(ctx, id) {
+		return nil, nil
+	}
+	return &c, nil
+}
+
+// CreateClosure stores a new closure
+func (r *ScheduleRepository) CreateClosure(ctx context.Context, req models.CreateClosureRequest) (*models.Closure, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	c := models.Closure{
+		ID:        r.store.newID(),
+		DentistID: req.DentistID,
+		StartDate: req.StartDate,
+		EndDate:   req.EndDate,
+		Reason:    req.Reason,
+		CreatedAt: r.store.Now(),
+	}
+	r.store.closures[c.ID] = c
+	r.store.claim(ctx, c.ID, 0)
+
+	return &c, nil
+}
+
+// DeleteClosure removes a closure
+func (r *ScheduleRepository) DeleteClosure(ctx context.Context, id int) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if _, ok := r.store.closures[id]; !ok || !r.store.visible(ctx, id) {
+		return sql.ErrNoRows
+	}
+	delete(r.store.closures, id)
+	return nil
+}
@@ -0,0 +1,88 @@
+// dental_backend/internal/repository/memory/series_repository.go
+package memory
+
+import (
+	"context"
+	"database/sql"
+
+	"dental_backend/internal/models"
+)
+
+// SeriesRepository is the in-memory implementation of repository.SeriesRepository
+type SeriesRepository struct {
+	store *Store
+}
+
+// NewSeriesRepository creates a series repository backed by the store
+func NewSeriesRepository(store *Store) *SeriesRepository {
+	return &SeriesRepository{store: store}
+}
+
+// Create stores a new series
+func (r *SeriesRepository) Create(ctx context.Context, series models.AppointmentSeries) (*models.AppointmentSeries, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	now := r.store.Now()
+	series.ID = r.store.newID()
+	series.CreatedAt = now
+	series.UpdatedAt = now
+	r.store.series[series.ID] = series
+	r.store.claim(ctx, series.ID, series.PatientID)
+
+	return &series, nil
+}
+
+// GetByID returns a series, restricted to a dentist when dentistID is set, or nil
+func (r *SeriesRepository) GetByID(ctx context.Context, id int, dentistID *int) (*models.AppointmentSeries, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	series, ok := r.store.series[id]
+	if !ok || !r.store.visible(ctx, id) || (dentistID != nil && series.DentistID != *dentistID) {
+		return nil, nil
+	}
+	return &series, nil
+}
+
+// Update replaces the template fields of a series owned by the dentist
+func (r *SeriesRepository) Update(ctx context.Context, series models.AppointmentSeries) (*models.AppointmentSeries, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	existing, ok := r.store.series[series.ID]
+	if !ok || !r.store.visible(ctx, series.ID) || existing.DentistID != series.DentistID {
+		return nil, nil
+	}
+
+	existing.TreatmentID = series.TreatmentID
+	existing.StartTime = series.StartTime
+	existing.Duration = series.Duration
+	existing.Notes = series.Notes
+	existing.Recurrence.Until = series.Recurrence.Until
+	existing.Recurrence.Count = series.Recurrence.Count
+	existing.UpdatedAt = r.store.Now()
+	r.store.series[series.ID] = existing
+
+	return &existing, nil
+}
+
+// Delete removes a series and detaches its appointments
+func (r *SeriesRepository) Delete(ctx context.Context, id int, dentistID int) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	series, ok := r.store.series[id]
+	if !ok || !r.store.visible(ctx, id) || series.DentistID != dentistID {
+		return sql.ErrNoRows
+	}
+	delete(r.store.series, id)
+
+	for appointmentID, a := range r.store.appointments {
+		if a.SeriesID != nil && *a.SeriesID == id {
+			a.SeriesID = nil
+			r.store.appointments[appointmentID] = a
+		}
+	}
+	return nil
+}
@@ -0,0 +1,158 @@
+// dental_backend/internal/repository/memory/session_repository.go
+package memory
+
+import (
+	"context"
+	"database/sql"
+	"sort"
+	"time"
+
+	"dental_backend/internal/models"
+)
+
+// SessionRepository is the in-memory implementation of repository.SessionRepository
+type SessionRepository struct {
+	store *Store
+}
+
+// NewSessionRepository creates a session repository backed by the store
+func NewSessionRepository(store *Store) *SessionRepository {
+	return &SessionRepository{store: store}
+}
+
+// active reports whether a session is neither revoked nor expired; callers must hold a lock
+func (r *SessionRepository) active(row sessionRow) bool {
+	return row.session.RevokedAt == nil && row.session.ExpiresAt.After(r.store.Now())
+}
+
+// Create stores a new session
+func (r *SessionRepository) Create(ctx context.Context, session models.Session, tokenHash string) (*models.Session, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	now := r.store.Now()
+	session.CreatedAt = now
+	session.LastUsedAt = now
+	session.RevokedAt = nil
+	r.store.sessions[session.ID] = sessionRow{session: session, tokenHash: tokenHash}
+	return &session, nil
+}
+
+// GetActive returns an unrevoked, unexpired session
+func (r *SessionRepository) GetActive(ctx context.Context, id string) (*models.Session, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	row, ok := r.store.sessions[id]
+	if !ok || !r.active(row) {
+		return nil, nil
+	}
+	s := row.session
+	return &s, nil
+}
+
+// Rotate swaps the refresh token of the active session holding oldHash
+func (r *SessionRepository) Rotate(ctx context.Context, oldHash, newHash string, expiresAt time.Time, client models.SessionClient) (*models.Session, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	for id, row := range r.store.sessions {
+		if row.tokenHash != oldHash || !r.active(row) {
+			continue
+		}
+		row.previousTokenHash = row.tokenHash
+		row.tokenHash = newHash
+		row.session.ExpiresAt = expiresAt
+		row.session.UserAgent = client.UserAgent
+		row.session.IPAddress = client.IPAddress
+		row.session.LastUsedAt = r.store.Now()
+		r.store.sessions[id] = row
+
+		s := row.session
+		return &s, nil
+	}
+	return nil, nil
+}
+
+// RevokeByPreviousHash revokes the session a replayed refresh token belonged to
+func (r *SessionRepository) RevokeByPreviousHash(ctx context.Context, tokenHash string) (bool, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	for id, row := range r.store.sessions {
+		if row.previousTokenHash != tokenHash || row.session.RevokedAt != nil {
+			continue
+		}
+		now := r.store.Now()
+		row.session.RevokedAt = &now
+		r.store.sessions[id] = row
+		return true, nil
+	}
+	return false, nil
+}
+
+// Revoke revokes an active session of the user
+func (r *SessionRepository) Revoke(ctx context.Context, id string, userID int) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	row, ok := r.store.sessions[id]
+	if !ok || row.session.UserID != userID || !r.active(row) {
+		return sql.ErrNoRows
+	}
+	now := r.store.Now()
+	row.session.RevokedAt = &now
+	r.store.sessions[id] = row
+	return nil
+}
+
+// RevokeAll revokes every active session of the user other than exceptID
+func (r *SessionRepository) RevokeAll(ctx context.Context, userID int, exceptID string) (int, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	now := r.store.Now()
+	count := 0
+	for id, row := range r.store.sessions {
+		if row.session.UserID != userID || id == exceptID || !r.active(row) {
+			continue
+		}
+		row.session.RevokedAt = &now
+		r.store.sessions[id] = row
+		count++
+	}
+	return count, nil
+}
+
+// ListActive returns the user's active sessions, most recently used first
+func (r *SessionRepository) ListActive(ctx context.Context, userID int) ([]models.Session, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	var sessions []models.Session
+	for _, row := range r.store.sessions {
+		if row.session.UserID == userID && r.active(row) {
+			sessions = append(sessions, row.session)
+		}
+	}
+
+	sort.Slice(sessions, func(i, j int) bool {
+		return sessions[i].LastUsedAt.After(sessions[j].LastUsedAt)
+	})
+
+	return sessions, nil
+}
+
+// SetClinic moves an active session to another clinic
+func (r *SessionRepository) SetClinic(ctx context.Context, id string, clinicID int) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	row, ok := r.store.sessions[id]
+	if !ok || !r.active(row) {
+		return sql.ErrNoRows
+	}
+	row.session.ClinicID = &clinicID
+	r.store.sessions[id] = row
+	return nil
+}
@@ -0,0 +1,298 @@
+// dental_backend/internal/repository/memory/store.go
+package memory
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"dental_backend/internal/models"
+	"dental_backend/internal/repository"
+)
+
+// Store holds the in-memory tables shared by the memory repositories. Joined
+// fields such as patient and treatment names are resolved from the same store,
+// so repositories created from one Store behave like a single database.
+type Store struct {
+	mu sync.RWMutex
+
+	nextID int
+
+	users             map[int]models.User
+	patients          map[int]models.Patient
+	appointments      map[int]models.Appointment
+	treatments        map[int]models.Treatment
+	patientTreatments map[int]models.PatientTreatment
+	medicalEntries    map[int]models.MedicalEntry
+	riskRules         map[int]models.RiskRules      // keyed by clinic
+	riskAssessments   map[int]models.RiskAssessment // keyed by patient
+	invoices          map[int]models.Invoice
+	claims            map[int]models.InsuranceClaim
+	workingHours      map[membershipKey][]models.WorkingHours  // keyed by clinic and dentist
+	breaks            map[membershipKey][]models.ScheduleBreak // keyed by clinic and dentist
+	closures          map[int]models.Closure
+	series            map[int]models.AppointmentSeries
+	appointmentEvents []models.AppointmentEvent
+	waitlist          map[int]models.WaitlistEntry
+	openings          map[int]models.SlotOpening
+	reminders         map[int]models.ReminderDelivery
+	linkUses          map[string]models.AppointmentLinkUse // keyed by token ID
+	calendarFeeds     map[int]calendarFeedRow
+	sessions          map[string]sessionRow
+	accountTokens     map[string]models.AccountToken // keyed by token hash
+	authPolicies      map[int]models.AuthPolicy      // keyed by clinic
+	mfa               map[int]mfaRow                 // keyed by user
+	identities        map[int]models.UserIdentity
+	clinics           map[int]models.Clinic
+	memberships       map[membershipKey]membershipRow
+	auditLog          []models.AuditEntry
+
+	// rowClinics holds the clinic owning each patient, treatment and other
+	// clinic-scoped record, keyed by record ID; IDs are unique across the store
+	rowClinics map[int]int
+
+	// Now returns the current time; tests may replace it for deterministic timestamps
+	Now func() time.Time
+}
+
+// calendarFeedRow is a calendar feed with the token hash it is looked up by
+type calendarFeedRow struct {
+	feed      models.CalendarFeed
+	tokenHash string
+}
+
+// sessionRow is a session with the refresh token hashes it is looked up by
+type sessionRow struct {
+	session           models.Session
+	tokenHash         string
+	previousTokenHash string
+}
+
+// mfaRow is a user's two-factor state with their backup code hashes and when each was used
+type mfaRow struct {
+	mfa         models.UserMFA
+	backupCodes map[string]*time.Time
+}
+
+// membershipKey identifies a user's membership of a clinic
+type membershipKey struct {
+	clinicID int
+	userID   int
+}
+
+// membershipRow is a user's role at a clinic
+type membershipRow struct {
+	role      string
+	createdAt time.Time
+}
+
+// NewStore creates an in-memory store holding only the default clinic, as the
+// migrations leave a new database
+func NewStore() *Store {
+	s := &Store{
+		users:             map[int]models.User{},
+		patients:          map[int]models.Patient{},
+		appointments:      map[int]models.Appointment{},
+		treatments:        map[int]models.Treatment{},
+		patientTreatments: map[int]models.PatientTreatment{},
+		medicalEntries:    map[int]models.MedicalEntry{},
+		riskRules:         map[int]models.RiskRules{},
+		riskAssessments:   map[int]models.RiskAssessment{},
+		invoices:          map[int]models.Invoice{},
+		claims:            map[int]models.InsuranceClaim{},
+		workingHours:      map[membershipKey][]models.WorkingHours{},
+		breaks:            map[membershipKey][]models.ScheduleBreak{},
+		closures:          map[int]models.Closure{},
+		series:            map[int]models.AppointmentSeries{},
+		waitlist:          map[int]models.WaitlistEntry{},
+		openings:          map[int]models.SlotOpening{},
+		reminders:         map[int]models.ReminderDelivery{},
+		linkUses:          map[string]models.AppointmentLinkUse{},
+		calendarFeeds:     map[int]calendarFeedRow{},
+		sessions:          map[string]sessionRow{},
+		accountTokens:     map[string]models.AccountToken{},
+		authPolicies:      map[int]models.AuthPolicy{},
+		mfa:               map[int]mfaRow{},
+		identities:        map[int]models.UserIdentity{},
+		clinics:           map[int]models.Clinic{},
+		memberships:       map[membershipKey]membershipRow{},
+		rowClinics:        map[int]int{},
+		Now:               time.Now,
+	}
+
+	now := s.Now()
+	id := s.newID()
+	s.clinics[id] = models.Clinic{ID: id, Name: "Main Clinic", CreatedAt: now, UpdatedAt: now}
+	return s
+}
+
+// AddUser seeds a user so that dentist names can be resolved in joined results,
+// adding them to the default clinic with their role
+func (s *Store) AddUser(user models.User) models.User {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if user.ID == 0 {
+		user.ID = s.newID()
+	}
+	user.Active = user.DeactivatedAt == nil
+	s.users[user.ID] = user
+	s.joinClinic(repository.WithClinic(context.Background(), s.defaultClinicID()), user)
+	return user
+}
+
+// newID returns the next identifier; callers must hold the write lock
+func (s *Store) newID() int {
+	s.nextID++
+	return s.nextID
+}
+
+// joinClinic adds a new user to the clinic of ctx, if it has one, with their
+// role; callers must hold the write lock
+func (s *Store) joinClinic(ctx context.Context, user models.User) {
+	clinicID, ok := repository.ClinicID(ctx)
+	if !ok {
+		return
+	}
+	s.memberships[membershipKey{clinicID: clinicID, userID: user.ID}] = membershipRow{role: user.Role, createdAt: s.Now()}
+}
+
+// defaultClinicID returns the first clinic; callers must hold a lock
+func (s *Store) defaultClinicID() int {
+	first := 0
+	for id := range s.clinics {
+		if first == 0 || id < first {
+			first = id
+		}
+	}
+	return first
+}
+
+// member returns the user as the clinic of ctx sees them, with their role there,
+// and whether they work at it; without a clinic every user is seen with their
+// own role. Callers must hold a lock.
+func (s *Store) member(ctx context.Context, u models.User) (models.User, bool) {
+	clinicID, ok := repository.ClinicID(ctx)
+	if !ok {
+		return u, true
+	}
+	row, ok := s.memberships[membershipKey{clinicID: clinicID, userID: u.ID}]
+	u.Role = row.role
+	return u, ok
+}
+
+// isDentist reports whether the user is active and a dentist at the clinic of ctx
+// or, without one, by their own role; callers must hold a lock
+func (s *Store) isDentist(ctx context.Context, u models.User) bool {
+	if u.DeactivatedAt != nil {
+		return false
+	}
+	role := u.Role
+	if clinicID, ok := repository.ClinicID(ctx); ok {
+		role = s.memberships[membershipKey{clinicID: clinicID, userID: u.ID}].role
+	}
+	return role == string(models.UserRoleDentist)
+}
+
+// visible mirrors the row-level security policy: a record is visible to a context
+// with the clinic owning it, or one opened to every clinic. Callers must hold a lock.
+func (s *Store) visible(ctx context.Context, id int) bool {
+	if clinicID, ok := repository.ClinicID(ctx); ok {
+		return s.rowClinics[id] == clinicID
+	}
+	return repository.AllClinics(ctx)
+}
+
+// claim records the clinic owning a new record: the clinic of ctx or, without
+// one, the clinic of the record it belongs to. Callers must hold the write lock.
+func (s *Store) claim(ctx context.Context, id, parentID int) {
+	clinicID, ok := repository.ClinicID(ctx)
+	if !ok {
+		clinicID = s.rowClinics[parentID]
+	}
+	s.rowClinics[id] = clinicID
+}
+
+// patientName resolves a patient's display name; callers must hold a lock
+func (s *Store) patientName(patientID int) string {
+	p, ok := s.patients[patientID]
+	if !ok {
+		return ""
+	}
+	return p.FirstName + " " + p.LastName
+}
+
+// recordEvent appends a status change to the event log; callers must hold the write lock
+func (s *Store) recordEvent(ctx context.Context, appointmentID int, fromStatus, toStatus, reason string) {
+	s.appointmentEvents = append(s.appointmentEvents, models.AppointmentEvent{
+		ID:            s.newID(),
+		AppointmentID: appointmentID,
+		FromStatus:    fromStatus,
+		ToStatus:      toStatus,
+		Reason:        reason,
+		ActorID:       repository.ActorID(ctx),
+		ActorType:     repository.ActorType(ctx),
+		CreatedAt:     s.Now(),
+	})
+}
+
+// deleteAppointment removes an appointment with its events, reminders and link uses; callers must hold the write lock
+func (s *Store) deleteAppointment(id int) {
+	delete(s.appointments, id)
+
+	// Mirror the ON DELETE SET NULL foreign key from slot openings
+	for openingID, o := range s.openings {
+		if o.AppointmentID != nil && *o.AppointmentID == id {
+			o.AppointmentID = nil
+			s.openings[openingID] = o
+		}
+	}
+
+	events := s.appointmentEvents[:0]
+	for _, e := range s.appointmentEvents {
+		if e.AppointmentID != id {
+			events = append(events, e)
+		}
+	}
+	s.appointmentEvents = events
+
+	for reminderID, d := range s.reminders {
+		if d.AppointmentID == id {
+			delete(s.reminders, reminderID)
+		}
+	}
+	for tokenID, u := range s.linkUses {
+		if u.AppointmentID == id {
+			delete(s.linkUses, tokenID)
+		}
+	}
+}
+
+// today returns the current date in the format stored for date columns
+func (s *Store) today() string {
+	return s.Now().Format("2006-01-02")
+}
+
+// Compile-time checks that the memory repositories satisfy the interfaces
+var (
+	_ repository.PatientRepository         = (*PatientRepository)(nil)
+	_ repository.AppointmentRepository     = (*AppointmentRepository)(nil)
+	_ repository.TreatmentRepository       = (*TreatmentRepository)(nil)
+	_ repository.MedicalEntryRepository    = (*MedicalEntryRepository)(nil)
+	_ repository.RiskRepository            = (*RiskRepository)(nil)
+	_ repository.BillingRepository         = (*BillingRepository)(nil)
+	_ repository.ScheduleRepository        = (*ScheduleRepository)(nil)
+	_ repository.SeriesRepository          = (*SeriesRepository)(nil)
+	_ repository.WaitlistRepository        = (*WaitlistRepository)(nil)
+	_ repository.ReminderRepository        = (*ReminderRepository)(nil)
+	_ repository.AppointmentLinkRepository = (*AppointmentLinkRepository)(nil)
+	_ repository.CalendarFeedRepository    = (*CalendarFeedRepository)(nil)
+	_ repository.UserRepository            = (*UserRepository)(nil)
+	_ repository.SessionRepository         = (*SessionRepository)(nil)
+	_ repository.AccountTokenRepository    = (*AccountTokenRepository)(nil)
+	_ repository.AuthPolicyRepository      = (*AuthPolicyRepository)(nil)
+	_ repository.MFARepository             = (*MFARepository)(nil)
+	_ repository.IdentityRepository        = (*IdentityRepository)(nil)
+	_ repository.ClinicRepository          = (*ClinicRepository)(nil)
+	_ repository.AuditRepository           = (*AuditRepository)(nil)
+)
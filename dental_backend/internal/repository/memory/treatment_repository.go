@@ -0,0 +1,281 @@
+// dental_backend/internal/repository/memory/treatment_repository.go
+package memory
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sort"
+
+	"dental_backend/internal/models"
+)
+
+// TreatmentRepository is the in-memory implementation of repository.TreatmentRepository
+type TreatmentRepository struct {
+	store *Store
+}
+
+// NewTreatmentRepository creates a treatment repository backed by the store
+func NewTreatmentRepository(store *Store) *TreatmentRepository {
+	return &TreatmentRepository{store: store}
+}
+
+// List returns every treatment ordered by name
+func (r *TreatmentRepository) List(ctx context.Context) ([]models.Treatment, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	var treatments []models.Treatment
+	for _, t := range r.store.treatments {
+		if r.store.visible(ctx, t.ID) {
+			treatments = append(treatments, t)
+		}
+	}
+	sort.Slice(treatments, func(i, j int) bool { return treatments[i].Name < treatments[j].Name })
+
+	return treatments, nil
+}
+
+// GetByID returns a treatment or nil
+func (r *TreatmentRepository) GetByID(ctx context.Context, id int) (*models.Treatment, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	t, ok := r.store.treatments[id]
+	if !ok || !r.store.visible(ctx, id) {
+		return nil, nil
+	}
+	return &t, nil
+}
+
+// Create stores a new treatment
+func (r *TreatmentRepository) Create(ctx context.Context, req models.CreateTreatmentRequest) (*models.Treatment, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	t := models.Treatment{
+		ID:          r.store.newID(),
+		Name:        req.Name,
+		Description: req.Description,
+		Cost:        req.Cost,
+		Duration:    req.Duration,
+		Category:    req.Category,
+
+		BookableOnline: req.BookableOnline,
+	}
+	r.store.treatments[t.ID] = t
+	r.store.claim(ctx, t.ID, 0)
+
+	return &t, nil
+}
+
+// Update applies the non-zero fields of req
+func (r *TreatmentRepository) Update(ctx context.Context, id int, req models.UpdateTreatmentRequest) (*models.Treatment, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	t, ok := r.store.treatments[id]
+	if !ok || !r.store.visible(ctx, id) {
+		return nil, sql.ErrNoRows
+	}
+
+	setIfNotEmpty(&t.Name, req.Name)
+	setIfNotEmpty(&t.Description, req.Description)
+	if req.Cost != 0 {
+		t.Cost = req.Cost
+	}
+	if req.Duration != 0 {
+		t.Duration = req.Duration
+	}
+	setIfNotEmpty(&t.Category, req.Category)
+	if req.BookableOnline != nil {
+		t.BookableOnline = *req.BookableOnline
+	}
+
+	r.store.treatments[id] = t
+	return &t, nil
+}
+
+// Delete removes a treatment that no patient treatment references
+func (r *TreatmentRepository) Delete(ctx context.Context, id int) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if _, ok := r.store.treatments[id]; !ok || !r.store.visible(ctx, id) {
+		return sql.ErrNoRows
+	}
+
+	// Mirror the ON DELETE RESTRICT foreign key from patient_treatments
+	for _, pt := range r.store.patientTreatments {
+		if pt.TreatmentID == id {
+			return fmt.Errorf("treatment %d is referenced by patient treatment %d", id, pt.ID)
+		}
+	}
+
+	delete(r.store.treatments, id)
+	for claimID, c := range r.store.claims {
+		if c.TreatmentID != nil && *c.TreatmentID == id {
+			c.TreatmentID = nil
+			r.store.claims[claimID] = c
+		}
+	}
+	return nil
+}
+
+// withJoins fills in the joined patient, treatment and dentist names; callers must hold a lock
+func (r *TreatmentRepository) withJoins(pt models.PatientTreatment) models.PatientTreatment {
+	pt.PatientName = r.store.patientName(pt.PatientID)
+	pt.TreatmentName = r.store.treatments[pt.TreatmentID].Name
+	pt.DentistName = nil
+	if pt.DentistID != nil {
+		if u, ok := r.store.users[*pt.DentistID]; ok {
+			name := u.FirstName + " " + u.LastName
+			pt.DentistName = &name
+		}
+	}
+	return pt
+}
+
+// Queue returns pending and in-progress patient treatments by priority then age
+func (r *TreatmentRepository) Queue(ctx context.Context, dentistID *int) ([]models.PatientTreatment, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	var queue []models.PatientTreatment
+	for _, pt := range r.store.patientTreatments {
+		if !r.store.visible(ctx, pt.ID) {
+			continue
+		}
+		if pt.Status != string(models.PatientTreatmentStatusPending) && pt.Status != string(models.PatientTreatmentStatusInProgress) {
+			continue
+		}
+		if dentistID != nil && (pt.DentistID == nil || *pt.DentistID != *dentistID) {
+			continue
+		}
+		queue = append(queue, r.withJoins(pt))
+	}
+
+	sort.Slice(queue, func(i, j int) bool {
+		// Same text ordering as the SQL "ORDER BY pt.priority DESC"
+		if queue[i].Priority != queue[j].Priority {
+			return queue[i].Priority > queue[j].Priority
+		}
+		return queue[i].CreatedAt.Before(queue[j].CreatedAt)
+	})
+
+	return queue, nil
+}
+
+// ListForPatient returns a patient's treatments newest first
+func (r *TreatmentRepository) ListForPatient(ctx context.Context, patientID int) ([]models.PatientTreatment, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	var treatments []models.PatientTreatment
+	for _, pt := range r.store.patientTreatments {
+		if pt.PatientID == patientID && r.store.visible(ctx, pt.ID) {
+			treatments = append(treatments, r.withJoins(pt))
+		}
+	}
+
+	sort.Slice(treatments, func(i, j int) bool { return treatments[i].CreatedAt.After(treatments[j].CreatedAt) })
+
+	return treatments, nil
+}
+
+// GetPatientTreatment returns a patient treatment or nil
+func (r *TreatmentRepository) GetPatientTreatment(ctx context.Context, id int) (*models.PatientTreatment, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	pt, ok := r.store.patientTreatments[id]
+	if !ok || !r.store.visible(ctx, id) {
+		return nil, nil
+	}
+	pt = r.withJoins(pt)
+	return &pt, nil
+}
+
+// CreatePatientTreatment stores a new patient treatment
+func (r *TreatmentRepository) CreatePatientTreatment(ctx context.Context, req models.CreatePatientTreatmentRequest, dentistID int) (*models.PatientTreatment, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if _, ok := r.store.patients[req.PatientID]; !ok || !r.store.visible(ctx, req.PatientID) {
+		return nil, fmt.Errorf("failed to insert patient treatment: patient %d does not exist", req.PatientID)
+	}
+	if _, ok := r.store.treatments[req.TreatmentID]; !ok || !r.store.visible(ctx, req.TreatmentID) {
+		return nil, fmt.Errorf("failed to insert patient treatment: treatment %d does not exist", req.TreatmentID)
+	}
+
+	now := r.store.Now()
+	pt := models.PatientTreatment{
+		ID:          r.store.newID(),
+		PatientID:   req.PatientID,
+		TreatmentID: req.TreatmentID,
+		DentistID:   &dentistID,
+		Status:      req.Status,
+		Priority:    req.Priority,
+		StartDate:   req.StartDate,
+		Notes:       req.Notes,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+	if req.CompletionDate != nil && *req.CompletionDate != "" {
+		completionDate := *req.CompletionDate
+		pt.CompletionDate = &completionDate
+	}
+	r.store.patientTreatments[pt.ID] = pt
+	r.store.claim(ctx, pt.ID, pt.PatientID)
+
+	pt = r.withJoins(pt)
+	return &pt, nil
+}
+
+// UpdatePatientTreatment applies the provided fields of req
+func (r *TreatmentRepository) UpdatePatientTreatment(ctx context.Context, id int, req models.UpdatePatientTreatmentRequest) (*models.PatientTreatment, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	pt, ok := r.store.patientTreatments[id]
+	if !ok || !r.store.visible(ctx, id) {
+		return nil, sql.ErrNoRows
+	}
+
+	if req.PatientID != 0 {
+		pt.PatientID = req.PatientID
+	}
+	if req.TreatmentID != 0 {
+		pt.TreatmentID = req.TreatmentID
+	}
+	setIfNotEmpty(&pt.Status, req.Status)
+	setIfNotEmpty(&pt.Priority, req.Priority)
+	setIfNotEmpty(&pt.StartDate, req.StartDate)
+	if req.CompletionDate != nil {
+		if *req.CompletionDate == "" {
+			pt.CompletionDate = nil
+		} else {
+			completionDate := *req.CompletionDate
+			pt.CompletionDate = &completionDate
+		}
+	}
+	setIfNotEmpty(&pt.Notes, req.Notes)
+	pt.UpdatedAt = r.store.Now()
+
+	r.store.patientTreatments[id] = pt
+
+	pt = r.withJoins(pt)
+	return &pt, nil
+}
+
+// DeletePatientTreatment removes a patient treatment
+func (r *TreatmentRepository) DeletePatientTreatment(ctx context.Context, id int) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if _, ok := r.store.patientTreatments[id]; !ok || !r.store.visible(ctx, id) {
+		return sql.ErrNoRows
+	}
+	delete(r.store.patientTreatments, id)
+	return nil
+}
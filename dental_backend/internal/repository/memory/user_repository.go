@@ -0,0 +1,342 @@
+// dental_backend/internal/repository/memory/user_repository.go
+package memory
+
+import (
+	"context"
+	"database/sql"
+	"sort"
+	"strings"
+
+	"dental_backend/internal/models"
+	"dental_backend/internal/repository"
+)
+
+// UserRepository is the in-memory implementation of repository.UserRepository
+type UserRepository struct {
+	store *Store
+}
+
+// NewUserRepository creates a user repository backed by the store
+func NewUserRepository(store *Store) *UserRepository {
+	return &UserRepository{store: store}
+}
+
+// List returns users ordered by name
+func (r *UserRepository) List(ctx context.Context, filter repository.UserFilter) ([]models.User, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	needle := strings.ToLower(filter.Search)
+
+	var users []models.User
+	for _, u := range r.store.users {
+		u, ok := r.store.member(ctx, u)
+		if !ok {
+			continue
+		}
+		if filter.Role != nil && u.Role != *filter.Role {
+			continue
+		}
+		if filter.Active != nil && u.Active != *filter.Active {
+			continue
+		}
+		if needle != "" &&
+			!strings.Contains(strings.ToLower(u.FirstName), needle) &&
+			!strings.Contains(strings.ToLower(u.LastName), needle) &&
+			!strings.Contains(strings.ToLower(u.Email), needle) {
+			continue
+		}
+		users = append(users, u)
+	}
+
+	sort.Slice(users, func(i, j int) bool {
+		if users[i].LastName != users[j].LastName {
+			return users[i].LastName < users[j].LastName
+		}
+		if users[i].FirstName != users[j].FirstName {
+			return users[i].FirstName < users[j].FirstName
+		}
+		return users[i].ID < users[j].ID
+	})
+
+	return users, nil
+}
+
+// GetByID returns a user or nil when it does not exist
+func (r *UserRepository) GetByID(ctx context.Context, id int) (*models.User, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	u, ok := r.store.users[id]
+	if !ok {
+		return nil, nil
+	}
+	if u, ok = r.store.member(ctx, u); !ok {
+		return nil, nil
+	}
+	return &u, nil
+}
+
+// FindByEmail returns the user with the email address or nil
+func (r *UserRepository) FindByEmail(ctx context.Context, email string) (*models.User, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	for _, u := range r.store.users {
+		if u.Email == email {
+			return &u, nil
+		}
+	}
+	return nil, nil
+}
+
+// emailTaken reports whether another user has the email address; callers must hold a lock
+func (r *UserRepository) emailTaken(email string, exceptID int) bool {
+	for _, u := range r.store.users {
+		if u.ID != exceptID && u.Email == email {
+			return true
+		}
+	}
+	return false
+}
+
+// Create stores a new user
+func (r *UserRepository) Create(ctx context.Context, req models.CreateUserRequest, passwordHash string) (*models.User, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if r.emailTaken(req.Email, 0) {
+		return nil, repository.ErrEmailTaken
+	}
+
+	now := r.store.Now()
+	u := models.User{
+		ID:        r.store.newID(),
+		Email:     req.Email,
+		Password:  passwordHash,
+		FirstName: req.FirstName,
+		LastName:  req.LastName,
+		Role:      req.Role,
+		Phone:     req.Phone,
+		CreatedAt: now,
+		UpdatedAt: now,
+		Active:    true,
+	}
+	r.store.users[u.ID] = u
+	r.store.joinClinic(ctx, u)
+
+	return &u, nil
+}
+
+// Update applies the non-empty fields of req. With a clinic in ctx the role is
+// changed on the user's membership of it.
+func (r *UserRepository) Update(ctx context.Context, id int, req models.UpdateUserRequest) (*models.User, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	u, ok := r.store.users[id]
+	if !ok {
+		return nil, nil
+	}
+	if _, ok := r.store.member(ctx, u); !ok {
+		return nil, nil
+	}
+	if req.Email != "" && r.emailTaken(req.Email, id) {
+		return nil, repository.ErrEmailTaken
+	}
+
+	if clinicID, ok := repository.ClinicID(ctx); ok {
+		key := membershipKey{clinicID: clinicID, userID: id}
+		row := r.store.memberships[key]
+		setIfNotEmpty(&row.role, req.Role)
+		r.store.memberships[key] = row
+		req.Role = ""
+	}
+
+	if req.Email != "" && req.Email != u.Email {
+		u.EmailVerified = false
+	}
+	setIfNotEmpty(&u.Email, req.Email)
+	setIfNotEmpty(&u.FirstName, req.FirstName)
+	setIfNotEmpty(&u.LastName, req.LastName)
+	setIfNotEmpty(&u.Role, req.Role)
+	setIfNotEmpty(&u.Phone, req.Phone)
+	u.UpdatedAt = r.store.Now()
+
+	r.store.users[id] = u
+	u, _ = r.store.member(ctx, u)
+	return &u, nil
+}
+
+// SetPassword replaces the user's password hash
+func (r *UserRepository) SetPassword(ctx context.Context, id int, passwordHash string) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	u, ok := r.store.users[id]
+	if !ok {
+		return sql.ErrNoRows
+	}
+	u.Password = passwordHash
+	u.UpdatedAt = r.store.Now()
+	r.store.users[id] = u
+	return nil
+}
+
+// SetEmailVerified marks the user's email address as verified or not
+func (r *UserRepository) SetEmailVerified(ctx context.Context, id int, verified bool) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	u, ok := r.store.users[id]
+	if !ok {
+		return sql.ErrNoRows
+	}
+	u.EmailVerified = verified
+	u.UpdatedAt = r.store.Now()
+	r.store.users[id] = u
+	return nil
+}
+
+// SetActive clears or stamps the deactivation time; an already deactivated user keeps the original time
+func (r *UserRepository) SetActive(ctx context.Context, id int, active bool) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	u, ok := r.store.users[id]
+	if !ok {
+		return sql.ErrNoRows
+	}
+	if _, ok := r.store.member(ctx, u); !ok {
+		return sql.ErrNoRows
+	}
+
+	now := r.store.Now()
+	if active {
+		u.DeactivatedAt = nil
+	} else if u.DeactivatedAt == nil {
+		u.DeactivatedAt = &now
+	}
+	u.Active = u.DeactivatedAt == nil
+	u.UpdatedAt = now
+	r.store.users[id] = u
+	return nil
+}
+
+// Delete removes a user, refusing while appointments or series still reference
+// them or, with a clinic in ctx, they work at another clinic
+func (r *UserRepository) Delete(ctx context.Context, id int) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	u, ok := r.store.users[id]
+	if !ok {
+		return sql.ErrNoRows
+	}
+	if _, ok := r.store.member(ctx, u); !ok {
+		return sql.ErrNoRows
+	}
+	if clinicID, ok := repository.ClinicID(ctx); ok {
+		for key := range r.store.memberships {
+			if key.userID == id && key.clinicID != clinicID {
+				return repository.ErrUserInUse
+			}
+		}
+	}
+
+	// Mirror the ON DELETE RESTRICT foreign keys
+	for _, a := range r.store.appointments {
+		if a.DentistID == id {
+			return repository.ErrUserInUse
+		}
+	}
+	for _, series := range r.store.series {
+		if series.DentistID == id {
+			return repository.ErrUserInUse
+		}
+	}
+
+	delete(r.store.users, id)
+
+	// Mirror the ON DELETE CASCADE and SET NULL foreign keys
+	for key := range r.store.workingHours {
+		if key.userID == id {
+			delete(r.store.workingHours, key)
+		}
+	}
+	for key := range r.store.breaks {
+		if key.userID == id {
+			delete(r.store.breaks, key)
+		}
+	}
+	for closureID, c := range r.store.closures {
+		if c.DentistID != nil && *c.DentistID == id {
+			delete(r.store.closures, closureID)
+		}
+	}
+	for ptID, pt := range r.store.patientTreatments {
+		if pt.DentistID != nil && *pt.DentistID == id {
+			pt.DentistID = nil
+			r.store.patientTreatments[ptID] = pt
+		}
+	}
+	for entryID, e := range r.store.medicalEntries {
+		if e.RecordedBy != nil && *e.RecordedBy == id {
+			e.RecordedBy = nil
+			r.store.medicalEntries[entryID] = e
+		}
+	}
+	for i, e := range r.store.appointmentEvents {
+		if e.ActorID != nil && *e.ActorID == id {
+			r.store.appointmentEvents[i].ActorID = nil
+		}
+	}
+	for openingID, o := range r.store.openings {
+		if o.DentistID == id {
+			delete(r.store.openings, openingID)
+		}
+	}
+	for feedID, row := range r.store.calendarFeeds {
+		if row.feed.UserID == id {
+			delete(r.store.calendarFeeds, feedID)
+		}
+	}
+	for sessionID, row := range r.store.sessions {
+		if row.session.UserID == id {
+			delete(r.store.sessions, sessionID)
+		}
+	}
+	delete(r.store.mfa, id)
+	for key := range r.store.memberships {
+		if key.userID == id {
+			delete(r.store.memberships, key)
+		}
+	}
+	for identityID, identity := range r.store.identities {
+		if identity.UserID == id {
+			delete(r.store.identities, identityID)
+		}
+	}
+	for tokenHash, row := range r.store.accountTokens {
+		if row.UserID == id {
+			delete(r.store.accountTokens, tokenHash)
+		}
+	}
+
+	return nil
+}
+
+// CountActiveAdmins counts active users with the admin role
+func (r *UserRepository) CountActiveAdmins(ctx context.Context) (int, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	count := 0
+	for _, u := range r.store.users {
+		u, ok := r.store.member(ctx, u)
+		if ok && u.Role == string(models.UserRoleAdmin) && u.DeactivatedAt == nil {
+			count++
+		}
+	}
+	return count, nil
+}
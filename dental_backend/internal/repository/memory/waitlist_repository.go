@@ -0,0 +1,249 @@
+// dental_backend/internal/repository/memory/waitlist_repository.go
+package memory
+
+import (
+	"context"
+	"database/sql"
+	"sort"
+	"time"
+
+	"dental_backend/internal/models"
+	"dental_backend/internal/repository"
+)
+
+// WaitlistRepository is the in-memory implementation of repository.WaitlistRepository
+type WaitlistRepository struct {
+	store *Store
+}
+
+// NewWaitlistRepository creates a waitlist repository backed by the store
+func NewWaitlistRepository(store *Store) *WaitlistRepository {
+	return &WaitlistRepository{store: store}
+}
+
+// acceptsDentist reports whether the entry would see the dentist
+func acceptsDentist(entry models.WaitlistEntry, dentistID int) bool {
+	if len(entry.DentistIDs) == 0 {
+		return true
+	}
+	for _, id := range entry.DentistIDs {
+		if id == dentistID {
+			return true
+		}
+	}
+	return false
+}
+
+// ListEntries returns waitlist entries matching the filter, longest waiting first
+func (r *WaitlistRepository) ListEntries(ctx context.Context, filter repository.WaitlistFilter) ([]models.WaitlistEntry, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	var entries []models.WaitlistEntry
+	for _, e := range r.store.waitlist {
+		if !r.store.visible(ctx, e.ID) {
+			continue
+		}
+		if filter.Status != nil && e.Status != *filter.Status {
+			continue
+		}
+		if filter.DentistID != nil && !acceptsDentist(e, *filter.DentistID) {
+			continue
+		}
+		if filter.PatientID != nil && e.PatientID != *filter.PatientID {
+			continue
+		}
+		e.PatientName = r.store.patientName(e.PatientID)
+		entries = append(entries, e)
+	}
+
+	sort.Slice(entries, func(i, j int) bool {
+		if entries[i].CreatedAt.Equal(entries[j].CreatedAt) {
+			return entries[i].ID < entries[j].ID
+		}
+		return entries[i].CreatedAt.Before(entries[j].CreatedAt)
+	})
+
+	return entries, nil
+}
+
+// GetEntry returns a waitlist entry or nil
+func (r *WaitlistRepository) GetEntry(ctx context.Context, id int) (*models.WaitlistEntry, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	e, ok := r.store.waitlist[id]
+	if !ok || !r.store.visible(ctx, id) {
+		return nil, nil
+	}
+	e.PatientName = r.store.patientName(e.PatientID)
+	return &e, nil
+}
+
+// CreateEntry stores a new waitlist entry
+func (r *WaitlistRepository) CreateEntry(ctx context.Context, entry models.WaitlistEntry) (*models.WaitlistEntry, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	now := r.store.Now()
+	entry.ID = r.store.newID()
+	entry.CreatedAt = now
+	entry.UpdatedAt = now
+	r.store.waitlist[entry.ID] = entry
+	r.store.claim(ctx, entry.ID, entry.PatientID)
+
+	entry.PatientName = r.store.patientName(entry.PatientID)
+	return &entry, nil
+}
+
+// UpdateEntry replaces the preferences and status of a waitlist entry
+func (r *WaitlistRepository) UpdateEntry(ctx context.Context, entry models.WaitlistEntry) (*models.WaitlistEntry, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	existing, ok := r.store.waitlist[entry.ID]
+	if !ok || !r.store.visible(ctx, entry.ID) {
+		return nil, nil
+	}
+
+	existing.DentistIDs = entry.DentistIDs
+	existing.Weekdays = entry.Weekdays
+	existing.EarliestTime = entry.EarliestTime
+	existing.LatestTime = entry.LatestTime
+	existing.Duration = entry.Duration
+	existing.Urgency = entry.Urgency
+	existing.Status = entry.Status
+	existing.Notes = entry.Notes
+	existing.UpdatedAt = r.store.Now()
+	r.store.waitlist[entry.ID] = existing
+
+	existing.PatientName = r.store.patientName(existing.PatientID)
+	return &existing, nil
+}
+
+// DeleteEntry removes a waitlist entry
+func (r *WaitlistRepository) DeleteEntry(ctx context.Context, id int) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if _, ok := r.store.waitlist[id]; !ok || !r.store.visible(ctx, id) {
+		return sql.ErrNoRows
+	}
+	delete(r.store.waitlist, id)
+
+	// Mirror the ON DELETE SET NULL foreign key
+	for openingID, o := range r.store.openings {
+		if o.HeldEntryID != nil && *o.HeldEntryID == id {
+			o.HeldEntryID = nil
+			r.store.openings[openingID] = o
+		}
+	}
+	return nil
+}
+
+// CreateOpening stores a freed slot
+func (r *WaitlistRepository) CreateOpening(ctx context.Context, opening models.SlotOpening) (*models.SlotOpening, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	now := r.store.Now()
+	opening.ID = r.store.newID()
+	opening.CreatedAt = now
+	opening.UpdatedAt = now
+	r.store.openings[opening.ID] = opening
+	parentID := 0
+	if opening.AppointmentID != nil {
+		parentID = *opening.AppointmentID
+	}
+	r.store.claim(ctx, opening.ID, parentID)
+
+	return &opening, nil
+}
+
+// GetOpening returns an opening, restricted to a dentist when dentistID is set, or nil
+func (r *WaitlistRepository) GetOpening(ctx context.Context, id int, dentistID *int) (*models.SlotOpening, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	o, ok := r.store.openings[id]
+	if !ok || !r.store.visible(ctx, id) || (dentistID != nil && o.DentistID != *dentistID) {
+		return nil, nil
+	}
+	return &o, nil
+}
+
+// ListOpenings returns unfilled openings on or after a date ordered by date and time
+func (r *WaitlistRepository) ListOpenings(ctx context.Context, dentistID *int, from string) ([]models.SlotOpening, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	var openings []models.SlotOpening
+	for _, o := range r.store.openings {
+		if !r.store.visible(ctx, o.ID) {
+			continue
+		}
+		if o.Status == string(models.SlotOpeningFilled) || o.AppointmentDate < from {
+			continue
+		}
+		if dentistID != nil && o.DentistID != *dentistID {
+			continue
+		}
+		openings = append(openings, o)
+	}
+
+	sortOpenings(openings)
+	return openings, nil
+}
+
+// UpdateOpening replaces the status and hold of an opening
+func (r *WaitlistRepository) UpdateOpening(ctx context.Context, opening models.SlotOpening) (*models.SlotOpening, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	existing, ok := r.store.openings[opening.ID]
+	if !ok || !r.store.visible(ctx, opening.ID) {
+		return nil, nil
+	}
+
+	existing.Status = opening.Status
+	existing.HeldEntryID = opening.HeldEntryID
+	existing.HoldExpiresAt = opening.HoldExpiresAt
+	existing.UpdatedAt = r.store.Now()
+	r.store.openings[opening.ID] = existing
+
+	return &existing, nil
+}
+
+// Holds returns the dentist's openings still held at now that intersect the window
+func (r *WaitlistRepository) Holds(ctx context.Context, dentistID int, window models.AppointmentWindow, now time.Time) ([]models.SlotOpening, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	var openings []models.SlotOpening
+	for _, o := range r.store.openings {
+		if !r.store.visible(ctx, o.ID) {
+			continue
+		}
+		if o.DentistID != dentistID || !o.IsHeld(now) {
+			continue
+		}
+		other, err := o.Window()
+		if err != nil || !window.Overlaps(other) {
+			continue
+		}
+		openings = append(openings, o)
+	}
+
+	sortOpenings(openings)
+	return openings, nil
+}
+
+// sortOpenings orders openings by date and start time
+func sortOpenings(openings []models.SlotOpening) {
+	sort.Slice(openings, func(i, j int) bool {
+		if openings[i].AppointmentDate != openings[j].AppointmentDate {
+			return openings[i].AppointmentDate < openings[j].AppointmentDate
+		}
+		return openings[i].StartTime < openings[j].StartTime
+	})
+}
@@ -0,0 +1,72 @@
+// dental_backend/internal/repository/postgres/account_token_repository.go
+package postgres
+
+import (
+	"context"
+	"database/sql"
+
+	"dental_backend/internal/models"
+)
+
+const accountTokenColumns = `id, user_id, purpose, expires_at, used_at, created_at`
+
+// AccountTokenRepository is the PostgreSQL implementation of repository.AccountTokenRepository
+type AccountTokenRepository struct {
+	db *sql.DB
+}
+
+// NewAccountTokenRepository creates a new PostgreSQL account token repository
+func NewAccountTokenRepository(db *sql.DB) *AccountTokenRepository {
+	return &AccountTokenRepository{db: db}
+}
+
+// scanAccountToken scans a row selected with accountTokenColumns
+func scanAccountToken(row interface{ Scan(...interface{}) error }, t *models.AccountToken) error {
+	return row.Scan(&t.ID, &t.UserID, &t.Purpose, &t.ExpiresAt, &t.UsedAt, &t.CreatedAt)
+}
+
+// Create inserts a token and supersedes the user's unused tokens for the same purpose in one transaction
+func (r *AccountTokenRepository) Create(ctx context.Context, token models.AccountToken, tokenHash string) (*models.AccountToken, error) {
+	tx, err := conn(ctx, r.db).BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, `
+		UPDATE account_tokens SET used_at = NOW()
+		WHERE user_id = $1 AND purpose = $2 AND used_at IS NULL`,
+		token.UserID, token.Purpose); err != nil {
+		return nil, err
+	}
+
+	var created models.AccountToken
+	err = scanAccountToken(tx.QueryRowContext(ctx, `
+		INSERT INTO account_tokens (user_id, purpose, token_hash, expires_at)
+		VALUES ($1, $2, $3, $4)
+		RETURNING `+accountTokenColumns,
+		token.UserID, token.Purpose, tokenHash, token.ExpiresAt), &created)
+	if err != nil {
+		return nil, err
+	}
+
+	return &created, tx.Commit()
+}
+
+// Consume marks an unused, unexpired token as used; the conditional update lets only one caller win
+func (r *AccountTokenRepository) Consume(ctx context.Context, purpose models.AccountTokenPurpose, tokenHash string) (*models.AccountToken, error) {
+	var token models.AccountToken
+	err := scanAccountToken(conn(ctx, r.db).QueryRowContext(ctx, `
+		UPDATE account_tokens SET used_at = NOW()
+		WHERE token_hash = $1 AND purpose = $2 AND used_at IS NULL AND expires_at > NOW()
+		RETURNING `+accountTokenColumns,
+		tokenHash, purpose), &token)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return &token, nil
+}
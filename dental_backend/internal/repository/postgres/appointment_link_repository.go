@@ -0,0 +1,53 @@
+// dental_backend/internal/repository/postgres/appointment_link_repository.go
+package postgres
+
+import (
+	"context"
+	"database/sql"
+
+	"dental_backend/internal/models"
+)
+
+// AppointmentLinkRepository is the PostgreSQL implementation of repository.AppointmentLinkRepository
+type AppointmentLinkRepository struct {
+	db *sql.DB
+}
+
+// NewAppointmentLinkRepository creates a new PostgreSQL appointment link repository
+func NewAppointmentLinkRepository(db *sql.DB) *AppointmentLinkRepository {
+	return &AppointmentLinkRepository{db: db}
+}
+
+// Use records a token as spent, relying on the primary key so concurrent requests cannot both spend it
+func (r *AppointmentLinkRepository) Use(ctx context.Context, use models.AppointmentLinkUse) (bool, error) {
+	result, err := conn(ctx, r.db).ExecContext(ctx, `
+		INSERT INTO appointment_link_uses (token_id, appointment_id, action)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (token_id) DO NOTHING`,
+		use.TokenID, use.AppointmentID, use.Action)
+	if err != nil {
+		return false, err
+	}
+
+	if err := expectRows(result); err != nil {
+		if err == sql.ErrNoRows {
+			return false, nil
+		}
+		return false, err
+	}
+	return true, nil
+}
+
+// Used reports whether a token has been spent
+func (r *AppointmentLinkRepository) Used(ctx context.Context, tokenID string) (bool, error) {
+	var used bool
+	err := conn(ctx, r.db).QueryRowContext(ctx, `
+		SELECT EXISTS (SELECT 1 FROM appointment_link_uses WHERE token_id = $1)`, tokenID).Scan(&used)
+	return used, err
+}
+
+// Release deletes the record of a spent token
+func (r *AppointmentLinkRepository) Release(ctx context.Context, tokenID string) error {
+	_, err := conn(ctx, r.db).ExecContext(ctx, `DELETE FROM appointment_link_uses WHERE token_id = $1`, tokenID)
+	return err
+}
@@ -0,0 +1,382 @@
+// dental_backend/internal/repository/postgres/appointment_repository.go
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"strconv"
+	"strings"
+
+	"dental_backend/internal/models"
+	"dental_backend/internal/repository"
+)
+
+const appointmentSelect = `
+		SELECT a.id, a.clinic_id, a.patient_id, a.dentist_id,
+		       p.first_name || ' ' || p.last_name as patient_name,
+		       a.appointment_date, a.start_time, a.end_time, a.duration_minutes, a.treatment_id, a.series_id,
+		       a.status, a.notes, a.sequence, a.created_at, a.updated_at,
+		       a.cancellation_reason, a.confirmed_at, a.checked_in_at, a.in_chair_at,
+		       a.completed_at, a.cancelled_at, a.no_show_at
+		FROM appointments a
+		JOIN patients p ON a.patient_id = p.id`
+
+const appointmentReturning = `
+		RETURNING id, clinic_id, patient_id, dentist_id,
+		          (SELECT first_name || ' ' || last_name FROM patients WHERE id = patient_id),
+		          appointment_date, start_time, end_time, duration_minutes, treatment_id, series_id,
+		          status, notes, sequence, created_at, updated_at,
+		          cancellation_reason, confirmed_at, checked_in_at, in_chair_at,
+		          completed_at, cancelled_at, no_show_at`
+
+// statusTimestampColumns maps each status to the column stamped when an appointment enters it
+var statusTimestampColumns = map[string]string{
+	string(models.AppointmentStatusConfirmed): "confirmed_at",
+	string(models.AppointmentStatusCheckedIn): "checked_in_at",
+	string(models.AppointmentStatusInChair):   "in_chair_at",
+	string(models.AppointmentStatusCompleted): "completed_at",
+	string(models.AppointmentStatusCancelled): "cancelled_at",
+	string(models.AppointmentStatusNoShow):    "no_show_at",
+}
+
+// AppointmentRepository is the PostgreSQL implementation of repository.AppointmentRepository
+type AppointmentRepository struct {
+	db *sql.DB
+}
+
+// NewAppointmentRepository creates a new PostgreSQL appointment repository
+func NewAppointmentRepository(db *sql.DB) *AppointmentRepository {
+	return &AppointmentRepository{db: db}
+}
+
+// scanAppointment scans a row selected with appointmentSelect or appointmentReturning
+func scanAppointment(row interface{ Scan(...interface{}) error }, a *models.Appointment) error {
+	return row.Scan(
+		&a.ID, &a.ClinicID, &a.PatientID, &a.DentistID, &a.PatientName,
+		&a.AppointmentDate, &a.StartTime, &a.EndTime, &a.Duration, &a.TreatmentID, &a.SeriesID,
+		&a.Status, &a.Notes, &a.Sequence,
+		&a.CreatedAt, &a.UpdatedAt,
+		&a.CancellationReason, &a.ConfirmedAt, &a.CheckedInAt, &a.InChairAt,
+		&a.CompletedAt, &a.CancelledAt, &a.NoShowAt,
+	)
+}
+
+// List retrieves appointments matching the filter
+func (r *AppointmentRepository) List(ctx context.Context, filter repository.AppointmentFilter) ([]models.Appointment, error) {
+	query := appointmentSelect + `
+		WHERE 1=1`
+
+	args := []interface{}{}
+	argIndex := 1
+
+	if filter.DentistID != nil {
+		query += " AND a.dentist_id = $" + strconv.Itoa(argIndex)
+		args = append(args, *filter.DentistID)
+		argIndex++
+	}
+
+	if filter.Date != nil {
+		query += " AND a.appointment_date = $" + strconv.Itoa(argIndex)
+		args = append(args, *filter.Date)
+		argIndex++
+	}
+
+	if filter.From != nil {
+		query += " AND a.appointment_date >= $" + strconv.Itoa(argIndex)
+		args = append(args, *filter.From)
+		argIndex++
+	}
+
+	if filter.To != nil {
+		query += " AND a.appointment_date <= $" + strconv.Itoa(argIndex)
+		args = append(args, *filter.To)
+		argIndex++
+	}
+
+	if filter.Status != nil {
+		query += " AND a.status = $" + strconv.Itoa(argIndex)
+		args = append(args, *filter.Status)
+		argIndex++
+	}
+
+	if filter.PatientID != nil {
+		query += " AND a.patient_id = $" + strconv.Itoa(argIndex)
+		args = append(args, *filter.PatientID)
+		argIndex++
+	}
+
+	if filter.SeriesID != nil {
+		query += " AND a.series_id = $" + strconv.Itoa(argIndex)
+		args = append(args, *filter.SeriesID)
+		argIndex++
+	}
+
+	query += " ORDER BY a.appointment_date ASC, a.start_time ASC"
+
+	rows, err := conn(ctx, r.db).QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var appointments []models.Appointment
+	for rows.Next() {
+		var a models.Appointment
+		if err := scanAppointment(rows, &a); err != nil {
+			return nil, err
+		}
+		appointments = append(appointments, a)
+	}
+
+	return appointments, rows.Err()
+}
+
+// GetByID retrieves a specific appointment by ID, restricted to a dentist when dentistID is set
+func (r *AppointmentRepository) GetByID(ctx context.Context, id int, dentistID *int) (*models.Appointment, error) {
+	query := appointmentSelect + `
+		WHERE a.id = $1`
+	args := []interface{}{id}
+	if dentistID != nil {
+		query += " AND a.dentist_id = $2"
+		args = append(args, *dentistID)
+	}
+
+	var appointment models.Appointment
+	err := scanAppointment(conn(ctx, r.db).QueryRowContext(ctx, query, args...), &appointment)
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return &appointment, nil
+}
+
+// insertEvent records a status change inside tx
+func insertEvent(ctx context.Context, tx *sql.Tx, appointmentID int, fromStatus, toStatus, reason string) error {
+	_, err := tx.ExecContext(ctx, `
+		INSERT INTO appointment_events (appointment_id, from_status, to_status, reason, actor_id, actor_type)
+		VALUES ($1, $2, $3, $4, $5, $6)`,
+		appointmentID, fromStatus, toStatus, reason, repository.ActorID(ctx), repository.ActorType(ctx))
+	return err
+}
+
+// Create inserts a new appointment for a dentist
+func (r *AppointmentRepository) Create(ctx context.Context, req models.CreateAppointmentRequest, dentistID int) (*models.Appointment, error) {
+	tx, err := conn(ctx, r.db).BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	query := `
+		INSERT INTO appointments (
+			patient_id, dentist_id, appointment_date, start_time, duration_minutes, treatment_id,
+			series_id, status, notes, created_at, updated_at`
+	values := `$1, $2, $3, $4, $5, $6, $7, $8, $9, NOW(), NOW()`
+	if column, ok := statusTimestampColumns[req.Status]; ok {
+		query += ", " + column
+		values += ", NOW()"
+	}
+
+	var newAppointment models.Appointment
+	err = scanAppointment(tx.QueryRowContext(ctx, query+") VALUES ("+values+")"+appointmentReturning,
+		req.PatientID, dentistID, req.AppointmentDate, req.StartTime, req.Duration, req.TreatmentID,
+		req.SeriesID, req.Status, req.Notes,
+	), &newAppointment)
+
+	if err != nil {
+		if isExclusionViolation(err) {
+			return nil, repository.ErrOverlap
+		}
+		return nil, err
+	}
+
+	if err := insertEvent(ctx, tx, newAppointment.ID, "", newAppointment.Status, ""); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return &newAppointment, nil
+}
+
+// Update updates an existing appointment for a dentist
+func (r *AppointmentRepository) Update(ctx context.Context, id int, req models.UpdateAppointmentRequest, dentistID int) (*models.Appointment, error) {
+	tx, err := conn(ctx, r.db).BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	// Lock the row so the status we compare against cannot change underneath us
+	var currentStatus string
+	err = tx.QueryRowContext(ctx,
+		"SELECT status FROM appointments WHERE id = $1 AND dentist_id = $2 FOR UPDATE",
+		id, dentistID).Scan(&currentStatus)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+	if req.FromStatus != "" && req.FromStatus != currentStatus {
+		return nil, repository.ErrStaleStatus
+	}
+	statusChanged := req.Status != "" && req.Status != currentStatus
+
+	setClauses := []string{"updated_at = NOW()"}
+	args := []interface{}{}
+	argIndex := 1
+
+	// revised collects the conditions under which calendar clients must see a new
+	// revision; SET expressions compare against the row's values before the update
+	var revised []string
+
+	if req.PatientID != 0 {
+		setClauses = append(setClauses, "patient_id = $"+strconv.Itoa(argIndex))
+		args = append(args, req.PatientID)
+		argIndex++
+	}
+	if req.AppointmentDate != "" {
+		setClauses = append(setClauses, "appointment_date = $"+strconv.Itoa(argIndex))
+		revised = append(revised, "appointment_date <> $"+strconv.Itoa(argIndex)+"::date")
+		args = append(args, req.AppointmentDate)
+		argIndex++
+	}
+	if req.StartTime != "" {
+		setClauses = append(setClauses, "start_time = $"+strconv.Itoa(argIndex))
+		revised = append(revised, "start_time <> $"+strconv.Itoa(argIndex)+"::time")
+		args = append(args, req.StartTime)
+		argIndex++
+	}
+	if req.Duration != 0 {
+		setClauses = append(setClauses, "duration_minutes = $"+strconv.Itoa(argIndex))
+		revised = append(revised, "duration_minutes <> $"+strconv.Itoa(argIndex)+"::integer")
+		args = append(args, req.Duration)
+		argIndex++
+	}
+	if req.TreatmentID != nil {
+		setClauses = append(setClauses, "treatment_id = $"+strconv.Itoa(argIndex))
+		args = append(args, *req.TreatmentID)
+		argIndex++
+	}
+	if statusChanged {
+		setClauses = append(setClauses, "status = $"+strconv.Itoa(argIndex))
+		args = append(args, req.Status)
+		argIndex++
+
+		if column, ok := statusTimestampColumns[req.Status]; ok {
+			setClauses = append(setClauses, column+" = NOW()")
+		}
+		if req.Status == string(models.AppointmentStatusCancelled) {
+			revised = append(revised, "TRUE")
+			setClauses = append(setClauses, "cancellation_reason = $"+strconv.Itoa(argIndex))
+			args = append(args, req.CancellationReason)
+			argIndex++
+		}
+	}
+	if req.Notes != "" {
+		setClauses = append(setClauses, "notes = $"+strconv.Itoa(argIndex))
+		args = append(args, req.Notes)
+		argIndex++
+	}
+	if len(revised) > 0 {
+		setClauses = append(setClauses, "sequence = sequence + CASE WHEN "+strings.Join(revised, " OR ")+" THEN 1 ELSE 0 END")
+	}
+
+	// dentistID and id go LAST - but we need to track their indices properly
+	dentistIDIndex := argIndex
+	idIndex := argIndex + 1
+	args = append(args, dentistID, id)
+
+	query := "UPDATE appointments SET " +
+		strings.Join(setClauses, ", ") +
+		" WHERE dentist_id = $" + strconv.Itoa(dentistIDIndex) +
+		" AND id = $" + strconv.Itoa(idIndex) +
+		appointmentReturning
+
+	var updatedAppointment models.Appointment
+	err = scanAppointment(tx.QueryRowContext(ctx, query, args...), &updatedAppointment)
+	if err != nil {
+		if isExclusionViolation(err) {
+			return nil, repository.ErrOverlap
+		}
+		return nil, err
+	}
+
+	if statusChanged {
+		if err := insertEvent(ctx, tx, id, currentStatus, req.Status, req.CancellationReason); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return &updatedAppointment, nil
+}
+
+// Events retrieves the status history of an appointment, oldest first
+func (r *AppointmentRepository) Events(ctx context.Context, appointmentID int) ([]models.AppointmentEvent, error) {
+	rows, err := conn(ctx, r.db).QueryContext(ctx, `
+		SELECT id, appointment_id, from_status, to_status, reason, actor_id, actor_type, created_at
+		FROM appointment_events
+		WHERE appointment_id = $1
+		ORDER BY created_at ASC, id ASC`,
+		appointmentID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var events []models.AppointmentEvent
+	for rows.Next() {
+		var e models.AppointmentEvent
+		if err := rows.Scan(&e.ID, &e.AppointmentID, &e.FromStatus, &e.ToStatus, &e.Reason, &e.ActorID, &e.ActorType, &e.CreatedAt); err != nil {
+			return nil, err
+		}
+		events = append(events, e)
+	}
+
+	return events, rows.Err()
+}
+
+// Delete deletes an appointment for a dentist
+func (r *AppointmentRepository) Delete(ctx context.Context, id int, dentistID int) error {
+	result, err := conn(ctx, r.db).ExecContext(ctx, "DELETE FROM appointments WHERE id = $1 AND dentist_id = $2", id, dentistID)
+	if err != nil {
+		return err
+	}
+
+	return expectRows(result)
+}
+
+// Overlapping retrieves the dentist's active appointments that intersect the window
+func (r *AppointmentRepository) Overlapping(ctx context.Context, dentistID int, window models.AppointmentWindow, excludeID int) ([]models.Appointment, error) {
+	rows, err := conn(ctx, r.db).QueryContext(ctx, appointmentSelect+`
+		WHERE a.dentist_id = $1 AND a.appointment_date = $2
+		  AND a.start_time < $3 AND a.end_time > $4
+		  AND a.status NOT IN ('cancelled', 'no-show')
+		  AND a.id <> $5
+		ORDER BY a.start_time ASC`,
+		dentistID, window.Date, models.FormatClock(window.End), models.FormatClock(window.Start), excludeID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var appointments []models.Appointment
+	for rows.Next() {
+		var a models.Appointment
+		if err := scanAppointment(rows, &a); err != nil {
+			return nil, err
+		}
+		appointments = append(appointments, a)
+	}
+
+	return appointments, rows.Err()
+}
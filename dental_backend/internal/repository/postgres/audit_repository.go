@@ -0,0 +1,147 @@
+// dental_backend/internal/repository/postgres/audit_repository.go
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"strconv"
+
+	"dental_backend/internal/audit"
+	"dental_backend/internal/models"
+	"dental_backend/internal/repository"
+)
+
+// auditChainLockID is the advisory lock key held while an entry is appended, so
+// that concurrent requests extend the chain one at a time
+const auditChainLockID = 482311908
+
+const auditColumns = `id, occurred_at, clinic_id, actor_id, actor_role, action, resource_type, resource_id,
+	method, route, status, changes, request_id, ip_address, prev_hash, hash`
+
+// AuditRepository is the PostgreSQL implementation of repository.AuditRepository
+type AuditRepository struct {
+	db *sql.DB
+}
+
+// NewAuditRepository creates a new PostgreSQL audit repository
+func NewAuditRepository(db *sql.DB) *AuditRepository {
+	return &AuditRepository{db: db}
+}
+
+// scanAuditEntry scans a row selected with auditColumns
+func scanAuditEntry(row interface{ Scan(...interface{}) error }, e *models.AuditEntry) error {
+	var changes []byte
+	err := row.Scan(&e.ID, &e.OccurredAt, &e.ClinicID, &e.ActorID, &e.ActorRole, &e.Action, &e.ResourceType, &e.ResourceID,
+		&e.Method, &e.Route, &e.Status, &changes, &e.RequestID, &e.IPAddress, &e.PrevHash, &e.Hash)
+	if err != nil {
+		return err
+	}
+	e.Changes = changes
+	return nil
+}
+
+// Append seals entry onto the end of the chain
+func (r *AuditRepository) Append(ctx context.Context, entry models.AuditEntry) (*models.AuditEntry, error) {
+	tx, err := conn(ctx, r.db).BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, "SELECT pg_advisory_xact_lock($1)", auditChainLockID); err != nil {
+		return nil, err
+	}
+
+	err = tx.QueryRowContext(ctx, "SELECT hash FROM audit_log ORDER BY id DESC LIMIT 1").Scan(&entry.PrevHash)
+	if err == sql.ErrNoRows {
+		entry.PrevHash = audit.GenesisHash
+	} else if err != nil {
+		return nil, err
+	}
+	entry.Hash = audit.Hash(entry)
+
+	var changes interface{}
+	if len(entry.Changes) > 0 {
+		changes = string(entry.Changes)
+	}
+	err = tx.QueryRowContext(ctx, `
+		INSERT INTO audit_log (occurred_at, clinic_id, actor_id, actor_role, action, resource_type, resource_id,
+			method, route, status, changes, request_id, ip_address, prev_hash, hash)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15)
+		RETURNING id`,
+		entry.OccurredAt, entry.ClinicID, entry.ActorID, entry.ActorRole, entry.Action, entry.ResourceType, entry.ResourceID,
+		entry.Method, entry.Route, entry.Status, changes, entry.RequestID, entry.IPAddress, entry.PrevHash, entry.Hash,
+	).Scan(&entry.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return &entry, nil
+}
+
+// List retrieves matching entries, newest first
+func (r *AuditRepository) List(ctx context.Context, filter repository.AuditFilter) ([]models.AuditEntry, error) {
+	query := "SELECT " + auditColumns + " FROM audit_log WHERE 1=1"
+	args := []interface{}{}
+	argIndex := 1
+
+	add := func(condition string, value interface{}) {
+		query += " AND " + condition + " $" + strconv.Itoa(argIndex)
+		args = append(args, value)
+		argIndex++
+	}
+	if filter.ClinicID != nil {
+		add("clinic_id =", *filter.ClinicID)
+	}
+	if filter.ActorID != nil {
+		add("actor_id =", *filter.ActorID)
+	}
+	if filter.Action != "" {
+		add("action =", filter.Action)
+	}
+	if filter.ResourceType != "" {
+		add("resource_type =", filter.ResourceType)
+	}
+	if filter.ResourceID != "" {
+		add("resource_id =", filter.ResourceID)
+	}
+	if filter.From != nil {
+		add("occurred_at >=", *filter.From)
+	}
+	if filter.To != nil {
+		add("occurred_at <", *filter.To)
+	}
+
+	query += " ORDER BY id DESC LIMIT $" + strconv.Itoa(argIndex) + " OFFSET $" + strconv.Itoa(argIndex+1)
+	args = append(args, filter.Limit, filter.Offset)
+
+	return r.list(ctx, query, args...)
+}
+
+// Chain retrieves up to limit entries after afterID in chain order
+func (r *AuditRepository) Chain(ctx context.Context, afterID int64, limit int) ([]models.AuditEntry, error) {
+	return r.list(ctx, "SELECT "+auditColumns+" FROM audit_log WHERE id > $1 ORDER BY id LIMIT $2", afterID, limit)
+}
+
+// list retrieves the entries selected by query
+func (r *AuditRepository) list(ctx context.Context, query string, args ...interface{}) ([]models.AuditEntry, error) {
+	rows, err := conn(ctx, r.db).QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var entries []models.AuditEntry
+	for rows.Next() {
+		var e models.AuditEntry
+		if err := scanAuditEntry(rows, &e); err != nil {
+			return nil, err
+		}
+		entries = append(entries, e)
+	}
+
+	return entries, rows.Err()
+}
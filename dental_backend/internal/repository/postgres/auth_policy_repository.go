@@ -0,0 +1,70 @@
+// dental_backend/internal/repository/postgres/auth_policy_repository.go
+package postgres
+
+import (
+	"context"
+	"database/sql"
+
+	"dental_backend/internal/models"
+
+	"github.com/lib/pq"
+)
+
+const authPolicyColumns = `require_email_verification, require_mfa_roles, updated_at`
+
+// AuthPolicyRepository is the PostgreSQL implementation of repository.AuthPolicyRepository
+type AuthPolicyRepository struct {
+	db *sql.DB
+}
+
+// NewAuthPolicyRepository creates a new PostgreSQL auth policy repository
+func NewAuthPolicyRepository(db *sql.DB) *AuthPolicyRepository {
+	return &AuthPolicyRepository{db: db}
+}
+
+// scanAuthPolicy scans a row selected with authPolicyColumns
+func scanAuthPolicy(row interface{ Scan(...interface{}) error }, p *models.AuthPolicy) error {
+	var roles pq.StringArray
+	if err := row.Scan(&p.RequireEmailVerification, &roles, &p.UpdatedAt); err != nil {
+		return err
+	}
+	p.RequireMFARoles = []string(roles)
+	return nil
+}
+
+// Get retrieves the policy of the clinic of ctx, or the defaults when it has no row
+func (r *AuthPolicyRepository) Get(ctx context.Context) (*models.AuthPolicy, error) {
+	var policy models.AuthPolicy
+	err := scanAuthPolicy(conn(ctx, r.db).QueryRowContext(ctx,
+		"SELECT "+authPolicyColumns+" FROM auth_policy WHERE clinic_id = $1", clinicParam(ctx)), &policy)
+	if err == sql.ErrNoRows {
+		return &models.AuthPolicy{RequireMFARoles: []string{}}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &policy, nil
+}
+
+// Update applies the non-nil fields of req, creating the clinic's row on first use
+func (r *AuthPolicyRepository) Update(ctx context.Context, req models.UpdateAuthPolicyRequest) (*models.AuthPolicy, error) {
+	var mfaRoles interface{}
+	if req.RequireMFARoles != nil {
+		mfaRoles = pq.StringArray(*req.RequireMFARoles)
+	}
+
+	var policy models.AuthPolicy
+	err := scanAuthPolicy(conn(ctx, r.db).QueryRowContext(ctx, `
+		INSERT INTO auth_policy AS p (clinic_id, require_email_verification, require_mfa_roles)
+		VALUES ($1, COALESCE($2, FALSE), COALESCE($3, '{}'))
+		ON CONFLICT (clinic_id) DO UPDATE SET
+			require_email_verification = COALESCE($2, p.require_email_verification),
+			require_mfa_roles = COALESCE($3, p.require_mfa_roles),
+			updated_at = NOW()
+		RETURNING `+authPolicyColumns,
+		clinicParam(ctx), req.RequireEmailVerification, mfaRoles), &policy)
+	if err != nil {
+		return nil, err
+	}
+	return &policy, nil
+}
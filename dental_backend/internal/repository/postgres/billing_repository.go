@@ -0,0 +1,444 @@
+// dental_backend/internal/repository/postgres/billing_repository.go
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"strconv"
+
+	"dental_backend/internal/models"
+)
+
+const invoiceSelect = `
+		SELECT i.id, i.patient_id, COALESCE(p.first_name || ' ' || p.last_name, 'Unknown Patient') as patient_name,
+		       i.amount, i.status, i.due_date, i.issued_date, i.payment_method, i.notes, i.created_at, i.updated_at
+		FROM invoices i
+		LEFT JOIN patients p ON i.patient_id = p.id`
+
+const claimSelect = `
+		SELECT ic.id, ic.patient_id, p.first_name || ' ' || p.last_name as patient_name, ic.treatment_id,
+		       t.name as treatment_name, ic.claim_amount, ic.status,
+		       ic.submission_date, ic.approval_date, ic.notes, ic.created_at, ic.updated_at
+		FROM insurance_claims ic
+		JOIN patients p ON ic.patient_id = p.id
+		LEFT JOIN treatments t ON ic.treatment_id = t.id`
+
+// BillingRepository is the PostgreSQL implementation of repository.BillingRepository
+type BillingRepository struct {
+	db *sql.DB
+}
+
+// NewBillingRepository creates a new PostgreSQL billing repository
+func NewBillingRepository(db *sql.DB) *BillingRepository {
+	return &BillingRepository{db: db}
+}
+
+// Stats retrieves billing statistics for the dashboard
+func (r *BillingRepository) Stats(ctx context.Context) (*models.BillingStats, error) {
+	var stats models.BillingStats
+
+	// Get monthly revenue (paid invoices this month)
+	err := conn(ctx, r.db).QueryRowContext(ctx, `
+		SELECT COALESCE(SUM(amount), 0)
+		FROM invoices
+		WHERE status = 'paid'
+		AND EXTRACT(YEAR FROM issued_date) = EXTRACT(YEAR FROM CURRENT_DATE)
+		AND EXTRACT(MONTH FROM issued_date) = EXTRACT(MONTH FROM CURRENT_DATE)`).Scan(&stats.MonthlyRevenue)
+
+	if err != nil {
+		return nil, err
+	}
+
+	// Get pending payments (pending invoices)
+	err = conn(ctx, r.db).QueryRowContext(ctx, `
+		SELECT COALESCE(SUM(amount), 0)
+		FROM invoices
+		WHERE status = 'pending'`).Scan(&stats.PendingPayments)
+
+	if err != nil {
+		return nil, err
+	}
+
+	// Get insurance claims amount (submitted claims)
+	err = conn(ctx, r.db).QueryRowContext(ctx, `
+		SELECT COALESCE(SUM(claim_amount), 0)
+		FROM insurance_claims
+		WHERE status = 'submitted'`).Scan(&stats.InsuranceClaims)
+
+	if err != nil {
+		return nil, err
+	}
+
+	// Get collections (paid invoices)
+	err = conn(ctx, r.db).QueryRowContext(ctx, `
+		SELECT COALESCE(SUM(amount), 0)
+		FROM invoices
+		WHERE status = 'paid'`).Scan(&stats.Collections)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &stats, nil
+}
+
+// scanInvoice scans a row selected with invoiceSelect
+func scanInvoice(row interface{ Scan(...interface{}) error }, i *models.Invoice) error {
+	return row.Scan(
+		&i.ID, &i.PatientID, &i.PatientName, &i.Amount, &i.Status,
+		&i.DueDate, &i.IssuedDate, &i.PaymentMethod, &i.Notes,
+		&i.CreatedAt, &i.UpdatedAt,
+	)
+}
+
+// ListInvoices retrieves all invoices with optional filtering
+func (r *BillingRepository) ListInvoices(ctx context.Context, status, patientID string) ([]models.Invoice, error) {
+	query := invoiceSelect + `
+		WHERE 1=1`
+
+	args := []interface{}{}
+	argCount := 1
+
+	if status != "" {
+		query += " AND i.status = $" + strconv.Itoa(argCount)
+		args = append(args, status)
+		argCount++
+	}
+
+	if patientID != "" {
+		query += " AND i.patient_id = $" + strconv.Itoa(argCount)
+		args = append(args, patientID)
+		argCount++
+	}
+
+	query += " ORDER BY i.created_at DESC"
+
+	rows, err := conn(ctx, r.db).QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var invoices []models.Invoice
+	for rows.Next() {
+		var i models.Invoice
+		if err := scanInvoice(rows, &i); err != nil {
+			return nil, err
+		}
+		invoices = append(invoices, i)
+	}
+
+	// Check for errors that occurred during iteration
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return invoices, nil
+}
+
+// GetInvoice retrieves a single invoice by ID
+func (r *BillingRepository) GetInvoice(ctx context.Context, id int) (*models.Invoice, error) {
+	var i models.Invoice
+	err := scanInvoice(conn(ctx, r.db).QueryRowContext(ctx, invoiceSelect+`
+		WHERE i.id = $1`, id), &i)
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return &i, nil
+}
+
+// CreateInvoice inserts a new invoice
+func (r *BillingRepository) CreateInvoice(ctx context.Context, req models.CreateInvoiceRequest) (*models.Invoice, error) {
+	var newInvoice models.Invoice
+	err := scanInvoice(conn(ctx, r.db).QueryRowContext(ctx, `
+		INSERT INTO invoices (
+			patient_id, amount, status, due_date, issued_date, payment_method, notes, created_at, updated_at
+		) VALUES ($1, $2, $3, $4, COALESCE($5, CURRENT_DATE), $6, $7, NOW(), NOW())
+		RETURNING id, patient_id, (SELECT COALESCE(first_name || ' ' || last_name, 'Unknown Patient') FROM patients WHERE id = $1),
+		          amount, status, due_date, issued_date, payment_method, notes, created_at, updated_at`,
+		req.PatientID, req.Amount, req.Status, req.DueDate, nullIfEmpty(req.IssuedDate), req.PaymentMethod, req.Notes,
+	), &newInvoice)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &newInvoice, nil
+}
+
+// UpdateInvoice updates an existing invoice
+func (r *BillingRepository) UpdateInvoice(ctx context.Context, id int, req models.UpdateInvoiceRequest) (*models.Invoice, error) {
+	// Build the update query dynamically based on provided fields
+	query := "UPDATE invoices SET updated_at = NOW()"
+	args := []interface{}{id}
+	argCount := 2
+
+	if req.PatientID != 0 {
+		query += ", patient_id = $" + strconv.Itoa(argCount)
+		args = append(args, req.PatientID)
+		argCount++
+	}
+
+	if req.Amount != 0 {
+		query += ", amount = $" + strconv.Itoa(argCount)
+		args = append(args, req.Amount)
+		argCount++
+	}
+
+	if req.Status != "" {
+		query += ", status = $" + strconv.Itoa(argCount)
+		args = append(args, req.Status)
+		argCount++
+	}
+
+	if req.DueDate != "" {
+		query += ", due_date = $" + strconv.Itoa(argCount)
+		args = append(args, req.DueDate)
+		argCount++
+	}
+
+	if req.IssuedDate != "" {
+		query += ", issued_date = $" + strconv.Itoa(argCount)
+		args = append(args, req.IssuedDate)
+		argCount++
+	}
+
+	if req.PaymentMethod != "" {
+		query += ", payment_method = $" + strconv.Itoa(argCount)
+		args = append(args, req.PaymentMethod)
+		argCount++
+	}
+
+	if req.Notes != "" {
+		query += ", notes = $" + strconv.Itoa(argCount)
+		args = append(args, req.Notes)
+		argCount++
+	}
+
+	query += " WHERE id = $1"
+
+	result, err := conn(ctx, r.db).ExecContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := expectRows(result); err != nil {
+		return nil, err
+	}
+
+	// Retrieve the updated invoice
+	return r.GetInvoice(ctx, id)
+}
+
+// DeleteInvoice deletes an invoice by ID
+func (r *BillingRepository) DeleteInvoice(ctx context.Context, id int) error {
+	result, err := conn(ctx, r.db).ExecContext(ctx, "DELETE FROM invoices WHERE id = $1", id)
+	if err != nil {
+		return err
+	}
+
+	return expectRows(result)
+}
+
+// scanClaim scans a row selected with claimSelect
+func scanClaim(row interface{ Scan(...interface{}) error }) (*models.InsuranceClaim, error) {
+	var ic models.InsuranceClaim
+	var treatmentID sql.NullInt64
+	var treatmentName sql.NullString
+	var approvalDate sql.NullString
+
+	err := row.Scan(
+		&ic.ID, &ic.PatientID, &ic.PatientName, &treatmentID,
+		&treatmentName, &ic.ClaimAmount, &ic.Status,
+		&ic.SubmissionDate, &approvalDate, &ic.Notes,
+		&ic.CreatedAt, &ic.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	// Handle nullable fields
+	if treatmentID.Valid {
+		treatmentIDValue := int(treatmentID.Int64)
+		ic.TreatmentID = &treatmentIDValue
+	}
+
+	if treatmentName.Valid {
+		ic.TreatmentName = &treatmentName.String
+	}
+
+	if approvalDate.Valid {
+		ic.ApprovalDate = &approvalDate.String
+	}
+
+	return &ic, nil
+}
+
+// ListClaims retrieves all insurance claims with optional filtering
+func (r *BillingRepository) ListClaims(ctx context.Context, status, patientID string) ([]models.InsuranceClaim, error) {
+	query := claimSelect + `
+		WHERE 1=1`
+
+	args := []interface{}{}
+	argCount := 1
+
+	if status != "" {
+		query += " AND ic.status = $" + strconv.Itoa(argCount)
+		args = append(args, status)
+		argCount++
+	}
+
+	if patientID != "" {
+		query += " AND ic.patient_id = $" + strconv.Itoa(argCount)
+		args = append(args, patientID)
+		argCount++
+	}
+
+	query += " ORDER BY ic.created_at DESC"
+
+	rows, err := conn(ctx, r.db).QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var claims []models.InsuranceClaim
+	for rows.Next() {
+		ic, err := scanClaim(rows)
+		if err != nil {
+			return nil, err
+		}
+		claims = append(claims, *ic)
+	}
+
+	return claims, rows.Err()
+}
+
+// GetClaim retrieves a single insurance claim by ID
+func (r *BillingRepository) GetClaim(ctx context.Context, id int) (*models.InsuranceClaim, error) {
+	ic, err := scanClaim(conn(ctx, r.db).QueryRowContext(ctx, claimSelect+`
+		WHERE ic.id = $1`, id))
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return ic, nil
+}
+
+// CreateClaim inserts a new insurance claim
+func (r *BillingRepository) CreateClaim(ctx context.Context, req models.CreateInsuranceClaimRequest) (*models.InsuranceClaim, error) {
+	var treatmentID sql.NullInt64
+	var approvalDate sql.NullString
+
+	if req.TreatmentID != nil {
+		treatmentID.Valid = true
+		treatmentID.Int64 = int64(*req.TreatmentID)
+	}
+
+	if req.ApprovalDate != nil {
+		approvalDate.Valid = true
+		approvalDate.String = *req.ApprovalDate
+	}
+
+	var id int
+	err := conn(ctx, r.db).QueryRowContext(ctx, `
+		INSERT INTO insurance_claims (
+			patient_id, treatment_id, claim_amount, status, submission_date, approval_date, notes, created_at, updated_at
+		) VALUES ($1, $2, $3, $4, COALESCE($5, CURRENT_DATE), $6, $7, NOW(), NOW())
+		RETURNING id`,
+		req.PatientID, treatmentID, req.ClaimAmount, req.Status, nullIfEmpty(req.SubmissionDate), approvalDate, req.Notes,
+	).Scan(&id)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return r.GetClaim(ctx, id)
+}
+
+// UpdateClaim updates an existing insurance claim
+func (r *BillingRepository) UpdateClaim(ctx context.Context, id int, req models.UpdateInsuranceClaimRequest) (*models.InsuranceClaim, error) {
+	// Build the update query dynamically based on provided fields
+	query := "UPDATE insurance_claims SET updated_at = NOW()"
+	args := []interface{}{id}
+	argCount := 2
+
+	if req.PatientID != 0 {
+		query += ", patient_id = $" + strconv.Itoa(argCount)
+		args = append(args, req.PatientID)
+		argCount++
+	}
+
+	if req.TreatmentID != nil && *req.TreatmentID != 0 {
+		query += ", treatment_id = $" + strconv.Itoa(argCount)
+		args = append(args, *req.TreatmentID)
+		argCount++
+	} else {
+		query += ", treatment_id = NULL"
+	}
+
+	if req.ClaimAmount != 0 {
+		query += ", claim_amount = $" + strconv.Itoa(argCount)
+		args = append(args, req.ClaimAmount)
+		argCount++
+	}
+
+	if req.Status != "" {
+		query += ", status = $" + strconv.Itoa(argCount)
+		args = append(args, req.Status)
+		argCount++
+	}
+
+	if req.SubmissionDate != "" {
+		query += ", submission_date = $" + strconv.Itoa(argCount)
+		args = append(args, req.SubmissionDate)
+		argCount++
+	}
+
+	if req.ApprovalDate != nil && *req.ApprovalDate != "" {
+		query += ", approval_date = $" + strconv.Itoa(argCount)
+		args = append(args, *req.ApprovalDate)
+		argCount++
+	} else {
+		query += ", approval_date = NULL"
+	}
+
+	if req.Notes != "" {
+		query += ", notes = $" + strconv.Itoa(argCount)
+		args = append(args, req.Notes)
+		argCount++
+	}
+
+	query += " WHERE id = $1"
+
+	result, err := conn(ctx, r.db).ExecContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := expectRows(result); err != nil {
+		return nil, err
+	}
+
+	// Retrieve the updated insurance claim
+	return r.GetClaim(ctx, id)
+}
+
+// DeleteClaim deletes an insurance claim by ID
+func (r *BillingRepository) DeleteClaim(ctx context.Context, id int) error {
+	result, err := conn(ctx, r.db).ExecContext(ctx, "DELETE FROM insurance_claims WHERE id = $1", id)
+	if err != nil {
+		return err
+	}
+
+	return expectRows(result)
+}
@@ -0,0 +1,101 @@
+// dental_backend/internal/repository/postgres/calendar_feed_repository.go
+package postgres
+
+import (
+	"context"
+	"database/sql"
+
+	"dental_backend/internal/models"
+)
+
+const calendarFeedColumns = `f.id, f.clinic_id, f.user_id, f.feed_type, f.subject_id, f.created_at, f.last_used_at, f.revoked_at`
+
+// CalendarFeedRepository is the PostgreSQL implementation of repository.CalendarFeedRepository
+type CalendarFeedRepository struct {
+	db *sql.DB
+}
+
+// NewCalendarFeedRepository creates a new PostgreSQL calendar feed repository
+func NewCalendarFeedRepository(db *sql.DB) *CalendarFeedRepository {
+	return &CalendarFeedRepository{db: db}
+}
+
+// scanCalendarFeed scans a row selected with calendarFeedColumns
+func scanCalendarFeed(row interface{ Scan(...interface{}) error }, f *models.CalendarFeed, extra ...interface{}) error {
+	return row.Scan(append([]interface{}{
+		&f.ID, &f.ClinicID, &f.UserID, &f.Type, &f.SubjectID, &f.CreatedAt, &f.LastUsedAt, &f.RevokedAt,
+	}, extra...)...)
+}
+
+// Create inserts a new calendar feed at the clinic of ctx
+func (r *CalendarFeedRepository) Create(ctx context.Context, feed models.CalendarFeed, tokenHash string) (*models.CalendarFeed, error) {
+	var created models.CalendarFeed
+	err := scanCalendarFeed(conn(ctx, r.db).QueryRowContext(ctx, `
+		INSERT INTO calendar_feeds AS f (user_id, feed_type, subject_id, token_hash)
+		VALUES ($1, $2, $3, $4)
+		RETURNING `+calendarFeedColumns,
+		feed.UserID, feed.Type, feed.SubjectID, tokenHash), &created)
+	if err != nil {
+		return nil, err
+	}
+	return &created, nil
+}
+
+// FindActive retrieves the unrevoked feed with the token hash, with its owner's
+// role at the feed's clinic, and stamps its last use
+func (r *CalendarFeedRepository) FindActive(ctx context.Context, tokenHash string) (*models.CalendarFeed, error) {
+	var feed models.CalendarFeed
+	err := scanCalendarFeed(conn(ctx, r.db).QueryRowContext(ctx, `
+		UPDATE calendar_feeds AS f SET last_used_at = NOW()
+		FROM users u
+		JOIN clinic_memberships m ON m.user_id = u.id
+		WHERE u.id = f.user_id AND m.clinic_id = f.clinic_id AND u.deactivated_at IS NULL
+			AND f.token_hash = $1 AND f.revoked_at IS NULL
+		RETURNING `+calendarFeedColumns+`, m.role`,
+		tokenHash), &feed, &feed.UserRole)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &feed, nil
+}
+
+// ListForUser retrieves a user's feeds at the clinic of ctx, newest first
+func (r *CalendarFeedRepository) ListForUser(ctx context.Context, userID int) ([]models.CalendarFeed, error) {
+	rows, err := conn(ctx, r.db).QueryContext(ctx, `
+		SELECT `+calendarFeedColumns+`
+		FROM calendar_feeds f
+		WHERE f.user_id = $1
+		ORDER BY f.created_at DESC, f.id DESC`,
+		userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var feeds []models.CalendarFeed
+	for rows.Next() {
+		var f models.CalendarFeed
+		if err := scanCalendarFeed(rows, &f); err != nil {
+			return nil, err
+		}
+		feeds = append(feeds, f)
+	}
+
+	return feeds, rows.Err()
+}
+
+// Revoke revokes an active feed at the clinic of ctx, restricted to its owner when userID is set
+func (r *CalendarFeedRepository) Revoke(ctx context.Context, id int, userID *int) error {
+	result, err := conn(ctx, r.db).ExecContext(ctx, `
+		UPDATE calendar_feeds SET revoked_at = NOW()
+		WHERE id = $1 AND revoked_at IS NULL AND ($2::integer IS NULL OR user_id = $2)`,
+		id, userID)
+	if err != nil {
+		return err
+	}
+
+	return expectRows(result)
+}
@@ -0,0 +1,222 @@
+// dental_backend/internal/repository/postgres/clinic_repository.go
+package postgres
+
+import (
+	"context"
+	"database/sql"
+
+	"dental_backend/internal/models"
+)
+
+const clinicColumns = `id, name, address, phone, created_at, updated_at`
+
+// ClinicRepository is the PostgreSQL implementation of repository.ClinicRepository
+type ClinicRepository struct {
+	db *sql.DB
+}
+
+// NewClinicRepository creates a new PostgreSQL clinic repository
+func NewClinicRepository(db *sql.DB) *ClinicRepository {
+	return &ClinicRepository{db: db}
+}
+
+// scanClinic scans a row selected with clinicColumns
+func scanClinic(row interface{ Scan(...interface{}) error }, c *models.Clinic) error {
+	return row.Scan(&c.ID, &c.Name, &c.Address, &c.Phone, &c.CreatedAt, &c.UpdatedAt)
+}
+
+// List retrieves every clinic ordered by name
+func (r *ClinicRepository) List(ctx context.Context) ([]models.Clinic, error) {
+	rows, err := conn(ctx, r.db).QueryContext(ctx, "SELECT "+clinicColumns+" FROM clinics ORDER BY name, id")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var clinics []models.Clinic
+	for rows.Next() {
+		var c models.Clinic
+		if err := scanClinic(rows, &c); err != nil {
+			return nil, err
+		}
+		clinics = append(clinics, c)
+	}
+
+	return clinics, rows.Err()
+}
+
+// GetByID retrieves a clinic by ID
+func (r *ClinicRepository) GetByID(ctx context.Context, id int) (*models.Clinic, error) {
+	return r.get(ctx, "SELECT "+clinicColumns+" FROM clinics WHERE id = $1", id)
+}
+
+// Default retrieves the first clinic
+func (r *ClinicRepository) Default(ctx context.Context) (*models.Clinic, error) {
+	return r.get(ctx, "SELECT "+clinicColumns+" FROM clinics ORDER BY id LIMIT 1")
+}
+
+// get retrieves the clinic selected by query, or nil when there is none
+func (r *ClinicRepository) get(ctx context.Context, query string, args ...interface{}) (*models.Clinic, error) {
+	var clinic models.Clinic
+	err := scanClinic(conn(ctx, r.db).QueryRowContext(ctx, query, args...), &clinic)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &clinic, nil
+}
+
+// Create inserts a clinic and makes ownerID its admin
+func (r *ClinicRepository) Create(ctx context.Context, req models.CreateClinicRequest, ownerID int) (*models.Clinic, error) {
+	tx, err := conn(ctx, r.db).BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	var clinic models.Clinic
+	err = scanClinic(tx.QueryRowContext(ctx, `
+		INSERT INTO clinics (name, address, phone)
+		VALUES ($1, $2, $3)
+		RETURNING `+clinicColumns,
+		req.Name, req.Address, req.Phone), &clinic)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := tx.ExecContext(ctx,
+		"INSERT INTO clinic_memberships (clinic_id, user_id, role) VALUES ($1, $2, 'admin')",
+		clinic.ID, ownerID); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return &clinic, nil
+}
+
+// Update applies the non-nil fields of req
+func (r *ClinicRepository) Update(ctx context.Context, id int, req models.UpdateClinicRequest) (*models.Clinic, error) {
+	var clinic models.Clinic
+	err := scanClinic(conn(ctx, r.db).QueryRowContext(ctx, `
+		UPDATE clinics SET
+			name = COALESCE($2, name),
+			address = COALESCE($3, address),
+			phone = COALESCE($4, phone),
+			updated_at = NOW()
+		WHERE id = $1
+		RETURNING `+clinicColumns,
+		id, req.Name, req.Address, req.Phone), &clinic)
+	if err != nil {
+		return nil, err
+	}
+	return &clinic, nil
+}
+
+// ListForUser retrieves the clinics a user works at
+func (r *ClinicRepository) ListForUser(ctx context.Context, userID int) ([]models.UserClinic, error) {
+	rows, err := conn(ctx, r.db).QueryContext(ctx, `
+		SELECT c.id, c.name, m.role
+		FROM clinic_memberships m
+		JOIN clinics c ON c.id = m.clinic_id
+		WHERE m.user_id = $1
+		ORDER BY c.id`,
+		userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var clinics []models.UserClinic
+	for rows.Next() {
+		var c models.UserClinic
+		if err := rows.Scan(&c.ClinicID, &c.Name, &c.Role); err != nil {
+			return nil, err
+		}
+		clinics = append(clinics, c)
+	}
+
+	return clinics, rows.Err()
+}
+
+// Membership retrieves the user's membership of a clinic
+func (r *ClinicRepository) Membership(ctx context.Context, clinicID, userID int) (*models.UserClinic, error) {
+	var membership models.UserClinic
+	err := conn(ctx, r.db).QueryRowContext(ctx, `
+		SELECT c.id, c.name, m.role
+		FROM clinic_memberships m
+		JOIN clinics c ON c.id = m.clinic_id
+		WHERE m.clinic_id = $1 AND m.user_id = $2`,
+		clinicID, userID).Scan(&membership.ClinicID, &membership.Name, &membership.Role)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &membership, nil
+}
+
+// Members retrieves the users working at a clinic ordered by name
+func (r *ClinicRepository) Members(ctx context.Context, clinicID int) ([]models.ClinicMember, error) {
+	rows, err := conn(ctx, r.db).QueryContext(ctx, `
+		SELECT u.id, u.email, u.first_name, u.last_name, m.role, u.deactivated_at IS NULL, m.created_at
+		FROM clinic_memberships m
+		JOIN users u ON u.id = m.user_id
+		WHERE m.clinic_id = $1
+		ORDER BY u.last_name, u.first_name, u.id`,
+		clinicID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var members []models.ClinicMember
+	for rows.Next() {
+		var m models.ClinicMember
+		if err := rows.Scan(&m.UserID, &m.Email, &m.FirstName, &m.LastName, &m.Role, &m.Active, &m.JoinedAt); err != nil {
+			return nil, err
+		}
+		members = append(members, m)
+	}
+
+	return members, rows.Err()
+}
+
+// SetMember adds a user to a clinic or changes their role there
+func (r *ClinicRepository) SetMember(ctx context.Context, clinicID, userID int, role string) error {
+	_, err := conn(ctx, r.db).ExecContext(ctx, `
+		INSERT INTO clinic_memberships (clinic_id, user_id, role)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (clinic_id, user_id) DO UPDATE SET role = EXCLUDED.role`,
+		clinicID, userID, role)
+	if hasErrorCode(err, foreignKeyViolation) {
+		return sql.ErrNoRows
+	}
+	return err
+}
+
+// RemoveMember removes a user from a clinic
+func (r *ClinicRepository) RemoveMember(ctx context.Context, clinicID, userID int) error {
+	result, err := conn(ctx, r.db).ExecContext(ctx,
+		"DELETE FROM clinic_memberships WHERE clinic_id = $1 AND user_id = $2", clinicID, userID)
+	if err != nil {
+		return err
+	}
+	return expectRows(result)
+}
+
+// CountActiveAdmins counts the active admins of a clinic
+func (r *ClinicRepository) CountActiveAdmins(ctx context.Context, clinicID int) (int, error) {
+	var count int
+	err := conn(ctx, r.db).QueryRowContext(ctx, `
+		SELECT COUNT(*)
+		FROM clinic_memberships m
+		JOIN users u ON u.id = m.user_id
+		WHERE m.clinic_id = $1 AND m.role = 'admin' AND u.deactivated_at IS NULL`,
+		clinicID).Scan(&count)
+	return count, err
+}
@@ -0,0 +1,93 @@
+// dental_backend/internal/repository/postgres/helpers.go
+package postgres
+
+import (
+	"database/sql"
+	"errors"
+
+	"github.com/lib/pq"
+
+	"dental_backend/internal/repository"
+)
+
+// SQLSTATE codes PostgreSQL reports when a constraint rejects a row
+const (
+	exclusionViolation  = "23P01"
+	uniqueViolation     = "23505"
+	foreignKeyViolation = "23503"
+)
+
+// expectRows returns sql.ErrNoRows when a statement did not affect any rows
+func expectRows(result sql.Result) error {
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
+
+// nullIfEmpty returns NULL for empty strings
+func nullIfEmpty(s string) interface{} {
+	if s == "" {
+		return nil
+	}
+	return s
+}
+
+// int64Array converts ints for binding to an INTEGER[] or SMALLINT[] parameter
+func int64Array(values []int) pq.Int64Array {
+	array := make(pq.Int64Array, len(values))
+	for i, v := range values {
+		array[i] = int64(v)
+	}
+	return array
+}
+
+// intsFromArray converts a scanned INTEGER[] or SMALLINT[] column back to ints
+func intsFromArray(array pq.Int64Array) []int {
+	values := make([]int, len(array))
+	for i, v := range array {
+		values[i] = int(v)
+	}
+	return values
+}
+
+// isExclusionViolation reports whether err came from an exclusion constraint
+func isExclusionViolation(err error) bool {
+	return hasErrorCode(err, exclusionViolation)
+}
+
+// hasErrorCode reports whether err is a PostgreSQL error with the given SQLSTATE
+func hasErrorCode(err error, code pq.ErrorCode) bool {
+	var pqErr *pq.Error
+	return errors.As(err, &pqErr) && pqErr.Code == code
+}
+
+// Compile-time checks that the PostgreSQL repositories satisfy the interfaces
+var (
+	_ repository.PatientRepository         = (*PatientRepository)(nil)
+	_ repository.AppointmentRepository     = (*AppointmentRepository)(nil)
+	_ repository.TreatmentRepository       = (*TreatmentRepository)(nil)
+	_ repository.MedicalEntryRepository    = (*MedicalEntryRepository)(nil)
+	_ repository.RiskRepository            = (*RiskRepository)(nil)
+	_ repository.BillingRepository         = (*BillingRepository)(nil)
+	_ repository.ScheduleRepository        = (*ScheduleRepository)(nil)
+	_ repository.SeriesRepository          = (*SeriesRepository)(nil)
+	_ repository.WaitlistRepository        = (*WaitlistRepository)(nil)
+	_ repository.ReminderRepository        = (*ReminderRepository)(nil)
+	_ repository.AppointmentLinkRepository = (*AppointmentLinkRepository)(nil)
+	_ repository.CalendarFeedRepository    = (*CalendarFeedRepository)(nil)
+	_ repository.UserRepository            = (*UserRepository)(nil)
+	_ repository.SessionRepository         = (*SessionRepository)(nil)
+	_ repository.AccountTokenRepository    = (*AccountTokenRepository)(nil)
+	_ repository.AuthPolicyRepository      = (*AuthPolicyRepository)(nil)
+	_ repository.MFARepository             = (*MFARepository)(nil)
+	_ repository.IdentityRepository        = (*IdentityRepository)(nil)
+	_ repository.ClinicRepository          = (*ClinicRepository)(nil)
+	_ repository.AuditRepository           = (*AuditRepository)(nil)
+)
@@ -0,0 +1,88 @@
+// dental_backend/internal/repository/postgres/identity_repository.go
+package postgres
+
+import (
+	"context"
+	"database/sql"
+
+	"dental_backend/internal/models"
+	"dental_backend/internal/repository"
+)
+
+const identityColumns = `id, user_id, provider, subject, COALESCE(email, ''), created_at, last_login_at`
+
+// IdentityRepository is the PostgreSQL implementation of repository.IdentityRepository
+type IdentityRepository struct {
+	db *sql.DB
+}
+
+// NewIdentityRepository creates a new PostgreSQL identity repository
+func NewIdentityRepository(db *sql.DB) *IdentityRepository {
+	return &IdentityRepository{db: db}
+}
+
+// scanIdentity scans a row selected with identityColumns
+func scanIdentity(row interface{ Scan(...interface{}) error }, i *models.UserIdentity) error {
+	return row.Scan(&i.ID, &i.UserID, &i.Provider, &i.Subject, &i.Email, &i.CreatedAt, &i.LastLoginAt)
+}
+
+// Find retrieves the identity for a provider's subject
+func (r *IdentityRepository) Find(ctx context.Context, provider, subject string) (*models.UserIdentity, error) {
+	var identity models.UserIdentity
+	err := scanIdentity(conn(ctx, r.db).QueryRowContext(ctx,
+		"SELECT "+identityColumns+" FROM user_identities WHERE provider = $1 AND subject = $2",
+		provider, subject), &identity)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return &identity, nil
+}
+
+// Create links an identity to a user
+func (r *IdentityRepository) Create(ctx context.Context, identity models.UserIdentity) (*models.UserIdentity, error) {
+	var created models.UserIdentity
+	err := scanIdentity(conn(ctx, r.db).QueryRowContext(ctx, `
+		INSERT INTO user_identities (user_id, provider, subject, email, last_login_at)
+		VALUES ($1, $2, $3, NULLIF($4, ''), $5)
+		RETURNING `+identityColumns,
+		identity.UserID, identity.Provider, identity.Subject, identity.Email, identity.LastLoginAt), &created)
+	if err != nil {
+		if hasErrorCode(err, uniqueViolation) {
+			return nil, repository.ErrIdentityTaken
+		}
+		return nil, err
+	}
+
+	return &created, nil
+}
+
+// RecordLogin stamps the identity's last sign-in
+func (r *IdentityRepository) RecordLogin(ctx context.Context, id int) error {
+	_, err := conn(ctx, r.db).ExecContext(ctx, "UPDATE user_identities SET last_login_at = NOW() WHERE id = $1", id)
+	return err
+}
+
+// ListByUser retrieves the user's identities ordered by provider
+func (r *IdentityRepository) ListByUser(ctx context.Context, userID int) ([]models.UserIdentity, error) {
+	rows, err := conn(ctx, r.db).QueryContext(ctx,
+		"SELECT "+identityColumns+" FROM user_identities WHERE user_id = $1 ORDER BY provider", userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	identities := []models.UserIdentity{}
+	for rows.Next() {
+		var identity models.UserIdentity
+		if err := scanIdentity(rows, &identity); err != nil {
+			return nil, err
+		}
+		identities = append(identities, identity)
+	}
+
+	return identities, rows.Err()
+}
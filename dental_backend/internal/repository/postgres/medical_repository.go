@@ -0,0 +1,258 @@
+// dental_backend/internal/repository/postgres/medical_repository.go
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"dental_backend/internal/fieldcrypt"
+	"dental_backend/internal/models"
+)
+
+const medicalEntryColumns = `id, patient_id, kind, name, severity, reaction, dosage,
+		       COALESCE(to_char(onset, 'YYYY-MM-DD'), ''), status, alert, notes,
+		       recorded_by, created_at, updated_at, data_key, data_key_id`
+
+// medicalEntryOrder lists active entries first, then by onset, newest first
+const medicalEntryOrder = ` ORDER BY (status = 'active') DESC, onset DESC NULLS LAST, id DESC`
+
+// MedicalEntryRepository is the PostgreSQL implementation of
+// repository.MedicalEntryRepository. Like PatientRepository it encrypts the
+// fields of models.MedicalEntry tagged encrypted when it has a cipher.
+type MedicalEntryRepository struct {
+	db     *sql.DB
+	cipher *fieldcrypt.Cipher
+}
+
+// NewMedicalEntryRepository creates a new PostgreSQL medical entry repository;
+// cipher is nil to store entries in plaintext
+func NewMedicalEntryRepository(db *sql.DB, cipher *fieldcrypt.Cipher) *MedicalEntryRepository {
+	return &MedicalEntryRepository{db: db, cipher: cipher}
+}
+
+// scanEntry scans a row selected with medicalEntryColumns, decrypting its
+// fields when it is encrypted
+func (r *MedicalEntryRepository) scanEntry(ctx context.Context, row interface{ Scan(...interface{}) error }, e *models.MedicalEntry) error {
+	var recordedBy sql.NullInt64
+	var dataKey []byte
+	var dataKeyID sql.NullString
+	err := row.Scan(
+		&e.ID, &e.PatientID, &e.Kind, &e.Name, &e.Severity, &e.Reaction, &e.Dosage,
+		&e.Onset, &e.Status, &e.Alert, &e.Notes,
+		&recordedBy, &e.CreatedAt, &e.UpdatedAt, &dataKey, &dataKeyID,
+	)
+	if err != nil {
+		return err
+	}
+	if recordedBy.Valid {
+		id := int(recordedBy.Int64)
+		e.RecordedBy = &id
+	}
+	if dataKey == nil {
+		return nil
+	}
+
+	if r.cipher == nil {
+		return fmt.Errorf("medical entry %d is encrypted but no field encryption keys are configured", e.ID)
+	}
+	return r.cipher.Open(ctx, e, fieldcrypt.Envelope{KeyID: dataKeyID.String, WrappedKey: dataKey})
+}
+
+// query runs a select of medical entries
+func (r *MedicalEntryRepository) query(ctx context.Context, query string, args ...interface{}) ([]models.MedicalEntry, error) {
+	rows, err := conn(ctx, r.db).QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var entries []models.MedicalEntry
+	for rows.Next() {
+		var e models.MedicalEntry
+		if err := r.scanEntry(ctx, rows, &e); err != nil {
+			return nil, err
+		}
+		entries = append(entries, e)
+	}
+	return entries, rows.Err()
+}
+
+// entryValues returns the values the fields of e from name to notes, then
+// data_key and data_key_id, are written with, sealing e under a new data key
+// when encrypt is set and the repository has a cipher
+func (r *MedicalEntryRepository) entryValues(ctx context.Context, e models.MedicalEntry, encrypt bool) ([]interface{}, error) {
+	var dataKey, dataKeyID interface{}
+	if encrypt && r.cipher != nil {
+		env, err := r.cipher.Seal(ctx, &e)
+		if err != nil {
+			return nil, err
+		}
+		dataKey, dataKeyID = env.WrappedKey, env.KeyID
+	}
+
+	return []interface{}{
+		e.Name, e.Severity, e.Reaction, e.Dosage, nullIfEmpty(e.Onset), e.Status, e.Alert, e.Notes,
+		dataKey, dataKeyID,
+	}, nil
+}
+
+// medicalEntryAssignments is the SET list writing the values of entryValues from $1
+const medicalEntryAssignments = `name = $1, severity = $2, reaction = $3, dosage = $4, onset = $5,
+		    status = $6, alert = $7, notes = $8, data_key = $9, data_key_id = $10`
+
+// ListForPatient retrieves a patient's entries
+func (r *MedicalEntryRepository) ListForPatient(ctx context.Context, patientID int) ([]models.MedicalEntry, error) {
+	return r.query(ctx, `
+		SELECT `+medicalEntryColumns+`
+		FROM patient_medical_entries
+		WHERE patient_id = $1`+medicalEntryOrder, patientID)
+}
+
+// ListActive retrieves the active entries of the patients
+func (r *MedicalEntryRepository) ListActive(ctx context.Context, patientIDs []int) ([]models.MedicalEntry, error) {
+	return r.query(ctx, `
+		SELECT `+medicalEntryColumns+`
+		FROM patient_medical_entries
+		WHERE patient_id = ANY($1) AND status = 'active'`+medicalEntryOrder, int64Array(patientIDs))
+}
+
+// GetByID retrieves a single entry
+func (r *MedicalEntryRepository) GetByID(ctx context.Context, id int) (*models.MedicalEntry, error) {
+	var e models.MedicalEntry
+	err := r.scanEntry(ctx, conn(ctx, r.db).QueryRowContext(ctx, `
+		SELECT `+medicalEntryColumns+`
+		FROM patient_medical_entries
+		WHERE id = $1`, id), &e)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &e, nil
+}
+
+// Create inserts a new entry
+func (r *MedicalEntryRepository) Create(ctx context.Context, entry models.MedicalEntry) (*models.MedicalEntry, error) {
+	values, err := r.entryValues(ctx, entry, true)
+	if err != nil {
+		return nil, err
+	}
+	values = append(values, entry.PatientID, entry.Kind, entry.RecordedBy)
+
+	var e models.MedicalEntry
+	err = r.scanEntry(ctx, conn(ctx, r.db).QueryRowContext(ctx, `
+		INSERT INTO patient_medical_entries (
+			name, severity, reaction, dosage, onset, status, alert, notes, data_key, data_key_id,
+			patient_id, kind, recorded_by
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)
+		RETURNING `+medicalEntryColumns, values...), &e)
+	if err != nil {
+		return nil, fmt.Errorf("failed to insert medical entry: %w", err)
+	}
+	return &e, nil
+}
+
+// Update writes every field of entry but its patient and kind
+func (r *MedicalEntryRepository) Update(ctx context.Context, entry models.MedicalEntry) (*models.MedicalEntry, error) {
+	values, err := r.entryValues(ctx, entry, true)
+	if err != nil {
+		return nil, err
+	}
+	values = append(values, entry.ID)
+
+	var e models.MedicalEntry
+	err = r.scanEntry(ctx, conn(ctx, r.db).QueryRowContext(ctx, `
+		UPDATE patient_medical_entries
+		SET `+medicalEntryAssignments+`, updated_at = NOW()
+		WHERE id = $11
+		RETURNING `+medicalEntryColumns, values...), &e)
+	if err != nil {
+		return nil, err
+	}
+	return &e, nil
+}
+
+// Delete deletes an entry
+func (r *MedicalEntryRepository) Delete(ctx context.Context, id int) error {
+	result, err := conn(ctx, r.db).ExecContext(ctx, "DELETE FROM patient_medical_entries WHERE id = $1", id)
+	if err != nil {
+		return err
+	}
+	return expectRows(result)
+}
+
+// Rekey encrypts the entries still stored in plaintext and re-seals those
+// sealed under a key other than the current one, as PatientRepository.Rekey does
+func (r *MedicalEntryRepository) Rekey(ctx context.Context, batchSize int) (int, error) {
+	if r.cipher == nil {
+		return 0, fmt.Errorf("no field encryption keys are configured")
+	}
+	return r.rewrite(ctx, batchSize, true, `data_key IS NULL OR data_key_id <> $2`, r.cipher.CurrentKeyID())
+}
+
+// Decrypt writes every encrypted entry back in plaintext
+func (r *MedicalEntryRepository) Decrypt(ctx context.Context, batchSize int) (int, error) {
+	return r.rewrite(ctx, batchSize, false, `data_key IS NOT NULL`)
+}
+
+// rewrite rewrites the entries matching condition in batches until none match;
+// condition is bound from $2, after the batch size
+func (r *MedicalEntryRepository) rewrite(ctx context.Context, batchSize int, encrypt bool, condition string, args ...interface{}) (int, error) {
+	total := 0
+	for {
+		n, err := r.rewriteBatch(ctx, batchSize, encrypt, condition, args)
+		total += n
+		if err != nil || n == 0 {
+			return total, err
+		}
+	}
+}
+
+// rewriteBatch rewrites up to batchSize entries matching condition in one transaction
+func (r *MedicalEntryRepository) rewriteBatch(ctx context.Context, batchSize int, encrypt bool, condition string, args []interface{}) (int, error) {
+	tx, err := conn(ctx, r.db).BeginTx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	rows, err := tx.QueryContext(ctx, `
+		SELECT `+medicalEntryColumns+`
+		FROM patient_medical_entries
+		WHERE `+condition+`
+		ORDER BY id
+		LIMIT $1
+		FOR UPDATE`, append([]interface{}{batchSize}, args...)...)
+	if err != nil {
+		return 0, err
+	}
+
+	var entries []models.MedicalEntry
+	for rows.Next() {
+		var e models.MedicalEntry
+		if err := r.scanEntry(ctx, rows, &e); err != nil {
+			rows.Close()
+			return 0, err
+		}
+		entries = append(entries, e)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, err
+	}
+
+	for _, e := range entries {
+		values, err := r.entryValues(ctx, e, encrypt)
+		if err != nil {
+			return 0, err
+		}
+		values = append(values, e.ID)
+		if _, err := tx.ExecContext(ctx, `UPDATE patient_medical_entries SET `+medicalEntryAssignments+` WHERE id = $11`, values...); err != nil {
+			return 0, err
+		}
+	}
+
+	return len(entries), tx.Commit()
+}
@@ -0,0 +1,158 @@
+// dental_backend/internal/repository/postgres/mfa_repository.go
+package postgres
+
+import (
+	"context"
+	"database/sql"
+
+	"dental_backend/internal/models"
+)
+
+// MFARepository is the PostgreSQL implementation of repository.MFARepository
+type MFARepository struct {
+	db *sql.DB
+}
+
+// NewMFARepository creates a new PostgreSQL two-factor repository
+func NewMFARepository(db *sql.DB) *MFARepository {
+	return &MFARepository{db: db}
+}
+
+// Get retrieves the user's two-factor state with a count of unused backup codes
+func (r *MFARepository) Get(ctx context.Context, userID int) (*models.UserMFA, error) {
+	var mfa models.UserMFA
+	var secret sql.NullString
+	err := conn(ctx, r.db).QueryRowContext(ctx, `
+		SELECT u.id, u.totp_secret, u.totp_enabled_at, u.totp_last_step,
+			(SELECT COUNT(*) FROM mfa_backup_codes b WHERE b.user_id = u.id AND b.used_at IS NULL)
+		FROM users u
+		WHERE u.id = $1`,
+		userID,
+	).Scan(&mfa.UserID, &secret, &mfa.EnabledAt, &mfa.LastStep, &mfa.BackupCodesRemaining)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	mfa.Secret = secret.String
+	return &mfa, nil
+}
+
+// SetSecret stores a secret awaiting confirmation
+func (r *MFARepository) SetSecret(ctx context.Context, userID int, secret string) error {
+	result, err := conn(ctx, r.db).ExecContext(ctx, "UPDATE users SET totp_secret = $1 WHERE id = $2", secret, userID)
+	if err != nil {
+		return err
+	}
+
+	return expectRows(result)
+}
+
+// Enable turns on the stored secret and replaces the backup codes in one transaction
+func (r *MFARepository) Enable(ctx context.Context, userID int, step int64, codeHashes []string) error {
+	tx, err := conn(ctx, r.db).BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	result, err := tx.ExecContext(ctx, `
+		UPDATE users SET totp_enabled_at = NOW(), totp_last_step = $1, updated_at = NOW()
+		WHERE id = $2`,
+		step, userID)
+	if err != nil {
+		return err
+	}
+	if err := expectRows(result); err != nil {
+		return err
+	}
+	if err := replaceBackupCodes(ctx, tx, userID, codeHashes); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// Disable removes the secret and backup codes in one transaction
+func (r *MFARepository) Disable(ctx context.Context, userID int) error {
+	tx, err := conn(ctx, r.db).BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	result, err := tx.ExecContext(ctx, `
+		UPDATE users SET totp_secret = NULL, totp_enabled_at = NULL, totp_last_step = NULL, updated_at = NOW()
+		WHERE id = $1`,
+		userID)
+	if err != nil {
+		return err
+	}
+	if err := expectRows(result); err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, "DELETE FROM mfa_backup_codes WHERE user_id = $1", userID); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// ReplaceBackupCodes discards the user's backup codes and stores new ones in one transaction
+func (r *MFARepository) ReplaceBackupCodes(ctx context.Context, userID int, codeHashes []string) error {
+	tx, err := conn(ctx, r.db).BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := replaceBackupCodes(ctx, tx, userID, codeHashes); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// replaceBackupCodes swaps the user's backup codes within tx
+func replaceBackupCodes(ctx context.Context, tx *sql.Tx, userID int, codeHashes []string) error {
+	if _, err := tx.ExecContext(ctx, "DELETE FROM mfa_backup_codes WHERE user_id = $1", userID); err != nil {
+		return err
+	}
+	for _, hash := range codeHashes {
+		if _, err := tx.ExecContext(ctx,
+			"INSERT INTO mfa_backup_codes (user_id, code_hash) VALUES ($1, $2)", userID, hash); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// UseStep records a code's time step; the conditional update refuses a step that is not newer
+func (r *MFARepository) UseStep(ctx context.Context, userID int, step int64) (bool, error) {
+	result, err := conn(ctx, r.db).ExecContext(ctx, `
+		UPDATE users SET totp_last_step = $1
+		WHERE id = $2 AND (totp_last_step IS NULL OR totp_last_step < $1)`,
+		step, userID)
+	if err != nil {
+		return false, err
+	}
+
+	count, err := result.RowsAffected()
+	return count > 0, err
+}
+
+// UseBackupCode marks an unused backup code as used
+func (r *MFARepository) UseBackupCode(ctx context.Context, userID int, codeHash string) (bool, error) {
+	result, err := conn(ctx, r.db).ExecContext(ctx, `
+		UPDATE mfa_backup_codes SET used_at = NOW()
+		WHERE user_id = $1 AND code_hash = $2 AND used_at IS NULL`,
+		userID, codeHash)
+	if err != nil {
+		return false, err
+	}
+
+	count, err := result.RowsAffected()
+	return count > 0, err
+}
@@ -0,0 +1,451 @@
+// dental_backend/internal/repository/postgres/patient_repository.go
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"dental_backend/internal/fieldcrypt"
+	"dental_backend/internal/models"
+)
+
+const patientColumns = `id, first_name, last_name, date_of_birth, phone, email, address,
+		       emergency_contact, insurance_provider, insurance_policy_number,
+		       medical_history, risk_level, provisional, created_at, updated_at,
+		       data_key, data_key_id`
+
+// patientWriteColumns are the columns a patient is written to, in the order of
+// the values returned by patientValues
+var patientWriteColumns = []string{
+	"first_name", "last_name", "date_of_birth", "phone", "email", "address",
+	"emergency_contact", "insurance_provider", "insurance_policy_number",
+	"medical_history", "risk_level", "provisional",
+	"data_key", "data_key_id", "email_bidx", "phone_bidx",
+}
+
+// PatientRepository is the PostgreSQL implementation of repository.PatientRepository.
+// With a cipher the fields of models.Patient tagged encrypted are stored
+// encrypted, and email and phone are searched through blind indexes. Rows
+// without a data key are plaintext, either written before encryption was
+// enabled or by a repository without a cipher.
+type PatientRepository struct {
+	db     *sql.DB
+	cipher *fieldcrypt.Cipher
+}
+
+// NewPatientRepository creates a new PostgreSQL patient repository; cipher is
+// nil to store patients in plaintext
+func NewPatientRepository(db *sql.DB, cipher *fieldcrypt.Cipher) *PatientRepository {
+	return &PatientRepository{db: db, cipher: cipher}
+}
+
+// scanPatient scans a row selected with patientColumns, decrypting its fields
+// when it is encrypted
+func (r *PatientRepository) scanPatient(ctx context.Context, row interface{ Scan(...interface{}) error }, p *models.Patient) error {
+	var dataKey []byte
+	var dataKeyID sql.NullString
+	err := row.Scan(
+		&p.ID, &p.FirstName, &p.LastName, &p.DateOfBirth, &p.Phone, &p.Email,
+		&p.Address, &p.EmergencyContact, &p.InsuranceProvider, &p.InsurancePolicyNumber,
+		&p.MedicalHistory, &p.RiskLevel, &p.Provisional, &p.CreatedAt, &p.UpdatedAt,
+		&dataKey, &dataKeyID,
+	)
+	if err != nil || dataKey == nil {
+		return err
+	}
+
+	if r.cipher == nil {
+		return fmt.Errorf("patient %d is encrypted but no field encryption keys are configured", p.ID)
+	}
+	return r.cipher.Open(ctx, p, fieldcrypt.Envelope{KeyID: dataKeyID.String, WrappedKey: dataKey})
+}
+
+// patientValues returns the values p is written with, for patientWriteColumns.
+// The tagged fields are sealed under a new data key when encrypt is set and
+// the repository has a cipher.
+func (r *PatientRepository) patientValues(ctx context.Context, p models.Patient, encrypt bool) ([]interface{}, error) {
+	var dataKey, dataKeyID, emailIndex, phoneIndex interface{}
+	if encrypt && r.cipher != nil {
+		emailIndex = nullIfEmpty(r.cipher.BlindIndex("email", normalizeEmail(p.Email)))
+		phoneIndex = nullIfEmpty(r.cipher.BlindIndex("phone", normalizePhone(p.Phone)))
+
+		env, err := r.cipher.Seal(ctx, &p)
+		if err != nil {
+			return nil, err
+		}
+		dataKey, dataKeyID = env.WrappedKey, env.KeyID
+	}
+
+	return []interface{}{
+		p.FirstName, p.LastName, p.DateOfBirth, p.Phone, p.Email, p.Address,
+		p.EmergencyContact, p.InsuranceProvider, p.InsurancePolicyNumber,
+		p.MedicalHistory, p.RiskLevel, p.Provisional,
+		dataKey, dataKeyID, emailIndex, phoneIndex,
+	}, nil
+}
+
+// patientAssignments returns the SET list writing patientWriteColumns from $1 on
+func patientAssignments() string {
+	parts := make([]string, len(patientWriteColumns))
+	for i, column := range patientWriteColumns {
+		parts[i] = fmt.Sprintf("%s = $%d", column, i+1)
+	}
+	return strings.Join(parts, ", ")
+}
+
+// normalizeEmail is the form of an email its blind index is computed from
+func normalizeEmail(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
+
+// normalizePhone is the form of a phone number its blind index is computed
+// from: its digits, so the same number matches however it is formatted
+func normalizePhone(phone string) string {
+	return strings.Map(func(r rune) rune {
+		if r < '0' || r > '9' {
+			return -1
+		}
+		return r
+	}, phone)
+}
+
+// List retrieves all patients with optional filtering. Names are matched by
+// substring; encrypted emails and phone numbers can only be matched exactly,
+// through their blind indexes.
+func (r *PatientRepository) List(ctx context.Context, search string) ([]models.Patient, error) {
+	query := `
+		SELECT ` + patientColumns + `
+		FROM patients
+		WHERE 1=1`
+
+	args := []interface{}{}
+
+	if search != "" {
+		var emailIndex, phoneIndex interface{}
+		if r.cipher != nil {
+			emailIndex = nullIfEmpty(r.cipher.BlindIndex("email", normalizeEmail(search)))
+			phoneIndex = nullIfEmpty(r.cipher.BlindIndex("phone", normalizePhone(search)))
+		}
+		query += `
+		  AND (first_name ILIKE $1 OR last_name ILIKE $1
+		       OR email_bidx = $2 OR phone_bidx = $3
+		       OR (data_key IS NULL AND (email ILIKE $1 OR phone ILIKE $1)))`
+		args = append(args, "%"+search+"%", emailIndex, phoneIndex)
+	}
+
+	query += " ORDER BY created_at DESC"
+
+	rows, err := conn(ctx, r.db).QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var patients []models.Patient
+	for rows.Next() {
+		var p models.Patient
+		if err := r.scanPatient(ctx, rows, &p); err != nil {
+			return nil, err
+		}
+		patients = append(patients, p)
+	}
+
+	return patients, rows.Err()
+}
+
+// GetByID retrieves a single patient by ID
+func (r *PatientRepository) GetByID(ctx context.Context, id int) (*models.Patient, error) {
+	var p models.Patient
+	err := r.scanPatient(ctx, conn(ctx, r.db).QueryRowContext(ctx, `
+		SELECT `+patientColumns+`
+		FROM patients
+		WHERE id = $1`, id), &p)
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return &p, nil
+}
+
+// Create inserts a new patient record
+func (r *PatientRepository) Create(ctx context.Context, req models.CreatePatientRequest) (*models.Patient, error) {
+	values, err := r.patientValues(ctx, models.Patient{
+		FirstName:             req.FirstName,
+		LastName:              req.LastName,
+		DateOfBirth:           req.DateOfBirth,
+		Phone:                 req.Phone,
+		Email:                 req.Email,
+		Address:               req.Address,
+		EmergencyContact:      req.EmergencyContact,
+		InsuranceProvider:     req.InsuranceProvider,
+		InsurancePolicyNumber: req.InsurancePolicyNumber,
+		MedicalHistory:        req.MedicalHistory,
+		RiskLevel:             req.RiskLevel,
+		Provisional:           req.Provisional,
+	}, true)
+	if err != nil {
+		return nil, err
+	}
+
+	// Set created_at and updated_at to current time
+	now := time.Now()
+	values = append(values, now, now)
+
+	placeholders := make([]string, len(values))
+	for i := range placeholders {
+		placeholders[i] = fmt.Sprintf("$%d", i+1)
+	}
+
+	var p models.Patient
+	err = r.scanPatient(ctx, conn(ctx, r.db).QueryRowContext(ctx, `
+		INSERT INTO patients (`+strings.Join(patientWriteColumns, ", ")+`, created_at, updated_at)
+		VALUES (`+strings.Join(placeholders, ", ")+`)
+		RETURNING `+patientColumns, values...), &p)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &p, nil
+}
+
+// Update updates an existing patient record. The row is read and written back
+// whole in a transaction, since its encrypted fields share one data key.
+func (r *PatientRepository) Update(ctx context.Context, id int, req models.UpdatePatientRequest) (*models.Patient, error) {
+	tx, err := conn(ctx, r.db).BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	var p models.Patient
+	err = r.scanPatient(ctx, tx.QueryRowContext(ctx, `
+		SELECT `+patientColumns+`
+		FROM patients
+		WHERE id = $1
+		FOR UPDATE`, id), &p)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	// Apply the provided fields
+	changed := false
+	fields := []struct {
+		target *string
+		value  string
+	}{
+		{&p.FirstName, req.FirstName},
+		{&p.LastName, req.LastName},
+		{&p.DateOfBirth, req.DateOfBirth},
+		{&p.Phone, req.Phone},
+		{&p.Email, req.Email},
+		{&p.Address, req.Address},
+		{&p.EmergencyContact, req.EmergencyContact},
+		{&p.InsuranceProvider, req.InsuranceProvider},
+		{&p.InsurancePolicyNumber, req.InsurancePolicyNumber},
+		{&p.MedicalHistory, req.MedicalHistory},
+		{&p.RiskLevel, req.RiskLevel},
+	}
+
+	for _, field := range fields {
+		if field.value != "" {
+			*field.target = field.value
+			changed = true
+		}
+	}
+
+	if req.Provisional != nil {
+		p.Provisional = *req.Provisional
+		changed = true
+	}
+
+	// If no fields to update, return the existing patient
+	if !changed {
+		return &p, nil
+	}
+
+	values, err := r.patientValues(ctx, p, true)
+	if err != nil {
+		return nil, err
+	}
+
+	// Always update the updated_at timestamp
+	values = append(values, time.Now(), id)
+
+	query := fmt.Sprintf(
+		"UPDATE patients SET %s, updated_at = $%d WHERE id = $%d RETURNING "+patientColumns,
+		patientAssignments(), len(values)-1, len(values),
+	)
+
+	var updated models.Patient
+	if err := r.scanPatient(ctx, tx.QueryRowContext(ctx, query, values...), &updated); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return &updated, nil
+}
+
+// FindByEmailAndDOB retrieves the patient matching an email and date of birth.
+// Candidates are found by email and their dates of birth, which may be
+// encrypted, compared once decrypted.
+func (r *PatientRepository) FindByEmailAndDOB(ctx context.Context, email, dateOfBirth string) (*models.Patient, error) {
+	var emailIndex interface{}
+	if r.cipher != nil {
+		emailIndex = nullIfEmpty(r.cipher.BlindIndex("email", normalizeEmail(email)))
+	}
+
+	rows, err := conn(ctx, r.db).QueryContext(ctx, `
+		SELECT `+patientColumns+`
+		FROM patients
+		WHERE email_bidx = $1 OR (data_key IS NULL AND lower(email) = lower($2))
+		ORDER BY provisional ASC, created_at ASC`, emailIndex, strings.TrimSpace(email))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var p models.Patient
+		if err := r.scanPatient(ctx, rows, &p); err != nil {
+			return nil, err
+		}
+		if sameDate(p.DateOfBirth, dateOfBirth) {
+			return &p, nil
+		}
+	}
+
+	return nil, rows.Err()
+}
+
+// sameDate reports whether two dates written as YYYY-MM-DD, possibly followed
+// by a time, are the same day
+func sameDate(a, b string) bool {
+	dayA, errA := time.Parse("2006-01-02", firstN(strings.TrimSpace(a), 10))
+	dayB, errB := time.Parse("2006-01-02", firstN(strings.TrimSpace(b), 10))
+	if errA != nil || errB != nil {
+		return strings.TrimSpace(a) == strings.TrimSpace(b)
+	}
+	return dayA.Equal(dayB)
+}
+
+// firstN returns at most the first n bytes of s
+func firstN(s string, n int) string {
+	if len(s) > n {
+		return s[:n]
+	}
+	return s
+}
+
+// Rekey encrypts the patients still stored in plaintext and re-seals those
+// sealed under a key other than the current one, batchSize rows per
+// transaction, and returns how many it rewrote. updated_at is left alone, as
+// the patients themselves do not change.
+func (r *PatientRepository) Rekey(ctx context.Context, batchSize int) (int, error) {
+	if r.cipher == nil {
+		return 0, fmt.Errorf("no field encryption keys are configured")
+	}
+	return r.rewrite(ctx, batchSize, true, `data_key IS NULL OR data_key_id <> $2`, r.cipher.CurrentKeyID())
+}
+
+// Decrypt writes every encrypted patient back in plaintext, batchSize rows per
+// transaction, and returns how many it rewrote
+func (r *PatientRepository) Decrypt(ctx context.Context, batchSize int) (int, error) {
+	return r.rewrite(ctx, batchSize, false, `data_key IS NOT NULL`)
+}
+
+// rewrite rewrites the patients matching condition, encrypted or not, until
+// none match. condition is bound from $2, after the batch size.
+func (r *PatientRepository) rewrite(ctx context.Context, batchSize int, encrypt bool, condition string, args ...interface{}) (int, error) {
+	total := 0
+	for {
+		n, err := r.rewriteBatch(ctx, batchSize, encrypt, condition, args)
+		total += n
+		if err != nil || n == 0 {
+			return total, err
+		}
+	}
+}
+
+// rewriteBatch rewrites up to batchSize patients matching condition in one transaction
+func (r *PatientRepository) rewriteBatch(ctx context.Context, batchSize int, encrypt bool, condition string, args []interface{}) (int, error) {
+	tx, err := conn(ctx, r.db).BeginTx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	rows, err := tx.QueryContext(ctx, `
+		SELECT `+patientColumns+`
+		FROM patients
+		WHERE `+condition+`
+		ORDER BY id
+		LIMIT $1
+		FOR UPDATE`, append([]interface{}{batchSize}, args...)...)
+	if err != nil {
+		return 0, err
+	}
+
+	var patients []models.Patient
+	for rows.Next() {
+		var p models.Patient
+		if err := r.scanPatient(ctx, rows, &p); err != nil {
+			rows.Close()
+			return 0, err
+		}
+		patients = append(patients, p)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, err
+	}
+
+	for _, p := range patients {
+		values, err := r.patientValues(ctx, p, encrypt)
+		if err != nil {
+			return 0, err
+		}
+		values = append(values, p.ID)
+		query := fmt.Sprintf("UPDATE patients SET %s WHERE id = $%d", patientAssignments(), len(values))
+		if _, err := tx.ExecContext(ctx, query, values...); err != nil {
+			return 0, err
+		}
+	}
+
+	return len(patients), tx.Commit()
+}
+
+// Delete deletes a patient by ID
+func (r *PatientRepository) Delete(ctx context.Context, id int) error {
+	result, err := conn(ctx, r.db).ExecContext(ctx, "DELETE FROM patients WHERE id = $1", id)
+	if err != nil {
+		return err
+	}
+
+	return expectRows(result)
+}
+
+// Count returns the number of patients, optionally restricted to a risk level
+func (r *PatientRepository) Count(ctx context.Context, riskLevel string) (int, error) {
+	var count int
+	var err error
+	if riskLevel == "" {
+		err = conn(ctx, r.db).QueryRowContext(ctx, "SELECT COUNT(*) FROM patients").Scan(&count)
+	} else {
+		err = conn(ctx, r.db).QueryRowContext(ctx, "SELECT COUNT(*) FROM patients WHERE risk_level = $1", riskLevel).Scan(&count)
+	}
+	return count, err
+}
@@ -0,0 +1,104 @@
+// dental_backend/internal/repository/postgres/reminder_repository.go
+package postgres
+
+import (
+	"context"
+	"database/sql"
+
+	"dental_backend/internal/models"
+)
+
+const reminderDeliveryColumns = `id, appointment_id, to_char(appointment_date, 'YYYY-MM-DD'), to_char(start_time, 'HH24:MI'),
+		       offset_minutes, channel, recipient, status, attempts, error, sent_at, created_at, updated_at`
+
+// ReminderRepository is the PostgreSQL implementation of repository.ReminderRepository
+type ReminderRepository struct {
+	db *sql.DB
+}
+
+// NewReminderRepository creates a new PostgreSQL reminder repository
+func NewReminderRepository(db *sql.DB) *ReminderRepository {
+	return &ReminderRepository{db: db}
+}
+
+// scanReminderDelivery scans a row selected with reminderDeliveryColumns
+func scanReminderDelivery(row interface{ Scan(...interface{}) error }, d *models.ReminderDelivery) error {
+	return row.Scan(
+		&d.ID, &d.AppointmentID, &d.AppointmentDate, &d.StartTime,
+		&d.OffsetMinutes, &d.Channel, &d.Recipient, &d.Status, &d.Attempts, &d.Error, &d.SentAt, &d.CreatedAt, &d.UpdatedAt,
+	)
+}
+
+// Claim inserts a delivery, or re-claims a failed one with attempts left, in a single statement
+// so concurrent workers cannot both claim the same reminder
+func (r *ReminderRepository) Claim(ctx context.Context, delivery models.ReminderDelivery) (*models.ReminderDelivery, error) {
+	query := `
+		INSERT INTO reminder_deliveries (appointment_id, appointment_date, start_time, offset_minutes, channel, recipient)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		ON CONFLICT (appointment_id, appointment_date, start_time, offset_minutes, channel) DO UPDATE
+		SET status = 'sending', attempts = reminder_deliveries.attempts + 1,
+		    recipient = EXCLUDED.recipient, error = '', updated_at = NOW()
+		WHERE reminder_deliveries.status = 'failed' AND reminder_deliveries.attempts < $7
+		RETURNING ` + reminderDeliveryColumns
+
+	var claimed models.ReminderDelivery
+	err := scanReminderDelivery(conn(ctx, r.db).QueryRowContext(ctx, query,
+		delivery.AppointmentID, delivery.AppointmentDate, delivery.StartTime,
+		delivery.OffsetMinutes, delivery.Channel, delivery.Recipient, models.MaxReminderAttempts,
+	), &claimed)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return &claimed, nil
+}
+
+// Finish marks a claimed delivery sent, or failed with the given error
+func (r *ReminderRepository) Finish(ctx context.Context, id int, sendErr string) error {
+	query := `
+		UPDATE reminder_deliveries
+		SET status = 'sent', sent_at = NOW(), updated_at = NOW()
+		WHERE id = $1`
+	args := []interface{}{id}
+	if sendErr != "" {
+		query = `
+		UPDATE reminder_deliveries
+		SET status = 'failed', error = $2, updated_at = NOW()
+		WHERE id = $1`
+		args = append(args, sendErr)
+	}
+
+	result, err := conn(ctx, r.db).ExecContext(ctx, query, args...)
+	if err != nil {
+		return err
+	}
+
+	return expectRows(result)
+}
+
+// ListForAppointment retrieves an appointment's reminder deliveries, oldest first
+func (r *ReminderRepository) ListForAppointment(ctx context.Context, appointmentID int) ([]models.ReminderDelivery, error) {
+	rows, err := conn(ctx, r.db).QueryContext(ctx, `
+		SELECT `+reminderDeliveryColumns+`
+		FROM reminder_deliveries
+		WHERE appointment_id = $1
+		ORDER BY created_at ASC, id ASC`, appointmentID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var deliveries []models.ReminderDelivery
+	for rows.Next() {
+		var d models.ReminderDelivery
+		if err := scanReminderDelivery(rows, &d); err != nil {
+			return nil, err
+		}
+		deliveries = append(deliveries, d)
+	}
+
+	return deliveries, rows.Err()
+}
@@ -0,0 +1,128 @@
+// dental_backend/internal/repository/postgres/risk_repository.go
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+
+	"dental_backend/internal/models"
+)
+
+// RiskRepository is the PostgreSQL implementation of repository.RiskRepository
+type RiskRepository struct {
+	db *sql.DB
+}
+
+// NewRiskRepository creates a new PostgreSQL risk repository
+func NewRiskRepository(db *sql.DB) *RiskRepository {
+	return &RiskRepository{db: db}
+}
+
+// Rules returns the rules of the clinic of ctx or nil while the defaults apply
+func (r *RiskRepository) Rules(ctx context.Context) (*models.RiskRules, error) {
+	return r.rules(ctx, "SELECT rules FROM risk_rules WHERE clinic_id = $1", clinicParam(ctx))
+}
+
+// PatientRules returns the rules of the patient's clinic or nil while the defaults apply
+func (r *RiskRepository) PatientRules(ctx context.Context, patientID int) (*models.RiskRules, error) {
+	return r.rules(ctx, `
+		SELECT r.rules
+		FROM patients p
+		JOIN risk_rules r ON r.clinic_id = p.clinic_id
+		WHERE p.id = $1`, patientID)
+}
+
+// rules decodes the rules selected by query, or returns nil when there is no row
+func (r *RiskRepository) rules(ctx context.Context, query string, args ...interface{}) (*models.RiskRules, error) {
+	var data []byte
+	err := conn(ctx, r.db).QueryRowContext(ctx, query, args...).Scan(&data)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var rules models.RiskRules
+	if err := json.Unmarshal(data, &rules); err != nil {
+		return nil, err
+	}
+	return &rules, nil
+}
+
+// SetRules stores the rules of the clinic of ctx in place of any configured before
+func (r *RiskRepository) SetRules(ctx context.Context, rules models.RiskRules) error {
+	data, err := json.Marshal(rules)
+	if err != nil {
+		return err
+	}
+
+	_, err = conn(ctx, r.db).ExecContext(ctx, `
+		INSERT INTO risk_rules (clinic_id, rules) VALUES ($1, $2)
+		ON CONFLICT (clinic_id) DO UPDATE SET rules = EXCLUDED.rules, updated_at = NOW()`,
+		clinicParam(ctx), string(data))
+	return err
+}
+
+// GetAssessment returns a patient's latest assessment or nil
+func (r *RiskRepository) GetAssessment(ctx context.Context, patientID int) (*models.RiskAssessment, error) {
+	var a models.RiskAssessment
+	var factors []byte
+	err := conn(ctx, r.db).QueryRowContext(ctx, `
+		SELECT patient_id, score, level, factors, computed_at
+		FROM patient_risk_scores WHERE patient_id = $1`, patientID).
+		Scan(&a.PatientID, &a.Score, &a.Level, &factors, &a.ComputedAt)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal(factors, &a.Factors); err != nil {
+		return nil, err
+	}
+	return &a, nil
+}
+
+// SaveAssessment replaces a patient's assessment
+func (r *RiskRepository) SaveAssessment(ctx context.Context, a models.RiskAssessment) error {
+	factors := a.Factors
+	if factors == nil {
+		factors = []models.RiskFactor{}
+	}
+	data, err := json.Marshal(factors)
+	if err != nil {
+		return err
+	}
+
+	_, err = conn(ctx, r.db).ExecContext(ctx, `
+		INSERT INTO patient_risk_scores (patient_id, score, level, factors, computed_at)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (patient_id) DO UPDATE SET
+			score = EXCLUDED.score, level = EXCLUDED.level,
+			factors = EXCLUDED.factors, computed_at = EXCLUDED.computed_at`,
+		a.PatientID, a.Score, a.Level, string(data), a.ComputedAt)
+	return err
+}
+
+// CountByLevel returns the number of assessed patients at each derived level
+func (r *RiskRepository) CountByLevel(ctx context.Context) (map[string]int, error) {
+	rows, err := conn(ctx, r.db).QueryContext(ctx, "SELECT level, COUNT(*) FROM patient_risk_scores GROUP BY level")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	counts := make(map[string]int)
+	for rows.Next() {
+		var level string
+		var count int
+		if err := rows.Scan(&level, &count); err != nil {
+			return nil, err
+		}
+		counts[level] = count
+	}
+	return counts, rows.Err()
+}
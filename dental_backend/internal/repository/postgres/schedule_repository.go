@@ -0,0 +1,240 @@
+// dental_backend/internal/repository/postgres/schedule_repository.go
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"strconv"
+
+	"dental_backend/internal/models"
+)
+
+const closureColumns = `id, dentist_id, to_char(start_date, 'YYYY-MM-DD'), to_char(end_date, 'YYYY-MM-DD'), reason, created_at`
+
+// ScheduleRepository is the PostgreSQL implementation of repository.ScheduleRepository
+type ScheduleRepository struct {
+	db *sql.DB
+}
+
+// NewScheduleRepository creates a new PostgreSQL schedule repository
+func NewScheduleRepository(db *sql.DB) *ScheduleRepository {
+	return &ScheduleRepository{db: db}
+}
+
+// scanClosure scans a row selected with closureColumns
+func scanClosure(row interface{ Scan(...interface{}) error }, c *models.Closure) error {
+	return row.Scan(&c.ID, &c.DentistID, &c.StartDate, &c.EndDate, &c.Reason, &c.CreatedAt)
+}
+
+// dentistCondition matches active dentists: users with the dentist role at the
+// clinic bound to $1 or, when it is NULL, users whose own role is dentist
+const dentistCondition = `users.deactivated_at IS NULL AND CASE
+		WHEN $1::INTEGER IS NULL THEN users.role = 'dentist'
+		ELSE EXISTS (
+			SELECT 1 FROM clinic_memberships m
+			WHERE m.user_id = users.id AND m.clinic_id = $1 AND m.role = 'dentist'
+		)
+	END`
+
+// IsDentist reports whether the user exists, is active and has the dentist role
+func (r *ScheduleRepository) IsDentist(ctx context.Context, userID int) (bool, error) {
+	var isDentist bool
+	err := conn(ctx, r.db).QueryRowContext(ctx,
+		"SELECT EXISTS (SELECT 1 FROM users WHERE id = $2 AND "+dentistCondition+")", clinicParam(ctx), userID,
+	).Scan(&isDentist)
+	return isDentist, err
+}
+
+// Dentists retrieves every active dentist ordered by name
+func (r *ScheduleRepository) Dentists(ctx context.Context) ([]models.Dentist, error) {
+	rows, err := conn(ctx, r.db).QueryContext(ctx, `
+		SELECT id, first_name || ' ' || last_name
+		FROM users
+		WHERE `+dentistCondition+`
+		ORDER BY last_name, first_name, id`, clinicParam(ctx))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var dentists []models.Dentist
+	for rows.Next() {
+		var d models.Dentist
+		if err := rows.Scan(&d.ID, &d.Name); err != nil {
+			return nil, err
+		}
+		dentists = append(dentists, d)
+	}
+
+	return dentists, rows.Err()
+}
+
+// WorkingHours retrieves the dentist's weekly working hours at the clinic of ctx
+func (r *ScheduleRepository) WorkingHours(ctx context.Context, dentistID int) ([]models.WorkingHours, error) {
+	rows, err := conn(ctx, r.db).QueryContext(ctx, `
+		SELECT id, dentist_id, weekday, to_char(start_time, 'HH24:MI'), to_char(end_time, 'HH24:MI')
+		FROM dentist_working_hours
+		WHERE clinic_id = $1 AND dentist_id = $2
+		ORDER BY weekday, start_time`, clinicParam(ctx), dentistID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var hours []models.WorkingHours
+	for rows.Next() {
+		var h models.WorkingHours
+		if err := rows.Scan(&h.ID, &h.DentistID, &h.Weekday, &h.StartTime, &h.EndTime); err != nil {
+			return nil, err
+		}
+		hours = append(hours, h)
+	}
+
+	return hours, rows.Err()
+}
+
+// Breaks retrieves the dentist's weekly breaks at the clinic of ctx
+func (r *ScheduleRepository) Breaks(ctx context.Context, dentistID int) ([]models.ScheduleBreak, error) {
+	rows, err := conn(ctx, r.db).QueryContext(ctx, `
+		SELECT id, dentist_id, weekday, to_char(start_time, 'HH24:MI'), to_char(end_time, 'HH24:MI'), label
+		FROM dentist_breaks
+		WHERE clinic_id = $1 AND dentist_id = $2
+		ORDER BY weekday, start_time`, clinicParam(ctx), dentistID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var breaks []models.ScheduleBreak
+	for rows.Next() {
+		var b models.ScheduleBreak
+		if err := rows.Scan(&b.ID, &b.DentistID, &b.Weekday, &b.StartTime, &b.EndTime, &b.Label); err != nil {
+			return nil, err
+		}
+		breaks = append(breaks, b)
+	}
+
+	return breaks, rows.Err()
+}
+
+// ReplaceWeek replaces the dentist's working hours and breaks at the clinic of
+// ctx in one transaction
+func (r *ScheduleRepository) ReplaceWeek(ctx context.Context, dentistID int, hours []models.WorkingHours, breaks []models.ScheduleBreak) error {
+	clinicID := clinicParam(ctx)
+	tx, err := conn(ctx, r.db).BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, "DELETE FROM dentist_working_hours WHERE clinic_id = $1 AND dentist_id = $2", clinicID, dentistID); err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, "DELETE FROM dentist_breaks WHERE clinic_id = $1 AND dentist_id = $2", clinicID, dentistID); err != nil {
+		return err
+	}
+
+	for _, h := range hours {
+		if _, err := tx.ExecContext(ctx, `
+			INSERT INTO dentist_working_hours (clinic_id, dentist_id, weekday, start_time, end_time)
+			VALUES ($1, $2, $3, $4, $5)`,
+			clinicID, dentistID, h.Weekday, h.StartTime, h.EndTime); err != nil {
+			return err
+		}
+	}
+
+	for _, b := range breaks {
+		if _, err := tx.ExecContext(ctx, `
+			INSERT INTO dentist_breaks (clinic_id, dentist_id, weekday, start_time, end_time, label)
+			VALUES ($1, $2, $3, $4, $5, $6)`,
+			clinicID, dentistID, b.Weekday, b.StartTime, b.EndTime, b.Label); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// Closures retrieves closures intersecting the date range, at the clinic of ctx
+// when it has one
+func (r *ScheduleRepository) Closures(ctx context.Context, dentistID *int, from, to string) ([]models.Closure, error) {
+	query := "SELECT " + closureColumns + " FROM schedule_closures WHERE ($1::INTEGER IS NULL OR clinic_id = $1)"
+	args := []interface{}{clinicParam(ctx)}
+	argIndex := 2
+
+	if dentistID != nil {
+		query += " AND (dentist_id IS NULL OR dentist_id = $" + strconv.Itoa(argIndex) + ")"
+		args = append(args, *dentistID)
+		argIndex++
+	}
+	if from != "" {
+		query += " AND end_date >= $" + strconv.Itoa(argIndex)
+		args = append(args, from)
+		argIndex++
+	}
+	if to != "" {
+		query += " AND start_date <= $" + strconv.Itoa(argIndex)
+		args = append(args, to)
+		argIndex++
+	}
+
+	query += " ORDER BY start_date ASC, id ASC"
+
+	rows, err := conn(ctx, r.db).QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var closures []models.Closure
+	for rows.Next() {
+		var c models.Closure
+		if err := scanClosure(rows, &c); err != nil {
+			return nil, err
+		}
+		closures = append(closures, c)
+	}
+
+	return closures, rows.Err()
+}
+
+// GetClosure retrieves a single closure by ID
+func (r *ScheduleRepository) GetClosure(ctx context.Context, id int) (*models.Closure, error) {
+	var closure models.Closure
+	err := scanClosure(conn(ctx, r.db).QueryRowContext(ctx,
+		"SELECT "+closureColumns+" FROM schedule_closures WHERE id = $1", id), &closure)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return &closure, nil
+}
+
+// CreateClosure inserts a new closure
+func (r *ScheduleRepository) CreateClosure(ctx context.Context, req models.CreateClosureRequest) (*models.Closure, error) {
+	var closure models.Closure
+	err := scanClosure(conn(ctx, r.db).QueryRowContext(ctx, `
+		INSERT INTO schedule_closures (dentist_id, start_date, end_date, reason)
+		VALUES ($1, $2, $3, $4)
+		RETURNING `+closureColumns,
+		req.DentistID, req.StartDate, req.EndDate, req.Reason,
+	), &closure)
+	if err != nil {
+		return nil, err
+	}
+
+	return &closure, nil
+}
+
+// DeleteClosure deletes a closure by ID
+func (r *ScheduleRepository) DeleteClosure(ctx context.Context, id int) error {
+	result, err := conn(ctx, r.db).ExecContext(ctx, "DELETE FROM schedule_closures WHERE id = $1", id)
+	if err != nil {
+		return err
+	}
+
+	return expectRows(result)
+}
@@ -0,0 +1,79 @@
+// dental_backend/internal/repository/postgres/scope.go
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"log"
+	"strconv"
+
+	"dental_backend/internal/repository"
+)
+
+// executor is what the repositories run statements on: the pool, or the
+// connection a request has been scoped to a clinic on
+type executor interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+	BeginTx(ctx context.Context, opts *sql.TxOptions) (*sql.Tx, error)
+}
+
+type connKey struct{}
+
+// conn returns the connection ScopeClinic pinned to ctx, or db
+func conn(ctx context.Context, db *sql.DB) executor {
+	if c, ok := ctx.Value(connKey{}).(*sql.Conn); ok {
+		return c
+	}
+	return db
+}
+
+// ScopeClinic takes a connection from db limited to one clinic by the row-level
+// security policies, and returns a context that runs every repository call on
+// it. release must be called when the work is done to hand the connection back.
+func ScopeClinic(ctx context.Context, db *sql.DB, clinicID int) (context.Context, func(), error) {
+	return scope(repository.WithClinic(ctx, clinicID), db, "app.clinic_id", strconv.Itoa(clinicID))
+}
+
+// ScopeAllClinics takes a connection from db that the row-level security
+// policies let see every clinic, for patient self-service links and calendar
+// feeds, and returns a context that runs every repository call on it. release
+// must be called when the work is done to hand the connection back.
+func ScopeAllClinics(ctx context.Context, db *sql.DB) (context.Context, func(), error) {
+	return scope(repository.WithAllClinics(ctx), db, "app.all_clinics", "on")
+}
+
+// scope pins a connection from db with the setting the policies read, and
+// returns ctx carrying it
+func scope(ctx context.Context, db *sql.DB, setting, value string) (context.Context, func(), error) {
+	c, err := db.Conn(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	if _, err := c.ExecContext(ctx, "SELECT set_config($1, $2, false)", setting, value); err != nil {
+		c.Close()
+		return nil, nil, err
+	}
+
+	release := func() {
+		// A connection still scoped must not go back to the pool
+		if _, err := c.ExecContext(context.Background(), "SELECT set_config($1, '', false)", setting); err != nil {
+			log.Printf("Error resetting %s, discarding connection: %v", setting, err)
+			c.Raw(func(interface{}) error { return driver.ErrBadConn })
+		}
+		c.Close()
+	}
+
+	return context.WithValue(ctx, connKey{}, c), release, nil
+}
+
+// clinicParam returns the clinic of ctx for binding, or NULL when it has none
+func clinicParam(ctx context.Context) interface{} {
+	clinicID, ok := repository.ClinicID(ctx)
+	if !ok {
+		return nil
+	}
+	return clinicID
+}
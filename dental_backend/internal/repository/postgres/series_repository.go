@@ -0,0 +1,116 @@
+// dental_backend/internal/repository/postgres/series_repository.go
+package postgres
+
+import (
+	"context"
+	"database/sql"
+
+	"dental_backend/internal/models"
+)
+
+const seriesColumns = `id, dentist_id, patient_id, treatment_id,
+		       to_char(start_date, 'YYYY-MM-DD'), to_char(start_time, 'HH24:MI'), duration_minutes, notes,
+		       frequency, "interval", COALESCE(count, 0), COALESCE(to_char(until, 'YYYY-MM-DD'), ''), weekday,
+		       created_at, updated_at`
+
+// SeriesRepository is the PostgreSQL implementation of repository.SeriesRepository
+type SeriesRepository struct {
+	db *sql.DB
+}
+
+// NewSeriesRepository creates a new PostgreSQL series repository
+func NewSeriesRepository(db *sql.DB) *SeriesRepository {
+	return &SeriesRepository{db: db}
+}
+
+// scanSeries scans a row selected with seriesColumns
+func scanSeries(row interface{ Scan(...interface{}) error }, s *models.AppointmentSeries) error {
+	return row.Scan(
+		&s.ID, &s.DentistID, &s.PatientID, &s.TreatmentID,
+		&s.StartDate, &s.StartTime, &s.Duration, &s.Notes,
+		&s.Recurrence.Frequency, &s.Recurrence.Interval, &s.Recurrence.Count, &s.Recurrence.Until, &s.Recurrence.Weekday,
+		&s.CreatedAt, &s.UpdatedAt,
+	)
+}
+
+// nullIfZero returns NULL for zero integers
+func nullIfZero(n int) interface{} {
+	if n == 0 {
+		return nil
+	}
+	return n
+}
+
+// Create inserts a new series
+func (r *SeriesRepository) Create(ctx context.Context, series models.AppointmentSeries) (*models.AppointmentSeries, error) {
+	var created models.AppointmentSeries
+	err := scanSeries(conn(ctx, r.db).QueryRowContext(ctx, `
+		INSERT INTO appointment_series (
+			dentist_id, patient_id, treatment_id, start_date, start_time, duration_minutes, notes,
+			frequency, "interval", count, until, weekday
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+		RETURNING `+seriesColumns,
+		series.DentistID, series.PatientID, series.TreatmentID, series.StartDate, series.StartTime,
+		series.Duration, series.Notes, series.Recurrence.Frequency, series.Recurrence.Interval,
+		nullIfZero(series.Recurrence.Count), nullIfEmpty(series.Recurrence.Until), series.Recurrence.Weekday,
+	), &created)
+	if err != nil {
+		return nil, err
+	}
+
+	return &created, nil
+}
+
+// GetByID retrieves a series, restricted to a dentist when dentistID is set
+func (r *SeriesRepository) GetByID(ctx context.Context, id int, dentistID *int) (*models.AppointmentSeries, error) {
+	query := "SELECT " + seriesColumns + " FROM appointment_series WHERE id = $1"
+	args := []interface{}{id}
+	if dentistID != nil {
+		query += " AND dentist_id = $2"
+		args = append(args, *dentistID)
+	}
+
+	var series models.AppointmentSeries
+	err := scanSeries(conn(ctx, r.db).QueryRowContext(ctx, query, args...), &series)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return &series, nil
+}
+
+// Update replaces the template fields of a series
+func (r *SeriesRepository) Update(ctx context.Context, series models.AppointmentSeries) (*models.AppointmentSeries, error) {
+	var updated models.AppointmentSeries
+	err := scanSeries(conn(ctx, r.db).QueryRowContext(ctx, `
+		UPDATE appointment_series
+		SET treatment_id = $1, start_time = $2, duration_minutes = $3, notes = $4, until = $5, count = $6,
+		    updated_at = NOW()
+		WHERE id = $7 AND dentist_id = $8
+		RETURNING `+seriesColumns,
+		series.TreatmentID, series.StartTime, series.Duration, series.Notes,
+		nullIfEmpty(series.Recurrence.Until), nullIfZero(series.Recurrence.Count),
+		series.ID, series.DentistID,
+	), &updated)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return &updated, nil
+}
+
+// Delete deletes a series; its appointments keep existing without a series
+func (r *SeriesRepository) Delete(ctx context.Context, id int, dentistID int) error {
+	result, err := conn(ctx, r.db).ExecContext(ctx, "DELETE FROM appointment_series WHERE id = $1 AND dentist_id = $2", id, dentistID)
+	if err != nil {
+		return err
+	}
+
+	return expectRows(result)
+}
@@ -0,0 +1,160 @@
+// dental_backend/internal/repository/postgres/session_repository.go
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"dental_backend/internal/models"
+)
+
+const sessionColumns = `id, user_id, clinic_id, user_agent, ip_address, created_at, last_used_at, expires_at, revoked_at`
+
+// SessionRepository is the PostgreSQL implementation of repository.SessionRepository
+type SessionRepository struct {
+	db *sql.DB
+}
+
+// NewSessionRepository creates a new PostgreSQL session repository
+func NewSessionRepository(db *sql.DB) *SessionRepository {
+	return &SessionRepository{db: db}
+}
+
+// scanSession scans a row selected with sessionColumns
+func scanSession(row interface{ Scan(...interface{}) error }, s *models.Session) error {
+	return row.Scan(&s.ID, &s.UserID, &s.ClinicID, &s.UserAgent, &s.IPAddress, &s.CreatedAt, &s.LastUsedAt, &s.ExpiresAt, &s.RevokedAt)
+}
+
+// Create inserts a new session
+func (r *SessionRepository) Create(ctx context.Context, session models.Session, tokenHash string) (*models.Session, error) {
+	var created models.Session
+	err := scanSession(conn(ctx, r.db).QueryRowContext(ctx, `
+		INSERT INTO sessions (id, user_id, clinic_id, token_hash, user_agent, ip_address, expires_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING `+sessionColumns,
+		session.ID, session.UserID, session.ClinicID, tokenHash, session.UserAgent, session.IPAddress, session.ExpiresAt), &created)
+	if err != nil {
+		return nil, err
+	}
+	return &created, nil
+}
+
+// GetActive retrieves an unrevoked, unexpired session
+func (r *SessionRepository) GetActive(ctx context.Context, id string) (*models.Session, error) {
+	var session models.Session
+	err := scanSession(conn(ctx, r.db).QueryRowContext(ctx, `
+		SELECT `+sessionColumns+`
+		FROM sessions
+		WHERE id = $1 AND revoked_at IS NULL AND expires_at > NOW()`,
+		id), &session)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &session, nil
+}
+
+// Rotate swaps the refresh token of the active session holding oldHash
+func (r *SessionRepository) Rotate(ctx context.Context, oldHash, newHash string, expiresAt time.Time, client models.SessionClient) (*models.Session, error) {
+	var session models.Session
+	err := scanSession(conn(ctx, r.db).QueryRowContext(ctx, `
+		UPDATE sessions
+		SET previous_token_hash = token_hash, token_hash = $2, expires_at = $3,
+		    user_agent = $4, ip_address = $5, last_used_at = NOW()
+		WHERE token_hash = $1 AND revoked_at IS NULL AND expires_at > NOW()
+		RETURNING `+sessionColumns,
+		oldHash, newHash, expiresAt, client.UserAgent, client.IPAddress), &session)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &session, nil
+}
+
+// RevokeByPreviousHash revokes the session a replayed refresh token belonged to
+func (r *SessionRepository) RevokeByPreviousHash(ctx context.Context, tokenHash string) (bool, error) {
+	result, err := conn(ctx, r.db).ExecContext(ctx, `
+		UPDATE sessions SET revoked_at = NOW()
+		WHERE previous_token_hash = $1 AND revoked_at IS NULL`,
+		tokenHash)
+	if err != nil {
+		return false, err
+	}
+
+	if err := expectRows(result); err != nil {
+		if err == sql.ErrNoRows {
+			return false, nil
+		}
+		return false, err
+	}
+	return true, nil
+}
+
+// Revoke revokes an active session of the user
+func (r *SessionRepository) Revoke(ctx context.Context, id string, userID int) error {
+	result, err := conn(ctx, r.db).ExecContext(ctx, `
+		UPDATE sessions SET revoked_at = NOW()
+		WHERE id = $1 AND user_id = $2 AND revoked_at IS NULL AND expires_at > NOW()`,
+		id, userID)
+	if err != nil {
+		return err
+	}
+
+	return expectRows(result)
+}
+
+// RevokeAll revokes every active session of the user other than exceptID
+func (r *SessionRepository) RevokeAll(ctx context.Context, userID int, exceptID string) (int, error) {
+	result, err := conn(ctx, r.db).ExecContext(ctx, `
+		UPDATE sessions SET revoked_at = NOW()
+		WHERE user_id = $1 AND id <> $2 AND revoked_at IS NULL AND expires_at > NOW()`,
+		userID, exceptID)
+	if err != nil {
+		return 0, err
+	}
+
+	count, err := result.RowsAffected()
+	return int(count), err
+}
+
+// ListActive retrieves the user's active sessions, most recently used first
+func (r *SessionRepository) ListActive(ctx context.Context, userID int) ([]models.Session, error) {
+	rows, err := conn(ctx, r.db).QueryContext(ctx, `
+		SELECT `+sessionColumns+`
+		FROM sessions
+		WHERE user_id = $1 AND revoked_at IS NULL AND expires_at > NOW()
+		ORDER BY last_used_at DESC`,
+		userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var sessions []models.Session
+	for rows.Next() {
+		var s models.Session
+		if err := scanSession(rows, &s); err != nil {
+			return nil, err
+		}
+		sessions = append(sessions, s)
+	}
+
+	return sessions, rows.Err()
+}
+
+// SetClinic moves an active session to another clinic
+func (r *SessionRepository) SetClinic(ctx context.Context, id string, clinicID int) error {
+	result, err := conn(ctx, r.db).ExecContext(ctx, `
+		UPDATE sessions SET clinic_id = $2
+		WHERE id = $1 AND revoked_at IS NULL AND expires_at > NOW()`,
+		id, clinicID)
+	if err != nil {
+		return err
+	}
+	return expectRows(result)
+}
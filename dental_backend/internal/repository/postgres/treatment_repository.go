@@ -0,0 +1,387 @@
+// dental_backend/internal/repository/postgres/treatment_repository.go
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"dental_backend/internal/models"
+)
+
+const patientTreatmentSelect = `
+		SELECT pt.id, pt.patient_id, pt.treatment_id, pt.dentist_id, p.first_name || ' ' || p.last_name as patient_name,
+		       t.name as treatment_name,
+		       CASE WHEN pt.dentist_id IS NOT NULL THEN u.first_name || ' ' || u.last_name ELSE NULL END as dentist_name,
+		       pt.status, pt.priority, pt.start_date, pt.completion_date, pt.notes, pt.created_at, pt.updated_at
+		FROM patient_treatments pt
+		JOIN patients p ON pt.patient_id = p.id
+		JOIN treatments t ON pt.treatment_id = t.id
+		LEFT JOIN users u ON pt.dentist_id = u.id`
+
+// TreatmentRepository is the PostgreSQL implementation of repository.TreatmentRepository
+type TreatmentRepository struct {
+	db *sql.DB
+}
+
+// NewTreatmentRepository creates a new PostgreSQL treatment repository
+func NewTreatmentRepository(db *sql.DB) *TreatmentRepository {
+	return &TreatmentRepository{db: db}
+}
+
+// List retrieves all treatments
+func (r *TreatmentRepository) List(ctx context.Context) ([]models.Treatment, error) {
+	rows, err := conn(ctx, r.db).QueryContext(ctx, `
+		SELECT id, name, description, cost, duration_minutes, category, bookable_online
+		FROM treatments
+		ORDER BY name ASC`)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to query treatments: %w", err)
+	}
+	defer rows.Close()
+
+	var treatments []models.Treatment
+	for rows.Next() {
+		var t models.Treatment
+		err := rows.Scan(
+			&t.ID, &t.Name, &t.Description, &t.Cost, &t.Duration, &t.Category, &t.BookableOnline,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan treatment: %w", err)
+		}
+		treatments = append(treatments, t)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating treatments: %w", err)
+	}
+
+	return treatments, nil
+}
+
+// GetByID retrieves a single treatment by ID
+func (r *TreatmentRepository) GetByID(ctx context.Context, id int) (*models.Treatment, error) {
+	var t models.Treatment
+	err := conn(ctx, r.db).QueryRowContext(ctx, `
+		SELECT id, name, description, cost, duration_minutes, category, bookable_online
+		FROM treatments
+		WHERE id = $1`, id).Scan(
+		&t.ID, &t.Name, &t.Description, &t.Cost, &t.Duration, &t.Category, &t.BookableOnline,
+	)
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get treatment by ID %d: %w", id, err)
+	}
+
+	return &t, nil
+}
+
+// Create inserts a new treatment
+func (r *TreatmentRepository) Create(ctx context.Context, req models.CreateTreatmentRequest) (*models.Treatment, error) {
+	// For PostgreSQL, use RETURNING to get the inserted ID
+	var id int
+	err := conn(ctx, r.db).QueryRowContext(ctx, `
+		INSERT INTO treatments (
+			name, description, cost, duration_minutes, category, bookable_online
+		) VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING id`,
+		req.Name, req.Description, req.Cost, req.Duration, req.Category, req.BookableOnline,
+	).Scan(&id)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to insert treatment: %w", err)
+	}
+
+	// Fetch the created treatment
+	return r.GetByID(ctx, id)
+}
+
+// Update updates an existing treatment
+func (r *TreatmentRepository) Update(ctx context.Context, id int, req models.UpdateTreatmentRequest) (*models.Treatment, error) {
+	// Build the update query dynamically based on provided fields
+	setParts := []string{}
+	args := []interface{}{}
+
+	argIndex := 1
+
+	if req.Name != "" {
+		setParts = append(setParts, "name = $"+strconv.Itoa(argIndex))
+		args = append(args, req.Name)
+		argIndex++
+	}
+
+	if req.Description != "" {
+		setParts = append(setParts, "description = $"+strconv.Itoa(argIndex))
+		args = append(args, req.Description)
+		argIndex++
+	}
+
+	if req.Cost != 0 {
+		setParts = append(setParts, "cost = $"+strconv.Itoa(argIndex))
+		args = append(args, req.Cost)
+		argIndex++
+	}
+
+	if req.Duration != 0 {
+		setParts = append(setParts, "duration_minutes = $"+strconv.Itoa(argIndex))
+		args = append(args, req.Duration)
+		argIndex++
+	}
+
+	if req.Category != "" {
+		setParts = append(setParts, "category = $"+strconv.Itoa(argIndex))
+		args = append(args, req.Category)
+		argIndex++
+	}
+
+	if req.BookableOnline != nil {
+		setParts = append(setParts, "bookable_online = $"+strconv.Itoa(argIndex))
+		args = append(args, *req.BookableOnline)
+		argIndex++
+	}
+
+	// If no fields to update, return the existing treatment
+	if len(setParts) == 0 {
+		return r.GetByID(ctx, id)
+	}
+
+	// Add the WHERE clause parameter
+	args = append(args, id)
+
+	query := fmt.Sprintf("UPDATE treatments SET %s WHERE id = $%d",
+		strings.Join(setParts, ", "), argIndex)
+
+	result, err := conn(ctx, r.db).ExecContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update treatment: %w", err)
+	}
+
+	if err := expectRows(result); err != nil {
+		return nil, err
+	}
+
+	// Retrieve the updated treatment
+	return r.GetByID(ctx, id)
+}
+
+// Delete deletes a treatment by ID
+func (r *TreatmentRepository) Delete(ctx context.Context, id int) error {
+	result, err := conn(ctx, r.db).ExecContext(ctx, "DELETE FROM treatments WHERE id = $1", id)
+	if err != nil {
+		return fmt.Errorf("failed to delete treatment: %w", err)
+	}
+
+	return expectRows(result)
+}
+
+// scanPatientTreatment scans a row selected with patientTreatmentSelect
+func scanPatientTreatment(row interface{ Scan(...interface{}) error }) (*models.PatientTreatment, error) {
+	var pt models.PatientTreatment
+	var dentistIDNull sql.NullInt64
+	var completionDate sql.NullString
+	var dentistName sql.NullString
+
+	err := row.Scan(
+		&pt.ID, &pt.PatientID, &pt.TreatmentID, &dentistIDNull, &pt.PatientName,
+		&pt.TreatmentName, &dentistName, &pt.Status, &pt.Priority,
+		&pt.StartDate, &completionDate, &pt.Notes, &pt.CreatedAt, &pt.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	// Handle nullable fields
+	if dentistIDNull.Valid {
+		dentistIDValue := int(dentistIDNull.Int64)
+		pt.DentistID = &dentistIDValue
+	}
+
+	if dentistName.Valid {
+		pt.DentistName = &dentistName.String
+	}
+
+	if completionDate.Valid {
+		pt.CompletionDate = &completionDate.String
+	}
+
+	return &pt, nil
+}
+
+// queryPatientTreatments runs a patient treatment query and scans every row
+func (r *TreatmentRepository) queryPatientTreatments(ctx context.Context, query string, args ...interface{}) ([]models.PatientTreatment, error) {
+	rows, err := conn(ctx, r.db).QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query patient treatments: %w", err)
+	}
+	defer rows.Close()
+
+	var treatments []models.PatientTreatment
+	for rows.Next() {
+		pt, err := scanPatientTreatment(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan patient treatment: %w", err)
+		}
+		treatments = append(treatments, *pt)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating patient treatments: %w", err)
+	}
+
+	return treatments, nil
+}
+
+// Queue retrieves patient treatments with status "pending" or "in-progress"
+func (r *TreatmentRepository) Queue(ctx context.Context, dentistID *int) ([]models.PatientTreatment, error) {
+	query := patientTreatmentSelect + `
+		WHERE pt.status IN ('pending', 'in-progress')`
+	args := []interface{}{}
+
+	if dentistID != nil {
+		query += " AND pt.dentist_id = $1"
+		args = append(args, *dentistID)
+	}
+
+	query += " ORDER BY pt.priority DESC, pt.created_at ASC"
+
+	return r.queryPatientTreatments(ctx, query, args...)
+}
+
+// ListForPatient retrieves all treatments for a specific patient
+func (r *TreatmentRepository) ListForPatient(ctx context.Context, patientID int) ([]models.PatientTreatment, error) {
+	return r.queryPatientTreatments(ctx, patientTreatmentSelect+`
+		WHERE pt.patient_id = $1
+		ORDER BY pt.created_at DESC`, patientID)
+}
+
+// GetPatientTreatment retrieves a single patient treatment with joined data
+func (r *TreatmentRepository) GetPatientTreatment(ctx context.Context, id int) (*models.PatientTreatment, error) {
+	pt, err := scanPatientTreatment(conn(ctx, r.db).QueryRowContext(ctx, patientTreatmentSelect+`
+		WHERE pt.id = $1`, id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to fetch patient treatment: %w", err)
+	}
+	return pt, nil
+}
+
+// CreatePatientTreatment inserts a new patient treatment
+func (r *TreatmentRepository) CreatePatientTreatment(ctx context.Context, req models.CreatePatientTreatmentRequest, dentistID int) (*models.PatientTreatment, error) {
+	now := time.Now()
+
+	// Handle completion date
+	var completionDateValue interface{}
+	if req.CompletionDate != nil && *req.CompletionDate != "" {
+		completionDateValue = *req.CompletionDate
+	}
+
+	// For PostgreSQL, use RETURNING to get the inserted ID
+	var id int
+	err := conn(ctx, r.db).QueryRowContext(ctx, `
+		INSERT INTO patient_treatments (
+			patient_id, treatment_id, dentist_id, status, priority, start_date, completion_date, notes, created_at, updated_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+		RETURNING id`,
+		req.PatientID, req.TreatmentID, dentistID, req.Status, req.Priority, req.StartDate, completionDateValue, req.Notes,
+		now, now,
+	).Scan(&id)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to insert patient treatment: %w", err)
+	}
+
+	// Fetch the created patient treatment with joined data
+	return r.GetPatientTreatment(ctx, id)
+}
+
+// UpdatePatientTreatment updates an existing patient treatment
+func (r *TreatmentRepository) UpdatePatientTreatment(ctx context.Context, id int, req models.UpdatePatientTreatmentRequest) (*models.PatientTreatment, error) {
+	// Build the update query dynamically based on provided fields
+	setParts := []string{"updated_at = $1"}
+	args := []interface{}{time.Now()}
+
+	argIndex := 2 // Start from $2 since $1 is used for updated_at
+
+	if req.PatientID != 0 {
+		setParts = append(setParts, "patient_id = $"+strconv.Itoa(argIndex))
+		args = append(args, req.PatientID)
+		argIndex++
+	}
+
+	if req.TreatmentID != 0 {
+		setParts = append(setParts, "treatment_id = $"+strconv.Itoa(argIndex))
+		args = append(args, req.TreatmentID)
+		argIndex++
+	}
+
+	if req.Status != "" {
+		setParts = append(setParts, "status = $"+strconv.Itoa(argIndex))
+		args = append(args, req.Status)
+		argIndex++
+	}
+
+	if req.Priority != "" {
+		setParts = append(setParts, "priority = $"+strconv.Itoa(argIndex))
+		args = append(args, req.Priority)
+		argIndex++
+	}
+
+	if req.StartDate != "" {
+		setParts = append(setParts, "start_date = $"+strconv.Itoa(argIndex))
+		args = append(args, req.StartDate)
+		argIndex++
+	}
+
+	if req.CompletionDate != nil {
+		if *req.CompletionDate == "" {
+			setParts = append(setParts, "completion_date = NULL")
+		} else {
+			setParts = append(setParts, "completion_date = $"+strconv.Itoa(argIndex))
+			args = append(args, *req.CompletionDate)
+			argIndex++
+		}
+	}
+
+	if req.Notes != "" {
+		setParts = append(setParts, "notes = $"+strconv.Itoa(argIndex))
+		args = append(args, req.Notes)
+		argIndex++
+	}
+
+	// Add the WHERE clause parameter
+	args = append(args, id)
+
+	query := fmt.Sprintf("UPDATE patient_treatments SET %s WHERE id = $%d",
+		strings.Join(setParts, ", "), argIndex)
+
+	result, err := conn(ctx, r.db).ExecContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update patient treatment: %w", err)
+	}
+
+	if err := expectRows(result); err != nil {
+		return nil, err
+	}
+
+	// Retrieve the updated patient treatment
+	return r.GetPatientTreatment(ctx, id)
+}
+
+// DeletePatientTreatment deletes a patient treatment by ID
+func (r *TreatmentRepository) DeletePatientTreatment(ctx context.Context, id int) error {
+	result, err := conn(ctx, r.db).ExecContext(ctx, "DELETE FROM patient_treatments WHERE id = $1", id)
+	if err != nil {
+		return fmt.Errorf("failed to delete patient treatment: %w", err)
+	}
+
+	return expectRows(result)
+}
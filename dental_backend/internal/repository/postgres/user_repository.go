@@ -0,0 +1,325 @@
+// dental_backend/internal/repository/postgres/user_repository.go
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"strconv"
+	"strings"
+
+	"dental_backend/internal/models"
+	"dental_backend/internal/repository"
+)
+
+const userColumns = `u.id, u.email, u.password_hash, u.first_name, u.last_name, u.role, COALESCE(u.phone, ''),
+	u.created_at, u.updated_at, u.email_verified, u.totp_enabled_at IS NOT NULL, u.deactivated_at IS NULL, u.deactivated_at`
+
+// memberColumns are userColumns with the user's role at the clinic joined as m
+var memberColumns = strings.Replace(userColumns, "u.role", "m.role", 1)
+
+// userSource returns the columns and table users u are selected with: with a
+// clinic in ctx, only the clinic's members with their role there. The clinic is
+// the first query argument.
+func userSource(ctx context.Context) (string, []interface{}) {
+	if clinicID, ok := repository.ClinicID(ctx); ok {
+		return memberColumns + " FROM users u JOIN clinic_memberships m ON m.user_id = u.id AND m.clinic_id = $1",
+			[]interface{}{clinicID}
+	}
+	return userColumns + " FROM users u", nil
+}
+
+// memberCondition limits a statement on users u to the members of the clinic of
+// ctx, if it has one, passed as argument n
+func memberCondition(ctx context.Context, n int) (string, []interface{}) {
+	if clinicID, ok := repository.ClinicID(ctx); ok {
+		return " AND EXISTS (SELECT 1 FROM clinic_memberships m WHERE m.user_id = u.id AND m.clinic_id = $" +
+			strconv.Itoa(n) + ")", []interface{}{clinicID}
+	}
+	return "", nil
+}
+
+// UserRepository is the PostgreSQL implementation of repository.UserRepository
+type UserRepository struct {
+	db *sql.DB
+}
+
+// NewUserRepository creates a new PostgreSQL user repository
+func NewUserRepository(db *sql.DB) *UserRepository {
+	return &UserRepository{db: db}
+}
+
+// scanUser scans a row selected with userColumns
+func scanUser(row interface{ Scan(...interface{}) error }, u *models.User) error {
+	return row.Scan(&u.ID, &u.Email, &u.Password, &u.FirstName, &u.LastName, &u.Role, &u.Phone,
+		&u.CreatedAt, &u.UpdatedAt, &u.EmailVerified, &u.MFAEnabled, &u.Active, &u.DeactivatedAt)
+}
+
+// mapUserError translates constraint violations into repository errors
+func mapUserError(err error) error {
+	switch {
+	case hasErrorCode(err, uniqueViolation):
+		return repository.ErrEmailTaken
+	case hasErrorCode(err, foreignKeyViolation):
+		return repository.ErrUserInUse
+	}
+	return err
+}
+
+// List retrieves users ordered by name
+func (r *UserRepository) List(ctx context.Context, filter repository.UserFilter) ([]models.User, error) {
+	source, args := userSource(ctx)
+	query := "SELECT " + source + " WHERE 1=1"
+	argIndex := len(args) + 1
+
+	if filter.Role != nil {
+		_, clinicScoped := repository.ClinicID(ctx)
+		if clinicScoped {
+			query += " AND m.role = $" + strconv.Itoa(argIndex)
+		} else {
+			query += " AND u.role = $" + strconv.Itoa(argIndex)
+		}
+		args = append(args, *filter.Role)
+		argIndex++
+	}
+	if filter.Active != nil {
+		if *filter.Active {
+			query += " AND u.deactivated_at IS NULL"
+		} else {
+			query += " AND u.deactivated_at IS NOT NULL"
+		}
+	}
+	if filter.Search != "" {
+		placeholder := "$" + strconv.Itoa(argIndex)
+		query += " AND (u.first_name ILIKE " + placeholder + " OR u.last_name ILIKE " + placeholder +
+			" OR u.email ILIKE " + placeholder + ")"
+		args = append(args, "%"+filter.Search+"%")
+		argIndex++
+	}
+
+	query += " ORDER BY u.last_name, u.first_name, u.id"
+
+	rows, err := conn(ctx, r.db).QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var users []models.User
+	for rows.Next() {
+		var u models.User
+		if err := scanUser(rows, &u); err != nil {
+			return nil, err
+		}
+		users = append(users, u)
+	}
+
+	return users, rows.Err()
+}
+
+// GetByID retrieves a user by ID
+func (r *UserRepository) GetByID(ctx context.Context, id int) (*models.User, error) {
+	source, args := userSource(ctx)
+	query := "SELECT " + source + " WHERE u.id = $" + strconv.Itoa(len(args)+1)
+
+	var user models.User
+	err := scanUser(conn(ctx, r.db).QueryRowContext(ctx, query, append(args, id)...), &user)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return &user, nil
+}
+
+// FindByEmail retrieves a user by email address
+func (r *UserRepository) FindByEmail(ctx context.Context, email string) (*models.User, error) {
+	var user models.User
+	err := scanUser(conn(ctx, r.db).QueryRowContext(ctx, "SELECT "+userColumns+" FROM users u WHERE u.email = $1", email), &user)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return &user, nil
+}
+
+// Create inserts a new user and adds them to the clinic of ctx, if it has one
+func (r *UserRepository) Create(ctx context.Context, req models.CreateUserRequest, passwordHash string) (*models.User, error) {
+	tx, err := conn(ctx, r.db).BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	var user models.User
+	err = scanUser(tx.QueryRowContext(ctx, `
+		INSERT INTO users AS u (email, password_hash, first_name, last_name, role, phone)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING `+userColumns,
+		req.Email, passwordHash, req.FirstName, req.LastName, req.Role, nullIfEmpty(req.Phone),
+	), &user)
+	if err != nil {
+		return nil, mapUserError(err)
+	}
+
+	if clinicID, ok := repository.ClinicID(ctx); ok {
+		if _, err := tx.ExecContext(ctx,
+			"INSERT INTO clinic_memberships (clinic_id, user_id, role) VALUES ($1, $2, $3)",
+			clinicID, user.ID, user.Role); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+// Update applies the non-empty fields of req. With a clinic in ctx the role is
+// changed on the user's membership of it.
+func (r *UserRepository) Update(ctx context.Context, id int, req models.UpdateUserRequest) (*models.User, error) {
+	tx, err := conn(ctx, r.db).BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	role := nullIfEmpty(req.Role)
+	clinicID, clinicScoped := repository.ClinicID(ctx)
+	var clinicRole string
+	if clinicScoped {
+		err := tx.QueryRowContext(ctx, `
+			UPDATE clinic_memberships SET role = COALESCE($1, role)
+			WHERE clinic_id = $2 AND user_id = $3
+			RETURNING role`,
+			role, clinicID, id,
+		).Scan(&clinicRole)
+		if err != nil {
+			if err == sql.ErrNoRows {
+				return nil, nil
+			}
+			return nil, err
+		}
+		role = nil
+	}
+
+	var user models.User
+	err = scanUser(tx.QueryRowContext(ctx, `
+		UPDATE users u SET
+			email_verified = email_verified AND COALESCE($1, email) = email,
+			email = COALESCE($1, email),
+			first_name = COALESCE($2, first_name),
+			last_name = COALESCE($3, last_name),
+			role = COALESCE($4, role),
+			phone = COALESCE($5, phone),
+			updated_at = NOW()
+		WHERE id = $6
+		RETURNING `+userColumns,
+		nullIfEmpty(req.Email), nullIfEmpty(req.FirstName), nullIfEmpty(req.LastName),
+		role, nullIfEmpty(req.Phone), id,
+	), &user)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, mapUserError(err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	if clinicScoped {
+		user.Role = clinicRole
+	}
+	return &user, nil
+}
+
+// SetPassword replaces the user's password hash
+func (r *UserRepository) SetPassword(ctx context.Context, id int, passwordHash string) error {
+	result, err := conn(ctx, r.db).ExecContext(ctx,
+		"UPDATE users SET password_hash = $1, updated_at = NOW() WHERE id = $2", passwordHash, id)
+	if err != nil {
+		return err
+	}
+
+	return expectRows(result)
+}
+
+// SetEmailVerified marks the user's email address as verified or not
+func (r *UserRepository) SetEmailVerified(ctx context.Context, id int, verified bool) error {
+	result, err := conn(ctx, r.db).ExecContext(ctx,
+		"UPDATE users SET email_verified = $1, updated_at = NOW() WHERE id = $2", verified, id)
+	if err != nil {
+		return err
+	}
+
+	return expectRows(result)
+}
+
+// SetActive clears or stamps deactivated_at; an already deactivated user keeps the original time
+func (r *UserRepository) SetActive(ctx context.Context, id int, active bool) error {
+	query := "UPDATE users u SET deactivated_at = COALESCE(deactivated_at, NOW()), updated_at = NOW() WHERE u.id = $1"
+	if active {
+		query = "UPDATE users u SET deactivated_at = NULL, updated_at = NOW() WHERE u.id = $1"
+	}
+	condition, args := memberCondition(ctx, 2)
+
+	result, err := conn(ctx, r.db).ExecContext(ctx, query+condition, append([]interface{}{id}, args...)...)
+	if err != nil {
+		return err
+	}
+
+	return expectRows(result)
+}
+
+// Delete deletes a user by ID. With a clinic in ctx the user must be one of its
+// members and work at no other clinic.
+func (r *UserRepository) Delete(ctx context.Context, id int) error {
+	if clinicID, ok := repository.ClinicID(ctx); ok {
+		var member, elsewhere bool
+		err := conn(ctx, r.db).QueryRowContext(ctx, `
+			SELECT COALESCE(bool_or(clinic_id = $2), FALSE), COALESCE(bool_or(clinic_id <> $2), FALSE)
+			FROM clinic_memberships
+			WHERE user_id = $1`,
+			id, clinicID).Scan(&member, &elsewhere)
+		if err != nil {
+			return err
+		}
+		if !member {
+			return sql.ErrNoRows
+		}
+		if elsewhere {
+			return repository.ErrUserInUse
+		}
+	}
+
+	result, err := conn(ctx, r.db).ExecContext(ctx, "DELETE FROM users WHERE id = $1", id)
+	if err != nil {
+		return mapUserError(err)
+	}
+
+	return expectRows(result)
+}
+
+// CountActiveAdmins counts active users with the admin role
+func (r *UserRepository) CountActiveAdmins(ctx context.Context) (int, error) {
+	query := "SELECT COUNT(*) FROM users u WHERE u.role = 'admin' AND u.deactivated_at IS NULL"
+	var args []interface{}
+	if clinicID, ok := repository.ClinicID(ctx); ok {
+		query = `
+			SELECT COUNT(*)
+			FROM clinic_memberships m
+			JOIN users u ON u.id = m.user_id
+			WHERE m.clinic_id = $1 AND m.role = 'admin' AND u.deactivated_at IS NULL`
+		args = append(args, clinicID)
+	}
+
+	var count int
+	err := conn(ctx, r.db).QueryRowContext(ctx, query, args...).Scan(&count)
+	return count, err
+}
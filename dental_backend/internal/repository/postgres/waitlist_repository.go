@@ -0,0 +1,276 @@
+// dental_backend/internal/repository/postgres/waitlist_repository.go
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"strconv"
+	"time"
+
+	"github.com/lib/pq"
+
+	"dental_backend/internal/models"
+	"dental_backend/internal/repository"
+)
+
+const waitlistEntrySelect = `
+		SELECT w.id, w.patient_id, p.first_name || ' ' || p.last_name,
+		       w.dentist_ids, w.weekdays,
+		       COALESCE(to_char(w.earliest_time, 'HH24:MI'), ''), COALESCE(to_char(w.latest_time, 'HH24:MI'), ''),
+		       w.duration_minutes, w.treatment_id, w.urgency, w.status, w.notes, w.created_at, w.updated_at
+		FROM waitlist_entries w
+		JOIN patients p ON w.patient_id = p.id`
+
+const slotOpeningColumns = `id, dentist_id, to_char(appointment_date, 'YYYY-MM-DD'),
+		       to_char(start_time, 'HH24:MI'), to_char(end_time, 'HH24:MI'),
+		       appointment_id, status, held_entry_id, hold_expires_at, created_at, updated_at`
+
+// WaitlistRepository is the PostgreSQL implementation of repository.WaitlistRepository
+type WaitlistRepository struct {
+	db *sql.DB
+}
+
+// NewWaitlistRepository creates a new PostgreSQL waitlist repository
+func NewWaitlistRepository(db *sql.DB) *WaitlistRepository {
+	return &WaitlistRepository{db: db}
+}
+
+// scanWaitlistEntry scans a row selected with waitlistEntrySelect
+func scanWaitlistEntry(row interface{ Scan(...interface{}) error }, e *models.WaitlistEntry) error {
+	var dentistIDs, weekdays pq.Int64Array
+	err := row.Scan(
+		&e.ID, &e.PatientID, &e.PatientName,
+		&dentistIDs, &weekdays,
+		&e.EarliestTime, &e.LatestTime,
+		&e.Duration, &e.TreatmentID, &e.Urgency, &e.Status, &e.Notes, &e.CreatedAt, &e.UpdatedAt,
+	)
+	if err != nil {
+		return err
+	}
+	e.DentistIDs = intsFromArray(dentistIDs)
+	e.Weekdays = intsFromArray(weekdays)
+	return nil
+}
+
+// scanSlotOpening scans a row selected with slotOpeningColumns
+func scanSlotOpening(row interface{ Scan(...interface{}) error }, o *models.SlotOpening) error {
+	return row.Scan(
+		&o.ID, &o.DentistID, &o.AppointmentDate, &o.StartTime, &o.EndTime,
+		&o.AppointmentID, &o.Status, &o.HeldEntryID, &o.HoldExpiresAt, &o.CreatedAt, &o.UpdatedAt,
+	)
+}
+
+// ListEntries retrieves waitlist entries matching the filter, longest waiting first
+func (r *WaitlistRepository) ListEntries(ctx context.Context, filter repository.WaitlistFilter) ([]models.WaitlistEntry, error) {
+	query := waitlistEntrySelect + `
+		WHERE 1=1`
+	args := []interface{}{}
+	argIndex := 1
+
+	if filter.Status != nil {
+		query += " AND w.status = $" + strconv.Itoa(argIndex)
+		args = append(args, *filter.Status)
+		argIndex++
+	}
+	if filter.DentistID != nil {
+		query += " AND (cardinality(w.dentist_ids) = 0 OR $" + strconv.Itoa(argIndex) + " = ANY(w.dentist_ids))"
+		args = append(args, *filter.DentistID)
+		argIndex++
+	}
+	if filter.PatientID != nil {
+		query += " AND w.patient_id = $" + strconv.Itoa(argIndex)
+		args = append(args, *filter.PatientID)
+		argIndex++
+	}
+
+	query += " ORDER BY w.created_at ASC, w.id ASC"
+
+	rows, err := conn(ctx, r.db).QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var entries []models.WaitlistEntry
+	for rows.Next() {
+		var e models.WaitlistEntry
+		if err := scanWaitlistEntry(rows, &e); err != nil {
+			return nil, err
+		}
+		entries = append(entries, e)
+	}
+
+	return entries, rows.Err()
+}
+
+// GetEntry retrieves a waitlist entry by ID
+func (r *WaitlistRepository) GetEntry(ctx context.Context, id int) (*models.WaitlistEntry, error) {
+	var entry models.WaitlistEntry
+	err := scanWaitlistEntry(conn(ctx, r.db).QueryRowContext(ctx, waitlistEntrySelect+`
+		WHERE w.id = $1`, id), &entry)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return &entry, nil
+}
+
+// CreateEntry inserts a new waitlist entry
+func (r *WaitlistRepository) CreateEntry(ctx context.Context, entry models.WaitlistEntry) (*models.WaitlistEntry, error) {
+	var id int
+	err := conn(ctx, r.db).QueryRowContext(ctx, `
+		INSERT INTO waitlist_entries (
+			patient_id, dentist_ids, weekdays, earliest_time, latest_time, duration_minutes,
+			treatment_id, urgency, status, notes, created_at, updated_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, NOW(), NOW())
+		RETURNING id`,
+		entry.PatientID, int64Array(entry.DentistIDs), int64Array(entry.Weekdays),
+		nullIfEmpty(entry.EarliestTime), nullIfEmpty(entry.LatestTime), entry.Duration,
+		entry.TreatmentID, entry.Urgency, entry.Status, entry.Notes,
+	).Scan(&id)
+	if err != nil {
+		return nil, err
+	}
+
+	return r.GetEntry(ctx, id)
+}
+
+// UpdateEntry replaces the preferences and status of a waitlist entry
+func (r *WaitlistRepository) UpdateEntry(ctx context.Context, entry models.WaitlistEntry) (*models.WaitlistEntry, error) {
+	result, err := conn(ctx, r.db).ExecContext(ctx, `
+		UPDATE waitlist_entries
+		SET dentist_ids = $1, weekdays = $2, earliest_time = $3, latest_time = $4, duration_minutes = $5,
+		    urgency = $6, status = $7, notes = $8, updated_at = NOW()
+		WHERE id = $9`,
+		int64Array(entry.DentistIDs), int64Array(entry.Weekdays),
+		nullIfEmpty(entry.EarliestTime), nullIfEmpty(entry.LatestTime), entry.Duration,
+		entry.Urgency, entry.Status, entry.Notes, entry.ID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if err := expectRows(result); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return r.GetEntry(ctx, entry.ID)
+}
+
+// DeleteEntry deletes a waitlist entry
+func (r *WaitlistRepository) DeleteEntry(ctx context.Context, id int) error {
+	result, err := conn(ctx, r.db).ExecContext(ctx, "DELETE FROM waitlist_entries WHERE id = $1", id)
+	if err != nil {
+		return err
+	}
+
+	return expectRows(result)
+}
+
+// CreateOpening records a freed slot
+func (r *WaitlistRepository) CreateOpening(ctx context.Context, opening models.SlotOpening) (*models.SlotOpening, error) {
+	var created models.SlotOpening
+	err := scanSlotOpening(conn(ctx, r.db).QueryRowContext(ctx, `
+		INSERT INTO slot_openings (
+			dentist_id, appointment_date, start_time, end_time, appointment_id,
+			status, held_entry_id, hold_expires_at, created_at, updated_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, NOW(), NOW())
+		RETURNING `+slotOpeningColumns,
+		opening.DentistID, opening.AppointmentDate, opening.StartTime, opening.EndTime, opening.AppointmentID,
+		opening.Status, opening.HeldEntryID, opening.HoldExpiresAt,
+	), &created)
+	if err != nil {
+		return nil, err
+	}
+
+	return &created, nil
+}
+
+// GetOpening retrieves an opening, restricted to a dentist when dentistID is set
+func (r *WaitlistRepository) GetOpening(ctx context.Context, id int, dentistID *int) (*models.SlotOpening, error) {
+	query := "SELECT " + slotOpeningColumns + " FROM slot_openings WHERE id = $1"
+	args := []interface{}{id}
+	if dentistID != nil {
+		query += " AND dentist_id = $2"
+		args = append(args, *dentistID)
+	}
+
+	var opening models.SlotOpening
+	err := scanSlotOpening(conn(ctx, r.db).QueryRowContext(ctx, query, args...), &opening)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return &opening, nil
+}
+
+// ListOpenings retrieves unfilled openings on or after a date
+func (r *WaitlistRepository) ListOpenings(ctx context.Context, dentistID *int, from string) ([]models.SlotOpening, error) {
+	query := "SELECT " + slotOpeningColumns + " FROM slot_openings WHERE status <> 'filled' AND appointment_date >= $1"
+	args := []interface{}{from}
+	if dentistID != nil {
+		query += " AND dentist_id = $2"
+		args = append(args, *dentistID)
+	}
+	query += " ORDER BY appointment_date ASC, start_time ASC"
+
+	return r.queryOpenings(ctx, query, args...)
+}
+
+// UpdateOpening replaces the status and hold of an opening
+func (r *WaitlistRepository) UpdateOpening(ctx context.Context, opening models.SlotOpening) (*models.SlotOpening, error) {
+	var updated models.SlotOpening
+	err := scanSlotOpening(conn(ctx, r.db).QueryRowContext(ctx, `
+		UPDATE slot_openings
+		SET status = $1, held_entry_id = $2, hold_expires_at = $3, updated_at = NOW()
+		WHERE id = $4
+		RETURNING `+slotOpeningColumns,
+		opening.Status, opening.HeldEntryID, opening.HoldExpiresAt, opening.ID,
+	), &updated)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return &updated, nil
+}
+
+// Holds retrieves the dentist's openings still held at now that intersect the window
+func (r *WaitlistRepository) Holds(ctx context.Context, dentistID int, window models.AppointmentWindow, now time.Time) ([]models.SlotOpening, error) {
+	return r.queryOpenings(ctx, "SELECT "+slotOpeningColumns+` FROM slot_openings
+		WHERE dentist_id = $1 AND appointment_date = $2
+		  AND start_time < $3 AND end_time > $4
+		  AND status = 'held' AND hold_expires_at > $5
+		ORDER BY start_time ASC`,
+		dentistID, window.Date, models.FormatClock(window.End), models.FormatClock(window.Start), now)
+}
+
+// queryOpenings runs a query selecting slotOpeningColumns
+func (r *WaitlistRepository) queryOpenings(ctx context.Context, query string, args ...interface{}) ([]models.SlotOpening, error) {
+	rows, err := conn(ctx, r.db).QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var openings []models.SlotOpening
+	for rows.Next() {
+		var o models.SlotOpening
+		if err := scanSlotOpening(rows, &o); err != nil {
+			return nil, err
+		}
+		openings = append(openings, o)
+	}
+
+	return openings, rows.Err()
+}
@@ -0,0 +1,426 @@
+// dental_backend/internal/repository/repository.go
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"dental_backend/internal/models"
+)
+
+// Lookups that find nothing return (nil, nil). Deletes, and the updates that
+// document it, return sql.ErrNoRows when no row matched so handlers can keep
+// mapping that error to a 404 regardless of the implementation in use.
+
+// ErrOverlap is returned when a write would give a dentist two active
+// appointments at the same time
+var ErrOverlap = errors.New("appointment overlaps an existing booking")
+
+// ErrStaleStatus is returned when a conditional status change finds the
+// appointment in a different status than the caller expected
+var ErrStaleStatus = errors.New("appointment status has changed")
+
+// ErrEmailTaken is returned when creating or updating a user would duplicate an email address
+var ErrEmailTaken = errors.New("email address is already in use")
+
+// ErrUserInUse is returned when deleting a user who is still referenced by clinical
+// records or works at another clinic
+var ErrUserInUse = errors.New("user is referenced by other records")
+
+// ErrIdentityTaken is returned when linking a provider account that is already
+// linked, or a second account at the same provider to one user
+var ErrIdentityTaken = errors.New("identity is already linked")
+
+// PatientRepository persists patients
+type PatientRepository interface {
+	// List returns patients newest first, optionally filtered by a search term
+	// matched against name, email and phone
+	List(ctx context.Context, search string) ([]models.Patient, error)
+	GetByID(ctx context.Context, id int) (*models.Patient, error)
+	Create(ctx context.Context, req models.CreatePatientRequest) (*models.Patient, error)
+	// Update applies the non-empty fields of req and returns (nil, nil) when the patient does not exist
+	Update(ctx context.Context, id int, req models.UpdatePatientRequest) (*models.Patient, error)
+	Delete(ctx context.Context, id int) error
+	// FindByEmailAndDOB returns the patient with the email, compared case-insensitively,
+	// and date of birth, or (nil, nil) when there is none
+	FindByEmailAndDOB(ctx context.Context, email, dateOfBirth string) (*models.Patient, error)
+	// Count returns the number of patients, restricted to a risk level when one is given
+	Count(ctx context.Context, riskLevel string) (int, error)
+}
+
+// AppointmentFilter narrows an appointment listing. A nil DentistID lists
+// every dentist's appointments; From and To bound the date range inclusively.
+type AppointmentFilter struct {
+	DentistID *int
+	Date      *string
+	From      *string
+	To        *string
+	Status    *string
+	PatientID *int
+	SeriesID  *int
+}
+
+// AppointmentRepository persists appointments
+type AppointmentRepository interface {
+	List(ctx context.Context, filter AppointmentFilter) ([]models.Appointment, error)
+	// GetByID looks an appointment up within one dentist's calendar, or the whole clinic when dentistID is nil
+	GetByID(ctx context.Context, id int, dentistID *int) (*models.Appointment, error)
+	// Create records the initial status as the first event and returns ErrOverlap when
+	// the new appointment would double-book the dentist
+	Create(ctx context.Context, req models.CreateAppointmentRequest, dentistID int) (*models.Appointment, error)
+	// Update applies the non-empty fields of req and returns (nil, nil) when the appointment does not exist.
+	// It returns ErrOverlap when the result would double-book the dentist, and ErrStaleStatus when
+	// req.FromStatus is set and no longer matches. A status change stamps its timestamp column and
+	// is recorded as an event attributed to ActorID(ctx) and ActorType(ctx). Sequence is incremented
+	// when the date, start time or duration changes or the appointment is cancelled.
+	Update(ctx context.Context, id int, req models.UpdateAppointmentRequest, dentistID int) (*models.Appointment, error)
+	Delete(ctx context.Context, id int, dentistID int) error
+
+	// Events returns the status history of an appointment, oldest first
+	Events(ctx context.Context, appointmentID int) ([]models.AppointmentEvent, error)
+
+	// Overlapping returns the dentist's active appointments intersecting window, ignoring excludeID
+	Overlapping(ctx context.Context, dentistID int, window models.AppointmentWindow, excludeID int) ([]models.Appointment, error)
+}
+
+// SeriesRepository persists recurring appointment series
+type SeriesRepository interface {
+	Create(ctx context.Context, series models.AppointmentSeries) (*models.AppointmentSeries, error)
+	// GetByID looks a series up within one dentist's calendar, or the whole clinic when dentistID is nil
+	GetByID(ctx context.Context, id int, dentistID *int) (*models.AppointmentSeries, error)
+	// Update replaces the series template and returns (nil, nil) when the series does not exist
+	Update(ctx context.Context, series models.AppointmentSeries) (*models.AppointmentSeries, error)
+	Delete(ctx context.Context, id int, dentistID int) error
+}
+
+// WaitlistFilter narrows a waitlist listing. DentistID matches entries that
+// accept that dentist, including those with no dentist preference.
+type WaitlistFilter struct {
+	Status    *string
+	DentistID *int
+	PatientID *int
+}
+
+// WaitlistRepository persists waitlist entries and the slot openings offered to them
+type WaitlistRepository interface {
+	// ListEntries returns matching entries, longest waiting first
+	ListEntries(ctx context.Context, filter WaitlistFilter) ([]models.WaitlistEntry, error)
+	GetEntry(ctx context.Context, id int) (*models.WaitlistEntry, error)
+	CreateEntry(ctx context.Context, entry models.WaitlistEntry) (*models.WaitlistEntry, error)
+	// UpdateEntry replaces an entry's preferences and status and returns (nil, nil) when it does not exist
+	UpdateEntry(ctx context.Context, entry models.WaitlistEntry) (*models.WaitlistEntry, error)
+	DeleteEntry(ctx context.Context, id int) error
+
+	CreateOpening(ctx context.Context, opening models.SlotOpening) (*models.SlotOpening, error)
+	// GetOpening looks an opening up within one dentist's calendar, or the whole clinic when dentistID is nil
+	GetOpening(ctx context.Context, id int, dentistID *int) (*models.SlotOpening, error)
+	// ListOpenings returns unfilled openings on or after the from date ordered by date and time
+	ListOpenings(ctx context.Context, dentistID *int, from string) ([]models.SlotOpening, error)
+	// UpdateOpening replaces an opening's status and hold and returns (nil, nil) when it does not exist
+	UpdateOpening(ctx context.Context, opening models.SlotOpening) (*models.SlotOpening, error)
+	// Holds returns the dentist's openings still held at now that intersect window
+	Holds(ctx context.Context, dentistID int, window models.AppointmentWindow, now time.Time) ([]models.SlotOpening, error)
+}
+
+// ReminderRepository records appointment reminders so each is sent at most once
+type ReminderRepository interface {
+	// Claim inserts a delivery in the sending status and returns it, or re-claims a failed
+	// one that has attempts left. It returns (nil, nil) when the reminder was already sent,
+	// is being sent, or has used up its attempts.
+	Claim(ctx context.Context, delivery models.ReminderDelivery) (*models.ReminderDelivery, error)
+	// Finish records the outcome of a claimed delivery; an empty sendErr marks it sent
+	Finish(ctx context.Context, id int, sendErr string) error
+	// ListForAppointment returns an appointment's deliveries, oldest first
+	ListForAppointment(ctx context.Context, appointmentID int) ([]models.ReminderDelivery, error)
+}
+
+// AppointmentLinkRepository records spent self-service link tokens
+type AppointmentLinkRepository interface {
+	// Use spends a token on an action and reports false when it had already been spent
+	Use(ctx context.Context, use models.AppointmentLinkUse) (bool, error)
+	// Used reports whether a token has been spent
+	Used(ctx context.Context, tokenID string) (bool, error)
+	// Release makes a token usable again after the action it was spent on failed
+	Release(ctx context.Context, tokenID string) error
+}
+
+// TreatmentRepository persists the treatment catalogue and patient treatments
+type TreatmentRepository interface {
+	List(ctx context.Context) ([]models.Treatment, error)
+	GetByID(ctx context.Context, id int) (*models.Treatment, error)
+	Create(ctx context.Context, req models.CreateTreatmentRequest) (*models.Treatment, error)
+	// Update returns sql.ErrNoRows when the treatment does not exist
+	Update(ctx context.Context, id int, req models.UpdateTreatmentRequest) (*models.Treatment, error)
+	Delete(ctx context.Context, id int) error
+
+	// Queue returns pending and in-progress patient treatments, for one dentist when dentistID is set
+	Queue(ctx context.Context, dentistID *int) ([]models.PatientTreatment, error)
+	ListForPatient(ctx context.Context, patientID int) ([]models.PatientTreatment, error)
+	GetPatientTreatment(ctx context.Context, id int) (*models.PatientTreatment, error)
+	CreatePatientTreatment(ctx context.Context, req models.CreatePatientTreatmentRequest, dentistID int) (*models.PatientTreatment, error)
+	// UpdatePatientTreatment returns sql.ErrNoRows when the patient treatment does not exist
+	UpdatePatientTreatment(ctx context.Context, id int, req models.UpdatePatientTreatmentRequest) (*models.PatientTreatment, error)
+	DeletePatientTreatment(ctx context.Context, id int) error
+}
+
+// MedicalEntryRepository persists the allergies, medications and conditions of patients
+type MedicalEntryRepository interface {
+	// ListForPatient returns a patient's entries of every kind, active ones
+	// first, then by onset, newest first
+	ListForPatient(ctx context.Context, patientID int) ([]models.MedicalEntry, error)
+	// ListActive returns the active entries of the patients
+	ListActive(ctx context.Context, patientIDs []int) ([]models.MedicalEntry, error)
+	// GetByID returns an entry or (nil, nil)
+	GetByID(ctx context.Context, id int) (*models.MedicalEntry, error)
+	Create(ctx context.Context, entry models.MedicalEntry) (*models.MedicalEntry, error)
+	// Update writes every field of entry but its patient and kind, and returns
+	// sql.ErrNoRows when it does not exist
+	Update(ctx context.Context, entry models.MedicalEntry) (*models.MedicalEntry, error)
+	// Delete returns sql.ErrNoRows when the entry does not exist
+	Delete(ctx context.Context, id int) error
+}
+
+// RiskRepository stores the risk scoring rules, shared by every clinic, and the
+// risk assessment derived for each patient
+type RiskRepository interface {
+	// Rules returns the rules configured for the clinic of the context, or (nil, nil)
+	// while the defaults apply
+	Rules(ctx context.Context) (*models.RiskRules, error)
+	// PatientRules returns the rules configured for the clinic the patient belongs
+	// to, or (nil, nil) while the defaults apply or the patient does not exist
+	PatientRules(ctx context.Context, patientID int) (*models.RiskRules, error)
+	// SetRules stores the rules of the clinic of the context
+	SetRules(ctx context.Context, rules models.RiskRules) error
+
+	// GetAssessment returns a patient's latest assessment or (nil, nil) when they have not been assessed
+	GetAssessment(ctx context.Context, patientID int) (*models.RiskAssessment, error)
+	// SaveAssessment replaces a patient's assessment
+	SaveAssessment(ctx context.Context, assessment models.RiskAssessment) error
+	// CountByLevel returns the number of assessed patients at each derived level
+	CountByLevel(ctx context.Context) (map[string]int, error)
+}
+
+// BillingRepository persists invoices and insurance claims
+type BillingRepository interface {
+	Stats(ctx context.Context) (*models.BillingStats, error)
+
+	ListInvoices(ctx context.Context, status, patientID string) ([]models.Invoice, error)
+	GetInvoice(ctx context.Context, id int) (*models.Invoice, error)
+	CreateInvoice(ctx context.Context, req models.CreateInvoiceRequest) (*models.Invoice, error)
+	// UpdateInvoice returns sql.ErrNoRows when the invoice does not exist
+	UpdateInvoice(ctx context.Context, id int, req models.UpdateInvoiceRequest) (*models.Invoice, error)
+	DeleteInvoice(ctx context.Context, id int) error
+
+	ListClaims(ctx context.Context, status, patientID string) ([]models.InsuranceClaim, error)
+	GetClaim(ctx context.Context, id int) (*models.InsuranceClaim, error)
+	CreateClaim(ctx context.Context, req models.CreateInsuranceClaimRequest) (*models.InsuranceClaim, error)
+	// UpdateClaim returns sql.ErrNoRows when the claim does not exist
+	UpdateClaim(ctx context.Context, id int, req models.UpdateInsuranceClaimRequest) (*models.InsuranceClaim, error)
+	DeleteClaim(ctx context.Context, id int) error
+}
+
+// ScheduleRepository persists dentist working hours, breaks and closures
+type ScheduleRepository interface {
+	// IsDentist reports whether userID belongs to a user with the dentist role, at
+	// the clinic of the context when it has one
+	IsDentist(ctx context.Context, userID int) (bool, error)
+	// Dentists lists every active user with the dentist role ordered by name, at
+	// the clinic of the context when it has one
+	Dentists(ctx context.Context) ([]models.Dentist, error)
+
+	// WorkingHours and Breaks return the dentist's week at the clinic of the context,
+	// and nothing for a context without one
+	WorkingHours(ctx context.Context, dentistID int) ([]models.WorkingHours, error)
+	Breaks(ctx context.Context, dentistID int) ([]models.ScheduleBreak, error)
+	// ReplaceWeek atomically replaces the dentist's working hours and breaks at the
+	// clinic of the context
+	ReplaceWeek(ctx context.Context, dentistID int, hours []models.WorkingHours, breaks []models.ScheduleBreak) error
+
+	// Closures returns closures intersecting the inclusive date range; empty bounds are open.
+	// When dentistID is set only that dentist's and clinic-wide closures are returned, and
+	// a context with a clinic sees only that clinic's closures.
+	Closures(ctx context.Context, dentistID *int, from, to string) ([]models.Closure, error)
+	GetClosure(ctx context.Context, id int) (*models.Closure, error)
+	CreateClosure(ctx context.Context, req models.CreateClosureRequest) (*models.Closure, error)
+	DeleteClosure(ctx context.Context, id int) error
+}
+
+// CalendarFeedRepository stores calendar feed subscriptions by token hash
+type CalendarFeedRepository interface {
+	Create(ctx context.Context, feed models.CalendarFeed, tokenHash string) (*models.CalendarFeed, error)
+	// FindActive returns the unrevoked feed of an active user with the token hash, with its owner's role
+	// at the feed's clinic, and records the access; it returns (nil, nil) when there is none or the owner
+	// no longer works at the clinic
+	FindActive(ctx context.Context, tokenHash string) (*models.CalendarFeed, error)
+	// ListForUser returns a user's feeds at the clinic of ctx, newest first, including revoked ones
+	ListForUser(ctx context.Context, userID int) ([]models.CalendarFeed, error)
+	// Revoke revokes a feed at the clinic of ctx owned by userID, or by anyone when userID is nil,
+	// and returns sql.ErrNoRows when there is no such active feed
+	Revoke(ctx context.Context, id int, userID *int) error
+}
+
+// UserFilter narrows a user listing; nil fields are not filtered on
+type UserFilter struct {
+	Role   *string
+	Active *bool
+	Search string
+}
+
+// UserRepository stores staff user accounts. Accounts are shared by every clinic:
+// with a clinic in the context, List, GetByID, Update, SetActive, Delete and
+// CountActiveAdmins see only the clinic's members and give their role there.
+// Without one they see every account, as signing in does.
+type UserRepository interface {
+	List(ctx context.Context, filter UserFilter) ([]models.User, error)
+	// GetByID returns a user, with the password hash in Password, or (nil, nil) when it does not exist
+	GetByID(ctx context.Context, id int) (*models.User, error)
+	// FindByEmail returns the user with the email address or (nil, nil)
+	FindByEmail(ctx context.Context, email string) (*models.User, error)
+	// Create returns ErrEmailTaken when the email address is in use. The new user
+	// joins the clinic of the context with their role; without one they join no
+	// clinic and cannot sign in until an admin adds them to one.
+	Create(ctx context.Context, req models.CreateUserRequest, passwordHash string) (*models.User, error)
+	// Update applies the non-empty fields of req, returns (nil, nil) when the user does not
+	// exist and ErrEmailTaken when the new email address is in use. Changing the email
+	// address clears EmailVerified. With a clinic in the context the role changed is
+	// the user's role there.
+	Update(ctx context.Context, id int, req models.UpdateUserRequest) (*models.User, error)
+	// SetPassword returns sql.ErrNoRows when the user does not exist
+	SetPassword(ctx context.Context, id int, passwordHash string) error
+	// SetEmailVerified returns sql.ErrNoRows when the user does not exist
+	SetEmailVerified(ctx context.Context, id int, verified bool) error
+	// SetActive deactivates or reactivates a user and returns sql.ErrNoRows when the user does not exist
+	SetActive(ctx context.Context, id int, active bool) error
+	// Delete returns sql.ErrNoRows when the user does not exist and ErrUserInUse when
+	// appointments or other records still refer to them, or they work at another clinic
+	Delete(ctx context.Context, id int) error
+	// CountActiveAdmins returns the number of active users with the admin role
+	CountActiveAdmins(ctx context.Context) (int, error)
+}
+
+// SessionRepository stores login sessions by the hash of their current refresh token
+type SessionRepository interface {
+	Create(ctx context.Context, session models.Session, tokenHash string) (*models.Session, error)
+	// GetActive returns an unrevoked, unexpired session or (nil, nil)
+	GetActive(ctx context.Context, id string) (*models.Session, error)
+	// Rotate replaces the refresh token hash of the active session holding oldHash, keeping
+	// oldHash as the previous one, and extends it to expiresAt. It returns (nil, nil) when no
+	// active session holds oldHash.
+	Rotate(ctx context.Context, oldHash, newHash string, expiresAt time.Time, client models.SessionClient) (*models.Session, error)
+	// RevokeByPreviousHash revokes the session whose previous refresh token hash is tokenHash,
+	// reporting whether there was one
+	RevokeByPreviousHash(ctx context.Context, tokenHash string) (bool, error)
+	// Revoke revokes an active session of the user and returns sql.ErrNoRows when there is none
+	Revoke(ctx context.Context, id string, userID int) error
+	// RevokeAll revokes every active session of the user except exceptID (which may be
+	// empty) and returns how many there were
+	RevokeAll(ctx context.Context, userID int, exceptID string) (int, error)
+	// ListActive returns the user's active sessions, most recently used first
+	ListActive(ctx context.Context, userID int) ([]models.Session, error)
+	// SetClinic changes the clinic of an active session and returns sql.ErrNoRows when there is none
+	SetClinic(ctx context.Context, id string, clinicID int) error
+}
+
+// AccountTokenRepository stores emailed single-use tokens by their hash
+type AccountTokenRepository interface {
+	// Create stores a token and marks the user's earlier unused tokens for the same purpose as used
+	Create(ctx context.Context, token models.AccountToken, tokenHash string) (*models.AccountToken, error)
+	// Consume marks an unused, unexpired token as used and returns it, or (nil, nil) when
+	// there is no such token for the purpose
+	Consume(ctx context.Context, purpose models.AccountTokenPurpose, tokenHash string) (*models.AccountToken, error)
+}
+
+// IdentityRepository stores the external provider accounts linked to users
+type IdentityRepository interface {
+	// Find returns the identity for the provider's subject or (nil, nil)
+	Find(ctx context.Context, provider, subject string) (*models.UserIdentity, error)
+	// Create links an identity and returns ErrIdentityTaken when it, or another identity
+	// at the same provider for the user, is already linked
+	Create(ctx context.Context, identity models.UserIdentity) (*models.UserIdentity, error)
+	// RecordLogin stamps the identity's last sign-in
+	RecordLogin(ctx context.Context, id int) error
+	// ListByUser returns the user's identities by provider
+	ListByUser(ctx context.Context, userID int) ([]models.UserIdentity, error)
+}
+
+// MFARepository stores users' TOTP secrets and hashed backup codes
+type MFARepository interface {
+	// Get returns the user's two-factor state or (nil, nil) when the user does not exist
+	Get(ctx context.Context, userID int) (*models.UserMFA, error)
+	// SetSecret stores a secret awaiting confirmation and returns sql.ErrNoRows when the user does not exist
+	SetSecret(ctx context.Context, userID int, secret string) error
+	// Enable turns on the stored secret, recording step as used, and replaces the backup codes
+	Enable(ctx context.Context, userID int, step int64, codeHashes []string) error
+	// Disable removes the secret and backup codes
+	Disable(ctx context.Context, userID int) error
+	// ReplaceBackupCodes discards the user's backup codes and stores new ones
+	ReplaceBackupCodes(ctx context.Context, userID int, codeHashes []string) error
+	// UseStep records a code's time step as used, reporting false when it or a later
+	// step was already used
+	UseStep(ctx context.Context, userID int, step int64) (bool, error)
+	// UseBackupCode marks an unused backup code as used, reporting whether there was one
+	UseBackupCode(ctx context.Context, userID int, codeHash string) (bool, error)
+}
+
+// AuthPolicyRepository stores each clinic's sign-in policy
+type AuthPolicyRepository interface {
+	// Get returns the policy of the clinic of the context, or the defaults when
+	// it has none configured or the context has no clinic
+	Get(ctx context.Context) (*models.AuthPolicy, error)
+	// Update applies the non-nil fields of req to the policy of the clinic of the context
+	Update(ctx context.Context, req models.UpdateAuthPolicyRequest) (*models.AuthPolicy, error)
+}
+
+// ClinicRepository stores clinics and the users who work at them. Clinics and
+// memberships are shared by the whole group, so they are not limited to the
+// clinic of the context.
+type ClinicRepository interface {
+	List(ctx context.Context) ([]models.Clinic, error)
+	// GetByID returns a clinic or (nil, nil)
+	GetByID(ctx context.Context, id int) (*models.Clinic, error)
+	// Default returns the first clinic, which users who sign themselves up join
+	Default(ctx context.Context) (*models.Clinic, error)
+	// Create opens a clinic with ownerID as its first admin
+	Create(ctx context.Context, req models.CreateClinicRequest, ownerID int) (*models.Clinic, error)
+	// Update applies the non-nil fields of req and returns sql.ErrNoRows when the clinic does not exist
+	Update(ctx context.Context, id int, req models.UpdateClinicRequest) (*models.Clinic, error)
+
+	// ListForUser returns the clinics a user works at, by clinic ID
+	ListForUser(ctx context.Context, userID int) ([]models.UserClinic, error)
+	// Membership returns the user's membership of the clinic or (nil, nil)
+	Membership(ctx context.Context, clinicID, userID int) (*models.UserClinic, error)
+	// Members returns the users working at a clinic by name
+	Members(ctx context.Context, clinicID int) ([]models.ClinicMember, error)
+	// SetMember adds a user to a clinic or changes their role there, and returns
+	// sql.ErrNoRows when the user does not exist
+	SetMember(ctx context.Context, clinicID, userID int, role string) error
+	// RemoveMember returns sql.ErrNoRows when the user is not a member
+	RemoveMember(ctx context.Context, clinicID, userID int) error
+	// CountActiveAdmins returns the number of active users with the admin role at the clinic
+	CountActiveAdmins(ctx context.Context, clinicID int) (int, error)
+}
+
+// AuditFilter selects audit entries; nil and empty fields match every entry
+type AuditFilter struct {
+	ClinicID     *int
+	ActorID      *int
+	Action       string
+	ResourceType string
+	ResourceID   string
+	From         *time.Time
+	To           *time.Time
+	Limit        int
+	Offset       int
+}
+
+// AuditRepository stores the append-only audit trail. It spans every clinic,
+// so the hash chain can be checked as a whole.
+type AuditRepository interface {
+	// Append seals entry onto the end of the chain, filling in its ID, PrevHash and Hash
+	Append(ctx context.Context, entry models.AuditEntry) (*models.AuditEntry, error)
+	// List returns matching entries, newest first
+	List(ctx context.Context, filter AuditFilter) ([]models.AuditEntry, error)
+	// Chain returns up to limit entries after afterID in chain order
+	Chain(ctx context.Context, afterID int64, limit int) ([]models.AuditEntry, error)
+}
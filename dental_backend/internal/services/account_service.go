@@ -0,0 +1,226 @@
+// dental_backend/internal/services/account_service.go
+package services
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"net/url"
+	"time"
+
+	"dental_backend/internal/models"
+	"dental_backend/internal/notify"
+	"dental_backend/internal/repository"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// ErrInvalidAccountToken is returned for password reset and verification tokens
+// that are unknown, expired or already used
+var ErrInvalidAccountToken = errors.New("invalid or expired token")
+
+// AccountService handles password resets, email verification and the sign-in
+// policy. Tokens are emailed as links to the web app and stored only as hashes;
+// each works once, and issuing a new one supersedes the last.
+type AccountService struct {
+	users    repository.UserRepository
+	tokens   repository.AccountTokenRepository
+	sessions repository.SessionRepository
+	policy   repository.AuthPolicyRepository
+
+	// mailer delivers account emails; nil only logs that nothing was sent
+	mailer notify.Notifier
+
+	appURL          string
+	resetTTL        time.Duration
+	verificationTTL time.Duration
+
+	// now returns the current time; replaced in tests to pin "now"
+	now func() time.Time
+}
+
+// NewAccountService creates an account service linking emails to pages under appURL
+func NewAccountService(users repository.UserRepository, tokens repository.AccountTokenRepository, sessions repository.SessionRepository, policy repository.AuthPolicyRepository, appURL string, resetTTL, verificationTTL time.Duration) *AccountService {
+	return &AccountService{
+		users:           users,
+		tokens:          tokens,
+		sessions:        sessions,
+		policy:          policy,
+		appURL:          appURL,
+		resetTTL:        resetTTL,
+		verificationTTL: verificationTTL,
+		now:             time.Now,
+	}
+}
+
+// SetMailer sets the notifier account emails are sent through
+func (s *AccountService) SetMailer(mailer notify.Notifier) {
+	s.mailer = mailer
+}
+
+// hashAccountToken returns the stored form of an account token
+func hashAccountToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// describeDuration renders a link lifetime for an email, such as "2 days" or "30 minutes"
+func describeDuration(d time.Duration) string {
+	unit, size := "minute", time.Minute
+	switch {
+	case d >= 24*time.Hour && d%(24*time.Hour) == 0:
+		unit, size = "day", 24*time.Hour
+	case d >= time.Hour && d%time.Hour == 0:
+		unit, size = "hour", time.Hour
+	}
+
+	n := int(d / size)
+	if n == 1 {
+		return "1 " + unit
+	}
+	return fmt.Sprintf("%d %ss", n, unit)
+}
+
+// GetPolicy returns the clinic's sign-in policy
+func (s *AccountService) GetPolicy(ctx context.Context) (*models.AuthPolicy, error) {
+	return s.policy.Get(ctx)
+}
+
+// UpdatePolicy changes the clinic's sign-in policy
+func (s *AccountService) UpdatePolicy(ctx context.Context, req models.UpdateAuthPolicyRequest) (*models.AuthPolicy, error) {
+	return s.policy.Update(ctx, req)
+}
+
+// VerificationRequired reports whether the policy of the clinic being signed in to
+// stops the user signing in until they verify their email address
+func (s *AccountService) VerificationRequired(ctx context.Context, user models.User, clinic models.UserClinic) (bool, error) {
+	if user.EmailVerified {
+		return false, nil
+	}
+	policy, err := s.policy.Get(repository.WithClinic(ctx, clinic.ClinicID))
+	if err != nil {
+		return false, err
+	}
+	return policy.RequireEmailVerification, nil
+}
+
+// issue stores a new token for the user and returns the link that carries it
+func (s *AccountService) issue(ctx context.Context, user models.User, purpose models.AccountTokenPurpose, ttl time.Duration, path string) (string, error) {
+	token, err := randomToken(32)
+	if err != nil {
+		return "", err
+	}
+
+	_, err = s.tokens.Create(ctx, models.AccountToken{
+		UserID:    user.ID,
+		Purpose:   purpose,
+		ExpiresAt: s.now().Add(ttl),
+	}, hashAccountToken(token))
+	if err != nil {
+		return "", err
+	}
+
+	return s.appURL + path + "?token=" + url.QueryEscape(token), nil
+}
+
+// send emails a user, logging instead when no mailer is configured
+func (s *AccountService) send(ctx context.Context, user models.User, subject, body string) error {
+	if s.mailer == nil {
+		log.Printf("No email notifier configured; %q for user %d was not sent", subject, user.ID)
+		return nil
+	}
+	return s.mailer.Send(ctx, notify.Message{
+		Channel: notify.ChannelEmail,
+		To:      user.Email,
+		Subject: subject,
+		Body:    body,
+	})
+}
+
+// SendVerification emails the user a link confirming their email address
+func (s *AccountService) SendVerification(ctx context.Context, user models.User) error {
+	if user.EmailVerified {
+		return nil
+	}
+
+	link, err := s.issue(ctx, user, models.AccountTokenEmailVerification, s.verificationTTL, "/verify-email")
+	if err != nil {
+		return err
+	}
+
+	body := fmt.Sprintf("Dear %s,\n\nPlease confirm your email address by opening this link:\n%s\n\nThe link expires in %s.\n",
+		user.FirstName, link, describeDuration(s.verificationTTL))
+	return s.send(ctx, user, "Confirm your email address", body)
+}
+
+// ResendVerification sends a new verification email to an unverified account. Unknown
+// addresses are ignored so the response does not reveal which accounts exist.
+func (s *AccountService) ResendVerification(ctx context.Context, email string) error {
+	user, err := s.users.FindByEmail(ctx, email)
+	if err != nil || user == nil || !user.Active {
+		return err
+	}
+	return s.SendVerification(ctx, *user)
+}
+
+// VerifyEmail marks the address of the token's user as verified
+func (s *AccountService) VerifyEmail(ctx context.Context, token string) error {
+	used, err := s.tokens.Consume(ctx, models.AccountTokenEmailVerification, hashAccountToken(token))
+	if err != nil {
+		return err
+	}
+	if used == nil {
+		return ErrInvalidAccountToken
+	}
+
+	return s.users.SetEmailVerified(ctx, used.UserID, true)
+}
+
+// RequestPasswordReset emails a reset link to an active account. Unknown addresses
+// are ignored so the response does not reveal which accounts exist.
+func (s *AccountService) RequestPasswordReset(ctx context.Context, email string) error {
+	user, err := s.users.FindByEmail(ctx, email)
+	if err != nil || user == nil || !user.Active {
+		return err
+	}
+
+	link, err := s.issue(ctx, *user, models.AccountTokenPasswordReset, s.resetTTL, "/reset-password")
+	if err != nil {
+		return err
+	}
+
+	body := fmt.Sprintf("Dear %s,\n\nA password reset was requested for your account. Choose a new password here:\n%s\n\n"+
+		"The link expires in %s. If you did not ask for this, you can ignore this email.\n",
+		user.FirstName, link, describeDuration(s.resetTTL))
+	return s.send(ctx, *user, "Reset your password", body)
+}
+
+// ResetPassword sets a new password from a reset token and signs the user out
+// everywhere. Following the emailed link also proves the address, so it is
+// marked verified.
+func (s *AccountService) ResetPassword(ctx context.Context, req models.ResetPasswordRequest) error {
+	used, err := s.tokens.Consume(ctx, models.AccountTokenPasswordReset, hashAccountToken(req.Token))
+	if err != nil {
+		return err
+	}
+	if used == nil {
+		return ErrInvalidAccountToken
+	}
+
+	hash, err := bcrypt.GenerateFromPassword([]byte(req.NewPassword), bcrypt.DefaultCost)
+	if err != nil {
+		return err
+	}
+	if err := s.users.SetPassword(ctx, used.UserID, string(hash)); err != nil {
+		return err
+	}
+	if err := s.users.SetEmailVerified(ctx, used.UserID, true); err != nil {
+		return err
+	}
+
+	_, err = s.sessions.RevokeAll(ctx, used.UserID, "")
+	return err
+}
@@ -0,0 +1,263 @@
+// dental_backend/internal/services/appointment_link_service.go
+package services
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"dental_backend/internal/models"
+	"dental_backend/internal/repository"
+)
+
+// DefaultRescheduleDays is how many days ahead reschedule options are offered when
+// the patient does not ask for a range, and MaxRescheduleDays caps the range
+const (
+	DefaultRescheduleDays = 14
+	MaxRescheduleDays     = 60
+)
+
+// linkCaller is the clinic-wide caller patient self-service changes are made as
+var linkCaller = Caller{Role: models.UserRoleStaff}
+
+// AppointmentLinkService lets patients confirm, cancel or reschedule an appointment
+// through a signed single-use link, without logging in
+type AppointmentLinkService struct {
+	tokens       *LinkTokens
+	links        repository.AppointmentLinkRepository
+	appointments repository.AppointmentRepository
+	booking      *AppointmentService
+	schedules    *ScheduleService
+}
+
+// NewAppointmentLinkService creates a new appointment link service
+func NewAppointmentLinkService(tokens *LinkTokens, links repository.AppointmentLinkRepository, appointments repository.AppointmentRepository, booking *AppointmentService, schedules *ScheduleService) *AppointmentLinkService {
+	return &AppointmentLinkService{
+		tokens:       tokens,
+		links:        links,
+		appointments: appointments,
+		booking:      booking,
+		schedules:    schedules,
+	}
+}
+
+// IssueLink creates a self-service link for an appointment visible to the caller. The
+// link expires when the appointment starts. It returns (nil, nil) when the appointment
+// does not exist.
+func (s *AppointmentLinkService) IssueLink(ctx context.Context, appointmentID int, caller Caller) (*models.AppointmentLink, error) {
+	appointment, err := s.appointments.GetByID(ctx, appointmentID, caller.dentistScope())
+	if err != nil || appointment == nil {
+		return nil, err
+	}
+
+	startsAt, err := s.startsAt(*appointment)
+	if err != nil {
+		return nil, err
+	}
+	if !(appointment.IsUpcoming() || appointment.IsPending()) || !startsAt.After(s.tokens.now()) {
+		return nil, &ValidationError{"Links can only be issued for upcoming appointments"}
+	}
+
+	token, err := s.tokens.Issue(appointment.ID, startsAt)
+	if err != nil {
+		return nil, err
+	}
+	return &models.AppointmentLink{AppointmentID: appointment.ID, Token: token, ExpiresAt: startsAt}, nil
+}
+
+// startsAt returns when an appointment begins
+func (s *AppointmentLinkService) startsAt(appointment models.Appointment) (time.Time, error) {
+	window, err := appointment.Window()
+	if err != nil {
+		return time.Time{}, err
+	}
+	return windowStart(window, s.tokens.now().Location()), nil
+}
+
+// resolve verifies a token that has not been used yet and loads its appointment
+func (s *AppointmentLinkService) resolve(ctx context.Context, token string) (linkClaims, *models.Appointment, error) {
+	claims, err := s.tokens.parse(token)
+	if err != nil {
+		return claims, nil, err
+	}
+
+	used, err := s.links.Used(ctx, claims.TokenID)
+	if err != nil {
+		return claims, nil, err
+	}
+	if used {
+		return claims, nil, ErrLinkUsed
+	}
+
+	appointment, err := s.appointments.GetByID(ctx, claims.AppointmentID, nil)
+	if err != nil {
+		return claims, nil, err
+	}
+	if appointment == nil {
+		return claims, nil, ErrInvalidLink
+	}
+	return claims, appointment, nil
+}
+
+// checkAction explains why an action is not possible for the appointment, or returns nil
+func checkAction(appointment models.Appointment, action models.AppointmentLinkAction) *ValidationError {
+	switch {
+	case action == models.AppointmentLinkConfirm && appointment.Status == string(models.AppointmentStatusConfirmed):
+		return &ValidationError{"This appointment is already confirmed"}
+	case action == models.AppointmentLinkConfirm && appointment.IsPending():
+		return &ValidationError{"This booking is waiting for the clinic to accept it"}
+	case action == models.AppointmentLinkConfirm && appointment.Status != string(models.AppointmentStatusScheduled):
+		return &ValidationError{fmt.Sprintf("A %s appointment can no longer be confirmed", appointment.Status)}
+	case !appointment.IsUpcoming() && !appointment.IsPending():
+		return &ValidationError{fmt.Sprintf("A %s appointment can no longer be changed online", appointment.Status)}
+	}
+	return nil
+}
+
+// Preview shows the appointment behind a link and whether the action can still be taken
+func (s *AppointmentLinkService) Preview(ctx context.Context, token string, action models.AppointmentLinkAction) (*models.AppointmentLinkPreview, error) {
+	_, appointment, err := s.resolve(ctx, token)
+	if err != nil {
+		return nil, err
+	}
+
+	preview := &models.AppointmentLinkPreview{
+		Appointment: models.NewPublicAppointment(*appointment),
+		Action:      string(action),
+		Allowed:     true,
+	}
+	if verr := checkAction(*appointment, action); verr != nil {
+		preview.Allowed = false
+		preview.Reason = verr.Message
+	}
+	return preview, nil
+}
+
+// use spends a link on an action and applies it as the patient. The link stays
+// usable if the action fails.
+func (s *AppointmentLinkService) use(ctx context.Context, token string, action models.AppointmentLinkAction, apply func(ctx context.Context, appointment models.Appointment) (*models.Appointment, error)) (*models.PublicAppointment, error) {
+	claims, appointment, err := s.resolve(ctx, token)
+	if err != nil {
+		return nil, err
+	}
+	if verr := checkAction(*appointment, action); verr != nil {
+		return nil, verr
+	}
+
+	spent, err := s.links.Use(ctx, models.AppointmentLinkUse{TokenID: claims.TokenID, AppointmentID: appointment.ID, Action: string(action)})
+	if err != nil {
+		return nil, err
+	}
+	if !spent {
+		return nil, ErrLinkUsed
+	}
+
+	// The change is made at the appointment's clinic, whose schedule it follows
+	updated, err := apply(repository.WithPatientActor(repository.WithClinic(ctx, appointment.ClinicID)), *appointment)
+	if err == nil && updated == nil {
+		err = ErrInvalidLink
+	}
+	if err != nil {
+		if releaseErr := s.links.Release(context.WithoutCancel(ctx), claims.TokenID); releaseErr != nil {
+			return nil, releaseErr
+		}
+		return nil, err
+	}
+
+	result := models.NewPublicAppointment(*updated)
+	return &result, nil
+}
+
+// Confirm confirms the appointment behind a link
+func (s *AppointmentLinkService) Confirm(ctx context.Context, token string) (*models.PublicAppointment, error) {
+	return s.use(ctx, token, models.AppointmentLinkConfirm, func(ctx context.Context, appointment models.Appointment) (*models.Appointment, error) {
+		return s.booking.UpdateAppointment(ctx, appointment.ID, models.UpdateAppointmentRequest{
+			Status: string(models.AppointmentStatusConfirmed),
+		}, linkCaller)
+	})
+}
+
+// Cancel cancels the appointment behind a link, offering its slot to the waitlist
+func (s *AppointmentLinkService) Cancel(ctx context.Context, token string, req models.PublicCancelRequest) (*models.PublicAppointment, error) {
+	return s.use(ctx, token, models.AppointmentLinkCancel, func(ctx context.Context, appointment models.Appointment) (*models.Appointment, error) {
+		reason := req.Reason
+		if reason == "" {
+			reason = "Cancelled by patient"
+		}
+		return s.booking.UpdateAppointment(ctx, appointment.ID, models.UpdateAppointmentRequest{
+			Status:             string(models.AppointmentStatusCancelled),
+			CancellationReason: reason,
+		}, linkCaller)
+	})
+}
+
+// RescheduleOptions lists the dentist's open slots of the appointment's length at its
+// clinic over the next days, starting today
+func (s *AppointmentLinkService) RescheduleOptions(ctx context.Context, token string, days int) (*models.RescheduleOptions, error) {
+	_, appointment, err := s.resolve(ctx, token)
+	if err != nil {
+		return nil, err
+	}
+	if verr := checkAction(*appointment, models.AppointmentLinkReschedule); verr != nil {
+		return nil, verr
+	}
+
+	if days <= 0 {
+		days = DefaultRescheduleDays
+	}
+	if days > MaxRescheduleDays {
+		days = MaxRescheduleDays
+	}
+
+	options := &models.RescheduleOptions{
+		Appointment: models.NewPublicAppointment(*appointment),
+		Days:        []models.Availability{},
+	}
+
+	ctx = repository.WithClinic(ctx, appointment.ClinicID)
+	today := s.tokens.now()
+	for i := 0; i < days; i++ {
+		date := today.AddDate(0, 0, i).Format("2006-01-02")
+		availability, err := s.schedules.GetAvailability(ctx, appointment.DentistID, date, appointment.Duration)
+		if err != nil {
+			return nil, err
+		}
+		if len(availability.Slots) > 0 {
+			options.Days = append(options.Days, *availability)
+		}
+	}
+
+	return options, nil
+}
+
+// Reschedule moves the appointment behind a link to one of its reschedule options
+func (s *AppointmentLinkService) Reschedule(ctx context.Context, token string, req models.PublicRescheduleRequest) (*models.PublicAppointment, error) {
+	return s.use(ctx, token, models.AppointmentLinkReschedule, func(ctx context.Context, appointment models.Appointment) (*models.Appointment, error) {
+		availability, err := s.schedules.GetAvailability(ctx, appointment.DentistID, req.AppointmentDate, appointment.Duration)
+		if err != nil {
+			return nil, err
+		}
+
+		start, err := models.ParseAppointmentTime(req.StartTime)
+		if err != nil {
+			return nil, &ValidationError{"Invalid start time, expected HH:MM"}
+		}
+		startTime := models.FormatHourMinute(start)
+
+		offered := false
+		for _, slot := range availability.Slots {
+			if slot.StartTime == startTime {
+				offered = true
+				break
+			}
+		}
+		if !offered {
+			return nil, &ValidationError{"The selected time is not available"}
+		}
+
+		return s.booking.UpdateAppointment(ctx, appointment.ID, models.UpdateAppointmentRequest{
+			AppointmentDate: availability.Date,
+			StartTime:       startTime,
+		}, linkCaller)
+	})
+}
@@ -0,0 +1,103 @@
+// dental_backend/internal/services/appointment_link_service_test.go
+package services
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"dental_backend/internal/models"
+	"dental_backend/internal/repository/memory"
+)
+
+// newLinkService creates a link service over the fixture's store whose tokens
+// are issued and checked at *now
+func newLinkService(f *bookingFixture, now *time.Time) *AppointmentLinkService {
+	tokens := NewLinkTokens("test-secret")
+	tokens.SetClock(func() time.Time { return *now })
+
+	appointments := memory.NewAppointmentRepository(f.store)
+	schedules := NewScheduleService(memory.NewScheduleRepository(f.store), appointments)
+	schedules.SetClock(func() time.Time { return *now })
+	return NewAppointmentLinkService(tokens, memory.NewAppointmentLinkRepository(f.store), appointments, f.appointments, schedules)
+}
+
+func TestAppointmentLinksAreSingleUse(t *testing.T) {
+	f := newBookingFixture(t)
+	now := testNow
+	links := newLinkService(f, &now)
+
+	appointment, err := f.book("2026-03-02", "10:00", 30)
+	if err != nil {
+		t.Fatalf("book appointment: %v", err)
+	}
+	link, err := links.IssueLink(f.ctx, appointment.ID, Caller{Role: models.UserRoleStaff})
+	if err != nil {
+		t.Fatalf("issue link: %v", err)
+	}
+
+	confirmed, err := links.Confirm(f.ctx, link.Token)
+	if err != nil {
+		t.Fatalf("confirm with link: %v", err)
+	}
+	if confirmed.Status != string(models.AppointmentStatusConfirmed) {
+		t.Errorf("status = %s, want %s", confirmed.Status, models.AppointmentStatusConfirmed)
+	}
+
+	// The spent link can neither be previewed nor used for another action
+	if _, err := links.Preview(f.ctx, link.Token, models.AppointmentLinkCancel); !errors.Is(err, ErrLinkUsed) {
+		t.Errorf("preview spent link error = %v, want %v", err, ErrLinkUsed)
+	}
+	if _, err := links.Cancel(f.ctx, link.Token, models.PublicCancelRequest{}); !errors.Is(err, ErrLinkUsed) {
+		t.Errorf("cancel with spent link error = %v, want %v", err, ErrLinkUsed)
+	}
+}
+
+func TestAppointmentLinkStaysUsableWhenTheActionFails(t *testing.T) {
+	f := newBookingFixture(t)
+	now := testNow
+	links := newLinkService(f, &now)
+
+	appointment, err := f.book("2026-03-02", "10:00", 30)
+	if err != nil {
+		t.Fatalf("book appointment: %v", err)
+	}
+	link, err := links.IssueLink(f.ctx, appointment.ID, Caller{Role: models.UserRoleStaff})
+	if err != nil {
+		t.Fatalf("issue link: %v", err)
+	}
+
+	// Moving onto a slot outside working hours fails without spending the link
+	_, err = links.Reschedule(f.ctx, link.Token, models.PublicRescheduleRequest{AppointmentDate: "2026-03-02", StartTime: "20:00"})
+	var verr *ValidationError
+	if !errors.As(err, &verr) {
+		t.Fatalf("reschedule outside working hours error = %v, want validation error", err)
+	}
+
+	if _, err := links.Confirm(f.ctx, link.Token); err != nil {
+		t.Fatalf("confirm after failed reschedule: %v", err)
+	}
+}
+
+func TestAppointmentLinksExpireWhenTheAppointmentStarts(t *testing.T) {
+	f := newBookingFixture(t)
+	now := testNow
+	links := newLinkService(f, &now)
+
+	appointment, err := f.book("2026-03-02", "10:00", 30)
+	if err != nil {
+		t.Fatalf("book appointment: %v", err)
+	}
+	link, err := links.IssueLink(f.ctx, appointment.ID, Caller{Role: models.UserRoleStaff})
+	if err != nil {
+		t.Fatalf("issue link: %v", err)
+	}
+	if !link.ExpiresAt.Equal(time.Date(2026, 3, 2, 10, 0, 0, 0, time.UTC)) {
+		t.Errorf("link expires at %v, want the appointment start", link.ExpiresAt)
+	}
+
+	now = link.ExpiresAt.Add(time.Minute)
+	if _, err := links.Confirm(f.ctx, link.Token); !errors.Is(err, ErrInvalidLink) {
+		t.Errorf("confirm with expired link error = %v, want %v", err, ErrInvalidLink)
+	}
+}
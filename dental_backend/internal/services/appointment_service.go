@@ -1,12 +1,16 @@
-// dental_backend/internal/services/appointment_services.go
+// dental_backend/internal/services/appointment_service.go
 package services
 
 import (
+	"context"
 	"database/sql"
-	"dental_backend/internal/models"
-	"strconv"
-	"strings"
+	"errors"
+	"fmt"
+	"log"
 	"time"
+
+	"dental_backend/internal/models"
+	"dental_backend/internal/repository"
 )
 
 // ValidationError represents a validation error
@@ -18,264 +22,439 @@ func (e *ValidationError) Error() string {
 	return e.Message
 }
 
-// formatInt converts an integer to a string
-func formatInt(i int) string {
-	return strconv.Itoa(i)
+// ConflictError reports a booking that overlaps one of the dentist's existing appointments
+type ConflictError struct {
+	Message     string
+	Conflicting *models.Appointment
+}
+
+func (e *ConflictError) Error() string {
+	return e.Message
+}
+
+// ForbiddenError reports an operation the caller's role does not allow
+type ForbiddenError struct {
+	Message string
+}
+
+func (e *ForbiddenError) Error() string {
+	return e.Message
 }
 
 // AppointmentService provides business logic for appointment operations
 type AppointmentService struct {
-	db *sql.DB
+	appointments repository.AppointmentRepository
+	treatments   repository.TreatmentRepository
+	schedules    repository.ScheduleRepository
+
+	// waitlist, when attached by NewWaitlistService, holds freed slots for
+	// waitlisted patients and is told about every slot that frees up
+	waitlist *WaitlistService
+
+	// risk, when attached by RiskService.Watch, rederives the risk of patients
+	// whose no-shows change
+	risk *RiskService
+
+	// now returns the current time; replaced in tests to pin "today"
+	now func() time.Time
 }
 
 // NewAppointmentService creates a new appointment service
-func NewAppointmentService(db *sql.DB) *AppointmentService {
-	return &AppointmentService{db: db}
+func NewAppointmentService(appointments repository.AppointmentRepository, treatments repository.TreatmentRepository, schedules repository.ScheduleRepository) *AppointmentService {
+	return &AppointmentService{appointments: appointments, treatments: treatments, schedules: schedules, now: time.Now}
 }
 
-// GetTodaysAppointments retrieves all appointments for today for the logged-in dentist
-func (s *AppointmentService) GetTodaysAppointments(dentistID int) ([]models.Appointment, error) {
-	today := time.Now().Format("2006-01-02")
-
-	rows, err := s.db.Query(`
-		SELECT a.id, a.patient_id,
-		       p.first_name || ' ' || p.last_name as patient_name, 
-		       a.appointment_date, a.start_time, a.status, a.notes, a.created_at, a.updated_at
-		FROM appointments a
-		JOIN patients p ON a.patient_id = p.id
-		WHERE a.appointment_date = $1 AND a.dentist_id = $2
-		ORDER BY a.start_time ASC`, today, dentistID)
+// SetClock overrides the clock used for date validation
+func (s *AppointmentService) SetClock(now func() time.Time) {
+	s.now = now
+}
 
+// validateNotInPast rejects appointment dates before today
+func (s *AppointmentService) validateNotInPast(appointmentDate string) error {
+	appDate, err := time.Parse("2006-01-02", appointmentDate)
 	if err != nil {
-		return nil, err
+		return &ValidationError{"Invalid appointment date, expected YYYY-MM-DD"}
 	}
-	defer rows.Close()
 
-	var appointments []models.Appointment
-	for rows.Next() {
-		var a models.Appointment
-		err := rows.Scan(
-			&a.ID, &a.PatientID, &a.PatientName,
-			&a.AppointmentDate, &a.StartTime, &a.Status, &a.Notes,
-			&a.CreatedAt, &a.UpdatedAt,
-		)
+	today := s.now().Truncate(24 * time.Hour)
+	if appDate.Before(today) {
+		return &ValidationError{"Appointment date cannot be in the past"}
+	}
+	return nil
+}
+
+// resolveDuration picks the booking length: an explicit duration wins, then the
+// linked treatment's duration, then the clinic default
+func (s *AppointmentService) resolveDuration(ctx context.Context, duration int, treatmentID *int) (int, error) {
+	if duration > 0 {
+		return duration, nil
+	}
+
+	if treatmentID != nil {
+		treatment, err := s.treatments.GetByID(ctx, *treatmentID)
 		if err != nil {
-			return nil, err
+			return 0, err
+		}
+		if treatment == nil {
+			return 0, &ValidationError{fmt.Sprintf("Treatment %d not found", *treatmentID)}
+		}
+		if treatment.Duration > 0 {
+			return treatment.Duration, nil
 		}
-		appointments = append(appointments, a)
 	}
 
-	return appointments, rows.Err()
+	return models.DefaultAppointmentDuration, nil
 }
 
-// GetAllAppointments retrieves all appointments with optional filtering for the logged-in dentist
-func (s *AppointmentService) GetAllAppointments(dentistID int, date *string, status *string, patientID *int) ([]models.Appointment, error) {
-	query := `
-		SELECT a.id, a.patient_id,
-		       p.first_name || ' ' || p.last_name as patient_name,
-		       a.appointment_date, a.start_time, a.status, a.notes, a.created_at, a.updated_at
-		FROM appointments a
-		JOIN patients p ON a.patient_id = p.id
-		WHERE a.dentist_id = $1`
+// bookingWindow validates the time range an appointment would occupy
+func bookingWindow(date, startTime string, duration int) (models.AppointmentWindow, error) {
+	window, err := models.NewAppointmentWindow(date, startTime, duration)
+	if err != nil {
+		return window, &ValidationError{"Invalid appointment time, expected HH:MM"}
+	}
+
+	if window.End > 24*time.Hour || window.End <= window.Start {
+		return window, &ValidationError{"Appointment must end on the day it starts"}
+	}
+	return window, nil
+}
 
-	args := []interface{}{dentistID}
-	argIndex := 2
+// windowStart returns the wall-clock time a window starts in the given time zone
+func windowStart(window models.AppointmentWindow, loc *time.Location) time.Time {
+	day, err := models.ParseAppointmentDate(window.Date)
+	if err != nil {
+		return time.Time{}
+	}
+	return time.Date(day.Year(), day.Month(), day.Day(), 0, 0, 0, 0, loc).Add(window.Start)
+}
 
-	if date != nil {
-		query += " AND a.appointment_date = $" + formatInt(argIndex)
-		args = append(args, *date)
-		argIndex++
+// checkSchedule rejects bookings on closed days or outside the dentist's working hours
+func (s *AppointmentService) checkSchedule(ctx context.Context, dentistID int, window models.AppointmentWindow) error {
+	day, err := models.ParseAppointmentDate(window.Date)
+	if err != nil {
+		return &ValidationError{"Invalid appointment date, expected YYYY-MM-DD"}
 	}
 
-	if status != nil {
-		query += " AND a.status = $" + formatInt(argIndex)
-		args = append(args, *status)
-		argIndex++
+	schedule, err := loadDaySchedule(ctx, s.schedules, dentistID, day)
+	if err != nil {
+		return err
 	}
 
-	if patientID != nil {
-		query += " AND a.patient_id = $" + formatInt(argIndex)
-		args = append(args, *patientID)
-		argIndex++
+	if schedule.closed {
+		if schedule.reason != "" {
+			return &ValidationError{fmt.Sprintf("Dentist is not available on %s: %s", window.Date, schedule.reason)}
+		}
+		return &ValidationError{fmt.Sprintf("Dentist is not available on %s", window.Date)}
 	}
 
-	query += " ORDER BY a.appointment_date ASC, a.start_time ASC"
+	if !schedule.contains(window) {
+		return &ValidationError{fmt.Sprintf("Appointment from %s to %s falls outside the dentist's working hours",
+			models.FormatHourMinute(window.Start), models.FormatHourMinute(window.End))}
+	}
+	return nil
+}
 
-	rows, err := s.db.Query(query, args...)
+// checkConflicts returns a ConflictError describing the first appointment that overlaps window
+func (s *AppointmentService) checkConflicts(ctx context.Context, dentistID int, window models.AppointmentWindow, excludeID int) error {
+	conflicts, err := s.appointments.Overlapping(ctx, dentistID, window, excludeID)
 	if err != nil {
-		return nil, err
+		return err
 	}
-	defer rows.Close()
+	if len(conflicts) == 0 {
+		return nil
+	}
+	return newConflictError(conflicts[0])
+}
 
-	var appointments []models.Appointment
-	for rows.Next() {
-		var a models.Appointment
-		err := rows.Scan(
-			&a.ID, &a.PatientID, &a.PatientName,
-			&a.AppointmentDate, &a.StartTime, &a.Status, &a.Notes,
-			&a.CreatedAt, &a.UpdatedAt,
-		)
-		if err != nil {
-			return nil, err
+// checkHolds returns a ConflictError when the window overlaps a slot held for
+// another patient on the waitlist
+func (s *AppointmentService) checkHolds(ctx context.Context, dentistID int, window models.AppointmentWindow, patientID int) error {
+	if s.waitlist == nil {
+		return nil
+	}
+	return s.waitlist.checkHolds(ctx, dentistID, window, patientID)
+}
+
+// freeSlot tells the waitlist that an active appointment no longer occupies its
+// slot. A failure is logged rather than failing the cancellation that caused it.
+func (s *AppointmentService) freeSlot(ctx context.Context, appointment models.Appointment) {
+	if s.waitlist == nil || !appointment.IsActive() {
+		return
+	}
+	if err := s.waitlist.SlotFreed(ctx, appointment); err != nil {
+		log.Printf("Error offering freed slot of appointment %d to the waitlist: %v", appointment.ID, err)
+	}
+}
+
+// newConflictError describes an existing appointment that blocks a booking
+func newConflictError(conflicting models.Appointment) *ConflictError {
+	message := fmt.Sprintf("Dentist already has appointment %d", conflicting.ID)
+	if window, err := conflicting.Window(); err == nil {
+		message = fmt.Sprintf("Dentist already has appointment %d with %s on %s from %s to %s",
+			conflicting.ID, conflicting.PatientName, window.Date,
+			models.FormatHourMinute(window.Start), models.FormatHourMinute(window.End))
+	}
+	return &ConflictError{Message: message, Conflicting: &conflicting}
+}
+
+// overlapError converts a repository.ErrOverlap raised by a concurrent booking into a ConflictError
+func (s *AppointmentService) overlapError(ctx context.Context, err error, dentistID int, window models.AppointmentWindow, excludeID int) error {
+	if !errors.Is(err, repository.ErrOverlap) {
+		return err
+	}
+	if conflictErr := s.checkConflicts(ctx, dentistID, window, excludeID); conflictErr != nil {
+		return conflictErr
+	}
+	return &ConflictError{Message: "Dentist is already booked at that time"}
+}
+
+// GetTodaysAppointments retrieves today's appointments in the caller's calendar,
+// or across the clinic for staff and admins
+func (s *AppointmentService) GetTodaysAppointments(ctx context.Context, caller Caller) ([]models.Appointment, error) {
+	today := s.now().Format("2006-01-02")
+	return s.appointments.List(ctx, repository.AppointmentFilter{DentistID: caller.dentistScope(), Date: &today})
+}
+
+// GetAllAppointments retrieves the appointments matching filter. Dentists and
+// hygienists are limited to their own calendar; staff and admins see every
+// dentist unless filter.DentistID narrows it.
+func (s *AppointmentService) GetAllAppointments(ctx context.Context, caller Caller, filter repository.AppointmentFilter) ([]models.Appointment, error) {
+	if !caller.ClinicWide() {
+		if filter.DentistID != nil && *filter.DentistID != caller.UserID {
+			return nil, &ForbiddenError{"You can only view your own appointments"}
 		}
-		appointments = append(appointments, a)
+		filter.DentistID = caller.dentistScope()
 	}
 
-	return appointments, rows.Err()
+	for _, date := range []*string{filter.Date, filter.From, filter.To} {
+		if date == nil {
+			continue
+		}
+		if _, err := models.ParseAppointmentDate(*date); err != nil {
+			return nil, &ValidationError{fmt.Sprintf("Invalid date %q, expected YYYY-MM-DD", *date)}
+		}
+	}
+	if filter.From != nil && filter.To != nil && *filter.To < *filter.From {
+		return nil, &ValidationError{"The to date cannot be before the from date"}
+	}
+
+	return s.appointments.List(ctx, filter)
 }
 
-// GetAppointmentByID retrieves a specific appointment by ID for the logged-in dentist
-func (s *AppointmentService) GetAppointmentByID(id int, dentistID int) (*models.Appointment, error) {
-	var appointment models.Appointment
-	err := s.db.QueryRow(`
-		SELECT a.id, a.patient_id,
-		       p.first_name || ' ' || p.last_name as patient_name,
-		       a.appointment_date, a.start_time, a.status, a.notes, a.created_at, a.updated_at
-		FROM appointments a
-		JOIN patients p ON a.patient_id = p.id
-		WHERE a.id = $1 AND a.dentist_id = $2`,
-		id, dentistID).Scan(
-		&appointment.ID, &appointment.PatientID, &appointment.PatientName,
-		&appointment.AppointmentDate, &appointment.StartTime, &appointment.Status,
-		&appointment.Notes, &appointment.CreatedAt, &appointment.UpdatedAt,
-	)
+// GetAppointmentByID retrieves a specific appointment visible to the caller
+func (s *AppointmentService) GetAppointmentByID(ctx context.Context, id int, caller Caller) (*models.Appointment, error) {
+	return s.appointments.GetByID(ctx, id, caller.dentistScope())
+}
 
-	if err != nil {
-		if err == sql.ErrNoRows {
-			return nil, nil
+// bookingDentist works out whose calendar a new booking goes into. Dentists and
+// hygienists book for themselves; staff and admins must name a dentist.
+func (s *AppointmentService) bookingDentist(ctx context.Context, caller Caller, requested *int) (int, error) {
+	if !caller.ClinicWide() {
+		if requested != nil && *requested != caller.UserID {
+			return 0, &ForbiddenError{"You can only book appointments in your own calendar"}
 		}
-		return nil, err
+		return caller.UserID, nil
 	}
 
-	return &appointment, nil
+	if requested == nil {
+		return 0, &ValidationError{"dentistId is required when booking on behalf of a dentist"}
+	}
+	isDentist, err := s.schedules.IsDentist(ctx, *requested)
+	if err != nil {
+		return 0, err
+	}
+	if !isDentist {
+		return 0, &ValidationError{fmt.Sprintf("User %d is not a dentist", *requested)}
+	}
+	return *requested, nil
 }
 
-// CreateAppointment creates a new appointment for the logged-in dentist
-func (s *AppointmentService) CreateAppointment(req models.CreateAppointmentRequest, dentistID int) (*models.Appointment, error) {
-	appDate, err := time.Parse("2006-01-02", req.AppointmentDate)
+// CreateAppointment books an appointment in the caller's calendar or, for staff
+// and admins, in the calendar of the dentist named in the request
+func (s *AppointmentService) CreateAppointment(ctx context.Context, req models.CreateAppointmentRequest, caller Caller) (*models.Appointment, error) {
+	dentistID, err := s.bookingDentist(ctx, caller, req.DentistID)
 	if err != nil {
 		return nil, err
 	}
+	return s.book(ctx, req, dentistID)
+}
 
-	today := time.Now().Truncate(24 * time.Hour)
-	if appDate.Before(today) {
-		return nil, &ValidationError{"Appointment date cannot be in the past"}
+// book creates an appointment in a dentist's calendar after validating the slot
+func (s *AppointmentService) book(ctx context.Context, req models.CreateAppointmentRequest, dentistID int) (*models.Appointment, error) {
+	if err := s.validateNotInPast(req.AppointmentDate); err != nil {
+		return nil, err
 	}
 
 	if req.Status == "" {
 		req.Status = string(models.AppointmentStatusScheduled)
 	}
 
-	var newAppointment models.Appointment
-	err = s.db.QueryRow(`
-		INSERT INTO appointments (
-			patient_id, dentist_id, appointment_date, start_time, status, notes, created_at, updated_at
-		) VALUES ($1, $2, $3, $4, $5, $6, NOW(), NOW())
-		RETURNING id, patient_id,
-		          (SELECT first_name || ' ' || last_name FROM patients WHERE id = $1),
-		          appointment_date, start_time, status, notes, created_at, updated_at`,
-		req.PatientID, dentistID, req.AppointmentDate, req.StartTime, req.Status, req.Notes,
-	).Scan(
-		&newAppointment.ID, &newAppointment.PatientID,
-		&newAppointment.PatientName,
-		&newAppointment.AppointmentDate, &newAppointment.StartTime, &newAppointment.Status,
-		&newAppointment.Notes, &newAppointment.CreatedAt, &newAppointment.UpdatedAt,
-	)
+	duration, err := s.resolveDuration(ctx, req.Duration, req.TreatmentID)
+	if err != nil {
+		return nil, err
+	}
+	req.Duration = duration
 
+	window, err := bookingWindow(req.AppointmentDate, req.StartTime, req.Duration)
 	if err != nil {
 		return nil, err
 	}
 
-	return &newAppointment, nil
+	// Reject double bookings up front so the caller learns which appointment is in the way
+	if (models.Appointment{Status: req.Status}).IsActive() {
+		if err := s.checkSchedule(ctx, dentistID, window); err != nil {
+			return nil, err
+		}
+		if err := s.checkConflicts(ctx, dentistID, window, 0); err != nil {
+			return nil, err
+		}
+		if err := s.checkHolds(ctx, dentistID, window, req.PatientID); err != nil {
+			return nil, err
+		}
+	}
+
+	appointment, err := s.appointments.Create(ctx, req, dentistID)
+	if err != nil {
+		return nil, s.overlapError(ctx, err, dentistID, window, 0)
+	}
+	return appointment, nil
 }
 
-// UpdateAppointment updates an existing appointment for the logged-in dentist
-func (s *AppointmentService) UpdateAppointment(id int, req models.UpdateAppointmentRequest, dentistID int) (*models.Appointment, error) {
+// UpdateAppointment updates an existing appointment visible to the caller. It
+// returns (nil, nil) when the appointment does not exist.
+func (s *AppointmentService) UpdateAppointment(ctx context.Context, id int, req models.UpdateAppointmentRequest, caller Caller) (*models.Appointment, error) {
 	if req.AppointmentDate != "" {
-		appDate, err := time.Parse("2006-01-02", req.AppointmentDate)
-		if err != nil {
+		if err := s.validateNotInPast(req.AppointmentDate); err != nil {
 			return nil, err
 		}
-		today := time.Now().Truncate(24 * time.Hour)
-		if appDate.Before(today) {
-			return nil, &ValidationError{"Appointment date cannot be in the past"}
+	}
+
+	existing, err := s.appointments.GetByID(ctx, id, caller.dentistScope())
+	if err != nil || existing == nil {
+		return nil, err
+	}
+	dentistID := existing.DentistID
+
+	if req.Status != "" && req.Status != existing.Status {
+		if !models.CanTransition(models.AppointmentStatus(existing.Status), models.AppointmentStatus(req.Status)) {
+			return nil, &ValidationError{fmt.Sprintf("Cannot change appointment status from %s to %s", existing.Status, req.Status)}
 		}
+		// Apply the transition only if nobody else moved the appointment since we read it
+		req.FromStatus = existing.Status
+	}
+	if req.Status != string(models.AppointmentStatusCancelled) {
+		req.CancellationReason = ""
+	}
+	if existing.IsFinal() && (req.AppointmentDate != "" || req.StartTime != "" || req.Duration != 0) {
+		return nil, &ValidationError{fmt.Sprintf("Cannot reschedule a %s appointment", existing.Status)}
 	}
 
-	setClauses := []string{"updated_at = NOW()"}
-	args := []interface{}{}
-	argIndex := 1
+	// Changing the treatment without an explicit duration re-derives the duration from it
+	if req.Duration == 0 && req.TreatmentID != nil {
+		duration, err := s.resolveDuration(ctx, 0, req.TreatmentID)
+		if err != nil {
+			return nil, err
+		}
+		req.Duration = duration
+	}
 
+	// Work out the slot the appointment will occupy once the changes are applied
+	merged := *existing
 	if req.PatientID != 0 {
-		setClauses = append(setClauses, "patient_id = $"+strconv.Itoa(argIndex))
-		args = append(args, req.PatientID)
-		argIndex++
+		merged.PatientID = req.PatientID
 	}
 	if req.AppointmentDate != "" {
-		setClauses = append(setClauses, "appointment_date = $"+strconv.Itoa(argIndex))
-		args = append(args, req.AppointmentDate)
-		argIndex++
+		merged.AppointmentDate = req.AppointmentDate
 	}
 	if req.StartTime != "" {
-		setClauses = append(setClauses, "start_time = $"+strconv.Itoa(argIndex))
-		args = append(args, req.StartTime)
-		argIndex++
+		merged.StartTime = req.StartTime
+	}
+	if req.Duration != 0 {
+		merged.Duration = req.Duration
 	}
 	if req.Status != "" {
-		setClauses = append(setClauses, "status = $"+strconv.Itoa(argIndex))
-		args = append(args, req.Status)
-		argIndex++
-	}
-	if req.Notes != "" {
-		setClauses = append(setClauses, "notes = $"+strconv.Itoa(argIndex))
-		args = append(args, req.Notes)
-		argIndex++
-	}
-
-	// dentistID and id go LAST - but we need to track their indices properly
-	dentistIDIndex := argIndex
-	idIndex := argIndex + 1
-	args = append(args, dentistID, id)
-
-	query := "UPDATE appointments SET " +
-		strings.Join(setClauses, ", ") +
-		" WHERE dentist_id = $" + strconv.Itoa(dentistIDIndex) +
-		" AND id = $" + strconv.Itoa(idIndex) +
-		" RETURNING id, patient_id, " +
-		"(SELECT first_name || ' ' || last_name FROM patients WHERE id = patient_id), " +
-		"appointment_date, start_time, status, notes, created_at, updated_at"
-
-	var updatedAppointment models.Appointment
-	err := s.db.QueryRow(query, args...).Scan(
-		&updatedAppointment.ID, &updatedAppointment.PatientID,
-		&updatedAppointment.PatientName,
-		&updatedAppointment.AppointmentDate, &updatedAppointment.StartTime, &updatedAppointment.Status,
-		&updatedAppointment.Notes, &updatedAppointment.CreatedAt, &updatedAppointment.UpdatedAt,
-	)
+		merged.Status = req.Status
+	}
+
+	window, err := bookingWindow(merged.AppointmentDate, merged.StartTime, merged.Duration)
 	if err != nil {
-		if err == sql.ErrNoRows {
-			return nil, nil
-		}
 		return nil, err
 	}
-	return &updatedAppointment, nil
+
+	if merged.IsActive() {
+		// Only re-validate the schedule when the slot moves, so appointments booked
+		// before the schedule changed can still be edited
+		if req.AppointmentDate != "" || req.StartTime != "" || req.Duration != 0 {
+			if err := s.checkSchedule(ctx, dentistID, window); err != nil {
+				return nil, err
+			}
+			if err := s.checkHolds(ctx, dentistID, window, merged.PatientID); err != nil {
+				return nil, err
+			}
+		}
+		if err := s.checkConflicts(ctx, dentistID, window, id); err != nil {
+			return nil, err
+		}
+	}
+
+	appointment, err := s.appointments.Update(ctx, id, req, dentistID)
+	if err != nil {
+		if errors.Is(err, repository.ErrStaleStatus) {
+			return nil, &ConflictError{Message: fmt.Sprintf("Appointment %d changed status while it was being updated; reload and try again", id)}
+		}
+		return nil, s.overlapError(ctx, err, dentistID, window, id)
+	}
+
+	// A cancellation frees the slot for the waitlist
+	if appointment != nil && appointment.Status == string(models.AppointmentStatusCancelled) {
+		s.freeSlot(ctx, *existing)
+	}
+
+	// Marking a no-show, or undoing or moving one, changes the patients' no-show history
+	noShow := string(models.AppointmentStatusNoShow)
+	if appointment != nil && (existing.Status == noShow || appointment.Status == noShow) {
+		s.risk.refresh(ctx, existing.PatientID, appointment.PatientID)
+	}
+	return appointment, nil
 }
 
-// DeleteAppointment deletes an appointment for the logged-in dentist
-func (s *AppointmentService) DeleteAppointment(id int, dentistID int) error {
-	result, err := s.db.Exec("DELETE FROM appointments WHERE id = $1 AND dentist_id = $2", id, dentistID)
+// GetAppointmentHistory retrieves the status changes of an appointment visible to
+// the caller, or nil when the appointment does not exist
+func (s *AppointmentService) GetAppointmentHistory(ctx context.Context, id int, caller Caller) ([]models.AppointmentEvent, error) {
+	appointment, err := s.appointments.GetByID(ctx, id, caller.dentistScope())
+	if err != nil || appointment == nil {
+		return nil, err
+	}
+
+	events, err := s.appointments.Events(ctx, id)
 	if err != nil {
-		return err
+		return nil, err
+	}
+	if events == nil {
+		events = []models.AppointmentEvent{}
 	}
+	return events, nil
+}
 
-	rowsAffected, err := result.RowsAffected()
+// DeleteAppointment deletes an appointment visible to the caller
+func (s *AppointmentService) DeleteAppointment(ctx context.Context, id int, caller Caller) error {
+	appointment, err := s.appointments.GetByID(ctx, id, caller.dentistScope())
 	if err != nil {
 		return err
 	}
-
-	if rowsAffected == 0 {
+	if appointment == nil {
 		return sql.ErrNoRows
 	}
+	if err := s.appointments.Delete(ctx, id, appointment.DentistID); err != nil {
+		return err
+	}
 
+	// The deleted row is gone, so the opening is not linked back to it
+	appointment.ID = 0
+	s.freeSlot(ctx, *appointment)
+
+	if appointment.Status == string(models.AppointmentStatusNoShow) {
+		s.risk.refresh(ctx, appointment.PatientID)
+	}
 	return nil
 }
@@ -0,0 +1,210 @@
+// dental_backend/internal/services/appointment_service_test.go
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"dental_backend/internal/models"
+	"dental_backend/internal/repository"
+	"dental_backend/internal/repository/memory"
+)
+
+// testNow is a Monday morning, inside the default working week
+var testNow = time.Date(2026, 3, 2, 8, 0, 0, 0, time.UTC)
+
+// bookingFixture is an appointment service on an in-memory store with one
+// dentist and one patient at the default clinic
+type bookingFixture struct {
+	store        *memory.Store
+	appointments *AppointmentService
+	ctx          context.Context
+	dentistID    int
+	patientID    int
+}
+
+func newBookingFixture(t *testing.T) *bookingFixture {
+	t.Helper()
+	store := memory.NewStore()
+	store.Now = func() time.Time { return testNow }
+
+	clinic, err := memory.NewClinicRepository(store).Default(context.Background())
+	if err != nil || clinic == nil {
+		t.Fatalf("default clinic: %v", err)
+	}
+	ctx := repository.WithClinic(context.Background(), clinic.ID)
+
+	dentist := store.AddUser(models.User{Email: "dentist@example.com", FirstName: "Dana", LastName: "Smith", Role: string(models.UserRoleDentist)})
+	patient, err := memory.NewPatientRepository(store).Create(ctx, models.CreatePatientRequest{
+		FirstName: "Pat", LastName: "Jones", DateOfBirth: "1990-05-01", Phone: "555-0100", Email: "pat@example.com",
+	})
+	if err != nil {
+		t.Fatalf("create patient: %v", err)
+	}
+
+	schedules := memory.NewScheduleRepository(store)
+	appointments := NewAppointmentService(memory.NewAppointmentRepository(store), memory.NewTreatmentRepository(store), schedules)
+	appointments.SetClock(func() time.Time { return testNow })
+
+	return &bookingFixture{store: store, appointments: appointments, ctx: ctx, dentistID: dentist.ID, patientID: patient.ID}
+}
+
+// book creates an appointment as the dentist
+func (f *bookingFixture) book(date, startTime string, duration int) (*models.Appointment, error) {
+	return f.appointments.CreateAppointment(f.ctx, models.CreateAppointmentRequest{
+		PatientID:       f.patientID,
+		AppointmentDate: date,
+		StartTime:       startTime,
+		Duration:        duration,
+	}, Caller{UserID: f.dentistID, Role: models.UserRoleDentist})
+}
+
+// setStatus moves an appointment to status as the front desk
+func (f *bookingFixture) setStatus(id int, status models.AppointmentStatus) (*models.Appointment, error) {
+	return f.appointments.UpdateAppointment(f.ctx, id, models.UpdateAppointmentRequest{Status: string(status)}, Caller{Role: models.UserRoleStaff})
+}
+
+func TestCreateAppointmentRejectsPastDates(t *testing.T) {
+	tests := []struct {
+		name    string
+		date    string
+		wantErr bool
+	}{
+		{name: "yesterday", date: "2026-03-01", wantErr: true},
+		{name: "last year", date: "2025-03-02", wantErr: true},
+		{name: "today", date: "2026-03-02"},
+		{name: "tomorrow", date: "2026-03-03"},
+		{name: "not a date", date: "03/02/2026", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newBookingFixture(t)
+			_, err := f.book(tt.date, "10:00", 30)
+			var verr *ValidationError
+			if tt.wantErr != errors.As(err, &verr) {
+				t.Fatalf("book(%s) error = %v, want validation error %v", tt.date, err, tt.wantErr)
+			}
+			if !tt.wantErr && err != nil {
+				t.Fatalf("book(%s) error = %v", tt.date, err)
+			}
+		})
+	}
+}
+
+func TestCreateAppointmentRejectsOverlaps(t *testing.T) {
+	tests := []struct {
+		name         string
+		startTime    string
+		duration     int
+		existingDone models.AppointmentStatus // status the existing 10:00-10:30 booking is moved to first
+		wantConflict bool
+	}{
+		{name: "same slot", startTime: "10:00", duration: 30, wantConflict: true},
+		{name: "starts inside", startTime: "10:15", duration: 30, wantConflict: true},
+		{name: "ends inside", startTime: "09:45", duration: 30, wantConflict: true},
+		{name: "contains it", startTime: "09:30", duration: 90, wantConflict: true},
+		{name: "ends as it starts", startTime: "09:30", duration: 30},
+		{name: "starts as it ends", startTime: "10:30", duration: 30},
+		{name: "over a cancelled booking", startTime: "10:00", duration: 30, existingDone: models.AppointmentStatusCancelled},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newBookingFixture(t)
+			existing, err := f.book("2026-03-02", "10:00", 30)
+			if err != nil {
+				t.Fatalf("book existing appointment: %v", err)
+			}
+			if tt.existingDone != "" {
+				if _, err := f.setStatus(existing.ID, tt.existingDone); err != nil {
+					t.Fatalf("move existing appointment to %s: %v", tt.existingDone, err)
+				}
+			}
+
+			_, err = f.book("2026-03-02", tt.startTime, tt.duration)
+			var conflict *ConflictError
+			if tt.wantConflict {
+				if !errors.As(err, &conflict) {
+					t.Fatalf("book(%s, %d min) error = %v, want conflict", tt.startTime, tt.duration, err)
+				}
+				if conflict.Conflicting == nil || conflict.Conflicting.ID != existing.ID {
+					t.Errorf("conflicting appointment = %+v, want %d", conflict.Conflicting, existing.ID)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("book(%s, %d min) error = %v", tt.startTime, tt.duration, err)
+			}
+		})
+	}
+}
+
+func TestUpdateAppointmentStatusTransitions(t *testing.T) {
+	tests := []struct {
+		name    string
+		path    []models.AppointmentStatus
+		wantErr bool // whether the last step is refused
+	}{
+		{name: "confirm", path: []models.AppointmentStatus{models.AppointmentStatusConfirmed}},
+		{name: "visit", path: []models.AppointmentStatus{
+			models.AppointmentStatusCheckedIn, models.AppointmentStatusInChair, models.AppointmentStatusCompleted,
+		}},
+		{name: "no-show", path: []models.AppointmentStatus{models.AppointmentStatusNoShow}},
+		{name: "complete without a visit", path: []models.AppointmentStatus{models.AppointmentStatusCompleted}, wantErr: true},
+		{name: "seat without checking in", path: []models.AppointmentStatus{models.AppointmentStatusInChair}, wantErr: true},
+		{name: "reopen a cancellation", path: []models.AppointmentStatus{
+			models.AppointmentStatusCancelled, models.AppointmentStatusScheduled,
+		}, wantErr: true},
+		{name: "cancel a completed visit", path: []models.AppointmentStatus{
+			models.AppointmentStatusCheckedIn, models.AppointmentStatusInChair, models.AppointmentStatusCompleted, models.AppointmentStatusCancelled,
+		}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newBookingFixture(t)
+			appointment, err := f.book("2026-03-02", "10:00", 30)
+			if err != nil {
+				t.Fatalf("book appointment: %v", err)
+			}
+
+			last := len(tt.path) - 1
+			for i, status := range tt.path {
+				updated, err := f.setStatus(appointment.ID, status)
+				if i < last || !tt.wantErr {
+					if err != nil {
+						t.Fatalf("move to %s: %v", status, err)
+					}
+					if updated.Status != string(status) {
+						t.Fatalf("status = %s, want %s", updated.Status, status)
+					}
+					continue
+				}
+				var verr *ValidationError
+				if !errors.As(err, &verr) {
+					t.Fatalf("move to %s error = %v, want validation error", status, err)
+				}
+			}
+		})
+	}
+}
+
+func TestUpdateAppointmentRefusesToRescheduleFinalAppointments(t *testing.T) {
+	f := newBookingFixture(t)
+	appointment, err := f.book("2026-03-02", "10:00", 30)
+	if err != nil {
+		t.Fatalf("book appointment: %v", err)
+	}
+	if _, err := f.setStatus(appointment.ID, models.AppointmentStatusCancelled); err != nil {
+		t.Fatalf("cancel appointment: %v", err)
+	}
+
+	_, err = f.appointments.UpdateAppointment(f.ctx, appointment.ID, models.UpdateAppointmentRequest{StartTime: "11:00"}, Caller{Role: models.UserRoleStaff})
+	var verr *ValidationError
+	if !errors.As(err, &verr) {
+		t.Fatalf("reschedule cancelled appointment error = %v, want validation error", err)
+	}
+}
@@ -0,0 +1,103 @@
+// dental_backend/internal/services/audit_service.go
+package services
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"dental_backend/internal/audit"
+	"dental_backend/internal/models"
+	"dental_backend/internal/repository"
+)
+
+const (
+	// defaultAuditPage and maxAuditPage bound how many entries one listing returns
+	defaultAuditPage = 100
+	maxAuditPage     = 10000
+
+	// auditVerifyBatch is how many entries Verify reads at a time
+	auditVerifyBatch = 1000
+)
+
+// AuditService writes and reads the audit trail
+type AuditService struct {
+	audit repository.AuditRepository
+
+	// now returns the current time; replaced in tests to pin "now"
+	now func() time.Time
+}
+
+// NewAuditService creates a new audit service
+func NewAuditService(audit repository.AuditRepository) *AuditService {
+	return &AuditService{audit: audit, now: time.Now}
+}
+
+// Record appends an entry to the trail, stamped with the current time
+func (s *AuditService) Record(ctx context.Context, entry models.AuditEntry) error {
+	entry.OccurredAt = s.now().UTC().Truncate(time.Microsecond)
+	_, err := s.audit.Append(ctx, entry)
+	return err
+}
+
+// GetEntries lists matching entries, newest first, a page at a time
+func (s *AuditService) GetEntries(ctx context.Context, filter repository.AuditFilter) ([]models.AuditEntry, error) {
+	if filter.Limit <= 0 {
+		filter.Limit = defaultAuditPage
+	}
+	if filter.Limit > maxAuditPage {
+		filter.Limit = maxAuditPage
+	}
+	if filter.Offset < 0 {
+		filter.Offset = 0
+	}
+
+	entries, err := s.audit.List(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	if entries == nil {
+		entries = []models.AuditEntry{}
+	}
+	return entries, nil
+}
+
+// Verify walks the whole chain and reports the first entry whose hash does not
+// match its contents or whose predecessor is not the entry before it
+func (s *AuditService) Verify(ctx context.Context) (*models.AuditVerification, error) {
+	result := &models.AuditVerification{Valid: true}
+	prev := audit.GenesisHash
+	var afterID int64
+
+	for {
+		entries, err := s.audit.Chain(ctx, afterID, auditVerifyBatch)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, entry := range entries {
+			reason := ""
+			switch {
+			case entry.PrevHash != prev:
+				reason = fmt.Sprintf("entry %d does not follow the entry before it", entry.ID)
+			case audit.Hash(entry) != entry.Hash:
+				reason = fmt.Sprintf("entry %d does not match its hash", entry.ID)
+			}
+			if reason != "" {
+				id := entry.ID
+				result.Valid = false
+				result.BrokenAt = &id
+				result.Reason = reason
+				return result, nil
+			}
+
+			result.Checked++
+			prev = entry.Hash
+			afterID = entry.ID
+		}
+
+		if len(entries) < auditVerifyBatch {
+			return result, nil
+		}
+	}
+}
@@ -0,0 +1,246 @@
+// dental_backend/internal/services/booking_service.go
+package services
+
+import (
+	"context"
+	"errors"
+	"log"
+	"strings"
+	"time"
+
+	"dental_backend/internal/captcha"
+	"dental_backend/internal/models"
+	"dental_backend/internal/repository"
+)
+
+// BookingService lets patients book online without an account. Bookings match an
+// existing patient by email and date of birth or create a provisional one, and
+// wait in pending-confirmation until the front desk accepts them.
+type BookingService struct {
+	treatments repository.TreatmentRepository
+	patients   repository.PatientRepository
+	schedules  *ScheduleService
+	booking    *AppointmentService
+	links      *AppointmentLinkService
+	captcha    captcha.Verifier
+}
+
+// NewBookingService creates a new online booking service
+func NewBookingService(treatments repository.TreatmentRepository, patients repository.PatientRepository, schedules *ScheduleService, booking *AppointmentService, links *AppointmentLinkService, verifier captcha.Verifier) *BookingService {
+	if verifier == nil {
+		verifier = captcha.Disabled{}
+	}
+	return &BookingService{
+		treatments: treatments,
+		patients:   patients,
+		schedules:  schedules,
+		booking:    booking,
+		links:      links,
+		captcha:    verifier,
+	}
+}
+
+// GetAppointmentTypes lists the treatments offered for online booking
+func (s *BookingService) GetAppointmentTypes(ctx context.Context) ([]models.AppointmentType, error) {
+	treatments, err := s.treatments.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	types := []models.AppointmentType{}
+	for _, t := range treatments {
+		if t.BookableOnline {
+			types = append(types, appointmentType(t))
+		}
+	}
+	return types, nil
+}
+
+// appointmentType exposes the public fields of a treatment
+func appointmentType(t models.Treatment) models.AppointmentType {
+	return models.AppointmentType{
+		ID:          t.ID,
+		Name:        t.Name,
+		Description: t.Description,
+		Category:    t.Category,
+		Duration:    t.Duration,
+	}
+}
+
+// GetDentists lists the dentists patients can book with
+func (s *BookingService) GetDentists(ctx context.Context) ([]models.Dentist, error) {
+	return s.schedules.GetDentists(ctx)
+}
+
+// bookableType loads a treatment offered online, or returns a ValidationError
+func (s *BookingService) bookableType(ctx context.Context, typeID int) (*models.Treatment, error) {
+	treatment, err := s.treatments.GetByID(ctx, typeID)
+	if err != nil {
+		return nil, err
+	}
+	if treatment == nil || !treatment.BookableOnline {
+		return nil, &ValidationError{"Unknown appointment type"}
+	}
+	return treatment, nil
+}
+
+// checkDentist returns a ValidationError unless id is a dentist patients can book with
+func (s *BookingService) checkDentist(ctx context.Context, id int) error {
+	dentists, err := s.schedules.GetDentists(ctx)
+	if err != nil {
+		return err
+	}
+	for _, d := range dentists {
+		if d.ID == id {
+			return nil
+		}
+	}
+	return &ValidationError{"Unknown dentist"}
+}
+
+// GetSlots lists the open slots for an appointment type on a date, with one dentist
+// or with every dentist when dentistID is nil
+func (s *BookingService) GetSlots(ctx context.Context, typeID int, date string, dentistID *int) ([]models.Availability, error) {
+	treatment, err := s.bookableType(ctx, typeID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.booking.validateNotInPast(date); err != nil {
+		return nil, err
+	}
+
+	dentists, err := s.schedules.GetDentists(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var dentistIDs []int
+	for _, d := range dentists {
+		if dentistID == nil || d.ID == *dentistID {
+			dentistIDs = append(dentistIDs, d.ID)
+		}
+	}
+	if dentistID != nil && len(dentistIDs) == 0 {
+		return nil, &ValidationError{"Unknown dentist"}
+	}
+
+	days := []models.Availability{}
+	for _, id := range dentistIDs {
+		availability, err := s.schedules.GetAvailability(ctx, id, date, treatment.Duration)
+		if err != nil {
+			return nil, err
+		}
+		days = append(days, *availability)
+	}
+	return days, nil
+}
+
+// Book places a pending-confirmation appointment for the patient described in req
+func (s *BookingService) Book(ctx context.Context, req models.OnlineBookingRequest, remoteIP string) (*models.OnlineBooking, error) {
+	if err := s.captcha.Verify(ctx, req.CaptchaToken, remoteIP); err != nil {
+		if errors.Is(err, captcha.ErrRejected) {
+			return nil, &ValidationError{"Captcha verification failed"}
+		}
+		return nil, err
+	}
+
+	dob, err := models.ParseAppointmentDate(req.DateOfBirth)
+	if err != nil || dob.After(s.booking.now()) {
+		return nil, &ValidationError{"Invalid date of birth, expected YYYY-MM-DD"}
+	}
+
+	treatment, err := s.bookableType(ctx, req.AppointmentTypeID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.booking.validateNotInPast(req.AppointmentDate); err != nil {
+		return nil, err
+	}
+	if err := s.checkDentist(ctx, req.DentistID); err != nil {
+		return nil, err
+	}
+
+	// Only the slots offered by GetSlots can be booked
+	start, err := models.ParseAppointmentTime(req.StartTime)
+	if err != nil {
+		return nil, &ValidationError{"Invalid start time, expected HH:MM"}
+	}
+	startTime := models.FormatHourMinute(start)
+	availability, err := s.schedules.GetAvailability(ctx, req.DentistID, req.AppointmentDate, treatment.Duration)
+	if err != nil {
+		return nil, err
+	}
+	offered := false
+	for _, slot := range availability.Slots {
+		if slot.StartTime == startTime {
+			offered = true
+			break
+		}
+	}
+	if !offered {
+		return nil, &ConflictError{Message: "The selected time is not available"}
+	}
+
+	// Changes made from here on were requested by the patient
+	ctx = repository.WithPatientActor(ctx)
+
+	patient, created, err := s.matchPatient(ctx, req, dob)
+	if err != nil {
+		return nil, err
+	}
+
+	appointment, err := s.booking.book(ctx, models.CreateAppointmentRequest{
+		PatientID:       patient.ID,
+		AppointmentDate: availability.Date,
+		StartTime:       startTime,
+		Duration:        treatment.Duration,
+		TreatmentID:     &treatment.ID,
+		Status:          string(models.AppointmentStatusPendingConfirmation),
+		Notes:           strings.TrimSpace("Booked online. " + req.Notes),
+	}, req.DentistID)
+	if err != nil {
+		// Do not leave behind a provisional patient without a booking
+		if created {
+			if delErr := s.patients.Delete(context.WithoutCancel(ctx), patient.ID); delErr != nil {
+				log.Printf("Error removing provisional patient %d after a failed booking: %v", patient.ID, delErr)
+			}
+		}
+		return nil, err
+	}
+
+	link, err := s.links.IssueLink(ctx, appointment.ID, linkCaller)
+	if err != nil {
+		return nil, err
+	}
+
+	// Echo the name the patient entered rather than the one on file
+	result := models.NewPublicAppointment(*appointment)
+	result.PatientName = req.FirstName + " " + req.LastName
+
+	return &models.OnlineBooking{Appointment: result, Link: link}, nil
+}
+
+// matchPatient finds the patient by email and date of birth or creates a provisional
+// one, reporting whether it was created
+func (s *BookingService) matchPatient(ctx context.Context, req models.OnlineBookingRequest, dob time.Time) (*models.Patient, bool, error) {
+	dateOfBirth := dob.Format("2006-01-02")
+
+	patient, err := s.patients.FindByEmailAndDOB(ctx, req.Email, dateOfBirth)
+	if err != nil || patient != nil {
+		return patient, false, err
+	}
+
+	patient, err = s.patients.Create(ctx, models.CreatePatientRequest{
+		FirstName:   req.FirstName,
+		LastName:    req.LastName,
+		DateOfBirth: dateOfBirth,
+		Phone:       req.Phone,
+		Email:       req.Email,
+		RiskLevel:   string(models.RiskLevelLow),
+		Provisional: true,
+	})
+	if err != nil {
+		return nil, false, err
+	}
+	return patient, true, nil
+}
@@ -0,0 +1,251 @@
+// dental_backend/internal/services/calendar_service.go
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"dental_backend/internal/ical"
+	"dental_backend/internal/models"
+	"dental_backend/internal/repository"
+)
+
+// CalendarFeedHistory is how far back calendar feeds list past appointments
+const CalendarFeedHistory = 90 * 24 * time.Hour
+
+// calendarProdID identifies the generated calendars to clients
+const calendarProdID = "-//Dental Flow//Appointments//EN"
+
+// ErrInvalidFeed is returned for feed tokens that are unknown, revoked or issued for another calendar
+var ErrInvalidFeed = errors.New("invalid calendar feed token")
+
+// CalendarService issues calendar feed tokens and renders the feeds as iCalendar.
+// Calendar clients cannot send Bearer headers, so each feed URL carries its own
+// token, stored only as a hash and revocable by its owner.
+type CalendarService struct {
+	feeds        repository.CalendarFeedRepository
+	appointments repository.AppointmentRepository
+	patients     repository.PatientRepository
+	schedules    *ScheduleService
+
+	// baseURL is prefixed to feed paths to form the subscription URL
+	baseURL string
+
+	// now returns the current time; replaced in tests to pin "now"
+	now func() time.Time
+}
+
+// NewCalendarService creates a calendar service whose feed URLs start with baseURL
+func NewCalendarService(feeds repository.CalendarFeedRepository, appointments repository.AppointmentRepository, patients repository.PatientRepository, schedules *ScheduleService, baseURL string) *CalendarService {
+	return &CalendarService{
+		feeds:        feeds,
+		appointments: appointments,
+		patients:     patients,
+		schedules:    schedules,
+		baseURL:      baseURL,
+		now:          time.Now,
+	}
+}
+
+// hashFeedToken returns the stored form of a feed token
+func hashFeedToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// dentistName returns a dentist's display name, or "" when id is not a dentist
+func (s *CalendarService) dentistName(ctx context.Context, id int) (string, error) {
+	dentists, err := s.schedules.GetDentists(ctx)
+	if err != nil {
+		return "", err
+	}
+	for _, d := range dentists {
+		if d.ID == id {
+			return d.Name, nil
+		}
+	}
+	return "", nil
+}
+
+// CreateFeed issues a feed for a dentist's or patient's appointments. Dentists may
+// only subscribe to their own calendar; a patient feed shows the appointments the
+// caller can see.
+func (s *CalendarService) CreateFeed(ctx context.Context, req models.CreateCalendarFeedRequest, caller Caller) (*models.CalendarFeed, error) {
+	feedType := models.CalendarFeedType(req.Type)
+
+	switch feedType {
+	case models.CalendarFeedDentist:
+		name, err := s.dentistName(ctx, req.SubjectID)
+		if err != nil {
+			return nil, err
+		}
+		if name == "" {
+			return nil, &ValidationError{"Unknown dentist"}
+		}
+		if !caller.ClinicWide() && caller.UserID != req.SubjectID {
+			return nil, &ForbiddenError{"You can only subscribe to your own calendar"}
+		}
+	case models.CalendarFeedPatient:
+		patient, err := s.patients.GetByID(ctx, req.SubjectID)
+		if err != nil {
+			return nil, err
+		}
+		if patient == nil {
+			return nil, &ValidationError{"Unknown patient"}
+		}
+	default:
+		return nil, &ValidationError{"Invalid feed type"}
+	}
+
+	raw := make([]byte, 32)
+	if _, err := rand.Read(raw); err != nil {
+		return nil, err
+	}
+	token := base64.RawURLEncoding.EncodeToString(raw)
+
+	feed, err := s.feeds.Create(ctx, models.CalendarFeed{
+		UserID:    caller.UserID,
+		Type:      feedType,
+		SubjectID: req.SubjectID,
+	}, hashFeedToken(token))
+	if err != nil {
+		return nil, err
+	}
+
+	feed.Token = token
+	feed.URL = fmt.Sprintf("%s/api/calendar/%ss/%d.ics?token=%s", s.baseURL, feedType, feed.SubjectID, token)
+	return feed, nil
+}
+
+// GetFeeds lists the caller's calendar feeds
+func (s *CalendarService) GetFeeds(ctx context.Context, caller Caller) ([]models.CalendarFeed, error) {
+	feeds, err := s.feeds.ListForUser(ctx, caller.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if feeds == nil {
+		feeds = []models.CalendarFeed{}
+	}
+	return feeds, nil
+}
+
+// RevokeFeed revokes one of the caller's feeds; admins may revoke anyone's.
+// It returns sql.ErrNoRows when there is no such active feed.
+func (s *CalendarService) RevokeFeed(ctx context.Context, id int, caller Caller) error {
+	var owner *int
+	if caller.Role != models.UserRoleAdmin {
+		owner = &caller.UserID
+	}
+	return s.feeds.Revoke(ctx, id, owner)
+}
+
+// OpenFeed returns the feed a feed URL's token was issued for. The token must
+// have been issued for exactly this calendar, by a user who still works at the
+// feed's clinic. ctx must see every clinic, as the token is all that names it.
+func (s *CalendarService) OpenFeed(ctx context.Context, feedType models.CalendarFeedType, subjectID int, token string) (*models.CalendarFeed, error) {
+	if token == "" {
+		return nil, ErrInvalidFeed
+	}
+	feed, err := s.feeds.FindActive(ctx, hashFeedToken(token))
+	if err != nil {
+		return nil, err
+	}
+	if feed == nil || feed.Type != feedType || feed.SubjectID != subjectID {
+		return nil, ErrInvalidFeed
+	}
+	return feed, nil
+}
+
+// Feed renders the calendar of a feed opened with OpenFeed. ctx must be limited
+// to the feed's clinic, whose appointments are all it shows, and what it shows
+// follows its owner's current role there.
+func (s *CalendarService) Feed(ctx context.Context, feed *models.CalendarFeed) (*ical.Calendar, error) {
+	if clinicID, ok := repository.ClinicID(ctx); !ok || clinicID != feed.ClinicID {
+		return nil, fmt.Errorf("calendar feed %d rendered outside its clinic", feed.ID)
+	}
+	feedType, subjectID := feed.Type, feed.SubjectID
+	owner := Caller{UserID: feed.UserID, Role: models.UserRole(feed.UserRole)}
+
+	dentists, err := s.schedules.GetDentists(ctx)
+	if err != nil {
+		return nil, err
+	}
+	names := make(map[int]string, len(dentists))
+	for _, d := range dentists {
+		names[d.ID] = d.Name
+	}
+
+	from := s.now().Add(-CalendarFeedHistory).Format("2006-01-02")
+	filter := repository.AppointmentFilter{From: &from}
+	calendar := &ical.Calendar{ProdID: calendarProdID}
+
+	switch feedType {
+	case models.CalendarFeedDentist:
+		// A dentist who has since lost access to other calendars keeps only their own
+		if !owner.ClinicWide() && owner.UserID != subjectID {
+			return nil, ErrInvalidFeed
+		}
+		filter.DentistID = &subjectID
+		calendar.Name = "Appointments - " + names[subjectID]
+	case models.CalendarFeedPatient:
+		filter.PatientID = &subjectID
+		filter.DentistID = owner.dentistScope()
+		calendar.Name = "Dental appointments"
+	}
+
+	appointments, err := s.appointments.List(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	loc := s.now().Location()
+	for _, a := range appointments {
+		window, err := a.Window()
+		if err != nil {
+			log.Printf("Skipping appointment %d in calendar feed: %v", a.ID, err)
+			continue
+		}
+		start := windowStart(window, loc)
+
+		event := ical.Event{
+			UID:      fmt.Sprintf("appointment-%d@dental-flow", a.ID),
+			Sequence: a.Sequence,
+			Stamp:    a.UpdatedAt,
+			Start:    start,
+			End:      start.Add(window.End - window.Start),
+			Status:   calendarStatus(a.Status),
+		}
+		// Patient feeds leave out clinical notes, which are written for staff
+		if feedType == models.CalendarFeedDentist {
+			event.Summary = a.PatientName
+			event.Description = a.Notes
+		} else {
+			event.Summary = "Dental appointment"
+			if name := names[a.DentistID]; name != "" {
+				event.Summary += " with " + name
+			}
+		}
+		calendar.Events = append(calendar.Events, event)
+	}
+
+	return calendar, nil
+}
+
+// calendarStatus maps an appointment status to an iCalendar event status
+func calendarStatus(status string) string {
+	switch models.AppointmentStatus(status) {
+	case models.AppointmentStatusPendingConfirmation:
+		return ical.StatusTentative
+	case models.AppointmentStatusCancelled:
+		return ical.StatusCancelled
+	default:
+		return ical.StatusConfirmed
+	}
+}
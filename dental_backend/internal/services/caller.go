@@ -0,0 +1,25 @@
+// dental_backend/internal/services/caller.go
+package services
+
+import "dental_backend/internal/models"
+
+// Caller identifies the authenticated user a service call is made for
+type Caller struct {
+	UserID int
+	Role   models.UserRole
+}
+
+// ClinicWide reports whether the caller works across every dentist's calendar.
+// Front-desk staff and admins do; dentists and hygienists see their own.
+func (c Caller) ClinicWide() bool {
+	return c.Role == models.UserRoleAdmin || c.Role == models.UserRoleStaff
+}
+
+// dentistScope returns the dentist whose records the caller is limited to, or nil for the whole clinic
+func (c Caller) dentistScope() *int {
+	if c.ClinicWide() {
+		return nil
+	}
+	userID := c.UserID
+	return &userID
+}
@@ -0,0 +1,116 @@
+// dental_backend/internal/services/clinic_service.go
+package services
+
+import (
+	"context"
+	"database/sql"
+
+	"dental_backend/internal/models"
+	"dental_backend/internal/repository"
+)
+
+// ClinicService manages the clinics of the group and who works at each. A role
+// given at one clinic applies to the user's sessions there on their next request.
+type ClinicService struct {
+	clinics repository.ClinicRepository
+	users   repository.UserRepository
+}
+
+// NewClinicService creates a new clinic service
+func NewClinicService(clinics repository.ClinicRepository, users repository.UserRepository) *ClinicService {
+	return &ClinicService{clinics: clinics, users: users}
+}
+
+// GetClinic retrieves a clinic by ID, or nil when it does not exist
+func (s *ClinicService) GetClinic(ctx context.Context, id int) (*models.Clinic, error) {
+	return s.clinics.GetByID(ctx, id)
+}
+
+// DefaultClinic retrieves the clinic used when none is named
+func (s *ClinicService) DefaultClinic(ctx context.Context) (*models.Clinic, error) {
+	return s.clinics.Default(ctx)
+}
+
+// CreateClinic opens a clinic with the caller as its admin
+func (s *ClinicService) CreateClinic(ctx context.Context, caller Caller, req models.CreateClinicRequest) (*models.Clinic, error) {
+	return s.clinics.Create(ctx, req, caller.UserID)
+}
+
+// UpdateClinic changes a clinic's details
+func (s *ClinicService) UpdateClinic(ctx context.Context, id int, req models.UpdateClinicRequest) (*models.Clinic, error) {
+	return s.clinics.Update(ctx, id, req)
+}
+
+// GetMembers lists the users working at a clinic
+func (s *ClinicService) GetMembers(ctx context.Context, clinicID int) ([]models.ClinicMember, error) {
+	members, err := s.clinics.Members(ctx, clinicID)
+	if err != nil {
+		return nil, err
+	}
+	if members == nil {
+		members = []models.ClinicMember{}
+	}
+	return members, nil
+}
+
+// SetMember adds a user to a clinic or changes their role there, refusing to
+// demote the clinic's last active admin
+func (s *ClinicService) SetMember(ctx context.Context, clinicID, userID int, req models.SetClinicMemberRequest) error {
+	// A user who is not yet a member, such as one who signed themselves up, is
+	// not among the clinic's users
+	user, err := s.users.GetByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	if user != nil && req.Role != string(models.UserRoleAdmin) {
+		if err := s.guardLastAdmin(ctx, clinicID, user); err != nil {
+			return err
+		}
+	}
+
+	return s.clinics.SetMember(ctx, clinicID, userID, req.Role)
+}
+
+// RemoveMember takes a user off a clinic, refusing to remove its last active admin
+func (s *ClinicService) RemoveMember(ctx context.Context, caller Caller, clinicID, userID int) error {
+	if userID == caller.UserID {
+		return &ValidationError{"You cannot remove yourself from the clinic"}
+	}
+
+	user, err := s.users.GetByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if user == nil {
+		return sql.ErrNoRows
+	}
+	if err := s.guardLastAdmin(ctx, clinicID, user); err != nil {
+		return err
+	}
+
+	return s.clinics.RemoveMember(ctx, clinicID, userID)
+}
+
+// guardLastAdmin refuses to take away the admin role at a clinic from its only active admin
+func (s *ClinicService) guardLastAdmin(ctx context.Context, clinicID int, user *models.User) error {
+	if !user.Active {
+		return nil
+	}
+	membership, err := s.clinics.Membership(ctx, clinicID, user.ID)
+	if err != nil {
+		return err
+	}
+	if membership == nil || membership.Role != string(models.UserRoleAdmin) {
+		return nil
+	}
+
+	admins, err := s.clinics.CountActiveAdmins(ctx, clinicID)
+	if err != nil {
+		return err
+	}
+	if admins <= 1 {
+		return &ConflictError{Message: "Cannot remove the clinic's last active admin"}
+	}
+	return nil
+}
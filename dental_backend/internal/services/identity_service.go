@@ -0,0 +1,213 @@
+// dental_backend/internal/services/identity_service.go
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"dental_backend/internal/models"
+	"dental_backend/internal/repository"
+)
+
+// signInCodeTTL is how long the web app has to redeem a provider sign-in
+const signInCodeTTL = 2 * time.Minute
+
+// IdentityService signs users in with their accounts at external providers. A
+// provider account is linked to a user by that user from a signed-in session, to
+// a newly created user, or, for providers trusted to auto-link, to the user with
+// the same verified email address the first time it is used.
+type IdentityService struct {
+	identities repository.IdentityRepository
+	users      repository.UserRepository
+	clinics    repository.ClinicRepository
+	tokens     repository.AccountTokenRepository
+
+	// now returns the current time; replaced in tests to pin "now"
+	now func() time.Time
+}
+
+// NewIdentityService creates an identity service
+func NewIdentityService(identities repository.IdentityRepository, users repository.UserRepository, clinics repository.ClinicRepository, tokens repository.AccountTokenRepository) *IdentityService {
+	return &IdentityService{identities: identities, users: users, clinics: clinics, tokens: tokens, now: time.Now}
+}
+
+// validRole reports whether role is one users can have
+func validRole(role string) bool {
+	switch models.UserRole(role) {
+	case models.UserRoleDentist, models.UserRoleHygienist, models.UserRoleAdmin, models.UserRoleStaff:
+		return true
+	}
+	return false
+}
+
+// Find returns the user linked to the provider account. An account not linked yet
+// is linked first to the user with the same verified email address when the
+// provider auto-links; otherwise that user must link it after signing in, and a
+// ConflictError says so. It returns nil when there is no such user.
+func (s *IdentityService) Find(ctx context.Context, profile models.ExternalProfile) (*models.User, error) {
+	identity, err := s.identities.Find(ctx, profile.Provider, profile.Subject)
+	if err != nil {
+		return nil, err
+	}
+	if identity != nil {
+		if err := s.identities.RecordLogin(ctx, identity.ID); err != nil {
+			return nil, err
+		}
+		return s.users.GetByID(ctx, identity.UserID)
+	}
+
+	// Only a verified address proves the account belongs to the same person
+	if profile.Email == "" || !profile.EmailVerified {
+		return nil, nil
+	}
+	user, err := s.users.FindByEmail(ctx, profile.Email)
+	if err != nil || user == nil {
+		return nil, err
+	}
+	if !profile.AutoLink {
+		return nil, &ConflictError{Message: "An account with this email address already exists; sign in and link your " + profile.Provider + " account to it"}
+	}
+
+	if _, err := s.link(ctx, user.ID, profile); err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+// Link links the provider account to a signed-in user. Linking an account the
+// user already has is not an error.
+func (s *IdentityService) Link(ctx context.Context, userID int, profile models.ExternalProfile) (*models.UserIdentity, error) {
+	identity, err := s.identities.Find(ctx, profile.Provider, profile.Subject)
+	if err != nil {
+		return nil, err
+	}
+	if identity != nil {
+		if identity.UserID != userID {
+			return nil, &ConflictError{Message: "This " + profile.Provider + " account is already linked to another user"}
+		}
+		return identity, nil
+	}
+	return s.link(ctx, userID, profile)
+}
+
+// Register creates a user for a provider account that is not linked yet. The user
+// joins the clinic of ctx, or none when it has none.
+func (s *IdentityService) Register(ctx context.Context, profile models.ExternalProfile) (*models.User, error) {
+	if profile.Email == "" {
+		return nil, &ValidationError{"The sign-in provider did not share an email address"}
+	}
+	if !validRole(profile.Role) {
+		return nil, &ValidationError{fmt.Sprintf("Invalid role %q", profile.Role)}
+	}
+
+	// Provider accounts have no password; they can set one through a password reset
+	user, err := s.users.Create(ctx, models.CreateUserRequest{
+		Email:     profile.Email,
+		FirstName: profile.FirstName,
+		LastName:  profile.LastName,
+		Role:      profile.Role,
+		Phone:     profile.Phone,
+	}, "")
+	if err != nil {
+		if errors.Is(err, repository.ErrEmailTaken) {
+			return nil, &ConflictError{Message: "User already exists"}
+		}
+		return nil, err
+	}
+
+	if profile.EmailVerified {
+		if err := s.users.SetEmailVerified(ctx, user.ID, true); err != nil {
+			return nil, err
+		}
+		user.EmailVerified = true
+	}
+
+	if _, err := s.link(ctx, user.ID, profile); err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+// SignIn returns the user for a provider account, creating one when the provider
+// assigned a role. The role comes from the operator's group mapping, so the new
+// user joins the default clinic with it.
+func (s *IdentityService) SignIn(ctx context.Context, profile models.ExternalProfile) (*models.User, error) {
+	user, err := s.Find(ctx, profile)
+	if err != nil || user != nil {
+		return user, err
+	}
+	if profile.Role == "" {
+		return nil, &ForbiddenError{"No account exists for this user; ask an administrator for access"}
+	}
+
+	clinic, err := s.clinics.Default(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if clinic == nil {
+		return nil, ErrNoClinic
+	}
+	return s.Register(repository.WithClinic(ctx, clinic.ID), profile)
+}
+
+// link records the provider account as belonging to the user
+func (s *IdentityService) link(ctx context.Context, userID int, profile models.ExternalProfile) (*models.UserIdentity, error) {
+	now := s.now()
+	identity, err := s.identities.Create(ctx, models.UserIdentity{
+		UserID:      userID,
+		Provider:    profile.Provider,
+		Subject:     profile.Subject,
+		Email:       profile.Email,
+		LastLoginAt: &now,
+	})
+	if errors.Is(err, repository.ErrIdentityTaken) {
+		return nil, &ConflictError{Message: "This user is already linked to a different " + profile.Provider + " account"}
+	}
+	return identity, err
+}
+
+// ListIdentities returns the provider accounts linked to the user
+func (s *IdentityService) ListIdentities(ctx context.Context, userID int) ([]models.UserIdentity, error) {
+	return s.identities.ListByUser(ctx, userID)
+}
+
+// IssueSignInCode returns a single-use code the web app exchanges for the user's
+// tokens once the provider redirects back, so no token appears in a URL
+func (s *IdentityService) IssueSignInCode(ctx context.Context, user models.User) (string, error) {
+	code, err := randomToken(32)
+	if err != nil {
+		return "", err
+	}
+
+	_, err = s.tokens.Create(ctx, models.AccountToken{
+		UserID:    user.ID,
+		Purpose:   models.AccountTokenSignIn,
+		ExpiresAt: s.now().Add(signInCodeTTL),
+	}, hashAccountToken(code))
+	if err != nil {
+		return "", err
+	}
+	return code, nil
+}
+
+// RedeemSignInCode returns the user a sign-in code was issued for
+func (s *IdentityService) RedeemSignInCode(ctx context.Context, code string) (*models.User, error) {
+	used, err := s.tokens.Consume(ctx, models.AccountTokenSignIn, hashAccountToken(code))
+	if err != nil {
+		return nil, err
+	}
+	if used == nil {
+		return nil, ErrInvalidAccountToken
+	}
+
+	user, err := s.users.GetByID(ctx, used.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil {
+		return nil, ErrInvalidAccountToken
+	}
+	return user, nil
+}
@@ -0,0 +1,317 @@
+// dental_backend/internal/services/medical_service.go
+package services
+
+import (
+	"context"
+	"database/sql"
+	"sort"
+	"time"
+
+	"dental_backend/internal/models"
+	"dental_backend/internal/repository"
+)
+
+// MedicalHistoryService manages the allergies, medications and conditions of
+// patients and the medical alerts drawn from them
+type MedicalHistoryService struct {
+	entries  repository.MedicalEntryRepository
+	patients repository.PatientRepository
+
+	// risk, when attached by RiskService.Watch, rederives the risk of patients
+	// whose conditions change
+	risk *RiskService
+}
+
+// NewMedicalHistoryService creates a new medical history service
+func NewMedicalHistoryService(entries repository.MedicalEntryRepository, patients repository.PatientRepository) *MedicalHistoryService {
+	return &MedicalHistoryService{entries: entries, patients: patients}
+}
+
+// severityRank orders severities for alerts, most serious first
+var severityRank = map[string]int{
+	string(models.MedicalSeverityLifeThreatening): 4,
+	string(models.MedicalSeveritySevere):          3,
+	string(models.MedicalSeverityModerate):        2,
+	string(models.MedicalSeverityMild):            1,
+}
+
+// kindRank orders kinds for alerts with the same severity
+var kindRank = map[string]int{
+	string(models.MedicalEntryAllergy):    0,
+	string(models.MedicalEntryMedication): 1,
+	string(models.MedicalEntryCondition):  2,
+}
+
+// medicalAlerts returns the alerts among entries: every active allergy, and
+// active medications and conditions that are flagged or at least severe
+func medicalAlerts(entries []models.MedicalEntry) []models.MedicalAlert {
+	alerts := []models.MedicalAlert{}
+	for _, e := range entries {
+		if e.Status != string(models.MedicalEntryStatusActive) {
+			continue
+		}
+		if e.Kind != string(models.MedicalEntryAllergy) && !e.Alert && severityRank[e.Severity] < severityRank[string(models.MedicalSeveritySevere)] {
+			continue
+		}
+
+		alert := models.MedicalAlert{EntryID: e.ID, Kind: e.Kind, Name: e.Name, Severity: e.Severity}
+		switch e.Kind {
+		case string(models.MedicalEntryAllergy):
+			alert.Detail = e.Reaction
+		case string(models.MedicalEntryMedication):
+			alert.Detail = e.Dosage
+		}
+		alerts = append(alerts, alert)
+	}
+
+	sort.SliceStable(alerts, func(i, j int) bool {
+		if severityRank[alerts[i].Severity] != severityRank[alerts[j].Severity] {
+			return severityRank[alerts[i].Severity] > severityRank[alerts[j].Severity]
+		}
+		if kindRank[alerts[i].Kind] != kindRank[alerts[j].Kind] {
+			return kindRank[alerts[i].Kind] < kindRank[alerts[j].Kind]
+		}
+		return alerts[i].Name < alerts[j].Name
+	})
+	return alerts
+}
+
+// GetHistory retrieves a patient's medical history, or nil when the patient does not exist
+func (s *MedicalHistoryService) GetHistory(ctx context.Context, patientID int) (*models.MedicalHistory, error) {
+	patient, err := s.patients.GetByID(ctx, patientID)
+	if err != nil || patient == nil {
+		return nil, err
+	}
+
+	entries, err := s.entries.ListForPatient(ctx, patientID)
+	if err != nil {
+		return nil, err
+	}
+
+	history := &models.MedicalHistory{
+		PatientID:   patientID,
+		Alerts:      medicalAlerts(entries),
+		Allergies:   []models.MedicalEntry{},
+		Medications: []models.MedicalEntry{},
+		Conditions:  []models.MedicalEntry{},
+		Notes:       patient.MedicalHistory,
+	}
+	for _, e := range entries {
+		switch e.Kind {
+		case string(models.MedicalEntryAllergy):
+			history.Allergies = append(history.Allergies, e)
+		case string(models.MedicalEntryMedication):
+			history.Medications = append(history.Medications, e)
+		case string(models.MedicalEntryCondition):
+			history.Conditions = append(history.Conditions, e)
+		}
+	}
+	return history, nil
+}
+
+// GetPatientDetail retrieves a patient with their medical alerts, or nil when
+// the patient does not exist
+func (s *MedicalHistoryService) GetPatientDetail(ctx context.Context, patientID int) (*models.PatientDetail, error) {
+	patient, err := s.patients.GetByID(ctx, patientID)
+	if err != nil || patient == nil {
+		return nil, err
+	}
+
+	entries, err := s.entries.ListActive(ctx, []int{patientID})
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.PatientDetail{Patient: *patient, MedicalAlerts: medicalAlerts(entries)}, nil
+}
+
+// AttachAlerts sets the medical alerts of each treatment's patient
+func (s *MedicalHistoryService) AttachAlerts(ctx context.Context, treatments []models.PatientTreatment) error {
+	if len(treatments) == 0 {
+		return nil
+	}
+
+	var patientIDs []int
+	seen := make(map[int]bool)
+	for _, pt := range treatments {
+		if !seen[pt.PatientID] {
+			seen[pt.PatientID] = true
+			patientIDs = append(patientIDs, pt.PatientID)
+		}
+	}
+
+	entries, err := s.entries.ListActive(ctx, patientIDs)
+	if err != nil {
+		return err
+	}
+	byPatient := make(map[int][]models.MedicalEntry)
+	for _, e := range entries {
+		byPatient[e.PatientID] = append(byPatient[e.PatientID], e)
+	}
+
+	for i := range treatments {
+		treatments[i].MedicalAlerts = medicalAlerts(byPatient[treatments[i].PatientID])
+	}
+	return nil
+}
+
+// ListEntries retrieves a patient's entries of one kind, returning
+// sql.ErrNoRows when the patient does not exist
+func (s *MedicalHistoryService) ListEntries(ctx context.Context, patientID int, kind models.MedicalEntryKind) ([]models.MedicalEntry, error) {
+	patient, err := s.patients.GetByID(ctx, patientID)
+	if err != nil {
+		return nil, err
+	}
+	if patient == nil {
+		return nil, sql.ErrNoRows
+	}
+
+	all, err := s.entries.ListForPatient(ctx, patientID)
+	if err != nil {
+		return nil, err
+	}
+
+	entries := []models.MedicalEntry{}
+	for _, e := range all {
+		if e.Kind == string(kind) {
+			entries = append(entries, e)
+		}
+	}
+	return entries, nil
+}
+
+// CreateEntry adds an entry of one kind to a patient's medical history,
+// returning sql.ErrNoRows when the patient does not exist
+func (s *MedicalHistoryService) CreateEntry(ctx context.Context, caller Caller, patientID int, kind models.MedicalEntryKind, req models.CreateMedicalEntryRequest) (*models.MedicalEntry, error) {
+	if err := validateOnset(req.Onset); err != nil {
+		return nil, err
+	}
+
+	patient, err := s.patients.GetByID(ctx, patientID)
+	if err != nil {
+		return nil, err
+	}
+	if patient == nil {
+		return nil, sql.ErrNoRows
+	}
+
+	entry := models.MedicalEntry{
+		PatientID: patientID,
+		Kind:      string(kind),
+		Name:      req.Name,
+		Severity:  req.Severity,
+		Reaction:  req.Reaction,
+		Dosage:    req.Dosage,
+		Onset:     req.Onset,
+		Status:    req.Status,
+		Alert:     req.Alert,
+		Notes:     req.Notes,
+	}
+	if entry.Status == "" {
+		entry.Status = string(models.MedicalEntryStatusActive)
+	}
+	if caller.UserID != 0 {
+		recordedBy := caller.UserID
+		entry.RecordedBy = &recordedBy
+	}
+
+	created, err := s.entries.Create(ctx, entry)
+	if err != nil {
+		return nil, err
+	}
+	s.refreshRisk(ctx, *created)
+	return created, nil
+}
+
+// UpdateEntry applies the provided fields to one of a patient's entries of a
+// kind, returning sql.ErrNoRows when there is no such entry
+func (s *MedicalHistoryService) UpdateEntry(ctx context.Context, patientID int, kind models.MedicalEntryKind, id int, req models.UpdateMedicalEntryRequest) (*models.MedicalEntry, error) {
+	if req.Onset != nil {
+		if err := validateOnset(*req.Onset); err != nil {
+			return nil, err
+		}
+	}
+
+	entry, err := s.getEntry(ctx, patientID, kind, id)
+	if err != nil {
+		return nil, err
+	}
+
+	fields := []struct {
+		target *string
+		value  *string
+	}{
+		{&entry.Name, req.Name},
+		{&entry.Severity, req.Severity},
+		{&entry.Reaction, req.Reaction},
+		{&entry.Dosage, req.Dosage},
+		{&entry.Onset, req.Onset},
+		{&entry.Status, req.Status},
+		{&entry.Notes, req.Notes},
+	}
+	for _, field := range fields {
+		if field.value != nil {
+			*field.target = *field.value
+		}
+	}
+	if req.Alert != nil {
+		entry.Alert = *req.Alert
+	}
+
+	updated, err := s.entries.Update(ctx, *entry)
+	if err != nil {
+		return nil, err
+	}
+	s.refreshRisk(ctx, *updated)
+	return updated, nil
+}
+
+// DeleteEntry removes one of a patient's entries of a kind, returning
+// sql.ErrNoRows when there is no such entry
+func (s *MedicalHistoryService) DeleteEntry(ctx context.Context, patientID int, kind models.MedicalEntryKind, id int) error {
+	entry, err := s.getEntry(ctx, patientID, kind, id)
+	if err != nil {
+		return err
+	}
+	if err := s.entries.Delete(ctx, id); err != nil {
+		return err
+	}
+	s.refreshRisk(ctx, *entry)
+	return nil
+}
+
+// refreshRisk rederives the patient's risk after a change to one of their
+// conditions; allergies and medications do not count towards it
+func (s *MedicalHistoryService) refreshRisk(ctx context.Context, entry models.MedicalEntry) {
+	if entry.Kind == string(models.MedicalEntryCondition) {
+		s.risk.refresh(ctx, entry.PatientID)
+	}
+}
+
+// getEntry retrieves an entry, returning sql.ErrNoRows unless it is of the
+// kind and belongs to the patient
+func (s *MedicalHistoryService) getEntry(ctx context.Context, patientID int, kind models.MedicalEntryKind, id int) (*models.MedicalEntry, error) {
+	entry, err := s.entries.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if entry == nil || entry.PatientID != patientID || entry.Kind != string(kind) {
+		return nil, sql.ErrNoRows
+	}
+	return entry, nil
+}
+
+// validateOnset checks an onset is empty or a date no later than today
+func validateOnset(onset string) error {
+	if onset == "" {
+		return nil
+	}
+	date, err := time.Parse("2006-01-02", onset)
+	if err != nil {
+		return &ValidationError{"onset must be a date in YYYY-MM-DD format"}
+	}
+	if date.After(time.Now()) {
+		return &ValidationError{"onset cannot be in the future"}
+	}
+	return nil
+}
@@ -0,0 +1,344 @@
+// dental_backend/internal/services/mfa_service.go
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base32"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"dental_backend/internal/models"
+	"dental_backend/internal/repository"
+	"dental_backend/internal/totp"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// ErrInvalidChallenge is returned for MFA challenge tokens that are malformed,
+// expired or belong to a user who can no longer sign in
+var ErrInvalidChallenge = errors.New("invalid or expired two-factor challenge")
+
+const (
+	// challengeTTL is how long a user has to answer a login challenge
+	challengeTTL = 5 * time.Minute
+	// challengeType marks challenge tokens so they are never mistaken for access tokens
+	challengeType = "mfa"
+
+	backupCodeCount = 10
+	// totpSkew is how many 30-second steps of clock drift are tolerated either way
+	totpSkew = 1
+)
+
+// MFAService manages TOTP two-factor authentication. A password login by a user
+// with two-factor enabled, or whose role the policy requires it for, returns a
+// short-lived challenge token instead of a session; answering the challenge with
+// an authenticator or backup code starts the session.
+type MFAService struct {
+	mfa    repository.MFARepository
+	users  repository.UserRepository
+	policy repository.AuthPolicyRepository
+
+	secret []byte
+	issuer string
+
+	// now returns the current time; replaced in tests to pin "now"
+	now func() time.Time
+}
+
+// NewMFAService creates a two-factor service signing challenges with secret and
+// naming issuer in authenticator apps
+func NewMFAService(mfa repository.MFARepository, users repository.UserRepository, policy repository.AuthPolicyRepository, secret, issuer string) *MFAService {
+	return &MFAService{
+		mfa:    mfa,
+		users:  users,
+		policy: policy,
+		secret: []byte(secret),
+		issuer: issuer,
+		now:    time.Now,
+	}
+}
+
+// hashBackupCode returns the stored form of a backup code
+func hashBackupCode(code string) string {
+	sum := sha256.Sum256([]byte(normalizeBackupCode(code)))
+	return hex.EncodeToString(sum[:])
+}
+
+// normalizeBackupCode drops the separators and case users may type a code with
+func normalizeBackupCode(code string) string {
+	return strings.ToUpper(strings.NewReplacer("-", "", " ", "").Replace(code))
+}
+
+// newBackupCodes returns fresh backup codes formatted for display, with their hashes
+func newBackupCodes() ([]string, []string, error) {
+	codes := make([]string, backupCodeCount)
+	hashes := make([]string, backupCodeCount)
+	for i := range codes {
+		raw := make([]byte, 7)
+		if _, err := rand.Read(raw); err != nil {
+			return nil, nil, err
+		}
+		code := base32.StdEncoding.EncodeToString(raw)[:10]
+		codes[i] = code[:5] + "-" + code[5:]
+		hashes[i] = hashBackupCode(code)
+	}
+	return codes, hashes, nil
+}
+
+// Status reports the caller's two-factor state, or nil when the user does not exist
+func (s *MFAService) Status(ctx context.Context, caller Caller) (*models.MFAStatus, error) {
+	state, err := s.mfa.Get(ctx, caller.UserID)
+	if err != nil || state == nil {
+		return nil, err
+	}
+	policy, err := s.policy.Get(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.MFAStatus{
+		Enabled:              state.EnabledAt != nil,
+		EnabledAt:            state.EnabledAt,
+		BackupCodesRemaining: state.BackupCodesRemaining,
+		Required:             policy.RequiresMFA(string(caller.Role)),
+	}, nil
+}
+
+// Setup generates a new authenticator secret for the user. It is not enforced
+// until confirmed with a code through Enable.
+func (s *MFAService) Setup(ctx context.Context, user models.User) (*models.MFASetup, error) {
+	if user.MFAEnabled {
+		return nil, &ConflictError{Message: "Two-factor authentication is already enabled"}
+	}
+
+	secret, err := totp.GenerateSecret()
+	if err != nil {
+		return nil, err
+	}
+	if err := s.mfa.SetSecret(ctx, user.ID, secret); err != nil {
+		return nil, err
+	}
+
+	return &models.MFASetup{
+		Secret:          secret,
+		ProvisioningURI: totp.ProvisioningURI(s.issuer, user.Email, secret),
+	}, nil
+}
+
+// Enable confirms the secret from Setup with a code from the authenticator app and
+// returns the backup codes, which are only shown this once
+func (s *MFAService) Enable(ctx context.Context, userID int, code string) ([]string, error) {
+	state, err := s.mfa.Get(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if state == nil {
+		return nil, ErrInvalidChallenge
+	}
+	if state.EnabledAt != nil {
+		return nil, &ConflictError{Message: "Two-factor authentication is already enabled"}
+	}
+	if state.Secret == "" {
+		return nil, &ValidationError{"Set up two-factor authentication first"}
+	}
+
+	step, ok := totp.Validate(state.Secret, code, s.now(), totpSkew)
+	if !ok {
+		return nil, &ValidationError{"Invalid authentication code"}
+	}
+
+	codes, hashes, err := newBackupCodes()
+	if err != nil {
+		return nil, err
+	}
+	if err := s.mfa.Enable(ctx, userID, step, hashes); err != nil {
+		return nil, err
+	}
+	return codes, nil
+}
+
+// Disable turns two-factor authentication off after checking a current code.
+// Users whose role the policy requires it for cannot turn it off.
+func (s *MFAService) Disable(ctx context.Context, caller Caller, code string) error {
+	policy, err := s.policy.Get(ctx)
+	if err != nil {
+		return err
+	}
+	if policy.RequiresMFA(string(caller.Role)) {
+		return &ForbiddenError{"Two-factor authentication is required for your role"}
+	}
+
+	if err := s.checkCode(ctx, caller.UserID, code); err != nil {
+		return err
+	}
+	return s.mfa.Disable(ctx, caller.UserID)
+}
+
+// RegenerateBackupCodes replaces the user's backup codes after checking a current code
+func (s *MFAService) RegenerateBackupCodes(ctx context.Context, userID int, code string) ([]string, error) {
+	if err := s.checkCode(ctx, userID, code); err != nil {
+		return nil, err
+	}
+
+	codes, hashes, err := newBackupCodes()
+	if err != nil {
+		return nil, err
+	}
+	if err := s.mfa.ReplaceBackupCodes(ctx, userID, hashes); err != nil {
+		return nil, err
+	}
+	return codes, nil
+}
+
+// checkCode verifies an authenticator or backup code for a user with two-factor enabled
+func (s *MFAService) checkCode(ctx context.Context, userID int, code string) error {
+	state, err := s.mfa.Get(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if state == nil || state.EnabledAt == nil {
+		return &ValidationError{"Two-factor authentication is not enabled"}
+	}
+
+	ok, err := s.verify(ctx, state, code)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return &ValidationError{"Invalid authentication code"}
+	}
+	return nil
+}
+
+// verify accepts a TOTP code whose step has not been used yet, or an unused backup code
+func (s *MFAService) verify(ctx context.Context, state *models.UserMFA, code string) (bool, error) {
+	code = strings.TrimSpace(code)
+	if len(code) == totp.Digits {
+		step, ok := totp.Validate(state.Secret, code, s.now(), totpSkew)
+		if !ok {
+			return false, nil
+		}
+		return s.mfa.UseStep(ctx, state.UserID, step)
+	}
+
+	return s.mfa.UseBackupCode(ctx, state.UserID, hashBackupCode(code))
+}
+
+// EnrollmentRequired reports whether the user has to enable two-factor
+// authentication before working at the clinic: the policy requires it for their
+// role there and they have not enabled it
+func (s *MFAService) EnrollmentRequired(ctx context.Context, user models.User, clinic models.UserClinic) (bool, error) {
+	if user.MFAEnabled {
+		return false, nil
+	}
+	policy, err := s.policy.Get(repository.WithClinic(ctx, clinic.ClinicID))
+	if err != nil {
+		return false, err
+	}
+	return policy.RequiresMFA(clinic.Role), nil
+}
+
+// Challenge returns the second step a password login into the clinic has to pass,
+// or nil when the user can be signed in straight away
+func (s *MFAService) Challenge(ctx context.Context, user models.User, clinic models.UserClinic) (*models.MFAChallenge, error) {
+	enroll, err := s.EnrollmentRequired(ctx, user, clinic)
+	if err != nil {
+		return nil, err
+	}
+	if !user.MFAEnabled && !enroll {
+		return nil, nil
+	}
+
+	now := s.now()
+	expiresAt := now.Add(challengeTTL)
+	claims := jwt.MapClaims{
+		"typ":         challengeType,
+		"mfa_user_id": user.ID,
+		"enroll":      enroll,
+		"exp":         expiresAt.Unix(),
+		"iat":         now.Unix(),
+	}
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(s.secret)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.MFAChallenge{Token: token, ExpiresAt: expiresAt, EnrollmentRequired: enroll}, nil
+}
+
+// parseChallenge verifies a challenge token and loads its user, who must still be active
+func (s *MFAService) parseChallenge(ctx context.Context, tokenString string) (*models.User, bool, error) {
+	token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
+		// Validate the alg is what we expect
+		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
+		}
+		return s.secret, nil
+	}, jwt.WithTimeFunc(s.now))
+	if err != nil || !token.Valid {
+		return nil, false, ErrInvalidChallenge
+	}
+
+	claims, ok := token.Claims.(jwt.MapClaims)
+	if !ok || claims["typ"] != challengeType {
+		return nil, false, ErrInvalidChallenge
+	}
+	userID, ok := claims["mfa_user_id"].(float64)
+	if !ok {
+		return nil, false, ErrInvalidChallenge
+	}
+	enroll, _ := claims["enroll"].(bool)
+
+	user, err := s.users.GetByID(ctx, int(userID))
+	if err != nil {
+		return nil, false, err
+	}
+	if user == nil || !user.Active {
+		return nil, false, ErrInvalidChallenge
+	}
+	return user, enroll, nil
+}
+
+// EnrollFromChallenge sets up an authenticator for a user the policy stopped at login
+func (s *MFAService) EnrollFromChallenge(ctx context.Context, token string) (*models.MFASetup, error) {
+	user, enroll, err := s.parseChallenge(ctx, token)
+	if err != nil {
+		return nil, err
+	}
+	if !enroll {
+		return nil, ErrInvalidChallenge
+	}
+	return s.Setup(ctx, *user)
+}
+
+// CompleteChallenge answers a login challenge and returns the user to start a session
+// for. For an enrolment challenge the code confirms the new authenticator, and the
+// backup codes generated for it are returned too.
+func (s *MFAService) CompleteChallenge(ctx context.Context, token, code string) (*models.User, []string, error) {
+	user, enroll, err := s.parseChallenge(ctx, token)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if user.MFAEnabled {
+		if err := s.checkCode(ctx, user.ID, code); err != nil {
+			return nil, nil, err
+		}
+		return user, nil, nil
+	}
+	if !enroll {
+		return nil, nil, ErrInvalidChallenge
+	}
+
+	codes, err := s.Enable(ctx, user.ID, code)
+	if err != nil {
+		return nil, nil, err
+	}
+	user.MFAEnabled = true
+	return user, codes, nil
+}
@@ -0,0 +1,261 @@
+// dental_backend/internal/services/reminder_service.go
+package services
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sort"
+	"time"
+
+	"dental_backend/internal/models"
+	"dental_backend/internal/notify"
+	"dental_backend/internal/repository"
+)
+
+// reminderChannels is the order channels are tried in for each reminder
+var reminderChannels = []notify.Channel{notify.ChannelEmail, notify.ChannelSMS}
+
+// ReminderService sends appointment reminders at fixed offsets before each appointment.
+// Every send is claimed in the reminder repository first, so a restarted or second
+// worker never sends the same reminder twice.
+type ReminderService struct {
+	appointments repository.AppointmentRepository
+	patients     repository.PatientRepository
+	reminders    repository.ReminderRepository
+	notifiers    notify.Notifiers
+
+	// offsets are how long before an appointment reminders go out, shortest first
+	offsets []time.Duration
+
+	// links and linkURL add a self-service link to each reminder when set;
+	// the token is appended to linkURL
+	links   *LinkTokens
+	linkURL string
+
+	// now returns the current time; replaced in tests to pin "now"
+	now func() time.Time
+}
+
+// NewReminderService creates a reminder service sending through the given notifiers.
+// Channels without a notifier are skipped.
+func NewReminderService(appointments repository.AppointmentRepository, patients repository.PatientRepository, reminders repository.ReminderRepository, notifiers notify.Notifiers, offsets []time.Duration) *ReminderService {
+	sorted := append([]time.Duration(nil), offsets...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+
+	return &ReminderService{
+		appointments: appointments,
+		patients:     patients,
+		reminders:    reminders,
+		notifiers:    notifiers,
+		offsets:      sorted,
+		now:          time.Now,
+	}
+}
+
+// SetNotifiers replaces the notifiers reminders are sent through
+func (s *ReminderService) SetNotifiers(notifiers notify.Notifiers) {
+	s.notifiers = notifiers
+}
+
+// SetLinks adds a signed confirm, cancel and reschedule link to every reminder,
+// formed by appending the token to baseURL
+func (s *ReminderService) SetLinks(links *LinkTokens, baseURL string) {
+	s.links = links
+	s.linkURL = baseURL
+}
+
+// SetClock overrides the clock used to decide which reminders are due
+func (s *ReminderService) SetClock(now func() time.Time) {
+	s.now = now
+}
+
+// Run sends due reminders every interval until ctx is cancelled
+func (s *ReminderService) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if sent, err := s.RunOnce(ctx); err != nil {
+			log.Printf("Error sending appointment reminders: %v", err)
+		} else if sent > 0 {
+			log.Printf("Sent %d appointment reminders", sent)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// RunOnce sends every reminder that has fallen due and returns how many were sent.
+// Failures to reach a single patient are recorded on the delivery and retried on
+// later runs; only errors reading the schedule are returned.
+func (s *ReminderService) RunOnce(ctx context.Context) (int, error) {
+	if len(s.offsets) == 0 || len(s.notifiers) == 0 {
+		return 0, nil
+	}
+
+	now := s.now()
+	from := now.Format("2006-01-02")
+	to := now.Add(s.offsets[len(s.offsets)-1]).Format("2006-01-02")
+
+	appointments, err := s.appointments.List(ctx, repository.AppointmentFilter{From: &from, To: &to})
+	if err != nil {
+		return 0, err
+	}
+
+	sent := 0
+	for _, appointment := range appointments {
+		if !appointment.IsUpcoming() {
+			continue
+		}
+
+		window, err := appointment.Window()
+		if err != nil {
+			log.Printf("Skipping reminders for appointment %d: %v", appointment.ID, err)
+			continue
+		}
+		startsAt := windowStart(window, now.Location())
+
+		offset, due := s.dueOffset(startsAt, now)
+		if !due {
+			continue
+		}
+
+		patient, err := s.patients.GetByID(ctx, appointment.PatientID)
+		if err != nil {
+			return sent, err
+		}
+		if patient == nil {
+			continue
+		}
+
+		for _, channel := range reminderChannels {
+			if err := ctx.Err(); err != nil {
+				return sent, err
+			}
+			ok, err := s.send(ctx, appointment, window, startsAt, offset, channel, *patient)
+			if err != nil {
+				return sent, err
+			}
+			if ok {
+				sent++
+			}
+		}
+	}
+
+	return sent, nil
+}
+
+// dueOffset returns the shortest offset whose send time has passed for an appointment
+// that has not started yet. Reminders for longer offsets missed while the worker was
+// down are skipped rather than sent late alongside the current one.
+func (s *ReminderService) dueOffset(startsAt, now time.Time) (time.Duration, bool) {
+	if !startsAt.After(now) {
+		return 0, false
+	}
+	for _, offset := range s.offsets {
+		if !now.Before(startsAt.Add(-offset)) {
+			return offset, true
+		}
+	}
+	return 0, false
+}
+
+// send claims and delivers one reminder over one channel, reporting whether it went out
+func (s *ReminderService) send(ctx context.Context, appointment models.Appointment, window models.AppointmentWindow, startsAt time.Time, offset time.Duration, channel notify.Channel, patient models.Patient) (bool, error) {
+	notifier, ok := s.notifiers[channel]
+	if !ok {
+		return false, nil
+	}
+
+	recipient := patient.Email
+	if channel == notify.ChannelSMS {
+		recipient = patient.Phone
+	}
+	if recipient == "" {
+		return false, nil
+	}
+
+	delivery, err := s.reminders.Claim(ctx, models.ReminderDelivery{
+		AppointmentID:   appointment.ID,
+		AppointmentDate: startsAt.Format("2006-01-02"),
+		StartTime:       models.FormatHourMinute(window.Start),
+		OffsetMinutes:   int(offset / time.Minute),
+		Channel:         string(channel),
+		Recipient:       recipient,
+	})
+	if err != nil {
+		return false, err
+	}
+	if delivery == nil {
+		return false, nil
+	}
+
+	link := ""
+	if s.links != nil && s.linkURL != "" {
+		token, err := s.links.Issue(appointment.ID, startsAt)
+		if err != nil {
+			return false, err
+		}
+		link = s.linkURL + token
+	}
+
+	sendErr := ""
+	if err := notifier.Send(ctx, reminderMessage(channel, recipient, patient, startsAt, link)); err != nil {
+		log.Printf("Error sending %s reminder for appointment %d: %v", channel, appointment.ID, err)
+		sendErr = err.Error()
+	}
+
+	// Record the outcome even if the run is being cancelled, so a sent reminder is never retried
+	if err := s.reminders.Finish(context.WithoutCancel(ctx), delivery.ID, sendErr); err != nil {
+		return false, err
+	}
+
+	return sendErr == "", nil
+}
+
+// reminderMessage renders the reminder text for a channel, with the self-service link when there is one
+func reminderMessage(channel notify.Channel, recipient string, patient models.Patient, startsAt time.Time, link string) notify.Message {
+	when := startsAt.Format("Monday, 2 January 2006 at 15:04")
+
+	body := fmt.Sprintf("Hi %s, this is a reminder of your dental appointment on %s.", patient.FirstName, when)
+	if link != "" {
+		body += " Confirm, cancel or reschedule: " + link
+	}
+	if channel == notify.ChannelEmail {
+		action := "If you can no longer attend, please contact the clinic so the slot can be offered to another patient.\n"
+		if link != "" {
+			action = "Please confirm, cancel or reschedule your appointment here:\n" + link + "\n"
+		}
+		body = fmt.Sprintf("Dear %s,\n\nThis is a reminder of your dental appointment on %s.\n\n%s", patient.FirstName, when, action)
+	}
+
+	return notify.Message{
+		Channel: channel,
+		To:      recipient,
+		Subject: "Appointment reminder",
+		Body:    body,
+	}
+}
+
+// GetReminders retrieves the reminders sent for an appointment visible to the caller,
+// or nil when the appointment is not found
+func (s *ReminderService) GetReminders(ctx context.Context, appointmentID int, caller Caller) ([]models.ReminderDelivery, error) {
+	appointment, err := s.appointments.GetByID(ctx, appointmentID, caller.dentistScope())
+	if err != nil || appointment == nil {
+		return nil, err
+	}
+
+	deliveries, err := s.reminders.ListForAppointment(ctx, appointmentID)
+	if err != nil {
+		return nil, err
+	}
+	if deliveries == nil {
+		deliveries = []models.ReminderDelivery{}
+	}
+	return deliveries, nil
+}
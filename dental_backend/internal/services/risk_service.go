@@ -0,0 +1,356 @@
+// dental_backend/internal/services/risk_service.go
+package services
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strconv"
+	"time"
+
+	"dental_backend/internal/models"
+	"dental_backend/internal/repository"
+)
+
+// RiskService derives each patient's risk score and level from their medical
+// conditions, age, outstanding high-priority treatments, no-shows and overdue
+// invoices, by configurable rules. The level entered by hand on the patient is
+// kept alongside it.
+type RiskService struct {
+	risk         repository.RiskRepository
+	patients     repository.PatientRepository
+	entries      repository.MedicalEntryRepository
+	treatments   repository.TreatmentRepository
+	appointments repository.AppointmentRepository
+	billing      repository.BillingRepository
+
+	// now returns the current time; replaced in tests to pin "today"
+	now func() time.Time
+}
+
+// NewRiskService creates a new risk service
+func NewRiskService(risk repository.RiskRepository, patients repository.PatientRepository, entries repository.MedicalEntryRepository, treatments repository.TreatmentRepository, appointments repository.AppointmentRepository, billing repository.BillingRepository) *RiskService {
+	return &RiskService{
+		risk:         risk,
+		patients:     patients,
+		entries:      entries,
+		treatments:   treatments,
+		appointments: appointments,
+		billing:      billing,
+		now:          time.Now,
+	}
+}
+
+// Watch attaches the service to the services whose changes feed into risk, so
+// each such change recomputes the patients it concerns
+func (s *RiskService) Watch(patients *PatientService, medical *MedicalHistoryService, treatments *TreatmentService, appointments *AppointmentService, billing *BillingService) {
+	patients.risk = s
+	medical.risk = s
+	treatments.risk = s
+	appointments.risk = s
+	billing.risk = s
+}
+
+// SetClock overrides the clock used for ages and overdue invoices
+func (s *RiskService) SetClock(now func() time.Time) {
+	s.now = now
+}
+
+// GetRules retrieves the clinic's configured rules, or the defaults when none are
+func (s *RiskService) GetRules(ctx context.Context) (*models.RiskRules, error) {
+	return orDefaultRules(s.risk.Rules(ctx))
+}
+
+// patientRules retrieves the rules of the clinic the patient belongs to
+func (s *RiskService) patientRules(ctx context.Context, patientID int) (*models.RiskRules, error) {
+	return orDefaultRules(s.risk.PatientRules(ctx, patientID))
+}
+
+// orDefaultRules returns the default rules in place of none configured
+func orDefaultRules(rules *models.RiskRules, err error) (*models.RiskRules, error) {
+	if err != nil || rules != nil {
+		return rules, err
+	}
+	defaults := models.DefaultRiskRules()
+	return &defaults, nil
+}
+
+// UpdateRules replaces the clinic's rules. Assessments made under the old rules stand
+// until their patients are next recomputed.
+func (s *RiskService) UpdateRules(ctx context.Context, rules models.RiskRules) (*models.RiskRules, error) {
+	for _, band := range rules.AgeBands {
+		if band.MaxAge != 0 && band.MaxAge < band.MinAge {
+			return nil, &ValidationError{fmt.Sprintf("Age band from %d has a maximum age below its minimum", band.MinAge)}
+		}
+	}
+	if rules.AgeBands == nil {
+		rules.AgeBands = []models.RiskAgeBand{}
+	}
+
+	if err := s.risk.SetRules(ctx, rules); err != nil {
+		return nil, err
+	}
+	return &rules, nil
+}
+
+// GetAssessment retrieves a patient's latest assessment, assessing them first
+// when they never have been. It returns nil when the patient does not exist.
+func (s *RiskService) GetAssessment(ctx context.Context, patientID int) (*models.RiskAssessment, error) {
+	assessment, err := s.risk.GetAssessment(ctx, patientID)
+	if err != nil || assessment != nil {
+		return assessment, err
+	}
+	return s.Recompute(ctx, patientID)
+}
+
+// Recompute assesses a patient under the current rules and stores the result.
+// It returns nil when the patient does not exist.
+func (s *RiskService) Recompute(ctx context.Context, patientID int) (*models.RiskAssessment, error) {
+	patient, err := s.patients.GetByID(ctx, patientID)
+	if err != nil || patient == nil {
+		return nil, err
+	}
+
+	rules, err := s.patientRules(ctx, patientID)
+	if err != nil {
+		return nil, err
+	}
+
+	assessment, err := s.assess(ctx, *rules, *patient)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.risk.SaveAssessment(ctx, *assessment); err != nil {
+		return nil, err
+	}
+	return assessment, nil
+}
+
+// RecomputeAll reassesses every patient visible to ctx under their clinic's current rules
+func (s *RiskService) RecomputeAll(ctx context.Context) (*models.RiskRecomputeResult, error) {
+	// A run over every clinic, as the worker's is, looks up each patient's rules
+	var clinicRules *models.RiskRules
+	if _, ok := repository.ClinicID(ctx); ok {
+		var err error
+		if clinicRules, err = s.GetRules(ctx); err != nil {
+			return nil, err
+		}
+	}
+
+	patients, err := s.patients.List(ctx, "")
+	if err != nil {
+		return nil, err
+	}
+
+	result := &models.RiskRecomputeResult{}
+	for _, patient := range patients {
+		if err := ctx.Err(); err != nil {
+			return result, err
+		}
+
+		rules := clinicRules
+		if rules == nil {
+			if rules, err = s.patientRules(ctx, patient.ID); err != nil {
+				return result, err
+			}
+		}
+
+		previous, err := s.risk.GetAssessment(ctx, patient.ID)
+		if err != nil {
+			return result, err
+		}
+		assessment, err := s.assess(ctx, *rules, patient)
+		if err != nil {
+			return result, fmt.Errorf("assessing patient %d: %w", patient.ID, err)
+		}
+		if err := s.risk.SaveAssessment(ctx, *assessment); err != nil {
+			return result, err
+		}
+
+		result.Patients++
+		if previous == nil || previous.Level != assessment.Level {
+			result.Changed++
+		}
+	}
+	return result, nil
+}
+
+// Run recomputes every patient every interval until ctx is cancelled, so the
+// factors that change with time, such as age and invoices falling overdue, are
+// picked up
+func (s *RiskService) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if result, err := s.RecomputeAll(ctx); err != nil {
+			log.Printf("Error recomputing patient risk: %v", err)
+		} else if result.Changed > 0 {
+			log.Printf("Recomputed risk of %d patients, %d changed level", result.Patients, result.Changed)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// refresh recomputes the patients after a change that concerns them. A failure
+// is logged rather than failing the change that caused it.
+func (s *RiskService) refresh(ctx context.Context, patientIDs ...int) {
+	if s == nil {
+		return
+	}
+	seen := make(map[int]bool)
+	for _, patientID := range patientIDs {
+		if patientID == 0 || seen[patientID] {
+			continue
+		}
+		seen[patientID] = true
+		if _, err := s.Recompute(ctx, patientID); err != nil {
+			log.Printf("Error recomputing risk of patient %d: %v", patientID, err)
+		}
+	}
+}
+
+// assess scores a patient under rules, explaining each factor that added points
+func (s *RiskService) assess(ctx context.Context, rules models.RiskRules, patient models.Patient) (*models.RiskAssessment, error) {
+	now := s.now()
+	today := now.Format("2006-01-02")
+	assessment := &models.RiskAssessment{PatientID: patient.ID, Factors: []models.RiskFactor{}, ComputedAt: now}
+
+	add := func(factor models.RiskFactorKind, description string, count, points, max int) {
+		if max > 0 && points > max {
+			points = max
+		}
+		if points <= 0 {
+			return
+		}
+		assessment.Factors = append(assessment.Factors, models.RiskFactor{Factor: string(factor), Description: description, Count: count, Points: points})
+		assessment.Score += points
+	}
+
+	// Active medical conditions, weighted by severity
+	entries, err := s.entries.ListActive(ctx, []int{patient.ID})
+	if err != nil {
+		return nil, err
+	}
+	conditions, conditionPoints := 0, 0
+	for _, e := range entries {
+		if e.Kind == string(models.MedicalEntryCondition) {
+			conditions++
+			conditionPoints += rules.Conditions.Points(e.Severity)
+		}
+	}
+	add(models.RiskFactorConditions, plural(conditions, "active medical condition"), conditions, conditionPoints, rules.Conditions.Max)
+
+	// Age, by the first band it falls in
+	if age, ok := ageOn(patient.DateOfBirth, now); ok {
+		for _, band := range rules.AgeBands {
+			if age >= band.MinAge && (band.MaxAge == 0 || age <= band.MaxAge) {
+				add(models.RiskFactorAge, describeAgeBand(band), 0, band.Points, 0)
+				break
+			}
+		}
+	}
+
+	// Outstanding high-priority treatments
+	treatments, err := s.treatments.ListForPatient(ctx, patient.ID)
+	if err != nil {
+		return nil, err
+	}
+	outstanding := 0
+	for _, pt := range treatments {
+		if pt.Status == string(models.PatientTreatmentStatusCompleted) {
+			continue
+		}
+		if pt.Priority == string(models.PatientTreatmentPriorityHigh) || pt.Priority == string(models.PatientTreatmentPriorityUrgent) {
+			outstanding++
+		}
+	}
+	add(models.RiskFactorHighPriorityTreatments, plural(outstanding, "outstanding high-priority treatment"),
+		outstanding, outstanding*rules.HighPriorityTreatments.Points, rules.HighPriorityTreatments.Max)
+
+	// No-shows, within the window when one is set
+	noShow := string(models.AppointmentStatusNoShow)
+	filter := repository.AppointmentFilter{PatientID: &patient.ID, Status: &noShow}
+	if rules.NoShowWindowDays > 0 {
+		from := now.AddDate(0, 0, -rules.NoShowWindowDays).Format("2006-01-02")
+		filter.From = &from
+		filter.To = &today
+	}
+	noShows, err := s.appointments.List(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	description := plural(len(noShows), "no-show")
+	if rules.NoShowWindowDays > 0 {
+		description += fmt.Sprintf(" in the last %d days", rules.NoShowWindowDays)
+	}
+	add(models.RiskFactorNoShows, description, len(noShows), len(noShows)*rules.NoShows.Points, rules.NoShows.Max)
+
+	// Overdue invoices
+	invoices, err := s.billing.ListInvoices(ctx, "", strconv.Itoa(patient.ID))
+	if err != nil {
+		return nil, err
+	}
+	overdue := 0
+	for _, invoice := range invoices {
+		if invoiceOverdue(invoice, today) {
+			overdue++
+		}
+	}
+	add(models.RiskFactorOverdueInvoices, plural(overdue, "overdue invoice"), overdue, overdue*rules.OverdueInvoices.Points, rules.OverdueInvoices.Max)
+
+	switch {
+	case assessment.Score >= rules.HighScore:
+		assessment.Level = string(models.RiskLevelHigh)
+	case assessment.Score >= rules.MediumScore:
+		assessment.Level = string(models.RiskLevelMedium)
+	default:
+		assessment.Level = string(models.RiskLevelLow)
+	}
+	return assessment, nil
+}
+
+// ageOn returns the age in whole years on now of someone born on dateOfBirth,
+// reporting false when it is not a YYYY-MM-DD date
+func ageOn(dateOfBirth string, now time.Time) (int, bool) {
+	born, err := time.Parse("2006-01-02", dateOfBirth)
+	if err != nil || born.After(now) {
+		return 0, false
+	}
+	age := now.Year() - born.Year()
+	if now.Month() < born.Month() || (now.Month() == born.Month() && now.Day() < born.Day()) {
+		age--
+	}
+	return age, true
+}
+
+// describeAgeBand names an age band without revealing the patient's exact age
+func describeAgeBand(band models.RiskAgeBand) string {
+	if band.MaxAge == 0 {
+		return fmt.Sprintf("Aged %d or over", band.MinAge)
+	}
+	return fmt.Sprintf("Aged %d to %d", band.MinAge, band.MaxAge)
+}
+
+// invoiceOverdue reports whether an invoice is marked overdue, or still
+// pending after its due date
+func invoiceOverdue(invoice models.Invoice, today string) bool {
+	if invoice.Status == "overdue" {
+		return true
+	}
+	// Due dates read from PostgreSQL carry a time after the date
+	return invoice.Status == "pending" && len(invoice.DueDate) >= 10 && invoice.DueDate[:10] < today
+}
+
+// plural describes a count of things, such as "2 overdue invoices"
+func plural(count int, thing string) string {
+	if count == 1 {
+		return "1 " + thing
+	}
+	return fmt.Sprintf("%d %ss", count, thing)
+}
@@ -0,0 +1,398 @@
+// dental_backend/internal/services/schedule_service.go
+package services
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"dental_backend/internal/models"
+	"dental_backend/internal/repository"
+)
+
+// SlotInterval is the spacing between the start times offered by the availability search
+const SlotInterval = 15 * time.Minute
+
+// interval is a half-open range of time measured from midnight
+type interval struct {
+	start time.Duration
+	end   time.Duration
+}
+
+// daySchedule is the time a dentist is open for bookings on one date
+type daySchedule struct {
+	closed bool
+	reason string
+	open   []interval
+}
+
+// contains reports whether the window fits entirely inside one open interval
+func (d daySchedule) contains(window models.AppointmentWindow) bool {
+	for _, iv := range d.open {
+		if window.Start >= iv.start && window.End <= iv.end {
+			return true
+		}
+	}
+	return false
+}
+
+// loadDaySchedule works out a dentist's open time on a date from their weekly
+// hours and breaks and any closures covering that date
+func loadDaySchedule(ctx context.Context, schedules repository.ScheduleRepository, dentistID int, day time.Time) (daySchedule, error) {
+	date := day.Format("2006-01-02")
+
+	closures, err := schedules.Closures(ctx, &dentistID, date, date)
+	if err != nil {
+		return daySchedule{}, err
+	}
+	if len(closures) > 0 {
+		return daySchedule{closed: true, reason: closures[0].Reason}, nil
+	}
+
+	hours, err := schedules.WorkingHours(ctx, dentistID)
+	if err != nil {
+		return daySchedule{}, err
+	}
+	if len(hours) == 0 {
+		hours = defaultWorkingHours(dentistID)
+	}
+
+	breaks, err := schedules.Breaks(ctx, dentistID)
+	if err != nil {
+		return daySchedule{}, err
+	}
+
+	weekday := int(day.Weekday())
+	var open []interval
+	for _, h := range hours {
+		if h.Weekday != weekday {
+			continue
+		}
+		iv, err := parseInterval(h.StartTime, h.EndTime)
+		if err != nil {
+			return daySchedule{}, err
+		}
+		open = append(open, iv)
+	}
+
+	for _, b := range breaks {
+		if b.Weekday != weekday {
+			continue
+		}
+		iv, err := parseInterval(b.StartTime, b.EndTime)
+		if err != nil {
+			return daySchedule{}, err
+		}
+		open = subtractInterval(open, iv)
+	}
+
+	sort.Slice(open, func(i, j int) bool { return open[i].start < open[j].start })
+
+	return daySchedule{closed: len(open) == 0, open: open}, nil
+}
+
+// defaultWorkingHours returns the clinic's standard week for a dentist without a schedule
+func defaultWorkingHours(dentistID int) []models.WorkingHours {
+	hours := make([]models.WorkingHours, len(models.DefaultWorkingHours))
+	for i, entry := range models.DefaultWorkingHours {
+		hours[i] = models.WorkingHours{
+			DentistID: dentistID,
+			Weekday:   entry.Weekday,
+			StartTime: entry.StartTime,
+			EndTime:   entry.EndTime,
+		}
+	}
+	return hours
+}
+
+// parseInterval parses a start and end clock time
+func parseInterval(startTime, endTime string) (interval, error) {
+	start, err := models.ParseAppointmentTime(startTime)
+	if err != nil {
+		return interval{}, err
+	}
+	end, err := models.ParseAppointmentTime(endTime)
+	if err != nil {
+		return interval{}, err
+	}
+	return interval{start: start, end: end}, nil
+}
+
+// subtractInterval removes cut from every interval in open
+func subtractInterval(open []interval, cut interval) []interval {
+	var result []interval
+	for _, iv := range open {
+		if cut.end <= iv.start || cut.start >= iv.end {
+			result = append(result, iv)
+			continue
+		}
+		if cut.start > iv.start {
+			result = append(result, interval{start: iv.start, end: cut.start})
+		}
+		if cut.end < iv.end {
+			result = append(result, interval{start: cut.end, end: iv.end})
+		}
+	}
+	return result
+}
+
+// ScheduleService provides business logic for dentist schedules and availability
+type ScheduleService struct {
+	schedules    repository.ScheduleRepository
+	appointments repository.AppointmentRepository
+
+	// now returns the current time; replaced in tests to pin "today"
+	now func() time.Time
+}
+
+// NewScheduleService creates a new schedule service
+func NewScheduleService(schedules repository.ScheduleRepository, appointments repository.AppointmentRepository) *ScheduleService {
+	return &ScheduleService{schedules: schedules, appointments: appointments, now: time.Now}
+}
+
+// SetClock overrides the clock used to hide slots that have already started
+func (s *ScheduleService) SetClock(now func() time.Time) {
+	s.now = now
+}
+
+// requireDentist returns a ValidationError unless the user is a dentist
+func (s *ScheduleService) requireDentist(ctx context.Context, dentistID int) error {
+	isDentist, err := s.schedules.IsDentist(ctx, dentistID)
+	if err != nil {
+		return err
+	}
+	if !isDentist {
+		return &ValidationError{fmt.Sprintf("User %d is not a dentist", dentistID)}
+	}
+	return nil
+}
+
+// GetDentists lists the dentists appointments can be booked with
+func (s *ScheduleService) GetDentists(ctx context.Context) ([]models.Dentist, error) {
+	dentists, err := s.schedules.Dentists(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if dentists == nil {
+		dentists = []models.Dentist{}
+	}
+	return dentists, nil
+}
+
+// GetSchedule retrieves a dentist's weekly schedule, falling back to the clinic default hours
+func (s *ScheduleService) GetSchedule(ctx context.Context, dentistID int) (*models.WeeklySchedule, error) {
+	if err := s.requireDentist(ctx, dentistID); err != nil {
+		return nil, err
+	}
+
+	hours, err := s.schedules.WorkingHours(ctx, dentistID)
+	if err != nil {
+		return nil, err
+	}
+	breaks, err := s.schedules.Breaks(ctx, dentistID)
+	if err != nil {
+		return nil, err
+	}
+
+	schedule := &models.WeeklySchedule{DentistID: dentistID, Hours: hours, Breaks: breaks}
+	if len(hours) == 0 {
+		schedule.Hours = defaultWorkingHours(dentistID)
+		schedule.Default = true
+	}
+	if schedule.Breaks == nil {
+		schedule.Breaks = []models.ScheduleBreak{}
+	}
+	return schedule, nil
+}
+
+// UpdateSchedule replaces a dentist's weekly working hours and breaks
+func (s *ScheduleService) UpdateSchedule(ctx context.Context, dentistID int, req models.UpdateScheduleRequest) (*models.WeeklySchedule, error) {
+	if err := s.requireDentist(ctx, dentistID); err != nil {
+		return nil, err
+	}
+
+	hours := make([]models.WorkingHours, len(req.Hours))
+	byDay := map[int][]interval{}
+	for i, entry := range req.Hours {
+		iv, err := validateScheduleEntry(entry.Weekday, entry.StartTime, entry.EndTime)
+		if err != nil {
+			return nil, err
+		}
+		for _, other := range byDay[entry.Weekday] {
+			if iv.start < other.end && other.start < iv.end {
+				return nil, &ValidationError{fmt.Sprintf("Working hours overlap on %s", time.Weekday(entry.Weekday))}
+			}
+		}
+		byDay[entry.Weekday] = append(byDay[entry.Weekday], iv)
+
+		hours[i] = models.WorkingHours{
+			DentistID: dentistID,
+			Weekday:   entry.Weekday,
+			StartTime: models.FormatHourMinute(iv.start),
+			EndTime:   models.FormatHourMinute(iv.end),
+		}
+	}
+
+	breaks := make([]models.ScheduleBreak, len(req.Breaks))
+	for i, entry := range req.Breaks {
+		iv, err := validateScheduleEntry(entry.Weekday, entry.StartTime, entry.EndTime)
+		if err != nil {
+			return nil, err
+		}
+		breaks[i] = models.ScheduleBreak{
+			DentistID: dentistID,
+			Weekday:   entry.Weekday,
+			StartTime: models.FormatHourMinute(iv.start),
+			EndTime:   models.FormatHourMinute(iv.end),
+			Label:     entry.Label,
+		}
+	}
+
+	if err := s.schedules.ReplaceWeek(ctx, dentistID, hours, breaks); err != nil {
+		return nil, err
+	}
+
+	return s.GetSchedule(ctx, dentistID)
+}
+
+// validateScheduleEntry checks one block of a weekly schedule
+func validateScheduleEntry(weekday int, startTime, endTime string) (interval, error) {
+	if weekday < 0 || weekday > 6 {
+		return interval{}, &ValidationError{"Weekday must be between 0 (Sunday) and 6 (Saturday)"}
+	}
+	iv, err := parseInterval(startTime, endTime)
+	if err != nil {
+		return interval{}, &ValidationError{"Invalid schedule time, expected HH:MM"}
+	}
+	if iv.end <= iv.start {
+		return interval{}, &ValidationError{fmt.Sprintf("Schedule block %s-%s must end after it starts", startTime, endTime)}
+	}
+	return iv, nil
+}
+
+// GetClosures retrieves closures in a date range, for one dentist when dentistID is set
+func (s *ScheduleService) GetClosures(ctx context.Context, dentistID *int, from, to string) ([]models.Closure, error) {
+	return s.schedules.Closures(ctx, dentistID, from, to)
+}
+
+// GetClosureByID retrieves a single closure by ID
+func (s *ScheduleService) GetClosureByID(ctx context.Context, id int) (*models.Closure, error) {
+	return s.schedules.GetClosure(ctx, id)
+}
+
+// CreateClosure records a closure for a dentist or, without a dentist, the whole clinic
+func (s *ScheduleService) CreateClosure(ctx context.Context, req models.CreateClosureRequest) (*models.Closure, error) {
+	if req.EndDate == "" {
+		req.EndDate = req.StartDate
+	}
+
+	start, err := models.ParseAppointmentDate(req.StartDate)
+	if err != nil {
+		return nil, &ValidationError{"Invalid start date, expected YYYY-MM-DD"}
+	}
+	end, err := models.ParseAppointmentDate(req.EndDate)
+	if err != nil {
+		return nil, &ValidationError{"Invalid end date, expected YYYY-MM-DD"}
+	}
+	if end.Before(start) {
+		return nil, &ValidationError{"Closure end date cannot be before its start date"}
+	}
+
+	if req.DentistID != nil {
+		if err := s.requireDentist(ctx, *req.DentistID); err != nil {
+			return nil, err
+		}
+	}
+
+	return s.schedules.CreateClosure(ctx, req)
+}
+
+// DeleteClosure deletes a closure by ID
+func (s *ScheduleService) DeleteClosure(ctx context.Context, id int) error {
+	return s.schedules.DeleteClosure(ctx, id)
+}
+
+// GetAvailability computes the open slots of the given length for a dentist on a date
+func (s *ScheduleService) GetAvailability(ctx context.Context, dentistID int, date string, duration int) (*models.Availability, error) {
+	if duration <= 0 {
+		duration = models.DefaultAppointmentDuration
+	}
+
+	day, err := models.ParseAppointmentDate(date)
+	if err != nil {
+		return nil, &ValidationError{"Invalid date, expected YYYY-MM-DD"}
+	}
+
+	if err := s.requireDentist(ctx, dentistID); err != nil {
+		return nil, err
+	}
+
+	availability := &models.Availability{
+		DentistID: dentistID,
+		Date:      day.Format("2006-01-02"),
+		Duration:  duration,
+		Slots:     []models.Slot{},
+	}
+
+	schedule, err := loadDaySchedule(ctx, s.schedules, dentistID, day)
+	if err != nil {
+		return nil, err
+	}
+	if schedule.closed {
+		availability.Closed = true
+		availability.Reason = schedule.reason
+		return availability, nil
+	}
+
+	// Existing bookings for the whole day
+	booked, err := s.appointments.Overlapping(ctx, dentistID, models.AppointmentWindow{
+		Date:  availability.Date,
+		Start: 0,
+		End:   24 * time.Hour,
+	}, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	var bookedWindows []models.AppointmentWindow
+	for _, a := range booked {
+		if window, err := a.Window(); err == nil {
+			bookedWindows = append(bookedWindows, window)
+		}
+	}
+
+	// Slots that have already started today are not offered
+	now := s.now()
+	earliest := time.Duration(-1)
+	if now.Format("2006-01-02") == availability.Date {
+		earliest = time.Duration(now.Hour())*time.Hour + time.Duration(now.Minute())*time.Minute
+	}
+
+	length := time.Duration(duration) * time.Minute
+	for _, iv := range schedule.open {
+		// Align the first start to the slot grid
+		start := (iv.start + SlotInterval - 1) / SlotInterval * SlotInterval
+		for ; start+length <= iv.end; start += SlotInterval {
+			if start < earliest {
+				continue
+			}
+			candidate := models.AppointmentWindow{Date: availability.Date, Start: start, End: start + length}
+			free := true
+			for _, b := range bookedWindows {
+				if candidate.Overlaps(b) {
+					free = false
+					break
+				}
+			}
+			if free {
+				availability.Slots = append(availability.Slots, models.Slot{
+					StartTime: models.FormatHourMinute(candidate.Start),
+					EndTime:   models.FormatHourMinute(candidate.End),
+				})
+			}
+		}
+	}
+
+	return availability, nil
+}
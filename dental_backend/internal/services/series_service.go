@@ -0,0 +1,390 @@
+// dental_backend/internal/services/series_service.go
+package services
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"dental_backend/internal/models"
+	"dental_backend/internal/repository"
+)
+
+// SeriesService provides business logic for recurring appointment series. Each
+// occurrence is booked and changed through the AppointmentService, so the same
+// schedule and double-booking rules apply to every one of them.
+type SeriesService struct {
+	series       repository.SeriesRepository
+	appointments repository.AppointmentRepository
+	booking      *AppointmentService
+}
+
+// NewSeriesService creates a new series service
+func NewSeriesService(series repository.SeriesRepository, appointments repository.AppointmentRepository, booking *AppointmentService) *SeriesService {
+	return &SeriesService{series: series, appointments: appointments, booking: booking}
+}
+
+// expandRecurrence returns the occurrence dates of a rule starting from start
+func expandRecurrence(start time.Time, rule models.Recurrence) ([]time.Time, error) {
+	interval := rule.Interval
+	if interval <= 0 {
+		interval = 1
+	}
+
+	if rule.Count == 0 && rule.Until == "" {
+		return nil, &ValidationError{"Recurrence needs a count or an until date"}
+	}
+	if rule.Count > models.MaxSeriesOccurrences {
+		return nil, &ValidationError{fmt.Sprintf("A series can have at most %d occurrences", models.MaxSeriesOccurrences)}
+	}
+
+	var until time.Time
+	if rule.Until != "" {
+		var err error
+		until, err = models.ParseAppointmentDate(rule.Until)
+		if err != nil {
+			return nil, &ValidationError{"Invalid until date, expected YYYY-MM-DD"}
+		}
+		if until.Before(start) {
+			return nil, &ValidationError{"Recurrence until date is before the start date"}
+		}
+	}
+
+	// next returns the i-th occurrence counted from the first one
+	var next func(i int) time.Time
+	switch models.RecurrenceFrequency(rule.Frequency) {
+	case models.RecurrenceDaily:
+		next = func(i int) time.Time { return start.AddDate(0, 0, i*interval) }
+	case models.RecurrenceWeekly:
+		first := start
+		if rule.Weekday != nil {
+			offset := (*rule.Weekday - int(start.Weekday()) + 7) % 7
+			first = start.AddDate(0, 0, offset)
+		}
+		next = func(i int) time.Time { return first.AddDate(0, 0, 7*i*interval) }
+	case models.RecurrenceMonthly:
+		// Clamp to the last day of shorter months instead of spilling into the next one
+		next = func(i int) time.Time {
+			month := time.Date(start.Year(), start.Month()+time.Month(i*interval), 1, 0, 0, 0, 0, time.UTC)
+			lastDay := month.AddDate(0, 1, -1).Day()
+			day := start.Day()
+			if day > lastDay {
+				day = lastDay
+			}
+			return time.Date(month.Year(), month.Month(), day, 0, 0, 0, 0, time.UTC)
+		}
+	default:
+		return nil, &ValidationError{fmt.Sprintf("Unsupported recurrence frequency %q", rule.Frequency)}
+	}
+
+	var dates []time.Time
+	for i := 0; ; i++ {
+		date := next(i)
+		if rule.Until != "" && date.After(until) {
+			break
+		}
+		if rule.Count > 0 && len(dates) == rule.Count {
+			break
+		}
+		if len(dates) == models.MaxSeriesOccurrences {
+			return nil, &ValidationError{fmt.Sprintf("A series can have at most %d occurrences", models.MaxSeriesOccurrences)}
+		}
+		dates = append(dates, date)
+	}
+
+	if len(dates) == 0 {
+		return nil, &ValidationError{"Recurrence does not produce any occurrences"}
+	}
+	return dates, nil
+}
+
+// occurrenceConflict converts a booking failure into a per-occurrence report;
+// errors other than validation and conflict errors are returned unchanged
+func occurrenceConflict(err error, appointmentID *int, date, startTime string) (*models.OccurrenceConflict, error) {
+	conflict := &models.OccurrenceConflict{AppointmentID: appointmentID, Date: date, StartTime: startTime}
+	switch e := err.(type) {
+	case *ConflictError:
+		conflict.Reason = e.Message
+		conflict.ConflictingAppointment = e.Conflicting
+	case *ValidationError:
+		conflict.Reason = e.Message
+	default:
+		return nil, err
+	}
+	return conflict, nil
+}
+
+// CreateSeries creates a recurring series and books each occurrence. Occurrences
+// that cannot be booked are reported in the result instead of failing the series.
+func (s *SeriesService) CreateSeries(ctx context.Context, req models.CreateSeriesRequest, caller Caller) (*models.SeriesResult, error) {
+	dentistID, err := s.booking.bookingDentist(ctx, caller, req.DentistID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.booking.validateNotInPast(req.StartDate); err != nil {
+		return nil, err
+	}
+
+	start, err := models.ParseAppointmentDate(req.StartDate)
+	if err != nil {
+		return nil, &ValidationError{"Invalid start date, expected YYYY-MM-DD"}
+	}
+
+	rule := req.Recurrence
+	if rule.Interval <= 0 {
+		rule.Interval = 1
+	}
+	if rule.Weekday != nil && models.RecurrenceFrequency(rule.Frequency) != models.RecurrenceWeekly {
+		return nil, &ValidationError{"A weekday can only be set on weekly series"}
+	}
+
+	dates, err := expandRecurrence(start, rule)
+	if err != nil {
+		return nil, err
+	}
+
+	duration, err := s.booking.resolveDuration(ctx, req.Duration, req.TreatmentID)
+	if err != nil {
+		return nil, err
+	}
+
+	series, err := s.series.Create(ctx, models.AppointmentSeries{
+		DentistID:   dentistID,
+		PatientID:   req.PatientID,
+		TreatmentID: req.TreatmentID,
+		StartDate:   start.Format("2006-01-02"),
+		StartTime:   req.StartTime,
+		Duration:    duration,
+		Notes:       req.Notes,
+		Recurrence:  rule,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	result := &models.SeriesResult{
+		Series:       series,
+		Appointments: []models.Appointment{},
+		Conflicts:    []models.OccurrenceConflict{},
+	}
+
+	for _, date := range dates {
+		appointment, err := s.booking.book(ctx, models.CreateAppointmentRequest{
+			PatientID:       req.PatientID,
+			AppointmentDate: date.Format("2006-01-02"),
+			StartTime:       req.StartTime,
+			Duration:        duration,
+			TreatmentID:     req.TreatmentID,
+			Status:          string(models.AppointmentStatusScheduled),
+			Notes:           req.Notes,
+			SeriesID:        &series.ID,
+		}, dentistID)
+		if err != nil {
+			conflict, err := occurrenceConflict(err, nil, date.Format("2006-01-02"), req.StartTime)
+			if err != nil {
+				return nil, err
+			}
+			result.Conflicts = append(result.Conflicts, *conflict)
+			continue
+		}
+		result.Appointments = append(result.Appointments, *appointment)
+	}
+
+	// Nothing could be booked, so don't leave an empty series behind
+	if len(result.Appointments) == 0 {
+		if err := s.series.Delete(ctx, series.ID, dentistID); err != nil {
+			return nil, err
+		}
+		result.Series = nil
+	}
+
+	return result, nil
+}
+
+// GetSeries retrieves a series visible to the caller with all of its appointments
+func (s *SeriesService) GetSeries(ctx context.Context, id int, caller Caller) (*models.SeriesResult, error) {
+	series, err := s.series.GetByID(ctx, id, caller.dentistScope())
+	if err != nil || series == nil {
+		return nil, err
+	}
+
+	appointments, err := s.appointments.List(ctx, repository.AppointmentFilter{DentistID: &series.DentistID, SeriesID: &id})
+	if err != nil {
+		return nil, err
+	}
+	if appointments == nil {
+		appointments = []models.Appointment{}
+	}
+
+	return &models.SeriesResult{Series: series, Appointments: appointments, Conflicts: []models.OccurrenceConflict{}}, nil
+}
+
+// scopeTargets returns the occurrences an operation on appointment applies to.
+// Only upcoming (scheduled or confirmed) occurrences from today onwards are touched
+// by series-wide operations; visits in progress, finished or cancelled are left as they are.
+func (s *SeriesService) scopeTargets(ctx context.Context, appointment *models.Appointment, scope models.SeriesScope) ([]models.Appointment, error) {
+	if scope == models.SeriesScopeThis || appointment.SeriesID == nil {
+		return []models.Appointment{*appointment}, nil
+	}
+
+	occurrences, err := s.appointments.List(ctx, repository.AppointmentFilter{DentistID: &appointment.DentistID, SeriesID: appointment.SeriesID})
+	if err != nil {
+		return nil, err
+	}
+
+	from, err := models.ParseAppointmentDate(appointment.AppointmentDate)
+	if err != nil {
+		return nil, err
+	}
+	today := s.booking.now().Truncate(24 * time.Hour)
+	if scope == models.SeriesScopeAll || from.Before(today) {
+		from = today
+	}
+
+	var targets []models.Appointment
+	for _, occurrence := range occurrences {
+		date, err := models.ParseAppointmentDate(occurrence.AppointmentDate)
+		if err != nil {
+			return nil, err
+		}
+		if date.Before(from) || !occurrence.IsUpcoming() {
+			continue
+		}
+		targets = append(targets, occurrence)
+	}
+	return targets, nil
+}
+
+// parseScope validates a scope, defaulting to a single occurrence
+func parseScope(scope string) (models.SeriesScope, error) {
+	switch models.SeriesScope(scope) {
+	case "":
+		return models.SeriesScopeThis, nil
+	case models.SeriesScopeThis, models.SeriesScopeFollowing, models.SeriesScopeAll:
+		return models.SeriesScope(scope), nil
+	}
+	return "", &ValidationError{fmt.Sprintf("Invalid scope %q, expected this, following or all", scope)}
+}
+
+// applyToScope runs change against every occurrence in scope and collects the failures
+func (s *SeriesService) applyToScope(ctx context.Context, appointmentID int, scope string, caller Caller,
+	change func(target models.Appointment) (*models.Appointment, error)) (*models.SeriesResult, *models.Appointment, error) {
+
+	seriesScope, err := parseScope(scope)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	appointment, err := s.appointments.GetByID(ctx, appointmentID, caller.dentistScope())
+	if err != nil || appointment == nil {
+		return nil, nil, err
+	}
+
+	targets, err := s.scopeTargets(ctx, appointment, seriesScope)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	result := &models.SeriesResult{Appointments: []models.Appointment{}, Conflicts: []models.OccurrenceConflict{}}
+	for _, target := range targets {
+		updated, err := change(target)
+		if err != nil {
+			targetID := target.ID
+			conflict, err := occurrenceConflict(err, &targetID, target.AppointmentDate, target.StartTime)
+			if err != nil {
+				return nil, nil, err
+			}
+			result.Conflicts = append(result.Conflicts, *conflict)
+			continue
+		}
+		if updated != nil {
+			result.Appointments = append(result.Appointments, *updated)
+		}
+	}
+
+	return result, appointment, nil
+}
+
+// UpdateOccurrences applies an edit to one occurrence, that occurrence and the
+// following ones, or the whole series. It returns (nil, nil) when the
+// appointment does not exist.
+func (s *SeriesService) UpdateOccurrences(ctx context.Context, appointmentID int, scope string, req models.UpdateAppointmentRequest, caller Caller) (*models.SeriesResult, error) {
+	if req.AppointmentDate != "" && scope != "" && models.SeriesScope(scope) != models.SeriesScopeThis {
+		return nil, &ValidationError{"Moving the date is only supported for a single occurrence"}
+	}
+
+	result, appointment, err := s.applyToScope(ctx, appointmentID, scope, caller, func(target models.Appointment) (*models.Appointment, error) {
+		return s.booking.UpdateAppointment(ctx, target.ID, req, caller)
+	})
+	if err != nil || result == nil {
+		return nil, err
+	}
+
+	if appointment.SeriesID != nil {
+		series, err := s.series.GetByID(ctx, *appointment.SeriesID, &appointment.DentistID)
+		if err != nil {
+			return nil, err
+		}
+
+		// Editing the whole series also updates the template it was created from
+		if series != nil && models.SeriesScope(scope) == models.SeriesScopeAll {
+			if req.StartTime != "" {
+				series.StartTime = req.StartTime
+			}
+			if req.Duration != 0 {
+				series.Duration = req.Duration
+			}
+			if req.TreatmentID != nil {
+				series.TreatmentID = req.TreatmentID
+			}
+			if req.Notes != "" {
+				series.Notes = req.Notes
+			}
+			if series, err = s.series.Update(ctx, *series); err != nil {
+				return nil, err
+			}
+		}
+		result.Series = series
+	}
+
+	return result, nil
+}
+
+// CancelOccurrences cancels one occurrence, that occurrence and the following
+// ones, or the whole series, recording reason on each. It returns (nil, nil) when
+// the appointment does not exist.
+func (s *SeriesService) CancelOccurrences(ctx context.Context, appointmentID int, scope string, reason string, caller Caller) (*models.SeriesResult, error) {
+	cancel := models.UpdateAppointmentRequest{
+		Status:             string(models.AppointmentStatusCancelled),
+		CancellationReason: reason,
+	}
+
+	result, appointment, err := s.applyToScope(ctx, appointmentID, scope, caller, func(target models.Appointment) (*models.Appointment, error) {
+		return s.booking.UpdateAppointment(ctx, target.ID, cancel, caller)
+	})
+	if err != nil || result == nil {
+		return nil, err
+	}
+
+	if appointment.SeriesID != nil {
+		series, err := s.series.GetByID(ctx, *appointment.SeriesID, &appointment.DentistID)
+		if err != nil {
+			return nil, err
+		}
+
+		// Cancelling from an occurrence onwards ends the series the day before it
+		if series != nil && models.SeriesScope(scope) == models.SeriesScopeFollowing {
+			if date, err := models.ParseAppointmentDate(appointment.AppointmentDate); err == nil {
+				series.Recurrence.Until = date.AddDate(0, 0, -1).Format("2006-01-02")
+				series.Recurrence.Count = 0
+				if series, err = s.series.Update(ctx, *series); err != nil {
+					return nil, err
+				}
+			}
+		}
+		result.Series = series
+	}
+
+	return result, nil
+}
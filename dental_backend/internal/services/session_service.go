@@ -0,0 +1,399 @@
+// dental_backend/internal/services/session_service.go
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"dental_backend/internal/models"
+	"dental_backend/internal/repository"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// ErrInvalidSession is returned for access or refresh tokens that are malformed,
+// expired, or belong to a revoked session
+var ErrInvalidSession = errors.New("invalid or expired session")
+
+// ErrNoClinic is returned when a user who works at no clinic signs in
+var ErrNoClinic = errors.New("user is not a member of any clinic")
+
+// AccessClaims identifies the user, session and clinic an access token was
+// issued for. Role is the user's role at that clinic.
+type AccessClaims struct {
+	UserID    int
+	Role      models.UserRole
+	SessionID string
+	ClinicID  int
+}
+
+// SessionService issues short-lived access tokens backed by server-side sessions.
+// Each session holds one refresh token at a time; using it rotates it, and
+// replaying a rotated token revokes the session, since only a stolen copy
+// would still be presented. A session works in one clinic at a time.
+type SessionService struct {
+	sessions repository.SessionRepository
+	users    repository.UserRepository
+	clinics  repository.ClinicRepository
+
+	secret     []byte
+	accessTTL  time.Duration
+	refreshTTL time.Duration
+
+	// mfa, when set, keeps sessions out of clinics whose two-factor policy the user does not meet
+	mfa *MFAService
+
+	// now returns the current time; replaced in tests to pin "now"
+	now func() time.Time
+}
+
+// NewSessionService creates a session service signing access tokens with secret
+func NewSessionService(sessions repository.SessionRepository, users repository.UserRepository, clinics repository.ClinicRepository, secret string, accessTTL, refreshTTL time.Duration) *SessionService {
+	return &SessionService{
+		sessions:   sessions,
+		users:      users,
+		clinics:    clinics,
+		secret:     []byte(secret),
+		accessTTL:  accessTTL,
+		refreshTTL: refreshTTL,
+		now:        time.Now,
+	}
+}
+
+// SetMFA refuses to move a session to a clinic where the user's role requires
+// two-factor authentication they have not enabled
+func (s *SessionService) SetMFA(mfa *MFAService) {
+	s.mfa = mfa
+}
+
+// randomToken returns n random bytes encoded for use in URLs and headers
+func randomToken(n int) (string, error) {
+	raw := make([]byte, n)
+	if _, err := rand.Read(raw); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(raw), nil
+}
+
+// hashRefreshToken returns the stored form of a refresh token
+func hashRefreshToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// Start opens a session for a user who has just authenticated, in the first
+// clinic they work at
+func (s *SessionService) Start(ctx context.Context, user models.User, client models.SessionClient) (*models.TokenPair, error) {
+	clinic, err := s.clinicFor(ctx, user.ID, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	id, err := randomToken(16)
+	if err != nil {
+		return nil, err
+	}
+	refreshToken, err := randomToken(32)
+	if err != nil {
+		return nil, err
+	}
+
+	session, err := s.sessions.Create(ctx, models.Session{
+		ID:        id,
+		UserID:    user.ID,
+		UserAgent: client.UserAgent,
+		IPAddress: client.IPAddress,
+		ClinicID:  &clinic.ClinicID,
+		ExpiresAt: s.now().Add(s.refreshTTL),
+	}, hashRefreshToken(refreshToken))
+	if err != nil {
+		return nil, err
+	}
+
+	return s.tokenPair(user, *clinic, session.ID, refreshToken)
+}
+
+// SignInClinic returns the user's membership of the clinic a new session starts
+// in, or ErrNoClinic when they work at none
+func (s *SessionService) SignInClinic(ctx context.Context, userID int) (*models.UserClinic, error) {
+	return s.clinicFor(ctx, userID, nil)
+}
+
+// clinicFor returns the user's membership of the preferred clinic, or of the
+// first clinic they work at when they have left it or none is preferred
+func (s *SessionService) clinicFor(ctx context.Context, userID int, preferred *int) (*models.UserClinic, error) {
+	if preferred != nil {
+		membership, err := s.clinics.Membership(ctx, *preferred, userID)
+		if err != nil || membership != nil {
+			return membership, err
+		}
+	}
+
+	clinics, err := s.clinics.ListForUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if len(clinics) == 0 {
+		return nil, ErrNoClinic
+	}
+	return &clinics[0], nil
+}
+
+// tokenPair signs an access token for the session in a clinic and pairs it with refreshToken
+func (s *SessionService) tokenPair(user models.User, clinic models.UserClinic, sessionID, refreshToken string) (*models.TokenPair, error) {
+	now := s.now()
+	expiresAt := now.Add(s.accessTTL)
+
+	claims := jwt.MapClaims{
+		"user_id":   user.ID,
+		"user_role": clinic.Role,
+		"clinic_id": clinic.ClinicID,
+		"sid":       sessionID,
+		"exp":       expiresAt.Unix(),
+		"iat":       now.Unix(),
+	}
+	accessToken, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(s.secret)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.TokenPair{
+		AccessToken:  accessToken,
+		RefreshToken: refreshToken,
+		ExpiresAt:    expiresAt,
+		SessionID:    sessionID,
+		Clinic:       clinic,
+	}, nil
+}
+
+// Refresh rotates a refresh token and issues a new access token carrying the
+// user's current role at the session's clinic
+func (s *SessionService) Refresh(ctx context.Context, refreshToken string, client models.SessionClient) (*models.TokenPair, *models.User, error) {
+	next, err := randomToken(32)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	oldHash := hashRefreshToken(refreshToken)
+	session, err := s.sessions.Rotate(ctx, oldHash, hashRefreshToken(next), s.now().Add(s.refreshTTL), client)
+	if err != nil {
+		return nil, nil, err
+	}
+	if session == nil {
+		// A rotated token presented again means it was copied; end that session
+		reused, err := s.sessions.RevokeByPreviousHash(ctx, oldHash)
+		if err != nil {
+			return nil, nil, err
+		}
+		if reused {
+			log.Printf("Refresh token reuse detected from %s; session revoked", client.IPAddress)
+		}
+		return nil, nil, ErrInvalidSession
+	}
+
+	user, err := s.users.GetByID(ctx, session.UserID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if user == nil || !user.Active {
+		return nil, nil, ErrInvalidSession
+	}
+
+	// A user removed from the session's clinic carries on in another one
+	clinic, err := s.clinicFor(ctx, user.ID, session.ClinicID)
+	if errors.Is(err, ErrNoClinic) {
+		return nil, nil, ErrInvalidSession
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	if session.ClinicID == nil || *session.ClinicID != clinic.ClinicID {
+		if err := s.sessions.SetClinic(ctx, session.ID, clinic.ClinicID); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	pair, err := s.tokenPair(*user, *clinic, session.ID, next)
+	if err != nil {
+		return nil, nil, err
+	}
+	return pair, user, nil
+}
+
+// Authenticate verifies an access token, checks that its session is still active
+// in the token's clinic, and reads the user's role there
+func (s *SessionService) Authenticate(ctx context.Context, tokenString string) (*AccessClaims, error) {
+	token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
+		// Validate the alg is what we expect
+		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
+		}
+		return s.secret, nil
+	}, jwt.WithTimeFunc(s.now))
+	if err != nil || !token.Valid {
+		return nil, ErrInvalidSession
+	}
+
+	claims, ok := token.Claims.(jwt.MapClaims)
+	if !ok {
+		return nil, ErrInvalidSession
+	}
+	userID, ok := claims["user_id"].(float64)
+	if !ok {
+		return nil, ErrInvalidSession
+	}
+	// Tokens without a session cannot be revoked, so they are not accepted
+	sessionID, _ := claims["sid"].(string)
+	if sessionID == "" {
+		return nil, ErrInvalidSession
+	}
+	clinicID, ok := claims["clinic_id"].(float64)
+	if !ok {
+		return nil, ErrInvalidSession
+	}
+
+	session, err := s.sessions.GetActive(ctx, sessionID)
+	if err != nil {
+		return nil, err
+	}
+	if session == nil || session.UserID != int(userID) {
+		return nil, ErrInvalidSession
+	}
+	// Switching clinic retires the tokens issued for the previous one
+	if session.ClinicID == nil || *session.ClinicID != int(clinicID) {
+		return nil, ErrInvalidSession
+	}
+
+	// Removal from the clinic and role changes there apply at once
+	membership, err := s.clinics.Membership(ctx, int(clinicID), int(userID))
+	if err != nil {
+		return nil, err
+	}
+	if membership == nil {
+		return nil, ErrInvalidSession
+	}
+
+	return &AccessClaims{
+		UserID:    int(userID),
+		Role:      models.UserRole(membership.Role),
+		SessionID: sessionID,
+		ClinicID:  membership.ClinicID,
+	}, nil
+}
+
+// Clinics lists the clinics the user works at
+func (s *SessionService) Clinics(ctx context.Context, userID int) ([]models.UserClinic, error) {
+	clinics, err := s.clinics.ListForUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if clinics == nil {
+		clinics = []models.UserClinic{}
+	}
+	return clinics, nil
+}
+
+// SwitchClinic moves a session to another clinic the user works at and issues an
+// access token for it; the session keeps its refresh token
+func (s *SessionService) SwitchClinic(ctx context.Context, userID int, sessionID string, clinicID int) (*models.TokenPair, error) {
+	membership, err := s.clinics.Membership(ctx, clinicID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if membership == nil {
+		return nil, &ForbiddenError{"You are not a member of this clinic"}
+	}
+
+	user, err := s.users.GetByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil || !user.Active {
+		return nil, ErrInvalidSession
+	}
+
+	if s.mfa != nil {
+		required, err := s.mfa.EnrollmentRequired(ctx, *user, *membership)
+		if err != nil {
+			return nil, err
+		}
+		if required {
+			return nil, &ForbiddenError{"Two-factor authentication is required for your role at this clinic"}
+		}
+	}
+
+	err = s.sessions.SetClinic(ctx, sessionID, clinicID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrInvalidSession
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return s.tokenPair(*user, *membership, sessionID, "")
+}
+
+// Logout revokes one of the user's sessions
+func (s *SessionService) Logout(ctx context.Context, userID int, sessionID string) error {
+	return s.sessions.Revoke(ctx, sessionID, userID)
+}
+
+// LogoutAll revokes every session of the user and returns how many were active
+func (s *SessionService) LogoutAll(ctx context.Context, userID int) (int, error) {
+	return s.sessions.RevokeAll(ctx, userID, "")
+}
+
+// RevokeUserSession revokes a session of one of the clinic's users, returning
+// sql.ErrNoRows when there is no such user or session
+func (s *SessionService) RevokeUserSession(ctx context.Context, userID int, sessionID string) error {
+	if err := s.requireUser(ctx, userID); err != nil {
+		return err
+	}
+	return s.sessions.Revoke(ctx, sessionID, userID)
+}
+
+// RevokeUserSessions revokes every session of one of the clinic's users and returns
+// how many were active, or sql.ErrNoRows when there is no such user
+func (s *SessionService) RevokeUserSessions(ctx context.Context, userID int) (int, error) {
+	if err := s.requireUser(ctx, userID); err != nil {
+		return 0, err
+	}
+	return s.sessions.RevokeAll(ctx, userID, "")
+}
+
+// requireUser returns sql.ErrNoRows unless the user is one of the clinic's users
+func (s *SessionService) requireUser(ctx context.Context, userID int) error {
+	user, err := s.users.GetByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if user == nil {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+// GetSessions lists the user's active sessions, or nil when the user does not exist
+func (s *SessionService) GetSessions(ctx context.Context, userID int) ([]models.Session, error) {
+	user, err := s.users.GetByID(ctx, userID)
+	if err != nil || user == nil {
+		return nil, err
+	}
+
+	sessions, err := s.sessions.ListActive(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if sessions == nil {
+		sessions = []models.Session{}
+	}
+	return sessions, nil
+}
@@ -0,0 +1,255 @@
+// dental_backend/internal/services/user_service.go
+package services
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"dental_backend/internal/models"
+	"dental_backend/internal/repository"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// ErrInvalidCredentials is returned when no user has the email address and password
+var ErrInvalidCredentials = errors.New("invalid credentials")
+
+// UserService manages the staff accounts of the caller's clinic. A role change
+// sets the user's role at that clinic, which reaches their access token when it
+// is next refreshed; deactivation ends every session at once. The account itself
+// is shared by every clinic the user works at, so a clinic changes its profile,
+// email address or whether it is active only while it is the user's one clinic.
+type UserService struct {
+	users    repository.UserRepository
+	sessions repository.SessionRepository
+	clinics  repository.ClinicRepository
+}
+
+// NewUserService creates a new user service
+func NewUserService(users repository.UserRepository, sessions repository.SessionRepository, clinics repository.ClinicRepository) *UserService {
+	return &UserService{users: users, sessions: sessions, clinics: clinics}
+}
+
+// GetUsers lists the clinic's users matching the filter
+func (s *UserService) GetUsers(ctx context.Context, filter repository.UserFilter) ([]models.User, error) {
+	users, err := s.users.List(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	if users == nil {
+		users = []models.User{}
+	}
+	return users, nil
+}
+
+// GetUser retrieves a user by ID, or nil when it does not exist
+func (s *UserService) GetUser(ctx context.Context, id int) (*models.User, error) {
+	return s.users.GetByID(ctx, id)
+}
+
+// CreateUser creates an account with the given password
+func (s *UserService) CreateUser(ctx context.Context, req models.CreateUserRequest) (*models.User, error) {
+	hash, err := bcrypt.GenerateFromPassword([]byte(req.Password), bcrypt.DefaultCost)
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := s.users.Create(ctx, req, string(hash))
+	if errors.Is(err, repository.ErrEmailTaken) {
+		return nil, &ConflictError{Message: "User already exists"}
+	}
+	return user, err
+}
+
+// UpdateUser updates a user's profile or role at the clinic, refusing to demote its
+// last active admin. The profile and email address of a user who also works at
+// another clinic are left to the user.
+func (s *UserService) UpdateUser(ctx context.Context, id int, req models.UpdateUserRequest) (*models.User, error) {
+	user, err := s.users.GetByID(ctx, id)
+	if err != nil || user == nil {
+		return nil, err
+	}
+
+	if req.Email != "" || req.FirstName != "" || req.LastName != "" || req.Phone != "" {
+		if err := s.guardShared(ctx, id, "User also works at another clinic; only they can change their profile and email address"); err != nil {
+			return nil, err
+		}
+	}
+	if req.Role != "" && req.Role != user.Role {
+		if err := s.guardLastAdmin(ctx, user); err != nil {
+			return nil, err
+		}
+	}
+
+	return s.update(ctx, id, req)
+}
+
+// UpdateCurrentUser updates the caller's own profile; users cannot change their own role
+func (s *UserService) UpdateCurrentUser(ctx context.Context, caller Caller, req models.UpdateUserRequest) (*models.User, error) {
+	if req.Role != "" && req.Role != string(caller.Role) {
+		return nil, &ForbiddenError{"You cannot change your own role"}
+	}
+	req.Role = ""
+
+	return s.update(ctx, caller.UserID, req)
+}
+
+// update stores a profile change, reporting a duplicate email address as a conflict
+func (s *UserService) update(ctx context.Context, id int, req models.UpdateUserRequest) (*models.User, error) {
+	user, err := s.users.Update(ctx, id, req)
+	if errors.Is(err, repository.ErrEmailTaken) {
+		return nil, &ConflictError{Message: "Email address is already in use"}
+	}
+	return user, err
+}
+
+// Authenticate returns the user with the email address and password, or
+// ErrInvalidCredentials. Users who sign in only through a provider have no
+// password and never match.
+func (s *UserService) Authenticate(ctx context.Context, email, password string) (*models.User, error) {
+	user, err := s.users.FindByEmail(ctx, email)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil || bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(password)) != nil {
+		return nil, ErrInvalidCredentials
+	}
+	return user, nil
+}
+
+// ChangePassword replaces the caller's password after checking the current one,
+// and signs out every other session
+func (s *UserService) ChangePassword(ctx context.Context, caller Caller, sessionID string, req models.ChangePasswordRequest) error {
+	user, err := s.users.GetByID(ctx, caller.UserID)
+	if err != nil {
+		return err
+	}
+	if user == nil {
+		return sql.ErrNoRows
+	}
+
+	if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(req.CurrentPassword)); err != nil {
+		return &ValidationError{"Current password is incorrect"}
+	}
+
+	hash, err := bcrypt.GenerateFromPassword([]byte(req.NewPassword), bcrypt.DefaultCost)
+	if err != nil {
+		return err
+	}
+	if err := s.users.SetPassword(ctx, user.ID, string(hash)); err != nil {
+		return err
+	}
+
+	_, err = s.sessions.RevokeAll(ctx, user.ID, sessionID)
+	return err
+}
+
+// Deactivate blocks a user from signing in and ends their sessions while keeping
+// their records. A user who also works at another clinic is removed from this
+// clinic instead.
+func (s *UserService) Deactivate(ctx context.Context, caller Caller, id int) error {
+	if id == caller.UserID {
+		return &ValidationError{"You cannot deactivate your own account"}
+	}
+
+	user, err := s.users.GetByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if user == nil {
+		return sql.ErrNoRows
+	}
+	if err := s.guardShared(ctx, id, deactivateShared); err != nil {
+		return err
+	}
+	if err := s.guardLastAdmin(ctx, user); err != nil {
+		return err
+	}
+
+	if err := s.users.SetActive(ctx, id, false); err != nil {
+		return err
+	}
+	_, err = s.sessions.RevokeAll(ctx, id, "")
+	return err
+}
+
+// Activate lets a deactivated user sign in again, unless they also work at
+// another clinic
+func (s *UserService) Activate(ctx context.Context, id int) error {
+	user, err := s.users.GetByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if user == nil {
+		return sql.ErrNoRows
+	}
+	if err := s.guardShared(ctx, id, deactivateShared); err != nil {
+		return err
+	}
+	return s.users.SetActive(ctx, id, true)
+}
+
+// DeleteUser permanently deletes a user who has no clinical history
+func (s *UserService) DeleteUser(ctx context.Context, caller Caller, id int) error {
+	if id == caller.UserID {
+		return &ValidationError{"You cannot delete your own account"}
+	}
+
+	user, err := s.users.GetByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if user == nil {
+		return sql.ErrNoRows
+	}
+	if err := s.guardLastAdmin(ctx, user); err != nil {
+		return err
+	}
+
+	err = s.users.Delete(ctx, id)
+	if errors.Is(err, repository.ErrUserInUse) {
+		return &ConflictError{Message: "User has appointments on record or works at another clinic; deactivate the account or remove them from this clinic instead"}
+	}
+	return err
+}
+
+// deactivateShared explains why a user who works at several clinics cannot be
+// deactivated or reactivated by one of them
+const deactivateShared = "User also works at another clinic; remove them from this clinic instead"
+
+// guardShared refuses, with message, a change to the account of a user who works
+// at a clinic other than the one of ctx
+func (s *UserService) guardShared(ctx context.Context, id int, message string) error {
+	clinicID, ok := repository.ClinicID(ctx)
+	if !ok {
+		return nil
+	}
+
+	clinics, err := s.clinics.ListForUser(ctx, id)
+	if err != nil {
+		return err
+	}
+	for _, c := range clinics {
+		if c.ClinicID != clinicID {
+			return &ConflictError{Message: message}
+		}
+	}
+	return nil
+}
+
+// guardLastAdmin refuses to take away the admin role from the clinic's only active admin
+func (s *UserService) guardLastAdmin(ctx context.Context, user *models.User) error {
+	if user.Role != string(models.UserRoleAdmin) || !user.Active {
+		return nil
+	}
+
+	admins, err := s.users.CountActiveAdmins(ctx)
+	if err != nil {
+		return err
+	}
+	if admins <= 1 {
+		return &ConflictError{Message: "Cannot remove the last active admin"}
+	}
+	return nil
+}
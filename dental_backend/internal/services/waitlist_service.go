@@ -0,0 +1,472 @@
+// dental_backend/internal/services/waitlist_service.go
+package services
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"dental_backend/internal/models"
+	"dental_backend/internal/repository"
+)
+
+// urgencyScores weights waitlist entries by how soon the patient needs to be seen
+var urgencyScores = map[string]int{
+	string(models.WaitlistUrgencyUrgent): 40,
+	string(models.WaitlistUrgencyHigh):   30,
+	string(models.WaitlistUrgencyMedium): 20,
+	string(models.WaitlistUrgencyLow):    10,
+}
+
+// maxWaitingScore caps the points a patient earns for time spent on the waitlist, one per day
+const maxWaitingScore = 30
+
+// WaitlistService provides business logic for the waitlist and the slots freed for it
+type WaitlistService struct {
+	waitlist repository.WaitlistRepository
+	patients repository.PatientRepository
+	booking  *AppointmentService
+
+	// holdFor is how long a freed slot is reserved for the top candidate; zero disables holds
+	holdFor time.Duration
+}
+
+// NewWaitlistService creates a new waitlist service and attaches it to booking,
+// so cancellations and deletions offer their slots to the waitlist and held
+// slots cannot be booked for anyone else
+func NewWaitlistService(waitlist repository.WaitlistRepository, patients repository.PatientRepository, booking *AppointmentService, holdFor time.Duration) *WaitlistService {
+	s := &WaitlistService{waitlist: waitlist, patients: patients, booking: booking, holdFor: holdFor}
+	booking.waitlist = s
+	return s
+}
+
+// GetEntries retrieves waitlist entries matching the filter
+func (s *WaitlistService) GetEntries(ctx context.Context, filter repository.WaitlistFilter) ([]models.WaitlistEntry, error) {
+	return s.waitlist.ListEntries(ctx, filter)
+}
+
+// GetEntry retrieves a single waitlist entry
+func (s *WaitlistService) GetEntry(ctx context.Context, id int) (*models.WaitlistEntry, error) {
+	return s.waitlist.GetEntry(ctx, id)
+}
+
+// CreateEntry adds a patient to the waitlist
+func (s *WaitlistService) CreateEntry(ctx context.Context, req models.CreateWaitlistEntryRequest) (*models.WaitlistEntry, error) {
+	patient, err := s.patients.GetByID(ctx, req.PatientID)
+	if err != nil {
+		return nil, err
+	}
+	if patient == nil {
+		return nil, &ValidationError{fmt.Sprintf("Patient %d not found", req.PatientID)}
+	}
+
+	duration, err := s.booking.resolveDuration(ctx, req.Duration, req.TreatmentID)
+	if err != nil {
+		return nil, err
+	}
+
+	entry := models.WaitlistEntry{
+		PatientID:    req.PatientID,
+		DentistIDs:   req.DentistIDs,
+		Weekdays:     req.Weekdays,
+		EarliestTime: req.EarliestTime,
+		LatestTime:   req.LatestTime,
+		Duration:     duration,
+		TreatmentID:  req.TreatmentID,
+		Urgency:      req.Urgency,
+		Status:       string(models.WaitlistStatusWaiting),
+		Notes:        req.Notes,
+	}
+	if entry.Urgency == "" {
+		entry.Urgency = string(models.WaitlistUrgencyMedium)
+	}
+
+	if err := s.validateEntry(ctx, &entry); err != nil {
+		return nil, err
+	}
+
+	return s.waitlist.CreateEntry(ctx, entry)
+}
+
+// UpdateEntry changes a waitlist entry's preferences, urgency or status. It
+// returns (nil, nil) when the entry does not exist.
+func (s *WaitlistService) UpdateEntry(ctx context.Context, id int, req models.UpdateWaitlistEntryRequest) (*models.WaitlistEntry, error) {
+	entry, err := s.waitlist.GetEntry(ctx, id)
+	if err != nil || entry == nil {
+		return nil, err
+	}
+
+	if req.DentistIDs != nil {
+		entry.DentistIDs = *req.DentistIDs
+	}
+	if req.Weekdays != nil {
+		entry.Weekdays = *req.Weekdays
+	}
+	if req.EarliestTime != "" {
+		entry.EarliestTime = req.EarliestTime
+	}
+	if req.LatestTime != "" {
+		entry.LatestTime = req.LatestTime
+	}
+	if req.Duration != 0 {
+		entry.Duration = req.Duration
+	}
+	if req.Urgency != "" {
+		entry.Urgency = req.Urgency
+	}
+	if req.Status != "" {
+		entry.Status = req.Status
+	}
+	if req.Notes != "" {
+		entry.Notes = req.Notes
+	}
+
+	if err := s.validateEntry(ctx, entry); err != nil {
+		return nil, err
+	}
+
+	return s.waitlist.UpdateEntry(ctx, *entry)
+}
+
+// validateEntry checks an entry's preferences and normalises its times to HH:MM
+func (s *WaitlistService) validateEntry(ctx context.Context, entry *models.WaitlistEntry) error {
+	if entry.DentistIDs == nil {
+		entry.DentistIDs = []int{}
+	}
+	if entry.Weekdays == nil {
+		entry.Weekdays = []int{}
+	}
+
+	for _, dentistID := range entry.DentistIDs {
+		isDentist, err := s.booking.schedules.IsDentist(ctx, dentistID)
+		if err != nil {
+			return err
+		}
+		if !isDentist {
+			return &ValidationError{fmt.Sprintf("User %d is not a dentist", dentistID)}
+		}
+	}
+
+	for _, weekday := range entry.Weekdays {
+		if weekday < 0 || weekday > 6 {
+			return &ValidationError{"Weekday must be between 0 (Sunday) and 6 (Saturday)"}
+		}
+	}
+
+	for _, clock := range []*string{&entry.EarliestTime, &entry.LatestTime} {
+		if *clock == "" {
+			continue
+		}
+		t, err := models.ParseAppointmentTime(*clock)
+		if err != nil {
+			return &ValidationError{"Invalid preferred time, expected HH:MM"}
+		}
+		*clock = models.FormatHourMinute(t)
+	}
+	if entry.EarliestTime != "" && entry.LatestTime != "" && entry.EarliestTime >= entry.LatestTime {
+		return &ValidationError{"The earliest preferred time must be before the latest"}
+	}
+	return nil
+}
+
+// DeleteEntry removes a waitlist entry
+func (s *WaitlistService) DeleteEntry(ctx context.Context, id int) error {
+	return s.waitlist.DeleteEntry(ctx, id)
+}
+
+// SlotFreed records the slot of a cancelled or deleted appointment as an opening
+// and, when holds are enabled, reserves it for the best waitlist candidate.
+// Slots that have already started are ignored.
+func (s *WaitlistService) SlotFreed(ctx context.Context, appointment models.Appointment) error {
+	window, err := appointment.Window()
+	if err != nil {
+		return err
+	}
+	if !windowStart(window, s.booking.now().Location()).After(s.booking.now()) {
+		return nil
+	}
+
+	opening := models.SlotOpening{
+		DentistID:       appointment.DentistID,
+		AppointmentDate: window.Date,
+		StartTime:       models.FormatHourMinute(window.Start),
+		EndTime:         models.FormatHourMinute(window.End),
+		Status:          string(models.SlotOpeningOpen),
+	}
+	if appointment.ID != 0 {
+		opening.AppointmentID = &appointment.ID
+	}
+
+	created, err := s.waitlist.CreateOpening(ctx, opening)
+	if err != nil {
+		return err
+	}
+	if s.holdFor <= 0 {
+		return nil
+	}
+
+	candidates, err := s.rank(ctx, *created)
+	if err != nil || len(candidates) == 0 {
+		return err
+	}
+
+	expiresAt := s.booking.now().Add(s.holdFor)
+	created.Status = string(models.SlotOpeningHeld)
+	created.HeldEntryID = &candidates[0].Entry.ID
+	created.HoldExpiresAt = &expiresAt
+	_, err = s.waitlist.UpdateOpening(ctx, *created)
+	return err
+}
+
+// GetOpenings retrieves the unfilled openings from today onwards visible to the caller
+func (s *WaitlistService) GetOpenings(ctx context.Context, caller Caller) ([]models.SlotOpening, error) {
+	today := s.booking.now().Format("2006-01-02")
+	openings, err := s.waitlist.ListOpenings(ctx, caller.dentistScope(), today)
+	if err != nil {
+		return nil, err
+	}
+
+	result := []models.SlotOpening{}
+	for _, opening := range openings {
+		refreshed, err := s.refresh(ctx, opening)
+		if err != nil {
+			return nil, err
+		}
+		if refreshed.Status != string(models.SlotOpeningFilled) {
+			result = append(result, *refreshed)
+		}
+	}
+	return result, nil
+}
+
+// refresh releases an expired hold and marks an opening filled once an active
+// appointment occupies its slot, persisting any change
+func (s *WaitlistService) refresh(ctx context.Context, opening models.SlotOpening) (*models.SlotOpening, error) {
+	status := opening.Status
+
+	if status == string(models.SlotOpeningHeld) && !opening.IsHeld(s.booking.now()) {
+		opening.Status = string(models.SlotOpeningOpen)
+		opening.HeldEntryID = nil
+		opening.HoldExpiresAt = nil
+	}
+
+	if opening.Status != string(models.SlotOpeningFilled) {
+		window, err := opening.Window()
+		if err != nil {
+			return nil, err
+		}
+		booked, err := s.booking.appointments.Overlapping(ctx, opening.DentistID, window, 0)
+		if err != nil {
+			return nil, err
+		}
+		if len(booked) > 0 {
+			opening.Status = string(models.SlotOpeningFilled)
+		}
+	}
+
+	if opening.Status == status {
+		return &opening, nil
+	}
+	return s.waitlist.UpdateOpening(ctx, opening)
+}
+
+// GetCandidates ranks the waiting patients who fit an opening visible to the
+// caller. It returns (nil, nil) when the opening does not exist.
+func (s *WaitlistService) GetCandidates(ctx context.Context, openingID int, caller Caller) (*models.OpeningCandidates, error) {
+	opening, err := s.waitlist.GetOpening(ctx, openingID, caller.dentistScope())
+	if err != nil || opening == nil {
+		return nil, err
+	}
+	if opening, err = s.refresh(ctx, *opening); err != nil {
+		return nil, err
+	}
+
+	candidates := []models.WaitlistCandidate{}
+	if opening.Status != string(models.SlotOpeningFilled) {
+		if candidates, err = s.rank(ctx, *opening); err != nil {
+			return nil, err
+		}
+	}
+
+	return &models.OpeningCandidates{Opening: *opening, Candidates: candidates}, nil
+}
+
+// rank scores every waiting entry that fits the opening, best first
+func (s *WaitlistService) rank(ctx context.Context, opening models.SlotOpening) ([]models.WaitlistCandidate, error) {
+	window, err := opening.Window()
+	if err != nil {
+		return nil, err
+	}
+	day, err := models.ParseAppointmentDate(opening.AppointmentDate)
+	if err != nil {
+		return nil, err
+	}
+
+	waiting := string(models.WaitlistStatusWaiting)
+	entries, err := s.waitlist.ListEntries(ctx, repository.WaitlistFilter{Status: &waiting, DentistID: &opening.DentistID})
+	if err != nil {
+		return nil, err
+	}
+
+	now := s.booking.now()
+	candidates := []models.WaitlistCandidate{}
+	for _, entry := range entries {
+		if candidate, ok := scoreCandidate(entry, opening.DentistID, window, day.Weekday(), now); ok {
+			candidates = append(candidates, candidate)
+		}
+	}
+
+	// Entries are listed longest waiting first, so a stable sort keeps that order on ties
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].Score > candidates[j].Score
+	})
+	return candidates, nil
+}
+
+// scoreCandidate decides whether an entry fits a slot and, if so, how strongly.
+// Hard preferences (dentist, weekday, time window, length) exclude an entry;
+// urgency, matched preferences and time spent waiting add to its score.
+func scoreCandidate(entry models.WaitlistEntry, dentistID int, window models.AppointmentWindow, weekday time.Weekday, now time.Time) (models.WaitlistCandidate, bool) {
+	candidate := models.WaitlistCandidate{Entry: entry, Reasons: []string{}}
+
+	if time.Duration(entry.Duration)*time.Minute > window.End-window.Start {
+		return candidate, false
+	}
+
+	if len(entry.DentistIDs) > 0 {
+		matched := false
+		for _, id := range entry.DentistIDs {
+			matched = matched || id == dentistID
+		}
+		if !matched {
+			return candidate, false
+		}
+		candidate.Score += 15
+		candidate.Reasons = append(candidate.Reasons, "Prefers this dentist")
+	}
+
+	if len(entry.Weekdays) > 0 {
+		matched := false
+		for _, day := range entry.Weekdays {
+			matched = matched || time.Weekday(day) == weekday
+		}
+		if !matched {
+			return candidate, false
+		}
+		candidate.Score += 10
+		candidate.Reasons = append(candidate.Reasons, fmt.Sprintf("Prefers %s", weekday))
+	}
+
+	if entry.EarliestTime != "" || entry.LatestTime != "" {
+		if entry.EarliestTime != "" {
+			earliest, err := models.ParseAppointmentTime(entry.EarliestTime)
+			if err != nil || window.Start < earliest {
+				return candidate, false
+			}
+		}
+		if entry.LatestTime != "" {
+			latest, err := models.ParseAppointmentTime(entry.LatestTime)
+			if err != nil || window.Start+time.Duration(entry.Duration)*time.Minute > latest {
+				return candidate, false
+			}
+		}
+		candidate.Score += 10
+		candidate.Reasons = append(candidate.Reasons, "Within preferred time window")
+	}
+
+	candidate.Score += urgencyScores[entry.Urgency]
+	candidate.Reasons = append(candidate.Reasons, fmt.Sprintf("Urgency %s", entry.Urgency))
+
+	days := int(now.Sub(entry.CreatedAt).Hours() / 24)
+	if days > maxWaitingScore {
+		days = maxWaitingScore
+	}
+	if days > 0 {
+		candidate.Score += days
+		candidate.Reasons = append(candidate.Reasons, fmt.Sprintf("Waiting %d days", days))
+	}
+
+	return candidate, true
+}
+
+// BookOpening books a waitlist entry's patient into an opening visible to the
+// caller and takes the entry off the waitlist. It returns (nil, nil) when the
+// opening does not exist.
+func (s *WaitlistService) BookOpening(ctx context.Context, openingID int, req models.BookOpeningRequest, caller Caller) (*models.Appointment, error) {
+	opening, err := s.waitlist.GetOpening(ctx, openingID, caller.dentistScope())
+	if err != nil || opening == nil {
+		return nil, err
+	}
+	if opening, err = s.refresh(ctx, *opening); err != nil {
+		return nil, err
+	}
+	if opening.Status == string(models.SlotOpeningFilled) {
+		return nil, &ConflictError{Message: "This slot has already been filled"}
+	}
+
+	entry, err := s.waitlist.GetEntry(ctx, req.EntryID)
+	if err != nil {
+		return nil, err
+	}
+	if entry == nil {
+		return nil, &ValidationError{fmt.Sprintf("Waitlist entry %d not found", req.EntryID)}
+	}
+	if entry.Status != string(models.WaitlistStatusWaiting) {
+		return nil, &ValidationError{fmt.Sprintf("Waitlist entry %d is %s", entry.ID, entry.Status)}
+	}
+
+	notes := req.Notes
+	if notes == "" {
+		notes = entry.Notes
+	}
+	appointment, err := s.booking.book(ctx, models.CreateAppointmentRequest{
+		PatientID:       entry.PatientID,
+		AppointmentDate: opening.AppointmentDate,
+		StartTime:       opening.StartTime,
+		Duration:        entry.Duration,
+		TreatmentID:     entry.TreatmentID,
+		Notes:           notes,
+	}, opening.DentistID)
+	if err != nil {
+		return nil, err
+	}
+
+	entry.Status = string(models.WaitlistStatusBooked)
+	if _, err := s.waitlist.UpdateEntry(ctx, *entry); err != nil {
+		return nil, err
+	}
+
+	opening.Status = string(models.SlotOpeningFilled)
+	if _, err := s.waitlist.UpdateOpening(ctx, *opening); err != nil {
+		return nil, err
+	}
+
+	return appointment, nil
+}
+
+// checkHolds returns a ConflictError when the window overlaps a slot held for
+// a waitlist entry belonging to another patient
+func (s *WaitlistService) checkHolds(ctx context.Context, dentistID int, window models.AppointmentWindow, patientID int) error {
+	holds, err := s.waitlist.Holds(ctx, dentistID, window, s.booking.now())
+	if err != nil {
+		return err
+	}
+
+	for _, hold := range holds {
+		// A hold whose entry has been removed no longer reserves anything
+		if hold.HeldEntryID == nil {
+			continue
+		}
+		entry, err := s.waitlist.GetEntry(ctx, *hold.HeldEntryID)
+		if err != nil {
+			return err
+		}
+		if entry == nil || entry.PatientID == patientID {
+			continue
+		}
+		return &ConflictError{Message: fmt.Sprintf("The slot from %s to %s on %s is held for a waitlisted patient until %s",
+			hold.StartTime, hold.EndTime, hold.AppointmentDate, hold.HoldExpiresAt.Format("15:04"))}
+	}
+	return nil
+}
@@ -0,0 +1,107 @@
+// dental_backend/internal/totp/totp_test.go
+package totp
+
+import (
+	"testing"
+	"time"
+)
+
+// rfcSecret is the SHA-1 seed of the RFC 6238 test vectors, "12345678901234567890", in base32
+const rfcSecret = "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+
+func TestCodeAtMatchesRFC6238(t *testing.T) {
+	// The RFC lists eight-digit codes; six-digit codes are their last six digits
+	tests := []struct {
+		unix int64
+		rfc  string
+	}{
+		{unix: 59, rfc: "94287082"},
+		{unix: 1111111109, rfc: "07081804"},
+		{unix: 1111111111, rfc: "14050471"},
+		{unix: 1234567890, rfc: "89005924"},
+		{unix: 2000000000, rfc: "69279037"},
+		{unix: 20000000000, rfc: "65353130"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.rfc, func(t *testing.T) {
+			want := tt.rfc[len(tt.rfc)-Digits:]
+			got, err := CodeAt(rfcSecret, Step(time.Unix(tt.unix, 0)))
+			if err != nil {
+				t.Fatalf("CodeAt: %v", err)
+			}
+			if got != want {
+				t.Errorf("code at T=%d = %s, want %s", tt.unix, got, want)
+			}
+		})
+	}
+}
+
+func TestCodeAtAcceptsLowercaseSecrets(t *testing.T) {
+	got, err := CodeAt("gezdgnbvgy3tqojqgezdgnbvgy3tqojq", 1)
+	if err != nil {
+		t.Fatalf("CodeAt: %v", err)
+	}
+	if got != "287082" {
+		t.Errorf("code = %s, want 287082", got)
+	}
+}
+
+func TestCodeAtRejectsInvalidSecrets(t *testing.T) {
+	if _, err := CodeAt("not base32!", 1); err == nil {
+		t.Error("CodeAt with an invalid secret succeeded")
+	}
+}
+
+func TestValidate(t *testing.T) {
+	now := time.Unix(1111111109, 0)
+	current := Step(now)
+	codeAt := func(step int64) string {
+		t.Helper()
+		code, err := CodeAt(rfcSecret, step)
+		if err != nil {
+			t.Fatalf("CodeAt: %v", err)
+		}
+		return code
+	}
+
+	tests := []struct {
+		name     string
+		code     string
+		skew     int
+		wantStep int64
+		wantOK   bool
+	}{
+		{name: "current step", code: codeAt(current), skew: 1, wantStep: current, wantOK: true},
+		{name: "previous step", code: codeAt(current - 1), skew: 1, wantStep: current - 1, wantOK: true},
+		{name: "next step", code: codeAt(current + 1), skew: 1, wantStep: current + 1, wantOK: true},
+		{name: "two steps behind", code: codeAt(current - 2), skew: 1},
+		{name: "two steps ahead", code: codeAt(current + 2), skew: 1},
+		{name: "previous step without skew", code: codeAt(current - 1), skew: 0},
+		{name: "surrounding spaces", code: " " + codeAt(current) + " ", skew: 1, wantStep: current, wantOK: true},
+		{name: "wrong code", code: "000000", skew: 1},
+		{name: "too short", code: codeAt(current)[1:], skew: 1},
+		{name: "eight digits", code: "07081804", skew: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			step, ok := Validate(rfcSecret, tt.code, now, tt.skew)
+			if ok != tt.wantOK {
+				t.Fatalf("Validate(%q) ok = %v, want %v", tt.code, ok, tt.wantOK)
+			}
+			// The matched step is what replay protection records, so it must be exact
+			if ok && step != tt.wantStep {
+				t.Errorf("Validate(%q) step = %d, want %d", tt.code, step, tt.wantStep)
+			}
+		})
+	}
+}
+
+func TestProvisioningURI(t *testing.T) {
+	got := ProvisioningURI("Dental Flow", "dana@example.com", rfcSecret)
+	want := "otpauth://totp/Dental%20Flow:dana@example.com?algorithm=SHA1&digits=6&issuer=Dental%20Flow&period=30&secret=" + rfcSecret
+	if got != want {
+		t.Errorf("ProvisioningURI = %s, want %s", got, want)
+	}
+}
@@ -0,0 +1,20 @@
+-- dental_backend/scripts/db_roles.sql
+-- Creates dental_app, the role the API and worker connect as. Run it with psql
+-- as the user migrations run as (DB_MIGRATE_USER), which owns the tables:
+--
+--   psql -v app_password='secret' -f scripts/db_roles.sql
+--
+-- Row-level security does not apply to superusers, roles with BYPASSRLS or the
+-- owner of a table, so the API refuses to start as any of them.
+
+CREATE ROLE dental_app LOGIN NOSUPERUSER NOBYPASSRLS NOCREATEDB NOCREATEROLE PASSWORD :'app_password';
+
+REVOKE CREATE ON SCHEMA public FROM PUBLIC;
+GRANT USAGE ON SCHEMA public TO dental_app;
+
+GRANT SELECT, INSERT, UPDATE, DELETE ON ALL TABLES IN SCHEMA public TO dental_app;
+GRANT USAGE, SELECT ON ALL SEQUENCES IN SCHEMA public TO dental_app;
+
+-- Tables and sequences later migrations create
+ALTER DEFAULT PRIVILEGES IN SCHEMA public GRANT SELECT, INSERT, UPDATE, DELETE ON TABLES TO dental_app;
+ALTER DEFAULT PRIVILEGES IN SCHEMA public GRANT USAGE, SELECT ON SEQUENCES TO dental_app;
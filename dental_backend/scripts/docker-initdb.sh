@@ -0,0 +1,8 @@
+#!/bin/sh
+# dental_backend/scripts/docker-initdb.sh
+# Runs db_roles.sql when the postgres container initialises its database
+set -e
+
+psql -v ON_ERROR_STOP=1 -v app_password="$DENTAL_APP_PASSWORD" \
+    --username "$POSTGRES_USER" --dbname "$POSTGRES_DB" \
+    -f /scripts/db_roles.sql